@@ -128,6 +128,13 @@ func main() {
 		objectStoreBucket    string
 		objectStoreLocalPath string
 		objectStoreInst      *store.ObjectTokenStore
+		useRedisStore        bool
+		redisStoreURL        string
+		redisStorePrefix     string
+		redisStoreLocalPath  string
+		redisStoreInst       *store.RedisTokenStore
+		redisLockURL         string
+		redisLockPrefix      string
 	)
 
 	wd, err := os.Getwd()
@@ -202,6 +209,22 @@ func main() {
 	if value, ok := lookupEnv("OBJECTSTORE_LOCAL_PATH", "objectstore_local_path"); ok {
 		objectStoreLocalPath = value
 	}
+	if value, ok := lookupEnv("REDISSTORE_URL", "redisstore_url"); ok {
+		useRedisStore = true
+		redisStoreURL = value
+	}
+	if value, ok := lookupEnv("REDISSTORE_PREFIX", "redisstore_prefix"); ok {
+		redisStorePrefix = value
+	}
+	if value, ok := lookupEnv("REDISSTORE_LOCAL_PATH", "redisstore_local_path"); ok {
+		redisStoreLocalPath = value
+	}
+	if value, ok := lookupEnv("REDISLOCK_URL", "redislock_url"); ok {
+		redisLockURL = value
+	}
+	if value, ok := lookupEnv("REDISLOCK_PREFIX", "redislock_prefix"); ok {
+		redisLockPrefix = value
+	}
 
 	// Determine and load the configuration file.
 	// Prefer the Postgres store when configured, otherwise fallback to git or local files.
@@ -294,6 +317,35 @@ func main() {
 			cfg.AuthDir = objectStoreInst.AuthDir()
 			log.Infof("object-backed token store enabled, bucket: %s", objectStoreBucket)
 		}
+	} else if useRedisStore {
+		if redisStoreLocalPath == "" {
+			if writableBase != "" {
+				redisStoreLocalPath = writableBase
+			} else {
+				redisStoreLocalPath = wd
+			}
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		redisStoreInst, err = store.NewRedisTokenStore(ctx, store.RedisStoreConfig{
+			URL:       redisStoreURL,
+			Prefix:    redisStorePrefix,
+			LocalRoot: filepath.Join(redisStoreLocalPath, "redisstore"),
+		})
+		if err != nil {
+			cancel()
+			log.Fatalf("failed to initialize redis token store: %v", err)
+		}
+		if errBootstrap := redisStoreInst.Bootstrap(ctx); errBootstrap != nil {
+			cancel()
+			log.Fatalf("failed to bootstrap redis-backed config: %v", errBootstrap)
+		}
+		cancel()
+		configFilePath = redisStoreInst.ConfigPath()
+		cfg, err = config.LoadConfigOptional(configFilePath, false)
+		if err == nil {
+			cfg.AuthDir = redisStoreInst.AuthDir()
+			log.Infof("redis-backed token store enabled, workspace path: %s", redisStoreInst.WorkDir())
+		}
 	} else if useGitStore {
 		if gitStoreLocalPath == "" {
 			if writableBase != "" {
@@ -379,6 +431,9 @@ func main() {
 		); err != nil {
 			log.Warnf("Failed to initialize usage persistence: %v", err)
 		}
+		if err := usage.InitializeCounters(cfg.UsagePersistence.UsageCountersPath()); err != nil {
+			log.Warnf("Failed to load usage counters: %v", err)
+		}
 	}
 
 	provider.SetQuotaCooldownDisabled(cfg.DisableCooling)
@@ -408,11 +463,28 @@ func main() {
 		authlogin.RegisterTokenStore(pgStoreInst)
 	} else if useObjectStore {
 		authlogin.RegisterTokenStore(objectStoreInst)
+	} else if useRedisStore {
+		authlogin.RegisterTokenStore(redisStoreInst)
 	} else if useGitStore {
 		authlogin.RegisterTokenStore(gitStoreInst)
 	} else {
 		authlogin.RegisterTokenStore(authlogin.NewFileTokenStore())
 	}
+	// A separate lock lets instances sharing a store that cannot lock by
+	// itself, such as PostgreSQL, refresh each account only once.
+	if redisLockURL != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		locker, errLock := store.NewRedisRefreshLocker(ctx, store.RedisLockConfig{
+			URL:    redisLockURL,
+			Prefix: redisLockPrefix,
+		})
+		cancel()
+		if errLock != nil {
+			log.Fatalf("failed to initialize redis refresh lock: %v", errLock)
+		}
+		authlogin.RegisterRefreshLocker(locker)
+		log.Info("redis refresh lock enabled")
+	}
 
 	// Register built-in access providers before constructing services.
 	configaccess.Register()
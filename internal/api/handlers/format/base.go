@@ -3,13 +3,19 @@ package format
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/nghyane/llm-mux/internal/config"
 	"github.com/nghyane/llm-mux/internal/interfaces"
+	log "github.com/nghyane/llm-mux/internal/logging"
 	"github.com/nghyane/llm-mux/internal/provider"
 	"github.com/nghyane/llm-mux/internal/registry"
 	"github.com/nghyane/llm-mux/internal/util"
@@ -31,6 +37,9 @@ type BaseAPIHandler struct {
 	Cfg                   *config.SDKConfig
 	Routing               *config.RoutingConfig
 	OpenAICompatProviders []string
+
+	flights     requestFlights
+	idempotency idempotencyStore
 }
 
 func NewBaseAPIHandlers(cfg *config.SDKConfig, routing *config.RoutingConfig, authManager *provider.Manager, openAICompatProviders []string) *BaseAPIHandler {
@@ -53,11 +62,172 @@ func (h *BaseAPIHandler) getFallbackChain(model string) []string {
 	return h.Routing.GetFallbackChain(model)
 }
 
+// fallbacksFor returns the models to try when model cannot serve a request:
+// the client's fallback list when it sent one, otherwise the configured
+// fallback chain. A request pinned to a provider or account has none.
+func (h *BaseAPIHandler) fallbacksFor(ctx context.Context, model string) []string {
+	if routingOverrideFrom(ctx).active() {
+		return nil
+	}
+	if models := clientFallbacksFrom(ctx, model); len(models) > 0 {
+		return models
+	}
+	return h.getFallbackChain(model)
+}
+
 // Models returns all available models as maps from the global registry.
 func (h *BaseAPIHandler) Models() []map[string]any {
 	return registry.GetGlobalRegistry().GetAvailableModels("openai")
 }
 
+// AppendAliasModels adds an entry for every configured alias whose target is
+// listed in models, when routing.list-aliases is enabled.
+func (h *BaseAPIHandler) AppendAliasModels(models []map[string]any) []map[string]any {
+	if h.Routing == nil || !h.Routing.ListAliases {
+		return models
+	}
+	aliasMap := h.Routing.AliasMap()
+	if len(aliasMap) == 0 {
+		return models
+	}
+	byID := make(map[string]map[string]any, len(models))
+	for _, m := range models {
+		if id, ok := m["id"].(string); ok {
+			byID[id] = m
+		}
+	}
+	aliases := make([]string, 0, len(aliasMap))
+	for alias := range aliasMap {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	for _, alias := range aliases {
+		if _, exists := byID[alias]; exists {
+			continue
+		}
+		target := h.Routing.ResolveModelAlias(alias)
+		if _, model, ok := config.SplitAliasTarget(target); ok {
+			target = model
+		} else if _, model, ok := registry.GetGlobalRegistry().SplitProviderPrefix(target); ok {
+			target = model
+		}
+		src, ok := byID[target]
+		if !ok {
+			continue
+		}
+		entry := make(map[string]any, len(src))
+		for k, v := range src {
+			entry[k] = v
+		}
+		entry["id"] = alias
+		models = append(models, entry)
+	}
+	return models
+}
+
+// ApplyModelListing adjusts models to routing.list-models. In "canonical"
+// and "both" modes a family with a member in models is listed under its
+// canonical ID, with the listed members under "family_members"; "canonical"
+// also drops the members' own entries.
+func (h *BaseAPIHandler) ApplyModelListing(models []map[string]any) []map[string]any {
+	mode := h.Routing.ModelListing()
+	if mode == config.ModelListingProvider {
+		return models
+	}
+	byID := make(map[string]map[string]any, len(models))
+	for _, m := range models {
+		if id, ok := m["id"].(string); ok {
+			byID[id] = m
+		}
+	}
+	families := registry.ListModelFamilies()
+	canonicals := make([]string, 0, len(families))
+	for id := range families {
+		canonicals = append(canonicals, id)
+	}
+	sort.Strings(canonicals)
+
+	hidden := make(map[string]struct{})
+	var entries []map[string]any
+	for _, canonical := range canonicals {
+		var src map[string]any
+		var members []registry.FamilyMember
+		for _, m := range families[canonical] {
+			if listed, ok := byID[m.Model]; ok {
+				if src == nil {
+					src = listed
+				}
+				members = append(members, m)
+			}
+		}
+		if src == nil {
+			continue
+		}
+		if existing, ok := byID[canonical]; ok {
+			existing["family_members"] = members
+		} else {
+			entry := make(map[string]any, len(src)+1)
+			for k, v := range src {
+				entry[k] = v
+			}
+			entry["id"] = canonical
+			entry["family_members"] = members
+			entries = append(entries, entry)
+		}
+		if mode == config.ModelListingCanonical {
+			for _, m := range members {
+				if m.Model != canonical {
+					hidden[m.Model] = struct{}{}
+				}
+			}
+		}
+	}
+
+	out := make([]map[string]any, 0, len(models)+len(entries))
+	for _, m := range models {
+		if id, _ := m["id"].(string); id != "" {
+			if _, skip := hidden[id]; skip {
+				continue
+			}
+		}
+		out = append(out, m)
+	}
+	return append(out, entries...)
+}
+
+// ApplyModelPrefixes lists every provider-specific entry of models once per
+// provider serving it, as "provider/model", when routing.prefix-model-ids is
+// enabled. Canonical family and alias entries are left as they are.
+func (h *BaseAPIHandler) ApplyModelPrefixes(models []map[string]any) []map[string]any {
+	if h.Routing == nil || !h.Routing.PrefixModelIDs {
+		return models
+	}
+	reg := registry.GetGlobalRegistry()
+	out := make([]map[string]any, 0, len(models))
+	for _, m := range models {
+		id, _ := m["id"].(string)
+		if _, family := m["family_members"]; family || id == "" {
+			out = append(out, m)
+			continue
+		}
+		hosts := reg.ModelHosts(id)
+		if len(hosts) == 0 {
+			out = append(out, m)
+			continue
+		}
+		for _, provider := range hosts {
+			entry := make(map[string]any, len(m))
+			for k, v := range m {
+				entry[k] = v
+			}
+			entry["id"] = registry.PrefixModelID(provider, id)
+			entry["type"] = provider
+			out = append(out, entry)
+		}
+	}
+	return out
+}
+
 func (h *BaseAPIHandler) GetAlt(c *gin.Context) string {
 	alt, hasAlt := c.GetQuery("alt")
 	if !hasAlt {
@@ -69,11 +239,37 @@ func (h *BaseAPIHandler) GetAlt(c *gin.Context) string {
 	return alt
 }
 
+// GetContextWithCancel derives the context for an upstream call. It is also
+// cancelled when the client goes away, so a disconnect aborts the upstream
+// request instead of letting it run to completion.
 func (h *BaseAPIHandler) GetContextWithCancel(handler interfaces.APIHandler, c *gin.Context, ctx context.Context) (context.Context, APIHandlerCancelFunc) {
 	newCtx, cancel := context.WithCancel(ctx)
+	stop := func() bool { return false }
+	if c != nil && c.Request != nil {
+		stop = context.AfterFunc(c.Request.Context(), cancel)
+	}
 	newCtx = context.WithValue(newCtx, ctxKeyGin, c)
+	if c != nil && c.Request != nil {
+		if spec, ok := provider.FaultSpecFromContext(c.Request.Context()); ok {
+			newCtx = provider.WithFaultSpec(newCtx, spec)
+		}
+		if requestID := c.GetString(log.RequestIDKey); requestID != "" {
+			newCtx = log.WithRequestID(newCtx, requestID)
+		}
+		if selector := h.Cfg.LabelSelector(c.GetString("apiKey")); len(selector) > 0 {
+			newCtx = provider.WithLabelSelector(newCtx, selector)
+		}
+		timeout, warning := parseRequestTimeout(c.GetHeader(TimeoutHeader), h.Cfg.RequestTimeoutLimit())
+		if warning != "" {
+			writeWarnings(newCtx, []string{warning})
+		}
+		if timeout > 0 {
+			newCtx = provider.WithRequestTimeout(newCtx, timeout)
+		}
+	}
 	newCtx = context.WithValue(newCtx, ctxKeyHandler, handler)
 	return newCtx, func(params ...any) {
+		stop()
 		if h.Cfg.RequestLog && len(params) == 1 {
 			switch data := params[0].(type) {
 			case []byte:
@@ -120,6 +316,10 @@ func buildRequestOpts(normalizedModel string, rawJSON []byte, metadata map[strin
 	// Clone once, share between req and opts
 	payload := cloneBytes(rawJSON)
 	meta := cloneMetadata(metadata)
+	if meta == nil {
+		// Executors record translation warnings here.
+		meta = make(map[string]any, 1)
+	}
 
 	sourceFormat := provider.Format(handlerType)
 
@@ -141,13 +341,15 @@ func buildRequestOpts(normalizedModel string, rawJSON []byte, metadata map[strin
 // extractErrorDetails extracts status code and headers from error interface
 func extractErrorDetails(err error) (int, http.Header) {
 	status := http.StatusInternalServerError
-	if se, ok := err.(interface{ StatusCode() int }); ok {
+	var se interface{ StatusCode() int }
+	if errors.As(err, &se) {
 		if code := se.StatusCode(); code > 0 {
 			status = code
 		}
 	}
 	var addon http.Header
-	if he, ok := err.(interface{ Headers() http.Header }); ok {
+	var he interface{ Headers() http.Header }
+	if errors.As(err, &he) {
 		if hdr := he.Headers(); hdr != nil {
 			addon = hdr.Clone()
 		}
@@ -156,39 +358,117 @@ func extractErrorDetails(err error) (int, http.Header) {
 }
 
 func (h *BaseAPIHandler) ExecuteWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte, alt string) ([]byte, *interfaces.ErrorMessage) {
-	providers, normalizedModel, metadata, errMsg := h.getRequestDetails(modelName)
+	run := func(ctx context.Context) ([]byte, callNotes, *interfaces.ErrorMessage) {
+		if h.Cfg != nil && h.Cfg.RequestDedup {
+			key := requestHash(ctx, handlerType, modelName, alt, rawJSON)
+			return h.flights.do(ctx, key, func(callCtx context.Context) ([]byte, callNotes, *interfaces.ErrorMessage) {
+				return h.execute(callCtx, handlerType, modelName, rawJSON, alt)
+			})
+		}
+		return h.execute(ctx, handlerType, modelName, rawJSON, alt)
+	}
+
+	var payload []byte
+	var notes callNotes
+	var errMsg *interfaces.ErrorMessage
+	scope, errMsg := h.idempotencyScope(ctx)
+	switch {
+	case errMsg != nil:
+	case scope != "":
+		fingerprint := requestHash(ctx, handlerType, modelName, alt, rawJSON)
+		ttl := time.Duration(h.Cfg.IdempotencyTTL) * time.Second
+		payload, notes, errMsg = h.idempotency.do(ctx, scope, fingerprint, ttl, run)
+	default:
+		payload, notes, errMsg = run(ctx)
+	}
 	if errMsg != nil {
 		return nil, errMsg
 	}
+	writeWarnings(ctx, notes.warnings)
+	writeServedModel(ctx, notes.servedModel)
+	return h.withBodyWarnings(payload, notes.warnings), nil
+}
+
+// ExecuteManyWithAuthManager runs count identical non-streaming requests in
+// parallel and returns their payloads in order. It fails with the first error
+// if any call fails.
+func (h *BaseAPIHandler) ExecuteManyWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte, alt string, count int) ([][]byte, *interfaces.ErrorMessage) {
+	payloads := make([][]byte, count)
+	notes := make([]callNotes, count)
+	errs := make([]*interfaces.ErrorMessage, count)
+	var wg sync.WaitGroup
+	wg.Add(count)
+	for i := range count {
+		go func() {
+			defer wg.Done()
+			payloads[i], notes[i], errs[i] = h.execute(ctx, handlerType, modelName, rawJSON, alt)
+		}()
+	}
+	wg.Wait()
+	for i := range count {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+	}
+	for i := range count {
+		writeWarnings(ctx, notes[i].warnings)
+		writeServedModel(ctx, notes[i].servedModel)
+		payloads[i] = h.withBodyWarnings(payloads[i], notes[i].warnings)
+	}
+	return payloads, nil
+}
+
+// execute runs a non-streaming request, falling back along the client's or
+// the model's fallback chain, and returns the payload with any translation warnings.
+func (h *BaseAPIHandler) execute(ctx context.Context, handlerType, modelName string, rawJSON []byte, alt string) ([]byte, callNotes, *interfaces.ErrorMessage) {
+	required := requiredCapabilities(rawJSON)
+	providers, normalizedModel, metadata, errMsg := h.getRequestDetails(modelName, required)
+	if errMsg != nil {
+		return nil, callNotes{}, errMsg
+	}
+	providers, authID, errMsg := h.applyRoutingOverride(ctx, providers, normalizedModel, required)
+	if errMsg != nil {
+		return nil, callNotes{}, errMsg
+	}
 	req, opts := buildRequestOpts(normalizedModel, rawJSON, metadata, handlerType, alt, false)
+	opts.AuthID = authID
+	opts.Required = required
 	resp, err := h.AuthManager.Execute(ctx, providers, req, opts)
 	if err == nil {
-		return resp.Payload, nil
+		return resp.Payload, callNotes{warnings: provider.Warnings(req.Metadata)}, nil
 	}
 
-	fallbacks := h.getFallbackChain(normalizedModel)
-	for _, fallbackModel := range fallbacks {
-		fbProviders, fbNormalizedModel, fbMetadata, _ := h.getRequestDetails(fallbackModel)
+	for _, fallbackModel := range h.fallbacksFor(ctx, normalizedModel) {
+		fbProviders, fbNormalizedModel, fbMetadata, _ := h.getRequestDetails(fallbackModel, required)
 		if len(fbProviders) == 0 {
 			continue
 		}
 		fbReq, fbOpts := buildRequestOpts(fbNormalizedModel, rawJSON, fbMetadata, handlerType, alt, false)
+		fbOpts.Required = required
 		fbResp, fbErr := h.AuthManager.Execute(ctx, fbProviders, fbReq, fbOpts)
 		if fbErr == nil {
-			return fbResp.Payload, nil
+			return fbResp.Payload, callNotes{
+				warnings:    append(provider.Warnings(fbReq.Metadata), fallbackWarning(normalizedModel, fbNormalizedModel)),
+				servedModel: fbNormalizedModel,
+			}, nil
 		}
 	}
 
 	status, addon := extractErrorDetails(err)
-	return nil, &interfaces.ErrorMessage{StatusCode: status, Error: err, Addon: addon}
+	return nil, callNotes{}, &interfaces.ErrorMessage{StatusCode: status, Error: err, Addon: addon}
 }
 
 func (h *BaseAPIHandler) ExecuteCountWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte, alt string) ([]byte, *interfaces.ErrorMessage) {
-	providers, normalizedModel, metadata, errMsg := h.getRequestDetails(modelName)
+	providers, normalizedModel, metadata, errMsg := h.getRequestDetails(modelName, 0)
+	if errMsg != nil {
+		return nil, errMsg
+	}
+	providers, authID, errMsg := h.applyRoutingOverride(ctx, providers, normalizedModel, 0)
 	if errMsg != nil {
 		return nil, errMsg
 	}
 	req, opts := buildRequestOpts(normalizedModel, rawJSON, metadata, handlerType, alt, false)
+	opts.AuthID = authID
 	resp, err := h.AuthManager.ExecuteCount(ctx, providers, req, opts)
 	if err != nil {
 		status, addon := extractErrorDetails(err)
@@ -197,8 +477,82 @@ func (h *BaseAPIHandler) ExecuteCountWithAuthManager(ctx context.Context, handle
 	return resp.Payload, nil
 }
 
+// ExecuteEmbedWithAuthManager runs an embeddings request on a provider that
+// supports embeddings for modelName.
+func (h *BaseAPIHandler) ExecuteEmbedWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte) ([]byte, *interfaces.ErrorMessage) {
+	providers, normalizedModel, metadata, errMsg := h.getRequestDetails(modelName, 0)
+	if errMsg != nil {
+		return nil, errMsg
+	}
+	providers, authID, errMsg := h.applyRoutingOverride(ctx, providers, normalizedModel, 0)
+	if errMsg != nil {
+		return nil, errMsg
+	}
+	req, opts := buildRequestOpts(normalizedModel, rawJSON, metadata, handlerType, "", false)
+	opts.AuthID = authID
+	resp, err := h.AuthManager.ExecuteEmbed(ctx, providers, req, opts)
+	if err != nil {
+		status, addon := extractErrorDetails(err)
+		return nil, &interfaces.ErrorMessage{StatusCode: status, Error: err, Addon: addon}
+	}
+	return resp.Payload, nil
+}
+
+// ExecuteRerankWithAuthManager runs a rerank request on a provider that
+// supports rerank for modelName.
+func (h *BaseAPIHandler) ExecuteRerankWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte) ([]byte, *interfaces.ErrorMessage) {
+	providers, normalizedModel, metadata, errMsg := h.getRequestDetails(modelName, 0)
+	if errMsg != nil {
+		return nil, errMsg
+	}
+	providers, authID, errMsg := h.applyRoutingOverride(ctx, providers, normalizedModel, 0)
+	if errMsg != nil {
+		return nil, errMsg
+	}
+	req, opts := buildRequestOpts(normalizedModel, rawJSON, metadata, handlerType, "", false)
+	opts.AuthID = authID
+	resp, err := h.AuthManager.ExecuteRerank(ctx, providers, req, opts)
+	if err != nil {
+		status, addon := extractErrorDetails(err)
+		return nil, &interfaces.ErrorMessage{StatusCode: status, Error: err, Addon: addon}
+	}
+	return resp.Payload, nil
+}
+
+// ExecuteModerateWithAuthManager runs a moderation request on the configured
+// moderation provider. It fails with 501 when none is configured.
+func (h *BaseAPIHandler) ExecuteModerateWithAuthManager(ctx context.Context, handlerType string, rawJSON []byte) ([]byte, *interfaces.ErrorMessage) {
+	var modCfg config.ModerationConfig
+	if h.Cfg != nil {
+		modCfg = h.Cfg.Moderation
+	}
+	providerName := strings.ToLower(strings.TrimSpace(modCfg.Provider))
+	if providerName == "" {
+		return nil, &interfaces.ErrorMessage{StatusCode: http.StatusNotImplemented, Error: fmt.Errorf("moderation is not configured; set moderation.provider")}
+	}
+	model := gjson.GetBytes(rawJSON, "model").String()
+	if model == "" {
+		model = modCfg.Model
+	}
+	req, opts := buildRequestOpts(model, rawJSON, nil, handlerType, "", false)
+	resp, err := h.AuthManager.ExecuteModerate(ctx, providerName, req, opts)
+	if err != nil {
+		status, addon := extractErrorDetails(err)
+		return nil, &interfaces.ErrorMessage{StatusCode: status, Error: err, Addon: addon}
+	}
+	return resp.Payload, nil
+}
+
 func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte, alt string) (<-chan []byte, <-chan *interfaces.ErrorMessage) {
-	providers, normalizedModel, metadata, errMsg := h.getRequestDetails(modelName)
+	required := requiredCapabilities(rawJSON)
+	providers, normalizedModel, metadata, errMsg := h.getRequestDetails(modelName, required)
+	if errMsg != nil {
+		errChan := make(chan *interfaces.ErrorMessage, 1)
+		errChan <- errMsg
+		close(errChan)
+		return nil, errChan
+	}
+	providers, authID, errMsg := h.applyRoutingOverride(ctx, providers, normalizedModel, required)
 	if errMsg != nil {
 		errChan := make(chan *interfaces.ErrorMessage, 1)
 		errChan <- errMsg
@@ -206,21 +560,26 @@ func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handl
 		return nil, errChan
 	}
 	req, opts := buildRequestOpts(normalizedModel, rawJSON, metadata, handlerType, alt, true)
+	opts.AuthID = authID
+	opts.Required = required
 	chunks, err := h.AuthManager.ExecuteStream(ctx, providers, req, opts)
 	if err == nil {
-		return h.wrapStreamChannel(chunks)
+		writeWarnings(ctx, provider.Warnings(req.Metadata))
+		return h.wrapStreamChannel(ctx, chunks)
 	}
 
-	fallbacks := h.getFallbackChain(normalizedModel)
-	for _, fallbackModel := range fallbacks {
-		fbProviders, fbNormalizedModel, fbMetadata, _ := h.getRequestDetails(fallbackModel)
+	for _, fallbackModel := range h.fallbacksFor(ctx, normalizedModel) {
+		fbProviders, fbNormalizedModel, fbMetadata, _ := h.getRequestDetails(fallbackModel, required)
 		if len(fbProviders) == 0 {
 			continue
 		}
 		fbReq, fbOpts := buildRequestOpts(fbNormalizedModel, rawJSON, fbMetadata, handlerType, alt, true)
+		fbOpts.Required = required
 		fbChunks, fbErr := h.AuthManager.ExecuteStream(ctx, fbProviders, fbReq, fbOpts)
 		if fbErr == nil {
-			return h.wrapStreamChannel(fbChunks)
+			writeWarnings(ctx, append(provider.Warnings(fbReq.Metadata), fallbackWarning(normalizedModel, fbNormalizedModel)))
+			writeServedModel(ctx, fbNormalizedModel)
+			return h.wrapStreamChannel(ctx, fbChunks)
 		}
 	}
 
@@ -231,7 +590,9 @@ func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handl
 	return nil, errChan
 }
 
-func (h *BaseAPIHandler) wrapStreamChannel(chunks <-chan provider.StreamChunk) (<-chan []byte, <-chan *interfaces.ErrorMessage) {
+// wrapStreamChannel adapts provider chunks for the handlers. It stops when
+// ctx is cancelled so a handler that has gone away does not strand it.
+func (h *BaseAPIHandler) wrapStreamChannel(ctx context.Context, chunks <-chan provider.StreamChunk) (<-chan []byte, <-chan *interfaces.ErrorMessage) {
 	dataChan := make(chan []byte, 8) // Buffered to reduce blocking
 	errChan := make(chan *interfaces.ErrorMessage, 1)
 	go func() {
@@ -267,23 +628,51 @@ func (h *BaseAPIHandler) wrapStreamChannel(chunks <-chan provider.StreamChunk) (
 					errChan <- &interfaces.ErrorMessage{StatusCode: status, Error: err, Addon: addon}
 					return
 				}
-				dataChan <- chunk.Payload // No clone needed, executor already owns this
+				select {
+				case dataChan <- chunk.Payload: // No clone needed, executor already owns this
+				case <-ctx.Done():
+					return
+				}
 			}
 		}
 	}()
 	return dataChan, errChan
 }
 
-func (h *BaseAPIHandler) getRequestDetails(modelName string) (providers []string, normalizedModel string, metadata map[string]any, err *interfaces.ErrorMessage) {
+// getRequestDetails resolves the model name to the ordered providers that can serve it.
+// For model families, members lacking a capability in required are skipped.
+func (h *BaseAPIHandler) getRequestDetails(modelName string, required registry.Capability) (providers []string, normalizedModel string, metadata map[string]any, err *interfaces.ErrorMessage) {
 	resolvedModelName := util.ResolveAutoModel(modelName)
 	specifiedProvider := util.ExtractProviderFromPrefixedModelID(resolvedModelName)
 	cleanModelName := util.NormalizeIncomingModelID(resolvedModelName)
 
-	if h.Routing != nil {
-		cleanModelName = h.Routing.ResolveModelAlias(cleanModelName)
+	aliased := false
+	resolveAlias := func() {
+		if h.Routing == nil {
+			return
+		}
+		if resolved := h.Routing.ResolveModelAlias(cleanModelName); resolved != cleanModelName {
+			log.Debugf("model alias %s resolved to %s", cleanModelName, resolved)
+			cleanModelName = resolved
+			aliased = true
+		}
+	}
+	resolveAlias()
+
+	// A "provider/model" ID, given directly or as an alias target, pins the
+	// request to provider; the model part may itself be an alias.
+	pinnedProvider := ""
+	if p, m, ok := registry.GetGlobalRegistry().SplitProviderPrefix(cleanModelName); ok {
+		pinnedProvider, cleanModelName = p, m
+		if !aliased {
+			resolveAlias()
+		}
 	}
 
 	providerName, extractedModelName, isDynamic := h.parseDynamicModel(cleanModelName)
+	if !isDynamic && aliased {
+		providerName, extractedModelName, isDynamic = config.SplitAliasTarget(cleanModelName)
+	}
 	normalizedModel, metadata = util.NormalizeGeminiThinkingModel(cleanModelName)
 
 	if isDynamic {
@@ -294,15 +683,85 @@ func (h *BaseAPIHandler) getRequestDetails(modelName string) (providers []string
 	} else {
 		// GetProviderName uses canonical index for cross-provider routing
 		// Translation happens in executeWithProvider via GetModelIDForProvider
-		providers = util.GetProviderName(normalizedModel)
+		providers, normalizedModel = resolveModelProviders(normalizedModel)
+		if required != 0 && len(providers) > 0 && registry.IsCanonicalID(normalizedModel) {
+			members, errFamily := registry.ResolveModelFamily(normalizedModel, required)
+			if errFamily != nil {
+				return nil, "", nil, &interfaces.ErrorMessage{StatusCode: http.StatusBadRequest, Error: errFamily}
+			}
+			providers = filterProvidersByMembers(providers, members)
+			if len(providers) == 0 {
+				return nil, "", nil, &interfaces.ErrorMessage{StatusCode: http.StatusBadRequest, Error: &registry.CapabilityError{Family: normalizedModel, Required: required}}
+			}
+		}
 	}
 
+	if len(providers) == 0 && pinnedProvider == "" {
+		if p, m, ok := h.Routing.CatchAll(normalizedModel); ok {
+			log.Debugf("unknown model %s routed to catch-all provider %s as %s", modelName, p, m)
+			providers, normalizedModel = []string{p}, m
+			metadata = provider.MarkCatchAll(metadata)
+		}
+	}
 	if len(providers) == 0 {
 		return nil, "", nil, &interfaces.ErrorMessage{StatusCode: http.StatusBadRequest, Error: fmt.Errorf("unknown provider for model %s", modelName)}
 	}
+	if pinnedProvider != "" {
+		if !slices.Contains(providers, pinnedProvider) {
+			return nil, "", nil, &interfaces.ErrorMessage{StatusCode: http.StatusBadRequest, Error: fmt.Errorf("provider %s does not serve model %s", pinnedProvider, normalizedModel)}
+		}
+		providers = []string{pinnedProvider}
+	}
 	return providers, normalizedModel, metadata, nil
 }
 
+// filterProvidersByMembers keeps the providers that host one of members, preserving order.
+func filterProvidersByMembers(providers []string, members []registry.FamilyMember) []string {
+	allowed := make(map[string]struct{}, len(members))
+	for _, m := range members {
+		allowed[m.Provider] = struct{}{}
+	}
+	out := make([]string, 0, len(providers))
+	for _, p := range providers {
+		if _, ok := allowed[p]; ok {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// resolveModelProviders returns the providers that can serve model. A
+// provider-specific member ID of a model family, such as an Antigravity or
+// Kiro ID, is routed as its family: the request keeps working when the
+// member's own provider is unavailable, and each provider is sent its own ID
+// for the family. The member's own providers are listed ahead of the rest of
+// the family.
+func resolveModelProviders(model string) ([]string, string) {
+	providers := util.GetProviderName(model)
+	canonical := registry.GetCanonicalModelID(model)
+	if canonical == "" || canonical == model {
+		return providers, model
+	}
+	members, err := registry.ResolveModelFamily(canonical, 0)
+	if err != nil {
+		return providers, model
+	}
+	// A provider that hosts the same ID outside the family would be sent
+	// the wrong model if the request were routed as the family.
+	for _, p := range providers {
+		if !slices.ContainsFunc(members, func(m registry.FamilyMember) bool { return m.Provider == p && m.Model == model }) {
+			return providers, model
+		}
+	}
+	for _, p := range util.GetProviderName(canonical) {
+		if !slices.Contains(providers, p) {
+			providers = append(providers, p)
+		}
+	}
+	log.Debugf("model %s routed as family %s", model, canonical)
+	return providers, canonical
+}
+
 func (h *BaseAPIHandler) parseDynamicModel(modelName string) (providerName, model string, isDynamic bool) {
 	if parts := strings.SplitN(modelName, "://", 2); len(parts) == 2 {
 		for _, pName := range h.OpenAICompatProviders {
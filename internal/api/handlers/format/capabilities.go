@@ -0,0 +1,106 @@
+package format
+
+import (
+	"strings"
+
+	"github.com/nghyane/llm-mux/internal/registry"
+	"github.com/tidwall/gjson"
+)
+
+// requiredCapabilities inspects a request body in any supported source format
+// (OpenAI chat/responses, Claude, Gemini) and returns the features it relies on.
+func requiredCapabilities(rawJSON []byte) registry.Capability {
+	if len(rawJSON) == 0 {
+		return 0
+	}
+	root := gjson.ParseBytes(rawJSON)
+	var caps registry.Capability
+
+	if hasImageInput(root) {
+		caps |= registry.CapVision
+	}
+	if tools := root.Get("tools"); tools.IsArray() && len(tools.Array()) > 0 {
+		caps |= registry.CapTools
+	}
+	if root.Get("response_format.type").String() == "json_schema" ||
+		root.Get("text.format.type").String() == "json_schema" ||
+		root.Get("generationConfig.responseSchema").Exists() ||
+		root.Get("generationConfig.responseJsonSchema").Exists() {
+		caps |= registry.CapJSONSchema
+	}
+	if wantsThinking(root) {
+		caps |= registry.CapThinking
+	}
+	return caps
+}
+
+func hasImageInput(root gjson.Result) bool {
+	found := false
+	// OpenAI chat and Claude messages
+	root.Get("messages").ForEach(func(_, msg gjson.Result) bool {
+		msg.Get("content").ForEach(func(_, part gjson.Result) bool {
+			switch part.Get("type").String() {
+			case "image_url", "image", "input_image":
+				found = true
+			}
+			return !found
+		})
+		return !found
+	})
+	if found {
+		return true
+	}
+	// OpenAI Responses input items
+	root.Get("input").ForEach(func(_, item gjson.Result) bool {
+		if item.Get("type").String() == "input_image" {
+			found = true
+			return false
+		}
+		item.Get("content").ForEach(func(_, part gjson.Result) bool {
+			if part.Get("type").String() == "input_image" {
+				found = true
+			}
+			return !found
+		})
+		return !found
+	})
+	if found {
+		return true
+	}
+	// Gemini contents
+	root.Get("contents").ForEach(func(_, content gjson.Result) bool {
+		content.Get("parts").ForEach(func(_, part gjson.Result) bool {
+			for _, key := range [...]string{"inlineData", "inline_data", "fileData", "file_data"} {
+				data := part.Get(key)
+				if !data.Exists() {
+					continue
+				}
+				mime := data.Get("mimeType").String()
+				if mime == "" {
+					mime = data.Get("mime_type").String()
+				}
+				if strings.HasPrefix(mime, "image/") {
+					found = true
+					return false
+				}
+			}
+			return true
+		})
+		return !found
+	})
+	return found
+}
+
+func wantsThinking(root gjson.Result) bool {
+	if root.Get("thinking.type").String() == "enabled" {
+		return true
+	}
+	if effort := root.Get("reasoning_effort").String(); effort != "" && effort != "none" {
+		return true
+	}
+	if effort := root.Get("reasoning.effort").String(); effort != "" && effort != "none" {
+		return true
+	}
+	thinkingCfg := root.Get("generationConfig.thinkingConfig")
+	return thinkingCfg.Get("includeThoughts").Bool() || thinkingCfg.Get("thinkingBudget").Int() > 0
+}
@@ -0,0 +1,175 @@
+package format
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/registry"
+	"github.com/tidwall/gjson"
+)
+
+func TestRequiredCapabilities(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want registry.Capability
+	}{
+		{"plain text", `{"messages":[{"role":"user","content":"hi"}]}`, 0},
+		{"openai image", `{"messages":[{"role":"user","content":[{"type":"image_url","image_url":{"url":"data:image/png;base64,AA"}}]}]}`, registry.CapVision},
+		{"claude image", `{"messages":[{"role":"user","content":[{"type":"image","source":{"type":"base64"}}]}]}`, registry.CapVision},
+		{"gemini image", `{"contents":[{"parts":[{"inlineData":{"mimeType":"image/jpeg","data":"AA"}}]}]}`, registry.CapVision},
+		{"gemini pdf", `{"contents":[{"parts":[{"inlineData":{"mimeType":"application/pdf","data":"AA"}}]}]}`, 0},
+		{"tools", `{"tools":[{"type":"function","function":{"name":"f"}}]}`, registry.CapTools},
+		{"json schema", `{"response_format":{"type":"json_schema","json_schema":{}}}`, registry.CapJSONSchema},
+		{"thinking", `{"thinking":{"type":"enabled","budget_tokens":1024}}`, registry.CapThinking},
+		{"reasoning none", `{"reasoning_effort":"none"}`, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := requiredCapabilities([]byte(tt.body)); got != tt.want {
+				t.Errorf("requiredCapabilities() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImageRequestRoutedAwayFromTextOnlyMember(t *testing.T) {
+	reg := registry.GetGlobalRegistry()
+	reg.RegisterClient("cap-test-text", "qwen", []*registry.ModelInfo{
+		{ID: "cap-test-text-model", Capabilities: registry.CapTools},
+	})
+	reg.RegisterClient("cap-test-vision", "iflow", []*registry.ModelInfo{
+		{ID: "cap-test-vision-model", Capabilities: registry.CapVision | registry.CapTools},
+	})
+	defer reg.UnregisterClient("cap-test-text")
+	defer reg.UnregisterClient("cap-test-vision")
+
+	members := []registry.FamilyMember{
+		{Provider: "qwen", Model: "cap-test-text-model"},
+		{Provider: "iflow", Model: "cap-test-vision-model"},
+	}
+	if err := registry.RegisterModelFamily("cap-test-family", members); err != nil {
+		t.Fatalf("register family: %v", err)
+	}
+	defer registry.UnregisterModelFamily("cap-test-family")
+
+	h := &BaseAPIHandler{}
+
+	providers, _, _, errMsg := h.getRequestDetails("cap-test-family", 0)
+	if errMsg != nil || len(providers) != 2 || providers[0] != "qwen" {
+		t.Fatalf("text request: providers=%v err=%v", providers, errMsg)
+	}
+
+	providers, _, _, errMsg = h.getRequestDetails("cap-test-family", registry.CapVision)
+	if errMsg != nil {
+		t.Fatalf("image request: unexpected error %v", errMsg.Error)
+	}
+	if len(providers) != 1 || providers[0] != "iflow" {
+		t.Fatalf("image request: expected [iflow], got %v", providers)
+	}
+
+	_, _, _, errMsg = h.getRequestDetails("cap-test-family", registry.CapVision|registry.CapJSONSchema)
+	if errMsg == nil {
+		t.Fatal("expected error when no member supports the request")
+	}
+	var capErr *registry.CapabilityError
+	if errMsg.StatusCode != http.StatusBadRequest || !errors.As(errMsg.Error, &capErr) {
+		t.Fatalf("expected 400 capability error, got %d %v", errMsg.StatusCode, errMsg.Error)
+	}
+}
+
+func TestMemberModelIDRoutedAsFamily(t *testing.T) {
+	members := []registry.FamilyMember{
+		{Provider: "claude", Model: "xm-test-claude"},
+		{Provider: "antigravity", Model: "gemini-xm-test-claude"},
+	}
+	if err := registry.RegisterModelFamily("xm-test-family", members); err != nil {
+		t.Fatalf("register family: %v", err)
+	}
+	defer registry.UnregisterModelFamily("xm-test-family")
+	reg := registry.GetGlobalRegistry()
+	reg.RegisterClient("xm-test-claude", "claude", []*registry.ModelInfo{{ID: "xm-test-claude"}})
+	defer reg.UnregisterClient("xm-test-claude")
+	h := &BaseAPIHandler{}
+
+	// The Antigravity ID still works while only Claude is available.
+	providers, model, _, errMsg := h.getRequestDetails("gemini-xm-test-claude", 0)
+	if errMsg != nil || len(providers) != 1 || providers[0] != "claude" || model != "xm-test-family" {
+		t.Fatalf("antigravity ID without antigravity: providers=%v model=%s err=%v", providers, model, errMsg)
+	}
+	if got := reg.GetModelIDForProvider(model, "claude", 0); got != "xm-test-claude" {
+		t.Errorf("claude is sent %s, want xm-test-claude", got)
+	}
+
+	reg.RegisterClient("xm-test-antigravity", "antigravity", []*registry.ModelInfo{{ID: "gemini-xm-test-claude"}})
+	defer reg.UnregisterClient("xm-test-antigravity")
+	providers, model, _, _ = h.getRequestDetails("gemini-xm-test-claude", 0)
+	if len(providers) != 2 || providers[0] != "antigravity" || model != "xm-test-family" {
+		t.Errorf("member ID with its provider available: providers=%v model=%s, want antigravity first", providers, model)
+	}
+	providers, model, _, _ = h.getRequestDetails("xm-test-claude", 0)
+	if len(providers) != 2 || providers[0] != "claude" || model != "xm-test-family" {
+		t.Errorf("claude member ID: providers=%v model=%s, want claude first", providers, model)
+	}
+
+	// A provider serving the same ID outside the family keeps the ID as sent.
+	reg.RegisterClient("xm-test-other", "qwen", []*registry.ModelInfo{{ID: "xm-test-claude"}})
+	defer reg.UnregisterClient("xm-test-other")
+	if _, model, _, _ = h.getRequestDetails("xm-test-claude", 0); model != "xm-test-claude" {
+		t.Errorf("ID hosted outside the family routed as %s", model)
+	}
+}
+
+func TestImageRequestPicksCapableMemberOfProvider(t *testing.T) {
+	reg := registry.GetGlobalRegistry()
+	reg.RegisterClient("cap-pick-auth", "cap-pick", []*registry.ModelInfo{
+		{ID: "cap-pick-text", Capabilities: registry.CapTools},
+		{ID: "cap-pick-vision", Capabilities: registry.CapVision | registry.CapTools},
+	})
+	defer reg.UnregisterClient("cap-pick-auth")
+	members := []registry.FamilyMember{
+		{Provider: "cap-pick", Model: "cap-pick-text"},
+		{Provider: "cap-pick", Model: "cap-pick-vision"},
+	}
+	if err := registry.RegisterModelFamily("cap-pick-family", members); err != nil {
+		t.Fatalf("register family: %v", err)
+	}
+	defer registry.UnregisterModelFamily("cap-pick-family")
+
+	manager := provider.NewManager(nil, nil, nil)
+	manager.RegisterExecutor(fallbackExecutor{id: "cap-pick"})
+	if _, err := manager.Register(context.Background(), &provider.Auth{ID: "cap-pick-auth", Provider: "cap-pick"}); err != nil {
+		t.Fatal(err)
+	}
+	h := &BaseAPIHandler{Cfg: &config.SDKConfig{}, AuthManager: manager}
+
+	const (
+		textBody  = `{"messages":[{"role":"user","content":"hi"}]}`
+		imageBody = `{"messages":[{"role":"user","content":[{"type":"image_url","image_url":{"url":"data:image/png;base64,AA"}}]}]}`
+	)
+	for body, want := range map[string]string{textBody: "cap-pick-text", imageBody: "cap-pick-vision"} {
+		payload, _, errMsg := h.execute(context.Background(), "openai", "cap-pick-family", []byte(body), "")
+		if errMsg != nil {
+			t.Fatalf("execute: %v", errMsg.Error)
+		}
+		if got := gjson.GetBytes(payload, "model").String(); got != want {
+			t.Errorf("%s was sent to %s, want %s", body, got, want)
+		}
+		chunks, errs := h.ExecuteStreamWithAuthManager(context.Background(), "openai", "cap-pick-family", []byte(body), "")
+		var streamed []byte
+		for chunk := range chunks {
+			streamed = append(streamed, chunk...)
+		}
+		if errMsg = <-errs; errMsg != nil {
+			t.Fatalf("stream: %v", errMsg.Error)
+		}
+		if !strings.Contains(string(streamed), `"model":"`+want+`"`) {
+			t.Errorf("%s was streamed from %s, want %s", body, streamed, want)
+		}
+	}
+}
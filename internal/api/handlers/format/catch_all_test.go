@@ -0,0 +1,71 @@
+package format
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/registry"
+	"github.com/tidwall/gjson"
+)
+
+// newCatchAllHandler returns a handler whose catchall provider has one
+// account listing only catchall-listed-model.
+func newCatchAllHandler(t *testing.T, route config.UnknownModelRoute) *BaseAPIHandler {
+	t.Helper()
+	reg := registry.GetGlobalRegistry()
+	reg.RegisterClient("catchall-auth", "catchall", []*registry.ModelInfo{{ID: "catchall-listed-model"}})
+	t.Cleanup(func() { reg.UnregisterClient("catchall-auth") })
+	manager := provider.NewManager(nil, nil, nil)
+	manager.RegisterExecutor(fallbackExecutor{id: "catchall"})
+	if _, err := manager.Register(context.Background(), &provider.Auth{ID: "catchall-auth", Provider: "catchall"}); err != nil {
+		t.Fatal(err)
+	}
+	routing := &config.RoutingConfig{UnknownModels: route}
+	routing.Init()
+	return &BaseAPIHandler{Cfg: &config.SDKConfig{}, AuthManager: manager, Routing: routing}
+}
+
+func TestUnknownModel_StrictByDefault(t *testing.T) {
+	h := newCatchAllHandler(t, config.UnknownModelRoute{})
+	_, _, errMsg := h.execute(context.Background(), "openai", "catchall-new-model", []byte(`{}`), "")
+	if errMsg == nil || errMsg.StatusCode != http.StatusBadRequest {
+		t.Fatalf("unknown model without a catch-all: %+v, want 400", errMsg)
+	}
+}
+
+func TestUnknownModel_CatchAll(t *testing.T) {
+	tests := []struct {
+		name  string
+		route config.UnknownModelRoute
+		want  string
+	}{
+		{"passthrough", config.UnknownModelRoute{Provider: "CatchAll"}, "catchall-new-model"},
+		{"replaced", config.UnknownModelRoute{Provider: "catchall", Model: "catchall-default"}, "catchall-default"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newCatchAllHandler(t, tt.route)
+			payload, _, errMsg := h.execute(context.Background(), "openai", "catchall-new-model", []byte(`{}`), "")
+			if errMsg != nil {
+				t.Fatalf("unknown model: %v", errMsg.Error)
+			}
+			if got := gjson.GetBytes(payload, "model").String(); got != tt.want {
+				t.Errorf("catch-all served %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnknownModel_KnownModelsKeepTheirRoute(t *testing.T) {
+	h := newCatchAllHandler(t, config.UnknownModelRoute{Provider: "elsewhere"})
+	providers, model, metadata, errMsg := h.getRequestDetails("catchall-listed-model", 0)
+	if errMsg != nil {
+		t.Fatal(errMsg.Error)
+	}
+	if len(providers) != 1 || providers[0] != "catchall" || model != "catchall-listed-model" || provider.IsCatchAll(metadata) {
+		t.Errorf("listed model routed to %v as %s (catch-all %t)", providers, model, provider.IsCatchAll(metadata))
+	}
+}
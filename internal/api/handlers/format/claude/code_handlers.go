@@ -12,7 +12,6 @@ import (
 	"github.com/nghyane/llm-mux/internal/api/handlers/format"
 	"github.com/nghyane/llm-mux/internal/constant"
 	"github.com/nghyane/llm-mux/internal/interfaces"
-	"github.com/nghyane/llm-mux/internal/json"
 	"github.com/nghyane/llm-mux/internal/registry"
 	"github.com/nghyane/llm-mux/internal/runtime/executor"
 	log "github.com/nghyane/llm-mux/internal/logging"
@@ -78,7 +77,7 @@ func (h *ClaudeCodeAPIHandler) ClaudeCountTokens(c *gin.Context) {
 
 	resp, errMsg := h.ExecuteCountWithAuthManager(cliCtx, h.HandlerType(), modelName, rawJSON, alt)
 	if errMsg != nil {
-		h.WriteErrorResponse(c, errMsg)
+		h.WriteAnthropicErrorResponse(c, errMsg)
 		cliCancel(errMsg.Error)
 		return
 	}
@@ -88,7 +87,7 @@ func (h *ClaudeCodeAPIHandler) ClaudeCountTokens(c *gin.Context) {
 
 func (h *ClaudeCodeAPIHandler) ClaudeModels(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
-		"data": h.Models(),
+		"data": h.ApplyModelPrefixes(h.ApplyModelListing(h.Models())),
 	})
 }
 
@@ -101,7 +100,7 @@ func (h *ClaudeCodeAPIHandler) handleNonStreamingResponse(c *gin.Context, rawJSO
 
 	resp, errMsg := h.ExecuteWithAuthManager(cliCtx, h.HandlerType(), modelName, rawJSON, alt)
 	if errMsg != nil {
-		h.WriteErrorResponse(c, errMsg)
+		h.WriteAnthropicErrorResponse(c, errMsg)
 		cliCancel(errMsg.Error)
 		return
 	}
@@ -154,6 +153,8 @@ func (h *ClaudeCodeAPIHandler) handleStreamingResponse(c *gin.Context, rawJSON [
 }
 
 func (h *ClaudeCodeAPIHandler) forwardClaudeStream(c *gin.Context, flusher http.Flusher, cancel func(error), data <-chan []byte, errs <-chan *interfaces.ErrorMessage) {
+	keepAlive := h.NewStreamKeepAlive()
+	defer keepAlive.Stop()
 	for {
 		select {
 		case <-c.Request.Context().Done():
@@ -168,19 +169,18 @@ func (h *ClaudeCodeAPIHandler) forwardClaudeStream(c *gin.Context, flusher http.
 			if len(chunk) > 0 {
 				_, _ = c.Writer.Write(chunk)
 				flusher.Flush()
+				keepAlive.Touch()
 			}
 
+		case <-keepAlive.C():
+			keepAlive.Write(c.Writer, flusher)
+
 		case errMsg, ok := <-errs:
 			if !ok {
 				continue
 			}
 			if errMsg != nil {
-				// An error occurred: emit as a proper SSE error event
-				errorBytes, _ := json.Marshal(h.toClaudeError(errMsg))
-				_, _ = c.Writer.WriteString("event: error\n")
-				_, _ = c.Writer.WriteString("data: ")
-				_, _ = c.Writer.Write(errorBytes)
-				_, _ = c.Writer.WriteString("\n\n")
+				h.WriteAnthropicErrorResponse(c, errMsg)
 				flusher.Flush()
 			}
 			var execErr error
@@ -192,23 +192,3 @@ func (h *ClaudeCodeAPIHandler) forwardClaudeStream(c *gin.Context, flusher http.
 		}
 	}
 }
-
-type claudeErrorDetail struct {
-	Type    string `json:"type"`
-	Message string `json:"message"`
-}
-
-type claudeErrorResponse struct {
-	Type  string            `json:"type"`
-	Error claudeErrorDetail `json:"error"`
-}
-
-func (h *ClaudeCodeAPIHandler) toClaudeError(msg *interfaces.ErrorMessage) claudeErrorResponse {
-	return claudeErrorResponse{
-		Type: "error",
-		Error: claudeErrorDetail{
-			Type:    "api_error",
-			Message: msg.Error.Error(),
-		},
-	}
-}
@@ -0,0 +1,60 @@
+package format
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// FallbackModelsHeader lists, comma-separated and in order, the models a
+	// client accepts when the requested one cannot be served.
+	FallbackModelsHeader = "X-LLM-Mux-Fallback-Models"
+	// ServedModelHeader names the fallback model that served a request.
+	ServedModelHeader = "X-LLM-Mux-Model"
+)
+
+// maxClientFallbacks caps how many client fallback models one request tries.
+const maxClientFallbacks = 5
+
+// clientFallbacksFrom reads the fallback models the client listed for model,
+// without duplicates or model itself.
+func clientFallbacksFrom(ctx context.Context, model string) []string {
+	c, ok := ctx.Value(ctxKeyGin).(*gin.Context)
+	if !ok || c == nil || c.Request == nil {
+		return nil
+	}
+	var models []string
+	for _, value := range c.Request.Header.Values(FallbackModelsHeader) {
+		for _, m := range strings.Split(value, ",") {
+			m = strings.TrimSpace(m)
+			if m == "" || strings.EqualFold(m, model) || slices.ContainsFunc(models, func(v string) bool { return strings.EqualFold(v, m) }) {
+				continue
+			}
+			if len(models) == maxClientFallbacks {
+				return models
+			}
+			models = append(models, m)
+		}
+	}
+	return models
+}
+
+// fallbackWarning records that served answered a request for model.
+func fallbackWarning(model, served string) string {
+	return fmt.Sprintf("served by fallback model %s because %s was unavailable", served, model)
+}
+
+// writeServedModel names the fallback model that served the request in
+// ServedModelHeader. It must run before the response body is written.
+func writeServedModel(ctx context.Context, model string) {
+	if model == "" {
+		return
+	}
+	if c, ok := ctx.Value(ctxKeyGin).(*gin.Context); ok && c != nil {
+		c.Writer.Header().Set(ServedModelHeader, model)
+	}
+}
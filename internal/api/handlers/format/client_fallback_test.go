@@ -0,0 +1,169 @@
+package format
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/registry"
+)
+
+// fallbackExecutor serves its provider's model, or fails every call with 503
+// when down.
+type fallbackExecutor struct {
+	id   string
+	down bool
+}
+
+func (e fallbackExecutor) Identifier() string { return e.id }
+
+func (e fallbackExecutor) Execute(_ context.Context, _ *provider.Auth, req provider.Request, _ provider.Options) (provider.Response, error) {
+	if e.down {
+		return provider.Response{}, &provider.Error{Code: "unavailable", Message: e.id + " is down", HTTPStatus: http.StatusServiceUnavailable}
+	}
+	return provider.Response{Payload: []byte(`{"model":"` + req.Model + `"}`)}, nil
+}
+
+func (e fallbackExecutor) ExecuteStream(_ context.Context, _ *provider.Auth, req provider.Request, _ provider.Options) (<-chan provider.StreamChunk, error) {
+	if e.down {
+		return nil, &provider.Error{Code: "unavailable", Message: e.id + " is down", HTTPStatus: http.StatusServiceUnavailable}
+	}
+	ch := make(chan provider.StreamChunk, 1)
+	ch <- provider.StreamChunk{Payload: []byte(`data: {"model":"` + req.Model + `"}` + "\n\n")}
+	close(ch)
+	return ch, nil
+}
+
+func (fallbackExecutor) Refresh(_ context.Context, auth *provider.Auth) (*provider.Auth, error) {
+	return auth, nil
+}
+
+func (fallbackExecutor) CountTokens(context.Context, *provider.Auth, provider.Request, provider.Options) (provider.Response, error) {
+	return provider.Response{}, errors.New("not implemented")
+}
+
+func newFallbackHandler(t *testing.T) *BaseAPIHandler {
+	t.Helper()
+	reg := registry.GetGlobalRegistry()
+	manager := provider.NewManager(nil, nil, nil)
+	for _, p := range []struct {
+		provider, model string
+		down            bool
+	}{
+		{"fb-primary", "fb-primary-model", true},
+		{"fb-alt", "fb-alt-model", false},
+		{"fb-other", "fb-other-model", false},
+	} {
+		reg.RegisterClient(p.provider+"-auth", p.provider, []*registry.ModelInfo{{ID: p.model}})
+		t.Cleanup(func() { reg.UnregisterClient(p.provider + "-auth") })
+		manager.RegisterExecutor(fallbackExecutor{id: p.provider, down: p.down})
+		if _, err := manager.Register(context.Background(), &provider.Auth{ID: p.provider + "-auth", Provider: p.provider}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return &BaseAPIHandler{Cfg: &config.SDKConfig{}, AuthManager: manager}
+}
+
+func fallbackContext(fallbacks string) (context.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	if fallbacks != "" {
+		c.Request.Header.Set(FallbackModelsHeader, fallbacks)
+	}
+	return context.WithValue(context.Background(), ctxKeyGin, c), w
+}
+
+func TestClientFallback_PrimaryUnavailable(t *testing.T) {
+	h := newFallbackHandler(t)
+	body := []byte(`{"model":"fb-primary-model"}`)
+
+	// Unknown models in the list are skipped.
+	ctx, w := fallbackContext("no-such-model, fb-alt-model, fb-other-model")
+	payload, errMsg := h.ExecuteWithAuthManager(ctx, "openai", "fb-primary-model", body, "")
+	if errMsg != nil {
+		t.Fatalf("execute: %v", errMsg.Error)
+	}
+	if string(payload) != `{"model":"fb-alt-model"}` {
+		t.Errorf("payload = %s, want the first listed alternative", payload)
+	}
+	if got := w.Header().Get(ServedModelHeader); got != "fb-alt-model" {
+		t.Errorf("%s = %q, want fb-alt-model", ServedModelHeader, got)
+	}
+	if want := fallbackWarning("fb-primary-model", "fb-alt-model"); !slices.Contains(w.Header().Values(WarningsHeader), want) {
+		t.Errorf("%s = %q, want %q", WarningsHeader, w.Header().Values(WarningsHeader), want)
+	}
+
+	ctx, w = fallbackContext("fb-other-model")
+	chunks, errs := h.ExecuteStreamWithAuthManager(ctx, "openai", "fb-primary-model", body, "")
+	var got []byte
+	for chunk := range chunks {
+		got = append(got, chunk...)
+	}
+	if errMsg = <-errs; errMsg != nil {
+		t.Fatalf("stream: %v", errMsg.Error)
+	}
+	if string(got) != "data: {\"model\":\"fb-other-model\"}\n\n" || w.Header().Get(ServedModelHeader) != "fb-other-model" {
+		t.Errorf("stream served %q with %s %q, want fb-other-model", got, ServedModelHeader, w.Header().Get(ServedModelHeader))
+	}
+}
+
+func TestClientFallback_NotUsedWhenPrimaryServes(t *testing.T) {
+	h := newFallbackHandler(t)
+	ctx, w := fallbackContext("fb-other-model")
+	payload, errMsg := h.ExecuteWithAuthManager(ctx, "openai", "fb-alt-model", []byte(`{"model":"fb-alt-model"}`), "")
+	if errMsg != nil {
+		t.Fatalf("execute: %v", errMsg.Error)
+	}
+	if string(payload) != `{"model":"fb-alt-model"}` || w.Header().Get(ServedModelHeader) != "" {
+		t.Errorf("payload %s with %s %q, want the primary without the header", payload, ServedModelHeader, w.Header().Get(ServedModelHeader))
+	}
+}
+
+func TestClientFallback_AllUnavailable(t *testing.T) {
+	h := newFallbackHandler(t)
+	ctx, _ := fallbackContext("no-such-model")
+	_, errMsg := h.ExecuteWithAuthManager(ctx, "openai", "fb-primary-model", []byte(`{"model":"fb-primary-model"}`), "")
+	if errMsg == nil || errMsg.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("err = %v, want the primary's 503", errMsg)
+	}
+}
+
+// The served model is reported as recorded, not parsed back out of the
+// warning, so any model ID survives, including through deduplication.
+func TestClientFallback_ServedModelHeaderKeepsModelID(t *testing.T) {
+	const odd = "fb odd because model"
+	reg := registry.GetGlobalRegistry()
+	reg.RegisterClient("fb-odd-auth", "fb-odd", []*registry.ModelInfo{{ID: odd}})
+	defer reg.UnregisterClient("fb-odd-auth")
+	h := newFallbackHandler(t)
+	h.AuthManager.RegisterExecutor(fallbackExecutor{id: "fb-odd"})
+	if _, err := h.AuthManager.Register(context.Background(), &provider.Auth{ID: "fb-odd-auth", Provider: "fb-odd"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, dedup := range []bool{false, true} {
+		h.Cfg.RequestDedup = dedup
+		ctx, w := fallbackContext(odd)
+		if _, errMsg := h.ExecuteWithAuthManager(ctx, "openai", "fb-primary-model", []byte(`{"model":"fb-primary-model"}`), ""); errMsg != nil {
+			t.Fatalf("dedup %t: %v", dedup, errMsg.Error)
+		}
+		if got := w.Header().Get(ServedModelHeader); got != odd {
+			t.Errorf("dedup %t: %s = %q, want %q", dedup, ServedModelHeader, got, odd)
+		}
+	}
+}
+
+func TestClientFallbacksFrom(t *testing.T) {
+	ctx, _ := fallbackContext(" b , a,B,,c,d,e,f,g")
+	got := clientFallbacksFrom(ctx, "a")
+	if want := []string{"b", "c", "d", "e", "f"}; !slices.Equal(got, want) {
+		t.Errorf("clientFallbacksFrom = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,333 @@
+package format
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/interfaces"
+	"github.com/nghyane/llm-mux/internal/json"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/tidwall/gjson"
+)
+
+// openAIErrorType returns the OpenAI error type for an HTTP status.
+func openAIErrorType(status int) string {
+	switch {
+	case status == http.StatusUnauthorized:
+		return "authentication_error"
+	case status == http.StatusForbidden:
+		return "permission_error"
+	case status == http.StatusNotFound:
+		return "not_found_error"
+	case status == http.StatusTooManyRequests:
+		return "rate_limit_error"
+	case status >= 500:
+		return "server_error"
+	default:
+		return "invalid_request_error"
+	}
+}
+
+// OpenAIErrorBody renders err as an OpenAI error envelope. Upstream bodies
+// already in the OpenAI shape are returned unchanged; Anthropic and Gemini
+// error JSON is mapped so the upstream message survives and the upstream
+// error kind (Anthropic error.type, Gemini error.status) becomes code.
+func OpenAIErrorBody(status int, err error) []byte {
+	detail := ErrorDetail{Type: openAIErrorType(status), Message: http.StatusText(status)}
+	if err != nil {
+		raw := err.Error()
+		// Prefer the upstream body carried by a wrapped status error over
+		// the prefixed message of its wrappers.
+		var perr *provider.Error
+		var se interface {
+			error
+			StatusCode() int
+		}
+		if errors.As(err, &perr) && perr != nil {
+			raw = perr.Message
+			detail.Code = perr.Code
+		} else if errors.As(err, &se) {
+			raw = se.Error()
+		}
+		raw = strings.TrimSpace(raw)
+		if body, ok := openAIErrorPassthrough(raw); ok {
+			return body
+		}
+		if !mapUpstreamError(raw, &detail) && raw != "" {
+			detail.Message = raw
+		}
+	}
+	out, _ := json.Marshal(ErrorResponse{Error: detail})
+	return out
+}
+
+// openAIErrorPassthrough reports whether raw is already an OpenAI error.
+func openAIErrorPassthrough(raw string) ([]byte, bool) {
+	if !gjson.Valid(raw) {
+		return nil, false
+	}
+	root := gjson.Parse(raw)
+	if root.Get("type").Exists() || root.Get("error.type").Type != gjson.String || root.Get("error.message").Type != gjson.String {
+		return nil, false
+	}
+	return []byte(raw), true
+}
+
+// mapUpstreamError fills detail from an Anthropic or Gemini error body.
+func mapUpstreamError(raw string, detail *ErrorDetail) bool {
+	if !gjson.Valid(raw) {
+		return false
+	}
+	root := gjson.Parse(raw)
+	// Gemini CLI and Vertex stream endpoints wrap the error in an array.
+	if root.IsArray() {
+		root = root.Get("0")
+	}
+	errObj := root.Get("error")
+	if !errObj.IsObject() {
+		return false
+	}
+	msg := errObj.Get("message").String()
+	if msg == "" {
+		return false
+	}
+	detail.Message = msg
+	switch {
+	case root.Get("type").String() == "error" && errObj.Get("type").Exists():
+		// Anthropic: {"type":"error","error":{"type":"...","message":"..."}}
+		detail.Code = errObj.Get("type").String()
+	case errObj.Get("status").Type == gjson.String:
+		// Gemini: {"error":{"code":400,"message":"...","status":"INVALID_ARGUMENT"}}
+		detail.Code = errObj.Get("status").String()
+	case errObj.Get("code").Type == gjson.String:
+		detail.Code = errObj.Get("code").String()
+	}
+	return true
+}
+
+// WriteOpenAIErrorResponse writes msg as an OpenAI error envelope, keeping the
+// upstream status code. Once a stream has started the envelope is sent as a
+// final SSE data event instead.
+func (h *BaseAPIHandler) WriteOpenAIErrorResponse(c *gin.Context, msg *interfaces.ErrorMessage) {
+	status := http.StatusInternalServerError
+	var err error
+	if msg != nil {
+		if msg.StatusCode > 0 {
+			status = msg.StatusCode
+		}
+		err = msg.Error
+	}
+	body := OpenAIErrorBody(status, err)
+	if c.Writer.Written() {
+		_, _ = c.Writer.Write([]byte("data: "))
+		_, _ = c.Writer.Write(body)
+		_, _ = c.Writer.Write([]byte("\n\n"))
+		return
+	}
+	if msg != nil {
+		for key, values := range msg.Addon {
+			if len(values) == 0 {
+				continue
+			}
+			c.Writer.Header().Del(key)
+			for _, value := range values {
+				c.Writer.Header().Add(key, value)
+			}
+		}
+	}
+	c.Header("Content-Type", "application/json")
+	c.Status(status)
+	_, _ = c.Writer.Write(body)
+}
+
+// anthropicErrorType returns the Anthropic error type for an HTTP status.
+func anthropicErrorType(status int) string {
+	switch {
+	case status == http.StatusUnauthorized:
+		return "authentication_error"
+	case status == http.StatusForbidden:
+		return "permission_error"
+	case status == http.StatusNotFound:
+		return "not_found_error"
+	case status == http.StatusRequestEntityTooLarge:
+		return "request_too_large"
+	case status == http.StatusTooManyRequests:
+		return "rate_limit_error"
+	case status == 529:
+		return "overloaded_error"
+	case status >= 500:
+		return "api_error"
+	default:
+		return "invalid_request_error"
+	}
+}
+
+// AnthropicErrorBody renders err as an Anthropic error envelope. Upstream
+// bodies already in the Anthropic shape are returned unchanged; OpenAI and
+// Gemini error JSON is mapped so the upstream message survives.
+func AnthropicErrorBody(status int, err error) []byte {
+	detail := ErrorDetail{Type: anthropicErrorType(status), Message: http.StatusText(status)}
+	if err != nil {
+		raw := err.Error()
+		var perr *provider.Error
+		var se interface {
+			error
+			StatusCode() int
+		}
+		if errors.As(err, &perr) && perr != nil {
+			raw = perr.Message
+		} else if errors.As(err, &se) {
+			raw = se.Error()
+		}
+		raw = strings.TrimSpace(raw)
+		if gjson.Valid(raw) {
+			if root := gjson.Parse(raw); root.Get("type").String() == "error" && root.Get("error.type").Type == gjson.String {
+				return []byte(raw)
+			}
+		}
+		var mapped ErrorDetail
+		if mapUpstreamError(raw, &mapped) {
+			detail.Message = mapped.Message
+		} else if raw != "" {
+			detail.Message = raw
+		}
+	}
+	out, _ := json.Marshal(map[string]any{"type": "error", "error": map[string]any{"type": detail.Type, "message": detail.Message}})
+	return out
+}
+
+// WriteAnthropicErrorResponse writes msg as an Anthropic error envelope,
+// keeping the upstream status code. Once a stream has started the envelope
+// is sent as an SSE error event instead.
+func (h *BaseAPIHandler) WriteAnthropicErrorResponse(c *gin.Context, msg *interfaces.ErrorMessage) {
+	status := http.StatusInternalServerError
+	var err error
+	if msg != nil {
+		if msg.StatusCode > 0 {
+			status = msg.StatusCode
+		}
+		err = msg.Error
+	}
+	body := AnthropicErrorBody(status, err)
+	if c.Writer.Written() {
+		_, _ = c.Writer.Write([]byte("event: error\ndata: "))
+		_, _ = c.Writer.Write(body)
+		_, _ = c.Writer.Write([]byte("\n\n"))
+		return
+	}
+	if msg != nil {
+		for key, values := range msg.Addon {
+			if len(values) == 0 {
+				continue
+			}
+			c.Writer.Header().Del(key)
+			for _, value := range values {
+				c.Writer.Header().Add(key, value)
+			}
+		}
+	}
+	c.Header("Content-Type", "application/json")
+	c.Status(status)
+	_, _ = c.Writer.Write(body)
+}
+
+// geminiErrorStatus returns the Google RPC status name for an HTTP status.
+func geminiErrorStatus(status int) string {
+	switch {
+	case status == http.StatusUnauthorized:
+		return "UNAUTHENTICATED"
+	case status == http.StatusForbidden:
+		return "PERMISSION_DENIED"
+	case status == http.StatusNotFound:
+		return "NOT_FOUND"
+	case status == http.StatusTooManyRequests:
+		return "RESOURCE_EXHAUSTED"
+	case status == http.StatusServiceUnavailable:
+		return "UNAVAILABLE"
+	case status == http.StatusGatewayTimeout:
+		return "DEADLINE_EXCEEDED"
+	case status >= 500:
+		return "INTERNAL"
+	default:
+		return "INVALID_ARGUMENT"
+	}
+}
+
+// GeminiErrorBody renders err as a Gemini error envelope. Upstream bodies
+// already in the Gemini shape are returned unchanged; OpenAI and Anthropic
+// error JSON is mapped so the upstream message survives.
+func GeminiErrorBody(status int, err error) []byte {
+	message := http.StatusText(status)
+	if err != nil {
+		raw := err.Error()
+		var perr *provider.Error
+		var se interface {
+			error
+			StatusCode() int
+		}
+		if errors.As(err, &perr) && perr != nil {
+			raw = perr.Message
+		} else if errors.As(err, &se) {
+			raw = se.Error()
+		}
+		raw = strings.TrimSpace(raw)
+		if gjson.Valid(raw) {
+			root := gjson.Parse(raw)
+			if root.IsArray() {
+				root = root.Get("0")
+			}
+			if root.Get("error.status").Type == gjson.String && root.Get("error.message").Type == gjson.String {
+				return []byte(root.Raw)
+			}
+		}
+		var mapped ErrorDetail
+		if mapUpstreamError(raw, &mapped) {
+			message = mapped.Message
+		} else if raw != "" {
+			message = raw
+		}
+	}
+	out, _ := json.Marshal(map[string]any{"error": map[string]any{"code": status, "message": message, "status": geminiErrorStatus(status)}})
+	return out
+}
+
+// WriteGeminiErrorResponse writes msg as a Gemini error envelope, keeping the
+// upstream status code. Once an SSE stream has started the envelope is sent
+// as a final data event instead.
+func (h *BaseAPIHandler) WriteGeminiErrorResponse(c *gin.Context, msg *interfaces.ErrorMessage) {
+	status := http.StatusInternalServerError
+	var err error
+	if msg != nil {
+		if msg.StatusCode > 0 {
+			status = msg.StatusCode
+		}
+		err = msg.Error
+	}
+	body := GeminiErrorBody(status, err)
+	if c.Writer.Written() {
+		if strings.HasPrefix(c.Writer.Header().Get("Content-Type"), "text/event-stream") {
+			_, _ = c.Writer.Write([]byte("data: "))
+			_, _ = c.Writer.Write(body)
+			_, _ = c.Writer.Write([]byte("\n\n"))
+		} else {
+			_, _ = c.Writer.Write(body)
+		}
+		return
+	}
+	if msg != nil {
+		for key, values := range msg.Addon {
+			if len(values) == 0 {
+				continue
+			}
+			c.Writer.Header().Del(key)
+			for _, value := range values {
+				c.Writer.Header().Add(key, value)
+			}
+		}
+	}
+	c.Header("Content-Type", "application/json")
+	c.Status(status)
+	_, _ = c.Writer.Write(body)
+}
@@ -0,0 +1,239 @@
+package format
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/interfaces"
+	"github.com/tidwall/gjson"
+)
+
+// upstreamError mirrors the executors' status error: the body is the message.
+type upstreamError struct {
+	code int
+	body string
+}
+
+func (e upstreamError) Error() string   { return e.body }
+func (e upstreamError) StatusCode() int { return e.code }
+
+func TestOpenAIErrorBody_MapsUpstreamErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		wantType string
+		wantCode string
+		wantMsg  string
+	}{
+		{
+			name:     "anthropic invalid request",
+			status:   400,
+			body:     `{"type":"error","error":{"type":"invalid_request_error","message":"max_tokens: 300000 > 64000, which is the maximum allowed number of output tokens for claude-sonnet-4-5"}}`,
+			wantType: "invalid_request_error",
+			wantCode: "invalid_request_error",
+			wantMsg:  "max_tokens: 300000 > 64000, which is the maximum allowed number of output tokens for claude-sonnet-4-5",
+		},
+		{
+			name:     "anthropic overloaded",
+			status:   529,
+			body:     `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`,
+			wantType: "server_error",
+			wantCode: "overloaded_error",
+			wantMsg:  "Overloaded",
+		},
+		{
+			name:     "gemini invalid argument",
+			status:   400,
+			body:     `{"error":{"code":400,"message":"* GenerateContentRequest.contents: contents is not specified\n","status":"INVALID_ARGUMENT"}}`,
+			wantType: "invalid_request_error",
+			wantCode: "INVALID_ARGUMENT",
+			wantMsg:  "* GenerateContentRequest.contents: contents is not specified\n",
+		},
+		{
+			name:     "gemini quota in array",
+			status:   429,
+			body:     `[{"error":{"code":429,"message":"Resource has been exhausted (e.g. check quota).","status":"RESOURCE_EXHAUSTED"}}]`,
+			wantType: "rate_limit_error",
+			wantCode: "RESOURCE_EXHAUSTED",
+			wantMsg:  "Resource has been exhausted (e.g. check quota).",
+		},
+		{
+			name:     "plain text",
+			status:   502,
+			body:     "upstream connect error",
+			wantType: "server_error",
+			wantMsg:  "upstream connect error",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := OpenAIErrorBody(tt.status, upstreamError{code: tt.status, body: tt.body})
+			got := gjson.ParseBytes(out).Get("error")
+			if got.Get("type").String() != tt.wantType {
+				t.Errorf("type = %q, want %q", got.Get("type").String(), tt.wantType)
+			}
+			if got.Get("code").String() != tt.wantCode {
+				t.Errorf("code = %q, want %q", got.Get("code").String(), tt.wantCode)
+			}
+			if got.Get("message").String() != tt.wantMsg {
+				t.Errorf("message = %q, want %q", got.Get("message").String(), tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestOpenAIErrorBody_PassesOpenAIErrorsThrough(t *testing.T) {
+	body := `{"error":{"message":"This model's maximum context length is 128000 tokens.","type":"invalid_request_error","param":"messages","code":"context_length_exceeded"}}`
+	if got := string(OpenAIErrorBody(400, upstreamError{code: 400, body: body})); got != body {
+		t.Errorf("body = %s, want unchanged upstream error", got)
+	}
+}
+
+func TestWriteOpenAIErrorResponse_KeepsUpstreamStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+
+	// Executors' errors may be wrapped on the way out; the status must survive.
+	err := fmt.Errorf("claude: %w", upstreamError{code: 400, body: `{"type":"error","error":{"type":"invalid_request_error","message":"prompt is too long"}}`})
+	status, addon := extractErrorDetails(err)
+	h := &BaseAPIHandler{}
+	h.WriteOpenAIErrorResponse(c, &interfaces.ErrorMessage{StatusCode: status, Error: err, Addon: addon})
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("content-type = %q", ct)
+	}
+	if msg := gjson.Get(rec.Body.String(), "error.message").String(); msg != "prompt is too long" {
+		t.Errorf("message = %q, body = %s", msg, rec.Body.String())
+	}
+}
+
+func TestAnthropicErrorBody(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		wantType string
+		wantMsg  string
+	}{
+		{
+			name:     "openai context length",
+			status:   400,
+			body:     `{"error":{"message":"This model's maximum context length is 128000 tokens.","type":"invalid_request_error","code":"context_length_exceeded"}}`,
+			wantType: "invalid_request_error",
+			wantMsg:  "This model's maximum context length is 128000 tokens.",
+		},
+		{
+			name:     "gemini quota",
+			status:   429,
+			body:     `{"error":{"code":429,"message":"Resource has been exhausted (e.g. check quota).","status":"RESOURCE_EXHAUSTED"}}`,
+			wantType: "rate_limit_error",
+			wantMsg:  "Resource has been exhausted (e.g. check quota).",
+		},
+		{
+			name:     "plain text",
+			status:   503,
+			body:     "no auth available",
+			wantType: "api_error",
+			wantMsg:  "no auth available",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := gjson.ParseBytes(AnthropicErrorBody(tt.status, upstreamError{code: tt.status, body: tt.body}))
+			if got.Get("type").String() != "error" {
+				t.Errorf("type = %q, want error", got.Get("type").String())
+			}
+			if got.Get("error.type").String() != tt.wantType {
+				t.Errorf("error.type = %q, want %q", got.Get("error.type").String(), tt.wantType)
+			}
+			if got.Get("error.message").String() != tt.wantMsg {
+				t.Errorf("error.message = %q, want %q", got.Get("error.message").String(), tt.wantMsg)
+			}
+		})
+	}
+
+	body := `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`
+	if got := string(AnthropicErrorBody(529, upstreamError{code: 529, body: body})); got != body {
+		t.Errorf("body = %s, want unchanged upstream error", got)
+	}
+}
+
+func TestWriteAnthropicErrorResponse_MidStream(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	_, _ = c.Writer.Write([]byte("event: message_start\ndata: {}\n\n"))
+
+	h := &BaseAPIHandler{}
+	h.WriteAnthropicErrorResponse(c, &interfaces.ErrorMessage{StatusCode: 500, Error: fmt.Errorf("upstream reset")})
+
+	events := strings.Split(strings.TrimSpace(rec.Body.String()), "\n\n")
+	last := events[len(events)-1]
+	data, ok := strings.CutPrefix(last, "event: error\ndata: ")
+	if !ok {
+		t.Fatalf("last event = %q, want an error event", last)
+	}
+	if got := gjson.Get(data, "error.message").String(); got != "upstream reset" {
+		t.Errorf("message = %q", got)
+	}
+}
+
+func TestGeminiErrorBody(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		wantStatus string
+		wantMsg    string
+	}{
+		{
+			name:       "anthropic overloaded",
+			status:     529,
+			body:       `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`,
+			wantStatus: "INTERNAL",
+			wantMsg:    "Overloaded",
+		},
+		{
+			name:       "openai rate limit",
+			status:     429,
+			body:       `{"error":{"message":"Rate limit reached","type":"rate_limit_error"}}`,
+			wantStatus: "RESOURCE_EXHAUSTED",
+			wantMsg:    "Rate limit reached",
+		},
+		{
+			name:       "plain text",
+			status:     400,
+			body:       "bad request",
+			wantStatus: "INVALID_ARGUMENT",
+			wantMsg:    "bad request",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := gjson.ParseBytes(GeminiErrorBody(tt.status, upstreamError{code: tt.status, body: tt.body}))
+			if got.Get("error.code").Int() != int64(tt.status) {
+				t.Errorf("error.code = %d, want %d", got.Get("error.code").Int(), tt.status)
+			}
+			if got.Get("error.status").String() != tt.wantStatus {
+				t.Errorf("error.status = %q, want %q", got.Get("error.status").String(), tt.wantStatus)
+			}
+			if got.Get("error.message").String() != tt.wantMsg {
+				t.Errorf("error.message = %q, want %q", got.Get("error.message").String(), tt.wantMsg)
+			}
+		})
+	}
+
+	body := `{"error":{"code":429,"message":"Quota exceeded","status":"RESOURCE_EXHAUSTED"}}`
+	if got := string(GeminiErrorBody(429, upstreamError{code: 429, body: "[" + body + "]"})); got != body {
+		t.Errorf("body = %s, want the unwrapped upstream error", got)
+	}
+}
@@ -161,6 +161,12 @@ func (h *GeminiCLIAPIHandler) handleInternalGenerateContent(c *gin.Context, rawJ
 }
 
 func (h *GeminiCLIAPIHandler) forwardCLIStream(c *gin.Context, flusher http.Flusher, alt string, cancel func(error), data <-chan []byte, errs <-chan *interfaces.ErrorMessage) {
+	// Keep-alive comments are SSE-only; alt streams are not event streams
+	var keepAlive *format.StreamKeepAlive
+	if alt == "" {
+		keepAlive = h.NewStreamKeepAlive()
+		defer keepAlive.Stop()
+	}
 	for {
 		select {
 		case <-c.Request.Context().Done():
@@ -186,6 +192,9 @@ func (h *GeminiCLIAPIHandler) forwardCLIStream(c *gin.Context, flusher http.Flus
 				_, _ = c.Writer.Write(chunk)
 			}
 			flusher.Flush()
+			keepAlive.Touch()
+		case <-keepAlive.C():
+			keepAlive.Write(c.Writer, flusher)
 		case errMsg, ok := <-errs:
 			if !ok {
 				continue
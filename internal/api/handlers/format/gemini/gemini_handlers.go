@@ -156,12 +156,7 @@ func (h *GeminiAPIHandler) GeminiHandler(c *gin.Context) {
 	}
 	action := strings.Split(request.Action, ":")
 	if len(action) != 2 {
-		c.JSON(http.StatusNotFound, format.ErrorResponse{
-			Error: format.ErrorDetail{
-				Message: fmt.Sprintf("%s not found.", c.Request.URL.Path),
-				Type:    "invalid_request_error",
-			},
-		})
+		h.writeNotFound(c)
 		return
 	}
 
@@ -175,9 +170,16 @@ func (h *GeminiAPIHandler) GeminiHandler(c *gin.Context) {
 		h.handleStreamGenerateContent(c, action[0], rawJSON)
 	case "countTokens":
 		h.handleCountTokens(c, action[0], rawJSON)
+	default:
+		h.writeNotFound(c)
 	}
 }
 
+// writeNotFound rejects an unknown model action the way the Gemini API does.
+func (h *GeminiAPIHandler) writeNotFound(c *gin.Context) {
+	c.Data(http.StatusNotFound, "application/json", format.GeminiErrorBody(http.StatusNotFound, fmt.Errorf("%s not found.", c.Request.URL.Path)))
+}
+
 func (h *GeminiAPIHandler) handleStreamGenerateContent(c *gin.Context, modelName string, rawJSON []byte) {
 	alt := h.GetAlt(c)
 
@@ -210,7 +212,7 @@ func (h *GeminiAPIHandler) handleCountTokens(c *gin.Context, modelName string, r
 	cliCtx, cliCancel := h.GetContextWithCancel(h, c, context.Background())
 	resp, errMsg := h.ExecuteCountWithAuthManager(cliCtx, h.HandlerType(), modelName, rawJSON, alt)
 	if errMsg != nil {
-		h.WriteErrorResponse(c, errMsg)
+		h.WriteGeminiErrorResponse(c, errMsg)
 		cliCancel(errMsg.Error)
 		return
 	}
@@ -224,7 +226,7 @@ func (h *GeminiAPIHandler) handleGenerateContent(c *gin.Context, modelName strin
 	cliCtx, cliCancel := h.GetContextWithCancel(h, c, context.Background())
 	resp, errMsg := h.ExecuteWithAuthManager(cliCtx, h.HandlerType(), modelName, rawJSON, alt)
 	if errMsg != nil {
-		h.WriteErrorResponse(c, errMsg)
+		h.WriteGeminiErrorResponse(c, errMsg)
 		cliCancel(errMsg.Error)
 		return
 	}
@@ -233,6 +235,12 @@ func (h *GeminiAPIHandler) handleGenerateContent(c *gin.Context, modelName strin
 }
 
 func (h *GeminiAPIHandler) forwardGeminiStream(c *gin.Context, flusher http.Flusher, alt string, cancel func(error), data <-chan []byte, errs <-chan *interfaces.ErrorMessage) {
+	// Keep-alive comments are SSE-only; alt streams are not event streams
+	var keepAlive *format.StreamKeepAlive
+	if alt == "" {
+		keepAlive = h.NewStreamKeepAlive()
+		defer keepAlive.Stop()
+	}
 	for {
 		select {
 		case <-c.Request.Context().Done():
@@ -258,12 +266,15 @@ func (h *GeminiAPIHandler) forwardGeminiStream(c *gin.Context, flusher http.Flus
 				_, _ = c.Writer.Write(chunk)
 			}
 			flusher.Flush()
+			keepAlive.Touch()
+		case <-keepAlive.C():
+			keepAlive.Write(c.Writer, flusher)
 		case errMsg, ok := <-errs:
 			if !ok {
 				continue
 			}
 			if errMsg != nil {
-				h.WriteErrorResponse(c, errMsg)
+				h.WriteGeminiErrorResponse(c, errMsg)
 				flusher.Flush()
 			}
 			var execErr error
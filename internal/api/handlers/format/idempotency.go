@@ -0,0 +1,200 @@
+package format
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/interfaces"
+)
+
+const (
+	// IdempotencyKeyHeader carries the client's key for safely retrying a
+	// non-streaming request.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader marks a response that was not produced by a
+	// new upstream call but replayed from an earlier request with the same key.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLen  = 255
+	maxIdempotencyEntries = 10000
+)
+
+// idempotentResult is the stored outcome of the first request with a key.
+type idempotentResult struct {
+	fingerprint string
+	payload     []byte
+	notes       callNotes
+	err         *interfaces.ErrorMessage
+	expires     time.Time
+}
+
+// idempotencyStore remembers the response of each idempotency key for a TTL
+// and runs in-flight duplicates as a single call. The zero value is ready to
+// use.
+type idempotencyStore struct {
+	mu      sync.Mutex
+	results map[string]*idempotentResult
+	// pending holds the request fingerprint of every key whose first call is
+	// still running.
+	pending map[string]string
+	flights requestFlights
+	now     func() time.Time
+}
+
+// do returns the stored result of scope if there is one, and otherwise runs
+// fn once for all concurrent callers with that scope, storing its result for
+// ttl. A key reused with a different request fails with 422 instead of
+// replaying a response to something else.
+func (s *idempotencyStore) do(ctx context.Context, scope, fingerprint string, ttl time.Duration, fn func(context.Context) ([]byte, callNotes, *interfaces.ErrorMessage)) ([]byte, callNotes, *interfaces.ErrorMessage) {
+	s.mu.Lock()
+	if s.pending == nil {
+		s.pending = make(map[string]string)
+	}
+	if r, ok := s.lookup(scope); ok {
+		s.mu.Unlock()
+		if r.fingerprint != fingerprint {
+			return nil, callNotes{}, idempotencyConflict()
+		}
+		markReplayed(ctx)
+		return bytes.Clone(r.payload), r.notes, r.err
+	}
+	running, joined := s.pending[scope]
+	if joined && running != fingerprint {
+		s.mu.Unlock()
+		return nil, callNotes{}, idempotencyConflict()
+	}
+	s.pending[scope] = fingerprint
+	s.mu.Unlock()
+
+	payload, notes, errMsg := s.flights.do(ctx, scope, func(callCtx context.Context) ([]byte, callNotes, *interfaces.ErrorMessage) {
+		// A duplicate may start a new flight just after the first one
+		// stored its result and ended; serve that result instead.
+		s.mu.Lock()
+		r, ok := s.lookup(scope)
+		s.mu.Unlock()
+		if ok {
+			if r.fingerprint != fingerprint {
+				return nil, callNotes{}, idempotencyConflict()
+			}
+			return r.payload, r.notes, r.err
+		}
+
+		payload, notes, errMsg := fn(callCtx)
+		s.mu.Lock()
+		// Store before the flight ends so a duplicate arriving in between
+		// either joins the flight or finds the result.
+		if callCtx.Err() == nil && replayable(errMsg) {
+			s.store(scope, &idempotentResult{
+				fingerprint: fingerprint,
+				payload:     bytes.Clone(payload),
+				notes:       notes,
+				err:         errMsg,
+				expires:     s.clock().Add(ttl),
+			})
+		}
+		if s.pending[scope] == fingerprint {
+			delete(s.pending, scope)
+		}
+		s.mu.Unlock()
+		return payload, notes, errMsg
+	})
+	if joined {
+		markReplayed(ctx)
+	}
+	return payload, notes, errMsg
+}
+
+// lookup returns the unexpired result of scope. The caller must hold s.mu.
+func (s *idempotencyStore) lookup(scope string) (*idempotentResult, bool) {
+	r, ok := s.results[scope]
+	if !ok {
+		return nil, false
+	}
+	if !s.clock().Before(r.expires) {
+		delete(s.results, scope)
+		return nil, false
+	}
+	return r, true
+}
+
+// store records r, first dropping expired results and, when the store is
+// still full, the result closest to expiry. The caller must hold s.mu.
+func (s *idempotencyStore) store(scope string, r *idempotentResult) {
+	if s.results == nil {
+		s.results = make(map[string]*idempotentResult)
+	}
+	if len(s.results) >= maxIdempotencyEntries {
+		now := s.clock()
+		var oldest string
+		for k, v := range s.results {
+			if !now.Before(v.expires) {
+				delete(s.results, k)
+				continue
+			}
+			if oldest == "" || v.expires.Before(s.results[oldest].expires) {
+				oldest = k
+			}
+		}
+		if len(s.results) >= maxIdempotencyEntries {
+			delete(s.results, oldest)
+		}
+	}
+	s.results[scope] = r
+}
+
+func (s *idempotencyStore) clock() time.Time {
+	if s.now != nil {
+		return s.now()
+	}
+	return time.Now()
+}
+
+// replayable reports whether a result is final enough to replay. Transient
+// failures are not stored, so a retry with the same key tries again.
+func replayable(errMsg *interfaces.ErrorMessage) bool {
+	if errMsg == nil {
+		return true
+	}
+	switch errMsg.StatusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests, statusClientClosedRequest:
+		return false
+	}
+	return errMsg.StatusCode < http.StatusInternalServerError
+}
+
+func idempotencyConflict() *interfaces.ErrorMessage {
+	return &interfaces.ErrorMessage{StatusCode: http.StatusUnprocessableEntity, Error: fmt.Errorf("%s was already used with a different request", IdempotencyKeyHeader)}
+}
+
+// idempotencyScope returns the store key of the request behind ctx: its
+// Idempotency-Key scoped to the client key, or "" when it has none or
+// idempotency keys are disabled.
+func (h *BaseAPIHandler) idempotencyScope(ctx context.Context) (string, *interfaces.ErrorMessage) {
+	if h.Cfg == nil || h.Cfg.IdempotencyTTL <= 0 {
+		return "", nil
+	}
+	c, ok := ctx.Value(ctxKeyGin).(*gin.Context)
+	if !ok || c == nil || c.Request == nil {
+		return "", nil
+	}
+	key := strings.TrimSpace(c.GetHeader(IdempotencyKeyHeader))
+	if key == "" {
+		return "", nil
+	}
+	if len(key) > maxIdempotencyKeyLen {
+		return "", &interfaces.ErrorMessage{StatusCode: http.StatusBadRequest, Error: fmt.Errorf("%s must be at most %d characters", IdempotencyKeyHeader, maxIdempotencyKeyLen)}
+	}
+	return c.GetString("apiKey") + "\x00" + key, nil
+}
+
+func markReplayed(ctx context.Context) {
+	if c, ok := ctx.Value(ctxKeyGin).(*gin.Context); ok && c != nil {
+		c.Writer.Header().Set(IdempotentReplayedHeader, "true")
+	}
+}
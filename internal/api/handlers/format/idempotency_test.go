@@ -0,0 +1,145 @@
+package format
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/interfaces"
+)
+
+func idempotencyContext(apiKey, key string) (context.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	c.Request.Header.Set(IdempotencyKeyHeader, key)
+	c.Set("apiKey", apiKey)
+	return context.WithValue(context.Background(), ctxKeyGin, c), w
+}
+
+func TestIdempotencyStore_ReplaysResult(t *testing.T) {
+	now := time.Unix(1000, 0)
+	s := &idempotencyStore{now: func() time.Time { return now }}
+	var calls atomic.Int32
+	status := http.StatusBadRequest
+	fn := func(context.Context) ([]byte, callNotes, *interfaces.ErrorMessage) {
+		calls.Add(1)
+		if status != 0 {
+			return nil, callNotes{}, &interfaces.ErrorMessage{StatusCode: status, Error: errors.New("bad")}
+		}
+		return []byte(`{"ok":true}`), callNotes{}, nil
+	}
+
+	ctx, _ := idempotencyContext("client", "k")
+	if _, _, errMsg := s.do(ctx, "k", "fp", time.Minute, fn); errMsg == nil || errMsg.StatusCode != http.StatusBadRequest {
+		t.Fatalf("first call = %+v, want 400", errMsg)
+	}
+	status = 0
+	replayCtx, w := idempotencyContext("client", "k")
+	if _, _, errMsg := s.do(replayCtx, "k", "fp", time.Minute, fn); errMsg == nil || errMsg.StatusCode != http.StatusBadRequest {
+		t.Fatalf("replay = %+v, want the original 400", errMsg)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("upstream calls = %d, want 1", calls.Load())
+	}
+	if w.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Errorf("replayed response lacks %s", IdempotentReplayedHeader)
+	}
+
+	if _, _, errMsg := s.do(ctx, "k", "other", time.Minute, fn); errMsg == nil || errMsg.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("reuse with another request = %+v, want 422", errMsg)
+	}
+
+	now = now.Add(time.Minute)
+	payload, _, errMsg := s.do(ctx, "k", "fp", time.Minute, fn)
+	if errMsg != nil || string(payload) != `{"ok":true}` || calls.Load() != 2 {
+		t.Errorf("after expiry got %s, %+v after %d calls; want a new call", payload, errMsg, calls.Load())
+	}
+}
+
+func TestIdempotencyStore_TransientErrorsAreNotStored(t *testing.T) {
+	var s idempotencyStore
+	var calls atomic.Int32
+	fn := func(context.Context) ([]byte, callNotes, *interfaces.ErrorMessage) {
+		calls.Add(1)
+		return nil, callNotes{}, &interfaces.ErrorMessage{StatusCode: http.StatusServiceUnavailable, Error: errors.New("down")}
+	}
+	ctx, _ := idempotencyContext("client", "k")
+	s.do(ctx, "k", "fp", time.Minute, fn)
+	s.do(ctx, "k", "fp", time.Minute, fn)
+	if calls.Load() != 2 {
+		t.Errorf("upstream calls = %d, want a retry after a 503", calls.Load())
+	}
+}
+
+func TestIdempotencyStore_ConcurrentDuplicatesWait(t *testing.T) {
+	var s idempotencyStore
+	var calls atomic.Int32
+	release := make(chan struct{})
+	fn := func(context.Context) ([]byte, callNotes, *interfaces.ErrorMessage) {
+		calls.Add(1)
+		<-release
+		return []byte(`{"ok":true}`), callNotes{}, nil
+	}
+
+	const n = 8
+	results := make([][]byte, n)
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for i := range n {
+		go func() {
+			defer wg.Done()
+			ctx, _ := idempotencyContext("client", "k")
+			results[i], _, _ = s.do(ctx, "k", "fp", time.Minute, fn)
+		}()
+	}
+	waitForWaiters(t, &s.flights, "k", n)
+
+	ctx, _ := idempotencyContext("client", "k")
+	if _, _, errMsg := s.do(ctx, "k", "other", time.Minute, fn); errMsg == nil || errMsg.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("in-flight reuse with another request = %+v, want 422", errMsg)
+	}
+	close(release)
+	wg.Wait()
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("upstream calls = %d, want 1", got)
+	}
+	for i, r := range results {
+		if string(r) != `{"ok":true}` {
+			t.Errorf("result %d = %s", i, r)
+		}
+	}
+	if len(s.pending) != 0 {
+		t.Errorf("finished key still pending")
+	}
+}
+
+func TestIdempotencyScope(t *testing.T) {
+	h := &BaseAPIHandler{Cfg: &config.SDKConfig{IdempotencyTTL: 60}}
+	a, _ := idempotencyContext("client-a", "k")
+	b, _ := idempotencyContext("client-b", "k")
+	scopeA, _ := h.idempotencyScope(a)
+	scopeB, _ := h.idempotencyScope(b)
+	if scopeA == "" || scopeA == scopeB {
+		t.Errorf("scopes %q and %q are not per client", scopeA, scopeB)
+	}
+
+	long, _ := idempotencyContext("client-a", strings.Repeat("k", maxIdempotencyKeyLen+1))
+	if _, errMsg := h.idempotencyScope(long); errMsg == nil || errMsg.StatusCode != http.StatusBadRequest {
+		t.Errorf("oversized key = %+v, want 400", errMsg)
+	}
+
+	h.Cfg.IdempotencyTTL = 0
+	if scope, _ := h.idempotencyScope(a); scope != "" {
+		t.Errorf("disabled store returned scope %q", scope)
+	}
+}
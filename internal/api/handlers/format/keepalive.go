@@ -0,0 +1,64 @@
+package format
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+)
+
+// sseKeepAliveComment is an SSE comment line; clients ignore it, but it keeps
+// intermediaries from closing a stream that is waiting on a slow model.
+const sseKeepAliveComment = ": keep-alive\n\n"
+
+// StreamKeepAlive fires once a stream has been silent for a full interval.
+// A nil *StreamKeepAlive is valid and never fires, so forwarding loops can
+// select on C unconditionally. Only SSE responses should use it.
+type StreamKeepAlive struct {
+	ticker   *time.Ticker
+	interval time.Duration
+}
+
+// NewStreamKeepAlive returns a keep-alive for an SSE stream, or nil when
+// stream-keep-alive is not configured.
+func (h *BaseAPIHandler) NewStreamKeepAlive() *StreamKeepAlive {
+	if h.Cfg == nil || h.Cfg.StreamKeepAlive <= 0 {
+		return nil
+	}
+	return newStreamKeepAlive(time.Duration(h.Cfg.StreamKeepAlive) * time.Second)
+}
+
+func newStreamKeepAlive(interval time.Duration) *StreamKeepAlive {
+	return &StreamKeepAlive{ticker: time.NewTicker(interval), interval: interval}
+}
+
+// C returns the channel that fires when a keep-alive is due.
+func (k *StreamKeepAlive) C() <-chan time.Time {
+	if k == nil {
+		return nil
+	}
+	return k.ticker.C
+}
+
+// Touch records that real data was written, restarting the idle interval.
+func (k *StreamKeepAlive) Touch() {
+	if k != nil {
+		k.ticker.Reset(k.interval)
+	}
+}
+
+// Write emits a keep-alive comment and flushes it.
+func (k *StreamKeepAlive) Write(w io.Writer, flusher http.Flusher) {
+	buf := append(ir.GetSSEChunkBuf(), sseKeepAliveComment...)
+	_, _ = w.Write(buf)
+	ir.PutSSEChunkBuf(buf)
+	flusher.Flush()
+}
+
+// Stop releases the ticker; no keep-alive fires afterwards.
+func (k *StreamKeepAlive) Stop() {
+	if k != nil {
+		k.ticker.Stop()
+	}
+}
@@ -0,0 +1,69 @@
+package format
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/config"
+)
+
+func TestNewStreamKeepAlive_DisabledByDefault(t *testing.T) {
+	h := &BaseAPIHandler{Cfg: &config.SDKConfig{}}
+	k := h.NewStreamKeepAlive()
+	if k != nil {
+		t.Fatalf("keep-alive enabled without stream-keep-alive")
+	}
+	// A nil keep-alive must be safe to use in a forwarding loop.
+	k.Touch()
+	k.Stop()
+	if k.C() != nil {
+		t.Errorf("nil keep-alive returned a non-nil channel")
+	}
+}
+
+func TestStreamKeepAlive_FiresWhenIdle(t *testing.T) {
+	k := newStreamKeepAlive(20 * time.Millisecond)
+	defer k.Stop()
+
+	select {
+	case <-k.C():
+	case <-time.After(time.Second):
+		t.Fatal("keep-alive did not fire on an idle stream")
+	}
+
+	rec := httptest.NewRecorder()
+	k.Write(rec, rec)
+	if got := rec.Body.String(); got != ": keep-alive\n\n" {
+		t.Errorf("body = %q, want SSE comment", got)
+	}
+	if !rec.Flushed {
+		t.Error("keep-alive was not flushed")
+	}
+}
+
+func TestStreamKeepAlive_TouchDefersAndStopEnds(t *testing.T) {
+	k := newStreamKeepAlive(50 * time.Millisecond)
+
+	deadline := time.After(120 * time.Millisecond)
+	tick := time.NewTicker(10 * time.Millisecond)
+	defer tick.Stop()
+loop:
+	for {
+		select {
+		case <-k.C():
+			t.Fatal("keep-alive fired while data was flowing")
+		case <-tick.C:
+			k.Touch()
+		case <-deadline:
+			break loop
+		}
+	}
+
+	k.Stop()
+	select {
+	case <-k.C():
+		t.Fatal("keep-alive fired after Stop")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,82 @@
+package format
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/registry"
+)
+
+func TestApplyModelListing(t *testing.T) {
+	reg := registry.GetGlobalRegistry()
+	reg.RegisterClient("listing-qwen", "qwen", []*registry.ModelInfo{{ID: "listing-qwen-model"}})
+	defer reg.UnregisterClient("listing-qwen")
+	reg.RegisterClient("listing-iflow", "iflow", []*registry.ModelInfo{{ID: "listing-iflow-model"}})
+	defer reg.UnregisterClient("listing-iflow")
+
+	members := []registry.FamilyMember{
+		{Provider: "qwen", Model: "listing-qwen-model"},
+		{Provider: "iflow", Model: "listing-iflow-model"},
+		{Provider: "kiro", Model: "listing-kiro-model"},
+	}
+	if err := registry.RegisterModelFamily("listing-family", members); err != nil {
+		t.Fatalf("register family: %v", err)
+	}
+	defer registry.UnregisterModelFamily("listing-family")
+
+	listed := func() []map[string]any {
+		return []map[string]any{
+			{"id": "listing-qwen-model", "object": "model", "owned_by": "qwen"},
+			{"id": "listing-iflow-model", "object": "model", "owned_by": "iflow"},
+			{"id": "unrelated-model", "object": "model", "owned_by": "openai"},
+		}
+	}
+	ids := func(models []map[string]any) []string {
+		out := make([]string, 0, len(models))
+		for _, m := range models {
+			out = append(out, m["id"].(string))
+		}
+		return out
+	}
+
+	tests := []struct {
+		mode string
+		want []string
+	}{
+		{"", []string{"listing-qwen-model", "listing-iflow-model", "unrelated-model"}},
+		{config.ModelListingProvider, []string{"listing-qwen-model", "listing-iflow-model", "unrelated-model"}},
+		{config.ModelListingCanonical, []string{"unrelated-model", "listing-family"}},
+		{config.ModelListingBoth, []string{"listing-qwen-model", "listing-iflow-model", "unrelated-model", "listing-family"}},
+	}
+	for _, tt := range tests {
+		h := &BaseAPIHandler{Routing: &config.RoutingConfig{ListModels: tt.mode}}
+		got := h.ApplyModelListing(listed())
+		// Other tests and built-in families may add entries; compare ours only.
+		got = slices.DeleteFunc(got, func(m map[string]any) bool {
+			id := m["id"].(string)
+			return id != "unrelated-model" && id != "listing-family" && id != "listing-qwen-model" && id != "listing-iflow-model"
+		})
+		if !slices.Equal(ids(got), tt.want) {
+			t.Errorf("mode %q: listed %v, want %v", tt.mode, ids(got), tt.want)
+		}
+		for _, m := range got {
+			if m["id"] != "listing-family" {
+				continue
+			}
+			family, _ := m["family_members"].([]registry.FamilyMember)
+			if len(family) != 2 || family[0].Model != "listing-qwen-model" || family[1].Model != "listing-iflow-model" {
+				t.Errorf("mode %q: family_members = %v, want the two listed members", tt.mode, m["family_members"])
+			}
+			if m["owned_by"] != "qwen" {
+				t.Errorf("mode %q: canonical entry owned_by = %v, want the first listed member's", tt.mode, m["owned_by"])
+			}
+		}
+	}
+
+	h := &BaseAPIHandler{Routing: &config.RoutingConfig{ListModels: config.ModelListingCanonical}}
+	providers, model, _, errMsg := h.getRequestDetails("listing-family", 0)
+	if errMsg != nil || model != "listing-family" || !slices.Equal(providers, []string{"qwen", "iflow"}) {
+		t.Fatalf("canonical request: providers=%v model=%s err=%v", providers, model, errMsg)
+	}
+}
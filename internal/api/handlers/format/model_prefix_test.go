@@ -0,0 +1,105 @@
+package format
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/registry"
+)
+
+func TestProviderPrefixedRouting(t *testing.T) {
+	reg := registry.GetGlobalRegistry()
+	reg.RegisterClient("prefix-a", "prefixa", []*registry.ModelInfo{{ID: "prefix-shared-model"}, {ID: "prefix-a-model"}})
+	defer reg.UnregisterClient("prefix-a")
+	reg.RegisterClient("prefix-b", "prefixb", []*registry.ModelInfo{{ID: "prefix-shared-model"}, {ID: "prefix-b-model"}, {ID: "prefix-b-only"}})
+	defer reg.UnregisterClient("prefix-b")
+	// An upstream whose own IDs look prefixed, as OpenRouter's do.
+	reg.RegisterClient("prefix-router", "prefixrouter", []*registry.ModelInfo{{ID: "prefixa/routed-model"}})
+	defer reg.UnregisterClient("prefix-router")
+
+	if err := registry.RegisterModelFamily("prefix-family", []registry.FamilyMember{
+		{Provider: "prefixa", Model: "prefix-a-model"},
+		{Provider: "prefixb", Model: "prefix-b-model"},
+	}); err != nil {
+		t.Fatalf("register family: %v", err)
+	}
+	defer registry.UnregisterModelFamily("prefix-family")
+
+	routing := &config.RoutingConfig{Aliases: map[string]string{
+		"prefix-fast":   "prefixb/prefix-shared-model",
+		"prefix-shared": "prefix-shared-model",
+	}}
+	routing.Init()
+	h := &BaseAPIHandler{Routing: routing}
+
+	tests := []struct {
+		model     string
+		providers []string
+		want      string
+	}{
+		{"prefix-shared-model", []string{"prefixa", "prefixb"}, "prefix-shared-model"},
+		{"prefixb/prefix-shared-model", []string{"prefixb"}, "prefix-shared-model"},
+		{"prefixa/prefix-shared-model", []string{"prefixa"}, "prefix-shared-model"},
+		{"prefix-fast", []string{"prefixb"}, "prefix-shared-model"},
+		{"prefixa/prefix-shared", []string{"prefixa"}, "prefix-shared-model"},
+		{"prefixb/prefix-family", []string{"prefixb"}, "prefix-family"},
+		{"prefixa/routed-model", []string{"prefixrouter"}, "prefixa/routed-model"},
+	}
+	for _, tt := range tests {
+		providers, model, _, errMsg := h.getRequestDetails(tt.model, 0)
+		if errMsg != nil {
+			t.Errorf("%s: %v", tt.model, errMsg.Error)
+			continue
+		}
+		slices.Sort(providers)
+		if !slices.Equal(providers, tt.providers) || model != tt.want {
+			t.Errorf("%s: routed to %v as %s, want %v as %s", tt.model, providers, model, tt.providers, tt.want)
+		}
+	}
+
+	if _, _, _, errMsg := h.getRequestDetails("prefixa/prefix-b-only", 0); errMsg == nil {
+		t.Error("model pinned to a provider that does not serve it was routed")
+	}
+
+	members, err := registry.ResolveModelFamily("prefixb/prefix-family", 0)
+	if err != nil || len(members) != 1 || members[0].Model != "prefix-b-model" {
+		t.Errorf("ResolveModelFamily(prefixb/prefix-family) = %v, %v; want the prefixb member", members, err)
+	}
+	if _, err := registry.ResolveModelFamily("prefixrouter/prefix-family", 0); err == nil {
+		t.Error("ResolveModelFamily resolved a provider outside the family")
+	}
+}
+
+func TestApplyModelPrefixes(t *testing.T) {
+	reg := registry.GetGlobalRegistry()
+	reg.RegisterClient("listed-a", "prefixa", []*registry.ModelInfo{{ID: "listed-shared-model"}})
+	defer reg.UnregisterClient("listed-a")
+	reg.RegisterClient("listed-b", "prefixb", []*registry.ModelInfo{{ID: "listed-shared-model"}})
+	defer reg.UnregisterClient("listed-b")
+
+	models := func() []map[string]any {
+		return []map[string]any{
+			{"id": "listed-shared-model", "object": "model"},
+			{"id": "listed-family", "object": "model", "family_members": []registry.FamilyMember{}},
+			{"id": "listed-alias", "object": "model"},
+		}
+	}
+	ids := func(models []map[string]any) []string {
+		var out []string
+		for _, m := range models {
+			out = append(out, m["id"].(string))
+		}
+		return out
+	}
+
+	off := &BaseAPIHandler{Routing: &config.RoutingConfig{}}
+	if got := ids(off.ApplyModelPrefixes(models())); !slices.Equal(got, []string{"listed-shared-model", "listed-family", "listed-alias"}) {
+		t.Errorf("prefixes disabled: listed %v", got)
+	}
+	on := &BaseAPIHandler{Routing: &config.RoutingConfig{PrefixModelIDs: true}}
+	want := []string{"prefixa/listed-shared-model", "prefixb/listed-shared-model", "listed-family", "listed-alias"}
+	if got := ids(on.ApplyModelPrefixes(models())); !slices.Equal(got, want) {
+		t.Errorf("prefixes enabled: listed %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,69 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/interfaces"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// requestedChoices returns the n of a chat completion request, at least 1.
+func requestedChoices(rawJSON []byte) int {
+	if n := gjson.GetBytes(rawJSON, "n").Int(); n > 1 {
+		return int(n)
+	}
+	return 1
+}
+
+// fillChoices tops resp up to n choices when the upstream ignored n, as
+// Anthropic does. With fan-out enabled each missing choice is requested
+// separately; otherwise the request fails rather than silently returning
+// fewer choices than asked for.
+func (h *OpenAIAPIHandler) fillChoices(c *gin.Context, ctx context.Context, modelName string, rawJSON, resp []byte, n int) ([]byte, *interfaces.ErrorMessage) {
+	got := len(gjson.GetBytes(resp, "choices").Array())
+	if got >= n {
+		return resp, nil
+	}
+	if h.Cfg == nil || !h.Cfg.ChoicesFanOut {
+		return nil, &interfaces.ErrorMessage{
+			StatusCode: http.StatusBadRequest,
+			Error:      fmt.Errorf("model %s returned %d of %d requested choices; enable choices-fan-out to make one upstream call per choice", modelName, got, n),
+		}
+	}
+	single, _ := sjson.DeleteBytes(rawJSON, "n")
+	extra, errMsg := h.ExecuteManyWithAuthManager(ctx, h.HandlerType(), modelName, single, h.GetAlt(c), n-got)
+	if errMsg != nil {
+		return nil, errMsg
+	}
+	return mergeChoices(resp, extra), nil
+}
+
+// mergeChoices appends the choices of extra to base, renumbering them
+// 0..n-1, and sums the token counts of all responses into base's usage.
+func mergeChoices(base []byte, extra [][]byte) []byte {
+	out := base
+	index := len(gjson.GetBytes(base, "choices").Array())
+	usage := gjson.GetBytes(base, "usage")
+	prompt, completion, total := usage.Get("prompt_tokens").Int(), usage.Get("completion_tokens").Int(), usage.Get("total_tokens").Int()
+	for _, resp := range extra {
+		for _, choice := range gjson.GetBytes(resp, "choices").Array() {
+			raw, _ := sjson.Set(choice.Raw, "index", index)
+			out, _ = sjson.SetRawBytes(out, "choices.-1", []byte(raw))
+			index++
+		}
+		u := gjson.GetBytes(resp, "usage")
+		prompt += u.Get("prompt_tokens").Int()
+		completion += u.Get("completion_tokens").Int()
+		total += u.Get("total_tokens").Int()
+	}
+	if usage.Exists() {
+		out, _ = sjson.SetBytes(out, "usage.prompt_tokens", prompt)
+		out, _ = sjson.SetBytes(out, "usage.completion_tokens", completion)
+		out, _ = sjson.SetBytes(out, "usage.total_tokens", total)
+	}
+	return out
+}
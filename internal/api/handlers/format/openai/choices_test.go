@@ -0,0 +1,54 @@
+package openai
+
+import (
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestRequestedChoices(t *testing.T) {
+	tests := map[string]int{
+		`{"model":"m"}`:       1,
+		`{"model":"m","n":0}`: 1,
+		`{"model":"m","n":1}`: 1,
+		`{"model":"m","n":3}`: 3,
+	}
+	for body, want := range tests {
+		if got := requestedChoices([]byte(body)); got != want {
+			t.Errorf("requestedChoices(%s) = %d, want %d", body, got, want)
+		}
+	}
+}
+
+func TestMergeChoices(t *testing.T) {
+	base := []byte(`{"id":"a","choices":[{"index":0,"message":{"content":"one"},"finish_reason":"stop"}],"usage":{"prompt_tokens":10,"completion_tokens":2,"total_tokens":12}}`)
+	extra := [][]byte{
+		[]byte(`{"id":"b","choices":[{"index":0,"message":{"content":"two"},"finish_reason":"stop"}],"usage":{"prompt_tokens":10,"completion_tokens":3,"total_tokens":13}}`),
+		[]byte(`{"id":"c","choices":[{"index":0,"message":{"content":"three"},"finish_reason":"length"}],"usage":{"prompt_tokens":10,"completion_tokens":4,"total_tokens":14}}`),
+	}
+
+	out := mergeChoices(base, extra)
+
+	if got := gjson.GetBytes(out, "id").String(); got != "a" {
+		t.Errorf("id = %q, want a", got)
+	}
+	choices := gjson.GetBytes(out, "choices").Array()
+	if len(choices) != 3 {
+		t.Fatalf("choices = %d, want 3", len(choices))
+	}
+	for i, want := range []string{"one", "two", "three"} {
+		if got := choices[i].Get("index").Int(); got != int64(i) {
+			t.Errorf("choices[%d].index = %d", i, got)
+		}
+		if got := choices[i].Get("message.content").String(); got != want {
+			t.Errorf("choices[%d].content = %q, want %q", i, got, want)
+		}
+	}
+	if got := choices[2].Get("finish_reason").String(); got != "length" {
+		t.Errorf("choices[2].finish_reason = %q, want length", got)
+	}
+	usage := gjson.GetBytes(out, "usage")
+	if usage.Get("prompt_tokens").Int() != 30 || usage.Get("completion_tokens").Int() != 9 || usage.Get("total_tokens").Int() != 39 {
+		t.Errorf("usage = %s, want summed counts", usage.Raw)
+	}
+}
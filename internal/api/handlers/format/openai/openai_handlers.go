@@ -60,9 +60,10 @@ func (h *OpenAIAPIHandler) Models() []map[string]any {
 // and specifications in OpenAI-compatible format.
 func (h *OpenAIAPIHandler) OpenAIModels(c *gin.Context) {
 	// Get all available models
-	allModels := h.Models()
+	allModels := h.ApplyModelPrefixes(h.AppendAliasModels(h.ApplyModelListing(h.Models())))
 
-	// Filter to only include the 4 required fields: id, object, created, owned_by
+	// Filter to only include the 4 required fields: id, object, created, owned_by,
+	// plus the members behind a canonical model family ID
 	filteredModels := make([]map[string]any, len(allModels))
 	for i, model := range allModels {
 		filteredModel := map[string]any{
@@ -77,6 +78,10 @@ func (h *OpenAIAPIHandler) OpenAIModels(c *gin.Context) {
 
 		// Add owned_by
 		filteredModel["owned_by"] = model["owned_by"]
+
+		if members, exists := model["family_members"]; exists {
+			filteredModel["family_members"] = members
+		}
 		filteredModels[i] = filteredModel
 	}
 
@@ -105,9 +110,36 @@ func (h *OpenAIAPIHandler) ChatCompletions(c *gin.Context) {
 		return
 	}
 
+	model := gjson.GetBytes(rawJSON, "model").String()
+	wrongEndpoint := ""
+	if registry.IsEmbeddingModel(model) {
+		wrongEndpoint = fmt.Sprintf("%s is an embedding model; use /v1/embeddings", model)
+	} else if registry.GetGlobalRegistry().IsRerankModel(model) {
+		wrongEndpoint = fmt.Sprintf("%s is a rerank model; use /v1/rerank", model)
+	}
+	if wrongEndpoint != "" {
+		c.JSON(http.StatusBadRequest, format.ErrorResponse{
+			Error: format.ErrorDetail{
+				Message: wrongEndpoint,
+				Type:    "invalid_request_error",
+			},
+		})
+		return
+	}
+
 	// Check if the client requested a streaming response.
 	streamResult := gjson.GetBytes(rawJSON, "stream")
 	if streamResult.Type == gjson.True {
+		// Choices from several upstreams cannot be interleaved into one stream.
+		if requestedChoices(rawJSON) > 1 {
+			c.JSON(http.StatusBadRequest, format.ErrorResponse{
+				Error: format.ErrorDetail{
+					Message: "n > 1 is not supported with stream: true",
+					Type:    "invalid_request_error",
+				},
+			})
+			return
+		}
 		h.handleStreamingResponse(c, rawJSON)
 	} else {
 		h.handleNonStreamingResponse(c, rawJSON)
@@ -115,6 +147,95 @@ func (h *OpenAIAPIHandler) ChatCompletions(c *gin.Context) {
 
 }
 
+// Embeddings handles the /v1/embeddings endpoint. Array inputs are batched to
+// fit the upstream limits and returned in request order.
+//
+// Parameters:
+//   - c: The Gin context containing the HTTP request and response
+func (h *OpenAIAPIHandler) Embeddings(c *gin.Context) {
+	rawJSON, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, format.ErrorResponse{
+			Error: format.ErrorDetail{
+				Message: fmt.Sprintf("Invalid request: %v", err),
+				Type:    "invalid_request_error",
+			},
+		})
+		return
+	}
+
+	modelName := gjson.GetBytes(rawJSON, "model").String()
+	cliCtx, cliCancel := h.GetContextWithCancel(h, c, context.Background())
+	resp, errMsg := h.ExecuteEmbedWithAuthManager(cliCtx, h.HandlerType(), modelName, rawJSON)
+	if errMsg != nil {
+		h.WriteOpenAIErrorResponse(c, errMsg)
+		cliCancel(errMsg.Error)
+		return
+	}
+	c.Header("Content-Type", "application/json")
+	_, _ = c.Writer.Write(resp)
+	cliCancel()
+}
+
+// Rerank handles the /v1/rerank endpoint by scoring documents against a
+// query with a rerank-capable provider.
+//
+// Parameters:
+//   - c: The Gin context containing the HTTP request and response
+func (h *OpenAIAPIHandler) Rerank(c *gin.Context) {
+	rawJSON, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, format.ErrorResponse{
+			Error: format.ErrorDetail{
+				Message: fmt.Sprintf("Invalid request: %v", err),
+				Type:    "invalid_request_error",
+			},
+		})
+		return
+	}
+
+	modelName := gjson.GetBytes(rawJSON, "model").String()
+	cliCtx, cliCancel := h.GetContextWithCancel(h, c, context.Background())
+	resp, errMsg := h.ExecuteRerankWithAuthManager(cliCtx, h.HandlerType(), modelName, rawJSON)
+	if errMsg != nil {
+		h.WriteOpenAIErrorResponse(c, errMsg)
+		cliCancel(errMsg.Error)
+		return
+	}
+	c.Header("Content-Type", "application/json")
+	_, _ = c.Writer.Write(resp)
+	cliCancel()
+}
+
+// Moderations handles the /v1/moderations endpoint by forwarding the request
+// to the moderation provider selected in the config.
+//
+// Parameters:
+//   - c: The Gin context containing the HTTP request and response
+func (h *OpenAIAPIHandler) Moderations(c *gin.Context) {
+	rawJSON, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, format.ErrorResponse{
+			Error: format.ErrorDetail{
+				Message: fmt.Sprintf("Invalid request: %v", err),
+				Type:    "invalid_request_error",
+			},
+		})
+		return
+	}
+
+	cliCtx, cliCancel := h.GetContextWithCancel(h, c, context.Background())
+	resp, errMsg := h.ExecuteModerateWithAuthManager(cliCtx, h.HandlerType(), rawJSON)
+	if errMsg != nil {
+		h.WriteOpenAIErrorResponse(c, errMsg)
+		cliCancel(errMsg.Error)
+		return
+	}
+	c.Header("Content-Type", "application/json")
+	_, _ = c.Writer.Write(resp)
+	cliCancel()
+}
+
 // Completions handles the /v1/completions endpoint.
 // It determines whether the request is for a streaming or non-streaming response
 // and calls the appropriate handler based on the model provider.
@@ -398,8 +519,13 @@ func (h *OpenAIAPIHandler) handleNonStreamingResponse(c *gin.Context, rawJSON []
 	modelName := gjson.GetBytes(rawJSON, "model").String()
 	cliCtx, cliCancel := h.GetContextWithCancel(h, c, context.Background())
 	resp, errMsg := h.ExecuteWithAuthManager(cliCtx, h.HandlerType(), modelName, rawJSON, h.GetAlt(c))
+	if errMsg == nil {
+		if n := requestedChoices(rawJSON); n > 1 {
+			resp, errMsg = h.fillChoices(c, cliCtx, modelName, rawJSON, resp, n)
+		}
+	}
 	if errMsg != nil {
-		h.WriteErrorResponse(c, errMsg)
+		h.WriteOpenAIErrorResponse(c, errMsg)
 		cliCancel(errMsg.Error)
 		return
 	}
@@ -455,7 +581,7 @@ func (h *OpenAIAPIHandler) handleCompletionsNonStreamingResponse(c *gin.Context,
 	cliCtx, cliCancel := h.GetContextWithCancel(h, c, context.Background())
 	resp, errMsg := h.ExecuteWithAuthManager(cliCtx, h.HandlerType(), modelName, chatCompletionsJSON, "")
 	if errMsg != nil {
-		h.WriteErrorResponse(c, errMsg)
+		h.WriteOpenAIErrorResponse(c, errMsg)
 		cliCancel(errMsg.Error)
 		return
 	}
@@ -496,6 +622,8 @@ func (h *OpenAIAPIHandler) handleCompletionsStreamingResponse(c *gin.Context, ra
 	cliCtx, cliCancel := h.GetContextWithCancel(h, c, context.Background())
 	dataChan, errChan := h.ExecuteStreamWithAuthManager(cliCtx, h.HandlerType(), modelName, chatCompletionsJSON, "")
 
+	keepAlive := h.NewStreamKeepAlive()
+	defer keepAlive.Stop()
 	for {
 		select {
 		case <-c.Request.Context().Done():
@@ -508,17 +636,20 @@ func (h *OpenAIAPIHandler) handleCompletionsStreamingResponse(c *gin.Context, ra
 				cliCancel()
 				return
 			}
+			keepAlive.Touch()
 			converted := convertChatCompletionsStreamChunkToCompletions(chunk)
 			if converted != nil {
 				_, _ = fmt.Fprintf(c.Writer, "data: %s\n\n", string(converted))
 				flusher.Flush()
 			}
+		case <-keepAlive.C():
+			keepAlive.Write(c.Writer, flusher)
 		case errMsg, isOk := <-errChan:
 			if !isOk {
 				continue
 			}
 			if errMsg != nil {
-				h.WriteErrorResponse(c, errMsg)
+				h.WriteOpenAIErrorResponse(c, errMsg)
 				flusher.Flush()
 			}
 			var execErr error
@@ -530,7 +661,43 @@ func (h *OpenAIAPIHandler) handleCompletionsStreamingResponse(c *gin.Context, ra
 		}
 	}
 }
+
+// handleStreamResult forwards a chat completion stream to the client. An
+// upstream failure after content was sent ends the stream with a terminal
+// chunk when salvage-partial-streams is on, and with an error otherwise.
 func (h *OpenAIAPIHandler) handleStreamResult(c *gin.Context, flusher http.Flusher, cancel func(error), data <-chan []byte, errs <-chan *interfaces.ErrorMessage) {
+	keepAlive := h.NewStreamKeepAlive()
+	defer keepAlive.Stop()
+	salvage := h.NewStreamSalvage()
+	writeChunk := func(chunk []byte) {
+		salvage.Observe(chunk)
+		// Check if chunk is already in SSE format (bytes comparison, no string alloc)
+		if len(chunk) > 6 && (bytes.HasPrefix(chunk, sseEventPrefix) || bytes.HasPrefix(chunk, sseDataPrefix)) {
+			_, _ = c.Writer.Write(chunk)
+		} else {
+			_, _ = c.Writer.Write(sseDataPrefix)
+			_, _ = c.Writer.Write(chunk)
+			_, _ = c.Writer.Write(sseNewline)
+		}
+	}
+	fail := func(errMsg *interfaces.ErrorMessage) {
+		if terminal, salvaged := salvage.Terminal(errMsg); salvaged {
+			if terminal != nil {
+				_, _ = c.Writer.Write(sseDataPrefix)
+				_, _ = c.Writer.Write(terminal)
+				_, _ = c.Writer.Write(sseNewline)
+			}
+			_, _ = fmt.Fprintf(c.Writer, "data: [DONE]\n\n")
+		} else if errMsg != nil {
+			h.WriteOpenAIErrorResponse(c, errMsg)
+		}
+		flusher.Flush()
+		var execErr error
+		if errMsg != nil {
+			execErr = errMsg.Error
+		}
+		cancel(execErr)
+	}
 	for {
 		select {
 		case <-c.Request.Context().Done():
@@ -538,33 +705,37 @@ func (h *OpenAIAPIHandler) handleStreamResult(c *gin.Context, flusher http.Flush
 			return
 		case chunk, ok := <-data:
 			if !ok {
+				// The data channel can close before a pending error is read.
+				select {
+				case errMsg, ok := <-errs:
+					if ok && errMsg != nil {
+						fail(errMsg)
+						return
+					}
+				default:
+				}
 				_, _ = fmt.Fprintf(c.Writer, "data: [DONE]\n\n")
 				flusher.Flush()
 				cancel(nil)
 				return
 			}
-			// Check if chunk is already in SSE format (bytes comparison, no string alloc)
-			if len(chunk) > 6 && (bytes.HasPrefix(chunk, sseEventPrefix) || bytes.HasPrefix(chunk, sseDataPrefix)) {
-				_, _ = c.Writer.Write(chunk)
-			} else {
-				_, _ = c.Writer.Write(sseDataPrefix)
-				_, _ = c.Writer.Write(chunk)
-				_, _ = c.Writer.Write(sseNewline)
-			}
+			keepAlive.Touch()
+			writeChunk(chunk)
 			flusher.Flush()
+		case <-keepAlive.C():
+			keepAlive.Write(c.Writer, flusher)
 		case errMsg, ok := <-errs:
 			if !ok {
 				continue
 			}
-			if errMsg != nil {
-				h.WriteErrorResponse(c, errMsg)
-				flusher.Flush()
-			}
-			var execErr error
-			if errMsg != nil {
-				execErr = errMsg.Error
+			if salvage != nil && data != nil {
+				// Chunks sent before the error may still be buffered; the
+				// channel closes right after the error.
+				for chunk := range data {
+					writeChunk(chunk)
+				}
 			}
-			cancel(execErr)
+			fail(errMsg)
 			return
 		}
 	}
@@ -103,7 +103,7 @@ func (h *OpenAIResponsesAPIHandler) handleNonStreamingResponse(c *gin.Context, r
 
 	resp, errMsg := h.ExecuteWithAuthManager(cliCtx, h.HandlerType(), modelName, rawJSON, "")
 	if errMsg != nil {
-		h.WriteErrorResponse(c, errMsg)
+		h.WriteOpenAIErrorResponse(c, errMsg)
 		return
 	}
 	_, _ = c.Writer.Write(resp)
@@ -142,6 +142,9 @@ func (h *OpenAIResponsesAPIHandler) handleStreamingResponse(c *gin.Context, rawJ
 }
 
 func (h *OpenAIResponsesAPIHandler) forwardResponsesStream(c *gin.Context, flusher http.Flusher, cancel func(error), data <-chan []byte, errs <-chan *interfaces.ErrorMessage) {
+	keepAlive := h.NewStreamKeepAlive()
+	defer keepAlive.Stop()
+	midEvent := false
 	for {
 		select {
 		case <-c.Request.Context().Done():
@@ -162,12 +165,19 @@ func (h *OpenAIResponsesAPIHandler) forwardResponsesStream(c *gin.Context, flush
 			_, _ = c.Writer.Write([]byte("\n"))
 
 			flusher.Flush()
+			midEvent = bytes.HasPrefix(chunk, []byte("event:")) && !bytes.Contains(chunk, []byte("\ndata:"))
+			keepAlive.Touch()
+		case <-keepAlive.C():
+			// Never split an event line from its data line
+			if !midEvent {
+				keepAlive.Write(c.Writer, flusher)
+			}
 		case errMsg, ok := <-errs:
 			if !ok {
 				continue
 			}
 			if errMsg != nil {
-				h.WriteErrorResponse(c, errMsg)
+				h.WriteOpenAIErrorResponse(c, errMsg)
 				flusher.Flush()
 			}
 			var execErr error
@@ -0,0 +1,111 @@
+package openai
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/api/handlers/format"
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/registry"
+)
+
+// endlessExecutor streams chunks until its context is cancelled, then
+// closes upstreamDone.
+type endlessExecutor struct {
+	upstreamDone chan struct{}
+}
+
+func (e *endlessExecutor) Identifier() string { return "endless" }
+
+func (e *endlessExecutor) Execute(context.Context, *provider.Auth, provider.Request, provider.Options) (provider.Response, error) {
+	return provider.Response{}, nil
+}
+
+func (e *endlessExecutor) Refresh(_ context.Context, auth *provider.Auth) (*provider.Auth, error) {
+	return auth, nil
+}
+
+func (e *endlessExecutor) CountTokens(context.Context, *provider.Auth, provider.Request, provider.Options) (provider.Response, error) {
+	return provider.Response{}, nil
+}
+
+func (e *endlessExecutor) ExecuteStream(ctx context.Context, _ *provider.Auth, _ provider.Request, _ provider.Options) (<-chan provider.StreamChunk, error) {
+	out := make(chan provider.StreamChunk)
+	go func() {
+		defer close(out)
+		defer close(e.upstreamDone)
+		chunk := []byte(`data: {"id":"c","object":"chat.completion.chunk","choices":[{"index":0,"delta":{"content":"x"}}]}` + "\n\n")
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(5 * time.Millisecond):
+			}
+			select {
+			case out <- provider.StreamChunk{Payload: chunk}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+func TestStreamingClientDisconnectCancelsUpstream(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	reg := registry.GetGlobalRegistry()
+	reg.RegisterClient("endless-1", "endless", []*registry.ModelInfo{{ID: "endless-model"}})
+	defer reg.UnregisterClient("endless-1")
+
+	exec := &endlessExecutor{upstreamDone: make(chan struct{})}
+	mgr := provider.NewManager(nil, nil, nil)
+	mgr.RegisterExecutor(exec)
+	if _, err := mgr.Register(context.Background(), &provider.Auth{ID: "endless-1", Provider: "endless"}); err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewOpenAIAPIHandler(format.NewBaseAPIHandlers(&config.SDKConfig{}, nil, mgr, nil))
+	engine := gin.New()
+	engine.POST("/v1/chat/completions", h.ChatCompletions)
+	srv := httptest.NewServer(engine)
+	defer srv.Close()
+
+	body := strings.NewReader(`{"model":"endless-model","stream":true,"messages":[{"role":"user","content":"hi"}]}`)
+	resp, err := http.Post(srv.URL+"/v1/chat/completions", "application/json", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader := bufio.NewReader(resp.Body)
+	for received := 0; received < 3; {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read stream: %v", err)
+		}
+		if strings.HasPrefix(line, "data: ") {
+			received++
+		}
+	}
+	// Hang up partway through the stream.
+	_ = resp.Body.Close()
+
+	select {
+	case <-exec.upstreamDone:
+	case <-time.After(2 * time.Second):
+		t.Fatal("upstream stream still running after the client disconnected")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for mgr.CancelledStreams() != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("cancelled streams = %d, want 1", mgr.CancelledStreams())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
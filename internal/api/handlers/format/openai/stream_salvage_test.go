@@ -0,0 +1,96 @@
+package openai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/api/handlers/format"
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/registry"
+	"github.com/tidwall/gjson"
+)
+
+// failingStreamExecutor streams two content chunks and then fails.
+type failingStreamExecutor struct{ endlessExecutor }
+
+func (e *failingStreamExecutor) Identifier() string { return "failing-stream" }
+
+func (e *failingStreamExecutor) ExecuteStream(context.Context, *provider.Auth, provider.Request, provider.Options) (<-chan provider.StreamChunk, error) {
+	out := make(chan provider.StreamChunk, 3)
+	for _, text := range []string{"Hello", ", wor"} {
+		out <- provider.StreamChunk{Payload: []byte(`data: {"id":"chatcmpl-1","object":"chat.completion.chunk","created":1700000000,"model":"failing-model","choices":[{"index":0,"delta":{"content":"` + text + `"}}]}` + "\n\n")}
+	}
+	out <- provider.StreamChunk{Err: &provider.Error{Code: "upstream_reset", Message: "connection reset by peer", HTTPStatus: http.StatusBadGateway}}
+	close(out)
+	return out, nil
+}
+
+func streamFailingUpstream(t *testing.T, salvage bool) string {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	reg := registry.GetGlobalRegistry()
+	reg.RegisterClient("failing-stream-1", "failing-stream", []*registry.ModelInfo{{ID: "failing-model"}})
+	t.Cleanup(func() { reg.UnregisterClient("failing-stream-1") })
+
+	mgr := provider.NewManager(nil, nil, nil)
+	mgr.RegisterExecutor(&failingStreamExecutor{})
+	if _, err := mgr.Register(context.Background(), &provider.Auth{ID: "failing-stream-1", Provider: "failing-stream"}); err != nil {
+		t.Fatal(err)
+	}
+	h := NewOpenAIAPIHandler(format.NewBaseAPIHandlers(&config.SDKConfig{SalvagePartialStreams: salvage}, nil, mgr, nil))
+	engine := gin.New()
+	engine.POST("/v1/chat/completions", h.ChatCompletions)
+
+	body := `{"model":"failing-model","stream":true,"messages":[{"role":"user","content":"hi"}]}`
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body)))
+	return w.Body.String()
+}
+
+// lastEvent returns the data of the last SSE event before any [DONE].
+func lastEvent(stream string) string {
+	var last string
+	for _, line := range strings.Split(stream, "\n") {
+		if data, ok := strings.CutPrefix(line, "data: "); ok && data != "[DONE]" {
+			last = data
+		}
+	}
+	return last
+}
+
+func TestStreamSalvage_EndsWithTerminalChunk(t *testing.T) {
+	stream := streamFailingUpstream(t, true)
+	if !strings.HasSuffix(stream, "data: [DONE]\n\n") {
+		t.Fatalf("salvaged stream does not end with [DONE]:\n%s", stream)
+	}
+	last := gjson.Parse(lastEvent(stream))
+	if got := last.Get("choices.0.finish_reason").String(); got != "length" {
+		t.Errorf("finish_reason = %q, want length", got)
+	}
+	if last.Get("id").String() != "chatcmpl-1" || last.Get("model").String() != "failing-model" {
+		t.Errorf("terminal chunk lost the stream's id or model: %s", last.Raw)
+	}
+	partial := last.Get("llm_mux_partial")
+	if partial.Get("finish_reason").String() != "error" || partial.Get("content").String() != "Hello, wor" {
+		t.Errorf("llm_mux_partial = %s, want the error reason and the content sent so far", partial.Raw)
+	}
+	if !strings.Contains(partial.Get("error.message").String(), "connection reset") {
+		t.Errorf("llm_mux_partial.error = %s, want the upstream error", partial.Get("error").Raw)
+	}
+}
+
+func TestStreamSalvage_HardFailByDefault(t *testing.T) {
+	stream := streamFailingUpstream(t, false)
+	if strings.Contains(stream, "[DONE]") || strings.Contains(stream, "llm_mux_partial") {
+		t.Fatalf("stream was salvaged while the option is off:\n%s", stream)
+	}
+	last := gjson.Parse(lastEvent(stream))
+	if !strings.Contains(last.Get("error.message").String(), "connection reset") {
+		t.Errorf("last event = %s, want the upstream error", last.Raw)
+	}
+}
@@ -0,0 +1,37 @@
+package format
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimeoutHeader lets a client set the upstream timeout of one request, such
+// as "120s" or "120", replacing the provider's configured request timeout.
+const TimeoutHeader = "X-LLM-Mux-Timeout"
+
+// parseRequestTimeout reads a TimeoutHeader value. A value above limit is
+// clamped to it, and an invalid one is ignored; both come with a warning for
+// the WarningsHeader. A zero timeout means the provider's default applies.
+func parseRequestTimeout(value string, limit time.Duration) (time.Duration, string) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, ""
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		seconds, errInt := strconv.Atoi(value)
+		if errInt != nil {
+			return 0, fmt.Sprintf("ignored invalid %s %q", TimeoutHeader, value)
+		}
+		timeout = time.Duration(seconds) * time.Second
+	}
+	if timeout <= 0 {
+		return 0, fmt.Sprintf("ignored invalid %s %q", TimeoutHeader, value)
+	}
+	if timeout > limit {
+		return limit, fmt.Sprintf("%s %s clamped to the server maximum of %s", TimeoutHeader, timeout, limit)
+	}
+	return timeout, ""
+}
@@ -0,0 +1,82 @@
+package format
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/config"
+	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+func TestParseRequestTimeout(t *testing.T) {
+	const limit = 10 * time.Minute
+	tests := []struct {
+		name        string
+		value       string
+		want        time.Duration
+		wantWarning bool
+	}{
+		{"unset", "", 0, false},
+		{"duration", "120s", 120 * time.Second, false},
+		{"seconds", "90", 90 * time.Second, false},
+		{"minutes", "2m", 2 * time.Minute, false},
+		{"at limit", "10m", limit, false},
+		{"over limit", "1h", limit, true},
+		{"over limit seconds", "3600", limit, true},
+		{"garbage", "soon", 0, true},
+		{"zero", "0s", 0, true},
+		{"negative", "-5s", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, warning := parseRequestTimeout(tt.value, limit)
+			if got != tt.want || (warning != "") != tt.wantWarning {
+				t.Errorf("parseRequestTimeout(%q) = %s, %q; want %s, warning %v", tt.value, got, warning, tt.want, tt.wantWarning)
+			}
+		})
+	}
+}
+
+func TestGetContextWithCancel_RequestTimeout(t *testing.T) {
+	h := &BaseAPIHandler{Cfg: &config.SDKConfig{MaxRequestTimeout: 300}}
+	for _, tt := range []struct {
+		value       string
+		want        time.Duration
+		wantWarning bool
+	}{
+		{"120s", 120 * time.Second, false},
+		{"1h", 300 * time.Second, true},
+		{"later", 0, true},
+	} {
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+		c.Request.Header.Set(TimeoutHeader, tt.value)
+		ctx, cancel := h.GetContextWithCancel(nil, c, context.Background())
+		got, _ := provider.RequestTimeoutFromContext(ctx)
+		cancel()
+		if got != tt.want {
+			t.Errorf("%s %q: timeout %s, want %s", TimeoutHeader, tt.value, got, tt.want)
+		}
+		if warned := rec.Header().Get(WarningsHeader) != ""; warned != tt.wantWarning {
+			t.Errorf("%s %q: warning header %q, want warning %v", TimeoutHeader, tt.value, rec.Header().Get(WarningsHeader), tt.wantWarning)
+		}
+	}
+}
+
+func TestGetContextWithCancel_RequestID(t *testing.T) {
+	h := &BaseAPIHandler{Cfg: &config.SDKConfig{}}
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	c.Set(log.RequestIDKey, "trace-42")
+	ctx, cancel := h.GetContextWithCancel(nil, c, context.Background())
+	defer cancel()
+	if got := log.RequestIDFromContext(ctx); got != "trace-42" {
+		t.Errorf("upstream context request ID = %q, want trace-42", got)
+	}
+}
@@ -0,0 +1,93 @@
+package format
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/registry"
+)
+
+// RouteExplanation is the routing decision for a request, as computed by
+// ExplainRoute.
+type RouteExplanation struct {
+	// RequestedModel is the model name as sent by the client.
+	RequestedModel string `json:"requested_model"`
+	// Family is the canonical model family the model resolved to, if any.
+	Family string `json:"family,omitempty"`
+	// Required lists the capabilities the request needs.
+	Required string `json:"required_capabilities,omitempty"`
+	// FilteredMembers are family members left out before account selection.
+	FilteredMembers []FilteredMember `json:"filtered_members,omitempty"`
+	// Error is the error the request would fail with before reaching a
+	// provider, if any.
+	Error string `json:"error,omitempty"`
+	provider.RouteExplanation
+}
+
+// FilteredMember is a family member that cannot take the request.
+type FilteredMember struct {
+	Provider string `json:"provider"`
+	Model    string `json:"model"`
+	Reason   string `json:"reason"`
+}
+
+// ExplainRoute resolves modelName the way a request with body rawJSON would
+// be resolved and reports where it would be sent, without calling upstream.
+// required adds capabilities to those the body relies on.
+func (h *BaseAPIHandler) ExplainRoute(ctx context.Context, modelName string, rawJSON []byte, required registry.Capability) RouteExplanation {
+	out := RouteExplanation{RequestedModel: modelName}
+	required |= requiredCapabilities(rawJSON)
+	if required != 0 {
+		out.Required = required.String()
+	}
+	providers, normalizedModel, _, errMsg := h.getRequestDetails(modelName, required)
+
+	var family string
+	var capErr *registry.CapabilityError
+	switch {
+	case errMsg != nil && errors.As(errMsg.Error, &capErr):
+		family = capErr.Family
+	case errMsg == nil && registry.IsCanonicalID(normalizedModel):
+		family = normalizedModel
+	}
+	if family != "" {
+		out.Family = family
+		out.FilteredMembers = filteredMembers(family, required, providers)
+	}
+	if errMsg != nil {
+		if errMsg.Error != nil {
+			out.Error = errMsg.Error.Error()
+		}
+		return out
+	}
+	if h.AuthManager != nil {
+		out.RouteExplanation = h.AuthManager.ExplainRoute(ctx, providers, normalizedModel, provider.Options{Required: required})
+	}
+	return out
+}
+
+// filteredMembers lists the members of family that getRequestDetails leaves
+// out: those lacking a required capability and those whose provider has no
+// registered account for the model.
+func filteredMembers(family string, required registry.Capability, providers []string) []FilteredMember {
+	members, err := registry.ResolveModelFamily(family, 0)
+	if err != nil {
+		return nil
+	}
+	capable := members
+	if required != 0 {
+		capable, _ = registry.ResolveModelFamily(family, required)
+	}
+	var out []FilteredMember
+	for _, m := range members {
+		switch {
+		case !slices.Contains(capable, m):
+			out = append(out, FilteredMember{Provider: m.Provider, Model: m.Model, Reason: "missing capability " + required.String()})
+		case !slices.Contains(providers, m.Provider):
+			out = append(out, FilteredMember{Provider: m.Provider, Model: m.Model, Reason: "no account serves the model"})
+		}
+	}
+	return out
+}
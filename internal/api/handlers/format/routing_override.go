@@ -0,0 +1,89 @@
+package format
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/interfaces"
+	"github.com/nghyane/llm-mux/internal/registry"
+)
+
+const (
+	// ProviderOverrideHeader forces a request onto one provider.
+	ProviderOverrideHeader = "X-LLM-Mux-Provider"
+	// AccountOverrideHeader forces a request onto one account by its ID.
+	AccountOverrideHeader = "X-LLM-Mux-Account"
+)
+
+// routingOverride is the provider or account a client pinned a request to.
+type routingOverride struct {
+	provider string
+	account  string
+	// apiKey is the client key the request authenticated with.
+	apiKey string
+}
+
+func (o routingOverride) active() bool { return o.provider != "" || o.account != "" }
+
+// routingOverrideFrom reads the override headers of the request behind ctx.
+func routingOverrideFrom(ctx context.Context) routingOverride {
+	c, ok := ctx.Value(ctxKeyGin).(*gin.Context)
+	if !ok || c == nil || c.Request == nil {
+		return routingOverride{}
+	}
+	return routingOverride{
+		provider: strings.ToLower(strings.TrimSpace(c.GetHeader(ProviderOverrideHeader))),
+		account:  strings.TrimSpace(c.GetHeader(AccountOverrideHeader)),
+		apiKey:   c.GetString("apiKey"),
+	}
+}
+
+// applyRoutingOverride narrows providers to the target the client pinned the
+// request to and returns the pinned account ID, if any. Overrides bypass
+// normal selection, so they are refused unless routing-override is enabled
+// and the client key is listed in routing-override-keys, and a target that cannot serve model fails with 400 instead of silently
+// falling back to normal routing. required picks the family member a pinned
+// account must serve.
+func (h *BaseAPIHandler) applyRoutingOverride(ctx context.Context, providers []string, model string, required registry.Capability) ([]string, string, *interfaces.ErrorMessage) {
+	o := routingOverrideFrom(ctx)
+	if !o.active() {
+		return providers, "", nil
+	}
+	if h.Cfg == nil || !h.Cfg.RoutingOverride {
+		return nil, "", &interfaces.ErrorMessage{StatusCode: http.StatusForbidden, Error: fmt.Errorf("routing override headers are disabled; set routing-override: true to use %s or %s", ProviderOverrideHeader, AccountOverrideHeader)}
+	}
+	if !h.Cfg.RoutingOverrideAllowed(o.apiKey) {
+		return nil, "", &interfaces.ErrorMessage{StatusCode: http.StatusForbidden, Error: fmt.Errorf("this API key may not use %s or %s; add it to routing-override-keys", ProviderOverrideHeader, AccountOverrideHeader)}
+	}
+
+	target := o.provider
+	if o.account != "" {
+		if h.AuthManager == nil {
+			return nil, "", &interfaces.ErrorMessage{StatusCode: http.StatusBadRequest, Error: fmt.Errorf("account %q not found", o.account)}
+		}
+		auth, ok := h.AuthManager.GetByID(o.account)
+		if !ok || auth == nil || auth.Disabled {
+			return nil, "", &interfaces.ErrorMessage{StatusCode: http.StatusBadRequest, Error: fmt.Errorf("account %q not found or disabled", o.account)}
+		}
+		accountProvider := strings.ToLower(auth.Provider)
+		if target != "" && target != accountProvider {
+			return nil, "", &interfaces.ErrorMessage{StatusCode: http.StatusBadRequest, Error: fmt.Errorf("account %q belongs to provider %s, not %s", o.account, accountProvider, target)}
+		}
+		target = accountProvider
+	}
+
+	if !slices.ContainsFunc(providers, func(p string) bool { return strings.EqualFold(p, target) }) {
+		return nil, "", &interfaces.ErrorMessage{StatusCode: http.StatusBadRequest, Error: fmt.Errorf("provider %s cannot serve model %s", target, model)}
+	}
+	if o.account != "" {
+		reg := registry.GetGlobalRegistry()
+		if !reg.ClientSupportsModel(o.account, reg.GetModelIDForProvider(model, target, required)) {
+			return nil, "", &interfaces.ErrorMessage{StatusCode: http.StatusBadRequest, Error: fmt.Errorf("account %q cannot serve model %s", o.account, model)}
+		}
+	}
+	return []string{target}, o.account, nil
+}
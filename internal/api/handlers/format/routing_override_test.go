@@ -0,0 +1,111 @@
+package format
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/registry"
+)
+
+func overrideContext(headers map[string]string) context.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	for k, v := range headers {
+		c.Request.Header.Set(k, v)
+	}
+	return context.WithValue(context.Background(), ctxKeyGin, c)
+}
+
+func TestApplyRoutingOverride(t *testing.T) {
+	reg := registry.GetGlobalRegistry()
+	reg.RegisterClient("override-claude", "claude", []*registry.ModelInfo{{ID: "override-model"}})
+	defer reg.UnregisterClient("override-claude")
+	reg.RegisterClient("override-qwen", "qwen", []*registry.ModelInfo{{ID: "other-model"}})
+	defer reg.UnregisterClient("override-qwen")
+
+	manager := provider.NewManager(nil, nil, nil)
+	for id, p := range map[string]string{"override-claude": "claude", "override-qwen": "qwen"} {
+		if _, err := manager.Register(context.Background(), &provider.Auth{ID: id, Provider: p}); err != nil {
+			t.Fatalf("register %s: %v", id, err)
+		}
+	}
+	h := &BaseAPIHandler{Cfg: &config.SDKConfig{RoutingOverride: true}, AuthManager: manager}
+	providers := []string{"claude", "qwen"}
+
+	tests := []struct {
+		name          string
+		headers       map[string]string
+		wantProviders []string
+		wantAuth      string
+		wantStatus    int
+	}{
+		{"no override", nil, providers, "", 0},
+		{"provider", map[string]string{ProviderOverrideHeader: "Claude"}, []string{"claude"}, "", 0},
+		{"account", map[string]string{AccountOverrideHeader: "override-claude"}, []string{"claude"}, "override-claude", 0},
+		{"provider without model", map[string]string{ProviderOverrideHeader: "gemini"}, nil, "", http.StatusBadRequest},
+		{"unknown account", map[string]string{AccountOverrideHeader: "missing"}, nil, "", http.StatusBadRequest},
+		{"account without model", map[string]string{AccountOverrideHeader: "override-qwen"}, nil, "", http.StatusBadRequest},
+		{"account of other provider", map[string]string{ProviderOverrideHeader: "qwen", AccountOverrideHeader: "override-claude"}, nil, "", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, authID, errMsg := h.applyRoutingOverride(overrideContext(tt.headers), providers, "override-model", 0)
+			if tt.wantStatus != 0 {
+				if errMsg == nil || errMsg.StatusCode != tt.wantStatus {
+					t.Fatalf("err = %v, want status %d", errMsg, tt.wantStatus)
+				}
+				return
+			}
+			if errMsg != nil {
+				t.Fatalf("unexpected error: %v", errMsg.Error)
+			}
+			if len(got) != len(tt.wantProviders) || got[0] != tt.wantProviders[0] || authID != tt.wantAuth {
+				t.Errorf("got providers=%v auth=%q, want %v %q", got, authID, tt.wantProviders, tt.wantAuth)
+			}
+		})
+	}
+}
+
+func TestApplyRoutingOverride_ClientKeys(t *testing.T) {
+	reg := registry.GetGlobalRegistry()
+	reg.RegisterClient("override-keys-claude", "claude", []*registry.ModelInfo{{ID: "override-keys-model"}})
+	defer reg.UnregisterClient("override-keys-claude")
+
+	h := &BaseAPIHandler{Cfg: &config.SDKConfig{RoutingOverride: true, RoutingOverrideKeys: []string{"sk-debug"}}}
+	for _, tt := range []struct {
+		apiKey     string
+		wantStatus int
+	}{
+		{"sk-debug", 0},
+		{"sk-other", http.StatusForbidden},
+		{"", 0},
+	} {
+		ctx := overrideContext(map[string]string{ProviderOverrideHeader: "claude"})
+		if tt.apiKey != "" {
+			ctx.Value(ctxKeyGin).(*gin.Context).Set("apiKey", tt.apiKey)
+		}
+		_, _, errMsg := h.applyRoutingOverride(ctx, []string{"claude"}, "override-keys-model", 0)
+		switch {
+		case tt.wantStatus == 0 && errMsg != nil:
+			t.Errorf("key %q: unexpected error: %v", tt.apiKey, errMsg.Error)
+		case tt.wantStatus != 0 && (errMsg == nil || errMsg.StatusCode != tt.wantStatus):
+			t.Errorf("key %q: err = %v, want status %d", tt.apiKey, errMsg, tt.wantStatus)
+		}
+	}
+}
+
+func TestApplyRoutingOverride_Disabled(t *testing.T) {
+	h := &BaseAPIHandler{Cfg: &config.SDKConfig{}}
+	ctx := overrideContext(map[string]string{ProviderOverrideHeader: "claude"})
+	if _, _, errMsg := h.applyRoutingOverride(ctx, []string{"claude"}, "m", 0); errMsg == nil || errMsg.StatusCode != http.StatusForbidden {
+		t.Fatalf("err = %v, want 403", errMsg)
+	}
+	if len(h.fallbacksFor(ctx, "m")) != 0 {
+		t.Error("pinned request must not use fallbacks")
+	}
+}
@@ -0,0 +1,131 @@
+package format
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/interfaces"
+	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+// flight is one upstream call shared by identical in-flight requests.
+type flight struct {
+	done    chan struct{}
+	payload []byte
+	notes   callNotes
+	err     *interfaces.ErrorMessage
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// requestFlights collapses concurrent identical non-streaming requests into a
+// single upstream call. The zero value is ready to use.
+type requestFlights struct {
+	mu    sync.Mutex
+	calls map[string]*flight
+}
+
+// do runs fn once for all concurrent callers with the same key. The shared
+// call runs on a context detached from any one caller (see sharedCallContext),
+// so a caller that goes away does not abort it for the rest; it is cancelled
+// only once every caller has gone.
+func (g *requestFlights) do(ctx context.Context, key string, fn func(context.Context) ([]byte, callNotes, *interfaces.ErrorMessage)) ([]byte, callNotes, *interfaces.ErrorMessage) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flight)
+	}
+	f, ok := g.calls[key]
+	if !ok {
+		callCtx, cancel := context.WithCancel(sharedCallContext(ctx))
+		f = &flight{done: make(chan struct{}), cancel: cancel}
+		g.calls[key] = f
+		go func() {
+			defer cancel()
+			f.payload, f.notes, f.err = fn(callCtx)
+			g.mu.Lock()
+			g.forget(key, f)
+			g.mu.Unlock()
+			close(f.done)
+		}()
+	}
+	f.waiters++
+	g.mu.Unlock()
+
+	select {
+	case <-f.done:
+		// Callers may rewrite their payload; give each its own copy.
+		return bytes.Clone(f.payload), f.notes, f.err
+	case <-ctx.Done():
+		g.mu.Lock()
+		f.waiters--
+		if f.waiters == 0 {
+			g.forget(key, f)
+			f.cancel()
+		}
+		g.mu.Unlock()
+		return nil, callNotes{}, &interfaces.ErrorMessage{StatusCode: statusClientClosedRequest, Error: ctx.Err()}
+	}
+}
+
+// sharedCallContext returns a fresh context carrying the values a shared
+// call reads from the first caller's ctx. Its gin context is a copy: gin
+// reuses the original once that request ends, possibly while the call is
+// still running for the other callers.
+func sharedCallContext(ctx context.Context) context.Context {
+	out := context.Background()
+	if c, ok := ctx.Value(ctxKeyGin).(*gin.Context); ok && c != nil {
+		out = context.WithValue(out, ctxKeyGin, c.Copy())
+	}
+	if handler := ctx.Value(ctxKeyHandler); handler != nil {
+		out = context.WithValue(out, ctxKeyHandler, handler)
+	}
+	if spec, ok := provider.FaultSpecFromContext(ctx); ok {
+		out = provider.WithFaultSpec(out, spec)
+	}
+	if selector := provider.LabelSelectorFromContext(ctx); len(selector) > 0 {
+		out = provider.WithLabelSelector(out, selector)
+	}
+	if timeout, ok := provider.RequestTimeoutFromContext(ctx); ok {
+		out = provider.WithRequestTimeout(out, timeout)
+	}
+	if id := log.RequestIDFromContext(ctx); id != "" {
+		out = log.WithRequestID(out, id)
+	}
+	return out
+}
+
+// forget drops f from the table unless a newer flight already replaced it.
+// The caller must hold g.mu.
+func (g *requestFlights) forget(key string, f *flight) {
+	if g.calls[key] == f {
+		delete(g.calls, key)
+	}
+}
+
+// statusClientClosedRequest reports a caller that stopped waiting.
+const statusClientClosedRequest = 499
+
+// requestHash identifies a non-streaming request for deduplication. Requests
+// only share a result when the handler format, client key, alt, model,
+// routing override, client fallback models and body are all identical.
+func requestHash(ctx context.Context, handlerType, modelName, alt string, rawJSON []byte) string {
+	var principal string
+	if c, ok := ctx.Value(ctxKeyGin).(*gin.Context); ok && c != nil {
+		principal = c.GetString("apiKey")
+	}
+	override := routingOverrideFrom(ctx)
+	fallbacks := strings.Join(clientFallbacksFrom(ctx, modelName), ",")
+	h := sha256.New()
+	for _, part := range []string{handlerType, principal, alt, modelName, override.provider, override.account, fallbacks} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	h.Write(rawJSON)
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,236 @@
+package format
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/interfaces"
+	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+func TestRequestFlights_CollapsesIdenticalCalls(t *testing.T) {
+	var g requestFlights
+	var calls atomic.Int32
+	release := make(chan struct{})
+	fn := func(context.Context) ([]byte, callNotes, *interfaces.ErrorMessage) {
+		calls.Add(1)
+		<-release
+		return []byte(`{"ok":true}`), callNotes{warnings: []string{"w"}}, nil
+	}
+
+	const n = 8
+	results := make([][]byte, n)
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for i := range n {
+		go func() {
+			defer wg.Done()
+			results[i], _, _ = g.do(context.Background(), "k", fn)
+		}()
+	}
+	waitForWaiters(t, &g, "k", n)
+	close(release)
+	wg.Wait()
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("upstream calls = %d, want 1", got)
+	}
+	for i, r := range results {
+		if string(r) != `{"ok":true}` {
+			t.Errorf("result %d = %s", i, r)
+		}
+	}
+	if len(g.calls) != 0 {
+		t.Errorf("finished flight was not forgotten")
+	}
+}
+
+func TestRequestFlights_WaiterCancelDoesNotAbortOthers(t *testing.T) {
+	var g requestFlights
+	release := make(chan struct{})
+	var upstreamErr error
+	fn := func(ctx context.Context) ([]byte, callNotes, *interfaces.ErrorMessage) {
+		select {
+		case <-release:
+			return []byte("done"), callNotes{}, nil
+		case <-ctx.Done():
+			upstreamErr = ctx.Err()
+			return nil, callNotes{}, &interfaces.ErrorMessage{StatusCode: 500, Error: ctx.Err()}
+		}
+	}
+
+	leaderCtx, cancelLeader := context.WithCancel(context.Background())
+	leaderDone := make(chan *interfaces.ErrorMessage, 1)
+	go func() {
+		_, _, errMsg := g.do(leaderCtx, "k", fn)
+		leaderDone <- errMsg
+	}()
+	waitForWaiters(t, &g, "k", 1)
+
+	followerDone := make(chan []byte, 1)
+	go func() {
+		payload, _, _ := g.do(context.Background(), "k", fn)
+		followerDone <- payload
+	}()
+	waitForWaiters(t, &g, "k", 2)
+
+	cancelLeader()
+	if errMsg := <-leaderDone; errMsg == nil || errMsg.StatusCode != statusClientClosedRequest {
+		t.Fatalf("cancelled waiter got %+v, want 499", errMsg)
+	}
+	close(release)
+	if got := <-followerDone; string(got) != "done" {
+		t.Errorf("follower payload = %q, want done", got)
+	}
+	if upstreamErr != nil {
+		t.Errorf("shared call was cancelled: %v", upstreamErr)
+	}
+}
+
+func TestRequestFlights_LastWaiterCancelAbortsCall(t *testing.T) {
+	var g requestFlights
+	aborted := make(chan struct{})
+	fn := func(ctx context.Context) ([]byte, callNotes, *interfaces.ErrorMessage) {
+		<-ctx.Done()
+		close(aborted)
+		return nil, callNotes{}, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		_, _, _ = g.do(ctx, "k", fn)
+		close(done)
+	}()
+	waitForWaiters(t, &g, "k", 1)
+	cancel()
+	<-done
+
+	select {
+	case <-aborted:
+	case <-time.After(time.Second):
+		t.Fatal("upstream call kept running after every waiter left")
+	}
+}
+
+func TestRequestFlights_SharedCallGetsDetachedGinContext(t *testing.T) {
+	var g requestFlights
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	c.Request.Header.Set(ProviderOverrideHeader, "claude")
+	c.Set("apiKey", "client-a")
+	type otherKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKeyGin, c)
+	ctx = context.WithValue(ctx, otherKey{}, "caller only")
+	ctx = provider.WithLabelSelector(ctx, map[string]string{"tier": "premium"})
+
+	started, release := make(chan struct{}), make(chan struct{})
+	seen := make(chan string, 4)
+	fn := func(callCtx context.Context) ([]byte, callNotes, *interfaces.ErrorMessage) {
+		close(started)
+		<-release
+		shared, _ := callCtx.Value(ctxKeyGin).(*gin.Context)
+		if shared == nil || shared == c {
+			seen <- "live gin context"
+			return nil, callNotes{}, nil
+		}
+		seen <- shared.GetString("apiKey")
+		seen <- shared.GetHeader(ProviderOverrideHeader)
+		seen <- provider.LabelSelectorFromContext(callCtx)["tier"]
+		if callCtx.Value(otherKey{}) != nil {
+			seen <- "caller value leaked"
+		}
+		return nil, callNotes{}, nil
+	}
+	done := make(chan struct{})
+	go func() {
+		_, _, _ = g.do(ctx, "k", fn)
+		close(done)
+	}()
+	<-started
+	// gin resets a pooled context for the next request once the first ends.
+	c.Keys = map[any]any{"apiKey": "client-b"}
+	close(release)
+	<-done
+	close(seen)
+
+	var got []string
+	for v := range seen {
+		got = append(got, v)
+	}
+	if want := []string{"client-a", "claude", "premium"}; !slices.Equal(got, want) {
+		t.Errorf("shared call saw %v, want %v", got, want)
+	}
+}
+
+func TestRequestFlights_SharedCallKeepsRequestID(t *testing.T) {
+	var g requestFlights
+	release := make(chan struct{})
+	seen := make(chan string, 1)
+	fn := func(callCtx context.Context) ([]byte, callNotes, *interfaces.ErrorMessage) {
+		<-release
+		seen <- log.RequestIDFromContext(callCtx)
+		return []byte("done"), callNotes{}, nil
+	}
+
+	var wg sync.WaitGroup
+	for i, id := range []string{"req-leader", "req-follower"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _, _ = g.do(log.WithRequestID(context.Background(), id), "k", fn)
+		}()
+		waitForWaiters(t, &g, "k", i+1)
+	}
+	close(release)
+	wg.Wait()
+
+	if got := <-seen; got != "req-leader" {
+		t.Errorf("shared call request ID = %q, want req-leader", got)
+	}
+}
+
+func TestRequestHash(t *testing.T) {
+	base := requestHash(context.Background(), "openai", "m", "", []byte(`{"a":1}`))
+	if base != requestHash(context.Background(), "openai", "m", "", []byte(`{"a":1}`)) {
+		t.Fatal("identical requests hash differently")
+	}
+	if base == requestHash(context.Background(), "openai", "m", "", []byte(`{"a":2}`)) {
+		t.Error("different bodies share a hash")
+	}
+	if base == requestHash(context.Background(), "claude", "m", "", []byte(`{"a":1}`)) {
+		t.Error("different handler formats share a hash")
+	}
+
+	c, _ := gin.CreateTestContext(nil)
+	c.Set("apiKey", "client-a")
+	withKey := context.WithValue(context.Background(), ctxKeyGin, c)
+	if base == requestHash(withKey, "openai", "m", "", []byte(`{"a":1}`)) {
+		t.Error("different clients share a hash")
+	}
+}
+
+// waitForWaiters blocks until the flight for key has n waiters.
+func waitForWaiters(t *testing.T, g *requestFlights, key string, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		g.mu.Lock()
+		f := g.calls[key]
+		ready := f != nil && f.waiters == n
+		g.mu.Unlock()
+		if ready {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("flight %q never reached %d waiters", key, n)
+}
@@ -0,0 +1,91 @@
+package format
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+
+	"github.com/nghyane/llm-mux/internal/interfaces"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// salvageField is the chat completion chunk field that explains a stream
+// ended early by salvage-partial-streams.
+const salvageField = "llm_mux_partial"
+
+// StreamSalvage follows an OpenAI chat completion stream so that, when the
+// upstream fails after sending content, the stream can end with a terminal
+// chunk instead of an error the client would discard its output for.
+type StreamSalvage struct {
+	id       string
+	model    string
+	created  int64
+	content  strings.Builder
+	finished bool
+}
+
+// NewStreamSalvage returns a salvage for one stream, or nil when
+// salvage-partial-streams is off.
+func (h *BaseAPIHandler) NewStreamSalvage() *StreamSalvage {
+	if h.Cfg == nil || !h.Cfg.SalvagePartialStreams {
+		return nil
+	}
+	return &StreamSalvage{}
+}
+
+// Observe records a chunk sent to the client, raw JSON or SSE framed.
+func (s *StreamSalvage) Observe(chunk []byte) {
+	if s == nil {
+		return
+	}
+	for _, line := range bytes.Split(chunk, []byte("\n")) {
+		line = bytes.TrimPrefix(bytes.TrimSpace(line), []byte("data:"))
+		if line = bytes.TrimSpace(line); len(line) == 0 || line[0] != '{' {
+			continue
+		}
+		fields := gjson.GetManyBytes(line, "id", "model", "created", "choices.0.delta.content", "choices.0.finish_reason")
+		if fields[0].String() != "" {
+			s.id = fields[0].String()
+		}
+		if fields[1].String() != "" {
+			s.model = fields[1].String()
+		}
+		if fields[2].Int() != 0 {
+			s.created = fields[2].Int()
+		}
+		s.content.WriteString(fields[3].String())
+		if fields[4].String() != "" {
+			s.finished = true
+		}
+	}
+}
+
+// Terminal returns the chunk that ends a stream failed by errMsg. It reports
+// false when there is nothing to salvage and the error should be sent as
+// usual; a nil chunk with true means the stream had already finished and
+// only needs closing.
+func (s *StreamSalvage) Terminal(errMsg *interfaces.ErrorMessage) ([]byte, bool) {
+	if s == nil || s.content.Len() == 0 {
+		return nil, false
+	}
+	if s.finished {
+		return nil, true
+	}
+	status := http.StatusInternalServerError
+	var err error
+	if errMsg != nil {
+		if errMsg.StatusCode > 0 {
+			status = errMsg.StatusCode
+		}
+		err = errMsg.Error
+	}
+	chunk := []byte(`{"object":"chat.completion.chunk","choices":[{"index":0,"delta":{},"finish_reason":"length"}]}`)
+	chunk, _ = sjson.SetBytes(chunk, "id", s.id)
+	chunk, _ = sjson.SetBytes(chunk, "created", s.created)
+	chunk, _ = sjson.SetBytes(chunk, "model", s.model)
+	chunk, _ = sjson.SetBytes(chunk, salvageField+".finish_reason", "error")
+	chunk, _ = sjson.SetBytes(chunk, salvageField+".content", s.content.String())
+	chunk, _ = sjson.SetRawBytes(chunk, salvageField+".error", []byte(gjson.GetBytes(OpenAIErrorBody(status, err), "error").Raw))
+	return chunk, true
+}
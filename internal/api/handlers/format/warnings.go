@@ -0,0 +1,61 @@
+package format
+
+import (
+	"bytes"
+	"context"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/sjson"
+)
+
+// WarningsHeader carries the non-fatal notices recorded while translating and
+// executing a request, such as a clamped max_tokens or dropped stop
+// sequences. Each notice is a separate header value.
+const WarningsHeader = "X-LLM-Mux-Warnings"
+
+// warningsField is the response body field that holds the same notices when
+// warnings-in-body is enabled.
+const warningsField = "llm_mux_warnings"
+
+// callNotes is what a non-streaming call reports besides its payload.
+type callNotes struct {
+	// warnings are the notices for WarningsHeader.
+	warnings []string
+	// servedModel is the fallback model that served the call, if any.
+	servedModel string
+}
+
+// writeWarnings adds each notice to the WarningsHeader of the response. It
+// must run before the response body is written.
+func writeWarnings(ctx context.Context, warnings []string) {
+	if len(warnings) == 0 {
+		return
+	}
+	c, ok := ctx.Value(ctxKeyGin).(*gin.Context)
+	if !ok || c == nil {
+		return
+	}
+	header := c.Writer.Header()
+	for _, w := range warnings {
+		if !slices.Contains(header.Values(WarningsHeader), w) {
+			header.Add(WarningsHeader, w)
+		}
+	}
+}
+
+// withBodyWarnings adds warnings to a JSON object payload when
+// warnings-in-body is enabled. Other payloads are returned unchanged.
+func (h *BaseAPIHandler) withBodyWarnings(payload []byte, warnings []string) []byte {
+	if len(warnings) == 0 || h.Cfg == nil || !h.Cfg.WarningsInBody {
+		return payload
+	}
+	if trimmed := bytes.TrimSpace(payload); len(trimmed) == 0 || trimmed[0] != '{' {
+		return payload
+	}
+	out, err := sjson.SetBytes(payload, warningsField, warnings)
+	if err != nil {
+		return payload
+	}
+	return out
+}
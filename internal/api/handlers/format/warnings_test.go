@@ -0,0 +1,93 @@
+package format
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/registry"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/tidwall/gjson"
+)
+
+// warningExecutor records a translation and an execution warning, as the
+// built-in executors do, and answers with an empty JSON object.
+type warningExecutor struct{}
+
+func (warningExecutor) Identifier() string { return "warn-test" }
+
+func (warningExecutor) Execute(_ context.Context, _ *provider.Auth, req provider.Request, _ provider.Options) (provider.Response, error) {
+	irReq := &ir.UnifiedChatRequest{Model: req.Model}
+	irReq.AddWarning("%s accepts at most %d stop sequences; %d were dropped", "warn-test", 5, 2)
+	irReq.AddWarning("%s accepts at most %d stop sequences; %d were dropped", "warn-test", 5, 2)
+	provider.AddWarnings(req.Metadata, irReq.Warnings...)
+	provider.AddWarnings(req.Metadata, "max_tokens clamped")
+	return provider.Response{Payload: []byte(`{"id":"x"}`)}, nil
+}
+
+func (warningExecutor) ExecuteStream(context.Context, *provider.Auth, provider.Request, provider.Options) (<-chan provider.StreamChunk, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (warningExecutor) Refresh(_ context.Context, auth *provider.Auth) (*provider.Auth, error) {
+	return auth, nil
+}
+
+func (warningExecutor) CountTokens(context.Context, *provider.Auth, provider.Request, provider.Options) (provider.Response, error) {
+	return provider.Response{}, nil
+}
+
+func TestWarningsCollectedAcrossStages(t *testing.T) {
+	reg := registry.GetGlobalRegistry()
+	reg.RegisterClient("warn-test-auth", "warn-test", []*registry.ModelInfo{{ID: "warn-test-model"}})
+	defer reg.UnregisterClient("warn-test-auth")
+
+	manager := provider.NewManager(nil, nil, nil)
+	manager.RegisterExecutor(warningExecutor{})
+	manager.Use(func(next provider.ProviderExecutor) provider.ProviderExecutor {
+		return &provider.ExecutorFuncs{Next: next, ExecuteFunc: func(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (provider.Response, error) {
+			provider.AddWarnings(req.Metadata, "middleware notice")
+			return next.Execute(ctx, auth, req, opts)
+		}}
+	})
+	if _, err := manager.Register(context.Background(), &provider.Auth{ID: "warn-test-auth", Provider: "warn-test"}); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"middleware notice", "warn-test accepts at most 5 stop sequences; 2 were dropped", "max_tokens clamped"}
+
+	for _, inBody := range []bool{false, true} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+		ctx := context.WithValue(context.Background(), ctxKeyGin, c)
+
+		h := &BaseAPIHandler{Cfg: &config.SDKConfig{WarningsInBody: inBody}, AuthManager: manager}
+		payload, errMsg := h.ExecuteWithAuthManager(ctx, "openai", "warn-test-model", []byte(`{"model":"warn-test-model"}`), "")
+		if errMsg != nil {
+			t.Fatalf("execute: %v", errMsg.Error)
+		}
+		if got := w.Header().Values(WarningsHeader); !slices.Equal(got, want) {
+			t.Errorf("%s = %q, want %q", WarningsHeader, got, want)
+		}
+		body := gjson.GetBytes(payload, warningsField)
+		if !inBody {
+			if body.Exists() {
+				t.Errorf("warnings in body while disabled: %s", payload)
+			}
+			continue
+		}
+		var got []string
+		for _, v := range body.Array() {
+			got = append(got, v.String())
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("body warnings = %q, want %q", got, want)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package management
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/auth/login"
+	"github.com/nghyane/llm-mux/internal/json"
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+const (
+	// bundlePassphraseHeader carries the passphrase used to encrypt an exported
+	// bundle or decrypt an imported one. A header keeps it out of access logs.
+	bundlePassphraseHeader = "X-Bundle-Passphrase"
+	accountBundleVersion   = "v1"
+	maxAccountBundleSize   = 1 * 1024 * 1024
+)
+
+// accountBundle is the portable form of an account's credentials.
+type accountBundle struct {
+	Version    string         `json:"llm_mux_bundle"`
+	Provider   string         `json:"provider"`
+	ID         string         `json:"id"`
+	FileName   string         `json:"file_name"`
+	Label      string         `json:"label,omitempty"`
+	ExportedAt time.Time      `json:"exported_at"`
+	Metadata   map[string]any `json:"metadata"`
+}
+
+// ExportAccount returns a portable token bundle for one account. When the
+// X-Bundle-Passphrase header is set, the bundle is encrypted with it.
+func (h *Handler) ExportAccount(c *gin.Context) {
+	if h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "core auth manager unavailable"})
+		return
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	auth, ok := h.authManager.GetByID(id)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "account not found"})
+		return
+	}
+	if len(auth.Metadata) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "account has no exportable credentials"})
+		return
+	}
+	fileName := auth.FileName
+	if fileName == "" {
+		fileName = auth.ID
+	}
+	bundle := accountBundle{
+		Version:    accountBundleVersion,
+		Provider:   auth.Provider,
+		ID:         auth.ID,
+		FileName:   filepath.Base(fileName),
+		Label:      auth.Label,
+		ExportedAt: time.Now().UTC(),
+		Metadata:   auth.Metadata,
+	}
+	data, err := json.Marshal(bundle)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to encode bundle: %v", err)})
+		return
+	}
+	if passphrase := c.GetHeader(bundlePassphraseHeader); passphrase != "" {
+		if data, err = login.SealWithPassphrase(data, passphrase); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to encrypt bundle: %v", err)})
+			return
+		}
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.bundle.json\"", strings.TrimSuffix(bundle.FileName, ".json")))
+	c.Data(http.StatusOK, "application/json", data)
+}
+
+// ImportAccount accepts a bundle produced by ExportAccount, refreshes its token
+// to confirm it is valid and writes it through the token store. Existing
+// accounts are rejected unless ?overwrite=true.
+func (h *Handler) ImportAccount(c *gin.Context) {
+	if h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "core auth manager unavailable"})
+		return
+	}
+	data, err := io.ReadAll(io.LimitReader(c.Request.Body, maxAccountBundleSize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read body"})
+		return
+	}
+	if login.IsEncryptedTokenData(data) {
+		passphrase := c.GetHeader(bundlePassphraseHeader)
+		if passphrase == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bundle is encrypted; " + bundlePassphraseHeader + " header required"})
+			return
+		}
+		if data, err = login.OpenWithPassphrase(data, passphrase); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "failed to decrypt bundle"})
+			return
+		}
+	}
+	var bundle accountBundle
+	if err = json.Unmarshal(data, &bundle); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid bundle"})
+		return
+	}
+	fileName, err := validateAccountBundle(&bundle)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	path := filepath.Join(h.cfg.AuthDir, fileName)
+	if !filepath.IsAbs(path) {
+		if abs, errAbs := filepath.Abs(path); errAbs == nil {
+			path = abs
+		}
+	}
+	authID := h.authIDForPath(path)
+	setAuditTarget(c, "id="+authID)
+	existing, exists := h.authManager.GetByID(authID)
+	if !exists {
+		if _, errStat := os.Stat(path); errStat == nil {
+			exists = true
+		}
+	}
+	overwrite := c.Query("overwrite") == "true" || c.Query("overwrite") == "1"
+	if exists && !overwrite {
+		c.JSON(http.StatusConflict, gin.H{"error": "account already exists; use overwrite=true to replace it", "id": authID})
+		return
+	}
+
+	now := time.Now()
+	auth := &provider.Auth{
+		ID:         authID,
+		Provider:   bundle.Provider,
+		FileName:   fileName,
+		Label:      bundle.Label,
+		Status:     provider.StatusActive,
+		Attributes: map[string]string{"path": path, "source": path},
+		Metadata:   bundle.Metadata,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if auth.Label == "" {
+		auth.Label = bundle.Provider
+	}
+	refreshed, err := h.authManager.RefreshCredentials(c.Request.Context(), auth)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": fmt.Sprintf("token validation failed: %v", err)})
+		return
+	}
+	if existing != nil {
+		refreshed.CreatedAt = existing.CreatedAt
+		if refreshed.Runtime == nil {
+			refreshed.Runtime = existing.Runtime
+		}
+	}
+	if _, err = h.saveTokenRecord(c.Request.Context(), refreshed); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to save account: %v", err)})
+		return
+	}
+	if existing != nil {
+		_, err = h.authManager.Update(c.Request.Context(), refreshed)
+	} else {
+		_, err = h.authManager.Register(c.Request.Context(), refreshed)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status":   "ok",
+		"id":       refreshed.ID,
+		"provider": refreshed.Provider,
+		"label":    refreshed.Label,
+		"replaced": exists,
+	})
+}
+
+// validateAccountBundle checks the bundle shape and returns the file name the
+// account is stored under.
+func validateAccountBundle(bundle *accountBundle) (string, error) {
+	if bundle.Version != accountBundleVersion {
+		return "", fmt.Errorf("unsupported bundle version %q", bundle.Version)
+	}
+	bundle.Provider = strings.TrimSpace(bundle.Provider)
+	if bundle.Provider == "" {
+		return "", fmt.Errorf("bundle provider is missing")
+	}
+	if len(bundle.Metadata) == 0 {
+		return "", fmt.Errorf("bundle has no credentials")
+	}
+	if t, _ := bundle.Metadata["type"].(string); strings.TrimSpace(t) == "" {
+		return "", fmt.Errorf("bundle credentials have no type")
+	}
+	name := strings.TrimSpace(bundle.FileName)
+	if name == "" {
+		name = strings.TrimSpace(bundle.ID)
+	}
+	name = filepath.Base(name)
+	if name == "" || name == "." || name == string(filepath.Separator) || !strings.HasSuffix(strings.ToLower(name), ".json") {
+		return "", fmt.Errorf("bundle file name must end with .json")
+	}
+	return name, nil
+}
@@ -0,0 +1,205 @@
+package management
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/auth/login"
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+const (
+	testAccessToken  = "sk-ant-oat-access-secret"
+	testRefreshToken = "sk-ant-ort-refresh-secret"
+)
+
+// refreshExecutor accepts every refresh and returns the auth unchanged.
+type refreshExecutor struct{}
+
+func (refreshExecutor) Identifier() string { return "claude" }
+func (refreshExecutor) Execute(context.Context, *provider.Auth, provider.Request, provider.Options) (provider.Response, error) {
+	return provider.Response{}, nil
+}
+func (refreshExecutor) ExecuteStream(context.Context, *provider.Auth, provider.Request, provider.Options) (<-chan provider.StreamChunk, error) {
+	return nil, nil
+}
+func (refreshExecutor) Refresh(_ context.Context, auth *provider.Auth) (*provider.Auth, error) {
+	return auth, nil
+}
+func (refreshExecutor) CountTokens(context.Context, *provider.Auth, provider.Request, provider.Options) (provider.Response, error) {
+	return provider.Response{}, nil
+}
+
+func newAccountsHandler(t *testing.T) *Handler {
+	t.Helper()
+	manager := provider.NewManager(nil, nil, nil)
+	manager.RegisterExecutor(refreshExecutor{})
+	h := &Handler{
+		cfg:         &config.Config{AuthDir: t.TempDir()},
+		authManager: manager,
+		tokenStore:  login.NewFileTokenStore(),
+	}
+	return h
+}
+
+func serve(h gin.HandlerFunc, req *http.Request, params ...gin.Param) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = params
+	h(c)
+	return w
+}
+
+func registerClaudeAccount(t *testing.T, h *Handler) {
+	t.Helper()
+	_, err := h.authManager.Register(context.Background(), &provider.Auth{
+		ID:       "claude-me.json",
+		Provider: "claude",
+		FileName: "claude-me.json",
+		Label:    "me",
+		Status:   provider.StatusActive,
+		Attributes: map[string]string{
+			"path": filepath.Join(h.cfg.AuthDir, "claude-me.json"),
+		},
+		Metadata: map[string]any{
+			"type":          "claude",
+			"email":         "me@example.com",
+			"access_token":  testAccessToken,
+			"refresh_token": testRefreshToken,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAccountBundle_RoundTrip(t *testing.T) {
+	for _, passphrase := range []string{"", "correct horse"} {
+		src := newAccountsHandler(t)
+		registerClaudeAccount(t, src)
+
+		req := httptest.NewRequest(http.MethodGet, "/v0/management/accounts/claude-me.json/export", nil)
+		if passphrase != "" {
+			req.Header.Set(bundlePassphraseHeader, passphrase)
+		}
+		w := serve(src.ExportAccount, req, gin.Param{Key: "id", Value: "claude-me.json"})
+		if w.Code != http.StatusOK {
+			t.Fatalf("export: status %d, body %s", w.Code, w.Body.String())
+		}
+		bundle := w.Body.Bytes()
+		if passphrase != "" && strings.Contains(string(bundle), testRefreshToken) {
+			t.Fatalf("encrypted bundle contains the refresh token")
+		}
+
+		dst := newAccountsHandler(t)
+		req = httptest.NewRequest(http.MethodPost, "/v0/management/accounts/import", strings.NewReader(string(bundle)))
+		if passphrase != "" {
+			req.Header.Set(bundlePassphraseHeader, passphrase)
+		}
+		w = serve(dst.ImportAccount, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("import (passphrase %q): status %d, body %s", passphrase, w.Code, w.Body.String())
+		}
+		auth, ok := dst.authManager.GetByID("claude-me.json")
+		if !ok {
+			t.Fatalf("imported account is not registered")
+		}
+		if auth.Metadata["refresh_token"] != testRefreshToken || auth.Label != "me" {
+			t.Errorf("imported account = label %q, metadata %v", auth.Label, auth.Metadata)
+		}
+		stored, err := login.ReadTokenFile(filepath.Join(dst.cfg.AuthDir, "claude-me.json"))
+		if err != nil || !strings.Contains(string(stored), testRefreshToken) {
+			t.Errorf("imported token file = %s, %v", stored, err)
+		}
+
+		req = httptest.NewRequest(http.MethodPost, "/v0/management/accounts/import", strings.NewReader(string(bundle)))
+		if passphrase != "" {
+			req.Header.Set(bundlePassphraseHeader, passphrase)
+		}
+		if w = serve(dst.ImportAccount, req); w.Code != http.StatusConflict {
+			t.Errorf("second import: status %d, want 409", w.Code)
+		}
+	}
+}
+
+func TestImportAccount_WrongPassphrase(t *testing.T) {
+	src := newAccountsHandler(t)
+	registerClaudeAccount(t, src)
+	req := httptest.NewRequest(http.MethodGet, "/v0/management/accounts/claude-me.json/export", nil)
+	req.Header.Set(bundlePassphraseHeader, "right")
+	bundle := serve(src.ExportAccount, req, gin.Param{Key: "id", Value: "claude-me.json"}).Body.String()
+
+	dst := newAccountsHandler(t)
+	req = httptest.NewRequest(http.MethodPost, "/v0/management/accounts/import", strings.NewReader(bundle))
+	req.Header.Set(bundlePassphraseHeader, "wrong")
+	if w := serve(dst.ImportAccount, req); w.Code != http.StatusBadRequest {
+		t.Errorf("status %d, want 400", w.Code)
+	}
+	if entries, _ := os.ReadDir(dst.cfg.AuthDir); len(entries) != 0 {
+		t.Errorf("a rejected import wrote %d files", len(entries))
+	}
+}
+
+// Export is the only endpoint allowed to return token values.
+func TestAuthFileListing_RedactsTokens(t *testing.T) {
+	h := newAccountsHandler(t)
+	registerClaudeAccount(t, h)
+	if err := os.WriteFile(filepath.Join(h.cfg.AuthDir, "claude-me.json"),
+		[]byte(`{"type":"claude","access_token":"`+testAccessToken+`","refresh_token":"`+testRefreshToken+`"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	listFromManager := h.ListAuthFiles
+	listFromDisk := (&Handler{cfg: h.cfg}).ListAuthFiles
+	for name, list := range map[string]gin.HandlerFunc{"manager": listFromManager, "disk": listFromDisk} {
+		w := serve(list, httptest.NewRequest(http.MethodGet, "/v0/management/auth-files", nil))
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "claude-me.json") {
+			t.Fatalf("%s listing: status %d, body %s", name, w.Code, w.Body.String())
+		}
+		for _, secret := range []string{testAccessToken, testRefreshToken} {
+			if strings.Contains(w.Body.String(), secret) {
+				t.Errorf("%s listing returns a token: %s", name, w.Body.String())
+			}
+		}
+	}
+}
+
+func TestUploadAuthFile_MultipartNeverWritesPlaintext(t *testing.T) {
+	t.Cleanup(func() { _ = login.ConfigureTokenEncryption("") })
+	if err := login.ConfigureTokenEncryption("secret"); err != nil {
+		t.Fatal(err)
+	}
+	h := newAccountsHandler(t)
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, err := mw.CreateFormFile("file", "claude-up.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = part.Write([]byte(`{"type":"claude","email":"up@example.com","access_token":"` + testAccessToken + `"}`))
+	_ = mw.Close()
+	req := httptest.NewRequest(http.MethodPost, "/v0/management/auth-files", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	if w := serve(h.UploadAuthFile, req); w.Code != http.StatusOK {
+		t.Fatalf("upload: status %d, body %s", w.Code, w.Body.String())
+	}
+	stored, err := os.ReadFile(filepath.Join(h.cfg.AuthDir, "claude-up.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !login.IsEncryptedTokenData(stored) || bytes.Contains(stored, []byte(testAccessToken)) {
+		t.Errorf("uploaded file stored in plaintext: %s", stored)
+	}
+}
@@ -0,0 +1,314 @@
+package management
+
+import (
+	"bufio"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/json"
+	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/util"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	// auditFileName is the audit log written in the log directory.
+	auditFileName = "audit.log"
+	// auditMemoryEntries bounds the audit log kept while no log directory is set.
+	auditMemoryEntries = 1000
+	// defaultAuditLimit is the number of entries GET /audit returns by default.
+	defaultAuditLimit = 100
+
+	auditActorKey  = "management.audit.actor"
+	auditTargetKey = "management.audit.target"
+)
+
+// auditExempt lists POST routes that change nothing and are not audited.
+var auditExempt = map[string]bool{
+	"/v0/management/route/explain": true,
+}
+
+// AuditEntry records one change made through the management API.
+type AuditEntry struct {
+	Time     time.Time `json:"time"`
+	Actor    string    `json:"actor"`
+	ClientIP string    `json:"client_ip"`
+	Action   string    `json:"action"`
+	Target   string    `json:"target,omitempty"`
+	Status   int       `json:"status"`
+	Result   string    `json:"result"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// AuditSink stores audit entries. Entries are only ever appended; Entries
+// returns them oldest first.
+type AuditSink interface {
+	Append(entry AuditEntry) error
+	Entries() ([]AuditEntry, error)
+}
+
+// FileAuditSink appends audit entries as JSON lines to a file.
+type FileAuditSink struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileAuditSink creates a sink writing to path. The file and its directory
+// are created on the first append.
+func NewFileAuditSink(path string) *FileAuditSink {
+	return &FileAuditSink{path: path}
+}
+
+// Append writes entry as one line at the end of the file.
+func (s *FileAuditSink) Append(entry AuditEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err = os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(append(line, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Entries reads every entry in the file. Lines that do not parse are skipped.
+func (s *FileAuditSink) Entries() ([]AuditEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.Open(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry AuditEntry
+		if json.Unmarshal(scanner.Bytes(), &entry) == nil {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// memoryAuditSink keeps the latest entries in memory.
+type memoryAuditSink struct {
+	mu      sync.Mutex
+	entries []AuditEntry
+}
+
+func (s *memoryAuditSink) Append(entry AuditEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.entries) >= auditMemoryEntries {
+		s.entries = append(s.entries[:0], s.entries[1:]...)
+	}
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+func (s *memoryAuditSink) Entries() ([]AuditEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]AuditEntry(nil), s.entries...), nil
+}
+
+// SetAuditSink replaces where audit entries are written.
+func (h *Handler) SetAuditSink(sink AuditSink) {
+	h.auditMu.Lock()
+	h.audit = sink
+	h.auditMu.Unlock()
+}
+
+func (h *Handler) auditSink() AuditSink {
+	h.auditMu.RLock()
+	defer h.auditMu.RUnlock()
+	return h.audit
+}
+
+// setAuditTarget names what a request changed, for handlers whose target is
+// in the body rather than the URL.
+func setAuditTarget(c *gin.Context, target string) {
+	c.Set(auditTargetKey, target)
+}
+
+// AuditMiddleware records every authenticated request that changes state,
+// with its outcome. It must run after Middleware.
+func (h *Handler) AuditMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		method := c.Request.Method
+		if method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions || auditExempt[c.FullPath()] {
+			c.Next()
+			return
+		}
+		w := &auditWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+
+		entry := AuditEntry{
+			Time:     time.Now().UTC(),
+			Actor:    c.GetString(auditActorKey),
+			ClientIP: c.ClientIP(),
+			Action:   method + " " + strings.TrimPrefix(c.FullPath(), "/v0/management"),
+			Target:   auditTarget(c),
+			Status:   w.Status(),
+			Result:   "ok",
+		}
+		if entry.Status >= http.StatusBadRequest {
+			entry.Result = "failed"
+			entry.Error = auditErrorMessage(w.body)
+		}
+		redactor := log.CurrentRedactor()
+		entry.Target = redactor.String(entry.Target)
+		entry.Error = redactor.String(entry.Error)
+		if sink := h.auditSink(); sink != nil {
+			if err := sink.Append(entry); err != nil {
+				log.Warnf("management audit: failed to record %s: %v", entry.Action, err)
+			}
+		}
+	}
+}
+
+// auditTarget returns the target a handler set, or else the path parameters
+// and query values of the request. Values of secret-looking keys are replaced.
+func auditTarget(c *gin.Context) string {
+	if target := c.GetString(auditTargetKey); target != "" {
+		return target
+	}
+	var parts []string
+	for _, p := range c.Params {
+		parts = append(parts, p.Key+"="+p.Value)
+	}
+	query := c.Request.URL.Query()
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := strings.Join(query[key], ",")
+		if isSecretKey(key) {
+			value = log.RedactedPlaceholder
+		}
+		parts = append(parts, key+"="+value)
+	}
+	return strings.Join(parts, " ")
+}
+
+func isSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, word := range []string{"key", "token", "secret", "password", "passphrase", "cookie"} {
+		if strings.Contains(key, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// auditErrorMessage extracts the error message from a management API error
+// response.
+func auditErrorMessage(body []byte) string {
+	for _, path := range []string{"error.message", "error", "message"} {
+		if v := gjson.GetBytes(body, path); v.Exists() && v.Type == gjson.String {
+			return v.String()
+		}
+	}
+	return ""
+}
+
+// maxAuditResponseBytes is how much of a response is kept to find its error.
+const maxAuditResponseBytes = 4096
+
+// auditWriter keeps the start of the response so a failure's message can be
+// recorded.
+type auditWriter struct {
+	gin.ResponseWriter
+	body []byte
+}
+
+func (w *auditWriter) Write(data []byte) (int, error) {
+	if room := maxAuditResponseBytes - len(w.body); room > 0 {
+		w.body = append(w.body, data[:min(room, len(data))]...)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *auditWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// managementActor describes how a request authenticated: the local password,
+// or the management key shown masked.
+func managementActor(localPassword bool, provided string) string {
+	if localPassword {
+		return "local-password"
+	}
+	return "management-key:" + util.HideAPIKey(provided)
+}
+
+// GetAudit lists recorded management changes, newest first. Query parameters:
+// limit (default 100), action (substring of the action), result (ok or
+// failed) and since (RFC 3339).
+func (h *Handler) GetAudit(c *gin.Context) {
+	limit := defaultAuditLimit
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+		limit = n
+	}
+	var since time.Time
+	if v := c.Query("since"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid since: use RFC 3339"})
+			return
+		}
+		since = t
+	}
+	action := c.Query("action")
+	result := c.Query("result")
+
+	sink := h.auditSink()
+	if sink == nil {
+		c.JSON(http.StatusOK, gin.H{"entries": []AuditEntry{}})
+		return
+	}
+	all, err := sink.Entries()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read audit log: " + err.Error()})
+		return
+	}
+	entries := make([]AuditEntry, 0, min(limit, len(all)))
+	for i := len(all) - 1; i >= 0 && len(entries) < limit; i-- {
+		e := all[i]
+		if (action != "" && !strings.Contains(e.Action, action)) || (result != "" && e.Result != result) || e.Time.Before(since) {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	c.JSON(http.StatusOK, gin.H{"entries": entries})
+}
@@ -29,7 +29,7 @@ var callbackForwardersMgr = oauth.NewCallbackServersManager()
 
 // startCallbackForwarder starts a callback forwarder that redirects OAuth callbacks to the main server.
 // Uses the new oauth.CallbackServersManager for cleaner lifecycle management.
-func startCallbackForwarder(port int, provider, targetBase string) (any, error) {
+func startCallbackForwarder(port int, provider, targetBase string) (*oauth.ForwarderServer, error) {
 	return callbackForwardersMgr.StartForwarder(port, provider, targetBase)
 }
 
@@ -145,7 +145,7 @@ func (h *Handler) listAuthFilesFromDisk(c *gin.Context) {
 
 			// Read file to get type field
 			full := filepath.Join(h.cfg.AuthDir, name)
-			if data, errRead := os.ReadFile(full); errRead == nil {
+			if data, errRead := login.ReadTokenFile(full); errRead == nil {
 				typeValue := gjson.GetBytes(data, "type").String()
 				emailValue := gjson.GetBytes(data, "email").String()
 				fileData["type"] = typeValue
@@ -193,6 +193,12 @@ func (h *Handler) buildAuthFileEntry(auth *provider.Auth) gin.H {
 	if email := authEmail(auth); email != "" {
 		entry["email"] = email
 	}
+	if len(auth.Labels) > 0 {
+		entry["labels"] = auth.Labels
+	}
+	if auth.ReauthRequired() {
+		entry["reauth_required"] = true
+	}
 	if accountType, account := auth.AccountInfo(); accountType != "" || account != "" {
 		if accountType != "" {
 			entry["account_type"] = accountType
@@ -264,40 +270,18 @@ func isRuntimeOnlyAuth(auth *provider.Auth) bool {
 	return strings.EqualFold(strings.TrimSpace(auth.Attributes["runtime_only"]), "true")
 }
 
-// Download single auth file by name
-func (h *Handler) DownloadAuthFile(c *gin.Context) {
-	name := c.Query("name")
-	if name == "" || strings.Contains(name, string(os.PathSeparator)) {
-		c.JSON(400, gin.H{"error": "invalid name"})
-		return
-	}
-	if !strings.HasSuffix(strings.ToLower(name), ".json") {
-		c.JSON(400, gin.H{"error": "name must end with .json"})
-		return
-	}
-	full := filepath.Join(h.cfg.AuthDir, name)
-	data, err := os.ReadFile(full)
-	if err != nil {
-		if os.IsNotExist(err) {
-			c.JSON(404, gin.H{"error": "file not found"})
-		} else {
-			c.JSON(500, gin.H{"error": fmt.Sprintf("failed to read file: %v", err)})
-		}
-		return
-	}
-	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", name))
-	c.Data(200, "application/json", data)
-}
-
 // Upload auth file: multipart or raw JSON with ?name=
 func (h *Handler) UploadAuthFile(c *gin.Context) {
 	if h.authManager == nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "core auth manager unavailable"})
 		return
 	}
+	// Limit uploads to 1MB to prevent DoS attacks
+	const maxAuthFileSize = 1 * 1024 * 1024
 	ctx := c.Request.Context()
 	if file, err := c.FormFile("file"); err == nil && file != nil {
 		name := filepath.Base(file.Filename)
+		setAuditTarget(c, "name="+name)
 		if !strings.HasSuffix(strings.ToLower(name), ".json") {
 			c.JSON(400, gin.H{"error": "file must be .json"})
 			return
@@ -308,13 +292,20 @@ func (h *Handler) UploadAuthFile(c *gin.Context) {
 				dst = abs
 			}
 		}
-		if errSave := c.SaveUploadedFile(file, dst); errSave != nil {
-			c.JSON(500, gin.H{"error": fmt.Sprintf("failed to save file: %v", errSave)})
+		// Read the upload in memory so a plaintext token never reaches disk.
+		src, errOpen := file.Open()
+		if errOpen != nil {
+			c.JSON(400, gin.H{"error": "failed to read file"})
 			return
 		}
-		data, errRead := os.ReadFile(dst)
+		data, errRead := io.ReadAll(io.LimitReader(src, maxAuthFileSize))
+		_ = src.Close()
 		if errRead != nil {
-			c.JSON(500, gin.H{"error": fmt.Sprintf("failed to read saved file: %v", errRead)})
+			c.JSON(400, gin.H{"error": "failed to read file"})
+			return
+		}
+		if data, errRead = storeUploadedAuth(dst, data); errRead != nil {
+			c.JSON(500, gin.H{"error": errRead.Error()})
 			return
 		}
 		if errReg := h.registerAuthFromFile(ctx, dst, data); errReg != nil {
@@ -333,8 +324,6 @@ func (h *Handler) UploadAuthFile(c *gin.Context) {
 		c.JSON(400, gin.H{"error": "name must end with .json"})
 		return
 	}
-	// Limit request body to 1MB to prevent DoS attacks
-	const maxAuthFileSize = 1 * 1024 * 1024
 	data, err := io.ReadAll(io.LimitReader(c.Request.Body, maxAuthFileSize))
 	if err != nil {
 		c.JSON(400, gin.H{"error": "failed to read body"})
@@ -346,8 +335,8 @@ func (h *Handler) UploadAuthFile(c *gin.Context) {
 			dst = abs
 		}
 	}
-	if errWrite := os.WriteFile(dst, data, 0o600); errWrite != nil {
-		c.JSON(500, gin.H{"error": fmt.Sprintf("failed to write file: %v", errWrite)})
+	if data, err = storeUploadedAuth(dst, data); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
 		return
 	}
 	if err = h.registerAuthFromFile(ctx, dst, data); err != nil {
@@ -442,6 +431,23 @@ func (h *Handler) authIDForPath(path string) string {
 	return path
 }
 
+// storeUploadedAuth writes an uploaded auth file to dst, encrypted when token
+// encryption is enabled, and returns its plaintext for registration.
+func storeUploadedAuth(dst string, data []byte) ([]byte, error) {
+	plain, _, err := login.DecryptTokenData(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt uploaded file: %w", err)
+	}
+	stored, err := login.EncryptTokenData(plain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt file: %w", err)
+	}
+	if err = os.WriteFile(dst, stored, 0o600); err != nil {
+		return nil, fmt.Errorf("failed to write file: %w", err)
+	}
+	return plain, nil
+}
+
 func (h *Handler) registerAuthFromFile(ctx context.Context, path string, data []byte) error {
 	if h.authManager == nil {
 		return nil
@@ -451,7 +457,7 @@ func (h *Handler) registerAuthFromFile(ctx context.Context, path string, data []
 	}
 	if data == nil {
 		var err error
-		data, err = os.ReadFile(path)
+		data, err = login.ReadTokenFile(path)
 		if err != nil {
 			return fmt.Errorf("failed to read auth file: %w", err)
 		}
@@ -13,6 +13,8 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/api/handlers/format"
+	"github.com/nghyane/llm-mux/internal/api/middleware"
 	"github.com/nghyane/llm-mux/internal/auth/login"
 	"github.com/nghyane/llm-mux/internal/buildinfo"
 	"github.com/nghyane/llm-mux/internal/config"
@@ -35,13 +37,19 @@ type Handler struct {
 	attemptsMu          sync.Mutex
 	failedAttempts      map[string]*attemptInfo // keyed by client IP
 	authManager         *provider.Manager
+	apiHandlers         *format.BaseAPIHandler
 	usageStats          *usage.RequestStatistics
+	usageCounters       *usage.Accumulator
+	inflight            *middleware.InFlightTracker
 	tokenStore          provider.Store
 	localPassword       string
+	managementKey       string
 	allowRemoteOverride bool
 	logDir              string
 	httpClient          *http.Client
 	httpClientOnce      sync.Once
+	auditMu             sync.RWMutex
+	audit               AuditSink
 }
 
 // NewHandler creates a new management handler instance.
@@ -56,8 +64,10 @@ func NewHandler(cfg *config.Config, configFilePath string, manager *provider.Man
 		failedAttempts:      make(map[string]*attemptInfo),
 		authManager:         manager,
 		usageStats:          usage.GetRequestStatistics(),
+		usageCounters:       usage.GetAccumulator(),
 		tokenStore:          login.GetTokenStore(),
 		allowRemoteOverride: envSecret != "",
+		audit:               &memoryAuditSink{},
 	}
 }
 
@@ -88,10 +98,18 @@ func (h *Handler) SetAuthManager(manager *provider.Manager) { h.authManager = ma
 // SetUsageStatistics allows replacing the usage statistics reference.
 func (h *Handler) SetUsageStatistics(stats *usage.RequestStatistics) { h.usageStats = stats }
 
+// SetInFlightTracker sets the tracker whose drain counters are reported with usage.
+func (h *Handler) SetInFlightTracker(tracker *middleware.InFlightTracker) { h.inflight = tracker }
+
 // SetLocalPassword configures the runtime-local password accepted for localhost requests.
 func (h *Handler) SetLocalPassword(password string) { h.localPassword = password }
 
+// SetManagementKey configures a key that replaces the configured management
+// key, for a management API served on its own listener.
+func (h *Handler) SetManagementKey(key string) { h.managementKey = key }
+
 // SetLogDirectory updates the directory where main.log should be looked up.
+// The audit log is written to the same directory.
 func (h *Handler) SetLogDirectory(dir string) {
 	if dir == "" {
 		return
@@ -102,6 +120,7 @@ func (h *Handler) SetLogDirectory(dir string) {
 		}
 	}
 	h.logDir = dir
+	h.SetAuditSink(NewFileAuditSink(filepath.Join(dir, auditFileName)))
 }
 
 // Middleware enforces access control for management endpoints.
@@ -131,6 +150,9 @@ func (h *Handler) Middleware() gin.HandlerFunc {
 
 		// Get management key (XDG-compliant: $XDG_CONFIG_HOME/llm-mux/credentials.json)
 		managementKey := config.GetManagementKey()
+		if h.managementKey != "" {
+			managementKey = h.managementKey
+		}
 
 		fail := func() {}
 		if !localClient {
@@ -204,6 +226,7 @@ func (h *Handler) Middleware() gin.HandlerFunc {
 		if localClient {
 			if lp := h.localPassword; lp != "" {
 				if subtle.ConstantTimeCompare([]byte(provided), []byte(lp)) == 1 {
+					c.Set(auditActorKey, managementActor(true, provided))
 					c.Next()
 					return
 				}
@@ -229,6 +252,7 @@ func (h *Handler) Middleware() gin.HandlerFunc {
 			h.attemptsMu.Unlock()
 		}
 
+		c.Set(auditActorKey, managementActor(false, provided))
 		c.Next()
 	}
 }
@@ -0,0 +1,231 @@
+package management
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/runtime/executor"
+	"github.com/sony/gobreaker"
+)
+
+// accountHealth is the per-account entry of the health report.
+type accountHealth struct {
+	ID          string            `json:"id"`
+	Provider    string            `json:"provider"`
+	Label       string            `json:"label,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Healthy     bool              `json:"healthy"`
+	Reachable   *bool             `json:"reachable,omitempty"`
+	TokenValid  bool              `json:"token_valid"`
+	TokenExpiry *time.Time        `json:"token_expiry,omitempty"`
+	// TokenError is why the deep credential check rejected the token.
+	TokenError string `json:"token_error,omitempty"`
+	// ReauthRequired marks credentials that expired and need a new login.
+	ReauthRequired bool     `json:"reauth_required,omitempty"`
+	BreakerState   string   `json:"breaker_state"`
+	CoolingDown    bool     `json:"cooling_down"`
+	LastError      string   `json:"last_error,omitempty"`
+	ProbeError     string   `json:"probe_error,omitempty"`
+	Timeouts       timeouts `json:"timeouts"`
+	// Selection reports how often the selector picked this account.
+	Selection provider.SelectionStats `json:"selection"`
+	// DailyQuota reports use of the account's daily quota when it has one.
+	DailyQuota *provider.DailyQuotaStatus `json:"daily_quota,omitempty"`
+	// Preflight is the startup credential check, when one ran.
+	Preflight *provider.PreflightResult `json:"preflight,omitempty"`
+	// Latency reports the account's call latency and time to first token.
+	Latency *provider.LatencyStatus `json:"latency,omitempty"`
+}
+
+// timeouts reports the effective outbound timeouts of an account's provider.
+type timeouts struct {
+	Connect    string `json:"connect"`
+	Request    string `json:"request"`
+	StreamIdle string `json:"stream_idle"`
+}
+
+// requestLimits reports the effective request body limits; zero is unlimited.
+type requestLimits struct {
+	MaxRequestBodyBytes           int64 `json:"max_request_body_bytes"`
+	MultimodalMaxRequestBodyBytes int64 `json:"multimodal_max_request_body_bytes"`
+}
+
+const (
+	// deepProbeConcurrency bounds the accounts probed at once in deep mode.
+	deepProbeConcurrency = 8
+	// healthCheckTimeout bounds the credential check of one account.
+	healthCheckTimeout = 10 * time.Second
+)
+
+// GetHealth reports aggregate readiness plus per-account detail.
+// By default only cached state is reported; ?deep=true probes each upstream
+// and checks each account's credentials read-only. Responds 503 when no enabled account
+// is healthy.
+func (h *Handler) GetHealth(c *gin.Context) {
+	deep := c.Query("deep") == "true" || c.Query("deep") == "1"
+	var auths []*provider.Auth
+	if h.authManager != nil {
+		auths = h.authManager.List()
+	}
+
+	now := time.Now()
+	var selections map[string]provider.SelectionStats
+	var latency map[string]provider.LatencyStatus
+	if h.authManager != nil {
+		selections = h.authManager.SelectionStats()
+		latency = h.authManager.Latency().Accounts()
+	}
+	accounts := make([]accountHealth, 0, len(auths))
+	for _, a := range auths {
+		if a == nil || a.Disabled {
+			continue
+		}
+		entry := h.cachedAccountHealth(a, now)
+		entry.Selection = selections[a.ID]
+		if l, ok := latency[a.ID]; ok {
+			entry.Latency = &l
+		}
+		accounts = append(accounts, entry)
+	}
+
+	if deep {
+		h.probeAccounts(c.Request.Context(), accounts)
+	}
+
+	sort.Slice(accounts, func(i, j int) bool {
+		if accounts[i].Provider != accounts[j].Provider {
+			return accounts[i].Provider < accounts[j].Provider
+		}
+		return accounts[i].ID < accounts[j].ID
+	})
+
+	healthy := 0
+	for _, a := range accounts {
+		if a.Healthy {
+			healthy++
+		}
+	}
+	status := "ok"
+	code := http.StatusOK
+	switch {
+	case healthy == 0:
+		status = "unavailable"
+		code = http.StatusServiceUnavailable
+	case healthy < len(accounts):
+		status = "degraded"
+	}
+
+	standard, multimodal := h.getConfig().BodyLimits()
+	c.JSON(code, gin.H{
+		"status":   status,
+		"deep":     deep,
+		"healthy":  healthy,
+		"total":    len(accounts),
+		"accounts": accounts,
+		"limits": requestLimits{
+			MaxRequestBodyBytes:           standard,
+			MultimodalMaxRequestBodyBytes: multimodal,
+		},
+	})
+}
+
+// probeAccounts pings the upstream of each account and checks its
+// credentials without changing them: expired tokens fail and API keys list
+// the upstream's models. Tokens are never refreshed here, so polling the
+// health endpoint cannot rotate them. At most deepProbeConcurrency accounts
+// are probed at once.
+func (h *Handler) probeAccounts(ctx context.Context, accounts []accountHealth) {
+	cfg := h.getConfig()
+	check := provider.PreflightOptions{
+		Timeout: healthCheckTimeout,
+		Check: func(ctx context.Context, auth *provider.Auth) error {
+			return executor.CheckCredentials(ctx, cfg, auth)
+		},
+	}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(deepProbeConcurrency, len(accounts)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if auth, ok := h.authManager.GetByID(accounts[i].ID); ok {
+					h.probeAccount(ctx, cfg, &accounts[i], auth, check)
+				}
+			}
+		}()
+	}
+	for i := range accounts {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+func (h *Handler) probeAccount(ctx context.Context, cfg *config.Config, entry *accountHealth, auth *provider.Auth, check provider.PreflightOptions) {
+	if err := executor.ProbeUpstream(ctx, cfg, auth); !errors.Is(err, executor.ErrProbeUnsupported) {
+		reachable := err == nil
+		entry.Reachable = &reachable
+		if err != nil {
+			entry.ProbeError = err.Error()
+			entry.Healthy = false
+		}
+	}
+	result := h.authManager.CheckAccount(ctx, auth, check)
+	if result.Skipped {
+		return
+	}
+	entry.TokenValid = result.Valid
+	if !result.Valid {
+		entry.TokenError = result.Error
+		entry.Healthy = false
+	}
+}
+
+func (h *Handler) cachedAccountHealth(a *provider.Auth, now time.Time) accountHealth {
+	entry := accountHealth{
+		ID:         a.ID,
+		Provider:   a.Provider,
+		Label:      a.Label,
+		Labels:     a.Labels,
+		TokenValid: true,
+	}
+	t := executor.ProviderTimeouts(h.getConfig(), a)
+	entry.Timeouts = timeouts{
+		Connect:    t.Connect.String(),
+		Request:    t.Request.String(),
+		StreamIdle: t.StreamIdle.String(),
+	}
+	if exp, ok := a.ExpirationTime(); ok {
+		entry.TokenExpiry = &exp
+		entry.TokenValid = exp.After(now)
+	}
+	breaker := h.authManager.BreakerState(a.Provider)
+	entry.BreakerState = breaker.String()
+	entry.CoolingDown = a.Unavailable && a.NextRetryAfter.After(now)
+	entry.ReauthRequired = a.ReauthRequired()
+	if a.LastError != nil {
+		entry.LastError = a.LastError.Message
+	} else if a.StatusMessage != "" && a.Status == provider.StatusError {
+		entry.LastError = a.StatusMessage
+	}
+	if quota, ok := h.authManager.DailyQuotaStatus(a); ok {
+		entry.DailyQuota = &quota
+	}
+	// A failed startup check stands until the account refreshes successfully.
+	preflightFailed := false
+	if check, ok := h.authManager.PreflightResult(a.ID); ok {
+		entry.Preflight = &check
+		preflightFailed = !check.Usable() && !a.LastRefreshedAt.After(check.CheckedAt)
+	}
+	entry.Healthy = entry.TokenValid && !entry.CoolingDown && !entry.ReauthRequired && breaker != gobreaker.StateOpen &&
+		(entry.DailyQuota == nil || !entry.DailyQuota.Exhausted) && !preflightFailed
+	return entry
+}
@@ -0,0 +1,106 @@
+package management
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/json"
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+// countingRefreshExecutor counts refreshes, which health checks must not do.
+type countingRefreshExecutor struct {
+	refreshExecutor
+	refreshes *atomic.Int64
+}
+
+func (e countingRefreshExecutor) Refresh(ctx context.Context, auth *provider.Auth) (*provider.Auth, error) {
+	e.refreshes.Add(1)
+	return e.refreshExecutor.Refresh(ctx, auth)
+}
+
+func TestGetHealth_DeepChecksCredentials(t *testing.T) {
+	var active, peak atomic.Int64
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := active.Add(1)
+		defer active.Add(-1)
+		for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+		}
+		time.Sleep(10 * time.Millisecond)
+		if r.Header.Get("x-api-key") == "bad" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer upstream.Close()
+
+	h := newAccountsHandler(t)
+	h.authManager = provider.NewManager(nil, nil, nil)
+	var refreshes atomic.Int64
+	h.authManager.RegisterExecutor(countingRefreshExecutor{refreshes: &refreshes})
+	register := func(id string, attrs map[string]string, metadata map[string]any) {
+		attrs["base_url"] = upstream.URL
+		if _, err := h.authManager.Register(context.Background(), &provider.Auth{
+			ID: id, Provider: "claude", Status: provider.StatusActive, Attributes: attrs, Metadata: metadata,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	register("oauth-ok", map[string]string{}, map[string]any{"refresh_token": "good", "expired": time.Now().Add(time.Hour).Format(time.RFC3339)})
+	register("oauth-expired", map[string]string{}, map[string]any{"refresh_token": "good", "expired": time.Now().Add(-time.Hour).Format(time.RFC3339)})
+	register("key-bad", map[string]string{"api_key": "bad"}, nil)
+	for i := range 3 * deepProbeConcurrency {
+		register(fmt.Sprintf("key-ok-%02d", i), map[string]string{"api_key": "good"}, nil)
+	}
+
+	w := serve(h.GetHealth, httptest.NewRequest(http.MethodGet, "/v0/management/health?deep=true", nil))
+	var body struct {
+		Healthy  int             `json:"healthy"`
+		Accounts []accountHealth `json:"accounts"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("%v: %s", err, w.Body.String())
+	}
+	for _, a := range body.Accounts {
+		wantValid := a.ID != "oauth-expired" && a.ID != "key-bad"
+		if a.TokenValid != wantValid || a.Healthy != wantValid || (a.TokenError == "") == !wantValid {
+			t.Errorf("%s: token_valid=%t healthy=%t token_error=%q, want valid %t", a.ID, a.TokenValid, a.Healthy, a.TokenError, wantValid)
+		}
+		if a.Reachable == nil || !*a.Reachable {
+			t.Errorf("%s: reachable = %v, want true", a.ID, a.Reachable)
+		}
+	}
+	if want := 3*deepProbeConcurrency + 1; body.Healthy != want {
+		t.Errorf("healthy = %d, want %d", body.Healthy, want)
+	}
+	if n := refreshes.Load(); n != 0 {
+		t.Errorf("deep health refreshed %d tokens, want none", n)
+	}
+	if got := peak.Load(); got > deepProbeConcurrency {
+		t.Errorf("%d concurrent upstream calls, want at most %d", got, deepProbeConcurrency)
+	}
+}
+
+func TestGetHealth_CachedDoesNotCallUpstream(t *testing.T) {
+	var calls atomic.Int64
+	upstream := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) { calls.Add(1) }))
+	defer upstream.Close()
+
+	h := newAccountsHandler(t)
+	if _, err := h.authManager.Register(context.Background(), &provider.Auth{
+		ID: "key", Provider: "claude", Status: provider.StatusActive,
+		Attributes: map[string]string{"api_key": "k", "base_url": upstream.URL},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if w := serve(h.GetHealth, httptest.NewRequest(http.MethodGet, "/v0/management/health", nil)); w.Code != http.StatusOK {
+		t.Fatalf("status %d, body %s", w.Code, w.Body.String())
+	}
+	if n := calls.Load(); n != 0 {
+		t.Errorf("cached health made %d upstream calls", n)
+	}
+}
@@ -0,0 +1,87 @@
+package management
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetModelAliases returns the configured model aliases.
+func (h *Handler) GetModelAliases(c *gin.Context) {
+	aliases := h.cfg.Routing.AliasMap()
+	if aliases == nil {
+		aliases = map[string]string{}
+	}
+	c.JSON(http.StatusOK, gin.H{"model-aliases": aliases, "list-aliases": h.cfg.Routing.ListAliases})
+}
+
+// PostModelAlias adds or replaces a model alias.
+// Body: {"alias": "gpt-4o", "target": "claude-sonnet-4-5"}; target may be "provider://model".
+func (h *Handler) PostModelAlias(c *gin.Context) {
+	var body struct {
+		Alias  string `json:"alias"`
+		Target string `json:"target"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+		return
+	}
+	alias := strings.TrimSpace(body.Alias)
+	target := strings.TrimSpace(body.Target)
+	setAuditTarget(c, "alias="+alias+" target="+target)
+	if alias == "" || target == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "alias and target are required"})
+		return
+	}
+	if alias == target {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "alias cannot point to itself"})
+		return
+	}
+
+	// Aliases are copy-on-write: requests may be resolving against the
+	// current map, so edits build a new one and publish it whole.
+	h.mu.Lock()
+	current := h.cfg.Routing.AliasMap()
+	next := make(map[string]string, len(current)+1)
+	for k, v := range current {
+		next[k] = v
+	}
+	next[alias] = target
+	err := h.cfg.Routing.SetAliases(next)
+	h.mu.Unlock()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	h.persist(c)
+}
+
+// DeleteModelAlias removes a model alias by name.
+func (h *Handler) DeleteModelAlias(c *gin.Context) {
+	alias := strings.TrimSpace(c.Query("alias"))
+	if alias == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing alias"})
+		return
+	}
+	h.mu.Lock()
+	current := h.cfg.Routing.AliasMap()
+	if _, ok := current[alias]; !ok {
+		h.mu.Unlock()
+		c.JSON(http.StatusNotFound, gin.H{"error": "alias not found"})
+		return
+	}
+	next := make(map[string]string, len(current))
+	for k, v := range current {
+		if k != alias {
+			next[k] = v
+		}
+	}
+	err := h.cfg.Routing.SetAliases(next)
+	h.mu.Unlock()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	h.persist(c)
+}
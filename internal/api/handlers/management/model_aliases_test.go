@@ -0,0 +1,95 @@
+package management
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/config"
+)
+
+// Run with -race: requests resolve aliases while management edits them.
+func TestModelAliases_ResolveWhileEditing(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configPath, []byte("port: 8317\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{}
+	cfg.Routing.Aliases = map[string]string{"fast": "claude-haiku-4-5"}
+	h := &Handler{cfg: cfg, configFilePath: configPath}
+
+	stop := make(chan struct{})
+	var readers sync.WaitGroup
+	for range 4 {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if got := cfg.Routing.ResolveModelAlias("fast"); got != "claude-haiku-4-5" {
+					t.Errorf("fast resolved to %q", got)
+					return
+				}
+				cfg.Routing.ResolveModelAlias("alias-3")
+			}
+		}()
+	}
+
+	var writers sync.WaitGroup
+	for i := range 8 {
+		writers.Add(1)
+		go func() {
+			defer writers.Done()
+			alias := fmt.Sprintf("alias-%d", i)
+			body := fmt.Sprintf(`{"alias":%q,"target":"gpt-4o"}`, alias)
+			req := httptest.NewRequest(http.MethodPost, "/v0/management/model-aliases", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			if w := serve(h.PostModelAlias, req); w.Code != http.StatusOK {
+				t.Errorf("add %s: status %d, body %s", alias, w.Code, w.Body.String())
+			}
+			if i%2 == 0 {
+				req = httptest.NewRequest(http.MethodDelete, "/v0/management/model-aliases?alias="+alias, nil)
+				if w := serve(h.DeleteModelAlias, req); w.Code != http.StatusOK {
+					t.Errorf("delete %s: status %d, body %s", alias, w.Code, w.Body.String())
+				}
+			}
+		}()
+	}
+	writers.Wait()
+	close(stop)
+	readers.Wait()
+
+	aliases := cfg.Routing.AliasMap()
+	if len(aliases) != 5 || aliases["alias-1"] != "gpt-4o" || aliases["alias-2"] != "" {
+		t.Errorf("aliases after edits = %v", aliases)
+	}
+}
+
+func TestPostModelAlias_RejectsCycleWithoutChangingAliases(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configPath, []byte("port: 8317\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{}
+	cfg.Routing.Aliases = map[string]string{"a": "b"}
+	before := cfg.Routing.AliasMap()
+	h := &Handler{cfg: cfg, configFilePath: configPath}
+
+	req := httptest.NewRequest(http.MethodPost, "/v0/management/model-aliases", strings.NewReader(`{"alias":"b","target":"a"}`))
+	req.Header.Set("Content-Type", "application/json")
+	if w := serve(h.PostModelAlias, req); w.Code != http.StatusBadRequest {
+		t.Fatalf("status %d, want 400", w.Code)
+	}
+	if got := cfg.Routing.AliasMap(); len(got) != 1 || len(before) != 1 || got["a"] != "b" {
+		t.Errorf("aliases after a rejected edit = %v, published map = %v", got, before)
+	}
+}
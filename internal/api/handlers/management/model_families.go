@@ -0,0 +1,144 @@
+package management
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/registry"
+)
+
+// GetModelFamilies lists the effective model families and marks which are custom.
+func (h *Handler) GetModelFamilies(c *gin.Context) {
+	custom := registry.CustomModelFamilies()
+	names := make([]string, 0, len(custom))
+	for id := range custom {
+		names = append(names, id)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"families": registry.ListModelFamilies(),
+		"custom":   names,
+	})
+}
+
+// GetCanonicalModelFamily reports the family a provider-specific model belongs to,
+// together with all of its members.
+func (h *Handler) GetCanonicalModelFamily(c *gin.Context) {
+	modelID := strings.TrimSpace(c.Query("model_id"))
+	if modelID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing model_id"})
+		return
+	}
+	canonical := registry.GetCanonicalModelID(modelID)
+	if canonical == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("model %s is not part of any model family; it is routed by its own ID", modelID)})
+		return
+	}
+	members, err := registry.ResolveModelFamily(canonical, 0)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"model_id":  modelID,
+		"canonical": canonical,
+		"members":   members,
+	})
+}
+
+// PostModelFamily registers or overrides a model family at runtime.
+// Body: {"canonical": "my-model", "members": [{"provider": "claude", "model": "..."}]}
+func (h *Handler) PostModelFamily(c *gin.Context) {
+	var body struct {
+		Canonical string                  `json:"canonical"`
+		Members   []registry.FamilyMember `json:"members"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+		return
+	}
+	setAuditTarget(c, "canonical="+body.Canonical)
+	if err := h.validateFamilyProviders(body.Members); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	canonical := strings.TrimSpace(body.Canonical)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	previous, existed := registry.CustomModelFamilies()[canonical]
+	if err := registry.RegisterModelFamily(canonical, body.Members); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.saveModelFamilies(); err != nil {
+		// Roll back so the running families match the file loaded at startup.
+		if existed {
+			_ = registry.RegisterModelFamily(canonical, previous)
+		} else {
+			registry.UnregisterModelFamily(canonical)
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// DeleteModelFamily removes a custom model family. Built-in families cannot be deleted.
+func (h *Handler) DeleteModelFamily(c *gin.Context) {
+	canonical := strings.TrimSpace(c.Query("canonical"))
+	if canonical == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing canonical"})
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	previous := registry.CustomModelFamilies()[canonical]
+	if !registry.UnregisterModelFamily(canonical) {
+		if registry.IsBuiltinModelFamily(canonical) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "built-in family cannot be deleted"})
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{"error": "family not found"})
+		return
+	}
+	if err := h.saveModelFamilies(); err != nil {
+		_ = registry.RegisterModelFamily(canonical, previous)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// validateFamilyProviders ensures every member references a provider that is
+// currently known, either through registered models or loaded credentials.
+func (h *Handler) validateFamilyProviders(members []registry.FamilyMember) error {
+	known := make(map[string]struct{})
+	for _, p := range registry.GetGlobalRegistry().GetAvailableProviders() {
+		known[strings.ToLower(p)] = struct{}{}
+	}
+	if h.authManager != nil {
+		for _, a := range h.authManager.List() {
+			known[strings.ToLower(a.Provider)] = struct{}{}
+		}
+	}
+	for _, m := range members {
+		p := strings.ToLower(strings.TrimSpace(m.Provider))
+		if p == "" {
+			continue
+		}
+		if _, ok := known[p]; !ok {
+			return fmt.Errorf("unknown provider: %s", m.Provider)
+		}
+	}
+	return nil
+}
+
+// saveModelFamilies writes the custom families next to the config file.
+// The caller must hold h.mu.
+func (h *Handler) saveModelFamilies() error {
+	if err := registry.SaveModelFamilies(registry.ModelFamiliesPath(h.configFilePath)); err != nil {
+		return fmt.Errorf("failed to save model families: %w", err)
+	}
+	return nil
+}
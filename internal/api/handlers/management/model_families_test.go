@@ -0,0 +1,80 @@
+package management
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/json"
+	"github.com/nghyane/llm-mux/internal/registry"
+)
+
+func TestGetCanonicalModelFamily(t *testing.T) {
+	members := []registry.FamilyMember{
+		{Provider: "claude", Model: "canonical-test-claude"},
+		{Provider: "gemini", Model: "canonical-test-gemini"},
+	}
+	if err := registry.RegisterModelFamily("canonical-test", members); err != nil {
+		t.Fatal(err)
+	}
+	defer registry.UnregisterModelFamily("canonical-test")
+	h := &Handler{}
+
+	for _, modelID := range []string{"canonical-test-gemini", "canonical-test"} {
+		w := serve(h.GetCanonicalModelFamily, httptest.NewRequest(http.MethodGet, "/v0/management/model-families/canonical?model_id="+modelID, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: status %d, body %s", modelID, w.Code, w.Body.String())
+		}
+		var body struct {
+			ModelID   string                  `json:"model_id"`
+			Canonical string                  `json:"canonical"`
+			Members   []registry.FamilyMember `json:"members"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		if body.ModelID != modelID || body.Canonical != "canonical-test" || len(body.Members) != len(members) {
+			t.Errorf("%s: got %+v", modelID, body)
+		}
+		for i, m := range members {
+			if i < len(body.Members) && body.Members[i] != m {
+				t.Errorf("%s: member %d = %+v, want %+v", modelID, i, body.Members[i], m)
+			}
+		}
+	}
+
+	w := serve(h.GetCanonicalModelFamily, httptest.NewRequest(http.MethodGet, "/v0/management/model-families/canonical?model_id=no-such-model", nil))
+	if w.Code != http.StatusNotFound || !strings.Contains(w.Body.String(), "not part of any model family") {
+		t.Errorf("unknown model: status %d, body %s", w.Code, w.Body.String())
+	}
+	w = serve(h.GetCanonicalModelFamily, httptest.NewRequest(http.MethodGet, "/v0/management/model-families/canonical", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("missing model_id: status %d, want 400", w.Code)
+	}
+}
+
+func TestPostModelFamily_SaveFailureRollsBack(t *testing.T) {
+	h := newAccountsHandler(t)
+	registerClaudeAccount(t, h)
+	// A regular file where the config directory should be makes every save fail.
+	blocker := filepath.Join(t.TempDir(), "not-a-dir")
+	if err := os.WriteFile(blocker, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	h.configFilePath = filepath.Join(blocker, "config.yaml")
+
+	body := `{"canonical":"rollback-test","members":[{"provider":"claude","model":"rollback-test-claude"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/v0/management/model-families", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := serve(h.PostModelFamily, req)
+	defer registry.UnregisterModelFamily("rollback-test")
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("status %d, want 500; body %s", w.Code, w.Body.String())
+	}
+	if registry.IsCanonicalID("rollback-test") {
+		t.Error("family stayed registered after its save failed")
+	}
+}
@@ -0,0 +1,15 @@
+package management
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/registry"
+)
+
+// PostModelsRefresh lists every account's models again, re-registers them and
+// drops the discovered models that have expired.
+func (h *Handler) PostModelsRefresh(c *gin.Context) {
+	expired := registry.GetGlobalRegistry().Refresh(c.Request.Context())
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "expired": expired})
+}
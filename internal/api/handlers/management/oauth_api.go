@@ -2,6 +2,7 @@ package management
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/nghyane/llm-mux/internal/json"
 	"io"
@@ -16,6 +17,7 @@ import (
 	"github.com/nghyane/llm-mux/internal/auth/claude"
 	"github.com/nghyane/llm-mux/internal/auth/codex"
 	"github.com/nghyane/llm-mux/internal/auth/copilot"
+	"github.com/nghyane/llm-mux/internal/auth/gemini"
 	"github.com/nghyane/llm-mux/internal/auth/iflow"
 	"github.com/nghyane/llm-mux/internal/auth/qwen"
 	"github.com/nghyane/llm-mux/internal/misc"
@@ -42,6 +44,10 @@ var emailReplacer = strings.NewReplacer("@", "_", ".", "_")
 type OAuthStartRequest struct {
 	Provider  string `json:"provider" binding:"required"`
 	ProjectID string `json:"project_id,omitempty"`
+	// FlowType optionally names the flow, "oauth" or "device". Gemini
+	// supports both and uses browser OAuth unless "device" is given; other
+	// providers accept only the flow they use.
+	FlowType string `json:"flow_type,omitempty"`
 }
 
 // OAuthStartResponse represents the response for starting an OAuth flow.
@@ -70,7 +76,7 @@ type oauthCallbackData struct {
 
 // OAuthStart handles POST /v0/management/oauth/start
 // Initiates an OAuth flow for the specified provider.
-// Supports: OAuth (claude, codex, gemini, antigravity, iflow), Device Flow (qwen, copilot)
+// Supports: OAuth (claude, codex, gemini, antigravity, iflow), Device Flow (qwen, copilot, gemini with flow_type=device)
 func (h *Handler) OAuthStart(c *gin.Context) {
 	var req OAuthStartRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -83,6 +89,15 @@ func (h *Handler) OAuthStart(c *gin.Context) {
 
 	// Normalize provider name
 	providerName := normalizeProvider(req.Provider)
+	setAuditTarget(c, "provider="+providerName)
+
+	if err := checkFlowType(providerName, req.FlowType); err != nil {
+		c.JSON(http.StatusBadRequest, OAuthStartResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return
+	}
 
 	// Handle device flow providers separately
 	switch providerName {
@@ -92,11 +107,31 @@ func (h *Handler) OAuthStart(c *gin.Context) {
 	case "copilot":
 		h.startCopilotDeviceFlow(c)
 		return
+	case "gemini":
+		if req.FlowType == "device" {
+			h.startGeminiDeviceFlow(c)
+			return
+		}
+	}
+
+	// Bind the callback forwarder first: its port is part of the redirect URI.
+	forwarder, callbackPort, err := h.startOAuthForwarder(providerName)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, oauth.ErrCallbackPortInUse) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, OAuthStartResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return
 	}
 
 	// Build auth URL for OAuth providers
-	authURL, state, codeVerifier, err := h.buildProviderAuthURL(providerName)
+	authURL, state, codeVerifier, err := h.buildProviderAuthURL(providerName, callbackPort)
 	if err != nil {
+		callbackForwardersMgr.ReleaseForwarder(forwarder)
 		c.JSON(http.StatusBadRequest, OAuthStartResponse{
 			Status: "error",
 			Error:  err.Error(),
@@ -107,16 +142,15 @@ func (h *Handler) OAuthStart(c *gin.Context) {
 	// Register OAuth request with codeVerifier for PKCE providers
 	oauthReq := oauthService.Registry().Create(state, providerName, oauth.ModeWebUI)
 	oauthReq.CodeVerifier = codeVerifier
+	oauthReq.RedirectURI = oauth.GetRedirectURIForPort(providerName, callbackPort)
 
-	// Start callback forwarder for WebUI mode
-	if targetURL, errTarget := h.managementCallbackURL("/" + providerName + "/callback"); errTarget == nil {
-		if port := oauth.GetCallbackPort(providerName); port > 0 {
-			_, _ = startCallbackForwarder(port, providerName, targetURL)
-		}
-	}
-
-	// Start background polling goroutine
+	// Start background polling goroutine; it and the forwarder are released
+	// once the request finishes or the registry expires it.
 	ctx, cancel := context.WithTimeout(context.Background(), deviceFlowTimeout)
+	oauthService.Registry().OnRelease(state, func() {
+		cancel()
+		callbackForwardersMgr.ReleaseForwarder(forwarder)
+	})
 	go h.pollOAuthCallback(ctx, cancel, providerName, state)
 
 	c.JSON(http.StatusOK, OAuthStartResponse{
@@ -129,6 +163,34 @@ func (h *Handler) OAuthStart(c *gin.Context) {
 	})
 }
 
+// startOAuthForwarder starts the callback forwarder of providerName and returns
+// it with the port the provider must redirect to. Providers accepting any
+// loopback port use the port from oauth-callback-ports when set. Without a
+// server port to forward to, no forwarder is started.
+func (h *Handler) startOAuthForwarder(providerName string) (*oauth.ForwarderServer, int, error) {
+	providerCfg, known := oauth.ProviderConfigs[providerName]
+	if !known {
+		return nil, 0, nil
+	}
+	port := providerCfg.Port
+	if configured, ok := h.cfg.CallbackPort(providerName); ok {
+		if !providerCfg.AnyLoopbackPort {
+			return nil, 0, fmt.Errorf("oauth-callback-ports: the %s callback port is registered with the provider and cannot be changed", providerName)
+		}
+		port = configured
+	}
+
+	targetURL, err := h.managementCallbackURL("/" + providerName + "/callback")
+	if err != nil {
+		return nil, providerCfg.Port, nil
+	}
+	forwarder, err := startCallbackForwarder(port, providerName, targetURL)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to start %s callback forwarder: %w", providerName, err)
+	}
+	return forwarder, forwarder.Port(), nil
+}
+
 // normalizeProvider converts provider aliases to canonical names.
 func normalizeProvider(provider string) string {
 	switch provider {
@@ -143,6 +205,26 @@ func normalizeProvider(provider string) string {
 	}
 }
 
+// checkFlowType rejects a flow_type providerName does not support.
+func checkFlowType(providerName, flowType string) error {
+	want := "oauth"
+	switch providerName {
+	case "qwen", "copilot":
+		want = "device"
+	case "gemini":
+		if flowType == "device" {
+			return nil
+		}
+	}
+	switch flowType {
+	case "", want:
+		return nil
+	case "oauth", "device":
+		return fmt.Errorf("%s does not support the %s flow; use %q", providerName, flowType, want)
+	}
+	return fmt.Errorf("unknown flow_type %q; use %q", flowType, want)
+}
+
 // pollOAuthCallback is a unified poller for all OAuth providers.
 // It polls the callback file and dispatches to provider-specific token exchange.
 func (h *Handler) pollOAuthCallback(ctx context.Context, cancel context.CancelFunc, providerName, state string) {
@@ -217,7 +299,7 @@ func (h *Handler) waitForCallbackFile(ctx context.Context, providerName, state s
 func (h *Handler) exchangeOAuthCode(ctx context.Context, providerName, state string, callback *oauthCallbackData) (*provider.Auth, error) {
 	switch providerName {
 	case "gemini", "antigravity":
-		return h.exchangeGoogleCode(ctx, providerName, callback.Code)
+		return h.exchangeGoogleCode(ctx, providerName, state, callback.Code)
 	case "claude":
 		return h.exchangeClaudeCode(ctx, state, callback.Code)
 	case "codex":
@@ -246,6 +328,7 @@ func (h *Handler) startQwenDeviceFlow(c *gin.Context) {
 
 	state := fmt.Sprintf("qwen-%d", time.Now().UnixNano())
 	oauthService.Registry().Create(state, "qwen", oauth.ModeWebUI)
+	oauthService.Registry().OnRelease(state, cancel)
 
 	go h.pollQwenToken(ctx, cancel, qwenAuth, deviceFlow, state)
 
@@ -288,6 +371,69 @@ func (h *Handler) pollQwenToken(ctx context.Context, cancel context.CancelFunc,
 	h.finishAuthFlow(ctx, state, record)
 }
 
+// startGeminiDeviceFlow initiates the Google device authorization flow for Gemini,
+// for hosts that cannot open a browser or receive the OAuth callback.
+func (h *Handler) startGeminiDeviceFlow(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), deviceFlowTimeout)
+
+	geminiAuth := gemini.NewGeminiAuth()
+	httpClient := h.getHTTPClient()
+	deviceFlow, err := geminiAuth.InitiateDeviceFlow(ctx, httpClient)
+	if err != nil {
+		cancel()
+		c.JSON(http.StatusInternalServerError, OAuthStartResponse{
+			Status: "error",
+			Error:  fmt.Sprintf("Failed to initiate device flow: %v", err),
+		})
+		return
+	}
+
+	state := fmt.Sprintf("gemini-%d", time.Now().UnixNano())
+	oauthService.Registry().Create(state, "gemini", oauth.ModeWebUI)
+	oauthService.Registry().OnRelease(state, cancel)
+
+	go h.pollGeminiToken(ctx, cancel, geminiAuth, httpClient, deviceFlow, state)
+
+	c.JSON(http.StatusOK, OAuthStartResponse{
+		Status:          "ok",
+		FlowType:        "device",
+		State:           state,
+		ID:              state,
+		UserCode:        deviceFlow.UserCode,
+		AuthURL:         deviceFlow.VerificationURL,
+		VerificationURL: deviceFlow.VerificationURL,
+		ExpiresIn:       deviceFlow.ExpiresIn,
+		Interval:        deviceFlow.Interval,
+	})
+}
+
+// pollGeminiToken polls for the Gemini device token in background.
+func (h *Handler) pollGeminiToken(ctx context.Context, cancel context.CancelFunc, geminiAuth *gemini.GeminiAuth, httpClient *http.Client, deviceFlow *gemini.DeviceFlow, state string) {
+	defer cancel()
+
+	log.WithField("state", state).Info("Waiting for Gemini device authentication...")
+
+	token, err := geminiAuth.PollForToken(ctx, httpClient, deviceFlow)
+	if err != nil {
+		h.handlePollError(ctx, state, "Gemini", err)
+		return
+	}
+
+	var email string
+	if info, _ := fetchGoogleUserInfo(ctx, token.AccessToken, httpClient); info != nil {
+		email = strings.TrimSpace(info.Email)
+	}
+
+	record := buildGoogleAuthRecord("gemini", &googleTokenResponse{
+		AccessToken:  token.AccessToken,
+		RefreshToken: token.RefreshToken,
+		ExpiresIn:    token.ExpiresIn,
+		TokenType:    token.TokenType,
+	}, email, "")
+
+	h.finishAuthFlow(ctx, state, record)
+}
+
 // startCopilotDeviceFlow initiates GitHub Copilot device authorization flow.
 func (h *Handler) startCopilotDeviceFlow(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), deviceFlowTimeout)
@@ -305,6 +451,7 @@ func (h *Handler) startCopilotDeviceFlow(c *gin.Context) {
 
 	state := fmt.Sprintf("copilot-%s", deviceCode.DeviceCode[:8])
 	oauthService.Registry().Create(state, "copilot", oauth.ModeWebUI)
+	oauthService.Registry().OnRelease(state, cancel)
 
 	go h.pollCopilotToken(ctx, cancel, copilotAuth, deviceCode, state)
 
@@ -390,9 +537,11 @@ func (h *Handler) OAuthStatus(c *gin.Context) {
 		return
 	}
 
+	// Expired states keep answering with status "expired" until their
+	// tombstone is swept; only unknown states are 404.
 	resp, err := oauthService.GetStatus(state)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"status": "error", "error": "OAuth state not found or expired"})
+		c.JSON(http.StatusNotFound, gin.H{"status": "error", "error": "OAuth state not found"})
 		return
 	}
 
@@ -424,8 +573,9 @@ func GetOAuthService() *oauth.Service {
 // Provider Auth URL Builders
 // =============================================================================
 
-// buildProviderAuthURL builds the authorization URL for a provider.
-func (h *Handler) buildProviderAuthURL(providerName string) (authURL, state, codeVerifier string, err error) {
+// buildProviderAuthURL builds the authorization URL for a provider whose
+// callback is served on callbackPort.
+func (h *Handler) buildProviderAuthURL(providerName string, callbackPort int) (authURL, state, codeVerifier string, err error) {
 	state, err = misc.GenerateRandomState()
 	if err != nil {
 		return "", "", "", fmt.Errorf("failed to generate state: %w", err)
@@ -437,9 +587,9 @@ func (h *Handler) buildProviderAuthURL(providerName string) (authURL, state, cod
 	case "codex":
 		return h.buildCodexAuthURL(state)
 	case "gemini", "antigravity":
-		return h.buildGoogleAuthURL(providerName, state)
+		return h.buildGoogleAuthURL(providerName, state, callbackPort)
 	case "iflow":
-		return h.buildIFlowAuthURL(state)
+		return h.buildIFlowAuthURL(state, callbackPort)
 	default:
 		return "", "", "", fmt.Errorf("unsupported OAuth provider: %s", providerName)
 	}
@@ -475,13 +625,13 @@ func (h *Handler) buildCodexAuthURL(state string) (string, string, string, error
 	return authURL, state, pkceCodes.CodeVerifier, nil
 }
 
-func (h *Handler) buildGoogleAuthURL(providerName, state string) (string, string, string, error) {
+func (h *Handler) buildGoogleAuthURL(providerName, state string, callbackPort int) (string, string, string, error) {
 	cfg, ok := googleOAuthConfigs[providerName]
 	if !ok {
 		return "", "", "", fmt.Errorf("unknown Google OAuth provider: %s", providerName)
 	}
 
-	redirectURI := fmt.Sprintf("http://localhost:%d/%s", oauth.GetCallbackPort(providerName), cfg.CallbackPath)
+	redirectURI := fmt.Sprintf("http://localhost:%d/%s", callbackPort, cfg.CallbackPath)
 
 	conf := &oauth2.Config{
 		ClientID:     cfg.ClientID,
@@ -495,9 +645,9 @@ func (h *Handler) buildGoogleAuthURL(providerName, state string) (string, string
 	return authURL, state, "", nil
 }
 
-func (h *Handler) buildIFlowAuthURL(state string) (string, string, string, error) {
+func (h *Handler) buildIFlowAuthURL(state string, callbackPort int) (string, string, string, error) {
 	iflowAuth := iflow.NewIFlowAuth(h.cfg)
-	authURL, _ := iflowAuth.AuthorizationURL(state, iflow.CallbackPort)
+	authURL, _ := iflowAuth.AuthorizationURL(state, callbackPort)
 	return authURL, state, "", nil
 }
 
@@ -536,13 +686,18 @@ var googleOAuthConfigs = map[string]googleOAuthConfig{
 	},
 }
 
-func (h *Handler) exchangeGoogleCode(ctx context.Context, providerName, code string) (*provider.Auth, error) {
+func (h *Handler) exchangeGoogleCode(ctx context.Context, providerName, state, code string) (*provider.Auth, error) {
 	cfg, ok := googleOAuthConfigs[providerName]
 	if !ok {
 		return nil, fmt.Errorf("unknown Google OAuth provider: %s", providerName)
 	}
 
+	// The redirect URI must match the one in the auth URL, whose port may
+	// have been moved.
 	redirectURI := fmt.Sprintf("http://localhost:%d/%s", oauth.GetCallbackPort(providerName), cfg.CallbackPath)
+	if oauthReq := oauthService.Registry().Get(state); oauthReq != nil && oauthReq.RedirectURI != "" {
+		redirectURI = oauthReq.RedirectURI
+	}
 	httpClient := h.getHTTPClient()
 
 	tokenResp, err := exchangeGoogleOAuthCode(ctx, code, redirectURI, cfg.ClientID, cfg.ClientSecret, httpClient)
@@ -0,0 +1,127 @@
+package management
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/json"
+	"github.com/nghyane/llm-mux/internal/oauth"
+)
+
+func TestOAuthStart_RejectsUnsupportedFlowType(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &Handler{}
+	for _, body := range []string{
+		`{"provider":"claude","flow_type":"device"}`,
+		`{"provider":"claude","flow_type":"browser"}`,
+		`{"provider":"qwen","flow_type":"oauth"}`,
+	} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/v0/management/oauth/start", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		h.OAuthStart(c)
+		if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "flow") {
+			t.Errorf("%s: status %d, body %s; want 400 naming the flow", body, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestCheckFlowType(t *testing.T) {
+	for _, tc := range []struct{ provider, flow string }{
+		{"gemini", ""}, {"gemini", "oauth"}, {"gemini", "device"}, {"copilot", "device"}, {"qwen", ""},
+	} {
+		if err := checkFlowType(tc.provider, tc.flow); err != nil {
+			t.Errorf("checkFlowType(%s, %q) = %v", tc.provider, tc.flow, err)
+		}
+	}
+}
+
+// redirectTransport sends every request to target, keeping its path, so the
+// fixed Google endpoints can be served by a test server.
+type redirectTransport struct{ target *url.URL }
+
+func (t redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme, req.URL.Host = t.target.Scheme, t.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestOAuthStart_GeminiDeviceFlow(t *testing.T) {
+	google := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/device/code":
+			_, _ = w.Write([]byte(`{"device_code":"dev-1","user_code":"ABCD-EFGH","verification_url":"https://www.google.com/device","expires_in":60,"interval":1}`))
+		case "/token":
+			_, _ = w.Write([]byte(`{"access_token":"ya29.access","refresh_token":"1//refresh","expires_in":3599,"token_type":"Bearer"}`))
+		case "/oauth2/v1/userinfo":
+			if r.Header.Get("Authorization") != "Bearer ya29.access" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"email":"me@example.com"}`))
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	}))
+	defer google.Close()
+	target, _ := url.Parse(google.URL)
+
+	h := newAccountsHandler(t)
+	h.httpClientOnce.Do(func() { h.httpClient = &http.Client{Transport: redirectTransport{target: target}} })
+
+	w := serve(h.OAuthStart, httptest.NewRequest(http.MethodPost, "/v0/management/oauth/start",
+		strings.NewReader(`{"provider":"gemini","flow_type":"device"}`)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("start: status %d, body %s", w.Code, w.Body.String())
+	}
+	var started OAuthStartResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &started); err != nil {
+		t.Fatal(err)
+	}
+	if started.FlowType != "device" || started.UserCode != "ABCD-EFGH" || started.VerificationURL != "https://www.google.com/device" {
+		t.Fatalf("start response = %+v", started)
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		status, err := oauthService.GetStatus(started.State)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status.Status == string(oauth.StatusCompleted) {
+			break
+		}
+		if status.Status != string(oauth.StatusPending) || time.Now().After(deadline) {
+			t.Fatalf("flow status = %+v", status)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	data, err := os.ReadFile(filepath.Join(h.cfg.AuthDir, "gemini-me@example.com-all.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved struct {
+		Type  string `json:"type"`
+		Email string `json:"email"`
+		Token struct {
+			AccessToken  string `json:"access_token"`
+			RefreshToken string `json:"refresh_token"`
+		} `json:"token"`
+	}
+	if err = json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if saved.Type != "gemini" || saved.Email != "me@example.com" || saved.Token.RefreshToken != "1//refresh" {
+		t.Errorf("saved token = %s", data)
+	}
+}
@@ -0,0 +1,45 @@
+package management
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/api/handlers/format"
+	"github.com/nghyane/llm-mux/internal/json"
+	"github.com/nghyane/llm-mux/internal/registry"
+)
+
+// SetAPIHandlers sets the request handlers whose routing PostRouteExplain reports.
+func (h *Handler) SetAPIHandlers(handlers *format.BaseAPIHandler) { h.apiHandlers = handlers }
+
+// PostRouteExplain reports how a request would be routed without sending it.
+// Body: {"model": "claude-sonnet-4-5", "capabilities": ["vision"], "request": {...}}
+// where request is an optional request body whose features are detected like
+// those of a real request.
+func (h *Handler) PostRouteExplain(c *gin.Context) {
+	var body struct {
+		Model        string          `json:"model"`
+		Capabilities []string        `json:"capabilities"`
+		Request      json.RawMessage `json:"request"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+		return
+	}
+	model := strings.TrimSpace(body.Model)
+	if model == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing model"})
+		return
+	}
+	required, err := registry.ParseCapabilities(body.Capabilities)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if h.apiHandlers == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "request handlers unavailable"})
+		return
+	}
+	c.JSON(http.StatusOK, h.apiHandlers.ExplainRoute(c.Request.Context(), model, body.Request, required))
+}
@@ -2,19 +2,59 @@ package management
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/api/middleware"
+	"github.com/nghyane/llm-mux/internal/provider"
 	"github.com/nghyane/llm-mux/internal/usage"
 )
 
-// GetUsageStatistics returns the in-memory request statistics snapshot.
+// GetUsageStatistics returns the in-memory request statistics snapshot along
+// with rolling last-hour and last-day counters, the number of streams
+// cancelled by their clients, the number that had to wait for a slow one, the
+// state of the retry budget, request queues of providers with a concurrency
+// limit, latency, including time to first token, per provider and model, and
+// the requests in flight and drained or forcibly closed during shutdown.
 func (h *Handler) GetUsageStatistics(c *gin.Context) {
 	var snapshot usage.StatisticsSnapshot
-	if h != nil && h.usageStats != nil {
-		snapshot = h.usageStats.Snapshot()
+	var counters *usage.Accumulator
+	var cancelled, backpressured int64
+	var budget *provider.RetryBudgetStatus
+	var latency map[string]provider.LatencyStatus
+	var queues map[string]provider.QueueStatus
+	var inflight int64
+	var drain middleware.DrainStats
+	if h != nil {
+		if h.usageStats != nil {
+			snapshot = h.usageStats.Snapshot()
+		}
+		counters = h.usageCounters
+		if h.inflight != nil {
+			inflight = h.inflight.Active()
+			drain = h.inflight.Stats()
+		}
+		if h.authManager != nil {
+			cancelled = h.authManager.CancelledStreams()
+			backpressured = h.authManager.BackpressuredStreams()
+			latency = h.authManager.Latency().Models()
+			queues = h.authManager.QueueStats()
+			if b := h.authManager.RetryBudget(); b != nil {
+				status := b.Status()
+				budget = &status
+			}
+		}
 	}
 	c.JSON(http.StatusOK, gin.H{
-		"usage":           snapshot,
-		"failed_requests": snapshot.FailureCount,
+		"usage":                 snapshot,
+		"failed_requests":       snapshot.FailureCount,
+		"cancelled_streams":     cancelled,
+		"backpressured_streams": backpressured,
+		"accumulated":           counters.Snapshot(time.Now()),
+		"retry_budget":          budget,
+		"latency":               latency,
+		"queues":                queues,
+		"in_flight":             inflight,
+		"drain":                 drain,
 	})
 }
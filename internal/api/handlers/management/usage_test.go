@@ -0,0 +1,32 @@
+package management
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/api/middleware"
+	"github.com/nghyane/llm-mux/internal/json"
+)
+
+func TestGetUsageStatistics_ReportsDrain(t *testing.T) {
+	tracker := middleware.NewInFlightTracker()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	tracker.Drain(ctx)
+
+	h := &Handler{}
+	h.SetInFlightTracker(tracker)
+	w := serve(h.GetUsageStatistics, httptest.NewRequest(http.MethodGet, "/v0/management/usage", nil))
+	var body struct {
+		InFlight *int64                 `json:"in_flight"`
+		Drain    *middleware.DrainStats `json:"drain"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.InFlight == nil || body.Drain == nil {
+		t.Fatalf("usage lacks in_flight or drain: %s", w.Body.String())
+	}
+}
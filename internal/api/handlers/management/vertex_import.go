@@ -72,6 +72,7 @@ func (h *Handler) ImportVertexCredential(c *gin.Context) {
 	}
 
 	fileName := fmt.Sprintf("vertex-%s.json", sanitizeVertexFilePart(projectID))
+	setAuditTarget(c, "id="+fileName)
 	label := labelForVertex(projectID, email)
 	storage := &vertex.VertexCredentialStorage{
 		ServiceAccount: serviceAccount,
@@ -9,7 +9,7 @@ import (
 // These routes are registered lazily when a management secret is configured.
 // This method can be safely called multiple times - subsequent calls are idempotent.
 func (s *Server) registerManagementRoutes() {
-	if s == nil || s.engine == nil || s.mgmt == nil {
+	if s == nil || s.managementEngine() == nil || s.mgmt == nil {
 		return
 	}
 	if !s.managementRoutesRegistered.CompareAndSwap(false, true) {
@@ -18,14 +18,16 @@ func (s *Server) registerManagementRoutes() {
 
 	log.Info("management routes registered after secret key configuration")
 
-	mgmt := s.engine.Group("/v0/management")
-	mgmt.Use(s.managementAvailabilityMiddleware(), s.mgmt.Middleware())
+	mgmt := s.managementEngine().Group("/v0/management")
+	mgmt.Use(s.managementAvailabilityMiddleware(), s.mgmt.Middleware(), s.mgmt.AuditMiddleware())
 	{
+		mgmt.GET("/health", s.mgmt.GetHealth)
 		mgmt.GET("/usage", s.mgmt.GetUsageStatistics)
 		mgmt.GET("/config", s.mgmt.GetConfig)
 		mgmt.GET("/config.yaml", s.mgmt.GetConfigYAML)
 		mgmt.PUT("/config.yaml", s.mgmt.PutConfigYAML)
 		mgmt.GET("/latest-version", s.mgmt.GetLatestVersion)
+		mgmt.GET("/audit", s.mgmt.GetAudit)
 
 		mgmt.GET("/debug", s.mgmt.GetDebug)
 		mgmt.PUT("/debug", s.mgmt.PutDebug)
@@ -85,10 +87,23 @@ func (s *Server) registerManagementRoutes() {
 		mgmt.DELETE("/oauth-excluded-models", s.mgmt.DeleteOAuthExcludedModels)
 
 		mgmt.GET("/auth-files", s.mgmt.ListAuthFiles)
-		mgmt.GET("/auth-files/download", s.mgmt.DownloadAuthFile)
 		mgmt.POST("/auth-files", s.mgmt.UploadAuthFile)
 		mgmt.DELETE("/auth-files", s.mgmt.DeleteAuthFile)
 		mgmt.POST("/vertex/import", s.mgmt.ImportVertexCredential)
+		mgmt.GET("/accounts/:id/export", s.mgmt.ExportAccount)
+		mgmt.POST("/accounts/import", s.mgmt.ImportAccount)
+
+		mgmt.POST("/models/refresh", s.mgmt.PostModelsRefresh)
+		mgmt.POST("/route/explain", s.mgmt.PostRouteExplain)
+
+		mgmt.GET("/model-families", s.mgmt.GetModelFamilies)
+		mgmt.GET("/model-families/canonical", s.mgmt.GetCanonicalModelFamily)
+		mgmt.POST("/model-families", s.mgmt.PostModelFamily)
+		mgmt.DELETE("/model-families", s.mgmt.DeleteModelFamily)
+
+		mgmt.GET("/model-aliases", s.mgmt.GetModelAliases)
+		mgmt.POST("/model-aliases", s.mgmt.PostModelAlias)
+		mgmt.DELETE("/model-aliases", s.mgmt.DeleteModelAlias)
 
 		// Unified OAuth API endpoints
 		mgmt.POST("/oauth/start", s.mgmt.OAuthStart)
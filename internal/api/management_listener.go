@@ -0,0 +1,106 @@
+// Package api provides the HTTP API server implementation for the CLI Proxy API.
+package api
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/api/middleware"
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/logging"
+	log "github.com/nghyane/llm-mux/internal/logging"
+)
+
+// WithManagementListener serves the management API on its own listener at
+// addr instead of the main one, so the control plane can be firewalled apart
+// from the data plane. A non-empty key replaces the management key for that
+// listener; the main listener then serves no management routes at all.
+func WithManagementListener(addr, key string) ServerOption {
+	return func(cfg *serverOptionConfig) {
+		cfg.managementAddr = addr
+		cfg.managementKey = key
+	}
+}
+
+// ValidateManagementAddress checks that addr is a host:port the management
+// listener can bind and that it does not share apiPort with the main listener.
+func ValidateManagementAddress(addr string, apiPort int) error {
+	host, portStr, err := net.SplitHostPort(strings.TrimSpace(addr))
+	if err != nil {
+		return fmt.Errorf("invalid management address %q: %w", addr, err)
+	}
+	port, err := net.LookupPort("tcp", portStr)
+	if err != nil || port <= 0 {
+		return fmt.Errorf("invalid management address %q: port must be between 1 and 65535", addr)
+	}
+	if port == apiPort && (host == "" || host == "0.0.0.0" || host == "::" || isLoopbackHost(host)) {
+		return fmt.Errorf("management address %q must not share the API port %d", addr, apiPort)
+	}
+	return nil
+}
+
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// newManagementEngine builds the engine behind the management listener, with
+// the same request bookkeeping as the main engine.
+func (s *Server) newManagementEngine() *gin.Engine {
+	engine := gin.New()
+	engine.Use(middleware.RequestIDMiddleware())
+	engine.Use(logging.GinLogrusLogger())
+	engine.Use(logging.GinLogrusRecovery())
+	engine.Use(s.inflight.Middleware())
+	engine.Use(corsMiddleware())
+	return engine
+}
+
+// managementEngine returns the engine management routes are registered on.
+func (s *Server) managementEngine() *gin.Engine {
+	if s.mgmtEngine != nil {
+		return s.mgmtEngine
+	}
+	return s.engine
+}
+
+// hasManagementKey reports whether the management API has a key to check.
+func (s *Server) hasManagementKey() bool {
+	return s.mgmtKey != "" || config.HasManagementKey()
+}
+
+// startManagementListener binds the management listener and serves it in the
+// background. Binding happens before returning, so an address in use fails
+// Start instead of being logged later.
+func (s *Server) startManagementListener() error {
+	if s.mgmtServer == nil {
+		return nil
+	}
+	ln, err := net.Listen("tcp", s.mgmtServer.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to start management server: %v", err)
+	}
+	if s.cfg != nil && s.cfg.TLS.Enable {
+		cert, errCert := tls.LoadX509KeyPair(strings.TrimSpace(s.cfg.TLS.Cert), strings.TrimSpace(s.cfg.TLS.Key))
+		if errCert != nil {
+			_ = ln.Close()
+			return fmt.Errorf("failed to start management server: %v", errCert)
+		}
+		ln = tls.NewListener(ln, &tls.Config{Certificates: []tls.Certificate{cert}})
+	}
+	log.Infof("management API listening on %s", ln.Addr())
+	go func() {
+		if errServe := s.mgmtServer.Serve(ln); errServe != nil && !errors.Is(errServe, http.ErrServerClosed) {
+			log.Errorf("management server stopped: %v", errServe)
+		}
+	}()
+	return nil
+}
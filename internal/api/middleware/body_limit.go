@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+)
+
+// multimodalSuffixes are the request paths, including their Amp provider
+// aliases, whose bodies may carry inline images, audio or documents.
+var multimodalSuffixes = []string{"/chat/completions", "/messages", "/responses", "/api/chat", "/api/generate"}
+
+// isMultimodalPath reports whether path gets the multimodal body limit.
+func isMultimodalPath(path string) bool {
+	if strings.Contains(path, ":generateContent") || strings.Contains(path, ":streamGenerateContent") {
+		return true
+	}
+	for _, suffix := range multimodalSuffixes {
+		if strings.HasSuffix(path, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// BodyLimiter rejects request bodies above a configured size with 413. The
+// limits can be changed while the server runs.
+type BodyLimiter struct {
+	standard   atomic.Int64
+	multimodal atomic.Int64
+}
+
+// NewBodyLimiter creates a limiter; a limit of zero or less is unlimited.
+func NewBodyLimiter(standard, multimodal int64) *BodyLimiter {
+	l := &BodyLimiter{}
+	l.SetLimits(standard, multimodal)
+	return l
+}
+
+// SetLimits replaces the text-only and multimodal limits.
+func (l *BodyLimiter) SetLimits(standard, multimodal int64) {
+	l.standard.Store(standard)
+	l.multimodal.Store(multimodal)
+}
+
+// Limit returns the body limit for path, or zero when it is unlimited.
+func (l *BodyLimiter) Limit(path string) int64 {
+	limit := l.standard.Load()
+	if isMultimodalPath(path) {
+		limit = l.multimodal.Load()
+	}
+	if limit < 0 {
+		return 0
+	}
+	return limit
+}
+
+// Middleware returns a Gin handler enforcing the limit. A declared
+// Content-Length is checked before anything is read; bodies of unknown
+// length are read up to the limit and rejected once they exceed it.
+func (l *BodyLimiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit := l.Limit(c.Request.URL.Path)
+		if limit <= 0 || c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+		if c.Request.ContentLength > limit {
+			abortBodyTooLarge(c, limit)
+			return
+		}
+		if c.Request.ContentLength < 0 {
+			// net/http stops a body at its Content-Length, so only
+			// chunked bodies need to be read here.
+			body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, limit))
+			if err != nil {
+				var maxErr *http.MaxBytesError
+				if errors.As(err, &maxErr) {
+					abortBodyTooLarge(c, limit)
+					return
+				}
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": gin.H{
+					"message": fmt.Sprintf("failed to read request body: %v", err),
+					"type":    "invalid_request_error",
+				}})
+				return
+			}
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+			c.Request.ContentLength = int64(len(body))
+		}
+		c.Next()
+	}
+}
+
+func abortBodyTooLarge(c *gin.Context, limit int64) {
+	c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": gin.H{
+		"message": fmt.Sprintf("Request body too large: %s accepts at most %d bytes.", c.Request.URL.Path, limit),
+		"type":    "invalid_request_error",
+		"code":    "request_too_large",
+	}})
+}
@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+)
+
+// newBodyLimitEngine echoes the length of the body each handler receives.
+func newBodyLimitEngine(l *BodyLimiter) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(l.Middleware())
+	echo := func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, "%d", len(body))
+	}
+	engine.POST("/v1/embeddings", echo)
+	engine.POST("/v1/chat/completions", echo)
+	return engine
+}
+
+func TestBodyLimiter(t *testing.T) {
+	engine := newBodyLimitEngine(NewBodyLimiter(10, 20))
+	tests := []struct {
+		name    string
+		path    string
+		size    int
+		chunked bool
+		want    int
+	}{
+		{name: "under limit", path: "/v1/embeddings", size: 10, want: http.StatusOK},
+		{name: "over limit", path: "/v1/embeddings", size: 11, want: http.StatusRequestEntityTooLarge},
+		{name: "chunked under limit", path: "/v1/embeddings", size: 10, chunked: true, want: http.StatusOK},
+		{name: "chunked over limit", path: "/v1/embeddings", size: 11, chunked: true, want: http.StatusRequestEntityTooLarge},
+		{name: "multimodal under limit", path: "/v1/chat/completions", size: 20, want: http.StatusOK},
+		{name: "multimodal over limit", path: "/v1/chat/completions", size: 21, chunked: true, want: http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body io.Reader = strings.NewReader(strings.Repeat("x", tt.size))
+			if tt.chunked {
+				// Hiding the concrete reader leaves ContentLength unknown.
+				body = io.MultiReader(body)
+			}
+			req := httptest.NewRequest(http.MethodPost, tt.path, body)
+			if tt.chunked {
+				req.ContentLength = -1
+			}
+			rec := httptest.NewRecorder()
+			engine.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d (body %s)", rec.Code, tt.want, rec.Body.String())
+			}
+			if tt.want == http.StatusOK && rec.Body.String() != strconv.Itoa(tt.size) {
+				t.Errorf("handler read %s bytes, want %d", rec.Body.String(), tt.size)
+			}
+			if tt.want == http.StatusRequestEntityTooLarge {
+				if got := gjson.Get(rec.Body.String(), "error.code").String(); got != "request_too_large" {
+					t.Errorf("error.code = %q (body %s)", got, rec.Body.String())
+				}
+			}
+		})
+	}
+}
+
+func TestBodyLimiter_Unlimited(t *testing.T) {
+	l := NewBodyLimiter(10, 20)
+	l.SetLimits(0, 0)
+	rec := httptest.NewRecorder()
+	newBodyLimitEngine(l).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/embeddings", strings.NewReader(strings.Repeat("x", 100))))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+}
+
+func TestIsMultimodalPath(t *testing.T) {
+	for path, want := range map[string]bool{
+		"/v1/chat/completions": true,
+		"/v1/messages":         true,
+		"/v1/responses":        true,
+		"/v1beta/models/gemini-2.5-pro:generateContent": true,
+		"/v1internal:streamGenerateContent":             true,
+		"/api/provider/anthropic/v1/messages":           true,
+		"/ollama/api/chat":                              true,
+		"/v1/embeddings":                                false,
+		"/v1/messages/count_tokens":                     false,
+		"/v1beta/models/gemini-2.5-pro:countTokens":     false,
+	} {
+		if got := isMultimodalPath(path); got != want {
+			t.Errorf("isMultimodalPath(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
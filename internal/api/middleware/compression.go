@@ -0,0 +1,348 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/config"
+)
+
+type decompressSettings struct {
+	maxBytes int64
+	// slots bounds concurrent decoding; nil when unlimited.
+	slots chan struct{}
+}
+
+// Decompressor decodes request bodies sent with Content-Encoding gzip, br or
+// deflate so handlers always see plain bodies. Decoded bodies are capped to
+// stop decompression bombs. The settings can be changed while the server
+// runs.
+type Decompressor struct {
+	settings atomic.Pointer[decompressSettings]
+	limits   *BodyLimiter
+}
+
+// NewDecompressor creates a decompressor. Without a configured cap, decoded
+// bodies are held to limits, the request body limits of each endpoint.
+func NewDecompressor(cfg config.Compression, limits *BodyLimiter) *Decompressor {
+	d := &Decompressor{limits: limits}
+	d.SetConfig(cfg)
+	return d
+}
+
+// SetConfig replaces the decoded size cap and the concurrency bound. Requests
+// already decoding keep the slot they hold.
+func (d *Decompressor) SetConfig(cfg config.Compression) {
+	s := &decompressSettings{maxBytes: cfg.MaxDecompressedBytes}
+	if cfg.Concurrency > 0 {
+		s.slots = make(chan struct{}, cfg.Concurrency)
+	}
+	d.settings.Store(s)
+}
+
+// Limit returns the largest decoded body accepted for path.
+func (d *Decompressor) Limit(path string) int64 {
+	if s := d.settings.Load(); s != nil && s.maxBytes > 0 {
+		return s.maxBytes
+	}
+	if d.limits != nil {
+		if limit := d.limits.Limit(path); limit > 0 {
+			return limit
+		}
+	}
+	return config.DefaultMultimodalMaxRequestBodyBytes
+}
+
+// Middleware returns a Gin handler that decodes compressed request bodies.
+// Unknown encodings are rejected with 415; corrupt bodies and bodies that
+// decode beyond the limit are rejected with 400.
+func (d *Decompressor) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		encoding := strings.ToLower(strings.TrimSpace(c.GetHeader("Content-Encoding")))
+		if encoding == "" || encoding == "identity" || c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+		s := d.settings.Load()
+		if s != nil && s.slots != nil {
+			select {
+			case s.slots <- struct{}{}:
+			case <-c.Request.Context().Done():
+				c.Abort()
+				return
+			}
+		}
+		body, err := d.decode(encoding, c.Request.Body, d.Limit(c.Request.URL.Path))
+		if s != nil && s.slots != nil {
+			<-s.slots
+		}
+		if err != nil {
+			abortDecompression(c, err)
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Request.ContentLength = int64(len(body))
+		c.Request.Header.Del("Content-Encoding")
+		c.Request.Header.Set("Content-Length", strconv.Itoa(len(body)))
+		c.Next()
+	}
+}
+
+// decompressionError is a request body that could not be decoded; status is
+// the response it gets.
+type decompressionError struct {
+	status  int
+	code    string
+	message string
+}
+
+func (e *decompressionError) Error() string { return e.message }
+
+func (d *Decompressor) decode(encoding string, body io.Reader, limit int64) ([]byte, error) {
+	r, err := newBodyDecoder(encoding, body)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = r.Close() }()
+	decoded, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, &decompressionError{status: http.StatusBadRequest, code: "invalid_body_encoding",
+			message: fmt.Sprintf("failed to decode %s request body: %v", encoding, err)}
+	}
+	if int64(len(decoded)) > limit {
+		return nil, &decompressionError{status: http.StatusBadRequest, code: "decompressed_body_too_large",
+			message: fmt.Sprintf("Decompressed request body too large: at most %d bytes are accepted.", limit)}
+	}
+	return decoded, nil
+}
+
+// newBodyDecoder returns a reader decoding body. For deflate both the zlib
+// format required by HTTP and the raw deflate some clients send are accepted.
+func newBodyDecoder(encoding string, body io.Reader) (io.ReadCloser, error) {
+	invalid := func(err error) error {
+		return &decompressionError{status: http.StatusBadRequest, code: "invalid_body_encoding",
+			message: fmt.Sprintf("failed to decode %s request body: %v", encoding, err)}
+	}
+	switch encoding {
+	case "gzip", "x-gzip":
+		r, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, invalid(err)
+		}
+		return r, nil
+	case "br":
+		return io.NopCloser(brotli.NewReader(body)), nil
+	case "deflate":
+		br := bufio.NewReader(body)
+		if h, err := br.Peek(2); err == nil && h[0]&0x0f == 8 && (uint16(h[0])<<8|uint16(h[1]))%31 == 0 {
+			r, err := zlib.NewReader(br)
+			if err != nil {
+				return nil, invalid(err)
+			}
+			return r, nil
+		}
+		return flate.NewReader(br), nil
+	}
+	return nil, &decompressionError{status: http.StatusUnsupportedMediaType, code: "unsupported_content_encoding",
+		message: fmt.Sprintf("Unsupported Content-Encoding %q: use gzip, br or deflate.", encoding)}
+}
+
+func abortDecompression(c *gin.Context, err error) {
+	de, ok := err.(*decompressionError)
+	if !ok {
+		de = &decompressionError{status: http.StatusBadRequest, code: "invalid_body_encoding", message: err.Error()}
+	}
+	c.AbortWithStatusJSON(de.status, gin.H{"error": gin.H{
+		"message": de.message,
+		"type":    "invalid_request_error",
+		"code":    de.code,
+	}})
+}
+
+// ResponseCompressor compresses responses with gzip or br for clients whose
+// Accept-Encoding allows it. Streams are only compressed, with gzip, when
+// enabled separately. It can be turned on and off while the server runs.
+type ResponseCompressor struct {
+	settings atomic.Pointer[config.Compression]
+}
+
+// NewResponseCompressor creates a compressor from the compression settings.
+func NewResponseCompressor(cfg config.Compression) *ResponseCompressor {
+	rc := &ResponseCompressor{}
+	rc.SetConfig(cfg)
+	return rc
+}
+
+// SetConfig replaces the compression settings.
+func (rc *ResponseCompressor) SetConfig(cfg config.Compression) {
+	rc.settings.Store(&cfg)
+}
+
+// Middleware returns a Gin handler that does nothing while response
+// compression is disabled.
+func (rc *ResponseCompressor) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		s := rc.settings.Load()
+		if s == nil || (!s.Responses && !s.Streams) || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+		w := &compressWriter{ResponseWriter: c.Writer}
+		accept := c.GetHeader("Accept-Encoding")
+		if s.Responses {
+			w.encoding = negotiateEncoding(accept, "br", "gzip")
+		}
+		if s.Streams {
+			w.streamEncoding = negotiateEncoding(accept, "gzip")
+		}
+		if w.encoding == "" && w.streamEncoding == "" {
+			c.Next()
+			return
+		}
+		c.Writer = w
+		defer w.Close()
+		c.Next()
+	}
+}
+
+// negotiateEncoding picks the encoding among supported that the
+// Accept-Encoding header weights highest, preferring the earlier one on a
+// tie. It returns "" when none is acceptable.
+func negotiateEncoding(header string, supported ...string) string {
+	if header == "" {
+		return ""
+	}
+	var best string
+	var bestQ float64
+	for _, item := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(item), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		rank := slices.Index(supported, name)
+		if rank < 0 {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q > 0 && (q > bestQ || (q == bestQ && rank < slices.Index(supported, best))) {
+			best, bestQ = name, q
+		}
+	}
+	return best
+}
+
+var gzipWriterPool = sync.Pool{New: func() any { return gzip.NewWriter(io.Discard) }}
+
+var brotliWriterPool = sync.Pool{New: func() any { return brotli.NewWriter(io.Discard) }}
+
+// compressWriter compresses what the handler writes. Whether to compress is
+// decided when the headers go out, by the Content-Type: streams use
+// streamEncoding and other responses encoding, and an empty one leaves them
+// unchanged. Responses that carry no body or that the handler already
+// encoded always pass through.
+type compressWriter struct {
+	gin.ResponseWriter
+	encoding       string
+	streamEncoding string
+	decided        bool
+	enc            io.WriteCloser
+	flusher        interface{ Flush() error }
+}
+
+func (w *compressWriter) decide() {
+	if w.decided {
+		return
+	}
+	w.decided = true
+	h := w.ResponseWriter.Header()
+	encoding := w.encoding
+	if isStreamContentType(h.Get("Content-Type")) {
+		encoding = w.streamEncoding
+	}
+	status := w.ResponseWriter.Status()
+	if encoding == "" || h.Get("Content-Encoding") != "" ||
+		status == http.StatusNoContent || status == http.StatusNotModified || status < http.StatusOK {
+		return
+	}
+	h.Set("Content-Encoding", encoding)
+	h.Add("Vary", "Accept-Encoding")
+	h.Del("Content-Length")
+	switch encoding {
+	case "br":
+		bw := brotliWriterPool.Get().(*brotli.Writer)
+		bw.Reset(w.ResponseWriter)
+		w.enc, w.flusher = bw, bw
+	default:
+		gw := gzipWriterPool.Get().(*gzip.Writer)
+		gw.Reset(w.ResponseWriter)
+		w.enc, w.flusher = gw, gw
+	}
+}
+
+func isStreamContentType(contentType string) bool {
+	return strings.HasPrefix(contentType, "text/event-stream") || strings.HasPrefix(contentType, "application/x-ndjson")
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	w.decide()
+	if w.enc == nil {
+		return w.ResponseWriter.Write(data)
+	}
+	w.ResponseWriter.WriteHeaderNow()
+	return w.enc.Write(data)
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *compressWriter) WriteHeaderNow() {
+	w.decide()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+// Flush emits everything written so far, so each flushed stream chunk reaches
+// the client without waiting for the encoder's buffer to fill.
+func (w *compressWriter) Flush() {
+	w.decide()
+	if w.flusher != nil {
+		_ = w.flusher.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// Close ends the compressed body. It is a no-op for responses that were not
+// compressed.
+func (w *compressWriter) Close() {
+	if w.enc == nil {
+		return
+	}
+	_ = w.enc.Close()
+	switch enc := w.enc.(type) {
+	case *gzip.Writer:
+		enc.Reset(io.Discard)
+		gzipWriterPool.Put(enc)
+	case *brotli.Writer:
+		enc.Reset(io.Discard)
+		brotliWriterPool.Put(enc)
+	}
+	w.enc, w.flusher = nil, nil
+}
@@ -0,0 +1,237 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/tidwall/gjson"
+)
+
+func compress(t *testing.T, encoding string, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch encoding {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "br":
+		w = brotli.NewWriter(&buf)
+	case "deflate":
+		w = zlib.NewWriter(&buf)
+	case "raw-deflate":
+		w, _ = flate.NewWriter(&buf, flate.DefaultCompression)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// newDecompressEngine echoes the body each handler receives.
+func newDecompressEngine(d *Decompressor) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(d.Middleware())
+	engine.POST("/v1/chat/completions", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.Data(http.StatusOK, "application/json", body)
+	})
+	return engine
+}
+
+func TestDecompressor_DecodesRequestBody(t *testing.T) {
+	engine := newDecompressEngine(NewDecompressor(config.Compression{}, NewBodyLimiter(0, 0)))
+	payload := []byte(`{"model":"gpt-4o","messages":[{"role":"user","content":"hi"}]}`)
+	for _, encoding := range []string{"gzip", "br", "deflate", "raw-deflate"} {
+		header := encoding
+		if encoding == "raw-deflate" {
+			header = "deflate"
+		}
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", bytes.NewReader(compress(t, encoding, payload)))
+		req.Header.Set("Content-Encoding", header)
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK || rec.Body.String() != string(payload) {
+			t.Errorf("%s: status %d, handler saw %q", encoding, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestDecompressor_RejectsBomb(t *testing.T) {
+	// 10 MiB of zeros compress to about 10 KiB.
+	bomb := compress(t, "gzip", make([]byte, 10<<20))
+	for name, d := range map[string]*Decompressor{
+		"endpoint limit":   NewDecompressor(config.Compression{}, NewBodyLimiter(0, 1<<20)),
+		"configured limit": NewDecompressor(config.Compression{MaxDecompressedBytes: 4096}, nil),
+	} {
+		engine := newDecompressEngine(d)
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", bytes.NewReader(bomb))
+		req.Header.Set("Content-Encoding", "gzip")
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s: status = %d, want 400", name, rec.Code)
+		}
+		if got := gjson.Get(rec.Body.String(), "error.code").String(); got != "decompressed_body_too_large" {
+			t.Errorf("%s: error.code = %q (body %s)", name, got, rec.Body.String())
+		}
+	}
+}
+
+func TestDecompressor_RejectsBadEncodings(t *testing.T) {
+	engine := newDecompressEngine(NewDecompressor(config.Compression{}, nil))
+	for encoding, want := range map[string]int{"gzip": http.StatusBadRequest, "zstd": http.StatusUnsupportedMediaType} {
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader("not compressed"))
+		req.Header.Set("Content-Encoding", encoding)
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Errorf("%s: status = %d, want %d", encoding, rec.Code, want)
+		}
+	}
+}
+
+func TestResponseCompressor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rc := NewResponseCompressor(config.Compression{Responses: true})
+	engine := gin.New()
+	engine.Use(rc.Middleware())
+	body := strings.Repeat(`{"object":"model"}`, 100)
+	engine.GET("/v1/models", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json", []byte(body))
+	})
+	engine.GET("/stream", func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/event-stream", []byte("data: {}\n\n"))
+	})
+	get := func(path, acceptEncoding string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("/v1/models", "gzip, deflate")
+	if rec.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("Content-Encoding = %q, want gzip", rec.Header().Get("Content-Encoding"))
+	}
+	zr, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := io.ReadAll(zr); string(got) != body {
+		t.Errorf("decoded body = %q", got)
+	}
+
+	rec = get("/v1/models", "gzip;q=0.5, br")
+	if rec.Header().Get("Content-Encoding") != "br" {
+		t.Fatalf("Content-Encoding = %q, want br", rec.Header().Get("Content-Encoding"))
+	}
+	if got, _ := io.ReadAll(brotli.NewReader(rec.Body)); string(got) != body {
+		t.Errorf("decoded br body = %q", got)
+	}
+
+	if rec := get("/v1/models", ""); rec.Header().Get("Content-Encoding") != "" || rec.Body.String() != body {
+		t.Errorf("client without Accept-Encoding got Content-Encoding %q", rec.Header().Get("Content-Encoding"))
+	}
+	if rec := get("/stream", "gzip"); rec.Header().Get("Content-Encoding") != "" {
+		t.Errorf("stream got Content-Encoding %q", rec.Header().Get("Content-Encoding"))
+	}
+
+	rc.SetConfig(config.Compression{})
+	if rec := get("/v1/models", "gzip"); rec.Header().Get("Content-Encoding") != "" {
+		t.Errorf("disabled compressor set Content-Encoding %q", rec.Header().Get("Content-Encoding"))
+	}
+}
+
+func TestResponseCompressor_StreamsFlushPerChunk(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(NewResponseCompressor(config.Compression{Streams: true}).Middleware())
+	next := make(chan struct{})
+	engine.GET("/stream", func(c *gin.Context) {
+		c.Header("Content-Type", "text/event-stream")
+		for i := range 3 {
+			if i > 0 {
+				// The next event is only sent once the client has the last.
+				select {
+				case <-next:
+				case <-time.After(5 * time.Second):
+					return
+				}
+			}
+			_, _ = c.Writer.WriteString("data: {\"n\":" + strconv.Itoa(i) + "}\n\n")
+			c.Writer.Flush()
+		}
+	})
+	srv := httptest.NewServer(engine)
+	defer srv.Close()
+	client := &http.Client{Transport: &http.Transport{DisableCompression: true}, Timeout: 10 * time.Second}
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/stream", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.Header.Get("Content-Encoding") != "gzip" {
+		t.Fatalf("Content-Encoding = %q, want gzip", resp.Header.Get("Content-Encoding"))
+	}
+	zr, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := bufio.NewReader(zr)
+	for i := range 3 {
+		want := "data: {\"n\":" + strconv.Itoa(i) + "}\n"
+		got, err := events.ReadString('\n')
+		if err != nil || got != want {
+			t.Fatalf("event %d = %q (%v), want %q", i, got, err, want)
+		}
+		if _, err := events.ReadString('\n'); err != nil {
+			t.Fatal(err)
+		}
+		if i < 2 {
+			select {
+			case next <- struct{}{}:
+			case <-time.After(5 * time.Second):
+				t.Fatalf("handler did not wait for event %d to be read", i)
+			}
+		}
+	}
+
+	// Clients that do not accept gzip get the plain stream.
+	go func() {
+		for range 2 {
+			next <- struct{}{}
+		}
+	}()
+	resp, err = client.Get(srv.URL + "/stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.Header.Get("Content-Encoding") != "" || !strings.HasPrefix(string(body), "data: {\"n\":0}") {
+		t.Errorf("plain client got Content-Encoding %q and body %q", resp.Header.Get("Content-Encoding"), body)
+	}
+}
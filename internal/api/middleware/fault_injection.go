@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+// Headers that set the faults of a single request while debug is enabled.
+const (
+	FaultStatusHeader      = "X-LLM-Mux-Fault-Status"
+	FaultDropAfterHeader   = "X-LLM-Mux-Fault-Drop-After"
+	FaultMalformedAtHeader = "X-LLM-Mux-Fault-Malformed-At"
+	FaultDelayHeader       = "X-LLM-Mux-Fault-Delay-Ms"
+)
+
+type faultSettings struct {
+	cfg     config.FaultInjection
+	headers bool
+}
+
+// FaultInjector attaches the configured faults to each request so the
+// provider fault injection middleware applies them. The settings can be
+// changed while the server runs.
+type FaultInjector struct {
+	settings atomic.Pointer[faultSettings]
+}
+
+// NewFaultInjector creates an injector. Per-request fault headers are only
+// honored when headers is true.
+func NewFaultInjector(cfg config.FaultInjection, headers bool) *FaultInjector {
+	f := &FaultInjector{}
+	f.SetConfig(cfg, headers)
+	return f
+}
+
+// SetConfig replaces the fault settings.
+func (f *FaultInjector) SetConfig(cfg config.FaultInjection, headers bool) {
+	f.settings.Store(&faultSettings{cfg: cfg, headers: headers})
+}
+
+// Middleware returns a Gin handler that does nothing while fault injection
+// is disabled. Invalid fault headers are rejected with 400.
+func (f *FaultInjector) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		s := f.settings.Load()
+		if s == nil || !s.cfg.Enabled {
+			c.Next()
+			return
+		}
+		spec := provider.FaultSpec{
+			Status:      s.cfg.Status,
+			DropAfter:   s.cfg.DropAfter,
+			MalformedAt: s.cfg.MalformedAt,
+			ChunkDelay:  s.cfg.ChunkDelay(),
+		}
+		if s.headers {
+			if err := applyFaultHeaders(c.Request.Header, &spec); err != nil {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": gin.H{
+					"message": err.Error(),
+					"type":    "invalid_request_error",
+					"code":    "invalid_fault_header",
+				}})
+				return
+			}
+		}
+		if spec.Active() {
+			c.Request = c.Request.WithContext(provider.WithFaultSpec(c.Request.Context(), spec))
+		}
+		c.Next()
+	}
+}
+
+// applyFaultHeaders overrides the fields of spec set by fault headers.
+func applyFaultHeaders(h http.Header, spec *provider.FaultSpec) error {
+	for _, field := range []struct {
+		header string
+		set    func(int)
+	}{
+		{FaultStatusHeader, func(v int) { spec.Status = v }},
+		{FaultDropAfterHeader, func(v int) { spec.DropAfter = v }},
+		{FaultMalformedAtHeader, func(v int) { spec.MalformedAt = v }},
+		{FaultDelayHeader, func(v int) { spec.ChunkDelay = time.Duration(v) * time.Millisecond }},
+	} {
+		raw := strings.TrimSpace(h.Get(field.header))
+		if raw == "" {
+			continue
+		}
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < 0 {
+			return fmt.Errorf("%s must be a non-negative integer", field.header)
+		}
+		field.set(v)
+	}
+	if h.Get(FaultStatusHeader) != "" && spec.Status != 0 && (spec.Status < 400 || spec.Status > 599) {
+		return fmt.Errorf("%s must be an HTTP error status", FaultStatusHeader)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+func TestFaultInjector(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var got provider.FaultSpec
+	var attached bool
+	f := NewFaultInjector(config.FaultInjection{}, false)
+	engine := gin.New()
+	engine.Use(f.Middleware())
+	engine.POST("/v1/chat/completions", func(c *gin.Context) {
+		got, attached = provider.FaultSpecFromContext(c.Request.Context())
+		c.Status(http.StatusOK)
+	})
+	do := func(headers map[string]string) int {
+		got, attached = provider.FaultSpec{}, false
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		return w.Code
+	}
+	headers := map[string]string{FaultDropAfterHeader: "3", FaultDelayHeader: "20"}
+
+	if do(headers); attached {
+		t.Fatal("faults attached while fault injection is disabled")
+	}
+
+	f.SetConfig(config.FaultInjection{Enabled: true, MalformedAt: 2}, false)
+	if do(headers); !attached || got != (provider.FaultSpec{MalformedAt: 2}) {
+		t.Errorf("without debug got %+v, want only the configured faults", got)
+	}
+
+	f.SetConfig(config.FaultInjection{Enabled: true, MalformedAt: 2}, true)
+	want := provider.FaultSpec{MalformedAt: 2, DropAfter: 3, ChunkDelay: 20 * time.Millisecond}
+	if do(headers); !attached || got != want {
+		t.Errorf("with debug got %+v, want %+v", got, want)
+	}
+
+	for _, bad := range []map[string]string{{FaultDropAfterHeader: "x"}, {FaultStatusHeader: "200"}, {FaultDelayHeader: "-1"}} {
+		if code := do(bad); code != http.StatusBadRequest {
+			t.Errorf("headers %v: status %d, want 400", bad, code)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// drainPollInterval is how often Drain re-checks the active request count.
+const drainPollInterval = 50 * time.Millisecond
+
+// DrainStats reports how in-flight requests fared during shutdown.
+type DrainStats struct {
+	// Drained counts requests that completed after draining began.
+	Drained int64 `json:"drained"`
+	// Forced counts requests still active when the drain timeout expired.
+	Forced int64 `json:"forced"`
+}
+
+// InFlightTracker counts active requests, including streaming responses, so
+// the server can wait for them to finish before closing connections.
+type InFlightTracker struct {
+	active   atomic.Int64
+	draining atomic.Bool
+	drained  atomic.Int64
+	forced   atomic.Int64
+}
+
+// NewInFlightTracker creates an empty tracker.
+func NewInFlightTracker() *InFlightTracker {
+	return &InFlightTracker{}
+}
+
+// Middleware returns a Gin handler that registers each request for its full
+// lifetime, including the time spent streaming the response body.
+func (t *InFlightTracker) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		t.active.Add(1)
+		defer func() {
+			t.active.Add(-1)
+			if t.draining.Load() {
+				t.drained.Add(1)
+			}
+		}()
+		c.Next()
+	}
+}
+
+// Active returns the number of requests currently being served.
+func (t *InFlightTracker) Active() int64 {
+	return t.active.Load()
+}
+
+// Drain marks the start of shutdown and blocks until every tracked request
+// has finished or ctx is done. When ctx expires first, the requests still
+// active are counted as forcibly terminated and false is returned.
+func (t *InFlightTracker) Drain(ctx context.Context) bool {
+	t.draining.Store(true)
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+	for {
+		if t.active.Load() <= 0 {
+			return true
+		}
+		select {
+		case <-ctx.Done():
+			t.draining.Store(false)
+			t.forced.Add(t.active.Load())
+			return false
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stats returns the drain counters collected so far.
+func (t *InFlightTracker) Stats() DrainStats {
+	return DrainStats{Drained: t.drained.Load(), Forced: t.forced.Load()}
+}
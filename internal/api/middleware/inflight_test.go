@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// serveHeld starts a request on engine that blocks until release is closed
+// and returns a channel closed once the request has finished.
+func serveHeld(t *testing.T, tracker *InFlightTracker, release chan struct{}) <-chan struct{} {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	started := make(chan struct{})
+	engine := gin.New()
+	engine.Use(tracker.Middleware())
+	engine.GET("/held", func(c *gin.Context) {
+		close(started)
+		<-release
+		c.Status(http.StatusOK)
+	})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/held", nil))
+	}()
+	<-started
+	return done
+}
+
+func TestInFlightTracker_DrainWaitsForRequests(t *testing.T) {
+	tracker := NewInFlightTracker()
+	release := make(chan struct{})
+	done := serveHeld(t, tracker, release)
+	if got := tracker.Active(); got != 1 {
+		t.Fatalf("active = %d, want 1", got)
+	}
+
+	drained := make(chan bool, 1)
+	go func() { drained <- tracker.Drain(context.Background()) }()
+	select {
+	case <-drained:
+		t.Fatal("Drain returned while a request was still active")
+	case <-time.After(3 * drainPollInterval):
+	}
+	close(release)
+	<-done
+	select {
+	case ok := <-drained:
+		if !ok {
+			t.Fatal("Drain reported a timeout")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Drain did not return after the request finished")
+	}
+	if stats := tracker.Stats(); stats != (DrainStats{Drained: 1}) {
+		t.Errorf("stats = %+v, want one drained request", stats)
+	}
+}
+
+func TestInFlightTracker_DrainTimeoutForcesRemaining(t *testing.T) {
+	tracker := NewInFlightTracker()
+	release := make(chan struct{})
+	done := serveHeld(t, tracker, release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*drainPollInterval)
+	defer cancel()
+	if tracker.Drain(ctx) {
+		t.Fatal("Drain succeeded with a request still active")
+	}
+	if stats := tracker.Stats(); stats != (DrainStats{Forced: 1}) {
+		t.Errorf("stats after timeout = %+v, want one forced request", stats)
+	}
+
+	// The forced request finishing later is not counted as drained.
+	close(release)
+	<-done
+	if stats := tracker.Stats(); stats != (DrainStats{Forced: 1}) {
+		t.Errorf("stats after forced close = %+v, want one forced request", stats)
+	}
+	if got := tracker.Active(); got != 0 {
+		t.Errorf("active = %d, want 0", got)
+	}
+}
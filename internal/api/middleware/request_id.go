@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+)
+
+// maxRequestIDLength bounds client-supplied request IDs so they cannot bloat logs.
+const maxRequestIDLength = 128
+
+// RequestIDMiddleware assigns every request a stable ID. An incoming X-Request-ID
+// is reused when valid; otherwise a UUID is generated. The ID is stored on the gin
+// context, written back onto the request headers so request logs include it, and
+// echoed in the response.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := strings.TrimSpace(c.GetHeader(logging.RequestIDHeader))
+		if !validRequestID(id) {
+			id = ir.GenerateUUID()
+		}
+		c.Request.Header.Set(logging.RequestIDHeader, id)
+		c.Set(logging.RequestIDKey, id)
+		c.Header(logging.RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+// validRequestID accepts short IDs made of printable ASCII without spaces.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
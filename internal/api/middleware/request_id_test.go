@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/logging"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var seen, forwarded string
+	r := gin.New()
+	r.Use(RequestIDMiddleware())
+	r.GET("/", func(c *gin.Context) {
+		seen = c.GetString(logging.RequestIDKey)
+		forwarded = c.Request.Header.Get(logging.RequestIDHeader)
+	})
+
+	for _, tt := range []struct {
+		name     string
+		incoming string
+		want     string // empty for a generated UUID
+	}{
+		{"client id", "trace-42", "trace-42"},
+		{"client id with padding", "  trace-43 ", "trace-43"},
+		{"absent", "", ""},
+		{"contains space", "trace 44", ""},
+		{"too long", strings.Repeat("a", maxRequestIDLength+1), ""},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			seen, forwarded = "", ""
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.incoming != "" {
+				req.Header.Set(logging.RequestIDHeader, tt.incoming)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			echoed := w.Header().Get(logging.RequestIDHeader)
+			if echoed != seen || echoed != forwarded {
+				t.Fatalf("response %q, context %q, request header %q; want one ID", echoed, seen, forwarded)
+			}
+			if tt.want != "" && echoed != tt.want {
+				t.Errorf("ID = %q, want %q", echoed, tt.want)
+			}
+			if tt.want == "" && !uuidPattern.MatchString(echoed) {
+				t.Errorf("ID = %q, want a generated UUID", echoed)
+			}
+		})
+	}
+}
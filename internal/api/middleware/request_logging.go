@@ -8,6 +8,7 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/nghyane/llm-mux/internal/logging"
@@ -18,7 +19,8 @@ import (
 // It captures detailed information about the request and response, including headers and body,
 // and uses the provided RequestLogger to record this data. When logging is disabled in the
 // logger, it still captures data so that upstream errors can be persisted.
-func RequestLoggingMiddleware(logger logging.RequestLogger) gin.HandlerFunc {
+// When sampler is non-nil, full bodies of sampled requests are also written to it.
+func RequestLoggingMiddleware(logger logging.RequestLogger, sampler *logging.BodySampler) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if logger == nil {
 			c.Next()
@@ -50,6 +52,11 @@ func RequestLoggingMiddleware(logger logging.RequestLogger) gin.HandlerFunc {
 		if !logger.IsEnabled() {
 			wrapper.logOnErrorOnly = true
 		}
+		if sampler != nil {
+			wrapper.sampler = sampler
+			wrapper.sampleSelected = sampler.Select(c.Request.Header)
+			wrapper.requestID = c.GetString(logging.RequestIDKey)
+		}
 		c.Writer = wrapper
 
 		// Process the request
@@ -83,12 +90,10 @@ func captureRequestInfo(c *gin.Context) (*RequestInfo, error) {
 		headers[key] = values
 	}
 
-	// Capture request body (limit to 10MB to prevent memory exhaustion)
-	const maxBodySize = 10 * 1024 * 1024
+	// Capture request body; its size is already capped by the body limiter
 	var body []byte
 	if c.Request.Body != nil {
-		// Read the body with size limit
-		bodyBytes, err := io.ReadAll(io.LimitReader(c.Request.Body, maxBodySize))
+		bodyBytes, err := io.ReadAll(c.Request.Body)
 		if err != nil {
 			return nil, err
 		}
@@ -99,10 +104,11 @@ func captureRequestInfo(c *gin.Context) (*RequestInfo, error) {
 	}
 
 	return &RequestInfo{
-		URL:     url,
-		Method:  method,
-		Headers: headers,
-		Body:    body,
+		URL:       url,
+		Method:    method,
+		Headers:   headers,
+		Body:      body,
+		StartedAt: time.Now(),
 	}, nil
 }
 
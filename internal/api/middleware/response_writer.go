@@ -7,6 +7,7 @@ import (
 	"bytes"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/nghyane/llm-mux/internal/interfaces"
@@ -16,10 +17,11 @@ import (
 
 // RequestInfo holds essential details of an incoming HTTP request for logging purposes.
 type RequestInfo struct {
-	URL     string
-	Method  string
-	Headers map[string][]string
-	Body    []byte
+	URL       string
+	Method    string
+	Headers   map[string][]string
+	Body      []byte
+	StartedAt time.Time
 }
 
 // ResponseWriterWrapper wraps gin.ResponseWriter to capture response data for logging.
@@ -35,6 +37,10 @@ type ResponseWriterWrapper struct {
 	statusCode     int
 	headers        map[string][]string
 	logOnErrorOnly bool
+	sampler        *logging.BodySampler
+	sampleSelected bool
+	requestID      string
+	tee            *logging.TeeStream
 }
 
 // NewResponseWriterWrapper creates and initializes a new ResponseWriterWrapper.
@@ -78,6 +84,12 @@ func (w *ResponseWriterWrapper) Write(data []byte) (int, error) {
 			default: // Channel full, skip logging to avoid blocking
 			}
 		}
+		// Keep a bounded copy for body sampling
+		if w.sampleSelected && w.body.Len() <= w.sampler.MaxBodyBytes() {
+			w.body.Write(data)
+		}
+		// Mirror to the stream tee, which drops chunks rather than block
+		w.tee.Write(data)
 	} else {
 		// For non-streaming responses: Buffer complete response
 		w.body.Write(data)
@@ -120,6 +132,15 @@ func (w *ResponseWriterWrapper) WriteHeader(statusCode int) {
 			_ = streamWriter.WriteStatus(statusCode, w.headers)
 		}
 	}
+	if w.isStreaming && w.sampleSelected && w.tee == nil {
+		w.tee = w.sampler.OpenStreamTee(logging.TeeStreamInfo{
+			RequestID: w.requestID,
+			Method:    w.requestInfo.Method,
+			URL:       w.requestInfo.URL,
+			Status:    statusCode,
+			StartedAt: w.requestInfo.StartedAt,
+		})
+	}
 
 	// Call original WriteHeader
 	w.ResponseWriter.WriteHeader(statusCode)
@@ -215,11 +236,20 @@ func (w *ResponseWriterWrapper) Finalize(c *gin.Context) error {
 	}
 
 	hasAPIError := len(slicesAPIResponseError) > 0 || finalStatusCode >= http.StatusBadRequest
+	w.writeSample(c, finalStatusCode, hasAPIError)
+	w.tee.Close()
+
 	forceLog := w.logOnErrorOnly && hasAPIError && !w.logger.IsEnabled()
 	if !w.logger.IsEnabled() && !forceLog {
 		return nil
 	}
 
+	if summaryLogger, ok := w.logger.(logging.SummaryRequestLogger); ok {
+		w.closeStream()
+		ir.PutBuffer(w.body)
+		return summaryLogger.LogRequestSummary(w.summary(c, finalStatusCode, slicesAPIResponseError), forceLog)
+	}
+
 	if w.isStreaming {
 		if w.chunkChannel != nil {
 			close(w.chunkChannel)
@@ -249,6 +279,56 @@ func (w *ResponseWriterWrapper) Finalize(c *gin.Context) error {
 	return err
 }
 
+// writeSample stores the full request and response bodies when the request was
+// selected for sampling up front, or when it failed and error sampling is on.
+func (w *ResponseWriterWrapper) writeSample(c *gin.Context, statusCode int, failed bool) {
+	if w.sampler == nil || w.requestInfo == nil {
+		return
+	}
+	if !w.sampleSelected && !(failed && w.sampler.WantsError()) {
+		return
+	}
+	if err := w.sampler.Write(c.GetString(logging.RequestIDKey), w.requestInfo.Method, w.requestInfo.URL, statusCode, w.requestInfo.Body, w.body.Bytes()); err != nil {
+		logging.WithError(err).Warn("failed to write body sample")
+	}
+}
+
+// closeStream stops the streaming chunk processor, if any, without logging.
+func (w *ResponseWriterWrapper) closeStream() {
+	if w.chunkChannel != nil {
+		close(w.chunkChannel)
+		w.chunkChannel = nil
+	}
+	if w.streamDone != nil {
+		<-w.streamDone
+		w.streamDone = nil
+	}
+	if w.streamWriter != nil {
+		_ = w.streamWriter.Close()
+		w.streamWriter = nil
+	}
+}
+
+func (w *ResponseWriterWrapper) summary(c *gin.Context, statusCode int, apiErrors []*interfaces.ErrorMessage) logging.RequestSummary {
+	summary := logging.RequestSummary{
+		Timestamp:  time.Now(),
+		RequestID:  c.GetString(logging.RequestIDKey),
+		ClientKey:  logging.ClientKeyLabel(c),
+		StatusCode: statusCode,
+		Meta:       logging.RequestMetaFromGin(c),
+		Errors:     apiErrors,
+	}
+	if w.requestInfo != nil {
+		summary.Method = w.requestInfo.Method
+		summary.URL = w.requestInfo.URL
+		if !w.requestInfo.StartedAt.IsZero() {
+			summary.Timestamp = w.requestInfo.StartedAt
+			summary.Latency = time.Since(w.requestInfo.StartedAt)
+		}
+	}
+	return summary
+}
+
 func (w *ResponseWriterWrapper) cloneHeaders() map[string][]string {
 	w.ensureHeadersCaptured()
 
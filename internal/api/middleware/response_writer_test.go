@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/logging"
+)
+
+// stalledTeeSink holds every chunk until release is closed.
+type stalledTeeSink struct {
+	release chan struct{}
+	written int
+	done    chan logging.TeeResult
+}
+
+func (s *stalledTeeSink) Open(logging.TeeStreamInfo) (logging.TeeWriter, error) { return s, nil }
+
+func (s *stalledTeeSink) Write([]byte) error {
+	<-s.release
+	s.written++
+	return nil
+}
+
+func (s *stalledTeeSink) Close(result logging.TeeResult) error {
+	s.done <- result
+	return nil
+}
+
+func TestStreamTee_SlowSinkDoesNotDelayClient(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	const chunks = 500
+	sink := &stalledTeeSink{release: make(chan struct{}), done: make(chan logging.TeeResult, 1)}
+	sampler := logging.NewBodySampler(t.TempDir())
+	sampler.SetConfig(logging.SamplingConfig{AllowForce: true})
+	sampler.SetStreamTee(logging.NewStreamTee(sink, 8, 1<<20))
+
+	engine := gin.New()
+	engine.Use(RequestLoggingMiddleware(logging.NewFileRequestLogger(false, t.TempDir(), ""), sampler))
+	engine.POST("/v1/chat/completions", func(c *gin.Context) {
+		c.Header("Content-Type", "text/event-stream")
+		c.Status(http.StatusOK)
+		c.Writer.WriteHeaderNow()
+		for i := range chunks {
+			fmt.Fprintf(c.Writer, "data: {\"i\":%d}\n\n", i)
+			c.Writer.Flush()
+		}
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(`{"stream":true}`))
+	req.Header.Set(logging.SampleHeader, "true")
+	w := httptest.NewRecorder()
+	served := make(chan struct{})
+	go func() {
+		defer close(served)
+		engine.ServeHTTP(w, req)
+	}()
+	select {
+	case <-served:
+	case <-time.After(5 * time.Second):
+		close(sink.release)
+		t.Fatal("client stream blocked on the tee sink")
+	}
+	if got := strings.Count(w.Body.String(), "data: "); got != chunks {
+		t.Fatalf("client received %d chunks, want %d", got, chunks)
+	}
+
+	close(sink.release)
+	select {
+	case result := <-sink.done:
+		if result.Dropped == 0 || sink.written+result.Dropped != chunks {
+			t.Errorf("sink wrote %d chunks and dropped %d, want drops adding up to %d", sink.written, result.Dropped, chunks)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("tee never closed")
+	}
+}
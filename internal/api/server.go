@@ -8,6 +8,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -42,6 +43,8 @@ type serverOptionConfig struct {
 	keepAliveEnabled     bool
 	keepAliveTimeout     time.Duration
 	keepAliveOnTimeout   func()
+	managementAddr       string
+	managementKey        string
 }
 
 // ServerOption customises HTTP server construction.
@@ -55,6 +58,39 @@ func defaultRequestLoggerFactory(cfg *config.Config, configPath string) logging.
 	return logging.NewFileRequestLogger(cfg.RequestLog, "logs", configDir)
 }
 
+// requestLogsDir resolves the directory the default request logger writes to.
+func requestLogsDir(configPath string) string {
+	dir := "logs"
+	if base := util.WritablePath(); base != "" {
+		dir = filepath.Join(base, "logs")
+	}
+	if filepath.IsAbs(dir) {
+		return dir
+	}
+	return filepath.Join(filepath.Dir(configPath), dir)
+}
+
+// samplingConfig converts the log-sampling section for the body sampler.
+// Clients may force a sample only in debug mode or by presenting the
+// management key, mgmtKey when set and the stored key otherwise.
+func samplingConfig(cfg *config.Config, mgmtKey string) logging.SamplingConfig {
+	if mgmtKey == "" {
+		mgmtKey = config.GetManagementKey()
+	}
+	return logging.SamplingConfig{
+		Rate:         cfg.LogSampling.Rate,
+		OnError:      cfg.LogSampling.Errors,
+		MaxBodyBytes: cfg.LogSampling.MaxBodyBytes,
+		Tee: logging.StreamTeeConfig{
+			File:         cfg.LogSampling.StreamTee.File,
+			WebhookURL:   cfg.LogSampling.StreamTee.Webhook,
+			BufferChunks: cfg.LogSampling.StreamTee.BufferChunks,
+		},
+		AllowForce: cfg.Debug,
+		ForceKey:   mgmtKey,
+	}
+}
+
 // WithMiddleware appends additional Gin middleware during server construction.
 func WithMiddleware(mw ...gin.HandlerFunc) ServerOption {
 	return func(cfg *serverOptionConfig) {
@@ -115,6 +151,7 @@ type Server struct {
 	accessManager  *access.Manager
 	requestLogger  logging.RequestLogger
 	loggerToggle   func(bool)
+	sampler        *logging.BodySampler
 	configFilePath string
 	currentPath    string
 
@@ -126,6 +163,12 @@ type Server struct {
 	mgmt      *managementHandlers.Handler
 	ampModule *ampmodule.AmpModule
 
+	// mgmtEngine and mgmtServer serve the management API when it has its
+	// own listener; both are nil when it shares the main one.
+	mgmtEngine *gin.Engine
+	mgmtServer *http.Server
+	mgmtKey    string
+
 	managementRoutesRegistered atomic.Bool
 	managementRoutesEnabled    atomic.Bool
 
@@ -135,6 +178,12 @@ type Server struct {
 	keepAliveOnTimeout func()
 	keepAliveHeartbeat chan struct{}
 	keepAliveStop      chan struct{}
+
+	inflight           *middleware.InFlightTracker
+	bodyLimiter        *middleware.BodyLimiter
+	decompressor       *middleware.Decompressor
+	responseCompressor *middleware.ResponseCompressor
+	faultInjector      *middleware.FaultInjector
 }
 
 // NewServer creates and initializes a new API server instance.
@@ -162,8 +211,23 @@ func NewServer(cfg *config.Config, authManager *provider.Manager, accessManager
 		optionState.engineConfigurator(engine)
 	}
 
+	engine.Use(middleware.RequestIDMiddleware())
 	engine.Use(logging.GinLogrusLogger())
 	engine.Use(logging.GinLogrusRecovery())
+	inflight := middleware.NewInFlightTracker()
+	engine.Use(inflight.Middleware())
+	bodyLimiter := middleware.NewBodyLimiter(cfg.BodyLimits())
+	engine.Use(bodyLimiter.Middleware())
+	decompressor := middleware.NewDecompressor(cfg.Compression, bodyLimiter)
+	engine.Use(decompressor.Middleware())
+	// Registered before request logging so logs see uncompressed responses.
+	responseCompressor := middleware.NewResponseCompressor(cfg.Compression)
+	engine.Use(responseCompressor.Middleware())
+	faultInjector := middleware.NewFaultInjector(cfg.FaultInjection, cfg.Debug)
+	engine.Use(faultInjector.Middleware())
+	if cfg.FaultInjection.Enabled {
+		log.Warn("fault injection is enabled: upstream responses will be broken on purpose")
+	}
 	for _, mw := range optionState.extraMiddleware {
 		engine.Use(mw)
 	}
@@ -171,12 +235,15 @@ func NewServer(cfg *config.Config, authManager *provider.Manager, accessManager
 	// Add request logging middleware (positioned after recovery, before auth)
 	// Resolve logs directory relative to the configuration file directory.
 	var requestLogger logging.RequestLogger
+	var sampler *logging.BodySampler
 	var toggle func(bool)
 	if optionState.requestLoggerFactory != nil {
-		requestLogger = optionState.requestLoggerFactory(cfg, configFilePath)
+		requestLogger = logging.NewRedactingRequestLogger(optionState.requestLoggerFactory(cfg, configFilePath))
 	}
 	if requestLogger != nil {
-		engine.Use(middleware.RequestLoggingMiddleware(requestLogger))
+		sampler = logging.NewBodySampler(filepath.Join(requestLogsDir(configFilePath), "samples"))
+		sampler.SetConfig(samplingConfig(cfg, optionState.managementKey))
+		engine.Use(middleware.RequestLoggingMiddleware(requestLogger, sampler))
 		if setter, ok := requestLogger.(interface{ SetEnabled(bool) }); ok {
 			toggle = setter.SetEnabled
 		}
@@ -195,15 +262,21 @@ func NewServer(cfg *config.Config, authManager *provider.Manager, accessManager
 		}
 	}
 	s := &Server{
-		engine:         engine,
-		handlers:       format.NewBaseAPIHandlers(&cfg.SDKConfig, &cfg.Routing, authManager, providerNames),
-		cfg:            cfg,
-		accessManager:  accessManager,
-		requestLogger:  requestLogger,
-		loggerToggle:   toggle,
-		configFilePath: configFilePath,
-		currentPath:    wd,
-		wsRoutes:       make(map[string]struct{}),
+		engine:             engine,
+		inflight:           inflight,
+		bodyLimiter:        bodyLimiter,
+		decompressor:       decompressor,
+		responseCompressor: responseCompressor,
+		faultInjector:      faultInjector,
+		handlers:           format.NewBaseAPIHandlers(&cfg.SDKConfig, &cfg.Routing, authManager, providerNames),
+		cfg:                cfg,
+		accessManager:      accessManager,
+		requestLogger:      requestLogger,
+		loggerToggle:       toggle,
+		sampler:            sampler,
+		configFilePath:     configFilePath,
+		currentPath:        wd,
+		wsRoutes:           make(map[string]struct{}),
 	}
 	s.wsAuthEnabled.Store(cfg.WebsocketAuth)
 	// Save initial YAML snapshot
@@ -218,9 +291,17 @@ func NewServer(cfg *config.Config, authManager *provider.Manager, accessManager
 	registry.GetGlobalRegistry().SetShowProviderPrefixes(cfg.ShowProviderPrefixes)
 	// Initialize management handler
 	s.mgmt = managementHandlers.NewHandler(cfg, configFilePath, authManager)
+	s.mgmt.SetAPIHandlers(s.handlers)
+	s.mgmt.SetInFlightTracker(inflight)
 	if optionState.localPassword != "" {
 		s.mgmt.SetLocalPassword(optionState.localPassword)
 	}
+	if optionState.managementAddr != "" {
+		s.mgmtEngine = s.newManagementEngine()
+		s.mgmtServer = &http.Server{Addr: optionState.managementAddr, Handler: s.mgmtEngine}
+		s.mgmtKey = optionState.managementKey
+		s.mgmt.SetManagementKey(optionState.managementKey)
+	}
 	logDir := filepath.Join(s.currentPath, "logs")
 	if base := util.WritablePath(); base != "" {
 		logDir = filepath.Join(base, "logs")
@@ -252,7 +333,7 @@ func NewServer(cfg *config.Config, authManager *provider.Manager, accessManager
 	}
 
 	// Register management routes when configuration or environment secrets are available.
-	hasManagementSecret := config.HasManagementKey()
+	hasManagementSecret := s.hasManagementKey()
 	s.managementRoutesEnabled.Store(hasManagementSecret)
 	if hasManagementSecret {
 		s.registerManagementRoutes()
@@ -280,6 +361,10 @@ func (s *Server) Start() error {
 		return fmt.Errorf("failed to start HTTP server: server not initialized")
 	}
 
+	if err := s.startManagementListener(); err != nil {
+		return err
+	}
+
 	useTLS := s.cfg != nil && s.cfg.TLS.Enable
 	if useTLS {
 		cert := strings.TrimSpace(s.cfg.TLS.Cert)
@@ -319,20 +404,64 @@ func (s *Server) Stop(ctx context.Context) error {
 		}
 	}
 
-	// Shutdown the HTTP server.
-	if err := s.server.Shutdown(ctx); err != nil {
+	// Stop accepting new connections and wait for in-flight requests,
+	// including streams, until ctx expires; then force the rest closed.
+	shutdownErr := make(chan error, 1)
+	go func() { shutdownErr <- s.server.Shutdown(ctx) }()
+	if active := s.inflight.Active(); active > 0 {
+		log.Infof("draining %d in-flight request(s)", active)
+	}
+	if !s.inflight.Drain(ctx) {
+		log.Warnf("drain timeout reached, closing %d in-flight request(s)", s.inflight.Active())
+		_ = s.server.Close()
+	}
+	if s.mgmtServer != nil {
+		if errMgmt := s.mgmtServer.Shutdown(ctx); errMgmt != nil {
+			_ = s.mgmtServer.Close()
+		}
+	}
+	err := <-shutdownErr
+	stats := s.inflight.Stats()
+	log.Infof("shutdown drain finished: drained=%d forced=%d", stats.Drained, stats.Forced)
+	if err != nil && !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, context.Canceled) {
 		return fmt.Errorf("failed to shutdown HTTP server: %v", err)
 	}
 
+	if closer, ok := s.requestLogger.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			log.Warnf("Failed to close request logger: %v", err)
+		}
+	}
+
 	// Stop usage persistence and flush pending writes
 	if err := usage.StopPersistence(); err != nil {
 		log.Warnf("Failed to stop usage persistence: %v", err)
 	}
+	if err := usage.StopCounters(); err != nil {
+		log.Warnf("Failed to save usage counters: %v", err)
+	}
 
 	log.Debug("API server stopped")
 	return nil
 }
 
+// DrainStats reports how many requests were drained or forcibly terminated
+// during shutdown.
+func (s *Server) DrainStats() middleware.DrainStats {
+	if s == nil || s.inflight == nil {
+		return middleware.DrainStats{}
+	}
+	return s.inflight.Stats()
+}
+
+// ActiveRequests returns the number of requests currently being served.
+func (s *Server) ActiveRequests() int64 {
+	if s == nil || s.inflight == nil {
+		return 0
+	}
+	return s.inflight.Active()
+}
+
 func (s *Server) applyAccessConfig(oldCfg, newCfg *config.Config) {
 	if s == nil || s.accessManager == nil || newCfg == nil {
 		return
@@ -372,6 +501,17 @@ func (s *Server) UpdateClients(cfg *config.Config) {
 		}
 	}
 
+	if s.sampler != nil {
+		s.sampler.SetConfig(samplingConfig(cfg, s.mgmtKey))
+	}
+	s.bodyLimiter.SetLimits(cfg.BodyLimits())
+	s.decompressor.SetConfig(cfg.Compression)
+	s.responseCompressor.SetConfig(cfg.Compression)
+	s.faultInjector.SetConfig(cfg.FaultInjection, cfg.Debug)
+	if cfg.FaultInjection.Enabled && oldCfg != nil && !oldCfg.FaultInjection.Enabled {
+		log.Warn("fault injection is enabled: upstream responses will be broken on purpose")
+	}
+
 	if oldCfg != nil && oldCfg.LoggingToFile != cfg.LoggingToFile {
 		if err := logging.ConfigureLogOutput(cfg.LoggingToFile); err != nil {
 			log.Errorf("failed to reconfigure log output: %v", err)
@@ -411,9 +551,10 @@ func (s *Server) UpdateClients(cfg *config.Config) {
 		}
 	}
 
-	// Management routes are controlled by credentials.json (fixed path).
+	// Management routes are controlled by credentials.json (fixed path) or
+	// the key of a dedicated management listener.
 	// Check if management key is available and enable/disable routes accordingly.
-	hasManagementKey := config.HasManagementKey()
+	hasManagementKey := s.hasManagementKey()
 	if hasManagementKey {
 		s.registerManagementRoutes()
 		if s.managementRoutesEnabled.CompareAndSwap(false, true) {
@@ -454,6 +595,7 @@ func (s *Server) UpdateClients(cfg *config.Config) {
 	s.handlers.OpenAICompatProviders = providerNames
 
 	s.handlers.UpdateClients(&cfg.SDKConfig)
+	s.handlers.UpdateRouting(&cfg.Routing)
 
 	if s.mgmt != nil {
 		s.mgmt.SetConfig(cfg)
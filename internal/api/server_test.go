@@ -1,6 +1,7 @@
 package api
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -10,11 +11,12 @@ import (
 
 	gin "github.com/gin-gonic/gin"
 	"github.com/nghyane/llm-mux/internal/access"
+	managementHandlers "github.com/nghyane/llm-mux/internal/api/handlers/management"
 	proxyconfig "github.com/nghyane/llm-mux/internal/config"
 	"github.com/nghyane/llm-mux/internal/provider"
 )
 
-func newTestServer(t *testing.T) *Server {
+func newTestServer(t *testing.T, opts ...ServerOption) *Server {
 	t.Helper()
 
 	gin.SetMode(gin.TestMode)
@@ -40,7 +42,7 @@ func newTestServer(t *testing.T) *Server {
 	accessManager := access.NewManager()
 
 	configPath := filepath.Join(tmpDir, "config.yaml")
-	return NewServer(cfg, authManager, accessManager, configPath)
+	return NewServer(cfg, authManager, accessManager, configPath, opts...)
 }
 
 func TestAmpProviderModelRoutes(t *testing.T) {
@@ -108,3 +110,127 @@ func TestAmpProviderModelRoutes(t *testing.T) {
 		})
 	}
 }
+
+func TestManagementListenerRouteSets(t *testing.T) {
+	server := newTestServer(t, WithManagementListener("127.0.0.1:0", "mgmt-key"))
+	if server.mgmtEngine == nil || server.mgmtServer == nil {
+		t.Fatal("management listener not configured")
+	}
+
+	serve := func(engine *gin.Engine, path, key string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = "127.0.0.1:12345"
+		req.Header.Set("Authorization", "Bearer "+key)
+		rr := httptest.NewRecorder()
+		engine.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	if code := serve(server.engine, "/v1/models", "test-key"); code != http.StatusOK {
+		t.Errorf("data plane /v1/models = %d, want 200", code)
+	}
+	if code := serve(server.engine, "/v0/management/config", "mgmt-key"); code != http.StatusNotFound {
+		t.Errorf("data plane /v0/management/config = %d, want 404", code)
+	}
+	if code := serve(server.mgmtEngine, "/v0/management/config", "mgmt-key"); code != http.StatusOK {
+		t.Errorf("management /v0/management/config = %d, want 200", code)
+	}
+	if code := serve(server.mgmtEngine, "/v0/management/config", "test-key"); code != http.StatusUnauthorized {
+		t.Errorf("management with API key = %d, want 401", code)
+	}
+	if code := serve(server.mgmtEngine, "/v1/models", "test-key"); code != http.StatusNotFound {
+		t.Errorf("management /v1/models = %d, want 404", code)
+	}
+}
+
+func TestValidateManagementAddress(t *testing.T) {
+	cases := []struct {
+		addr    string
+		wantErr bool
+	}{
+		{"127.0.0.1:9090", false},
+		{":9090", false},
+		{"10.0.0.5:8317", false},
+		{"127.0.0.1:8317", true},
+		{":8317", true},
+		{"localhost", true},
+		{"127.0.0.1:0", true},
+		{"127.0.0.1:70000", true},
+	}
+	for _, tc := range cases {
+		err := ValidateManagementAddress(tc.addr, 8317)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("ValidateManagementAddress(%q) error = %v, wantErr %v", tc.addr, err, tc.wantErr)
+		}
+	}
+}
+
+func TestManagementAuditRecordsMutations(t *testing.T) {
+	server := newTestServer(t, WithManagementListener("127.0.0.1:0", "mgmt-key-123456"))
+	server.mgmt.SetLogDirectory(t.TempDir())
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader("{}"))
+		req.RemoteAddr = "127.0.0.1:12345"
+		req.Header.Set("Authorization", "Bearer mgmt-key-123456")
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		server.mgmtEngine.ServeHTTP(rr, req)
+		return rr
+	}
+
+	var want []string
+	for _, route := range server.mgmtEngine.Routes() {
+		if route.Method == http.MethodGet || route.Path == "/v0/management/route/explain" {
+			continue
+		}
+		path := strings.Replace(route.Path, ":state", "unknown-state", 1)
+		serve(route.Method, path)
+		want = append(want, route.Method+" "+strings.TrimPrefix(route.Path, "/v0/management"))
+	}
+	if len(want) == 0 {
+		t.Fatal("no mutating management routes registered")
+	}
+	serve(http.MethodPost, "/v0/management/route/explain")
+	serve(http.MethodDelete, "/v0/management/oauth-excluded-models?provider=claude&access_token=sk-secret-token")
+
+	rr := serve(http.MethodGet, "/v0/management/audit?limit=1000")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("GET audit = %d: %s", rr.Code, rr.Body.String())
+	}
+	var body struct {
+		Entries []managementHandlers.AuditEntry `json:"entries"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	recorded := make(map[string]managementHandlers.AuditEntry, len(body.Entries))
+	for _, e := range body.Entries {
+		recorded[e.Action] = e
+	}
+	for _, action := range want {
+		e, ok := recorded[action]
+		if !ok {
+			t.Errorf("no audit entry for %s", action)
+			continue
+		}
+		if e.Actor != "management-key:mgmt...3456" || e.ClientIP != "127.0.0.1" || e.Time.IsZero() || e.Status == 0 {
+			t.Errorf("%s: incomplete entry %+v", action, e)
+		}
+		if strings.Contains(e.Actor+e.Target+e.Error, "mgmt-key-123456") {
+			t.Errorf("%s: entry leaks the management key: %+v", action, e)
+		}
+	}
+	if _, ok := recorded["POST /route/explain"]; ok {
+		t.Error("read-only route/explain was audited")
+	}
+	if e := recorded["POST /oauth/cancel/:state"]; e.Target != "state=unknown-state" || e.Result != "failed" || e.Error == "" {
+		t.Errorf("oauth cancel entry = %+v, want a failed entry naming the state", e)
+	}
+	if e := body.Entries[0]; e.Target != "access_token=[REDACTED] provider=claude" {
+		t.Errorf("latest entry target = %q, want the token redacted", e.Target)
+	}
+	if len(body.Entries) != len(want)+1 {
+		t.Errorf("%d audit entries, want %d", len(body.Entries), len(want)+1)
+	}
+}
@@ -23,6 +23,12 @@ type ClaudeTokenStorage struct {
 	Expire       string `json:"expired"`
 }
 
+// MarshalToken returns the JSON SaveTokenToFile writes.
+func (ts *ClaudeTokenStorage) MarshalToken() ([]byte, error) {
+	ts.Type = "claude"
+	return json.Marshal(ts)
+}
+
 // SaveTokenToFile serializes the Claude token storage to a JSON file.
 // This method creates the necessary directory structure and writes the token
 // data in JSON format to the specified file path for persistent storage.
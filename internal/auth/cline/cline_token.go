@@ -27,6 +27,12 @@ type ClineTokenStorage struct {
 	Expire       string `json:"expired"`
 }
 
+// MarshalToken returns the JSON SaveTokenToFile writes.
+func (ts *ClineTokenStorage) MarshalToken() ([]byte, error) {
+	ts.Type = "cline"
+	return json.Marshal(ts)
+}
+
 // SaveTokenToFile serializes the Cline token storage to a JSON file.
 // This method creates the necessary directory structure and writes the token
 // data in JSON format to the specified file path for persistent storage.
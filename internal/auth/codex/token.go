@@ -24,6 +24,12 @@ type CodexTokenStorage struct {
 	Expire       string `json:"expired"`
 }
 
+// MarshalToken returns the JSON SaveTokenToFile writes.
+func (ts *CodexTokenStorage) MarshalToken() ([]byte, error) {
+	ts.Type = "codex"
+	return json.Marshal(ts)
+}
+
 // SaveTokenToFile serializes the Codex token storage to a JSON file.
 // This method creates the necessary directory structure and writes the token
 // data in JSON format to the specified file path for persistent storage.
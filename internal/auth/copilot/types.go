@@ -32,6 +32,9 @@ type DeviceCodeResponse struct {
 type CopilotAPIToken struct {
 	Token     string `json:"token"`
 	ExpiresAt int64  `json:"expires_at"`
+	// RefreshIn is the number of seconds after which GitHub recommends
+	// fetching a new token, ahead of ExpiresAt.
+	RefreshIn int64 `json:"refresh_in,omitempty"`
 	Endpoints struct {
 		API           string `json:"api"`
 		Proxy         string `json:"proxy"`
@@ -69,7 +69,7 @@ func (g *GeminiAuth) GetAuthenticatedClient(ctx context.Context, ts *GeminiToken
 	if err == nil {
 		var transport *http.Transport
 		switch proxyURL.Scheme {
-		case "socks5":
+		case "socks5", "socks5h":
 			// Handle SOCKS5 proxy.
 			username := proxyURL.User.Username()
 			password, _ := proxyURL.User.Password()
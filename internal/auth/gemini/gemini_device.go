@@ -0,0 +1,158 @@
+package gemini
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/json"
+)
+
+const (
+	// GoogleDeviceCodeEndpoint is Google's OAuth 2.0 device authorization endpoint.
+	GoogleDeviceCodeEndpoint = "https://oauth2.googleapis.com/device/code"
+	// GoogleTokenEndpoint exchanges device codes for tokens.
+	GoogleTokenEndpoint = "https://oauth2.googleapis.com/token"
+	// GoogleDeviceGrantType is the grant type used while polling for a device token.
+	GoogleDeviceGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+)
+
+// DeviceFlow represents the response from Google's device authorization endpoint.
+type DeviceFlow struct {
+	// DeviceCode is the code the client uses to poll for an access token.
+	DeviceCode string `json:"device_code"`
+	// UserCode is the code the user enters at the verification URL.
+	UserCode string `json:"user_code"`
+	// VerificationURL is where the user enters the user code.
+	VerificationURL string `json:"verification_url"`
+	// ExpiresIn is the time in seconds until the device and user codes expire.
+	ExpiresIn int `json:"expires_in"`
+	// Interval is the minimum number of seconds between polling requests.
+	Interval int `json:"interval"`
+}
+
+// DeviceToken is the token returned once the user approves the device.
+type DeviceToken struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+	TokenType    string `json:"token_type"`
+	Scope        string `json:"scope"`
+}
+
+// InitiateDeviceFlow starts the Google device authorization flow for the Gemini client.
+func (g *GeminiAuth) InitiateDeviceFlow(ctx context.Context, httpClient *http.Client) (*DeviceFlow, error) {
+	data := url.Values{}
+	data.Set("client_id", geminiOauthClientID)
+	data.Set("scope", strings.Join(geminiOauthScopes, " "))
+
+	body, status, err := postForm(ctx, httpClient, GoogleDeviceCodeEndpoint, data)
+	if err != nil {
+		return nil, fmt.Errorf("device authorization request failed: %w", err)
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("device authorization failed: %d. Response: %s", status, string(body))
+	}
+
+	var result DeviceFlow
+	if err = json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse device flow response: %w", err)
+	}
+	if result.DeviceCode == "" {
+		return nil, fmt.Errorf("device authorization failed: device_code not found in response")
+	}
+	return &result, nil
+}
+
+// PollForToken polls the token endpoint until the user approves or denies the
+// device, the code expires, or ctx is cancelled.
+func (g *GeminiAuth) PollForToken(ctx context.Context, httpClient *http.Client, flow *DeviceFlow) (*DeviceToken, error) {
+	if flow == nil || flow.DeviceCode == "" {
+		return nil, fmt.Errorf("device flow is missing a device code")
+	}
+	pollInterval := time.Duration(flow.Interval) * time.Second
+	if pollInterval <= 0 {
+		pollInterval = 5 * time.Second
+	}
+	deadline := time.Now().Add(time.Duration(flow.ExpiresIn) * time.Second)
+	if flow.ExpiresIn <= 0 {
+		deadline = time.Now().Add(10 * time.Minute)
+	}
+
+	data := url.Values{}
+	data.Set("client_id", geminiOauthClientID)
+	data.Set("client_secret", geminiOauthClientSecret)
+	data.Set("device_code", flow.DeviceCode)
+	data.Set("grant_type", GoogleDeviceGrantType)
+
+	for time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(pollInterval):
+		}
+
+		body, status, err := postForm(ctx, httpClient, GoogleTokenEndpoint, data)
+		if err != nil {
+			continue
+		}
+		if status == http.StatusOK {
+			var token DeviceToken
+			if err = json.Unmarshal(body, &token); err != nil {
+				return nil, fmt.Errorf("failed to parse token response: %w", err)
+			}
+			return &token, nil
+		}
+
+		var errorData struct {
+			Error            string `json:"error"`
+			ErrorDescription string `json:"error_description"`
+		}
+		if err = json.Unmarshal(body, &errorData); err != nil {
+			return nil, fmt.Errorf("device token poll failed: %d. Response: %s", status, string(body))
+		}
+		switch errorData.Error {
+		case "authorization_pending":
+			continue
+		case "slow_down":
+			pollInterval += 5 * time.Second
+			continue
+		case "expired_token":
+			return nil, fmt.Errorf("device code expired. Please restart the authentication process")
+		case "access_denied":
+			return nil, fmt.Errorf("authorization denied by user. Please restart the authentication process")
+		default:
+			return nil, fmt.Errorf("device token poll failed: %s - %s", errorData.Error, errorData.ErrorDescription)
+		}
+	}
+	return nil, fmt.Errorf("device code expired. Please restart the authentication process")
+}
+
+func postForm(ctx context.Context, httpClient *http.Client, endpoint string, data url.Values) ([]byte, int, error) {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(data.Encode()))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, err
+	}
+	return body, resp.StatusCode, nil
+}
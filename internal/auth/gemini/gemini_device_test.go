@@ -0,0 +1,86 @@
+package gemini
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// redirectTransport sends every request to target, keeping its path, so the
+// fixed Google endpoints can be served by a test server.
+type redirectTransport struct{ target *url.URL }
+
+func (t redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme, req.URL.Host = t.target.Scheme, t.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func testClient(t *testing.T, handler http.Handler) *http.Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	target, _ := url.Parse(srv.URL)
+	return &http.Client{Transport: redirectTransport{target: target}}
+}
+
+func TestDeviceFlow(t *testing.T) {
+	var polls atomic.Int32
+	client := testClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if r.Form.Get("client_id") != geminiOauthClientID {
+			t.Errorf("%s: client_id %q", r.URL.Path, r.Form.Get("client_id"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/device/code":
+			if r.Form.Get("scope") != strings.Join(geminiOauthScopes, " ") {
+				t.Errorf("scope %q", r.Form.Get("scope"))
+			}
+			_, _ = w.Write([]byte(`{"device_code":"dev-1","user_code":"ABCD-EFGH","verification_url":"https://www.google.com/device","expires_in":60,"interval":1}`))
+		case "/token":
+			if r.Form.Get("device_code") != "dev-1" || r.Form.Get("grant_type") != GoogleDeviceGrantType {
+				t.Errorf("token poll form %v", r.Form)
+			}
+			if polls.Add(1) == 1 {
+				w.WriteHeader(http.StatusPreconditionRequired)
+				_, _ = w.Write([]byte(`{"error":"authorization_pending"}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"access_token":"ya29.access","refresh_token":"1//refresh","expires_in":3599,"token_type":"Bearer"}`))
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	}))
+
+	g := NewGeminiAuth()
+	flow, err := g.InitiateDeviceFlow(context.Background(), client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if flow.UserCode != "ABCD-EFGH" || flow.VerificationURL != "https://www.google.com/device" {
+		t.Fatalf("flow = %+v", flow)
+	}
+	token, err := g.PollForToken(context.Background(), client, flow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AccessToken != "ya29.access" || token.RefreshToken != "1//refresh" || polls.Load() != 2 {
+		t.Errorf("token = %+v after %d polls", token, polls.Load())
+	}
+}
+
+func TestPollForToken_Denied(t *testing.T) {
+	client := testClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"error":"access_denied"}`))
+	}))
+	_, err := NewGeminiAuth().PollForToken(context.Background(), client, &DeviceFlow{DeviceCode: "dev-1", ExpiresIn: 60, Interval: 1})
+	if err == nil || !strings.Contains(err.Error(), "denied") {
+		t.Errorf("err = %v, want access denied", err)
+	}
+}
@@ -24,6 +24,12 @@ type GeminiTokenStorage struct {
 	Type      string `json:"type"`
 }
 
+// MarshalToken returns the JSON SaveTokenToFile writes.
+func (ts *GeminiTokenStorage) MarshalToken() ([]byte, error) {
+	ts.Type = "gemini"
+	return json.Marshal(ts)
+}
+
 // SaveTokenToFile serializes the Gemini token storage to a JSON file.
 // This method creates the necessary directory structure and writes the token
 // data in JSON format to the specified file path for persistent storage.
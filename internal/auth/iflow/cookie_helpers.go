@@ -1,10 +1,28 @@
 package iflow
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"net/http"
 	"strings"
 )
 
+// ErrCookieExpired reports that the iFlow platform no longer accepts the
+// account's session cookie; the account must log in with a fresh cookie.
+var ErrCookieExpired = errors.New("iflow cookie expired")
+
+// cookieRejected reports whether an API key endpoint response means the
+// session cookie was not accepted: an auth status, or the HTML login page
+// served in place of JSON.
+func cookieRejected(status int, body []byte) bool {
+	if status == http.StatusUnauthorized || status == http.StatusForbidden {
+		return true
+	}
+	trimmed := bytes.TrimSpace(body)
+	return status == http.StatusOK && len(trimmed) > 0 && trimmed[0] == '<'
+}
+
 // NormalizeCookie normalizes raw cookie strings for iFlow authentication flows.
 func NormalizeCookie(raw string) (string, error) {
 	trimmed := strings.TrimSpace(raw)
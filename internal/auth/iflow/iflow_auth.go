@@ -366,6 +366,9 @@ func (ia *IFlowAuth) fetchAPIKeyInfo(ctx context.Context, cookie string) (*iFlow
 		return nil, fmt.Errorf("iflow cookie: read GET response failed: %w", err)
 	}
 
+	if cookieRejected(resp.StatusCode, body) {
+		return nil, fmt.Errorf("iflow cookie: %w (status %d)", ErrCookieExpired, resp.StatusCode)
+	}
 	if resp.StatusCode != http.StatusOK {
 		log.Debugf("iflow cookie GET request failed: status=%d body=%s", resp.StatusCode, string(body))
 		return nil, fmt.Errorf("iflow cookie: GET request failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
@@ -445,6 +448,9 @@ func (ia *IFlowAuth) RefreshAPIKey(ctx context.Context, cookie, name string) (*i
 		return nil, fmt.Errorf("iflow cookie refresh: read POST response failed: %w", err)
 	}
 
+	if cookieRejected(resp.StatusCode, body) {
+		return nil, fmt.Errorf("iflow cookie refresh: %w (status %d)", ErrCookieExpired, resp.StatusCode)
+	}
 	if resp.StatusCode != http.StatusOK {
 		log.Debugf("iflow cookie POST request failed: status=%d body=%s", resp.StatusCode, string(body))
 		return nil, fmt.Errorf("iflow cookie refresh: POST request failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
@@ -23,6 +23,12 @@ type IFlowTokenStorage struct {
 	Type         string `json:"type"`
 }
 
+// MarshalToken returns the JSON SaveTokenToFile writes.
+func (ts *IFlowTokenStorage) MarshalToken() ([]byte, error) {
+	ts.Type = "iflow"
+	return json.Marshal(ts)
+}
+
 // SaveTokenToFile serialises the token storage to disk.
 func (ts *IFlowTokenStorage) SaveTokenToFile(authFilePath string) error {
 	misc.LogSavingCredentials(authFilePath)
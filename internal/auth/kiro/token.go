@@ -19,6 +19,11 @@ func NewKiroTokenStorage(creds *KiroCredentials) *KiroTokenStorage {
 	}
 }
 
+// MarshalToken returns the JSON SaveTokenToFile writes.
+func (s *KiroTokenStorage) MarshalToken() ([]byte, error) {
+	return json.MarshalIndent(s.KiroCredentials, "", "  ")
+}
+
 // SaveTokenToFile persists the Kiro credentials to the specified file path.
 func (s *KiroTokenStorage) SaveTokenToFile(authFilePath string) error {
 	if authFilePath == "" {
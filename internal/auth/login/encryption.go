@@ -0,0 +1,275 @@
+package login
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/nghyane/llm-mux/internal/json"
+	"github.com/nghyane/llm-mux/internal/persist"
+)
+
+const (
+	// TokenKeyEnv holds the passphrase used to encrypt token files at rest.
+	TokenKeyEnv = "LLM_MUX_TOKEN_KEY"
+	// TokenKeyFileEnv points to a file (e.g. a mounted secret) containing the passphrase.
+	TokenKeyFileEnv = "LLM_MUX_TOKEN_KEY_FILE"
+
+	encryptedEnvelopeVersion = "v1"
+	encryptedEnvelopeMarker  = `"llm_mux_encrypted"`
+	tokenKDFIterations       = 600_000
+	tokenSaltSize            = 16
+)
+
+var (
+	// ErrTokenKeyRequired is returned when an encrypted token file is read without a key.
+	ErrTokenKeyRequired = errors.New("token file is encrypted but no encryption key is configured")
+	// ErrTokenDecrypt is returned when an encrypted token file cannot be decrypted with the configured key.
+	ErrTokenDecrypt = errors.New("token file could not be decrypted with the configured key")
+)
+
+// encryptedEnvelope is the on-disk JSON form of an encrypted token file.
+type encryptedEnvelope struct {
+	Version    string `json:"llm_mux_encrypted"`
+	Iterations int    `json:"iterations"`
+	Salt       string `json:"salt"`
+	Nonce      string `json:"nonce"`
+	Data       string `json:"data"`
+}
+
+// tokenCipher derives AES-256-GCM keys from a passphrase. Keys are cached per
+// salt so each file written by this process costs a single derivation.
+type tokenCipher struct {
+	passphrase []byte
+	salt       []byte
+
+	mu   sync.Mutex
+	keys map[string][]byte
+}
+
+var activeTokenCipher atomic.Pointer[tokenCipher]
+
+// ConfigureTokenEncryption enables at-rest encryption of token files with the
+// given passphrase. An empty passphrase disables encryption.
+func ConfigureTokenEncryption(passphrase string) error {
+	passphrase = strings.TrimSpace(passphrase)
+	if passphrase == "" {
+		activeTokenCipher.Store(nil)
+		return nil
+	}
+	c, err := newTokenCipher(passphrase)
+	if err != nil {
+		return err
+	}
+	activeTokenCipher.Store(c)
+	return nil
+}
+
+// ConfigureTokenEncryptionFromEnv loads the passphrase from TokenKeyEnv, or
+// from the file named by keyFile or TokenKeyFileEnv, and enables encryption
+// when one is found. It returns whether encryption is enabled.
+func ConfigureTokenEncryptionFromEnv(keyFile string) (bool, error) {
+	passphrase := os.Getenv(TokenKeyEnv)
+	if passphrase == "" {
+		if keyFile == "" {
+			keyFile = os.Getenv(TokenKeyFileEnv)
+		}
+		if keyFile = strings.TrimSpace(keyFile); keyFile != "" {
+			data, err := os.ReadFile(keyFile)
+			if err != nil {
+				return false, fmt.Errorf("token encryption: read key file: %w", err)
+			}
+			passphrase = string(data)
+			if strings.TrimSpace(passphrase) == "" {
+				return false, fmt.Errorf("token encryption: key file %s is empty", keyFile)
+			}
+		}
+	}
+	if err := ConfigureTokenEncryption(passphrase); err != nil {
+		return false, err
+	}
+	return TokenEncryptionEnabled(), nil
+}
+
+// TokenEncryptionEnabled reports whether token files are encrypted on write.
+func TokenEncryptionEnabled() bool {
+	return activeTokenCipher.Load() != nil
+}
+
+// IsEncryptedTokenData reports whether data is an encrypted token envelope.
+func IsEncryptedTokenData(data []byte) bool {
+	if !bytes.Contains(data, []byte(encryptedEnvelopeMarker)) {
+		return false
+	}
+	var env encryptedEnvelope
+	return json.Unmarshal(data, &env) == nil && env.Version != ""
+}
+
+// EncryptTokenData encrypts plaintext token JSON when encryption is enabled;
+// otherwise it returns plain unchanged.
+func EncryptTokenData(plain []byte) ([]byte, error) {
+	c := activeTokenCipher.Load()
+	if c == nil {
+		return plain, nil
+	}
+	return c.seal(plain)
+}
+
+// DecryptTokenData returns the plaintext of a token file. Plaintext input is
+// returned as-is with encrypted=false. Encrypted input without a configured
+// key, or with the wrong key, fails rather than falling back.
+func DecryptTokenData(data []byte) (plain []byte, encrypted bool, err error) {
+	if !IsEncryptedTokenData(data) {
+		return data, false, nil
+	}
+	c := activeTokenCipher.Load()
+	if c == nil {
+		return nil, true, ErrTokenKeyRequired
+	}
+	plain, err = c.open(data)
+	return plain, true, err
+}
+
+// SealWithPassphrase encrypts plain with a one-off passphrase, independent of
+// the configured token key. The result uses the same envelope as token files.
+func SealWithPassphrase(plain []byte, passphrase string) ([]byte, error) {
+	c, err := newTokenCipher(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return c.seal(plain)
+}
+
+// OpenWithPassphrase decrypts an envelope produced by SealWithPassphrase.
+func OpenWithPassphrase(data []byte, passphrase string) ([]byte, error) {
+	c, err := newTokenCipher(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return c.open(data)
+}
+
+// ReadTokenFile reads and, when needed, decrypts a token file.
+func ReadTokenFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	plain, _, err := DecryptTokenData(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return plain, nil
+}
+
+// writeTokenFile writes plain to path, encrypting it when enabled, via a
+// temporary file and rename so readers never observe a partial file.
+func writeTokenFile(path string, plain []byte) error {
+	stored, err := EncryptTokenData(plain)
+	if err != nil {
+		return err
+	}
+	if err = persist.WriteFile(path, stored); err != nil {
+		return fmt.Errorf("auth filestore: write failed: %w", err)
+	}
+	return nil
+}
+
+func newTokenCipher(passphrase string) (*tokenCipher, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("token encryption: passphrase is empty")
+	}
+	salt := make([]byte, tokenSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("token encryption: generate salt: %w", err)
+	}
+	return &tokenCipher{passphrase: []byte(passphrase), salt: salt, keys: make(map[string][]byte)}, nil
+}
+
+func (c *tokenCipher) seal(plain []byte) ([]byte, error) {
+	key, err := c.key(c.salt, tokenKDFIterations)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("token encryption: generate nonce: %w", err)
+	}
+	env := encryptedEnvelope{
+		Version:    encryptedEnvelopeVersion,
+		Iterations: tokenKDFIterations,
+		Salt:       base64.StdEncoding.EncodeToString(c.salt),
+		Nonce:      base64.StdEncoding.EncodeToString(nonce),
+		Data:       base64.StdEncoding.EncodeToString(aead.Seal(nil, nonce, plain, nil)),
+	}
+	return json.Marshal(env)
+}
+
+func (c *tokenCipher) open(data []byte) ([]byte, error) {
+	var env encryptedEnvelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTokenDecrypt, err)
+	}
+	if env.Version != encryptedEnvelopeVersion {
+		return nil, fmt.Errorf("%w: unsupported envelope version %q", ErrTokenDecrypt, env.Version)
+	}
+	salt, errSalt := base64.StdEncoding.DecodeString(env.Salt)
+	nonce, errNonce := base64.StdEncoding.DecodeString(env.Nonce)
+	sealed, errData := base64.StdEncoding.DecodeString(env.Data)
+	if errSalt != nil || errNonce != nil || errData != nil || env.Iterations <= 0 {
+		return nil, fmt.Errorf("%w: malformed envelope", ErrTokenDecrypt)
+	}
+	key, err := c.key(salt, env.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("%w: malformed nonce", ErrTokenDecrypt)
+	}
+	plain, err := aead.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return nil, ErrTokenDecrypt
+	}
+	return plain, nil
+}
+
+func (c *tokenCipher) key(salt []byte, iterations int) ([]byte, error) {
+	cacheKey := fmt.Sprintf("%x:%d", salt, iterations)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if key, ok := c.keys[cacheKey]; ok {
+		return key, nil
+	}
+	key, err := pbkdf2.Key(sha256.New, string(c.passphrase), salt, iterations, 32)
+	if err != nil {
+		return nil, fmt.Errorf("token encryption: derive key: %w", err)
+	}
+	c.keys[cacheKey] = key
+	return key, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("token encryption: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
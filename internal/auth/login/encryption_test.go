@@ -0,0 +1,120 @@
+package login
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+func TestTokenEncryptionRoundTrip(t *testing.T) {
+	t.Cleanup(func() { _ = ConfigureTokenEncryption("") })
+	if err := ConfigureTokenEncryption("secret"); err != nil {
+		t.Fatal(err)
+	}
+	plain := []byte(`{"type":"claude","access_token":"abc"}`)
+	sealed, err := EncryptTokenData(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncryptedTokenData(sealed) {
+		t.Fatalf("expected encrypted envelope, got %s", sealed)
+	}
+	got, encrypted, err := DecryptTokenData(sealed)
+	if err != nil || !encrypted || string(got) != string(plain) {
+		t.Fatalf("decrypt = %q, %v, %v", got, encrypted, err)
+	}
+
+	_ = ConfigureTokenEncryption("other")
+	if _, _, err = DecryptTokenData(sealed); !errors.Is(err, ErrTokenDecrypt) {
+		t.Fatalf("wrong key: got %v, want ErrTokenDecrypt", err)
+	}
+	_ = ConfigureTokenEncryption("")
+	if _, _, err = DecryptTokenData(sealed); !errors.Is(err, ErrTokenKeyRequired) {
+		t.Fatalf("no key: got %v, want ErrTokenKeyRequired", err)
+	}
+}
+
+func TestFileTokenStoreMigratesAndFailsClosed(t *testing.T) {
+	t.Cleanup(func() { _ = ConfigureTokenEncryption("") })
+	dir := t.TempDir()
+	plainPath := filepath.Join(dir, "plain.json")
+	if err := os.WriteFile(plainPath, []byte(`{"type":"codex","email":"a@example.com"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ConfigureTokenEncryption("secret"); err != nil {
+		t.Fatal(err)
+	}
+	store := NewFileTokenStore()
+	store.SetBaseDir(dir)
+	auths, err := store.List(context.Background())
+	if err != nil || len(auths) != 1 || auths[0].Provider != "codex" {
+		t.Fatalf("list = %+v, %v", auths, err)
+	}
+	raw, _ := os.ReadFile(plainPath)
+	if !IsEncryptedTokenData(raw) {
+		t.Fatalf("plaintext file was not migrated: %s", raw)
+	}
+
+	saved := &provider.Auth{ID: "saved.json", Provider: "claude", Metadata: map[string]any{"type": "claude"}}
+	if _, err = store.Save(context.Background(), saved); err != nil {
+		t.Fatal(err)
+	}
+	if raw, _ = os.ReadFile(filepath.Join(dir, "saved.json")); !IsEncryptedTokenData(raw) {
+		t.Fatalf("saved file is not encrypted: %s", raw)
+	}
+
+	_ = ConfigureTokenEncryption("wrong")
+	if auths, err = store.List(context.Background()); err != nil || len(auths) != 0 {
+		t.Fatalf("wrong key should skip files, got %d auths, %v", len(auths), err)
+	}
+}
+
+// fileOnlyStorage can only write its tokens to a file.
+type fileOnlyStorage struct{ t *testing.T }
+
+func (s fileOnlyStorage) SaveTokenToFile(path string) error {
+	return os.WriteFile(path, []byte(`{"type":"claude","access_token":"plain-secret"}`), 0o600)
+}
+
+// memoryStorage encodes its tokens in memory and must never write a file.
+type memoryStorage struct{ fileOnlyStorage }
+
+func (s memoryStorage) SaveTokenToFile(string) error {
+	s.t.Error("SaveTokenToFile called with encryption enabled")
+	return nil
+}
+
+func (memoryStorage) MarshalToken() ([]byte, error) {
+	return []byte(`{"type":"claude","access_token":"plain-secret"}`), nil
+}
+
+func TestFileTokenStoreEncryptsStorageInMemory(t *testing.T) {
+	t.Cleanup(func() { _ = ConfigureTokenEncryption("") })
+	if err := ConfigureTokenEncryption("secret"); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	store := NewFileTokenStore()
+	store.SetBaseDir(dir)
+
+	saved := &provider.Auth{ID: "memory.json", Provider: "claude", Storage: memoryStorage{fileOnlyStorage{t}}}
+	if _, err := store.Save(context.Background(), saved); err != nil {
+		t.Fatal(err)
+	}
+	if raw, _ := os.ReadFile(filepath.Join(dir, "memory.json")); !IsEncryptedTokenData(raw) {
+		t.Fatalf("saved file is not encrypted: %s", raw)
+	}
+
+	fileOnly := &provider.Auth{ID: "file-only.json", Provider: "claude", Storage: fileOnlyStorage{t}}
+	if _, err := store.Save(context.Background(), fileOnly); err == nil {
+		t.Fatal("a storage that only writes plaintext files was saved with encryption enabled")
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("token dir holds %d files, want only the encrypted one", len(entries))
+	}
+}
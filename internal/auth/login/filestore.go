@@ -2,6 +2,7 @@ package login
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io/fs"
 	"os"
@@ -10,7 +11,10 @@ import (
 	"sync"
 	"time"
 
+	baseauth "github.com/nghyane/llm-mux/internal/auth"
 	"github.com/nghyane/llm-mux/internal/json"
+	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/misc"
 	"github.com/nghyane/llm-mux/internal/provider"
 )
 
@@ -58,7 +62,7 @@ func (s *FileTokenStore) Save(ctx context.Context, auth *provider.Auth) (string,
 
 	switch {
 	case auth.Storage != nil:
-		if err = auth.Storage.SaveTokenToFile(path); err != nil {
+		if err = s.saveStorage(auth, path); err != nil {
 			return "", err
 		}
 	case auth.Metadata != nil:
@@ -67,18 +71,17 @@ func (s *FileTokenStore) Save(ctx context.Context, auth *provider.Auth) (string,
 			return "", fmt.Errorf("auth filestore: marshal metadata failed: %w", errMarshal)
 		}
 		if existing, errRead := os.ReadFile(path); errRead == nil {
-			if jsonEqual(existing, raw) {
+			// Skip rewriting unchanged content, unless the file still needs
+			// migrating from plaintext to the encrypted form.
+			plain, encrypted, errDecrypt := DecryptTokenData(existing)
+			if errDecrypt == nil && encrypted == TokenEncryptionEnabled() && jsonEqual(plain, raw) {
 				return path, nil
 			}
 		} else if !os.IsNotExist(errRead) {
 			return "", fmt.Errorf("auth filestore: read existing failed: %w", errRead)
 		}
-		tmp := path + ".tmp"
-		if errWrite := os.WriteFile(tmp, raw, 0o600); errWrite != nil {
-			return "", fmt.Errorf("auth filestore: write temp failed: %w", errWrite)
-		}
-		if errRename := os.Rename(tmp, path); errRename != nil {
-			return "", fmt.Errorf("auth filestore: rename failed: %w", errRename)
+		if errWrite := writeTokenFile(path, raw); errWrite != nil {
+			return "", errWrite
 		}
 	default:
 		return "", fmt.Errorf("auth filestore: nothing to persist for %s", auth.ID)
@@ -96,6 +99,24 @@ func (s *FileTokenStore) Save(ctx context.Context, auth *provider.Auth) (string,
 	return path, nil
 }
 
+// saveStorage persists token storage to path. Storages that encode in memory
+// go through writeTokenFile, so with encryption enabled only ciphertext is
+// ever written; others can only be saved unencrypted.
+func (s *FileTokenStore) saveStorage(auth *provider.Auth, path string) error {
+	if m, ok := auth.Storage.(baseauth.TokenMarshaler); ok {
+		raw, err := m.MarshalToken()
+		if err != nil {
+			return fmt.Errorf("auth filestore: marshal storage failed: %w", err)
+		}
+		misc.LogSavingCredentials(path)
+		return writeTokenFile(path, raw)
+	}
+	if TokenEncryptionEnabled() {
+		return fmt.Errorf("auth filestore: %T cannot be encrypted", auth.Storage)
+	}
+	return auth.Storage.SaveTokenToFile(path)
+}
+
 func (s *FileTokenStore) List(ctx context.Context) ([]*provider.Auth, error) {
 	dir := s.baseDirSnapshot()
 	if dir == "" {
@@ -114,6 +135,9 @@ func (s *FileTokenStore) List(ctx context.Context) ([]*provider.Auth, error) {
 		}
 		auth, err := s.readAuthFile(path, dir)
 		if err != nil {
+			if errors.Is(err, ErrTokenKeyRequired) || errors.Is(err, ErrTokenDecrypt) {
+				log.Errorf("auth filestore: skipping %s: %v", path, err)
+			}
 			return nil
 		}
 		if auth != nil {
@@ -161,6 +185,10 @@ func (s *FileTokenStore) readAuthFile(path, baseDir string) (*provider.Auth, err
 	if len(data) == 0 {
 		return nil, nil
 	}
+	data, encrypted, err := DecryptTokenData(data)
+	if err != nil {
+		return nil, err
+	}
 	metadata := make(map[string]any)
 	if err = json.Unmarshal(data, &metadata); err != nil {
 		return nil, fmt.Errorf("unmarshal auth json: %w", err)
@@ -179,6 +207,7 @@ func (s *FileTokenStore) readAuthFile(path, baseDir string) (*provider.Auth, err
 		Provider:         providerName,
 		FileName:         id,
 		Label:            s.labelFor(metadata),
+		Labels:           provider.LabelsFromMetadata(metadata),
 		Status:           provider.StatusActive,
 		Attributes:       map[string]string{"path": path},
 		Metadata:         metadata,
@@ -190,6 +219,15 @@ func (s *FileTokenStore) readAuthFile(path, baseDir string) (*provider.Auth, err
 	if email, ok := metadata["email"].(string); ok && email != "" {
 		auth.Attributes["email"] = email
 	}
+	if !encrypted && TokenEncryptionEnabled() {
+		s.mu.Lock()
+		if errMigrate := writeTokenFile(path, data); errMigrate != nil {
+			log.Warnf("auth filestore: failed to encrypt plaintext token file %s: %v", path, errMigrate)
+		} else {
+			log.Infof("auth filestore: encrypted plaintext token file %s", path)
+		}
+		s.mu.Unlock()
+	}
 	return auth, nil
 }
 
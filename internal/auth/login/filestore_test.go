@@ -0,0 +1,19 @@
+package login
+
+import (
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/store/storetest"
+)
+
+func TestFileTokenStoreConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) storetest.Opener {
+		dir := t.TempDir()
+		return func(*testing.T) provider.Store {
+			s := NewFileTokenStore()
+			s.SetBaseDir(dir)
+			return s
+		}
+	})
+}
@@ -400,7 +400,7 @@ func scanExistingKiroTokens(authDir string) map[string]bool {
 		}
 
 		fullPath := filepath.Join(authDir, name)
-		content, err := os.ReadFile(fullPath)
+		content, err := ReadTokenFile(fullPath)
 		if err != nil {
 			continue
 		}
@@ -60,6 +60,9 @@ func (m *Manager) Login(ctx context.Context, providerName string, cfg *config.Co
 		if dirSetter, ok := m.store.(interface{ SetBaseDir(string) }); ok {
 			dirSetter.SetBaseDir(cfg.AuthDir)
 		}
+		if _, err = ConfigureTokenEncryptionFromEnv(cfg.TokenKeyFile); err != nil {
+			return record, "", err
+		}
 	}
 
 	savedPath, err := m.store.Save(ctx, record)
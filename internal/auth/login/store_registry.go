@@ -31,3 +31,20 @@ func GetTokenStore() provider.Store {
 	}
 	return registeredStore
 }
+
+var registeredLocker provider.RefreshLocker
+
+// RegisterRefreshLocker sets the lock instances hold while refreshing an
+// account, for token stores that cannot lock by themselves.
+func RegisterRefreshLocker(locker provider.RefreshLocker) {
+	storeMu.Lock()
+	registeredLocker = locker
+	storeMu.Unlock()
+}
+
+// GetRefreshLocker returns the registered refresh lock, or nil.
+func GetRefreshLocker() provider.RefreshLocker {
+	storeMu.RLock()
+	defer storeMu.RUnlock()
+	return registeredLocker
+}
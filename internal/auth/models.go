@@ -15,3 +15,10 @@ type TokenStorage interface {
 	//   - error: An error if the save operation fails, nil otherwise
 	SaveTokenToFile(authFilePath string) error
 }
+
+// TokenMarshaler is implemented by TokenStorage types that can encode their
+// tokens in memory, in the same JSON form SaveTokenToFile writes. Stores use it
+// to encrypt tokens before anything reaches disk.
+type TokenMarshaler interface {
+	MarshalToken() ([]byte, error)
+}
@@ -23,6 +23,12 @@ type QwenTokenStorage struct {
 	Expire       string `json:"expired"`
 }
 
+// MarshalToken returns the JSON SaveTokenToFile writes.
+func (ts *QwenTokenStorage) MarshalToken() ([]byte, error) {
+	ts.Type = "qwen"
+	return json.Marshal(ts)
+}
+
 // SaveTokenToFile serializes the Qwen token storage to a JSON file.
 // This method creates the necessary directory structure and writes the token
 // data in JSON format to the specified file path for persistent storage.
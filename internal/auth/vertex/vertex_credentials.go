@@ -32,6 +32,18 @@ type VertexCredentialStorage struct {
 	Type string `json:"type"`
 }
 
+// MarshalToken returns the JSON SaveTokenToFile writes.
+func (s *VertexCredentialStorage) MarshalToken() ([]byte, error) {
+	if s == nil {
+		return nil, fmt.Errorf("vertex credential: storage is nil")
+	}
+	if s.ServiceAccount == nil {
+		return nil, fmt.Errorf("vertex credential: service account content is empty")
+	}
+	s.Type = "vertex"
+	return json.MarshalIndent(s, "", "  ")
+}
+
 // SaveTokenToFile writes the credential payload to the given file path in JSON format.
 // It ensures the parent directory exists and logs the operation for transparency.
 func (s *VertexCredentialStorage) SaveTokenToFile(authFilePath string) error {
@@ -31,6 +31,12 @@ func StartService(cfg *config.Config, configPath string, localPassword string) {
 
 	ctxSignal, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
+	go func() {
+		// Restore default signal handling once shutdown starts so a second
+		// SIGINT/SIGTERM terminates immediately instead of waiting for the drain.
+		<-ctxSignal.Done()
+		cancel()
+	}()
 
 	runCtx := ctxSignal
 	if localPassword != "" {
@@ -0,0 +1,35 @@
+package config
+
+import "fmt"
+
+// Compression configures compressed client request and response bodies.
+type Compression struct {
+	// MaxDecompressedBytes caps request bodies sent with Content-Encoding
+	// gzip, br or deflate once decoded. Defaults to the endpoint's request
+	// body limit, or 50 MiB when that limit is disabled.
+	MaxDecompressedBytes int64 `yaml:"max-decompressed-bytes,omitempty" json:"max-decompressed-bytes,omitempty"`
+	// Concurrency bounds how many request bodies are decoded at once; further
+	// compressed requests wait for a slot. Zero is unlimited.
+	Concurrency int `yaml:"concurrency,omitempty" json:"concurrency,omitempty"`
+	// Responses compresses non-streaming responses for clients that send a
+	// matching Accept-Encoding.
+	Responses bool `yaml:"responses,omitempty" json:"responses,omitempty"`
+	// Streams gzip-compresses SSE and NDJSON streams for clients that accept
+	// gzip. The encoder is flushed with every chunk so events are not held
+	// back.
+	Streams bool `yaml:"streams,omitempty" json:"streams,omitempty"`
+}
+
+// ValidateCompression rejects negative compression limits.
+func (cfg *Config) ValidateCompression() error {
+	if cfg == nil {
+		return nil
+	}
+	if cfg.Compression.MaxDecompressedBytes < 0 {
+		return fmt.Errorf("compression.max-decompressed-bytes must not be negative")
+	}
+	if cfg.Compression.Concurrency < 0 {
+		return fmt.Errorf("compression.concurrency must not be negative")
+	}
+	return nil
+}
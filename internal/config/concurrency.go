@@ -0,0 +1,59 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ProviderConcurrency caps how many requests each account of a provider
+// serves at once. Requests arriving while every account is at its cap are
+// rejected with 503, or wait in a bounded queue when one is configured.
+type ProviderConcurrency struct {
+	// MaxPerAccount is how many requests one account serves at once. Zero
+	// leaves the provider unlimited.
+	MaxPerAccount int `yaml:"max-per-account,omitempty" json:"max-per-account,omitempty"`
+	// QueueDepth is how many requests may wait for a free account. Zero
+	// rejects requests as soon as every account is busy.
+	QueueDepth int `yaml:"queue-depth,omitempty" json:"queue-depth,omitempty"`
+	// QueueWait is how long, in seconds, a queued request waits before it is
+	// rejected.
+	QueueWait int `yaml:"queue-wait,omitempty" json:"queue-wait,omitempty"`
+}
+
+// QueueWaitDuration returns QueueWait as a duration.
+func (c ProviderConcurrency) QueueWaitDuration() time.Duration {
+	return time.Duration(c.QueueWait) * time.Second
+}
+
+// ValidateConcurrency rejects negative values and queues without the cap or
+// wait they need.
+func (cfg *Config) ValidateConcurrency() error {
+	if cfg == nil {
+		return nil
+	}
+	for name, c := range cfg.Concurrency {
+		if c.MaxPerAccount < 0 || c.QueueDepth < 0 || c.QueueWait < 0 {
+			return fmt.Errorf("provider-concurrency.%s: values must not be negative", name)
+		}
+		if c.QueueDepth > 0 && (c.MaxPerAccount == 0 || c.QueueWait == 0) {
+			return fmt.Errorf("provider-concurrency.%s: queue-depth requires max-per-account and queue-wait", name)
+		}
+	}
+	return nil
+}
+
+// ProviderConcurrency returns the concurrency settings of provider. Providers
+// without an entry are unlimited.
+func (cfg *Config) ProviderConcurrency(provider string) ProviderConcurrency {
+	if cfg == nil {
+		return ProviderConcurrency{}
+	}
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	for name, c := range cfg.Concurrency {
+		if strings.ToLower(strings.TrimSpace(name)) == provider {
+			return c
+		}
+	}
+	return ProviderConcurrency{}
+}
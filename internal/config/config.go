@@ -9,8 +9,12 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/nghyane/llm-mux/internal/translator/ir"
 	"gopkg.in/yaml.v3"
@@ -34,6 +38,74 @@ type SDKConfig struct {
 	// ShowProviderPrefixes enables visual provider prefixes in model IDs (e.g., "[Gemini CLI] gemini-2.5-pro").
 	// This is purely cosmetic and does not affect actual model routing to providers.
 	ShowProviderPrefixes bool `yaml:"show-provider-prefixes" json:"show-provider-prefixes"`
+
+	// ChoicesFanOut serves OpenAI chat requests with n > 1 on providers without
+	// native support by making one upstream call per missing choice. Off by
+	// default because it multiplies cost; such requests are rejected instead.
+	ChoicesFanOut bool `yaml:"choices-fan-out,omitempty" json:"choices-fan-out,omitempty"`
+
+	// StreamKeepAlive is the number of idle seconds after which SSE streams
+	// receive a ": keep-alive" comment line. Zero disables keep-alives.
+	StreamKeepAlive int `yaml:"stream-keep-alive,omitempty" json:"stream-keep-alive,omitempty"`
+
+	// SalvagePartialStreams ends a chat completion stream that fails after
+	// sending content with a finish_reason "length" chunk carrying the
+	// content and the error, instead of an error event.
+	SalvagePartialStreams bool `yaml:"salvage-partial-streams,omitempty" json:"salvage-partial-streams,omitempty"`
+
+	// StrictSafetyBlocks turns provider safety blocks into a 400 error instead
+	// of a content_filter response.
+	StrictSafetyBlocks bool `yaml:"strict-safety-blocks,omitempty" json:"strict-safety-blocks,omitempty"`
+
+	// RepairToolArguments fixes malformed JSON in the tool-call arguments of
+	// translated non-streaming responses. Off by default so clients receive
+	// the arguments exactly as the model produced them.
+	RepairToolArguments bool `yaml:"repair-tool-arguments,omitempty" json:"repair-tool-arguments,omitempty"`
+
+	// RequestDedup collapses concurrent identical non-streaming requests from
+	// the same client into one upstream call whose response they all share.
+	RequestDedup bool `yaml:"request-dedup,omitempty" json:"request-dedup,omitempty"`
+
+	// IdempotencyTTL is the number of seconds the response to a non-streaming
+	// request carrying an Idempotency-Key header is kept and replayed to
+	// retries with the same key. Zero disables idempotency keys.
+	IdempotencyTTL int `yaml:"idempotency-ttl,omitempty" json:"idempotency-ttl,omitempty"`
+
+	// WarningsInBody copies the X-LLM-Mux-Warnings of a non-streaming JSON
+	// response into its llm_mux_warnings field, for clients that cannot read
+	// response headers.
+	WarningsInBody bool `yaml:"warnings-in-body,omitempty" json:"warnings-in-body,omitempty"`
+
+	// RoutingOverride lets clients pin a request to a provider or account
+	// with the X-LLM-Mux-Provider and X-LLM-Mux-Account headers, bypassing
+	// normal selection. Off by default because it overrides routing policy.
+	RoutingOverride bool `yaml:"routing-override,omitempty" json:"routing-override,omitempty"`
+
+	// RoutingOverrideKeys lists the client API keys allowed to send the
+	// routing override headers. Other keys are refused even while
+	// RoutingOverride is on.
+	RoutingOverrideKeys []string `yaml:"routing-override-keys,omitempty" json:"routing-override-keys,omitempty"`
+
+	// MaxRequestTimeout caps, in seconds, the per-request timeout clients may
+	// set with the X-LLM-Mux-Timeout header. Defaults to 600.
+	MaxRequestTimeout int `yaml:"max-request-timeout,omitempty" json:"max-request-timeout,omitempty"`
+
+	// APIKeyLabels restricts client API keys to accounts carrying given
+	// labels, isolating teams that share one instance.
+	APIKeyLabels []APIKeyLabels `yaml:"api-key-labels,omitempty" json:"api-key-labels,omitempty"`
+
+	// Moderation selects the backend that serves /v1/moderations.
+	Moderation ModerationConfig `yaml:"moderation,omitempty" json:"moderation,omitempty"`
+}
+
+// ModerationConfig selects the provider that serves /v1/moderations.
+type ModerationConfig struct {
+	// Provider is the provider key of an OpenAI-compatible provider with a
+	// moderation API. Empty disables the endpoint.
+	Provider string `yaml:"provider,omitempty" json:"provider,omitempty"`
+
+	// Model is used when the request does not name one.
+	Model string `yaml:"model,omitempty" json:"model,omitempty"`
 }
 
 // AccessConfig groups request authentication providers.
@@ -115,6 +187,73 @@ type Config struct {
 	MaxRetryInterval       int              `yaml:"max-retry-interval" json:"max-retry-interval"`
 	QuotaExceeded          QuotaExceeded    `yaml:"quota-exceeded" json:"quota-exceeded"`
 
+	// RetryBudget caps retries to a share of recent requests so outages do not cause retry storms.
+	RetryBudget RetryBudget `yaml:"retry-budget,omitempty" json:"retry-budget,omitempty"`
+
+	// DailyQuota caps per-account daily usage and rests exhausted accounts until the reset.
+	DailyQuota DailyQuota `yaml:"daily-quota,omitempty" json:"daily-quota,omitempty"`
+
+	// Selection is the account selection strategy: round-robin (default) or
+	// quota, which prefers the account furthest from its daily limit.
+	Selection string `yaml:"selection,omitempty" json:"selection,omitempty"`
+
+	// Preflight validates account credentials at startup.
+	Preflight Preflight `yaml:"preflight,omitempty" json:"preflight,omitempty"`
+
+	// ModelDiscovery fills the model registry from provider list-models endpoints.
+	ModelDiscovery ModelDiscovery `yaml:"model-discovery,omitempty" json:"model-discovery,omitempty"`
+
+	// ForwardRequestID forwards the X-Request-ID of each request to upstream providers.
+	ForwardRequestID bool `yaml:"forward-request-id,omitempty" json:"forward-request-id,omitempty"`
+
+	// DisableResponseLengthCheck stops failing non-streaming upstream
+	// responses that end before their Content-Length as retryable errors.
+	DisableResponseLengthCheck bool `yaml:"disable-response-length-check,omitempty" json:"disable-response-length-check,omitempty"`
+
+	// FaultInjection breaks upstream responses on purpose for client
+	// resilience tests. Never enable it in production.
+	FaultInjection FaultInjection `yaml:"fault-injection,omitempty" json:"fault-injection,omitempty"`
+
+	// TokenKeyFile names a file holding the passphrase used to encrypt token
+	// files in AuthDir. The LLM_MUX_TOKEN_KEY environment variable takes precedence.
+	TokenKeyFile string `yaml:"token-key-file,omitempty" json:"-"`
+
+	// LogRedaction extends the header and field names scrubbed from request logs.
+	LogRedaction LogRedaction `yaml:"log-redaction,omitempty" json:"log-redaction,omitempty"`
+
+	// LogSampling captures full request and response bodies for a fraction of requests.
+	LogSampling LogSampling `yaml:"log-sampling,omitempty" json:"log-sampling,omitempty"`
+
+	// Timeouts sets outbound HTTP timeouts per provider; the "default" entry
+	// applies to providers without their own.
+	Timeouts map[string]ProviderTimeouts `yaml:"timeouts,omitempty" json:"timeouts,omitempty"`
+
+	// Concurrency caps concurrent requests per account for each provider and
+	// optionally queues requests while every account is busy.
+	Concurrency map[string]ProviderConcurrency `yaml:"provider-concurrency,omitempty" json:"provider-concurrency,omitempty"`
+
+	// RetryableErrors lists, per provider, upstream responses to retry as
+	// transient failures regardless of their status.
+	RetryableErrors map[string][]RetryableError `yaml:"retryable-errors,omitempty" json:"retryable-errors,omitempty"`
+
+	// UpstreamHeaders adds headers to outbound provider requests per provider;
+	// the "default" entry applies to every provider.
+	UpstreamHeaders map[string]ProviderHeaders `yaml:"upstream-headers,omitempty" json:"upstream-headers,omitempty"`
+
+	// StreamBufferSize is how many chunks a stream buffers ahead of a slow
+	// client before upstream reads pause. Defaults to 32.
+	StreamBufferSize int `yaml:"stream-buffer-size,omitempty" json:"stream-buffer-size,omitempty"`
+
+	// RequestBodyLimit caps the size of client request bodies.
+	RequestBodyLimit RequestBodyLimit `yaml:"request-body-limit,omitempty" json:"request-body-limit,omitempty"`
+
+	// Compression configures compressed request and response bodies.
+	Compression Compression `yaml:"compression,omitempty" json:"compression,omitempty"`
+
+	// ShutdownDrainTimeout is how long, in seconds, shutdown waits for in-flight
+	// requests (including streams) before closing them. Defaults to 30.
+	ShutdownDrainTimeout int `yaml:"shutdown-drain-timeout,omitempty" json:"shutdown-drain-timeout,omitempty"`
+
 	WebsocketAuth bool `yaml:"ws-auth" json:"ws-auth"`
 	DisableAuth   bool `yaml:"disable-auth" json:"disable-auth"`
 
@@ -129,6 +268,15 @@ type Config struct {
 	Payload             PayloadConfig       `yaml:"payload" json:"payload"`
 	Routing             RoutingConfig       `yaml:"routing,omitempty" json:"routing,omitempty"`
 
+	// OAuthCallbackPorts moves the callback forwarder of providers that accept
+	// any loopback redirect port, keyed by provider; 0 picks a free port.
+	OAuthCallbackPorts map[string]int `yaml:"oauth-callback-ports,omitempty" json:"oauth-callback-ports,omitempty"`
+
+	// ModelFamilies defines canonical models served by ordered provider members.
+	// Entries override built-in families with the same canonical ID; families
+	// registered through the management API override both.
+	ModelFamilies map[string][]ModelFamilyMember `yaml:"model-families,omitempty" json:"model-families,omitempty"`
+
 	// UseCanonicalTranslator enables the unified IR translator architecture (default: true).
 	UseCanonicalTranslator bool `yaml:"use-canonical-translator" json:"use-canonical-translator" default:"true"`
 }
@@ -140,6 +288,117 @@ type TLSConfig struct {
 	Key    string `yaml:"key" json:"key"`
 }
 
+// LogRedaction lists extra names whose values are redacted from request logs,
+// in addition to the built-in defaults (Authorization, api_key, access_token, ...).
+type LogRedaction struct {
+	Headers []string `yaml:"headers,omitempty" json:"headers,omitempty"`
+	Fields  []string `yaml:"fields,omitempty" json:"fields,omitempty"`
+}
+
+// LogSampling configures full-body capture for debugging. Sampled bodies are
+// redacted and written to logs/samples, separate from the request logs.
+type LogSampling struct {
+	// Rate is the fraction of requests captured, between 0 and 1 (e.g. 0.01 for 1%).
+	Rate float64 `yaml:"rate,omitempty" json:"rate,omitempty"`
+	// Errors captures every request that finished with an error status.
+	Errors bool `yaml:"errors,omitempty" json:"errors,omitempty"`
+	// MaxBodyBytes truncates each captured body; defaults to 64 KiB.
+	MaxBodyBytes int `yaml:"max-body-bytes,omitempty" json:"max-body-bytes,omitempty"`
+	// StreamTee mirrors the streaming responses of sampled requests to a
+	// secondary sink while they are sent.
+	StreamTee StreamTee `yaml:"stream-tee,omitempty" json:"stream-tee,omitempty"`
+}
+
+// StreamTee mirrors sampled streams to a file, a webhook or both. Chunks the
+// sink cannot keep up with are dropped; the client stream is never slowed.
+type StreamTee struct {
+	// File writes each mirrored stream to logs/samples/streams.
+	File bool `yaml:"file,omitempty" json:"file,omitempty"`
+	// Webhook receives each mirrored stream as a JSON POST once it ends.
+	Webhook string `yaml:"webhook,omitempty" json:"webhook,omitempty"`
+	// BufferChunks is how many chunks per stream may wait for the sink.
+	// Defaults to 256.
+	BufferChunks int `yaml:"buffer-chunks,omitempty" json:"buffer-chunks,omitempty"`
+}
+
+// RequestBodyLimit caps client request bodies. Requests above the limit are
+// rejected with 413 before they reach a handler.
+type RequestBodyLimit struct {
+	// MaxBytes applies to text-only endpoints. Defaults to 10 MiB; a negative
+	// value disables the limit.
+	MaxBytes int64 `yaml:"max-bytes,omitempty" json:"max-bytes,omitempty"`
+	// MultimodalMaxBytes applies to endpoints that accept inline images, audio
+	// or documents. Defaults to 50 MiB; a negative value disables the limit.
+	MultimodalMaxBytes int64 `yaml:"multimodal-max-bytes,omitempty" json:"multimodal-max-bytes,omitempty"`
+}
+
+// Preflight configures the startup check of account credentials. Accounts with
+// a refresh token are refreshed; API-key accounts list the upstream's models.
+type Preflight struct {
+	// Enabled runs the check once the accounts are loaded.
+	Enabled bool `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+	// Concurrency bounds how many accounts are checked at once. Defaults to 4.
+	Concurrency int `yaml:"concurrency,omitempty" json:"concurrency,omitempty"`
+	// Timeout bounds the check of each account, in seconds. Defaults to 15.
+	Timeout int `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+	// RequiredProviders makes startup fail when none of a listed provider's
+	// accounts passes the check.
+	RequiredProviders []string `yaml:"required-providers,omitempty" json:"required-providers,omitempty"`
+}
+
+// ModelDiscovery lists the models of API-key accounts from their provider's
+// list-models endpoint and registers them alongside the built-in models.
+type ModelDiscovery struct {
+	// Enabled fetches the lists at startup and whenever an account changes.
+	Enabled bool `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+	// RefreshInterval is how often, in minutes, the lists are fetched again.
+	// Defaults to 60.
+	RefreshInterval int `yaml:"refresh-interval,omitempty" json:"refresh-interval,omitempty"`
+	// TTL is how long, in minutes, a discovered model stays registered after
+	// its provider last listed it. Defaults to 1440; negative never expires.
+	TTL int `yaml:"ttl,omitempty" json:"ttl,omitempty"`
+	// GracePeriod is how long, in minutes, a model missing from a newer list
+	// stays registered. Defaults to twice the refresh interval; negative drops
+	// it right away.
+	GracePeriod int `yaml:"grace-period,omitempty" json:"grace-period,omitempty"`
+}
+
+// DefaultModelDiscoveryInterval is used when model-discovery.refresh-interval is unset.
+const DefaultModelDiscoveryInterval = time.Hour
+
+// DefaultModelDiscoveryTTL is used when model-discovery.ttl is unset.
+const DefaultModelDiscoveryTTL = 24 * time.Hour
+
+// Interval returns the effective refresh interval.
+func (d ModelDiscovery) Interval() time.Duration {
+	if d.RefreshInterval <= 0 {
+		return DefaultModelDiscoveryInterval
+	}
+	return time.Duration(d.RefreshInterval) * time.Minute
+}
+
+// ModelTTL returns the effective TTL of discovered models; 0 disables expiry.
+func (d ModelDiscovery) ModelTTL() time.Duration {
+	switch {
+	case d.TTL < 0:
+		return 0
+	case d.TTL == 0:
+		return DefaultModelDiscoveryTTL
+	}
+	return time.Duration(d.TTL) * time.Minute
+}
+
+// Grace returns the effective grace period for models missing from a list.
+func (d ModelDiscovery) Grace() time.Duration {
+	switch {
+	case d.GracePeriod < 0:
+		return 0
+	case d.GracePeriod == 0:
+		return 2 * d.Interval()
+	}
+	return time.Duration(d.GracePeriod) * time.Minute
+}
+
 // RemoteManagement holds management API configuration under 'remote-management'.
 type RemoteManagement struct {
 	AllowRemote bool `yaml:"allow-remote"`
@@ -167,6 +426,25 @@ type UsagePersistence struct {
 
 	// RetentionDays defines how many days of records to keep before cleanup.
 	RetentionDays int `yaml:"retention-days" json:"retention-days"`
+
+	// CountersPath is the JSON file the rolling last-hour/last-day usage
+	// counters are saved to. Defaults to usage-counters.json next to DBPath.
+	CountersPath string `yaml:"counters-path,omitempty" json:"counters-path,omitempty"`
+}
+
+// UsageCountersPath returns the file the rolling usage counters are saved to,
+// or "" when usage persistence is disabled.
+func (u UsagePersistence) UsageCountersPath() string {
+	if !u.Enabled {
+		return ""
+	}
+	if u.CountersPath != "" {
+		return u.CountersPath
+	}
+	if u.DBPath == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(u.DBPath), "usage-counters.json")
 }
 
 // AmpModelMapping defines a model name mapping for Amp CLI requests.
@@ -193,6 +471,10 @@ type AmpCode struct {
 type PayloadConfig struct {
 	Default  []PayloadRule `yaml:"default" json:"default"`
 	Override []PayloadRule `yaml:"override" json:"override"`
+	// Precedence decides whether override rules replace values the client
+	// sent ("override", the default) or only fill values it left out
+	// ("client"). Defaults never replace client values.
+	Precedence string `yaml:"precedence,omitempty" json:"precedence,omitempty"`
 }
 
 // PayloadRule describes a single rule targeting a list of models with parameter updates.
@@ -201,6 +483,13 @@ type PayloadRule struct {
 	Params map[string]any     `yaml:"params" json:"params"`
 }
 
+// ModelFamilyMember is one provider's model inside a model family. Members are
+// tried in the order listed.
+type ModelFamilyMember struct {
+	Provider string `yaml:"provider" json:"provider"`
+	Model    string `yaml:"model" json:"model"`
+}
+
 // PayloadModelRule ties a model name pattern to a specific translator protocol.
 type PayloadModelRule struct {
 	Name     string `yaml:"name" json:"name"`
@@ -218,15 +507,34 @@ type RoutingConfig struct {
 
 	// Aliases maps user-facing model names to canonical internal names.
 	// Handles naming inconsistencies across providers (e.g., "." vs "-").
-	// Example: "claude-sonnet-4.5" -> "claude-sonnet-4-5"
+	// Aliases are consulted before model family resolution and may chain.
+	// A target of the form "provider://model" pins the request to that provider.
+	// Example: "claude-sonnet-4.5" -> "claude-sonnet-4-5", "gpt-4o" -> "claude://claude-sonnet-4-5-20250929"
 	Aliases map[string]string `yaml:"aliases,omitempty" json:"aliases,omitempty"`
 
+	// ListAliases adds aliases to the /v1/models listing.
+	ListAliases bool `yaml:"list-aliases,omitempty" json:"list-aliases,omitempty"`
+
+	// ListModels selects which model IDs /v1/models advertises:
+	// "provider" (default) lists provider-specific IDs, "canonical" lists
+	// model family IDs in place of their members, "both" lists both.
+	ListModels string `yaml:"list-models,omitempty" json:"list-models,omitempty"`
+
+	// PrefixModelIDs lists provider-specific IDs in /v1/models as
+	// "provider/model", once per provider serving the model. Requests for a
+	// prefixed ID are routed to that provider only, whether or not listing
+	// is enabled.
+	PrefixModelIDs bool `yaml:"prefix-model-ids,omitempty" json:"prefix-model-ids,omitempty"`
+
 	// Fallbacks defines ordered fallback chains when a model is unavailable.
 	// Supports tier downgrades and cross-vendor fallbacks.
 	// Example: "claude-opus-4-5" -> ["claude-sonnet-4-5", "gpt-4o"]
 	Fallbacks map[string][]string `yaml:"fallbacks,omitempty" json:"fallbacks,omitempty"`
 
-	hasAliases   bool
+	// UnknownModels routes requests for models that no provider lists. When
+	// unset, such requests are rejected with 400.
+	UnknownModels UnknownModelRoute `yaml:"unknown-models,omitempty" json:"unknown-models,omitempty"`
+
 	hasFallbacks bool
 	hasPriority  bool
 }
@@ -235,21 +543,157 @@ func (r *RoutingConfig) Init() {
 	if r == nil {
 		return
 	}
-	r.hasAliases = len(r.Aliases) > 0
 	r.hasFallbacks = len(r.Fallbacks) > 0
 	r.hasPriority = len(r.ProviderPriority) > 0
 }
 
-// ResolveModelAlias returns the canonical model name for the given input.
-// If no alias is defined, returns the original model name.
+// maxAliasDepth bounds alias chains so a misconfigured cycle cannot loop forever.
+const maxAliasDepth = 8
+
+// aliasMu guards the Aliases field of every RoutingConfig. Alias maps are
+// copy-on-write: a published map is never modified, so readers hold the lock
+// only while loading it.
+var aliasMu sync.RWMutex
+
+// AliasMap returns the current aliases. The map must not be modified.
+func (r *RoutingConfig) AliasMap() map[string]string {
+	if r == nil {
+		return nil
+	}
+	aliasMu.RLock()
+	defer aliasMu.RUnlock()
+	return r.Aliases
+}
+
+// SetAliases replaces the aliases with the given map after checking it for
+// cycles. The caller must not modify the map afterwards.
+func (r *RoutingConfig) SetAliases(aliases map[string]string) error {
+	if err := validateAliasMap(aliases); err != nil {
+		return err
+	}
+	aliasMu.Lock()
+	r.Aliases = aliases
+	aliasMu.Unlock()
+	return nil
+}
+
+// ResolveModelAlias returns the canonical model name for the given input,
+// following chained aliases. If no alias is defined, or the chain is cyclic,
+// returns the original model name.
 func (r *RoutingConfig) ResolveModelAlias(model string) string {
-	if r == nil || !r.hasAliases {
+	aliases := r.AliasMap()
+	if len(aliases) == 0 {
+		return model
+	}
+	resolved, err := resolveAliasChain(aliases, model)
+	if err != nil {
 		return model
 	}
-	if alias, ok := r.Aliases[model]; ok {
-		return alias
+	return resolved
+}
+
+// ValidateAliases reports the first alias cycle found, if any.
+func (r *RoutingConfig) ValidateAliases() error {
+	return validateAliasMap(r.AliasMap())
+}
+
+func validateAliasMap(aliases map[string]string) error {
+	keys := make([]string, 0, len(aliases))
+	for k := range aliases {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if _, err := resolveAliasChain(aliases, k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Values accepted by routing.list-models.
+const (
+	ModelListingProvider  = "provider"
+	ModelListingCanonical = "canonical"
+	ModelListingBoth      = "both"
+)
+
+// ModelListing returns the configured list-models mode, defaulting to
+// ModelListingProvider.
+func (r *RoutingConfig) ModelListing() string {
+	if r == nil || r.ListModels == "" {
+		return ModelListingProvider
+	}
+	return strings.ToLower(r.ListModels)
+}
+
+// ValidateModelListing rejects unknown list-models values.
+func (r *RoutingConfig) ValidateModelListing() error {
+	switch r.ModelListing() {
+	case ModelListingProvider, ModelListingCanonical, ModelListingBoth:
+		return nil
+	}
+	return fmt.Errorf("routing.list-models must be %q, %q or %q, got %q", ModelListingProvider, ModelListingCanonical, ModelListingBoth, r.ListModels)
+}
+
+// UnknownModelRoute is the catch-all route for unknown model names.
+type UnknownModelRoute struct {
+	// Provider serves unknown models, e.g. an OpenAI-compatible provider such
+	// as openrouter.
+	Provider string `yaml:"provider,omitempty" json:"provider,omitempty"`
+	// Model replaces the requested name; empty passes it through unchanged.
+	Model string `yaml:"model,omitempty" json:"model,omitempty"`
+}
+
+// CatchAll returns the provider and model that serve the unknown model,
+// or false when no catch-all provider is configured.
+func (r *RoutingConfig) CatchAll(model string) (providerName, target string, ok bool) {
+	if r == nil {
+		return "", "", false
+	}
+	providerName = strings.ToLower(strings.TrimSpace(r.UnknownModels.Provider))
+	if providerName == "" {
+		return "", "", false
+	}
+	if target = strings.TrimSpace(r.UnknownModels.Model); target == "" {
+		target = model
+	}
+	return providerName, target, true
+}
+
+// ValidateUnknownModels rejects a catch-all model without a provider.
+func (r *RoutingConfig) ValidateUnknownModels() error {
+	if r == nil {
+		return nil
+	}
+	if strings.TrimSpace(r.UnknownModels.Provider) == "" && strings.TrimSpace(r.UnknownModels.Model) != "" {
+		return fmt.Errorf("routing.unknown-models.model %q requires routing.unknown-models.provider", r.UnknownModels.Model)
+	}
+	return nil
+}
+
+func resolveAliasChain(aliases map[string]string, model string) (string, error) {
+	current := model
+	for depth := 0; depth <= maxAliasDepth; depth++ {
+		next, ok := aliases[current]
+		if !ok || next == current {
+			return current, nil
+		}
+		if next == model {
+			return "", fmt.Errorf("model alias cycle detected starting at %q", model)
+		}
+		current = next
+	}
+	return "", fmt.Errorf("model alias chain for %q exceeds %d hops", model, maxAliasDepth)
+}
+
+// SplitAliasTarget splits a "provider://model" alias target.
+func SplitAliasTarget(target string) (providerName, model string, ok bool) {
+	parts := strings.SplitN(target, "://", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
 	}
-	return model
+	return strings.ToLower(parts[0]), parts[1], true
 }
 
 // GetFallbackChain returns the fallback models for the given model.
@@ -266,6 +710,44 @@ func (r *RoutingConfig) HasProviderPriority() bool {
 	return r != nil && r.hasPriority
 }
 
+// DefaultShutdownDrainTimeout is used when shutdown-drain-timeout is unset.
+const DefaultShutdownDrainTimeout = 30 * time.Second
+
+// DrainTimeout returns the effective shutdown drain timeout.
+func (cfg *Config) DrainTimeout() time.Duration {
+	if cfg == nil || cfg.ShutdownDrainTimeout <= 0 {
+		return DefaultShutdownDrainTimeout
+	}
+	return time.Duration(cfg.ShutdownDrainTimeout) * time.Second
+}
+
+// Default request body limits, used when request-body-limit leaves them unset.
+const (
+	DefaultMaxRequestBodyBytes           int64 = 10 << 20
+	DefaultMultimodalMaxRequestBodyBytes int64 = 50 << 20
+)
+
+// BodyLimits returns the effective request body limits for text-only and
+// multimodal endpoints. Zero means unlimited.
+func (cfg *Config) BodyLimits() (standard, multimodal int64) {
+	standard, multimodal = DefaultMaxRequestBodyBytes, DefaultMultimodalMaxRequestBodyBytes
+	if cfg == nil {
+		return standard, multimodal
+	}
+	return effectiveBodyLimit(cfg.RequestBodyLimit.MaxBytes, standard), effectiveBodyLimit(cfg.RequestBodyLimit.MultimodalMaxBytes, multimodal)
+}
+
+func effectiveBodyLimit(configured, fallback int64) int64 {
+	switch {
+	case configured < 0:
+		return 0
+	case configured == 0:
+		return fallback
+	default:
+		return configured
+	}
+}
+
 // NewDefaultConfig creates a new Config with sensible defaults.
 // This allows the server to run without a config file using OAuth credentials only.
 func NewDefaultConfig() *Config {
@@ -355,6 +837,90 @@ func LoadConfigOptional(configFile string, optional bool) (*Config, error) {
 	cfg.OAuthExcludedModels = NormalizeOAuthExcludedModels(cfg.OAuthExcludedModels)
 
 	cfg.Routing.Init()
+	if err = cfg.Routing.ValidateAliases(); err != nil {
+		if optional {
+			return NewDefaultConfig(), nil
+		}
+		return nil, fmt.Errorf("invalid routing aliases: %w", err)
+	}
+	if err = cfg.Routing.ValidateModelListing(); err != nil {
+		if optional {
+			return NewDefaultConfig(), nil
+		}
+		return nil, err
+	}
+	if err = cfg.Routing.ValidateUnknownModels(); err != nil {
+		if optional {
+			return NewDefaultConfig(), nil
+		}
+		return nil, err
+	}
+	if err = cfg.ValidateTimeouts(); err != nil {
+		if optional {
+			return NewDefaultConfig(), nil
+		}
+		return nil, err
+	}
+	if err = cfg.ValidateConcurrency(); err != nil {
+		if optional {
+			return NewDefaultConfig(), nil
+		}
+		return nil, err
+	}
+	if err = cfg.ValidateRetryableErrors(); err != nil {
+		if optional {
+			return NewDefaultConfig(), nil
+		}
+		return nil, err
+	}
+	if err = cfg.ValidateCompression(); err != nil {
+		if optional {
+			return NewDefaultConfig(), nil
+		}
+		return nil, err
+	}
+	if err = cfg.ValidateOAuthCallbackPorts(); err != nil {
+		if optional {
+			return NewDefaultConfig(), nil
+		}
+		return nil, err
+	}
+	if err = cfg.ValidateUpstreamHeaders(); err != nil {
+		if optional {
+			return NewDefaultConfig(), nil
+		}
+		return nil, err
+	}
+	if err = cfg.ValidateDailyQuota(); err != nil {
+		if optional {
+			return NewDefaultConfig(), nil
+		}
+		return nil, err
+	}
+	if err = cfg.ValidateSelection(); err != nil {
+		if optional {
+			return NewDefaultConfig(), nil
+		}
+		return nil, err
+	}
+	if err = cfg.ValidatePayload(); err != nil {
+		if optional {
+			return NewDefaultConfig(), nil
+		}
+		return nil, err
+	}
+	if err = cfg.ValidateProxyURLs(); err != nil {
+		if optional {
+			return NewDefaultConfig(), nil
+		}
+		return nil, err
+	}
+	if err = cfg.ValidateAPIKeyLabels(); err != nil {
+		if optional {
+			return NewDefaultConfig(), nil
+		}
+		return nil, err
+	}
 
 	// Return the populated configuration struct.
 	return &cfg, nil
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DailyQuota caps how much each account is used per day. Accounts that reach
+// their limit are skipped by selection until the quota day resets.
+type DailyQuota struct {
+	// ResetHour is the hour of day (0-23) the counters reset, in Timezone.
+	ResetHour int `yaml:"reset-hour,omitempty" json:"reset-hour,omitempty"`
+	// Timezone is the IANA time zone ResetHour is read in. Defaults to UTC.
+	Timezone string `yaml:"timezone,omitempty" json:"timezone,omitempty"`
+	// StatePath is the JSON file the counters are saved to so restarts keep
+	// them. Defaults to daily-quota.json in the credentials directory.
+	StatePath string `yaml:"state-path,omitempty" json:"state-path,omitempty"`
+	// Limits lists the daily limits. An entry naming an account overrides
+	// the entry for its provider.
+	Limits []DailyQuotaLimit `yaml:"limits,omitempty" json:"limits,omitempty"`
+}
+
+// DailyQuotaLimit is the daily limit for one provider or account. Zero
+// values are unlimited.
+type DailyQuotaLimit struct {
+	// Provider is the provider the limit applies to, e.g. "gemini-cli".
+	Provider string `yaml:"provider,omitempty" json:"provider,omitempty"`
+	// Account narrows the limit to one account, matched against its auth ID,
+	// file name or label (usually the email).
+	Account string `yaml:"account,omitempty" json:"account,omitempty"`
+	// Requests is the number of requests allowed per day.
+	Requests int64 `yaml:"requests,omitempty" json:"requests,omitempty"`
+	// Tokens is the number of tokens allowed per day.
+	Tokens int64 `yaml:"tokens,omitempty" json:"tokens,omitempty"`
+}
+
+// Enabled reports whether any daily limit is configured.
+func (q DailyQuota) Enabled() bool { return len(q.Limits) > 0 }
+
+// Location returns the time zone the reset hour is read in.
+func (q DailyQuota) Location() *time.Location {
+	if q.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(q.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// CountersPath returns the file the daily counters are saved to, or "" when
+// no location is available.
+func (q DailyQuota) CountersPath() string {
+	if q.StatePath != "" {
+		return q.StatePath
+	}
+	dir := CredentialsDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "daily-quota.json")
+}
+
+// LimitFor returns the requests and tokens allowed per day for an account of
+// provider identified by any of names (auth ID, file name, label).
+func (q DailyQuota) LimitFor(provider string, names ...string) (requests, tokens int64) {
+	var providerLimit *DailyQuotaLimit
+	for i := range q.Limits {
+		l := &q.Limits[i]
+		if l.Provider != "" && !strings.EqualFold(l.Provider, provider) {
+			continue
+		}
+		if l.Account == "" {
+			if providerLimit == nil {
+				providerLimit = l
+			}
+			continue
+		}
+		for _, name := range names {
+			if name != "" && (name == l.Account || filepath.Base(name) == l.Account) {
+				return l.Requests, l.Tokens
+			}
+		}
+	}
+	if providerLimit != nil {
+		return providerLimit.Requests, providerLimit.Tokens
+	}
+	return 0, 0
+}
+
+// ValidateDailyQuota rejects a reset hour outside 0-23, an unknown time zone,
+// negative limits and limits naming neither a provider nor an account.
+func (cfg *Config) ValidateDailyQuota() error {
+	if cfg == nil {
+		return nil
+	}
+	q := cfg.DailyQuota
+	if q.ResetHour < 0 || q.ResetHour > 23 {
+		return fmt.Errorf("daily-quota.reset-hour: %d is not between 0 and 23", q.ResetHour)
+	}
+	if q.Timezone != "" {
+		if _, err := time.LoadLocation(q.Timezone); err != nil {
+			return fmt.Errorf("daily-quota.timezone: %w", err)
+		}
+	}
+	for i, l := range q.Limits {
+		if l.Provider == "" && l.Account == "" {
+			return fmt.Errorf("daily-quota.limits[%d]: provider or account is required", i)
+		}
+		if l.Requests < 0 || l.Tokens < 0 {
+			return fmt.Errorf("daily-quota.limits[%d]: values must not be negative", i)
+		}
+	}
+	return nil
+}
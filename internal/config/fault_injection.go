@@ -0,0 +1,30 @@
+package config
+
+import "time"
+
+// FaultInjection deliberately breaks upstream responses so clients can test
+// their retry and stream error handling. It is for test deployments only and
+// is off by default. The fault fields apply to every request; with debug
+// enabled, requests can also set them with X-LLM-Mux-Fault-* headers.
+type FaultInjection struct {
+	// Enabled turns fault injection on.
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// Status fails every call with this HTTP status.
+	Status int `yaml:"status,omitempty" json:"status,omitempty"`
+	// DropAfter ends streams with an error after this many chunks.
+	DropAfter int `yaml:"drop-after,omitempty" json:"drop-after,omitempty"`
+	// MalformedAt replaces the stream chunk at this 1-based position with
+	// invalid JSON.
+	MalformedAt int `yaml:"malformed-at,omitempty" json:"malformed-at,omitempty"`
+	// ChunkDelayMs delays every stream chunk, and non-streaming responses,
+	// by this many milliseconds.
+	ChunkDelayMs int `yaml:"chunk-delay-ms,omitempty" json:"chunk-delay-ms,omitempty"`
+}
+
+// ChunkDelay returns the configured per-chunk delay.
+func (f FaultInjection) ChunkDelay() time.Duration {
+	if f.ChunkDelayMs <= 0 {
+		return 0
+	}
+	return time.Duration(f.ChunkDelayMs) * time.Millisecond
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+)
+
+// APIKeyLabels limits one client API key to the accounts carrying every
+// label in Labels.
+type APIKeyLabels struct {
+	APIKey string            `yaml:"api-key" json:"api-key"`
+	Labels map[string]string `yaml:"labels" json:"labels"`
+}
+
+// LabelSelector returns the labels an account needs to serve apiKey, or nil
+// when the key may use every account.
+func (c *SDKConfig) LabelSelector(apiKey string) map[string]string {
+	if c == nil || apiKey == "" {
+		return nil
+	}
+	for _, entry := range c.APIKeyLabels {
+		if entry.APIKey == apiKey {
+			return entry.Labels
+		}
+	}
+	return nil
+}
+
+// ValidateAPIKeyLabels rejects api-key-labels entries without a key or
+// labels, and keys listed twice.
+func (c *SDKConfig) ValidateAPIKeyLabels() error {
+	seen := make(map[string]struct{}, len(c.APIKeyLabels))
+	for i, entry := range c.APIKeyLabels {
+		if strings.TrimSpace(entry.APIKey) == "" {
+			return fmt.Errorf("api-key-labels[%d]: api-key is required", i)
+		}
+		if len(entry.Labels) == 0 {
+			return fmt.Errorf("api-key-labels[%d]: labels must not be empty", i)
+		}
+		if _, dup := seen[entry.APIKey]; dup {
+			return fmt.Errorf("api-key-labels[%d]: api-key listed more than once", i)
+		}
+		seen[entry.APIKey] = struct{}{}
+	}
+	return nil
+}
+
+// AccountLabels returns the labels of the account created for key: the
+// provider's labels overlaid with the key's own.
+func (p *Provider) AccountLabels(key ProviderAPIKey) map[string]string {
+	if len(p.Labels) == 0 && len(key.Labels) == 0 {
+		return nil
+	}
+	labels := make(map[string]string, len(p.Labels)+len(key.Labels))
+	maps.Copy(labels, p.Labels)
+	maps.Copy(labels, key.Labels)
+	return labels
+}
@@ -0,0 +1,33 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadConfigModelFamilies(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := []byte(`
+model-families:
+  my-coder:
+    - provider: qwen
+      model: qwen3-coder-plus
+    - provider: iflow
+      model: qwen3-coder-plus
+`)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfigOptional(path, false)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	members := cfg.ModelFamilies["my-coder"]
+	if len(members) != 2 {
+		t.Fatalf("expected 2 members, got %+v", members)
+	}
+	if members[0].Provider != "qwen" || members[1].Provider != "iflow" || members[1].Model != "qwen3-coder-plus" {
+		t.Fatalf("unexpected members: %+v", members)
+	}
+}
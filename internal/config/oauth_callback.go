@@ -0,0 +1,30 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CallbackPort returns the configured callback forwarder port for provider.
+// ok is false when the provider has no entry; a port of 0 asks for an
+// ephemeral port.
+func (cfg *Config) CallbackPort(provider string) (port int, ok bool) {
+	if cfg == nil {
+		return 0, false
+	}
+	port, ok = cfg.OAuthCallbackPorts[strings.ToLower(provider)]
+	return port, ok
+}
+
+// ValidateOAuthCallbackPorts rejects callback ports outside the TCP range.
+func (cfg *Config) ValidateOAuthCallbackPorts() error {
+	if cfg == nil {
+		return nil
+	}
+	for name, port := range cfg.OAuthCallbackPorts {
+		if port < 0 || port > 65535 {
+			return fmt.Errorf("oauth-callback-ports.%s: port %d out of range", name, port)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Payload rule precedence modes.
+const (
+	// PayloadPrecedenceOverride lets override rules replace client values:
+	// override > client > default.
+	PayloadPrecedenceOverride = "override"
+	// PayloadPrecedenceClient keeps every value the client sent; override
+	// rules only fill what it left out: client > override > default.
+	PayloadPrecedenceClient = "client"
+)
+
+// ClientFirst reports whether client values take precedence over override
+// rules.
+func (p PayloadConfig) ClientFirst() bool {
+	return strings.EqualFold(strings.TrimSpace(p.Precedence), PayloadPrecedenceClient)
+}
+
+// ValidatePayload rejects an unknown payload precedence.
+func (cfg *Config) ValidatePayload() error {
+	if cfg == nil {
+		return nil
+	}
+	switch strings.ToLower(strings.TrimSpace(cfg.Payload.Precedence)) {
+	case "", PayloadPrecedenceOverride, PayloadPrecedenceClient:
+		return nil
+	default:
+		return fmt.Errorf("payload.precedence: unknown value %q (want %s or %s)", cfg.Payload.Precedence, PayloadPrecedenceOverride, PayloadPrecedenceClient)
+	}
+}
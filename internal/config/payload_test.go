@@ -0,0 +1,14 @@
+package config
+
+import "testing"
+
+func TestValidatePayloadPrecedence(t *testing.T) {
+	for _, p := range []string{"", "override", "Client"} {
+		if err := (&Config{Payload: PayloadConfig{Precedence: p}}).ValidatePayload(); err != nil {
+			t.Errorf("precedence %q: %v", p, err)
+		}
+	}
+	if err := (&Config{Payload: PayloadConfig{Precedence: "default"}}).ValidatePayload(); err == nil {
+		t.Error("unknown precedence accepted")
+	}
+}
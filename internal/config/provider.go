@@ -1,6 +1,9 @@
 package config
 
-import "strings"
+import (
+	"net/url"
+	"strings"
+)
 
 // ProviderType defines the type of API provider.
 type ProviderType string
@@ -17,13 +20,26 @@ const (
 
 	// ProviderTypeVertexCompat uses Vertex AI-compatible endpoints (zenmux, etc.).
 	ProviderTypeVertexCompat ProviderType = "vertex-compat"
+
+	// ProviderTypeCohere uses Cohere's chat, embed and rerank APIs.
+	ProviderTypeCohere ProviderType = "cohere"
+
+	// ProviderTypeVertex uses Vertex AI Gemini endpoints with service account
+	// credentials instead of an API key.
+	ProviderTypeVertex ProviderType = "vertex"
+
+	// ProviderTypeXAI uses xAI's OpenAI-compatible Grok API.
+	ProviderTypeXAI ProviderType = "xai"
+
+	// ProviderTypeMistral uses Mistral La Plateforme's chat API.
+	ProviderTypeMistral ProviderType = "mistral"
 )
 
 // Provider represents a unified API provider configuration.
 // This replaces the legacy gemini-api-key, claude-api-key, codex-api-key,
 // openai-compatibility, and vertex-api-key configurations.
 type Provider struct {
-	// Type specifies the provider type (gemini, anthropic, openai, vertex-compat).
+	// Type specifies the provider type (gemini, anthropic, openai, vertex-compat, cohere, vertex, xai, mistral).
 	Type ProviderType `yaml:"type" json:"type"`
 
 	// Name is a display name for this provider instance.
@@ -44,7 +60,7 @@ type Provider struct {
 
 	// BaseURL is the API endpoint URL.
 	// Required for: openai, vertex-compat
-	// Optional for: gemini, anthropic (uses default if not set)
+	// Optional for: gemini, anthropic, cohere, xai, mistral (uses default if not set)
 	BaseURL string `yaml:"base-url,omitempty" json:"base-url,omitempty"`
 
 	// ProxyURL sets a proxy for this provider's requests.
@@ -55,13 +71,48 @@ type Provider struct {
 
 	// Models defines available models for this provider.
 	// Required for: openai, vertex-compat
-	// Optional for: gemini, anthropic (uses built-in registry if not set)
+	// Optional for: gemini, anthropic, cohere, xai, mistral (uses built-in registry if not set)
 	Models []ProviderModel `yaml:"models,omitempty" json:"models,omitempty"`
 
 	// ExcludedModels lists model names to exclude from this provider.
 	ExcludedModels []string `yaml:"excluded-models,omitempty" json:"excluded-models,omitempty"`
+
+	// UserAgent is sent as the User-Agent of this provider's upstream
+	// requests. Default: llm-mux/<version>. Providers that require a specific
+	// client string keep their own.
+	UserAgent string `yaml:"user-agent,omitempty" json:"user-agent,omitempty"`
+
+	// StreamMode restricts the provider to one upstream mode when it cannot
+	// serve both: "stream" buffers its stream for non-streaming requests,
+	// "non-stream" chunks its responses for streaming requests. Default: both.
+	StreamMode string `yaml:"stream-mode,omitempty" json:"stream-mode,omitempty"`
+
+	// Labels tag every account of this provider, e.g. tier: premium. Client
+	// keys listed in api-key-labels only use accounts carrying their labels.
+	Labels map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
+
+	// Project is the Google Cloud project ID. Required for: vertex
+	Project string `yaml:"project,omitempty" json:"project,omitempty"`
+
+	// Location is the Vertex AI region. Default: us-central1
+	Location string `yaml:"location,omitempty" json:"location,omitempty"`
+
+	// Locations are further Vertex AI regions to fail over to, in order, when
+	// a region throttles or fails. When Location is empty the first entry is
+	// the primary region.
+	Locations []string `yaml:"locations,omitempty" json:"locations,omitempty"`
+
+	// CredentialsFile is the path to a service account JSON key for vertex.
+	// When empty, Application Default Credentials are used.
+	CredentialsFile string `yaml:"credentials-file,omitempty" json:"credentials-file,omitempty"`
 }
 
+// Values of Provider.StreamMode.
+const (
+	StreamModeStream    = "stream"
+	StreamModeNonStream = "non-stream"
+)
+
 // ProviderAPIKey represents an API key with optional per-key settings.
 type ProviderAPIKey struct {
 	// Key is the API key value.
@@ -69,6 +120,10 @@ type ProviderAPIKey struct {
 
 	// ProxyURL overrides the provider's proxy for this key.
 	ProxyURL string `yaml:"proxy-url,omitempty" json:"proxy-url,omitempty"`
+
+	// Labels are added to the provider's labels for this key's account,
+	// replacing provider labels of the same name.
+	Labels map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
 }
 
 // ProviderModel defines a model available from this provider.
@@ -79,6 +134,10 @@ type ProviderModel struct {
 	// Alias is an optional alternative name for this model.
 	// If set, both Name and Alias can be used to reference this model.
 	Alias string `yaml:"alias,omitempty" json:"alias,omitempty"`
+
+	// Type marks non-chat models. "rerank" models are served through
+	// /v1/rerank and left out of model listings. Default: chat.
+	Type string `yaml:"type,omitempty" json:"type,omitempty"`
 }
 
 // IsEnabled returns true if the provider is enabled (default: true).
@@ -116,6 +175,25 @@ func (p *Provider) Validate() error {
 		return &ProviderValidationError{Field: "type", Message: "type is required"}
 	}
 
+	if p.BaseURL != "" {
+		if err := ValidateBaseURL(p.BaseURL); err != nil {
+			return err
+		}
+	}
+
+	switch p.StreamMode {
+	case "", StreamModeStream, StreamModeNonStream:
+	default:
+		return &ProviderValidationError{Field: "stream-mode", Message: "stream-mode must be " + StreamModeStream + " or " + StreamModeNonStream}
+	}
+
+	if p.Type == ProviderTypeVertex {
+		if p.Project == "" {
+			return &ProviderValidationError{Field: "project", Message: "project is required for vertex"}
+		}
+		return nil
+	}
+
 	// Check API key
 	if p.APIKey == "" && len(p.APIKeys) == 0 {
 		return &ProviderValidationError{Field: "api-key", Message: "api-key or api-keys is required"}
@@ -135,6 +213,22 @@ func (p *Provider) Validate() error {
 	return nil
 }
 
+// ValidateBaseURL checks that raw is an absolute http or https URL, the only
+// form accepted for a provider base URL override.
+func ValidateBaseURL(raw string) error {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return &ProviderValidationError{Field: "base-url", Message: err.Error()}
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return &ProviderValidationError{Field: "base-url", Message: "scheme must be http or https"}
+	}
+	if u.Host == "" {
+		return &ProviderValidationError{Field: "base-url", Message: "host is required"}
+	}
+	return nil
+}
+
 // ProviderValidationError represents a validation error for provider config.
 type ProviderValidationError struct {
 	Field   string
@@ -169,6 +263,10 @@ func SanitizeProviders(providers []Provider) []Provider {
 		p.BaseURL = strings.TrimRight(strings.TrimSpace(p.BaseURL), "/")
 		p.ProxyURL = strings.TrimSpace(p.ProxyURL)
 		p.Headers = NormalizeHeaders(p.Headers)
+		p.Project = strings.TrimSpace(p.Project)
+		p.Location = strings.TrimSpace(p.Location)
+		p.UserAgent = strings.TrimSpace(p.UserAgent)
+		p.CredentialsFile = strings.TrimSpace(p.CredentialsFile)
 
 		// Normalize API keys
 		validKeys := make([]ProviderAPIKey, 0, len(p.APIKeys))
@@ -197,8 +295,8 @@ func SanitizeProviders(providers []Provider) []Provider {
 			continue
 		}
 
-		// Deduplicate by type+name+baseurl
-		uniqueKey := string(p.Type) + "|" + p.Name + "|" + p.BaseURL
+		// Deduplicate by type+name+baseurl+project
+		uniqueKey := string(p.Type) + "|" + p.Name + "|" + p.BaseURL + "|" + p.Project
 		if _, exists := seen[uniqueKey]; exists {
 			continue
 		}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ValidateProxyURL checks that raw is an http, https or socks5 proxy URL with
+// a host; socks5h is accepted as an alias of socks5. Errors never echo raw,
+// which may carry proxy credentials.
+func ValidateProxyURL(raw string) error {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return fmt.Errorf("proxy URL is not a valid URL")
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return fmt.Errorf("proxy URL scheme must be http, https or socks5, got %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("proxy URL has no host")
+	}
+	return nil
+}
+
+// ValidateProxyURLs rejects malformed global, provider and per-key proxy URLs
+// so a typo fails the config load instead of sending traffic direct.
+func (cfg *Config) ValidateProxyURLs() error {
+	if cfg == nil {
+		return nil
+	}
+	if cfg.ProxyURL != "" {
+		if err := ValidateProxyURL(cfg.ProxyURL); err != nil {
+			return fmt.Errorf("proxy-url: %w", err)
+		}
+	}
+	for i := range cfg.Providers {
+		p := &cfg.Providers[i]
+		if p.ProxyURL != "" {
+			if err := ValidateProxyURL(p.ProxyURL); err != nil {
+				return fmt.Errorf("providers[%d] (%s).proxy-url: %w", i, p.GetDisplayName(), err)
+			}
+		}
+		for j, k := range p.APIKeys {
+			if k.ProxyURL == "" {
+				continue
+			}
+			if err := ValidateProxyURL(k.ProxyURL); err != nil {
+				return fmt.Errorf("providers[%d] (%s).api-keys[%d].proxy-url: %w", i, p.GetDisplayName(), j, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package config
+
+import "time"
+
+// RetryBudget limits retries to a share of recent requests, so that during
+// an outage retries taper off instead of multiplying the load on a failing
+// upstream. The budget is tracked both across all providers and for each
+// provider; a retry needs room in both.
+type RetryBudget struct {
+	// Ratio is the number of retries allowed per request over the window,
+	// e.g. 0.2 for one retry per five requests. Zero disables the budget.
+	Ratio float64 `yaml:"ratio,omitempty" json:"ratio,omitempty"`
+	// MinRetries is the number of retries allowed per window regardless of
+	// the ratio, so low traffic can still retry. Defaults to 10.
+	MinRetries int `yaml:"min-retries,omitempty" json:"min-retries,omitempty"`
+	// Window is the length, in seconds, of the sliding window requests and
+	// retries are counted over. Defaults to 10.
+	Window int `yaml:"window,omitempty" json:"window,omitempty"`
+}
+
+// DefaultRetryBudgetMinRetries is used when retry-budget.min-retries is unset.
+const DefaultRetryBudgetMinRetries = 10
+
+// DefaultRetryBudgetWindow is used when retry-budget.window is unset.
+const DefaultRetryBudgetWindow = 10 * time.Second
+
+// Enabled reports whether a retry budget is configured.
+func (b RetryBudget) Enabled() bool { return b.Ratio > 0 }
+
+// Floor returns the effective number of retries always allowed per window.
+func (b RetryBudget) Floor() int {
+	if b.MinRetries <= 0 {
+		return DefaultRetryBudgetMinRetries
+	}
+	return b.MinRetries
+}
+
+// WindowDuration returns the effective sliding window.
+func (b RetryBudget) WindowDuration() time.Duration {
+	if b.Window <= 0 {
+		return DefaultRetryBudgetWindow
+	}
+	return time.Duration(b.Window) * time.Second
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// RetryableError matches an upstream response that should be retried even
+// though its status does not say so, such as a 400 reading "model overloaded"
+// or a 200 whose body is an error. Every field that is set must match.
+type RetryableError struct {
+	// Status is the HTTP status to match. Zero matches any status.
+	Status int `yaml:"status,omitempty" json:"status,omitempty"`
+	// Body is a substring of the response body, matched case-insensitively.
+	Body string `yaml:"body,omitempty" json:"body,omitempty"`
+	// BodyRegex is a regular expression matched against the response body.
+	BodyRegex string `yaml:"body-regex,omitempty" json:"body-regex,omitempty"`
+}
+
+// Pattern compiles BodyRegex, returning nil when it is empty.
+func (r RetryableError) Pattern() (*regexp.Regexp, error) {
+	if r.BodyRegex == "" {
+		return nil, nil
+	}
+	return regexp.Compile(r.BodyRegex)
+}
+
+// ValidateRetryableErrors rejects matchers that match nothing in particular,
+// statuses outside 100-599 and regular expressions that do not compile.
+func (cfg *Config) ValidateRetryableErrors() error {
+	if cfg == nil {
+		return nil
+	}
+	for name, rules := range cfg.RetryableErrors {
+		for i, r := range rules {
+			if r.Status == 0 && r.Body == "" && r.BodyRegex == "" {
+				return fmt.Errorf("retryable-errors.%s[%d]: set status, body or body-regex", name, i)
+			}
+			if r.Status != 0 && (r.Status < 100 || r.Status > 599) {
+				return fmt.Errorf("retryable-errors.%s[%d]: invalid status %d", name, i, r.Status)
+			}
+			if _, err := r.Pattern(); err != nil {
+				return fmt.Errorf("retryable-errors.%s[%d]: body-regex: %w", name, i, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ProviderRetryableErrors returns the retryable error matchers of provider.
+func (cfg *Config) ProviderRetryableErrors(provider string) []RetryableError {
+	if cfg == nil {
+		return nil
+	}
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	for name, rules := range cfg.RetryableErrors {
+		if strings.ToLower(strings.TrimSpace(name)) == provider {
+			return rules
+		}
+	}
+	return nil
+}
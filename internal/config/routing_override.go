@@ -0,0 +1,13 @@
+package config
+
+import "slices"
+
+// RoutingOverrideAllowed reports whether the client authenticated with apiKey
+// may pin requests with the routing override headers. An empty apiKey means
+// client authentication is off, leaving routing-override as the only gate.
+func (c *SDKConfig) RoutingOverrideAllowed(apiKey string) bool {
+	if c == nil || !c.RoutingOverride {
+		return false
+	}
+	return apiKey == "" || slices.Contains(c.RoutingOverrideKeys, apiKey)
+}
@@ -0,0 +1,64 @@
+package config
+
+import "testing"
+
+func TestResolveModelAliasChain(t *testing.T) {
+	r := RoutingConfig{Aliases: map[string]string{
+		"gpt-4":  "gpt-4o",
+		"gpt-4o": "claude-sonnet-4-5",
+	}}
+	r.Init()
+	if got := r.ResolveModelAlias("gpt-4"); got != "claude-sonnet-4-5" {
+		t.Errorf("ResolveModelAlias(gpt-4) = %q, want claude-sonnet-4-5", got)
+	}
+	if got := r.ResolveModelAlias("other"); got != "other" {
+		t.Errorf("ResolveModelAlias(other) = %q, want other", got)
+	}
+	if err := r.ValidateAliases(); err != nil {
+		t.Errorf("unexpected validation error: %v", err)
+	}
+}
+
+func TestResolveModelAliasCycle(t *testing.T) {
+	r := RoutingConfig{Aliases: map[string]string{
+		"a": "b",
+		"b": "c",
+		"c": "a",
+	}}
+	r.Init()
+	if err := r.ValidateAliases(); err == nil {
+		t.Fatal("expected cycle error")
+	}
+	if got := r.ResolveModelAlias("a"); got != "a" {
+		t.Errorf("cyclic alias should resolve to input, got %q", got)
+	}
+}
+
+func TestSplitAliasTarget(t *testing.T) {
+	p, m, ok := SplitAliasTarget("Claude://claude-sonnet-4-5-20250929")
+	if !ok || p != "claude" || m != "claude-sonnet-4-5-20250929" {
+		t.Errorf("SplitAliasTarget = %q %q %v", p, m, ok)
+	}
+	if _, _, ok = SplitAliasTarget("claude-sonnet-4-5"); ok {
+		t.Error("plain model must not split")
+	}
+}
+
+func TestUnknownModelRoute(t *testing.T) {
+	var r RoutingConfig
+	if _, _, ok := r.CatchAll("new-model"); ok {
+		t.Error("catch-all applied without a provider")
+	}
+	r.UnknownModels = UnknownModelRoute{Model: "fallback-model"}
+	if err := r.ValidateUnknownModels(); err == nil {
+		t.Error("catch-all model without a provider passed validation")
+	}
+	r.UnknownModels.Provider = " OpenRouter "
+	if p, m, ok := r.CatchAll("new-model"); !ok || p != "openrouter" || m != "fallback-model" {
+		t.Errorf("CatchAll = %q %q %v", p, m, ok)
+	}
+	r.UnknownModels.Model = ""
+	if _, m, _ := r.CatchAll("new-model"); m != "new-model" {
+		t.Errorf("passthrough catch-all sent %q", m)
+	}
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Account selection strategies.
+const (
+	// SelectionRoundRobin rotates through available accounts.
+	SelectionRoundRobin = "round-robin"
+	// SelectionQuota prefers the account with the most daily quota headroom.
+	SelectionQuota = "quota"
+)
+
+// SelectionStrategy returns the configured selection strategy, defaulting to
+// round-robin.
+func (cfg *Config) SelectionStrategy() string {
+	if cfg == nil {
+		return SelectionRoundRobin
+	}
+	if s := strings.ToLower(strings.TrimSpace(cfg.Selection)); s != "" {
+		return s
+	}
+	return SelectionRoundRobin
+}
+
+// ValidateSelection rejects an unknown selection strategy.
+func (cfg *Config) ValidateSelection() error {
+	switch cfg.SelectionStrategy() {
+	case SelectionRoundRobin, SelectionQuota:
+		return nil
+	default:
+		return fmt.Errorf("selection: unknown strategy %q (want %s or %s)", cfg.Selection, SelectionRoundRobin, SelectionQuota)
+	}
+}
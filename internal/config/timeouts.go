@@ -0,0 +1,105 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultTimeoutsKey is the key in Timeouts whose values apply to every provider
+// without its own entry.
+const DefaultTimeoutsKey = "default"
+
+// Built-in outbound timeouts, used when neither the provider entry nor the
+// default entry sets a value.
+const (
+	DefaultConnectTimeout    = 30 * time.Second
+	DefaultRequestTimeout    = 10 * time.Minute
+	DefaultStreamIdleTimeout = 5 * time.Minute
+)
+
+// RequestTimeoutLimit is the longest timeout a client may request with the
+// X-LLM-Mux-Timeout header. It defaults to DefaultRequestTimeout.
+func (c *SDKConfig) RequestTimeoutLimit() time.Duration {
+	if c == nil || c.MaxRequestTimeout <= 0 {
+		return DefaultRequestTimeout
+	}
+	return time.Duration(c.MaxRequestTimeout) * time.Second
+}
+
+// ProviderTimeouts overrides outbound HTTP timeouts for a provider, in seconds.
+// Zero or unset values inherit from the "default" entry and then the built-ins.
+type ProviderTimeouts struct {
+	// Connect bounds dialing and the TLS handshake.
+	Connect int `yaml:"connect,omitempty" json:"connect,omitempty"`
+	// Request bounds a whole non-streaming call, including reading the body.
+	// Streaming calls are exempt and governed by StreamIdle instead.
+	Request int `yaml:"request,omitempty" json:"request,omitempty"`
+	// StreamIdle aborts a stream that delivers no data for this long.
+	StreamIdle int `yaml:"stream-idle,omitempty" json:"stream-idle,omitempty"`
+}
+
+// EffectiveTimeouts is the resolved timeout set for one provider.
+type EffectiveTimeouts struct {
+	Connect    time.Duration
+	Request    time.Duration
+	StreamIdle time.Duration
+}
+
+// Overall returns the limit for a whole call. Only streaming calls have no
+// overall limit; non-streaming calls always get one.
+func (t EffectiveTimeouts) Overall(streaming bool) time.Duration {
+	if streaming {
+		return 0
+	}
+	return t.Request
+}
+
+// ValidateTimeouts rejects negative timeout values.
+func (cfg *Config) ValidateTimeouts() error {
+	if cfg == nil {
+		return nil
+	}
+	if cfg.MaxRequestTimeout < 0 {
+		return fmt.Errorf("max-request-timeout must not be negative")
+	}
+	for name, t := range cfg.Timeouts {
+		if t.Connect < 0 || t.Request < 0 || t.StreamIdle < 0 {
+			return fmt.Errorf("timeouts.%s: values must not be negative", name)
+		}
+	}
+	return nil
+}
+
+// ProviderTimeouts resolves the timeouts applied to outbound calls for provider.
+func (cfg *Config) ProviderTimeouts(provider string) EffectiveTimeouts {
+	out := EffectiveTimeouts{
+		Connect:    DefaultConnectTimeout,
+		Request:    DefaultRequestTimeout,
+		StreamIdle: DefaultStreamIdleTimeout,
+	}
+	if cfg == nil || len(cfg.Timeouts) == 0 {
+		return out
+	}
+	apply := func(t ProviderTimeouts) {
+		if t.Connect > 0 {
+			out.Connect = time.Duration(t.Connect) * time.Second
+		}
+		if t.Request > 0 {
+			out.Request = time.Duration(t.Request) * time.Second
+		}
+		if t.StreamIdle > 0 {
+			out.StreamIdle = time.Duration(t.StreamIdle) * time.Second
+		}
+	}
+	if t, ok := cfg.Timeouts[DefaultTimeoutsKey]; ok {
+		apply(t)
+	}
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	for name, t := range cfg.Timeouts {
+		if name != DefaultTimeoutsKey && strings.ToLower(strings.TrimSpace(name)) == provider {
+			apply(t)
+		}
+	}
+	return out
+}
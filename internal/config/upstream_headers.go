@@ -0,0 +1,172 @@
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// DefaultUpstreamHeadersKey is the key in UpstreamHeaders whose headers apply
+// to every provider; a provider's own entry wins on conflicts.
+const DefaultUpstreamHeadersKey = "default"
+
+// ProviderHeaders adds headers to every outbound request for a provider.
+type ProviderHeaders struct {
+	// Headers maps header names to values. Values may reference the selected
+	// account with {{attr.NAME}}, {{meta.NAME}}, {{auth.id}}, {{auth.label}}
+	// or {{auth.provider}}; a header whose value renders empty is not sent.
+	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+	// AllowOverride lists reserved headers (credentials and transport
+	// framing) that Headers may replace. Reserved headers are rejected
+	// otherwise, so a typo cannot silently swap out the executor's credentials.
+	AllowOverride []string `yaml:"allow-override,omitempty" json:"allow-override,omitempty"`
+}
+
+// reservedUpstreamHeaders are set by executors or the HTTP transport and may
+// only be replaced when listed in AllowOverride.
+var reservedUpstreamHeaders = map[string]struct{}{
+	"Authorization":       {},
+	"Proxy-Authorization": {},
+	"X-Api-Key":           {},
+	"X-Goog-Api-Key":      {},
+	"Cookie":              {},
+	"Host":                {},
+	"Content-Length":      {},
+	"Content-Type":        {},
+	"Transfer-Encoding":   {},
+}
+
+// IsReservedUpstreamHeader reports whether name is a header executors own.
+func IsReservedUpstreamHeader(name string) bool {
+	_, ok := reservedUpstreamHeaders[http.CanonicalHeaderKey(strings.TrimSpace(name))]
+	return ok
+}
+
+var headerTemplatePattern = regexp.MustCompile(`\{\{\s*([a-z]+)(?:\.([^}\s]+))?\s*\}\}`)
+
+// ValidateUpstreamHeaders rejects empty header names, unknown template
+// references and reserved headers that are not explicitly allowed.
+func (cfg *Config) ValidateUpstreamHeaders() error {
+	if cfg == nil {
+		return nil
+	}
+	for name, ph := range cfg.UpstreamHeaders {
+		allowed := make(map[string]struct{}, len(ph.AllowOverride))
+		for _, h := range ph.AllowOverride {
+			allowed[http.CanonicalHeaderKey(strings.TrimSpace(h))] = struct{}{}
+		}
+		for key, val := range ph.Headers {
+			canon := http.CanonicalHeaderKey(strings.TrimSpace(key))
+			if canon == "" {
+				return fmt.Errorf("upstream-headers.%s: header name must not be empty", name)
+			}
+			if IsReservedUpstreamHeader(canon) {
+				if _, ok := allowed[canon]; !ok {
+					return fmt.Errorf("upstream-headers.%s: %s is reserved; list it in allow-override to replace it", name, canon)
+				}
+			}
+			for _, m := range headerTemplatePattern.FindAllStringSubmatch(val, -1) {
+				if !validHeaderTemplate(m[1], m[2]) {
+					return fmt.Errorf("upstream-headers.%s: %s: unknown template %s", name, canon, m[0])
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func validHeaderTemplate(scope, field string) bool {
+	switch scope {
+	case "attr", "meta":
+		return field != ""
+	case "auth":
+		return field == "id" || field == "label" || field == "provider"
+	}
+	return false
+}
+
+// HeaderTemplateData is the account data available to header templates.
+type HeaderTemplateData struct {
+	ID         string
+	Label      string
+	Provider   string
+	Attributes map[string]string
+	Metadata   map[string]any
+}
+
+// UpstreamHeadersFor resolves the headers added to outbound calls for
+// provider, with templates rendered against data. Reserved headers are only
+// returned when allowed, and the result is nil when nothing applies.
+func (cfg *Config) UpstreamHeadersFor(provider string, data HeaderTemplateData) map[string]string {
+	if cfg == nil || len(cfg.UpstreamHeaders) == 0 {
+		return nil
+	}
+	var out map[string]string
+	apply := func(ph ProviderHeaders) {
+		allowed := make(map[string]struct{}, len(ph.AllowOverride))
+		for _, h := range ph.AllowOverride {
+			allowed[http.CanonicalHeaderKey(strings.TrimSpace(h))] = struct{}{}
+		}
+		for key, val := range ph.Headers {
+			canon := http.CanonicalHeaderKey(strings.TrimSpace(key))
+			if canon == "" {
+				continue
+			}
+			if IsReservedUpstreamHeader(canon) {
+				if _, ok := allowed[canon]; !ok {
+					continue
+				}
+			}
+			if out == nil {
+				out = make(map[string]string, len(ph.Headers))
+			}
+			out[canon] = renderHeaderTemplate(val, data)
+		}
+	}
+	if ph, ok := cfg.UpstreamHeaders[DefaultUpstreamHeadersKey]; ok {
+		apply(ph)
+	}
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	for name, ph := range cfg.UpstreamHeaders {
+		if name != DefaultUpstreamHeadersKey && strings.ToLower(strings.TrimSpace(name)) == provider {
+			apply(ph)
+		}
+	}
+	for k, v := range out {
+		if v == "" {
+			delete(out, k)
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+func renderHeaderTemplate(val string, data HeaderTemplateData) string {
+	if !strings.Contains(val, "{{") {
+		return strings.TrimSpace(val)
+	}
+	return strings.TrimSpace(headerTemplatePattern.ReplaceAllStringFunc(val, func(ref string) string {
+		m := headerTemplatePattern.FindStringSubmatch(ref)
+		switch m[1] {
+		case "attr":
+			return data.Attributes[m[2]]
+		case "meta":
+			if v, ok := data.Metadata[m[2]]; ok && v != nil {
+				return fmt.Sprint(v)
+			}
+		case "auth":
+			switch m[2] {
+			case "id":
+				return data.ID
+			case "label":
+				return data.Label
+			case "provider":
+				return data.Provider
+			}
+		}
+		return ""
+	}))
+}
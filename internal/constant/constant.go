@@ -30,4 +30,13 @@ const (
 
 	// Kiro represents the Kiro (Amazon Q) provider identifier.
 	Kiro = "kiro"
+
+	// Cohere represents the Cohere provider identifier.
+	Cohere = "cohere"
+
+	// XAI represents the xAI (Grok) provider identifier.
+	XAI = "xai"
+
+	// Mistral represents the Mistral La Plateforme provider identifier.
+	Mistral = "mistral"
 )
@@ -0,0 +1,187 @@
+package logging
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// SampleHeader forces full body capture for a single request when set to a
+// true value. It is honored only when SamplingConfig allows forcing.
+const SampleHeader = "X-Debug-Sample"
+
+// ForceKeyHeader carries the key that lets a caller force capture through
+// SampleHeader when forcing is not open to everyone.
+const ForceKeyHeader = "X-Management-Key"
+
+// DefaultSampleMaxBodyBytes caps each sampled body when no limit is configured.
+const DefaultSampleMaxBodyBytes = 64 * 1024
+
+// SamplingConfig controls which requests have their bodies captured.
+type SamplingConfig struct {
+	// Rate is the fraction of requests, between 0 and 1, captured at random.
+	Rate float64
+	// OnError captures every request that finished with an error status.
+	OnError bool
+	// MaxBodyBytes truncates each captured body; zero uses DefaultSampleMaxBodyBytes.
+	MaxBodyBytes int
+	// Tee mirrors the streaming responses of selected requests as they are
+	// sent, limited to MaxBodyBytes per stream.
+	Tee StreamTeeConfig
+	// AllowForce honors SampleHeader from any caller.
+	AllowForce bool
+	// ForceKey, when set, honors SampleHeader from callers sending it in
+	// ForceKeyHeader.
+	ForceKey string
+}
+
+// BodySampler writes full, redacted request and response bodies for a sample
+// of requests to a directory separate from the regular request logs.
+type BodySampler struct {
+	dir string
+	cfg atomic.Pointer[SamplingConfig]
+	tee atomic.Pointer[StreamTee]
+}
+
+// NewBodySampler creates a sampler writing to dir. Sampling is disabled until
+// SetConfig enables a rate, error capture or forced captures.
+func NewBodySampler(dir string) *BodySampler {
+	s := &BodySampler{dir: dir}
+	s.cfg.Store(&SamplingConfig{})
+	return s
+}
+
+// SetConfig replaces the sampling configuration.
+func (s *BodySampler) SetConfig(cfg SamplingConfig) {
+	if cfg.Rate < 0 {
+		cfg.Rate = 0
+	}
+	if cfg.Rate > 1 {
+		cfg.Rate = 1
+	}
+	if cfg.MaxBodyBytes <= 0 {
+		cfg.MaxBodyBytes = DefaultSampleMaxBodyBytes
+	}
+	s.cfg.Store(&cfg)
+	var tee *StreamTee
+	if sink := newTeeSink(cfg.Tee, filepath.Join(s.dir, "streams")); sink != nil {
+		tee = NewStreamTee(sink, cfg.Tee.BufferChunks, cfg.MaxBodyBytes)
+	}
+	s.tee.Store(tee)
+}
+
+// Config returns the active sampling configuration.
+func (s *BodySampler) Config() SamplingConfig {
+	return *s.cfg.Load()
+}
+
+// Select decides at request start whether the request is captured regardless
+// of its outcome: either forced through SampleHeader or picked at random.
+func (s *BodySampler) Select(header http.Header) bool {
+	if s == nil {
+		return false
+	}
+	cfg := s.cfg.Load()
+	if cfg.forced(header) {
+		return true
+	}
+	return cfg.Rate > 0 && rand.Float64() < cfg.Rate
+}
+
+// forced reports whether header forces capture and the caller may force it.
+func (cfg *SamplingConfig) forced(header http.Header) bool {
+	switch strings.ToLower(strings.TrimSpace(header.Get(SampleHeader))) {
+	case "1", "true", "yes":
+	default:
+		return false
+	}
+	if cfg.AllowForce {
+		return true
+	}
+	key := header.Get(ForceKeyHeader)
+	return cfg.ForceKey != "" && key != "" && subtle.ConstantTimeCompare([]byte(key), []byte(cfg.ForceKey)) == 1
+}
+
+// WantsError reports whether failed requests are captured.
+func (s *BodySampler) WantsError() bool {
+	return s != nil && s.cfg.Load().OnError
+}
+
+// MaxBodyBytes returns the per-body capture limit.
+func (s *BodySampler) MaxBodyBytes() int {
+	if s == nil {
+		return 0
+	}
+	if limit := s.cfg.Load().MaxBodyBytes; limit > 0 {
+		return limit
+	}
+	return DefaultSampleMaxBodyBytes
+}
+
+// SetStreamTee replaces the tee that mirrors selected streams, for sinks
+// other than the configured ones. A nil tee stops mirroring new streams.
+func (s *BodySampler) SetStreamTee(tee *StreamTee) {
+	s.tee.Store(tee)
+}
+
+// OpenStreamTee starts mirroring the stream of a selected request. It returns
+// nil when no tee is configured.
+func (s *BodySampler) OpenStreamTee(info TeeStreamInfo) *TeeStream {
+	if s == nil {
+		return nil
+	}
+	return s.tee.Load().Open(info)
+}
+
+// Write stores one sample. Bodies are redacted and truncated before writing.
+func (s *BodySampler) Write(requestID, method, url string, status int, requestBody, responseBody []byte) error {
+	if s == nil {
+		return nil
+	}
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create sample directory: %w", err)
+	}
+	r := CurrentRedactor()
+	limit := s.MaxBodyBytes()
+
+	var content strings.Builder
+	content.WriteString("=== SAMPLE ===\n")
+	content.WriteString(fmt.Sprintf("Request ID: %s\n", requestID))
+	content.WriteString(fmt.Sprintf("URL: %s\n", r.String(url)))
+	content.WriteString(fmt.Sprintf("Method: %s\n", method))
+	content.WriteString(fmt.Sprintf("Status: %d\n", status))
+	content.WriteString(fmt.Sprintf("Timestamp: %s\n\n", time.Now().Format(time.RFC3339Nano)))
+	content.WriteString("=== REQUEST BODY ===\n")
+	content.Write(r.Bytes(truncateBody(requestBody, limit)))
+	content.WriteString("\n\n=== RESPONSE BODY ===\n")
+	content.Write(r.Bytes(truncateBody(responseBody, limit)))
+	content.WriteString("\n")
+
+	name := fmt.Sprintf("sample-%s-%s.log", time.Now().Format("20060102-150405.000000"), sanitizeSampleID(requestID))
+	if err := os.WriteFile(filepath.Join(s.dir, name), []byte(content.String()), 0o644); err != nil {
+		return fmt.Errorf("failed to write sample: %w", err)
+	}
+	return nil
+}
+
+func truncateBody(body []byte, limit int) []byte {
+	if limit <= 0 || len(body) <= limit {
+		return body
+	}
+	out := make([]byte, 0, limit+32)
+	out = append(out, body[:limit]...)
+	return append(out, fmt.Sprintf("\n[TRUNCATED %d bytes]", len(body)-limit)...)
+}
+
+func sanitizeSampleID(id string) string {
+	if id == "" {
+		return "noid"
+	}
+	return sanitizeRegex2.ReplaceAllString(sanitizeRegex1.ReplaceAllString(strings.ReplaceAll(id, "/", "-"), "-"), "-")
+}
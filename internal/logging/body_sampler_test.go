@@ -0,0 +1,69 @@
+package logging
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBodySamplerSelect(t *testing.T) {
+	s := NewBodySampler(t.TempDir())
+	if s.Select(http.Header{}) {
+		t.Fatal("sampling should be off by default")
+	}
+	s.SetConfig(SamplingConfig{AllowForce: true})
+	for _, v := range []string{"true", "1"} {
+		if !s.Select(http.Header{SampleHeader: {v}}) {
+			t.Fatalf("debug header %q must force capture", v)
+		}
+	}
+	s.SetConfig(SamplingConfig{Rate: 1})
+	if !s.Select(http.Header{}) {
+		t.Fatal("rate 1 must capture every request")
+	}
+}
+
+func TestBodySamplerSelect_ForceNeedsDebugOrKey(t *testing.T) {
+	s := NewBodySampler(t.TempDir())
+	forced := http.Header{SampleHeader: {"true"}}
+	if s.Select(forced) {
+		t.Fatal("debug header forced capture with forcing disabled")
+	}
+	s.SetConfig(SamplingConfig{ForceKey: "mgmt-secret"})
+	if s.Select(forced) {
+		t.Fatal("debug header forced capture without the management key")
+	}
+	if s.Select(http.Header{SampleHeader: {"true"}, ForceKeyHeader: {"wrong"}}) {
+		t.Fatal("debug header forced capture with a wrong management key")
+	}
+	if !s.Select(http.Header{SampleHeader: {"true"}, ForceKeyHeader: {"mgmt-secret"}}) {
+		t.Fatal("debug header with the management key must force capture")
+	}
+}
+
+func TestBodySamplerWriteRedactsAndTruncates(t *testing.T) {
+	dir := t.TempDir()
+	s := NewBodySampler(dir)
+	s.SetConfig(SamplingConfig{MaxBodyBytes: 64})
+
+	request := []byte(`{"api_key":"sk-secret-sample-key","messages":[]}`)
+	response := []byte(strings.Repeat("x", 200))
+	if err := s.Write("req-1", "POST", "/v1/chat/completions", 500, request, response); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("expected one sample file, got %d (%v)", len(entries), err)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, entries[0].Name()))
+	content := string(data)
+	if strings.Contains(content, "sk-secret-sample-key") {
+		t.Fatal("sample leaked secret")
+	}
+	if !strings.Contains(content, "[TRUNCATED 136 bytes]") {
+		t.Fatalf("expected truncation marker, got:\n%s", content)
+	}
+}
@@ -34,6 +34,9 @@ func GinLogrusLogger() gin.HandlerFunc {
 		errorMessage := c.Errors.ByType(gin.ErrorTypePrivate).String()
 		timestamp := time.Now().Format("2006/01/02 - 15:04:05")
 		logLine := fmt.Sprintf("[GIN] %s | %3d | %13v | %15s | %-7s \"%s\"", timestamp, statusCode, latency, clientIP, method, path)
+		if requestID := c.GetString(RequestIDKey); requestID != "" {
+			logLine = logLine + " | request_id=" + requestID
+		}
 		if errorMessage != "" {
 			logLine = logLine + " | " + errorMessage
 		}
@@ -52,9 +55,10 @@ func GinLogrusLogger() gin.HandlerFunc {
 func GinLogrusRecovery() gin.HandlerFunc {
 	return gin.CustomRecovery(func(c *gin.Context, recovered any) {
 		WithFields(Fields{
-			"panic": recovered,
-			"stack": string(debug.Stack()),
-			"path":  c.Request.URL.Path,
+			"panic":      recovered,
+			"stack":      string(debug.Stack()),
+			"path":       c.Request.URL.Path,
+			"request_id": c.GetString(RequestIDKey),
 		}).Error("recovered from panic")
 
 		c.AbortWithStatus(http.StatusInternalServerError)
@@ -39,6 +39,9 @@ func maskAuthorizationHeader(value string) string {
 }
 
 func maskSensitiveHeaderValue(key, value string) string {
+	if value == RedactedPlaceholder {
+		return value
+	}
 	lowerKey := strings.ToLower(strings.TrimSpace(key))
 	switch {
 	case strings.Contains(lowerKey, "authorization"):
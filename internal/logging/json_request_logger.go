@@ -0,0 +1,194 @@
+package logging
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/interfaces"
+	"github.com/nghyane/llm-mux/internal/json"
+)
+
+// JSONRequestLogFileName is the file JSONRequestLogger appends to inside its directory.
+const JSONRequestLogFileName = "requests.jsonl"
+
+const jsonLogFlushInterval = time.Second
+
+// jsonLogEntry is one line of the JSON request log.
+type jsonLogEntry struct {
+	Timestamp    string `json:"timestamp"`
+	RequestID    string `json:"request_id,omitempty"`
+	ClientKey    string `json:"client_key,omitempty"`
+	Method       string `json:"method"`
+	URL          string `json:"url"`
+	Provider     string `json:"provider,omitempty"`
+	Model        string `json:"model,omitempty"`
+	Status       int    `json:"status"`
+	LatencyMS    int64  `json:"latency_ms"`
+	InputTokens  int64  `json:"input_tokens"`
+	OutputTokens int64  `json:"output_tokens"`
+	Retries      int    `json:"retries"`
+	Error        string `json:"error,omitempty"`
+}
+
+// JSONRequestLogger implements RequestLogger by appending one JSON object per
+// request to a JSON Lines file. Writes are buffered and flushed periodically.
+type JSONRequestLogger struct {
+	enabled atomic.Bool
+	dir     string
+
+	mu     sync.Mutex
+	file   *os.File
+	writer *bufio.Writer
+
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+// NewJSONRequestLogger creates an enabled JSON request logger writing to dir.
+// The log file is opened lazily on the first entry.
+func NewJSONRequestLogger(dir string) *JSONRequestLogger {
+	l := &JSONRequestLogger{dir: dir, stop: make(chan struct{})}
+	l.enabled.Store(true)
+	go l.flushLoop()
+	return l
+}
+
+// IsEnabled returns whether request logging is currently enabled.
+func (l *JSONRequestLogger) IsEnabled() bool {
+	return l.enabled.Load()
+}
+
+// SetEnabled updates the request logging enabled state.
+func (l *JSONRequestLogger) SetEnabled(enabled bool) {
+	l.enabled.Store(enabled)
+}
+
+// LogRequest records a request for which no summary is available.
+func (l *JSONRequestLogger) LogRequest(url, method string, requestHeaders map[string][]string, _ []byte, statusCode int, _ map[string][]string, _, _, _ []byte, apiResponseErrors []*interfaces.ErrorMessage) error {
+	if !l.IsEnabled() {
+		return nil
+	}
+	return l.write(RequestSummary{
+		Timestamp:  time.Now(),
+		RequestID:  RequestIDFromHeaders(requestHeaders),
+		Method:     method,
+		URL:        url,
+		StatusCode: statusCode,
+		Errors:     apiResponseErrors,
+	})
+}
+
+// LogStreamingRequest returns a no-op writer; streamed requests are recorded
+// once they finish through LogRequestSummary.
+func (l *JSONRequestLogger) LogStreamingRequest(_, _ string, _ map[string][]string, _ []byte) (StreamingLogWriter, error) {
+	return &NoOpStreamingLogWriter{}, nil
+}
+
+// LogRequestSummary writes one JSON line for a finished request.
+// The force flag records failed requests even when logging is disabled.
+func (l *JSONRequestLogger) LogRequestSummary(summary RequestSummary, force bool) error {
+	if !l.IsEnabled() && !force {
+		return nil
+	}
+	return l.write(summary)
+}
+
+func (l *JSONRequestLogger) write(s RequestSummary) error {
+	entry := jsonLogEntry{
+		Timestamp:    s.Timestamp.UTC().Format(time.RFC3339Nano),
+		RequestID:    s.RequestID,
+		ClientKey:    s.ClientKey,
+		Method:       s.Method,
+		URL:          s.URL,
+		Provider:     s.Meta.Provider,
+		Model:        s.Meta.Model,
+		Status:       s.StatusCode,
+		LatencyMS:    s.Latency.Milliseconds(),
+		InputTokens:  s.Meta.InputTokens,
+		OutputTokens: s.Meta.OutputTokens,
+		Retries:      s.Meta.Retries(),
+	}
+	for _, e := range s.Errors {
+		if e != nil && e.Error != nil {
+			entry.Error = e.Error.Error()
+		}
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode request log entry: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err = l.openLocked(); err != nil {
+		return err
+	}
+	if _, err = l.writer.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write request log entry: %w", err)
+	}
+	return nil
+}
+
+func (l *JSONRequestLogger) openLocked() error {
+	if l.writer != nil {
+		return nil
+	}
+	if err := os.MkdirAll(l.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create logs directory: %w", err)
+	}
+	file, err := os.OpenFile(filepath.Join(l.dir, JSONRequestLogFileName), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open request log: %w", err)
+	}
+	l.file = file
+	l.writer = bufio.NewWriterSize(file, 64*1024)
+	return nil
+}
+
+// Flush writes buffered entries to disk.
+func (l *JSONRequestLogger) Flush() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.writer == nil {
+		return nil
+	}
+	return l.writer.Flush()
+}
+
+// Close flushes pending entries and closes the log file.
+func (l *JSONRequestLogger) Close() error {
+	l.stopOnce.Do(func() { close(l.stop) })
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.writer == nil {
+		return nil
+	}
+	errFlush := l.writer.Flush()
+	errClose := l.file.Close()
+	l.writer = nil
+	l.file = nil
+	if errFlush != nil {
+		return errFlush
+	}
+	return errClose
+}
+
+func (l *JSONRequestLogger) flushLoop() {
+	ticker := time.NewTicker(jsonLogFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			if err := l.Flush(); err != nil {
+				WithError(err).Warn("failed to flush JSON request log")
+			}
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package logging
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestJSONRequestLoggerConcurrentWrites(t *testing.T) {
+	dir := t.TempDir()
+	l := NewJSONRequestLogger(dir)
+
+	const n = 200
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := l.LogRequestSummary(RequestSummary{
+				Timestamp:  time.Now(),
+				RequestID:  "req-1",
+				Method:     "POST",
+				URL:        "/v1/chat/completions",
+				StatusCode: 200,
+				Latency:    1500 * time.Millisecond,
+				Meta:       RequestMeta{Provider: "claude", Model: "claude-sonnet-4-5", InputTokens: 10, OutputTokens: 20, Attempts: 2},
+			}, false)
+			if err != nil {
+				t.Errorf("LogRequestSummary: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	f, err := os.Open(filepath.Join(dir, JSONRequestLogFileName))
+	if err != nil {
+		t.Fatalf("open log: %v", err)
+	}
+	defer f.Close()
+
+	lines := 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry jsonLogEntry
+		if err = json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("line %d is not valid JSON: %v", lines+1, err)
+		}
+		if entry.Provider != "claude" || entry.Retries != 1 || entry.LatencyMS != 1500 || entry.OutputTokens != 20 {
+			t.Fatalf("unexpected entry: %+v", entry)
+		}
+		lines++
+	}
+	if lines != n {
+		t.Fatalf("expected %d lines, got %d", n, lines)
+	}
+}
+
+func TestJSONRequestLoggerDisabled(t *testing.T) {
+	dir := t.TempDir()
+	l := NewJSONRequestLogger(dir)
+	l.SetEnabled(false)
+	if err := l.LogRequestSummary(RequestSummary{StatusCode: 200}, false); err != nil {
+		t.Fatal(err)
+	}
+	_ = l.Close()
+	if _, err := os.Stat(filepath.Join(dir, JSONRequestLogFileName)); !os.IsNotExist(err) {
+		t.Fatalf("expected no log file when disabled, got %v", err)
+	}
+}
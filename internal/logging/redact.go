@@ -0,0 +1,169 @@
+package logging
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"github.com/nghyane/llm-mux/internal/interfaces"
+)
+
+// RedactedPlaceholder replaces secret values in logs.
+const RedactedPlaceholder = "[REDACTED]"
+
+// DefaultRedactedHeaders lists header names whose values are always redacted.
+var DefaultRedactedHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"X-Api-Key",
+	"X-Goog-Api-Key",
+	"Api-Key",
+	"Cookie",
+	"Set-Cookie",
+}
+
+// DefaultRedactedFields lists JSON and form field names whose values are always redacted.
+var DefaultRedactedFields = []string{
+	"api_key",
+	"apiKey",
+	"access_token",
+	"refresh_token",
+	"id_token",
+	"client_secret",
+	"password",
+}
+
+var bearerRegex = regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9._~+/=-]+`)
+
+// Redactor scrubs secrets from headers and payloads before they are logged.
+type Redactor struct {
+	headers      map[string]struct{}
+	headerLineRe *regexp.Regexp
+	jsonFieldRe  *regexp.Regexp
+	formFieldRe  *regexp.Regexp
+}
+
+// NewRedactor builds a Redactor for the default header and field names plus
+// the extra ones provided. Matching is case-insensitive.
+func NewRedactor(extraHeaders, extraFields []string) *Redactor {
+	headerNames := mergeNames(DefaultRedactedHeaders, extraHeaders)
+	fieldNames := mergeNames(DefaultRedactedFields, extraFields)
+
+	r := &Redactor{headers: make(map[string]struct{}, len(headerNames))}
+	quotedHeaders := make([]string, 0, len(headerNames))
+	for _, h := range headerNames {
+		r.headers[strings.ToLower(h)] = struct{}{}
+		quotedHeaders = append(quotedHeaders, regexp.QuoteMeta(h))
+	}
+	quotedFields := make([]string, 0, len(fieldNames))
+	for _, f := range fieldNames {
+		quotedFields = append(quotedFields, regexp.QuoteMeta(f))
+	}
+	headerAlt := strings.Join(quotedHeaders, "|")
+	fieldAlt := strings.Join(quotedFields, "|")
+	r.headerLineRe = regexp.MustCompile(`(?im)^(` + headerAlt + `)[ \t]*:[^\r\n]*`)
+	r.jsonFieldRe = regexp.MustCompile(`(?i)("(?:` + fieldAlt + `)"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+	r.formFieldRe = regexp.MustCompile(`(?i)\b((?:` + fieldAlt + `)=)[^&\s"]+`)
+	return r
+}
+
+func mergeNames(defaults, extra []string) []string {
+	seen := make(map[string]struct{}, len(defaults)+len(extra))
+	out := make([]string, 0, len(defaults)+len(extra))
+	for _, list := range [][]string{defaults, extra} {
+		for _, name := range list {
+			name = strings.TrimSpace(name)
+			key := strings.ToLower(name)
+			if name == "" {
+				continue
+			}
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// Headers returns a copy of h with sensitive header values replaced.
+func (r *Redactor) Headers(h map[string][]string) map[string][]string {
+	if h == nil {
+		return nil
+	}
+	out := make(map[string][]string, len(h))
+	for key, values := range h {
+		copied := make([]string, len(values))
+		_, sensitive := r.headers[strings.ToLower(key)]
+		for i, v := range values {
+			if sensitive {
+				copied[i] = RedactedPlaceholder
+			} else {
+				copied[i] = r.String(v)
+			}
+		}
+		out[key] = copied
+	}
+	return out
+}
+
+// String scrubs bearer tokens, sensitive header lines and sensitive JSON or
+// form fields from s.
+func (r *Redactor) String(s string) string {
+	if s == "" {
+		return s
+	}
+	s = r.headerLineRe.ReplaceAllString(s, "${1}: "+RedactedPlaceholder)
+	s = bearerRegex.ReplaceAllString(s, "Bearer "+RedactedPlaceholder)
+	s = r.jsonFieldRe.ReplaceAllString(s, `${1}"`+RedactedPlaceholder+`"`)
+	s = r.formFieldRe.ReplaceAllString(s, "${1}"+RedactedPlaceholder)
+	return s
+}
+
+// Bytes is the []byte form of String.
+func (r *Redactor) Bytes(b []byte) []byte {
+	if len(b) == 0 {
+		return b
+	}
+	return []byte(r.String(string(b)))
+}
+
+// Errors returns copies of errs whose messages have been redacted.
+func (r *Redactor) Errors(errs []*interfaces.ErrorMessage) []*interfaces.ErrorMessage {
+	if len(errs) == 0 {
+		return errs
+	}
+	out := make([]*interfaces.ErrorMessage, len(errs))
+	for i, e := range errs {
+		if e == nil || e.Error == nil {
+			out[i] = e
+			continue
+		}
+		out[i] = &interfaces.ErrorMessage{
+			StatusCode: e.StatusCode,
+			Error:      errors.New(r.String(e.Error.Error())),
+			Addon:      http.Header(r.Headers(e.Addon)),
+		}
+	}
+	return out
+}
+
+var activeRedactor atomic.Pointer[Redactor]
+
+func init() {
+	activeRedactor.Store(NewRedactor(nil, nil))
+}
+
+// SetRedaction replaces the process-wide redactor with one that also covers
+// the given header and field names.
+func SetRedaction(extraHeaders, extraFields []string) {
+	activeRedactor.Store(NewRedactor(extraHeaders, extraFields))
+}
+
+// CurrentRedactor returns the process-wide redactor.
+func CurrentRedactor() *Redactor {
+	return activeRedactor.Load()
+}
@@ -0,0 +1,111 @@
+package logging
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/interfaces"
+)
+
+const (
+	secretAPIKey   = "sk-ant-REDACTED"
+	secretAccess   = "ya29.a0AfH6SMBsecretaccess"
+	secretRefresh  = "1//0gRefreshSecretToken"
+	secretBearer   = "ghu_BearerSecretToken42"
+	secretCustom   = "custom-field-secret-value"
+	secretXHeader  = "x-tenant-secret-value"
+	secretPassword = "hunter2-password-secret"
+)
+
+var allSecrets = []string{secretAPIKey, secretAccess, secretRefresh, secretBearer, secretCustom, secretXHeader, secretPassword}
+
+func assertNoSecrets(t *testing.T, where, s string) {
+	t.Helper()
+	for _, secret := range allSecrets {
+		if strings.Contains(s, secret) {
+			t.Errorf("%s leaked secret %q:\n%s", where, secret, s)
+		}
+	}
+}
+
+func TestRedactorPayloads(t *testing.T) {
+	r := NewRedactor([]string{"X-Tenant-Secret"}, []string{"session_secret"})
+
+	samples := map[string]string{
+		"json":        `{"api_key":"` + secretAPIKey + `","nested":{"access_token": "` + secretAccess + `","refresh_token":"` + secretRefresh + `"},"session_secret":"` + secretCustom + `"}`,
+		"form":        "grant_type=refresh_token&refresh_token=" + secretRefresh + "&password=" + secretPassword,
+		"bearer":      "upstream said: invalid token Bearer " + secretBearer,
+		"header dump": "Authorization: Bearer " + secretBearer + "\nx-api-key: " + secretAPIKey + "\nX-Tenant-Secret: " + secretXHeader + "\nContent-Type: application/json",
+		"sse":         `data: {"type":"message","apiKey":"` + secretAPIKey + `"}`,
+	}
+	for name, payload := range samples {
+		t.Run(name, func(t *testing.T) {
+			out := r.String(payload)
+			assertNoSecrets(t, name, out)
+			if !strings.Contains(out, RedactedPlaceholder) {
+				t.Errorf("expected placeholder in %q", out)
+			}
+		})
+	}
+
+	if got := r.String(`{"model":"claude-sonnet-4-5","max_tokens":1024}`); got != `{"model":"claude-sonnet-4-5","max_tokens":1024}` {
+		t.Errorf("non-sensitive payload modified: %s", got)
+	}
+
+	headers := r.Headers(map[string][]string{
+		"Authorization":   {"Bearer " + secretBearer},
+		"X-Tenant-Secret": {secretXHeader},
+		"Content-Type":    {"application/json"},
+	})
+	if headers["Content-Type"][0] != "application/json" {
+		t.Errorf("non-sensitive header modified: %v", headers["Content-Type"])
+	}
+	for k, v := range headers {
+		assertNoSecrets(t, "header "+k, strings.Join(v, ","))
+	}
+}
+
+func TestRedactingFileLoggerNeverWritesSecrets(t *testing.T) {
+	SetRedaction([]string{"X-Tenant-Secret"}, []string{"session_secret"})
+	defer SetRedaction(nil, nil)
+
+	dir := t.TempDir()
+	logger := NewRedactingRequestLogger(NewFileRequestLogger(true, dir, ""))
+
+	requestHeaders := map[string][]string{
+		"Authorization":   {"Bearer " + secretBearer},
+		"X-Api-Key":       {secretAPIKey},
+		"X-Tenant-Secret": {secretXHeader},
+	}
+	body := []byte(`{"model":"m","session_secret":"` + secretCustom + `"}`)
+	apiRequest := []byte("=== API REQUEST ===\nAuthorization: Bearer " + secretBearer + "\n\n{\"password\":\"" + secretPassword + "\"}")
+	apiResponse := []byte(`{"access_token":"` + secretAccess + `","refresh_token":"` + secretRefresh + `"}`)
+	errs := []*interfaces.ErrorMessage{{StatusCode: 401, Error: errors.New("invalid api_key=" + secretAPIKey)}}
+
+	if err := logger.LogRequest("/v1/chat/completions", "POST", requestHeaders, body, 401, nil, []byte(`{"error":"x"}`), apiRequest, apiResponse, errs); err != nil {
+		t.Fatalf("LogRequest: %v", err)
+	}
+
+	stream, err := logger.LogStreamingRequest("/v1/messages", "POST", requestHeaders, body)
+	if err != nil {
+		t.Fatalf("LogStreamingRequest: %v", err)
+	}
+	_ = stream.WriteStatus(200, map[string][]string{"Set-Cookie": {"session=" + secretCustom}})
+	stream.WriteChunkAsync([]byte(`data: {"api_key":"` + secretAPIKey + `"}` + "\n\n"))
+	_ = stream.Close()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) == 0 {
+		t.Fatalf("expected log files, err=%v", err)
+	}
+	for _, e := range entries {
+		data, errRead := os.ReadFile(filepath.Join(dir, e.Name()))
+		if errRead != nil {
+			t.Fatal(errRead)
+		}
+		assertNoSecrets(t, e.Name(), string(data))
+	}
+}
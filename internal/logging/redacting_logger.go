@@ -0,0 +1,109 @@
+package logging
+
+import (
+	"io"
+
+	"github.com/nghyane/llm-mux/internal/interfaces"
+)
+
+// NewRedactingRequestLogger wraps inner so that every header, body, upstream
+// payload and error passes through the process-wide Redactor before inner sees
+// it. Optional capabilities of inner (SetEnabled, LogRequestWithOptions,
+// LogRequestSummary, Close) are preserved.
+func NewRedactingRequestLogger(inner RequestLogger) RequestLogger {
+	if inner == nil {
+		return nil
+	}
+	base := &redactingLogger{inner: inner}
+	if summary, ok := inner.(SummaryRequestLogger); ok {
+		return &redactingSummaryLogger{redactingLogger: base, summary: summary}
+	}
+	return base
+}
+
+type redactingLogger struct {
+	inner RequestLogger
+}
+
+func (l *redactingLogger) IsEnabled() bool {
+	return l.inner.IsEnabled()
+}
+
+func (l *redactingLogger) SetEnabled(enabled bool) {
+	if setter, ok := l.inner.(interface{ SetEnabled(bool) }); ok {
+		setter.SetEnabled(enabled)
+	}
+}
+
+func (l *redactingLogger) Close() error {
+	if closer, ok := l.inner.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func (l *redactingLogger) LogRequest(url, method string, requestHeaders map[string][]string, body []byte, statusCode int, responseHeaders map[string][]string, response, apiRequest, apiResponse []byte, apiResponseErrors []*interfaces.ErrorMessage) error {
+	return l.LogRequestWithOptions(url, method, requestHeaders, body, statusCode, responseHeaders, response, apiRequest, apiResponse, apiResponseErrors, false)
+}
+
+func (l *redactingLogger) LogRequestWithOptions(url, method string, requestHeaders map[string][]string, body []byte, statusCode int, responseHeaders map[string][]string, response, apiRequest, apiResponse []byte, apiResponseErrors []*interfaces.ErrorMessage, force bool) error {
+	r := CurrentRedactor()
+	url = r.String(url)
+	requestHeaders = r.Headers(requestHeaders)
+	body = r.Bytes(body)
+	responseHeaders = r.Headers(responseHeaders)
+	response = r.Bytes(response)
+	apiRequest = r.Bytes(apiRequest)
+	apiResponse = r.Bytes(apiResponse)
+	apiResponseErrors = r.Errors(apiResponseErrors)
+
+	if withOptions, ok := l.inner.(interface {
+		LogRequestWithOptions(string, string, map[string][]string, []byte, int, map[string][]string, []byte, []byte, []byte, []*interfaces.ErrorMessage, bool) error
+	}); ok {
+		return withOptions.LogRequestWithOptions(url, method, requestHeaders, body, statusCode, responseHeaders, response, apiRequest, apiResponse, apiResponseErrors, force)
+	}
+	return l.inner.LogRequest(url, method, requestHeaders, body, statusCode, responseHeaders, response, apiRequest, apiResponse, apiResponseErrors)
+}
+
+func (l *redactingLogger) LogStreamingRequest(url, method string, headers map[string][]string, body []byte) (StreamingLogWriter, error) {
+	r := CurrentRedactor()
+	writer, err := l.inner.LogStreamingRequest(r.String(url), method, r.Headers(headers), r.Bytes(body))
+	if err != nil || writer == nil {
+		return writer, err
+	}
+	if _, noop := writer.(*NoOpStreamingLogWriter); noop {
+		return writer, nil
+	}
+	return &redactingStreamWriter{inner: writer, redactor: r}, nil
+}
+
+type redactingSummaryLogger struct {
+	*redactingLogger
+	summary SummaryRequestLogger
+}
+
+func (l *redactingSummaryLogger) LogRequestSummary(summary RequestSummary, force bool) error {
+	r := CurrentRedactor()
+	summary.URL = r.String(summary.URL)
+	summary.Errors = r.Errors(summary.Errors)
+	return l.summary.LogRequestSummary(summary, force)
+}
+
+// redactingStreamWriter redacts each streamed chunk. Secrets split across
+// chunk boundaries are not detected.
+type redactingStreamWriter struct {
+	inner    StreamingLogWriter
+	redactor *Redactor
+}
+
+func (w *redactingStreamWriter) WriteChunkAsync(chunk []byte) {
+	w.inner.WriteChunkAsync(w.redactor.Bytes(chunk))
+}
+
+func (w *redactingStreamWriter) WriteStatus(status int, headers map[string][]string) error {
+	return w.inner.WriteStatus(status, w.redactor.Headers(headers))
+}
+
+func (w *redactingStreamWriter) Close() error {
+	return w.inner.Close()
+}
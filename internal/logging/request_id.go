@@ -0,0 +1,34 @@
+package logging
+
+import (
+	"context"
+	"net/http"
+)
+
+// RequestIDHeader is the header used to carry the request ID in and out of the proxy.
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDKey is the gin context key holding the request ID.
+const RequestIDKey = "request_id"
+
+type requestIDKey struct{}
+
+// WithRequestID returns a context carrying id to the upstream calls made with it.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID attached to ctx by WithRequestID,
+// or an empty string when none is available.
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// RequestIDFromHeaders extracts the request ID from a header map.
+func RequestIDFromHeaders(headers map[string][]string) string {
+	return http.Header(headers).Get(RequestIDHeader)
+}
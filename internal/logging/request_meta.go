@@ -0,0 +1,124 @@
+package logging
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nghyane/llm-mux/internal/interfaces"
+)
+
+// requestMetaKey is the gin context key holding the *RequestMeta of a request.
+const requestMetaKey = "REQUEST_META"
+
+var requestMetaMu sync.Mutex
+
+// RequestMeta collects routing and usage details reported by the runtime while
+// a request is served. Each upstream attempt updates Provider and Model, so the
+// values describe the attempt that produced the response.
+type RequestMeta struct {
+	Provider     string
+	Model        string
+	InputTokens  int64
+	OutputTokens int64
+	Attempts     int
+}
+
+// Retries returns the number of upstream attempts beyond the first.
+func (m RequestMeta) Retries() int {
+	if m.Attempts <= 1 {
+		return 0
+	}
+	return m.Attempts - 1
+}
+
+type requestMetaHolder struct {
+	mu   sync.Mutex
+	meta RequestMeta
+}
+
+// UpdateRequestMeta applies fn to the metadata of the request carried by ctx.
+// It is a no-op when ctx does not belong to an HTTP request.
+func UpdateRequestMeta(ctx context.Context, fn func(*RequestMeta)) {
+	if ctx == nil || fn == nil {
+		return
+	}
+	ginCtx, ok := ctx.Value("gin").(*gin.Context)
+	if !ok || ginCtx == nil {
+		return
+	}
+	holder := metaHolder(ginCtx, true)
+	holder.mu.Lock()
+	fn(&holder.meta)
+	holder.mu.Unlock()
+}
+
+// RequestMetaFromGin returns a snapshot of the metadata recorded for c.
+func RequestMetaFromGin(c *gin.Context) RequestMeta {
+	holder := metaHolder(c, false)
+	if holder == nil {
+		return RequestMeta{}
+	}
+	holder.mu.Lock()
+	defer holder.mu.Unlock()
+	return holder.meta
+}
+
+func metaHolder(c *gin.Context, create bool) *requestMetaHolder {
+	if c == nil {
+		return nil
+	}
+	requestMetaMu.Lock()
+	defer requestMetaMu.Unlock()
+	if v, ok := c.Get(requestMetaKey); ok {
+		if holder, okHolder := v.(*requestMetaHolder); okHolder {
+			return holder
+		}
+	}
+	if !create {
+		return nil
+	}
+	holder := &requestMetaHolder{}
+	c.Set(requestMetaKey, holder)
+	return holder
+}
+
+// RequestSummary describes a finished request for loggers that record one
+// entry per request instead of the raw HTTP exchange.
+type RequestSummary struct {
+	Timestamp  time.Time
+	RequestID  string
+	ClientKey  string
+	Method     string
+	URL        string
+	StatusCode int
+	Latency    time.Duration
+	Meta       RequestMeta
+	Errors     []*interfaces.ErrorMessage
+}
+
+// SummaryRequestLogger is implemented by request loggers that consume a
+// RequestSummary. The request logging middleware prefers it over LogRequest.
+type SummaryRequestLogger interface {
+	LogRequestSummary(summary RequestSummary, force bool) error
+}
+
+// ClientKeyLabel returns a log-safe label for the client API key of c: the
+// key's configured label when available, otherwise the masked key.
+func ClientKeyLabel(c *gin.Context) string {
+	if c == nil {
+		return ""
+	}
+	if v, ok := c.Get("accessMetadata"); ok {
+		if md, okMD := v.(map[string]string); okMD && md["label"] != "" {
+			return md["label"]
+		}
+	}
+	if v, ok := c.Get("apiKey"); ok {
+		if key, okKey := v.(string); okKey && key != "" {
+			return hideAPIKey(key)
+		}
+	}
+	return ""
+}
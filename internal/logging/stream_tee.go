@@ -0,0 +1,321 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/json"
+)
+
+// DefaultTeeBufferChunks is how many chunks of a stream may wait for the tee
+// sink when no buffer size is configured.
+const DefaultTeeBufferChunks = 256
+
+// teeWebhookTimeout bounds each webhook delivery.
+const teeWebhookTimeout = 10 * time.Second
+
+// StreamTeeConfig mirrors sampled streaming responses to a secondary sink.
+// The tee is off unless File or WebhookURL is set.
+type StreamTeeConfig struct {
+	// File writes each mirrored stream to its own file under the sample
+	// directory's streams/ folder.
+	File bool
+	// WebhookURL receives each mirrored stream as a JSON POST once it ends.
+	WebhookURL string
+	// BufferChunks is how many chunks may wait for the sink before further
+	// chunks are dropped; zero uses DefaultTeeBufferChunks.
+	BufferChunks int
+}
+
+// Enabled reports whether the config names a sink.
+func (c StreamTeeConfig) Enabled() bool {
+	return c.File || strings.TrimSpace(c.WebhookURL) != ""
+}
+
+// TeeStreamInfo describes a mirrored stream.
+type TeeStreamInfo struct {
+	RequestID string    `json:"request_id"`
+	Method    string    `json:"method"`
+	URL       string    `json:"url"`
+	Status    int       `json:"status"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// TeeResult reports what the sink missed of a stream: chunks dropped while
+// it was behind and whether the stream outgrew the sample size limit.
+type TeeResult struct {
+	Dropped   int  `json:"dropped_chunks"`
+	Truncated bool `json:"truncated"`
+}
+
+// TeeSink receives copies of sampled streams.
+type TeeSink interface {
+	// Open starts a mirrored stream.
+	Open(info TeeStreamInfo) (TeeWriter, error)
+}
+
+// TeeWriter receives the redacted chunks of one mirrored stream, in order and
+// from a single goroutine that is never the client's.
+type TeeWriter interface {
+	Write(chunk []byte) error
+	Close(result TeeResult) error
+}
+
+// StreamTee mirrors streams to a sink through a bounded buffer, so a slow or
+// failing sink loses chunks instead of slowing the client.
+type StreamTee struct {
+	sink     TeeSink
+	buffer   int
+	maxBytes int
+}
+
+// NewStreamTee creates a tee forwarding to sink. Each stream buffers up to
+// bufferChunks chunks and mirrors at most maxBytes bytes; zero or negative
+// values use the defaults.
+func NewStreamTee(sink TeeSink, bufferChunks, maxBytes int) *StreamTee {
+	if bufferChunks <= 0 {
+		bufferChunks = DefaultTeeBufferChunks
+	}
+	if maxBytes <= 0 {
+		maxBytes = DefaultSampleMaxBodyBytes
+	}
+	return &StreamTee{sink: sink, buffer: bufferChunks, maxBytes: maxBytes}
+}
+
+// Open starts mirroring a stream. It returns nil when t is nil.
+func (t *StreamTee) Open(info TeeStreamInfo) *TeeStream {
+	if t == nil || t.sink == nil {
+		return nil
+	}
+	s := &TeeStream{
+		info:     info,
+		chunks:   make(chan []byte, t.buffer),
+		done:     make(chan struct{}),
+		maxBytes: t.maxBytes,
+	}
+	go s.forward(t.sink)
+	return s
+}
+
+// TeeStream is one stream being mirrored. Write and Close must be called from
+// the goroutine writing the client response; neither blocks.
+type TeeStream struct {
+	info     TeeStreamInfo
+	chunks   chan []byte
+	done     chan struct{}
+	maxBytes int
+
+	size   int
+	result TeeResult
+	closed bool
+}
+
+// Write queues a copy of chunk for the sink, dropping it when the buffer is
+// full or the stream has reached the size limit.
+func (s *TeeStream) Write(chunk []byte) {
+	if s == nil || s.closed || len(chunk) == 0 {
+		return
+	}
+	if s.size+len(chunk) > s.maxBytes {
+		s.result.Truncated = true
+		return
+	}
+	select {
+	case s.chunks <- append([]byte(nil), chunk...):
+		s.size += len(chunk)
+	default:
+		s.result.Dropped++
+	}
+}
+
+// Close ends the stream. The sink finishes with the buffered chunks in the
+// background.
+func (s *TeeStream) Close() {
+	if s == nil || s.closed {
+		return
+	}
+	s.closed = true
+	close(s.chunks)
+}
+
+// forward hands the buffered chunks, redacted, to the sink. result is read
+// only once chunks is closed, after the last Write.
+func (s *TeeStream) forward(sink TeeSink) {
+	defer close(s.done)
+	w, err := sink.Open(s.info)
+	if err != nil {
+		WithError(err).Warn("failed to open stream tee")
+		for range s.chunks {
+		}
+		return
+	}
+	r := CurrentRedactor()
+	for chunk := range s.chunks {
+		if err == nil {
+			err = w.Write(r.Bytes(chunk))
+		}
+	}
+	if err != nil {
+		WithError(err).Warn("failed to mirror stream")
+	}
+	if err = w.Close(s.result); err != nil {
+		WithError(err).Warn("failed to close stream tee")
+	}
+}
+
+// newTeeSink builds the sinks named by cfg; dir receives the stream files.
+func newTeeSink(cfg StreamTeeConfig, dir string) TeeSink {
+	var sinks multiTeeSink
+	if cfg.File {
+		sinks = append(sinks, fileTeeSink{dir: dir})
+	}
+	if url := strings.TrimSpace(cfg.WebhookURL); url != "" {
+		sinks = append(sinks, &webhookTeeSink{url: url, client: &http.Client{Timeout: teeWebhookTimeout}})
+	}
+	switch len(sinks) {
+	case 0:
+		return nil
+	case 1:
+		return sinks[0]
+	}
+	return sinks
+}
+
+// fileTeeSink writes each stream to a file in dir as its chunks arrive.
+type fileTeeSink struct {
+	dir string
+}
+
+func (f fileTeeSink) Open(info TeeStreamInfo) (TeeWriter, error) {
+	if err := os.MkdirAll(f.dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create stream tee directory: %w", err)
+	}
+	name := fmt.Sprintf("stream-%s-%s.log", info.StartedAt.Format("20060102-150405.000000"), sanitizeSampleID(info.RequestID))
+	file, err := os.OpenFile(filepath.Join(f.dir, name), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stream tee file: %w", err)
+	}
+	r := CurrentRedactor()
+	header := fmt.Sprintf("=== STREAM ===\nRequest ID: %s\nURL: %s\nMethod: %s\nStatus: %d\nTimestamp: %s\n\n",
+		info.RequestID, r.String(info.URL), info.Method, info.Status, info.StartedAt.Format(time.RFC3339Nano))
+	if _, err = file.WriteString(header); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return fileTeeWriter{file: file}, nil
+}
+
+type fileTeeWriter struct {
+	file *os.File
+}
+
+func (w fileTeeWriter) Write(chunk []byte) error {
+	_, err := w.file.Write(chunk)
+	return err
+}
+
+func (w fileTeeWriter) Close(result TeeResult) error {
+	_, err := fmt.Fprintf(w.file, "\n=== END (dropped chunks: %d, truncated: %t) ===\n", result.Dropped, result.Truncated)
+	if errClose := w.file.Close(); err == nil {
+		err = errClose
+	}
+	return err
+}
+
+// webhookTeeSink posts each stream to a URL once it ends.
+type webhookTeeSink struct {
+	url    string
+	client *http.Client
+}
+
+func (h *webhookTeeSink) Open(info TeeStreamInfo) (TeeWriter, error) {
+	return &webhookTeeWriter{sink: h, info: info}, nil
+}
+
+type webhookTeeWriter struct {
+	sink *webhookTeeSink
+	info TeeStreamInfo
+	body bytes.Buffer
+}
+
+func (w *webhookTeeWriter) Write(chunk []byte) error {
+	w.body.Write(chunk)
+	return nil
+}
+
+func (w *webhookTeeWriter) Close(result TeeResult) error {
+	payload, err := json.Marshal(struct {
+		TeeStreamInfo
+		TeeResult
+		Body string `json:"body"`
+	}{w.info, result, w.body.String()})
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), teeWebhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.sink.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.sink.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("stream tee webhook: %w", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("stream tee webhook: status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// multiTeeSink mirrors to several sinks; a sink that fails to open is skipped.
+type multiTeeSink []TeeSink
+
+func (m multiTeeSink) Open(info TeeStreamInfo) (TeeWriter, error) {
+	var writers multiTeeWriter
+	var firstErr error
+	for _, sink := range m {
+		w, err := sink.Open(info)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		writers = append(writers, w)
+	}
+	if len(writers) == 0 {
+		return nil, firstErr
+	}
+	return writers, nil
+}
+
+type multiTeeWriter []TeeWriter
+
+func (m multiTeeWriter) Write(chunk []byte) error {
+	var firstErr error
+	for _, w := range m {
+		if err := w.Write(chunk); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (m multiTeeWriter) Close(result TeeResult) error {
+	var firstErr error
+	for _, w := range m {
+		if err := w.Close(result); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,73 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordingTeeSink keeps what it receives and reports each finished stream.
+type recordingTeeSink struct {
+	chunks []string
+	done   chan TeeResult
+}
+
+func (s *recordingTeeSink) Open(TeeStreamInfo) (TeeWriter, error) { return s, nil }
+
+func (s *recordingTeeSink) Write(chunk []byte) error {
+	s.chunks = append(s.chunks, string(chunk))
+	return nil
+}
+
+func (s *recordingTeeSink) Close(result TeeResult) error {
+	s.done <- result
+	return nil
+}
+
+func TestStreamTeeRedactsAndTruncates(t *testing.T) {
+	sink := &recordingTeeSink{done: make(chan TeeResult, 1)}
+	stream := NewStreamTee(sink, 0, 64).Open(TeeStreamInfo{RequestID: "req-1"})
+	stream.Write([]byte(`data: {"access_token":"ya29.secret-tee-token"}` + "\n\n"))
+	stream.Write([]byte(strings.Repeat("x", 64)))
+	stream.Close()
+
+	select {
+	case result := <-sink.done:
+		if !result.Truncated || result.Dropped != 0 {
+			t.Errorf("result = %+v, want truncated without drops", result)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("sink never closed")
+	}
+	if len(sink.chunks) != 1 || strings.Contains(sink.chunks[0], "secret-tee-token") {
+		t.Fatalf("sink received %q, want one redacted chunk", sink.chunks)
+	}
+}
+
+func TestBodySamplerStreamTeeFile(t *testing.T) {
+	dir := t.TempDir()
+	s := NewBodySampler(dir)
+	if s.OpenStreamTee(TeeStreamInfo{}) != nil {
+		t.Fatal("stream tee open without a sink")
+	}
+	s.SetConfig(SamplingConfig{Tee: StreamTeeConfig{File: true}})
+	stream := s.OpenStreamTee(TeeStreamInfo{RequestID: "req-2", Method: "POST", URL: "/v1/messages", Status: 200, StartedAt: time.Now()})
+	stream.Write([]byte("data: one\n\n"))
+	stream.Write([]byte("data: two\n\n"))
+	stream.Close()
+	<-stream.done
+
+	paths, _ := filepath.Glob(filepath.Join(dir, "streams", "stream-*-req-2.log"))
+	if len(paths) != 1 {
+		t.Fatalf("found %d stream files, want 1", len(paths))
+	}
+	data, _ := os.ReadFile(paths[0])
+	content := string(data)
+	for _, want := range []string{"URL: /v1/messages", "data: one\n\ndata: two\n\n", "dropped chunks: 0"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("stream file lacks %q:\n%s", want, content)
+		}
+	}
+}
@@ -2,10 +2,12 @@ package oauth
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
 	"sync"
+	"syscall"
 	"time"
 
 	log "github.com/nghyane/llm-mux/internal/logging"
@@ -16,10 +18,18 @@ type ProviderConfig struct {
 	Name         string // Provider identifier (claude, gemini, codex, etc.)
 	Port         int    // Fixed callback port registered with OAuth provider
 	CallbackPath string // Path component of callback URL
+	// AnyLoopbackPort is true when the provider accepts a redirect URI on any
+	// loopback port, so the callback port may be moved.
+	AnyLoopbackPort bool
 }
 
+// ErrCallbackPortInUse is returned when a callback port is held by another
+// process or by a login flow with a different target.
+var ErrCallbackPortInUse = errors.New("OAuth callback port already in use")
+
 // Pre-defined provider configurations with fixed ports.
-// These CANNOT be changed - they are registered with OAuth providers.
+// Only ports of providers with AnyLoopbackPort can be changed; the others are
+// registered with the OAuth providers.
 var ProviderConfigs = map[string]ProviderConfig{
 	"claude": {
 		Name:         "claude",
@@ -37,14 +47,16 @@ var ProviderConfigs = map[string]ProviderConfig{
 		CallbackPath: "/auth/callback",
 	},
 	"gemini": {
-		Name:         "gemini",
-		Port:         8085,
-		CallbackPath: "/oauth2callback",
+		Name:            "gemini",
+		Port:            8085,
+		CallbackPath:    "/oauth2callback",
+		AnyLoopbackPort: true,
 	},
 	"gemini-cli": {
-		Name:         "gemini-cli",
-		Port:         8085,
-		CallbackPath: "/oauth2callback",
+		Name:            "gemini-cli",
+		Port:            8085,
+		CallbackPath:    "/oauth2callback",
+		AnyLoopbackPort: true,
 	},
 	"iflow": {
 		Name:         "iflow",
@@ -52,9 +64,10 @@ var ProviderConfigs = map[string]ProviderConfig{
 		CallbackPath: "/oauth2callback",
 	},
 	"antigravity": {
-		Name:         "antigravity",
-		Port:         51121,
-		CallbackPath: "/oauth-callback",
+		Name:            "antigravity",
+		Port:            51121,
+		CallbackPath:    "/oauth-callback",
+		AnyLoopbackPort: true,
 	},
 }
 
@@ -270,6 +283,16 @@ func GetRedirectURI(provider string) string {
 	return fmt.Sprintf("http://localhost:%d%s", config.Port, config.CallbackPath)
 }
 
+// GetRedirectURIForPort returns the redirect URI for a provider whose callback
+// is served on port.
+func GetRedirectURIForPort(provider string, port int) string {
+	config, ok := ProviderConfigs[provider]
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("http://localhost:%d%s", port, config.CallbackPath)
+}
+
 // GetCallbackPort returns the callback port for a provider.
 func GetCallbackPort(provider string) int {
 	config, ok := ProviderConfigs[provider]
@@ -280,11 +303,20 @@ func GetCallbackPort(provider string) int {
 }
 
 // ForwarderServer represents a temporary HTTP server that forwards OAuth callbacks.
+// Concurrent flows for the same port share one forwarder, which stops when the
+// last of them releases it.
 type ForwarderServer struct {
 	port     int
 	provider string
+	target   string
 	server   *http.Server
 	done     chan struct{}
+	refs     int // guarded by forwardersMu
+}
+
+// Port returns the port the forwarder listens on.
+func (f *ForwarderServer) Port() int {
+	return f.port
 }
 
 // forwarders holds active forwarder servers (separate from persistent callback servers).
@@ -295,24 +327,31 @@ var (
 
 // StartForwarder starts a temporary HTTP server that redirects OAuth callbacks to the target URL.
 // This is used for WebUI mode where callbacks need to be forwarded to the main server port.
-// Returns the forwarder instance and any error.
+// A forwarder already running on port for the same target is shared; each call
+// must be paired with ReleaseForwarder. Port 0 binds an ephemeral port, see
+// ForwarderServer.Port. A port held elsewhere returns ErrCallbackPortInUse.
 func (m *CallbackServersManager) StartForwarder(port int, provider, targetBase string) (*ForwarderServer, error) {
 	forwardersMu.Lock()
-	prev := forwarders[port]
-	if prev != nil {
-		delete(forwarders, port)
-	}
-	forwardersMu.Unlock()
+	defer forwardersMu.Unlock()
 
-	if prev != nil {
-		m.stopForwarderInstance(prev)
+	if prev := forwarders[port]; prev != nil && port != 0 {
+		if prev.target != targetBase {
+			return nil, fmt.Errorf("%w: port %d forwards %s callbacks", ErrCallbackPortInUse, port, prev.provider)
+		}
+		prev.refs++
+		return prev, nil
 	}
 
 	addr := fmt.Sprintf("127.0.0.1:%d", port)
 	ln, err := net.Listen("tcp", addr)
 	if err != nil {
+		if errors.Is(err, syscall.EADDRINUSE) {
+			return nil, fmt.Errorf("%w: %s is held by another process", ErrCallbackPortInUse, addr)
+		}
 		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
 	}
+	port = ln.Addr().(*net.TCPAddr).Port
+	addr = ln.Addr().String()
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		target := targetBase
@@ -346,13 +385,12 @@ func (m *CallbackServersManager) StartForwarder(port int, provider, targetBase s
 	forwarder := &ForwarderServer{
 		port:     port,
 		provider: provider,
+		target:   targetBase,
 		server:   srv,
 		done:     done,
+		refs:     1,
 	}
-
-	forwardersMu.Lock()
 	forwarders[port] = forwarder
-	forwardersMu.Unlock()
 
 	log.Infof("callback forwarder for %s listening on %s", provider, addr)
 
@@ -371,6 +409,24 @@ func (m *CallbackServersManager) StopForwarder(port int) {
 	m.stopForwarderInstance(forwarder)
 }
 
+// ReleaseForwarder drops one reference to forwarder taken by StartForwarder
+// and stops it once no flow uses it.
+func (m *CallbackServersManager) ReleaseForwarder(forwarder *ForwarderServer) {
+	if forwarder == nil {
+		return
+	}
+	forwardersMu.Lock()
+	forwarder.refs--
+	if forwarder.refs > 0 || forwarders[forwarder.port] != forwarder {
+		forwardersMu.Unlock()
+		return
+	}
+	delete(forwarders, forwarder.port)
+	forwardersMu.Unlock()
+
+	m.stopForwarderInstance(forwarder)
+}
+
 // stopForwarderInstance gracefully stops a forwarder server.
 func (m *CallbackServersManager) stopForwarderInstance(forwarder *ForwarderServer) {
 	if forwarder == nil || forwarder.server == nil {
@@ -0,0 +1,64 @@
+package oauth
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+)
+
+func TestStartForwarder_PortInUse(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	m := NewCallbackServersManager()
+	if _, err := m.StartForwarder(port, "gemini", "http://127.0.0.1:1/gemini/callback"); !errors.Is(err, ErrCallbackPortInUse) {
+		t.Fatalf("err = %v, want ErrCallbackPortInUse", err)
+	}
+}
+
+func TestStartForwarder_SharedAcrossFlows(t *testing.T) {
+	m := NewCallbackServersManager()
+	target := "http://127.0.0.1:1/gemini/callback"
+
+	first, err := m.StartForwarder(0, "gemini", target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := first.Port()
+	if port == 0 {
+		t.Fatal("ephemeral forwarder reports port 0")
+	}
+
+	second, err := m.StartForwarder(port, "gemini", target)
+	if err != nil || second != first {
+		t.Fatalf("second flow got %v, %v; want the running forwarder", second, err)
+	}
+	if _, err := m.StartForwarder(port, "iflow", "http://127.0.0.1:1/iflow/callback"); !errors.Is(err, ErrCallbackPortInUse) {
+		t.Fatalf("other target err = %v, want ErrCallbackPortInUse", err)
+	}
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	url := fmt.Sprintf("http://127.0.0.1:%d/oauth2callback?code=c&state=s", port)
+
+	m.ReleaseForwarder(first)
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatalf("forwarder stopped while a flow still uses it: %v", err)
+	}
+	resp.Body.Close()
+	if got := resp.Header.Get("Location"); got != target+"?code=c&state=s" {
+		t.Errorf("redirect = %q", got)
+	}
+
+	m.ReleaseForwarder(second)
+	if resp, err := client.Get(url); err == nil {
+		resp.Body.Close()
+		t.Error("forwarder still serving after the last flow released it")
+	}
+}
@@ -38,15 +38,16 @@ type OAuthResult struct {
 
 // OAuthRequest represents a pending OAuth authentication request.
 type OAuthRequest struct {
-	ID        string        // Unique identifier for this request
-	State     string        // OAuth state parameter (used as key)
-	Provider  string        // Provider name (claude, gemini, codex, etc.)
-	Mode      RequestMode   // CLI or WebUI
-	Status    RequestStatus // Current status
-	Error     string        // Error message if failed
-	AuthURL   string        // Full authorization URL to open in browser
-	CreatedAt time.Time     // When the request was created
-	ExpiresAt time.Time     // When the request expires (TTL)
+	ID         string        // Unique identifier for this request
+	State      string        // OAuth state parameter (used as key)
+	Provider   string        // Provider name (claude, gemini, codex, etc.)
+	Mode       RequestMode   // CLI or WebUI
+	Status     RequestStatus // Current status
+	Error      string        // Error message if failed
+	AuthURL    string        // Full authorization URL to open in browser
+	CreatedAt  time.Time     // When the request was created
+	ExpiresAt  time.Time     // When the request expires (TTL)
+	FinishedAt time.Time     // When the request left the pending status
 
 	// ResultChan receives the OAuth callback result.
 	// CLI mode blocks on this channel; WebUI mode uses it for internal signaling.
@@ -60,8 +61,22 @@ type OAuthRequest struct {
 	// Additional metadata
 	RedirectURI string
 	Scopes      []string
+
+	// release frees the resources of the flow once the request finishes.
+	release func()
 }
 
+const (
+	// DefaultRequestTTL is how long a request may stay pending before it
+	// expires. It covers the slowest flow, device authorization.
+	DefaultRequestTTL = 10 * time.Minute
+	// DefaultTombstoneTTL is how long a finished request is kept so its status
+	// can still be queried.
+	DefaultTombstoneTTL = 10 * time.Minute
+	// sweepInterval is how often expired requests are swept.
+	sweepInterval = 30 * time.Second
+)
+
 // Registry manages pending OAuth requests with thread-safe access.
 type Registry struct {
 	mu       sync.RWMutex
@@ -69,21 +84,34 @@ type Registry struct {
 	byID     map[string]*OAuthRequest // secondary index by ID
 
 	// Configuration
-	defaultTTL time.Duration
+	defaultTTL   time.Duration
+	tombstoneTTL time.Duration
+	now          func() time.Time
+
+	stop     chan struct{}
+	stopOnce sync.Once
 }
 
-// NewRegistry creates a new OAuth request registry.
+// NewRegistry creates a new OAuth request registry and starts the sweeper
+// that expires abandoned requests.
 func NewRegistry() *Registry {
 	r := &Registry{
-		requests:   make(map[string]*OAuthRequest),
-		byID:       make(map[string]*OAuthRequest),
-		defaultTTL: 5 * time.Minute,
+		requests:     make(map[string]*OAuthRequest),
+		byID:         make(map[string]*OAuthRequest),
+		defaultTTL:   DefaultRequestTTL,
+		tombstoneTTL: DefaultTombstoneTTL,
+		now:          time.Now,
+		stop:         make(chan struct{}),
 	}
-	// Start cleanup goroutine
-	go r.cleanupLoop()
+	go r.sweepLoop()
 	return r
 }
 
+// Close stops the sweeper.
+func (r *Registry) Close() {
+	r.stopOnce.Do(func() { close(r.stop) })
+}
+
 // Register creates and stores a new OAuth request.
 func (r *Registry) Register(provider string, mode RequestMode) (*OAuthRequest, error) {
 	state, err := misc.GenerateRandomState()
@@ -96,7 +124,7 @@ func (r *Registry) Register(provider string, mode RequestMode) (*OAuthRequest, e
 		return nil, err
 	}
 
-	now := time.Now()
+	now := r.now()
 	req := &OAuthRequest{
 		ID:         id,
 		State:      state,
@@ -133,6 +161,8 @@ func (r *Registry) GetByID(id string) *OAuthRequest {
 // Complete marks a request as completed with the given result.
 // Holds lock through channel send to prevent TOCTOU race with Remove().
 func (r *Registry) Complete(state string, result *OAuthResult) bool {
+	var release func()
+	defer runRelease(&release)
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -141,6 +171,7 @@ func (r *Registry) Complete(state string, result *OAuthResult) bool {
 		return false
 	}
 	req.Status = StatusCompleted
+	release = r.finish(req)
 
 	// Send result to channel while holding lock (non-blocking due to buffer)
 	select {
@@ -155,17 +186,22 @@ func (r *Registry) Complete(state string, result *OAuthResult) bool {
 // Fail marks a request as failed with an error message.
 // Holds lock through channel send to prevent TOCTOU race with Remove().
 func (r *Registry) Fail(state string, errMsg string) bool {
+	var release func()
+	defer runRelease(&release)
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
 	req, exists := r.requests[state]
 	if !exists {
 		// Create a new request if it doesn't exist (for backward compatibility)
+		now := r.now()
 		req = &OAuthRequest{
 			ID:         state,
 			State:      state,
 			Status:     StatusFailed,
 			Error:      errMsg,
+			CreatedAt:  now,
+			FinishedAt: now,
 			ResultChan: make(chan *OAuthResult, 1),
 		}
 		r.requests[state] = req
@@ -174,6 +210,7 @@ func (r *Registry) Fail(state string, errMsg string) bool {
 	}
 	req.Status = StatusFailed
 	req.Error = errMsg
+	release = r.finish(req)
 
 	// Send error result to channel while holding lock
 	select {
@@ -187,6 +224,8 @@ func (r *Registry) Fail(state string, errMsg string) bool {
 // Cancel cancels a pending request.
 // Holds lock through channel send to prevent TOCTOU race with Remove().
 func (r *Registry) Cancel(state string) bool {
+	var release func()
+	defer runRelease(&release)
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -196,6 +235,7 @@ func (r *Registry) Cancel(state string) bool {
 	}
 	req.Status = StatusCancelled
 	req.Error = "cancelled"
+	release = r.finish(req)
 
 	// Send cancellation to channel while holding lock
 	select {
@@ -208,6 +248,8 @@ func (r *Registry) Cancel(state string) bool {
 
 // Remove deletes a request from the registry.
 func (r *Registry) Remove(state string) {
+	var release func()
+	defer runRelease(&release)
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -218,6 +260,8 @@ func (r *Registry) Remove(state string) {
 
 	delete(r.requests, state)
 	delete(r.byID, req.ID)
+	release = req.release
+	req.release = nil
 
 	// Safe channel close using atomic flag to prevent double close panic
 	if req.channelClosed.CompareAndSwap(false, true) {
@@ -237,31 +281,81 @@ func (r *Registry) GetStatus(state string) (RequestStatus, bool) {
 	return req.Status, true
 }
 
-// cleanupLoop periodically removes expired requests.
-func (r *Registry) cleanupLoop() {
-	ticker := time.NewTicker(30 * time.Second)
+// OnRelease registers fn to free the resources of the flow behind state, such
+// as its polling goroutine and callback forwarder. fn runs once, when the
+// request completes, fails, is cancelled, expires or is removed; it runs right
+// away if the request is unknown or already finished.
+func (r *Registry) OnRelease(state string, fn func()) {
+	if fn == nil {
+		return
+	}
+	r.mu.Lock()
+	req, exists := r.requests[state]
+	if !exists || req.Status != StatusPending {
+		r.mu.Unlock()
+		fn()
+		return
+	}
+	if prev := req.release; prev != nil {
+		req.release = func() { prev(); fn() }
+	} else {
+		req.release = fn
+	}
+	r.mu.Unlock()
+}
+
+// finish marks req as finished and returns its release hook; r.mu must be held.
+// The tombstone is kept for tombstoneTTL so the status stays queryable.
+func (r *Registry) finish(req *OAuthRequest) func() {
+	req.FinishedAt = r.now()
+	release := req.release
+	req.release = nil
+	return release
+}
+
+// runRelease calls the release hook taken under the lock, once it is released.
+func runRelease(release *func()) {
+	if *release != nil {
+		(*release)()
+	}
+}
+
+// sweepLoop periodically expires abandoned requests until Close is called.
+func (r *Registry) sweepLoop() {
+	ticker := time.NewTicker(sweepInterval)
 	defer ticker.Stop()
 
-	for range ticker.C {
-		r.cleanup()
+	for {
+		select {
+		case <-ticker.C:
+			r.sweep()
+		case <-r.stop:
+			return
+		}
 	}
 }
 
-// cleanup removes expired requests.
-// Uses single write lock to prevent race conditions.
-func (r *Registry) cleanup() {
-	now := time.Now()
+// sweep marks pending requests past their TTL as expired and releases their
+// resources, then drops finished requests whose tombstone is older than
+// tombstoneTTL. It returns the number of requests expired.
+func (r *Registry) sweep() int {
+	var releases []func()
+	defer func() {
+		for _, release := range releases {
+			release()
+		}
+	}()
 
+	now := r.now()
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	expired := 0
 	for state, req := range r.requests {
-		if !now.After(req.ExpiresAt) {
-			continue
-		}
-
-		// Only expire pending requests
 		if req.Status == StatusPending {
+			if !now.After(req.ExpiresAt) {
+				continue
+			}
 			req.Status = StatusExpired
 			req.Error = "expired"
 			// Send expiry notification (non-blocking)
@@ -269,18 +363,28 @@ func (r *Registry) cleanup() {
 			case req.ResultChan <- &OAuthResult{State: state, Error: "expired"}:
 			default:
 			}
+			if release := r.finish(req); release != nil {
+				releases = append(releases, release)
+			}
+			expired++
+			continue
 		}
 
-		// Remove from maps
-		delete(r.requests, state)
-		delete(r.byID, req.ID)
+		if now.After(req.FinishedAt.Add(r.tombstoneTTL)) {
+			delete(r.requests, state)
+			delete(r.byID, req.ID)
+			if req.channelClosed.CompareAndSwap(false, true) {
+				close(req.ResultChan)
+			}
+		}
 	}
+	return expired
 }
 
 // Create creates a new OAuth request with a given state.
 // Used to explicitly set the state parameter during OAuth flow initiation.
 func (r *Registry) Create(state, provider string, mode RequestMode) *OAuthRequest {
-	now := time.Now()
+	now := r.now()
 	id := state // Use state as ID for simplicity
 
 	req := &OAuthRequest{
@@ -318,6 +422,7 @@ func (r *Registry) Stats() map[string]int {
 		"pending":   0,
 		"completed": 0,
 		"failed":    0,
+		"expired":   0,
 	}
 
 	for _, req := range r.requests {
@@ -326,7 +431,9 @@ func (r *Registry) Stats() map[string]int {
 			stats["pending"]++
 		case StatusCompleted:
 			stats["completed"]++
-		case StatusFailed, StatusExpired, StatusCancelled:
+		case StatusExpired:
+			stats["expired"]++
+		case StatusFailed, StatusCancelled:
 			stats["failed"]++
 		}
 	}
@@ -0,0 +1,84 @@
+package oauth
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRegistry_SweepExpiresAbandonedState(t *testing.T) {
+	r := NewRegistry()
+	defer r.Close()
+	now := time.Unix(1_700_000_000, 0)
+	r.now = func() time.Time { return now }
+	svc := &Service{registry: r}
+
+	req := r.Create("abandoned", "claude", ModeWebUI)
+	ctx, cancel := context.WithCancel(context.Background())
+	released := 0
+	r.OnRelease("abandoned", func() {
+		released++
+		cancel()
+	})
+
+	now = now.Add(DefaultRequestTTL - time.Second)
+	if n := r.sweep(); n != 0 {
+		t.Fatalf("swept %d requests before the TTL", n)
+	}
+
+	now = now.Add(2 * time.Second)
+	if n := r.sweep(); n != 1 {
+		t.Fatalf("swept %d requests, want 1", n)
+	}
+	if released != 1 || ctx.Err() == nil {
+		t.Fatalf("release ran %d times, ctx err %v; want the flow released", released, ctx.Err())
+	}
+	select {
+	case result := <-req.ResultChan:
+		if result.Error != "expired" {
+			t.Errorf("result error = %q, want expired", result.Error)
+		}
+	default:
+		t.Error("no expiry result sent to the waiting flow")
+	}
+
+	resp, err := svc.GetStatus("abandoned")
+	if err != nil || resp.Status != string(StatusExpired) {
+		t.Fatalf("status = %+v, %v; want expired", resp, err)
+	}
+	// The poller noticing its cancelled context must not overwrite the status.
+	if r.Cancel("abandoned") {
+		t.Error("cancelled an expired request")
+	}
+	r.sweep()
+	if released != 1 {
+		t.Errorf("release ran %d times, want once", released)
+	}
+
+	now = now.Add(DefaultTombstoneTTL + time.Second)
+	r.sweep()
+	if _, err := svc.GetStatus("abandoned"); err == nil {
+		t.Error("tombstone kept past its TTL")
+	}
+}
+
+func TestRegistry_OnReleaseRunsOnFinish(t *testing.T) {
+	r := NewRegistry()
+	defer r.Close()
+
+	r.Create("done", "codex", ModeWebUI)
+	released := false
+	r.OnRelease("done", func() { released = true })
+	r.Complete("done", &OAuthResult{State: "done", Code: "success"})
+	if !released {
+		t.Fatal("completing a request did not release it")
+	}
+
+	// Hooks registered for a finished or unknown state run right away.
+	late := 0
+	r.OnRelease("done", func() { late++ })
+	r.OnRelease("missing", func() { late++ })
+	if late != 2 {
+		t.Errorf("late hooks ran %d times, want 2", late)
+	}
+}
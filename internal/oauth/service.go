@@ -174,9 +174,13 @@ func (s *Service) WaitForCallback(ctx context.Context, state string, timeout tim
 	}
 }
 
-// GetStatus returns the current status of an OAuth request.
+// GetStatus returns the current status of an OAuth request. Requests swept
+// after their TTL report StatusExpired until their tombstone is dropped.
 func (s *Service) GetStatus(state string) (*StatusResponse, error) {
-	req := s.registry.Get(state)
+	s.registry.mu.RLock()
+	defer s.registry.mu.RUnlock()
+
+	req := s.registry.requests[state]
 	if req == nil {
 		return nil, fmt.Errorf("unknown OAuth state: %s", state)
 	}
@@ -186,6 +190,7 @@ func (s *Service) GetStatus(state string) (*StatusResponse, error) {
 		Provider: req.Provider,
 		Status:   string(req.Status),
 		Mode:     string(req.Mode),
+		Error:    req.Error,
 	}, nil
 }
 
@@ -215,6 +220,10 @@ func (s *Service) handleCallback(provider, code, state, errStr string) string {
 		log.Warnf("OAuth callback received with unknown state: %s", state)
 		return HTMLError("Invalid or expired authentication request")
 	}
+	if status, _ := s.registry.GetStatus(state); status == StatusExpired {
+		log.Warnf("OAuth callback received for expired state: %s", state)
+		return HTMLError("Authentication request expired, please start again")
+	}
 
 	// Handle error from OAuth provider
 	if errStr != "" {
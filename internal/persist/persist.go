@@ -0,0 +1,96 @@
+// Package persist writes state files atomically and keeps them up to date
+// with a periodic save loop.
+package persist
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	log "github.com/nghyane/llm-mux/internal/logging"
+)
+
+// WriteFile writes data to path through a temporary file and a rename, so
+// readers never observe a partial file. Missing parent directories are
+// created.
+func WriteFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// Loop calls a save function every interval until stopped. The zero value is
+// a stopped loop.
+type Loop struct {
+	mu   sync.Mutex
+	path string
+	save func(path string) error
+	stop chan struct{}
+	done chan struct{}
+}
+
+// Running reports whether the loop has been started and not yet stopped.
+func (l *Loop) Running() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.stop != nil
+}
+
+// Start calls save(path) every interval, logging failures with what naming
+// the saved state. It returns false without doing anything when the loop is
+// already running.
+func (l *Loop) Start(path string, interval time.Duration, what string, save func(path string) error) bool {
+	l.mu.Lock()
+	if l.stop != nil {
+		l.mu.Unlock()
+		return false
+	}
+	l.path, l.save = path, save
+	l.stop = make(chan struct{})
+	l.done = make(chan struct{})
+	stop, done := l.stop, l.done
+	l.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := save(path); err != nil {
+					log.Warnf("Failed to save %s: %v", what, err)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return true
+}
+
+// Stop ends the loop, waiting for a save in progress, and saves one last
+// time. It does nothing when the loop is not running.
+func (l *Loop) Stop() error {
+	l.mu.Lock()
+	stop, done, path, save := l.stop, l.done, l.path, l.save
+	l.stop, l.done = nil, nil
+	l.mu.Unlock()
+	if stop == nil {
+		return nil
+	}
+	close(stop)
+	<-done
+	return save(path)
+}
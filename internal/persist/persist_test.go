@@ -0,0 +1,72 @@
+package persist
+
+import (
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWriteFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "state.json")
+	for _, data := range []string{`{"v":1}`, `{"v":2}`} {
+		if err := WriteFile(path, []byte(data)); err != nil {
+			t.Fatal(err)
+		}
+		got, err := os.ReadFile(path)
+		if err != nil || string(got) != data {
+			t.Fatalf("read %q, %v; want %q", got, err, data)
+		}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("mode = %o, want 600", perm)
+	}
+	if _, err = os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("temporary file left behind: %v", err)
+	}
+}
+
+func TestLoop(t *testing.T) {
+	var saves atomic.Int32
+	var lastPath atomic.Value
+	save := func(path string) error {
+		saves.Add(1)
+		lastPath.Store(path)
+		return nil
+	}
+
+	var l Loop
+	if err := l.Stop(); err != nil || saves.Load() != 0 {
+		t.Fatalf("stopping an idle loop: err %v, %d saves", err, saves.Load())
+	}
+	if !l.Start("state.json", 5*time.Millisecond, "state", save) {
+		t.Fatal("Start returned false on an idle loop")
+	}
+	if l.Start("other.json", time.Millisecond, "state", save) || !l.Running() {
+		t.Fatal("a second Start must leave the running loop alone")
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for saves.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if saves.Load() == 0 {
+		t.Fatal("loop never saved")
+	}
+
+	if err := l.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	after := saves.Load()
+	if l.Running() || lastPath.Load() != "state.json" {
+		t.Errorf("running %v, last save to %v", l.Running(), lastPath.Load())
+	}
+	time.Sleep(20 * time.Millisecond)
+	if saves.Load() != after {
+		t.Error("loop saved after Stop returned")
+	}
+}
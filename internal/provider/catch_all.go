@@ -0,0 +1,22 @@
+package provider
+
+// catchAllMetadataKey marks a request routed by the unknown-model catch-all
+// in request metadata.
+const catchAllMetadataKey = "llm_mux_catch_all"
+
+// MarkCatchAll records in meta that the request was routed to a provider
+// that does not list its model, so any of the provider's accounts may serve
+// it. It returns meta, allocated when nil.
+func MarkCatchAll(meta map[string]any) map[string]any {
+	if meta == nil {
+		meta = make(map[string]any, 1)
+	}
+	meta[catchAllMetadataKey] = true
+	return meta
+}
+
+// IsCatchAll reports whether MarkCatchAll was applied to meta.
+func IsCatchAll(meta map[string]any) bool {
+	v, _ := meta[catchAllMetadataKey].(bool)
+	return v
+}
@@ -0,0 +1,317 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/json"
+)
+
+// ConcurrencyLimit caps the requests each account of a provider serves at
+// once. With a queue, requests arriving while every account is busy wait up
+// to QueueWait for a slot, at most QueueDepth of them at a time; without one
+// they fail at once. A zero PerAccount leaves the provider unlimited.
+type ConcurrencyLimit struct {
+	PerAccount int
+	QueueDepth int
+	QueueWait  time.Duration
+}
+
+// ConcurrencyLimitFunc returns the concurrency limit of a provider.
+type ConcurrencyLimitFunc func(provider string) ConcurrencyLimit
+
+// QueueStatus reports the requests in flight for a provider and its queue.
+// Wait times cover the requests that got a slot after queueing.
+type QueueStatus struct {
+	InFlight  int     `json:"in_flight"`
+	Depth     int     `json:"depth"`
+	MaxDepth  int     `json:"max_depth"`
+	Queued    int64   `json:"queued"`
+	TimedOut  int64   `json:"timed_out"`
+	Rejected  int64   `json:"rejected"`
+	AvgWaitMs float64 `json:"avg_wait_ms"`
+	MaxWaitMs float64 `json:"max_wait_ms"`
+}
+
+// providerSlots holds the slots in use on a provider's accounts and the
+// requests waiting for one.
+type providerSlots struct {
+	inFlight map[string]int
+	total    int
+	depth    int
+	// released is closed and replaced whenever a slot frees up.
+	released chan struct{}
+
+	queued, timedOut, rejected, served int64
+	totalWait, maxWait                 time.Duration
+}
+
+// concurrencyTracker counts the requests in flight per account and the
+// requests waiting per provider.
+type concurrencyTracker struct {
+	mu     sync.Mutex
+	limit  ConcurrencyLimitFunc
+	queues map[string]*providerSlots
+}
+
+func newConcurrencyTracker() *concurrencyTracker {
+	return &concurrencyTracker{queues: make(map[string]*providerSlots)}
+}
+
+// SetConcurrencyLimits sets the per-account concurrency caps and queues. A nil
+// func removes every cap; requests in flight keep their slots.
+func (m *Manager) SetConcurrencyLimits(fn ConcurrencyLimitFunc) {
+	if m == nil {
+		return
+	}
+	m.concurrency.mu.Lock()
+	m.concurrency.limit = fn
+	m.concurrency.mu.Unlock()
+}
+
+// QueueStats reports in-flight requests and queueing for every provider that
+// has had a concurrency limit.
+func (m *Manager) QueueStats() map[string]QueueStatus {
+	t := m.concurrency
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make(map[string]QueueStatus, len(t.queues))
+	for provider, q := range t.queues {
+		status := QueueStatus{
+			InFlight:  q.total,
+			Depth:     q.depth,
+			MaxDepth:  t.limitLocked(provider).QueueDepth,
+			Queued:    q.queued,
+			TimedOut:  q.timedOut,
+			Rejected:  q.rejected,
+			MaxWaitMs: float64(q.maxWait) / float64(time.Millisecond),
+		}
+		if q.served > 0 {
+			status.AvgWaitMs = float64(q.totalWait) / float64(q.served) / float64(time.Millisecond)
+		}
+		out[provider] = status
+	}
+	return out
+}
+
+func (t *concurrencyTracker) limitLocked(provider string) ConcurrencyLimit {
+	if t.limit == nil {
+		return ConcurrencyLimit{}
+	}
+	return t.limit(provider)
+}
+
+func (t *concurrencyTracker) queueLocked(provider string) *providerSlots {
+	q := t.queues[provider]
+	if q == nil {
+		q = &providerSlots{inFlight: make(map[string]int), released: make(chan struct{})}
+		t.queues[provider] = q
+	}
+	return q
+}
+
+// full reports whether auth serves as many requests as its provider allows.
+func (t *concurrencyTracker) full(auth *Auth) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	limit := t.limitLocked(auth.Provider)
+	if limit.PerAccount <= 0 {
+		return false
+	}
+	q := t.queues[auth.Provider]
+	return q != nil && q.inFlight[auth.ID] >= limit.PerAccount
+}
+
+// acquire takes a slot on auth. It fails when another request took the last
+// one since auth was picked. The returned func frees the slot.
+func (t *concurrencyTracker) acquire(auth *Auth) (func(), bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	limit := t.limitLocked(auth.Provider)
+	if limit.PerAccount <= 0 {
+		return func() {}, true
+	}
+	q := t.queueLocked(auth.Provider)
+	if q.inFlight[auth.ID] >= limit.PerAccount {
+		return nil, false
+	}
+	q.inFlight[auth.ID]++
+	q.total++
+	var once sync.Once
+	return func() { once.Do(func() { t.release(auth.Provider, auth.ID) }) }, true
+}
+
+func (t *concurrencyTracker) release(provider, authID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	q := t.queues[provider]
+	if q == nil || q.inFlight[authID] == 0 {
+		return
+	}
+	if q.inFlight[authID]--; q.inFlight[authID] == 0 {
+		delete(q.inFlight, authID)
+	}
+	q.total--
+	close(q.released)
+	q.released = make(chan struct{})
+}
+
+// released returns a channel closed when the next slot of provider frees up.
+// It is nil while provider is unlimited.
+func (t *concurrencyTracker) released(provider string) <-chan struct{} {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.limitLocked(provider).PerAccount <= 0 {
+		return nil
+	}
+	return t.queueLocked(provider).released
+}
+
+// queueTicket is a request's place in a provider's queue.
+type queueTicket struct {
+	provider string
+	start    time.Time
+	maxWait  time.Duration
+}
+
+// enqueue places a request whose provider is saturated in the queue, or
+// fails with a saturation error when queueing is off or the queue is full.
+func (t *concurrencyTracker) enqueue(provider string) (*queueTicket, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	limit := t.limitLocked(provider)
+	q := t.queueLocked(provider)
+	if limit.QueueDepth <= 0 || limit.QueueWait <= 0 || q.depth >= limit.QueueDepth {
+		q.rejected++
+		return nil, newSaturatedError(provider, time.Second)
+	}
+	q.depth++
+	q.queued++
+	return &queueTicket{provider: provider, start: time.Now(), maxWait: limit.QueueWait}, nil
+}
+
+// wait blocks until released is closed, the ticket's time in the queue runs
+// out or ctx ends. On failure the ticket leaves the queue.
+func (t *concurrencyTracker) wait(ctx context.Context, ticket *queueTicket, released <-chan struct{}) error {
+	timer := time.NewTimer(time.Until(ticket.start.Add(ticket.maxWait)))
+	defer timer.Stop()
+	select {
+	case <-released:
+		return nil
+	case <-timer.C:
+		t.leave(ticket, false)
+		t.mu.Lock()
+		t.queueLocked(ticket.provider).timedOut++
+		t.mu.Unlock()
+		return newSaturatedError(ticket.provider, ticket.maxWait)
+	case <-ctx.Done():
+		t.leave(ticket, false)
+		return ctx.Err()
+	}
+}
+
+// leave takes the ticket out of the queue, recording its wait when it got a
+// slot.
+func (t *concurrencyTracker) leave(ticket *queueTicket, served bool) {
+	if ticket == nil {
+		return
+	}
+	waited := time.Since(ticket.start)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	q := t.queueLocked(ticket.provider)
+	q.depth--
+	if served {
+		q.served++
+		q.totalWait += waited
+		if waited > q.maxWait {
+			q.maxWait = waited
+		}
+	}
+}
+
+// pickSlot picks an account for the request and takes one of its request
+// slots. When every account is at its concurrency cap the request waits in
+// the provider's queue, if it has one, for a slot to free up. The returned
+// func frees the slot.
+func (m *Manager) pickSlot(ctx context.Context, provider, model string, opts Options, tried map[string]struct{}) (*Auth, ProviderExecutor, func(), error) {
+	var ticket *queueTicket
+	for {
+		// Taken before picking so a slot freed meanwhile is not missed.
+		released := m.concurrency.released(provider)
+		auth, executor, err := m.pickNext(ctx, provider, model, opts, tried)
+		if err == nil {
+			release, ok := m.concurrency.acquire(auth)
+			if ok {
+				m.concurrency.leave(ticket, true)
+				return auth, executor, release, nil
+			}
+			// Another request took the last slot: pick again.
+			continue
+		}
+		if _, busy := err.(*saturatedError); !busy {
+			m.concurrency.leave(ticket, false)
+			return nil, nil, nil, err
+		}
+		if ticket == nil {
+			if ticket, err = m.concurrency.enqueue(provider); err != nil {
+				return nil, nil, nil, err
+			}
+		}
+		if err = m.concurrency.wait(ctx, ticket, released); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+}
+
+// saturatedError is returned when every account of a provider is at its
+// concurrency cap and the request could not wait for a slot.
+type saturatedError struct {
+	provider   string
+	retryAfter time.Duration
+}
+
+func newSaturatedError(provider string, retryAfter time.Duration) *saturatedError {
+	if retryAfter < time.Second {
+		retryAfter = time.Second
+	}
+	return &saturatedError{provider: provider, retryAfter: retryAfter}
+}
+
+func (e *saturatedError) retrySeconds() int {
+	return int(math.Ceil(e.retryAfter.Seconds()))
+}
+
+func (e *saturatedError) Error() string {
+	message := fmt.Sprintf("All credentials for provider %s are at their concurrency limit", e.provider)
+	payload := map[string]any{"error": map[string]any{
+		"code":     "provider_saturated",
+		"message":  message,
+		"provider": e.provider,
+	}}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Sprintf(`{"error":{"code":"provider_saturated","message":"%s"}}`, message)
+	}
+	return string(data)
+}
+
+// Category keeps the request from being retried; it already had its turn in
+// the queue.
+func (e *saturatedError) Category() ErrorCategory { return CategoryUnknown }
+
+func (e *saturatedError) StatusCode() int {
+	return http.StatusServiceUnavailable
+}
+
+func (e *saturatedError) Headers() http.Header {
+	headers := make(http.Header)
+	headers.Set("Content-Type", "application/json")
+	headers.Set("Retry-After", strconv.Itoa(e.retrySeconds()))
+	return headers
+}
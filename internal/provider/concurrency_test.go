@@ -0,0 +1,195 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// gateExecutor holds every call until a value is sent on gate, tracking how
+// many calls run at once.
+type gateExecutor struct {
+	gate    chan struct{}
+	running atomic.Int32
+	peak    atomic.Int32
+}
+
+func (e *gateExecutor) Identifier() string { return "gate" }
+
+func (e *gateExecutor) enter() {
+	n := e.running.Add(1)
+	for {
+		peak := e.peak.Load()
+		if n <= peak || e.peak.CompareAndSwap(peak, n) {
+			return
+		}
+	}
+}
+
+func (e *gateExecutor) Execute(ctx context.Context, _ *Auth, _ Request, _ Options) (Response, error) {
+	e.enter()
+	defer e.running.Add(-1)
+	select {
+	case <-e.gate:
+		return Response{Payload: []byte("ok")}, nil
+	case <-ctx.Done():
+		return Response{}, ctx.Err()
+	}
+}
+
+func (e *gateExecutor) ExecuteStream(ctx context.Context, _ *Auth, _ Request, _ Options) (<-chan StreamChunk, error) {
+	e.enter()
+	ch := make(chan StreamChunk)
+	go func() {
+		defer close(ch)
+		defer e.running.Add(-1)
+		select {
+		case <-e.gate:
+		case <-ctx.Done():
+		}
+	}()
+	return ch, nil
+}
+
+func (e *gateExecutor) Refresh(_ context.Context, auth *Auth) (*Auth, error) { return auth, nil }
+
+func (e *gateExecutor) CountTokens(context.Context, *Auth, Request, Options) (Response, error) {
+	return Response{}, nil
+}
+
+func newGateManager(t *testing.T, limit ConcurrencyLimit) (*Manager, *gateExecutor) {
+	t.Helper()
+	exec := &gateExecutor{gate: make(chan struct{})}
+	m := NewManager(nil, nil, nil)
+	m.RegisterExecutor(exec)
+	m.SetConcurrencyLimits(func(provider string) ConcurrencyLimit {
+		if provider == "gate" {
+			return limit
+		}
+		return ConcurrencyLimit{}
+	})
+	if _, err := m.Register(context.Background(), &Auth{ID: "gate-1", Provider: "gate"}); err != nil {
+		t.Fatal(err)
+	}
+	return m, exec
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func assertSaturated(t *testing.T, err error, retryAfter string) {
+	t.Helper()
+	var se *saturatedError
+	if !errors.As(err, &se) {
+		t.Fatalf("error = %v, want provider saturated", err)
+	}
+	if se.StatusCode() != http.StatusServiceUnavailable || se.Headers().Get("Retry-After") != retryAfter {
+		t.Errorf("status %d, Retry-After %q; want 503, %s", se.StatusCode(), se.Headers().Get("Retry-After"), retryAfter)
+	}
+}
+
+func TestConcurrencyQueue_FillAndDrain(t *testing.T) {
+	m, exec := newGateManager(t, ConcurrencyLimit{PerAccount: 1, QueueDepth: 2, QueueWait: 5 * time.Second})
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	errs := make([]error, 3)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = m.executeWithProvider(ctx, "gate", Request{}, Options{})
+		}()
+	}
+	// One request runs and the other two fill the queue.
+	waitFor(t, "a full queue", func() bool {
+		s := m.QueueStats()["gate"]
+		return s.InFlight == 1 && s.Depth == 2
+	})
+	_, err := m.executeWithProvider(ctx, "gate", Request{}, Options{})
+	assertSaturated(t, err, "1")
+
+	// Each finished request lets the next one in.
+	for range errs {
+		exec.gate <- struct{}{}
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("request %d: %v", i, err)
+		}
+	}
+	if peak := exec.peak.Load(); peak != 1 {
+		t.Errorf("%d requests ran at once on the account, want 1", peak)
+	}
+	s := m.QueueStats()["gate"]
+	if s.InFlight != 0 || s.Depth != 0 || s.MaxDepth != 2 || s.Queued != 2 || s.Rejected != 1 || s.TimedOut != 0 {
+		t.Errorf("queue stats = %+v", s)
+	}
+	if s.MaxWaitMs <= 0 || s.AvgWaitMs <= 0 || s.AvgWaitMs > s.MaxWaitMs {
+		t.Errorf("wait times avg %.1fms max %.1fms", s.AvgWaitMs, s.MaxWaitMs)
+	}
+}
+
+func TestConcurrencyQueue_Timeout(t *testing.T) {
+	m, exec := newGateManager(t, ConcurrencyLimit{PerAccount: 1, QueueDepth: 1, QueueWait: 100 * time.Millisecond})
+	ctx := context.Background()
+	done := make(chan error, 1)
+	go func() {
+		_, err := m.executeWithProvider(ctx, "gate", Request{}, Options{})
+		done <- err
+	}()
+	waitFor(t, "the first request", func() bool { return exec.running.Load() == 1 })
+
+	start := time.Now()
+	_, err := m.executeWithProvider(ctx, "gate", Request{}, Options{})
+	if waited := time.Since(start); waited < 100*time.Millisecond {
+		t.Errorf("request gave up after %v, want the 100ms queue wait", waited)
+	}
+	assertSaturated(t, err, "1")
+	if s := m.QueueStats()["gate"]; s.TimedOut != 1 || s.Depth != 0 {
+		t.Errorf("queue stats = %+v", s)
+	}
+
+	exec.gate <- struct{}{}
+	if err = <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConcurrencyLimit_WithoutQueueFailsAtOnce(t *testing.T) {
+	m, exec := newGateManager(t, ConcurrencyLimit{PerAccount: 1})
+	ctx := context.Background()
+
+	// A stream holds its slot until it ends.
+	out, err := m.executeStreamWithProvider(ctx, "gate", Request{}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = m.executeWithProvider(ctx, "gate", Request{}, Options{})
+	assertSaturated(t, err, "1")
+
+	exec.gate <- struct{}{}
+	for range out {
+	}
+	waitFor(t, "the stream's slot", func() bool { return m.QueueStats()["gate"].InFlight == 0 })
+	go func() { exec.gate <- struct{}{} }()
+	if _, err = m.executeWithProvider(ctx, "gate", Request{}, Options{}); err != nil {
+		t.Fatalf("request after the stream ended: %v", err)
+	}
+	if s := m.QueueStats()["gate"]; s.Rejected != 1 || s.Queued != 0 {
+		t.Errorf("queue stats = %+v", s)
+	}
+}
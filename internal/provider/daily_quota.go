@@ -0,0 +1,373 @@
+package provider
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/json"
+	"github.com/nghyane/llm-mux/internal/persist"
+)
+
+const (
+	dailyQuotaSaveInterval = time.Minute
+	dailyQuotaFileVersion  = 1
+)
+
+// DailyLimit caps an account's use within one quota day. Zero fields are
+// unlimited.
+type DailyLimit struct {
+	Requests int64
+	Tokens   int64
+}
+
+// Unlimited reports whether the limit caps nothing.
+func (l DailyLimit) Unlimited() bool { return l.Requests <= 0 && l.Tokens <= 0 }
+
+// DailyLimitFunc returns the daily limit of an account.
+type DailyLimitFunc func(auth *Auth) DailyLimit
+
+// DailyQuotaStatus reports an account's use of its daily quota. Headroom is
+// the unused share of the tightest limit, from 0 (exhausted) to 1 (unused).
+type DailyQuotaStatus struct {
+	Requests     int64     `json:"requests"`
+	Tokens       int64     `json:"tokens"`
+	RequestLimit int64     `json:"request_limit,omitempty"`
+	TokenLimit   int64     `json:"token_limit,omitempty"`
+	Exhausted    bool      `json:"exhausted"`
+	Headroom     float64   `json:"headroom"`
+	ResetAt      time.Time `json:"reset_at"`
+}
+
+type dailyUsage struct {
+	PeriodStart time.Time `json:"period_start"`
+	Requests    int64     `json:"requests"`
+	Tokens      int64     `json:"tokens"`
+}
+
+// DailyQuota counts requests and tokens per account over a day that starts
+// at a configurable hour, so accounts can be rested once their provider's
+// daily allowance is used up. Counters can be saved to a JSON file so a
+// restart does not reset them mid-day.
+type DailyQuota struct {
+	mu        sync.Mutex
+	resetHour int
+	loc       *time.Location
+	limit     DailyLimitFunc
+	usage     map[string]*dailyUsage
+	dirty     bool
+
+	saver persist.Loop
+}
+
+// NewDailyQuota returns a tracker whose days start at resetHour in loc.
+// A nil loc means UTC.
+func NewDailyQuota(resetHour int, loc *time.Location, limit DailyLimitFunc) *DailyQuota {
+	q := &DailyQuota{usage: make(map[string]*dailyUsage)}
+	q.Configure(resetHour, loc, limit)
+	return q
+}
+
+// Configure replaces the reset time and limits, keeping the counters.
+func (q *DailyQuota) Configure(resetHour int, loc *time.Location, limit DailyLimitFunc) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	if resetHour < 0 || resetHour > 23 {
+		resetHour = 0
+	}
+	q.mu.Lock()
+	q.resetHour, q.loc, q.limit = resetHour, loc, limit
+	q.mu.Unlock()
+}
+
+// periodStartLocked returns the start of the quota day containing now.
+func (q *DailyQuota) periodStartLocked(now time.Time) time.Time {
+	local := now.In(q.loc)
+	start := time.Date(local.Year(), local.Month(), local.Day(), q.resetHour, 0, 0, 0, q.loc)
+	if start.After(local) {
+		start = start.AddDate(0, 0, -1)
+	}
+	return start
+}
+
+// currentLocked returns the counters of authID for the day containing now,
+// or nil when nothing was recorded that day.
+func (q *DailyQuota) currentLocked(authID string, now time.Time) *dailyUsage {
+	u := q.usage[authID]
+	if u == nil || !u.PeriodStart.Equal(q.periodStartLocked(now)) {
+		return nil
+	}
+	return u
+}
+
+func (q *DailyQuota) addLocked(authID string, requests, tokens int64, now time.Time) {
+	if authID == "" {
+		return
+	}
+	start := q.periodStartLocked(now)
+	u := q.usage[authID]
+	if u == nil || !u.PeriodStart.Equal(start) {
+		u = &dailyUsage{PeriodStart: start}
+		q.usage[authID] = u
+	}
+	u.Requests += requests
+	u.Tokens += tokens
+	q.dirty = true
+}
+
+// RecordRequest counts one request against authID.
+func (q *DailyQuota) RecordRequest(authID string) {
+	if q == nil {
+		return
+	}
+	q.mu.Lock()
+	q.addLocked(authID, 1, 0, time.Now())
+	q.mu.Unlock()
+}
+
+// RecordTokens counts tokens consumed by authID.
+func (q *DailyQuota) RecordTokens(authID string, tokens int64) {
+	if q == nil || tokens <= 0 {
+		return
+	}
+	q.mu.Lock()
+	q.addLocked(authID, 0, tokens, time.Now())
+	q.mu.Unlock()
+}
+
+// Status reports auth's use of its daily quota as of now. ok is false when
+// the account has no limit.
+func (q *DailyQuota) Status(auth *Auth, now time.Time) (status DailyQuotaStatus, ok bool) {
+	if q == nil || auth == nil {
+		return status, false
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.limit == nil {
+		return status, false
+	}
+	limit := q.limit(auth)
+	if limit.Unlimited() {
+		return status, false
+	}
+	status.RequestLimit = max(limit.Requests, 0)
+	status.TokenLimit = max(limit.Tokens, 0)
+	status.ResetAt = q.periodStartLocked(now).AddDate(0, 0, 1)
+	if u := q.currentLocked(auth.ID, now); u != nil {
+		status.Requests, status.Tokens = u.Requests, u.Tokens
+	}
+	status.Exhausted = (status.RequestLimit > 0 && status.Requests >= status.RequestLimit) ||
+		(status.TokenLimit > 0 && status.Tokens >= status.TokenLimit)
+	status.Headroom = 1
+	if status.RequestLimit > 0 {
+		status.Headroom = remainingShare(status.Requests, status.RequestLimit)
+	}
+	if status.TokenLimit > 0 {
+		if share := remainingShare(status.Tokens, status.TokenLimit); share < status.Headroom {
+			status.Headroom = share
+		}
+	}
+	return status, true
+}
+
+// remainingShare returns the unused share of limit, clamped to [0, 1].
+func remainingShare(used, limit int64) float64 {
+	share := float64(limit-used) / float64(limit)
+	switch {
+	case share < 0:
+		return 0
+	case share > 1:
+		return 1
+	}
+	return share
+}
+
+// Headroom returns the unused share of auth's daily quota as of now. An
+// account without a limit has full headroom.
+func (q *DailyQuota) Headroom(auth *Auth, now time.Time) float64 {
+	status, ok := q.Status(auth, now)
+	if !ok {
+		return 1
+	}
+	return status.Headroom
+}
+
+// exhausted reports whether auth used up its quota for the day containing
+// now, and when that day ends.
+func (q *DailyQuota) exhausted(auth *Auth, now time.Time) (bool, time.Time) {
+	status, ok := q.Status(auth, now)
+	if !ok || !status.Exhausted {
+		return false, time.Time{}
+	}
+	return true, status.ResetAt
+}
+
+// dailyQuotaFile is the on-disk form of the counters.
+type dailyQuotaFile struct {
+	Version int                    `json:"version"`
+	Usage   map[string]*dailyUsage `json:"usage"`
+}
+
+// Load replaces the counters with those saved at path. A missing file is not
+// an error.
+func (q *DailyQuota) Load(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	var file dailyQuotaFile
+	if err = json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("decode daily quota counters: %w", err)
+	}
+	if file.Version != dailyQuotaFileVersion {
+		return fmt.Errorf("unsupported daily quota counters version %d", file.Version)
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.usage = make(map[string]*dailyUsage, len(file.Usage))
+	for id, u := range file.Usage {
+		if u != nil {
+			q.usage[id] = u
+		}
+	}
+	q.dirty = false
+	return nil
+}
+
+// Save writes the counters to path when they changed since the last save.
+// Counters from past days are dropped.
+func (q *DailyQuota) Save(path string) error {
+	q.mu.Lock()
+	if !q.dirty {
+		q.mu.Unlock()
+		return nil
+	}
+	now := time.Now()
+	file := dailyQuotaFile{Version: dailyQuotaFileVersion, Usage: make(map[string]*dailyUsage, len(q.usage))}
+	for id := range q.usage {
+		if u := q.currentLocked(id, now); u != nil {
+			copied := *u
+			file.Usage[id] = &copied
+		}
+	}
+	q.dirty = false
+	q.mu.Unlock()
+
+	data, err := json.Marshal(file)
+	if err == nil {
+		err = persist.WriteFile(path, data)
+	}
+	if err != nil {
+		q.mu.Lock()
+		q.dirty = true
+		q.mu.Unlock()
+	}
+	return err
+}
+
+// StartPersistence loads saved counters from path and saves them every
+// minute until StopPersistence is called. It does nothing while already
+// running, so live counters are never replaced by older saved ones.
+func (q *DailyQuota) StartPersistence(path string) error {
+	if q == nil || path == "" || q.saver.Running() {
+		return nil
+	}
+	if err := q.Load(path); err != nil {
+		return err
+	}
+	q.saver.Start(path, dailyQuotaSaveInterval, "daily quota counters", q.Save)
+	return nil
+}
+
+// StopPersistence stops the save loop and writes the counters one last time.
+func (q *DailyQuota) StopPersistence() error {
+	if q == nil {
+		return nil
+	}
+	return q.saver.Stop()
+}
+
+// SetDailyQuota installs the tracker used to rest accounts whose daily quota
+// is exhausted. A nil tracker disables daily quotas.
+func (m *Manager) SetDailyQuota(q *DailyQuota) {
+	if m == nil {
+		return
+	}
+	m.dailyQuota.Store(q)
+}
+
+// DailyQuota returns the installed tracker, or nil.
+func (m *Manager) DailyQuota() *DailyQuota {
+	if m == nil {
+		return nil
+	}
+	return m.dailyQuota.Load()
+}
+
+// RecordDailyTokens counts tokens consumed by an account against its daily quota.
+func (m *Manager) RecordDailyTokens(authID string, tokens int64) {
+	m.DailyQuota().RecordTokens(authID, tokens)
+}
+
+// DailyQuotaStatus reports auth's use of its daily quota. ok is false when
+// daily quotas are off or the account has no limit.
+func (m *Manager) DailyQuotaStatus(auth *Auth) (DailyQuotaStatus, bool) {
+	return m.DailyQuota().Status(auth, time.Now())
+}
+
+func (m *Manager) recordDailyRequest(authID string) {
+	m.DailyQuota().RecordRequest(authID)
+}
+
+// dailyQuotaError is returned when every account able to serve a request has
+// exhausted its daily quota.
+type dailyQuotaError struct {
+	provider string
+	resetIn  time.Duration
+}
+
+func newDailyQuotaError(provider string, resetIn time.Duration) *dailyQuotaError {
+	if resetIn < 0 {
+		resetIn = 0
+	}
+	return &dailyQuotaError{provider: provider, resetIn: resetIn}
+}
+
+func (e *dailyQuotaError) resetSeconds() int {
+	return int(math.Ceil(e.resetIn.Seconds()))
+}
+
+func (e *dailyQuotaError) Error() string {
+	message := fmt.Sprintf("All credentials for provider %s have exhausted their daily quota", e.provider)
+	payload := map[string]any{"error": map[string]any{
+		"code":          "daily_quota_exhausted",
+		"message":       message,
+		"provider":      e.provider,
+		"reset_time":    e.resetIn.Round(time.Second).String(),
+		"reset_seconds": e.resetSeconds(),
+	}}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Sprintf(`{"error":{"code":"daily_quota_exhausted","message":"%s"}}`, message)
+	}
+	return string(data)
+}
+
+func (e *dailyQuotaError) StatusCode() int {
+	return http.StatusTooManyRequests
+}
+
+func (e *dailyQuotaError) Headers() http.Header {
+	headers := make(http.Header)
+	headers.Set("Content-Type", "application/json")
+	headers.Set("Retry-After", strconv.Itoa(e.resetSeconds()))
+	return headers
+}
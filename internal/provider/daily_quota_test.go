@@ -0,0 +1,148 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// recordingExecutor succeeds and remembers which auth served each request.
+type recordingExecutor struct {
+	used []string
+}
+
+func (e *recordingExecutor) Identifier() string { return "quota" }
+
+func (e *recordingExecutor) Execute(_ context.Context, auth *Auth, _ Request, _ Options) (Response, error) {
+	e.used = append(e.used, auth.ID)
+	return Response{}, nil
+}
+
+func (e *recordingExecutor) ExecuteStream(context.Context, *Auth, Request, Options) (<-chan StreamChunk, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (e *recordingExecutor) Refresh(_ context.Context, auth *Auth) (*Auth, error) { return auth, nil }
+
+func (e *recordingExecutor) CountTokens(context.Context, *Auth, Request, Options) (Response, error) {
+	return Response{}, nil
+}
+
+func TestDailyQuota_RotatesAndRejectsWhenExhausted(t *testing.T) {
+	exec := &recordingExecutor{}
+	m := NewManager(nil, nil, nil)
+	m.RegisterExecutor(exec)
+	m.SetDailyQuota(NewDailyQuota(0, time.UTC, func(*Auth) DailyLimit { return DailyLimit{Requests: 2} }))
+	for _, id := range []string{"a", "b"} {
+		if _, err := m.Register(context.Background(), &Auth{ID: id, Provider: "quota"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i := 0; i < 4; i++ {
+		if _, err := m.executeWithProvider(context.Background(), "quota", Request{}, Options{}); err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+	}
+	counts := map[string]int{}
+	for _, id := range exec.used {
+		counts[id]++
+	}
+	if counts["a"] != 2 || counts["b"] != 2 {
+		t.Fatalf("requests per account = %v, want 2 each", counts)
+	}
+
+	_, err := m.executeWithProvider(context.Background(), "quota", Request{}, Options{})
+	var quotaErr *dailyQuotaError
+	if !errors.As(err, &quotaErr) {
+		t.Fatalf("err = %v, want daily quota error", err)
+	}
+	if quotaErr.StatusCode() != http.StatusTooManyRequests || quotaErr.Headers().Get("Retry-After") == "" {
+		t.Errorf("status %d, Retry-After %q", quotaErr.StatusCode(), quotaErr.Headers().Get("Retry-After"))
+	}
+	status, ok := m.DailyQuotaStatus(&Auth{ID: "a", Provider: "quota"})
+	if !ok || !status.Exhausted || status.Requests != 2 {
+		t.Errorf("status = %+v, %v", status, ok)
+	}
+}
+
+func TestDailyQuota_ResetHourInTimezone(t *testing.T) {
+	loc := time.FixedZone("UTC+7", 7*3600)
+	q := NewDailyQuota(6, loc, func(*Auth) DailyLimit { return DailyLimit{Tokens: 100} })
+	auth := &Auth{ID: "a"}
+
+	before := time.Date(2025, 3, 10, 5, 59, 0, 0, loc)
+	q.mu.Lock()
+	q.addLocked("a", 1, 100, before)
+	q.mu.Unlock()
+
+	status, _ := q.Status(auth, before)
+	if !status.Exhausted {
+		t.Fatalf("status = %+v, want exhausted", status)
+	}
+	if want := time.Date(2025, 3, 10, 6, 0, 0, 0, loc); !status.ResetAt.Equal(want) {
+		t.Errorf("reset at %v, want %v", status.ResetAt, want)
+	}
+	after := time.Date(2025, 3, 10, 6, 0, 0, 0, loc)
+	if status, _ = q.Status(auth, after); status.Exhausted || status.Tokens != 0 {
+		t.Errorf("status after reset = %+v", status)
+	}
+}
+
+func TestDailyQuota_PersistsCounters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "daily-quota.json")
+	limit := func(*Auth) DailyLimit { return DailyLimit{Requests: 10} }
+	q := NewDailyQuota(0, time.UTC, limit)
+	q.RecordRequest("a")
+	q.RecordRequest("a")
+	q.RecordTokens("a", 42)
+	if err := q.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	restored := NewDailyQuota(0, time.UTC, limit)
+	if err := restored.Load(path); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	status, _ := restored.Status(&Auth{ID: "a"}, time.Now())
+	if status.Requests != 2 || status.Tokens != 42 {
+		t.Errorf("restored status = %+v", status)
+	}
+}
+
+func TestQuotaSelector_PrefersMostHeadroom(t *testing.T) {
+	exec := &recordingExecutor{}
+	quota := NewDailyQuota(0, time.UTC, func(*Auth) DailyLimit { return DailyLimit{Requests: 100, Tokens: 10000} })
+	m := NewManager(nil, NewQuotaSelector(func() *DailyQuota { return quota }), nil)
+	m.RegisterExecutor(exec)
+	m.SetDailyQuota(quota)
+	for _, id := range []string{"a", "b", "c"} {
+		if _, err := m.Register(context.Background(), &Auth{ID: id, Provider: "quota"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Uneven prior usage: "c" has spent the fewest tokens, "a" the fewest
+	// requests, but requests are the looser limit for both.
+	quota.RecordTokens("a", 6000)
+	quota.RecordTokens("b", 3000)
+	quota.RecordTokens("c", 1000)
+	for range 5 {
+		quota.RecordRequest("b")
+		quota.RecordRequest("c")
+	}
+
+	if _, err := m.executeWithProvider(context.Background(), "quota", Request{}, Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(exec.used) != 1 || exec.used[0] != "c" {
+		t.Fatalf("served by %v, want the least used account c", exec.used)
+	}
+
+	status, ok := m.DailyQuotaStatus(&Auth{ID: "a", Provider: "quota"})
+	if !ok || status.Headroom != 0.4 {
+		t.Errorf("headroom of a = %v (ok %v), want 0.4", status.Headroom, ok)
+	}
+}
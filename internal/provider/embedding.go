@@ -0,0 +1,160 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/registry"
+	"github.com/sony/gobreaker"
+)
+
+// Embedder is an optional interface that provider executors implement to serve
+// embeddings requests. Embed receives the client's request payload and returns
+// the response in the client's format, splitting inputs across upstream calls
+// when the provider caps the batch size. Embedding calls bypass executor
+// middleware because middleware wraps only ProviderExecutor.
+type Embedder interface {
+	Embed(ctx context.Context, auth *Auth, req Request, opts Options) (Response, error)
+}
+
+// embedderFor returns the Embedder registered for provider, if any.
+func (m *Manager) embedderFor(provider string) (Embedder, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	embedder, ok := m.baseExecutors[provider].(Embedder)
+	return embedder, ok
+}
+
+// ExecuteEmbed runs an embeddings request against the first provider in
+// providers whose executor implements Embedder. Providers without embeddings
+// support are skipped.
+func (m *Manager) ExecuteEmbed(ctx context.Context, providers []string, req Request, opts Options) (Response, error) {
+	normalized := m.normalizeProviders(providers)
+	capable := normalized[:0:0]
+	for _, p := range normalized {
+		if _, ok := m.embedderFor(p); ok {
+			capable = append(capable, p)
+		}
+	}
+	if len(capable) == 0 {
+		return Response{}, &Error{Code: "embeddings_not_supported", Message: "no provider for model " + req.Model + " supports embeddings", HTTPStatus: http.StatusBadRequest}
+	}
+	return m.executeCapable(ctx, capable, req.Model, func(execCtx context.Context, provider string) (Response, error) {
+		return m.executeEmbedWithProvider(execCtx, provider, req, opts)
+	})
+}
+
+// executeCapable routes a request for model across providers, which must all
+// support the operation run performs, retrying with the same policy as
+// Execute. It backs the optional executor interfaces.
+func (m *Manager) executeCapable(ctx context.Context, providers []string, model string, run func(context.Context, string) (Response, error)) (Response, error) {
+	selected := m.selectProviders(model, providers)
+
+	retryTimes, maxWait := m.retrySettings()
+	attempts := retryTimes + 1
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var lastErr error
+	var lastProvider string
+	for attempt := 0; attempt < attempts; attempt++ {
+		start := time.Now()
+		resp, errExec := m.executeProvidersOnce(ctx, selected, func(execCtx context.Context, provider string) (Response, error) {
+			lastProvider = provider
+			return run(execCtx, provider)
+		})
+		if attempt == 0 {
+			m.RetryBudget().RecordRequest(lastProvider)
+		}
+		latency := time.Since(start)
+
+		if errExec == nil {
+			m.recordProviderResult(lastProvider, model, true, latency)
+			return resp, nil
+		}
+
+		m.recordProviderResult(lastProvider, model, false, latency)
+		lastErr = errExec
+
+		wait, shouldRetry := m.shouldRetryAfterError(errExec, attempt, attempts, selected, model, maxWait)
+		if !shouldRetry || !m.spendRetry(lastProvider) {
+			break
+		}
+		if errWait := waitForCooldown(ctx, wait); errWait != nil {
+			return Response{}, errWait
+		}
+	}
+	if lastErr != nil {
+		return Response{}, lastErr
+	}
+	return Response{}, &Error{Code: "auth_not_found", Message: "no auth available"}
+}
+
+// executeEmbedWithProvider handles embeddings for a single provider, attempting
+// multiple auth candidates until one succeeds or all are exhausted.
+func (m *Manager) executeEmbedWithProvider(ctx context.Context, provider string, req Request, opts Options) (Response, error) {
+	embedder, ok := m.embedderFor(provider)
+	if !ok {
+		return Response{}, &Error{Code: "embeddings_not_supported", Message: "provider " + provider + " does not support embeddings", HTTPStatus: http.StatusBadRequest}
+	}
+	req.Model = registry.GetGlobalRegistry().GetModelIDForProvider(req.Model, provider, opts.Required)
+	return m.executeWithAuths(ctx, provider, req.Model, req, opts, embedder.Embed)
+}
+
+// executeWithAuths runs call against the auths of provider that serve model,
+// attempting multiple candidates until one succeeds or all are exhausted. An
+// empty model considers every auth of the provider. It backs the optional
+// executor interfaces, which are not reachable through Execute.
+func (m *Manager) executeWithAuths(ctx context.Context, provider, model string, req Request, opts Options, call func(context.Context, *Auth, Request, Options) (Response, error)) (Response, error) {
+	breaker := m.getOrCreateBreaker(provider)
+	if breaker.State() == gobreaker.StateOpen {
+		return Response{}, &Error{Code: "circuit_open", Message: "provider circuit breaker is open"}
+	}
+
+	tried := make(map[string]struct{})
+	var lastErr error
+	for {
+		auth, _, errPick := m.pickNext(ctx, provider, model, opts, tried)
+		if errPick != nil {
+			if lastErr != nil {
+				return Response{}, lastErr
+			}
+			return Response{}, errPick
+		}
+
+		tried[auth.ID] = struct{}{}
+		m.recordDailyRequest(auth.ID)
+		execCtx := ctx
+		if rt := m.roundTripperFor(auth); rt != nil {
+			execCtx = context.WithValue(execCtx, roundTripperContextKey{}, rt)
+		}
+
+		authCopy := auth
+		reqCopy := req
+		result, errBreaker := breaker.Execute(func() (any, error) {
+			return call(execCtx, authCopy, reqCopy, opts)
+		})
+
+		if errBreaker != nil {
+			markResult := Result{AuthID: auth.ID, Provider: provider, Model: req.Model, Success: false}
+			markResult.Error = &Error{Message: errBreaker.Error()}
+			var se StatusCodeError
+			if errors.As(errBreaker, &se) && se != nil {
+				markResult.Error.HTTPStatus = se.StatusCode()
+			}
+			if ra := retryAfterFromError(errBreaker); ra != nil {
+				markResult.RetryAfter = ra
+			}
+			m.MarkResult(execCtx, markResult)
+			lastErr = errBreaker
+			continue
+		}
+
+		resp := result.(Response)
+		m.MarkResult(execCtx, Result{AuthID: auth.ID, Provider: provider, Model: req.Model, Success: true})
+		return resp, nil
+	}
+}
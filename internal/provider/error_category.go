@@ -35,8 +35,18 @@ const (
 	// CategoryNotFound indicates resource not found
 	// Should NOT retry
 	CategoryNotFound
+
+	// CategoryReauthRequired indicates credentials that can only be renewed
+	// by signing in again, such as an expired session cookie
+	// Should take the auth out of rotation until it is re-authenticated
+	CategoryReauthRequired
 )
 
+// StatusMessageReauthRequired is the status message of an account whose
+// credentials expired and need a new login. Executors include it in the
+// error message of calls that detect such credentials.
+const StatusMessageReauthRequired = "reauth_required"
+
 // String returns human-readable category name
 func (c ErrorCategory) String() string {
 	switch c {
@@ -52,6 +62,8 @@ func (c ErrorCategory) String() string {
 		return "transient"
 	case CategoryNotFound:
 		return "not_found"
+	case CategoryReauthRequired:
+		return "reauth_required"
 	default:
 		return "unknown"
 	}
@@ -64,7 +76,7 @@ func (c ErrorCategory) ShouldRetry() bool {
 
 // ShouldFallback returns true if should try another auth/provider
 func (c ErrorCategory) ShouldFallback() bool {
-	return c == CategoryQuotaError || c == CategoryTransient || c == CategoryAuthError
+	return c == CategoryQuotaError || c == CategoryTransient || c == CategoryAuthError || c == CategoryReauthRequired
 }
 
 // ShouldDisableAuth returns true if auth should be disabled
@@ -74,7 +86,7 @@ func (c ErrorCategory) ShouldDisableAuth() bool {
 
 // ShouldSuspendAuth returns true if auth should be temporarily suspended
 func (c ErrorCategory) ShouldSuspendAuth() bool {
-	return c == CategoryAuthError || c == CategoryQuotaError
+	return c == CategoryAuthError || c == CategoryQuotaError || c == CategoryReauthRequired
 }
 
 // IsUserFault returns true if error is caused by user's request
@@ -113,7 +125,12 @@ func CategorizeHTTPStatus(statusCode int) ErrorCategory {
 
 // CategorizeError determines category from error message and status code
 func CategorizeError(statusCode int, message string) ErrorCategory {
-	// Check for OAuth revoked errors first (most specific)
+	// Check for credentials that need a new login first (most specific)
+	if strings.Contains(message, StatusMessageReauthRequired) {
+		return CategoryReauthRequired
+	}
+
+	// Check for OAuth revoked errors
 	if isOAuthRevokedError(message) {
 		return CategoryAuthRevoked
 	}
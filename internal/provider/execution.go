@@ -29,12 +29,12 @@ func (m *Manager) executeWithProvider(ctx context.Context, provider string, req
 		return Response{}, &Error{Code: "circuit_open", Message: "provider circuit breaker is open"}
 	}
 
-	req.Model = registry.GetGlobalRegistry().GetModelIDForProvider(req.Model, provider)
+	req.Model = registry.GetGlobalRegistry().GetModelIDForProvider(req.Model, provider, opts.Required)
 
 	tried := make(map[string]struct{})
 	var lastErr error
 	for {
-		auth, executor, errPick := m.pickNext(ctx, provider, req.Model, opts, tried)
+		auth, executor, release, errPick := m.pickSlot(ctx, provider, req.Model, opts, tried)
 		if errPick != nil {
 			telemetry.RecordError(span, errPick)
 			if lastErr != nil {
@@ -44,6 +44,7 @@ func (m *Manager) executeWithProvider(ctx context.Context, provider string, req
 		}
 
 		tried[auth.ID] = struct{}{}
+		m.recordDailyRequest(auth.ID)
 		execCtx := ctx
 		if rt := m.roundTripperFor(auth); rt != nil {
 			execCtx = context.WithValue(execCtx, roundTripperContextKey{}, rt)
@@ -51,9 +52,15 @@ func (m *Manager) executeWithProvider(ctx context.Context, provider string, req
 
 		authCopy := auth
 		reqCopy := req
+		callStart := time.Now()
 		result, errBreaker := breaker.Execute(func() (any, error) {
-			return executor.Execute(execCtx, authCopy, reqCopy, opts)
+			resp, err := executor.Execute(execCtx, authCopy, reqCopy, opts)
+			if err = m.classifyRetryable(provider, resp, err); err != nil {
+				return nil, err
+			}
+			return resp, nil
 		})
+		release()
 
 		if errBreaker != nil {
 			telemetry.RecordError(span, errBreaker)
@@ -72,6 +79,7 @@ func (m *Manager) executeWithProvider(ctx context.Context, provider string, req
 		}
 
 		resp := result.(Response)
+		m.latency.RecordRequest(provider, req.Model, auth.ID, time.Since(callStart))
 		m.MarkResult(execCtx, Result{AuthID: auth.ID, Provider: provider, Model: req.Model, Success: true})
 		return resp, nil
 	}
@@ -89,7 +97,7 @@ func (m *Manager) executeCountWithProvider(ctx context.Context, provider string,
 		return Response{}, &Error{Code: "circuit_open", Message: "provider circuit breaker is open"}
 	}
 
-	req.Model = registry.GetGlobalRegistry().GetModelIDForProvider(req.Model, provider)
+	req.Model = registry.GetGlobalRegistry().GetModelIDForProvider(req.Model, provider, opts.Required)
 
 	tried := make(map[string]struct{})
 	var lastErr error
@@ -111,7 +119,11 @@ func (m *Manager) executeCountWithProvider(ctx context.Context, provider string,
 		authCopy := auth
 		reqCopy := req
 		result, errBreaker := breaker.Execute(func() (any, error) {
-			return executor.CountTokens(execCtx, authCopy, reqCopy, opts)
+			resp, err := executor.CountTokens(execCtx, authCopy, reqCopy, opts)
+			if err = m.classifyRetryable(provider, resp, err); err != nil {
+				return nil, err
+			}
+			return resp, nil
 		})
 
 		if errBreaker != nil {
@@ -147,12 +159,12 @@ func (m *Manager) executeStreamWithProvider(ctx context.Context, provider string
 		return nil, &Error{Code: "circuit_open", Message: "provider circuit breaker is open"}
 	}
 
-	req.Model = registry.GetGlobalRegistry().GetModelIDForProvider(req.Model, provider)
+	req.Model = registry.GetGlobalRegistry().GetModelIDForProvider(req.Model, provider, opts.Required)
 
 	tried := make(map[string]struct{})
 	var lastErr error
 	for {
-		auth, executor, errPick := m.pickNext(ctx, provider, req.Model, opts, tried)
+		auth, executor, release, errPick := m.pickSlot(ctx, provider, req.Model, opts, tried)
 		if errPick != nil {
 			if lastErr != nil {
 				return nil, lastErr
@@ -161,12 +173,18 @@ func (m *Manager) executeStreamWithProvider(ctx context.Context, provider string
 		}
 
 		tried[auth.ID] = struct{}{}
+		m.recordDailyRequest(auth.ID)
 		execCtx := ctx
 		if rt := m.roundTripperFor(auth); rt != nil {
 			execCtx = context.WithValue(execCtx, roundTripperContextKey{}, rt)
 		}
-		chunks, errStream := executor.ExecuteStream(execCtx, auth, req, opts)
+		streamCtx, cancelStream := context.WithCancel(execCtx)
+		streamStart := time.Now()
+		chunks, errStream := executor.ExecuteStream(streamCtx, auth, req, opts)
 		if errStream != nil {
+			errStream = m.classifyRetryable(provider, Response{}, errStream)
+			cancelStream()
+			release()
 			rerr := &Error{Message: errStream.Error()}
 			var se StatusCodeError
 			if errors.As(errStream, &se) && se != nil {
@@ -178,21 +196,44 @@ func (m *Manager) executeStreamWithProvider(ctx context.Context, provider string
 			lastErr = errStream
 			continue
 		}
-		out := make(chan StreamChunk, 1)
-		go func(streamCtx context.Context, streamAuth *Auth, streamProvider string, streamChunks <-chan StreamChunk) {
+		out := make(chan StreamChunk, m.streamBufferLen())
+		go func(ctx context.Context, cancelUpstream context.CancelFunc, streamAuth *Auth, streamProvider string, streamChunks <-chan StreamChunk) {
 			defer close(out)
-			var failed bool
+			defer release()
+			defer cancelUpstream()
+			watchdog := newStreamWatchdog(m.streamIdleTimeout(streamProvider))
+			defer watchdog.stop()
+			var failed, backpressured, firstToken bool
 			for {
 				select {
-				case <-streamCtx.Done():
+				case <-ctx.Done():
+					m.noteStreamCancelled(ctx)
+					return
+				case <-watchdog.expired():
+					// No chunk within the idle window: abort the upstream call
+					// and fail the stream instead of leaving the client waiting.
+					cancelUpstream()
+					stallErr := watchdog.err()
+					if !failed {
+						m.MarkResult(ctx, Result{AuthID: streamAuth.ID, Provider: streamProvider, Model: req.Model, Success: false, Error: stallErr})
+					}
+					select {
+					case out <- StreamChunk{Err: stallErr}:
+					case <-ctx.Done():
+					}
 					return
 				case chunk, ok := <-streamChunks:
 					if !ok {
 						if !failed {
-							m.MarkResult(streamCtx, Result{AuthID: streamAuth.ID, Provider: streamProvider, Model: req.Model, Success: true})
+							m.latency.RecordStream(streamProvider, req.Model, streamAuth.ID, time.Since(streamStart))
+							m.MarkResult(ctx, Result{AuthID: streamAuth.ID, Provider: streamProvider, Model: req.Model, Success: true})
 						}
 						return
 					}
+					if !firstToken && chunk.Err == nil && chunkHasContent(chunk.Payload) {
+						firstToken = true
+						m.latency.RecordFirstToken(streamProvider, req.Model, streamAuth.ID, time.Since(streamStart))
+					}
 					if chunk.Err != nil && !failed {
 						failed = true
 						rerr := &Error{Message: chunk.Err.Error()}
@@ -202,20 +243,37 @@ func (m *Manager) executeStreamWithProvider(ctx context.Context, provider string
 						}
 						result := Result{AuthID: streamAuth.ID, Provider: streamProvider, Model: req.Model, Success: false, Error: rerr}
 						result.RetryAfter = retryAfterFromError(chunk.Err)
-						m.MarkResult(streamCtx, result)
+						m.MarkResult(ctx, result)
 					}
-					select {
-					case out <- chunk:
-					case <-streamCtx.Done():
+					if !m.sendStreamChunk(ctx, out, chunk, &backpressured) {
+						m.noteStreamCancelled(ctx)
 						return
 					}
+					// Restart the idle window only once the chunk is handed
+					// off, so a slow consumer is not mistaken for a stall.
+					watchdog.reset()
 				}
 			}
-		}(execCtx, auth.Clone(), provider, chunks)
+		}(execCtx, cancelStream, auth.Clone(), provider, chunks)
 		return out, nil
 	}
 }
 
+// noteStreamCancelled counts a stream abandoned because the caller cancelled
+// ctx, typically a client that disconnected mid-stream. Returning from the
+// forwarding goroutine cancels the upstream call.
+func (m *Manager) noteStreamCancelled(ctx context.Context) {
+	if errors.Is(ctx.Err(), context.Canceled) {
+		m.cancelledStreams.Add(1)
+	}
+}
+
+// CancelledStreams returns how many streams were aborted by their caller
+// before the upstream finished.
+func (m *Manager) CancelledStreams() int64 {
+	return m.cancelledStreams.Load()
+}
+
 // executeProvidersOnce attempts execution across multiple providers in sequence,
 // returning the first successful response.
 func (m *Manager) executeProvidersOnce(ctx context.Context, providers []string, fn func(context.Context, string) (Response, error)) (Response, error) {
@@ -0,0 +1,148 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// FaultSpec describes the faults to inject into one request. The zero value
+// injects nothing.
+type FaultSpec struct {
+	// Status fails the call with this HTTP status before it reaches the
+	// upstream.
+	Status int
+	// DropAfter ends a stream with an error after this many chunks.
+	DropAfter int
+	// MalformedAt replaces the chunk at this 1-based position with one that
+	// is not valid JSON.
+	MalformedAt int
+	// ChunkDelay holds back every stream chunk, and non-streaming responses,
+	// for this long.
+	ChunkDelay time.Duration
+}
+
+// Active reports whether s injects any fault.
+func (s FaultSpec) Active() bool {
+	return s.Status > 0 || s.DropAfter > 0 || s.MalformedAt > 0 || s.ChunkDelay > 0
+}
+
+type faultSpecKey struct{}
+
+// WithFaultSpec returns a context carrying the faults FaultInjectionMiddleware
+// injects into calls made with it.
+func WithFaultSpec(ctx context.Context, spec FaultSpec) context.Context {
+	return context.WithValue(ctx, faultSpecKey{}, spec)
+}
+
+// FaultSpecFromContext returns the faults attached to ctx, if any.
+func FaultSpecFromContext(ctx context.Context) (FaultSpec, bool) {
+	spec, ok := ctx.Value(faultSpecKey{}).(FaultSpec)
+	return spec, ok && spec.Active()
+}
+
+// malformedChunk is sent in place of a chunk selected by MalformedAt.
+var malformedChunk = []byte("data: {\"injected_fault\": malformed\n\n")
+
+// FaultInjectionMiddleware deliberately breaks calls whose context carries a
+// FaultSpec, so clients can test how they handle failing upstreams. Calls
+// without one pass through untouched. It is meant for test deployments only.
+func FaultInjectionMiddleware() ExecutorMiddleware {
+	return func(next ProviderExecutor) ProviderExecutor {
+		return &ExecutorFuncs{
+			Next: next,
+			ExecuteFunc: func(ctx context.Context, auth *Auth, req Request, opts Options) (Response, error) {
+				spec, ok := FaultSpecFromContext(ctx)
+				if !ok {
+					return next.Execute(ctx, auth, req, opts)
+				}
+				if spec.Status > 0 {
+					return Response{}, injectedStatus(spec.Status)
+				}
+				resp, err := next.Execute(ctx, auth, req, opts)
+				if err == nil && !sleepCtx(ctx, spec.ChunkDelay) {
+					return Response{}, ctx.Err()
+				}
+				return resp, err
+			},
+			ExecuteStreamFunc: func(ctx context.Context, auth *Auth, req Request, opts Options) (<-chan StreamChunk, error) {
+				spec, ok := FaultSpecFromContext(ctx)
+				if !ok {
+					return next.ExecuteStream(ctx, auth, req, opts)
+				}
+				if spec.Status > 0 {
+					return nil, injectedStatus(spec.Status)
+				}
+				upstreamCtx, cancel := context.WithCancel(ctx)
+				in, err := next.ExecuteStream(upstreamCtx, auth, req, opts)
+				if err != nil {
+					cancel()
+					return nil, err
+				}
+				out := make(chan StreamChunk)
+				go injectStreamFaults(ctx, spec, in, out, cancel)
+				return out, nil
+			},
+		}
+	}
+}
+
+// injectStreamFaults copies in to out, applying spec. Once it stops early it
+// cancels the upstream call and drains in so the executor is not stranded.
+func injectStreamFaults(ctx context.Context, spec FaultSpec, in <-chan StreamChunk, out chan<- StreamChunk, cancel context.CancelFunc) {
+	defer func() {
+		cancel()
+		for range in {
+		}
+	}()
+	defer close(out)
+	send := func(chunk StreamChunk) bool {
+		select {
+		case out <- chunk:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	sent := 0
+	for chunk := range in {
+		if chunk.Err != nil {
+			send(chunk)
+			return
+		}
+		if !sleepCtx(ctx, spec.ChunkDelay) {
+			return
+		}
+		if sent+1 == spec.MalformedAt {
+			chunk = StreamChunk{Payload: malformedChunk}
+		}
+		if !send(chunk) {
+			return
+		}
+		sent++
+		if sent == spec.DropAfter {
+			send(StreamChunk{Err: &Error{Code: "fault_injection", Message: fmt.Sprintf("stream dropped after %d chunks", sent), HTTPStatus: 502}})
+			return
+		}
+	}
+}
+
+func injectedStatus(status int) error {
+	return &Error{Code: "fault_injection", Message: fmt.Sprintf("injected status %d", status), HTTPStatus: status}
+}
+
+// sleepCtx waits for d and reports whether ctx was still live afterwards.
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,102 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// chunkExecutor streams n numbered chunks.
+type chunkExecutor struct {
+	echoExecutor
+	n int
+}
+
+func (e *chunkExecutor) ExecuteStream(ctx context.Context, _ *Auth, _ Request, _ Options) (<-chan StreamChunk, error) {
+	ch := make(chan StreamChunk)
+	go func() {
+		defer close(ch)
+		for i := range e.n {
+			select {
+			case ch <- StreamChunk{Payload: []byte(fmt.Sprintf("data: {\"i\":%d}\n\n", i))}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+func collectStream(t *testing.T, exec ProviderExecutor, ctx context.Context) ([]string, error) {
+	t.Helper()
+	ch, err := exec.ExecuteStream(ctx, nil, Request{}, Options{})
+	if err != nil {
+		return nil, err
+	}
+	var got []string
+	for chunk := range ch {
+		if chunk.Err != nil {
+			return got, chunk.Err
+		}
+		got = append(got, string(chunk.Payload))
+	}
+	return got, nil
+}
+
+func TestFaultInjectionMiddleware(t *testing.T) {
+	var calls []string
+	exec := ChainExecutor(&chunkExecutor{echoExecutor: echoExecutor{calls: &calls}, n: 5}, FaultInjectionMiddleware())
+
+	t.Run("no spec passes through", func(t *testing.T) {
+		got, err := collectStream(t, exec, context.Background())
+		if err != nil || len(got) != 5 {
+			t.Fatalf("got %d chunks, err %v; want 5 clean chunks", len(got), err)
+		}
+	})
+
+	t.Run("status", func(t *testing.T) {
+		ctx := WithFaultSpec(context.Background(), FaultSpec{Status: 503})
+		_, err := collectStream(t, exec, ctx)
+		var se StatusCodeError
+		if !errors.As(err, &se) || se.StatusCode() != 503 {
+			t.Errorf("stream err = %v, want status 503", err)
+		}
+		calls = nil
+		if _, err := exec.Execute(ctx, nil, Request{}, Options{}); !errors.As(err, &se) || se.StatusCode() != 503 {
+			t.Errorf("execute err = %v, want status 503", err)
+		}
+		if len(calls) != 0 {
+			t.Errorf("upstream was called despite injected status")
+		}
+	})
+
+	t.Run("drop after", func(t *testing.T) {
+		got, err := collectStream(t, exec, WithFaultSpec(context.Background(), FaultSpec{DropAfter: 2}))
+		if len(got) != 2 || err == nil {
+			t.Errorf("got %d chunks, err %v; want 2 chunks then an error", len(got), err)
+		}
+	})
+
+	t.Run("malformed at", func(t *testing.T) {
+		got, err := collectStream(t, exec, WithFaultSpec(context.Background(), FaultSpec{MalformedAt: 3}))
+		if err != nil || len(got) != 5 {
+			t.Fatalf("got %d chunks, err %v", len(got), err)
+		}
+		if got[2] != string(malformedChunk) || got[1] == string(malformedChunk) {
+			t.Errorf("chunks = %q, want only the third malformed", got)
+		}
+	})
+
+	t.Run("delay", func(t *testing.T) {
+		start := time.Now()
+		got, err := collectStream(t, exec, WithFaultSpec(context.Background(), FaultSpec{ChunkDelay: 10 * time.Millisecond}))
+		if err != nil || len(got) != 5 {
+			t.Fatalf("got %d chunks, err %v", len(got), err)
+		}
+		if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+			t.Errorf("stream took %s, want at least 50ms", elapsed)
+		}
+	})
+}
@@ -0,0 +1,47 @@
+package provider
+
+import "context"
+
+type labelSelectorKey struct{}
+
+// WithLabelSelector returns a context whose calls may only use accounts
+// carrying every label in selector.
+func WithLabelSelector(ctx context.Context, selector map[string]string) context.Context {
+	return context.WithValue(ctx, labelSelectorKey{}, selector)
+}
+
+// LabelSelectorFromContext returns the label selector attached to ctx, if any.
+func LabelSelectorFromContext(ctx context.Context) map[string]string {
+	if ctx == nil {
+		return nil
+	}
+	selector, _ := ctx.Value(labelSelectorKey{}).(map[string]string)
+	return selector
+}
+
+// MatchesLabels reports whether a carries every label in selector. An empty
+// selector matches every account.
+func (a *Auth) MatchesLabels(selector map[string]string) bool {
+	for name, value := range selector {
+		if v, ok := a.Labels[name]; !ok || v != value {
+			return false
+		}
+	}
+	return true
+}
+
+// LabelsFromMetadata returns the string values of the "labels" object of a
+// file-backed account's metadata.
+func LabelsFromMetadata(metadata map[string]any) map[string]string {
+	raw, ok := metadata["labels"].(map[string]any)
+	if !ok || len(raw) == 0 {
+		return nil
+	}
+	labels := make(map[string]string, len(raw))
+	for name, v := range raw {
+		if s, ok := v.(string); ok {
+			labels[name] = s
+		}
+	}
+	return labels
+}
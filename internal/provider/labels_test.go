@@ -0,0 +1,73 @@
+package provider
+
+import (
+	"context"
+	"testing"
+)
+
+// authRecorder records the account each call was routed to.
+type authRecorder struct {
+	floodExecutor
+	used []string
+}
+
+func (e *authRecorder) Identifier() string { return "labeled" }
+
+func (e *authRecorder) Execute(_ context.Context, auth *Auth, _ Request, _ Options) (Response, error) {
+	e.used = append(e.used, auth.ID)
+	return Response{Payload: []byte("ok")}, nil
+}
+
+func TestLabelSelector_RestrictsSelection(t *testing.T) {
+	exec := &authRecorder{}
+	m := NewManager(nil, nil, nil)
+	m.RegisterExecutor(exec)
+	for _, a := range []*Auth{
+		{ID: "data-premium", Provider: "labeled", Labels: map[string]string{"team": "data", "tier": "premium"}},
+		{ID: "data-basic", Provider: "labeled", Labels: map[string]string{"team": "data", "tier": "basic"}},
+		{ID: "web", Provider: "labeled", Labels: map[string]string{"team": "web"}},
+		{ID: "unlabeled", Provider: "labeled"},
+	} {
+		if _, err := m.Register(context.Background(), a); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run := func(selector map[string]string, n int) map[string]int {
+		exec.used = nil
+		ctx := context.Background()
+		if selector != nil {
+			ctx = WithLabelSelector(ctx, selector)
+		}
+		for range n {
+			if _, err := m.executeWithProvider(ctx, "labeled", Request{}, Options{ForceRotate: true}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		seen := make(map[string]int)
+		for _, id := range exec.used {
+			seen[id]++
+		}
+		return seen
+	}
+
+	seen := run(map[string]string{"team": "data"}, 20)
+	if len(seen) != 2 || seen["data-premium"] == 0 || seen["data-basic"] == 0 {
+		t.Errorf("team=data selected %v, want only and both data accounts", seen)
+	}
+	if seen := run(map[string]string{"team": "data", "tier": "premium"}, 10); len(seen) != 1 || seen["data-premium"] != 10 {
+		t.Errorf("team=data,tier=premium selected %v, want only data-premium", seen)
+	}
+	if seen := run(nil, 20); len(seen) != 4 {
+		t.Errorf("unrestricted client selected %v, want every account", seen)
+	}
+
+	ctx := WithLabelSelector(context.Background(), map[string]string{"team": "finance"})
+	if _, err := m.executeWithProvider(ctx, "labeled", Request{}, Options{}); err == nil {
+		t.Error("selector matching no account succeeded")
+	}
+	explained := m.ExplainRoute(ctx, []string{"labeled"}, "", Options{})
+	if explained.Selected != nil {
+		t.Errorf("ExplainRoute selected %+v for a selector matching no account", explained.Selected)
+	}
+}
@@ -0,0 +1,214 @@
+package provider
+
+import (
+	"bytes"
+	"sync"
+	"time"
+
+	"github.com/tidwall/gjson"
+)
+
+// LatencyStats records how long upstream calls take, both per provider and
+// model and per account. For streams it tracks the time to first token (the
+// first chunk carrying model output) separately from the full stream
+// duration, since the former is what users perceive as responsiveness.
+type LatencyStats struct {
+	mu      sync.Mutex
+	byModel map[string]*latencyMetrics // key: "provider:model"
+	byAuth  map[string]*latencyMetrics
+}
+
+type latencyMetrics struct {
+	requests     int64
+	totalLatency time.Duration
+	ttftCount    int64
+	totalTTFT    time.Duration
+	streams      int64
+	totalStream  time.Duration
+}
+
+// LatencyStatus summarizes the latency recorded for one provider and model
+// or one account. Averages are in milliseconds and zero without samples.
+type LatencyStatus struct {
+	Requests          int64   `json:"requests"`
+	AvgLatencyMs      float64 `json:"avg_latency_ms"`
+	Streams           int64   `json:"streams"`
+	AvgTTFTMs         float64 `json:"avg_ttft_ms"`
+	AvgStreamMs       float64 `json:"avg_stream_duration_ms"`
+	StreamsWithTokens int64   `json:"streams_with_tokens"`
+}
+
+// NewLatencyStats creates an empty latency tracker.
+func NewLatencyStats() *LatencyStats {
+	return &LatencyStats{
+		byModel: make(map[string]*latencyMetrics),
+		byAuth:  make(map[string]*latencyMetrics),
+	}
+}
+
+// each calls fn with the metrics of provider:model and of authID, creating
+// them as needed. The caller holds s.mu.
+func (s *LatencyStats) each(provider, model, authID string, fn func(*latencyMetrics)) {
+	for _, entry := range []struct {
+		m   map[string]*latencyMetrics
+		key string
+	}{{s.byModel, provider + ":" + model}, {s.byAuth, authID}} {
+		if entry.key == "" {
+			continue
+		}
+		m := entry.m[entry.key]
+		if m == nil {
+			m = &latencyMetrics{}
+			entry.m[entry.key] = m
+		}
+		fn(m)
+	}
+}
+
+// Latency returns the latency recorded for the manager's upstream calls.
+func (m *Manager) Latency() *LatencyStats {
+	return m.latency
+}
+
+// RecordRequest records the latency of a successful non-streaming call.
+func (s *LatencyStats) RecordRequest(provider, model, authID string, latency time.Duration) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.each(provider, model, authID, func(m *latencyMetrics) {
+		m.requests++
+		m.totalLatency += latency
+	})
+}
+
+// RecordFirstToken records a stream's time to first token.
+func (s *LatencyStats) RecordFirstToken(provider, model, authID string, ttft time.Duration) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.each(provider, model, authID, func(m *latencyMetrics) {
+		m.ttftCount++
+		m.totalTTFT += ttft
+	})
+}
+
+// RecordStream records the duration of a stream that completed.
+func (s *LatencyStats) RecordStream(provider, model, authID string, duration time.Duration) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.each(provider, model, authID, func(m *latencyMetrics) {
+		m.streams++
+		m.totalStream += duration
+	})
+}
+
+// Models returns the latency status keyed by "provider:model".
+func (s *LatencyStats) Models() map[string]LatencyStatus {
+	return s.snapshot(func(s *LatencyStats) map[string]*latencyMetrics { return s.byModel })
+}
+
+// Accounts returns the latency status keyed by auth ID.
+func (s *LatencyStats) Accounts() map[string]LatencyStatus {
+	return s.snapshot(func(s *LatencyStats) map[string]*latencyMetrics { return s.byAuth })
+}
+
+func (s *LatencyStats) snapshot(pick func(*LatencyStats) map[string]*latencyMetrics) map[string]LatencyStatus {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	src := pick(s)
+	out := make(map[string]LatencyStatus, len(src))
+	for key, m := range src {
+		out[key] = LatencyStatus{
+			Requests:          m.requests,
+			AvgLatencyMs:      avgMillis(m.totalLatency, m.requests),
+			Streams:           m.streams,
+			AvgTTFTMs:         avgMillis(m.totalTTFT, m.ttftCount),
+			AvgStreamMs:       avgMillis(m.totalStream, m.streams),
+			StreamsWithTokens: m.ttftCount,
+		}
+	}
+	return out
+}
+
+func avgMillis(total time.Duration, n int64) float64 {
+	if n == 0 {
+		return 0
+	}
+	return float64(total) / float64(n) / float64(time.Millisecond)
+}
+
+// contentPaths locate model output in the chunk formats clients receive:
+// OpenAI chat, Anthropic messages, Gemini (plain and enveloped) and Ollama.
+var contentPaths = []string{
+	"choices.#.delta.content",
+	"choices.#.delta.reasoning_content",
+	"choices.#.delta.refusal",
+	"choices.#.delta.tool_calls",
+	"delta.text",
+	"delta.thinking",
+	"delta.partial_json",
+	"content_block.name",
+	"candidates.#.content.parts.#.text",
+	"candidates.#.content.parts.#.functionCall",
+	"response.candidates.#.content.parts.#.text",
+	"response.candidates.#.content.parts.#.functionCall",
+	"message.content",
+	"message.thinking",
+	"message.tool_calls",
+}
+
+// chunkHasContent reports whether a stream chunk carries model output: text,
+// reasoning or a tool call. SSE comments such as keep-alives, role-only or
+// empty deltas, pings and usage-only chunks do not count.
+func chunkHasContent(payload []byte) bool {
+	for _, line := range bytes.Split(payload, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 || line[0] == ':' || bytes.HasPrefix(line, []byte("event:")) {
+			continue
+		}
+		line = bytes.TrimSpace(bytes.TrimPrefix(line, []byte("data:")))
+		if !gjson.ValidBytes(line) {
+			continue
+		}
+		root := gjson.ParseBytes(line)
+		// Responses API text and argument deltas carry a string "delta".
+		if d := root.Get("delta"); d.Type == gjson.String && d.Str != "" {
+			return true
+		}
+		for _, path := range contentPaths {
+			if hasValue(root.Get(path)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasValue reports whether r holds a non-empty value.
+func hasValue(r gjson.Result) bool {
+	switch {
+	case r.Type == gjson.String:
+		return r.Str != ""
+	case r.IsArray():
+		for _, v := range r.Array() {
+			if hasValue(v) {
+				return true
+			}
+		}
+		return false
+	case r.IsObject():
+		return len(r.Map()) > 0
+	default:
+		return r.Type == gjson.Number || r.Type == gjson.True
+	}
+}
@@ -0,0 +1,96 @@
+package provider
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// slowFirstTokenExecutor streams chunks without output straight away and
+// the first content chunk only after delay.
+type slowFirstTokenExecutor struct {
+	floodExecutor
+	delay time.Duration
+}
+
+func (e *slowFirstTokenExecutor) Identifier() string { return "slow" }
+
+func (e *slowFirstTokenExecutor) ExecuteStream(ctx context.Context, _ *Auth, _ Request, _ Options) (<-chan StreamChunk, error) {
+	ch := make(chan StreamChunk)
+	go func() {
+		defer close(ch)
+		send := func(payload string) bool {
+			select {
+			case ch <- StreamChunk{Payload: []byte(payload)}:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		if !send(": keep-alive\n\n") || !send(`data: {"choices":[{"delta":{"role":"assistant","content":""}}]}`+"\n\n") {
+			return
+		}
+		time.Sleep(e.delay)
+		if !send(`data: {"choices":[{"delta":{"content":"Hi"}}]}` + "\n\n") {
+			return
+		}
+		time.Sleep(e.delay)
+		send("data: [DONE]\n\n")
+	}()
+	return ch, nil
+}
+
+func TestLatencyStats_TimeToFirstToken(t *testing.T) {
+	const delay = 50 * time.Millisecond
+	m := NewManager(nil, nil, nil)
+	m.RegisterExecutor(&slowFirstTokenExecutor{delay: delay})
+	if _, err := m.Register(context.Background(), &Auth{ID: "slow-1", Provider: "slow"}); err != nil {
+		t.Fatal(err)
+	}
+	out, err := m.executeStreamWithProvider(context.Background(), "slow", Request{}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range out {
+	}
+
+	for name, status := range map[string]LatencyStatus{"model": m.Latency().Models()["slow:"], "account": m.Latency().Accounts()["slow-1"]} {
+		if status.Streams != 1 || status.StreamsWithTokens != 1 {
+			t.Fatalf("%s: %+v, want one stream with a first token", name, status)
+		}
+		ttft := time.Duration(status.AvgTTFTMs * float64(time.Millisecond))
+		if ttft < delay || ttft >= 2*delay {
+			t.Errorf("%s: time to first token %s, want between %s and %s", name, ttft, delay, 2*delay)
+		}
+		if stream := time.Duration(status.AvgStreamMs * float64(time.Millisecond)); stream < 2*delay {
+			t.Errorf("%s: stream duration %s, want at least %s", name, stream, 2*delay)
+		}
+	}
+}
+
+func TestChunkHasContent(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    bool
+	}{
+		{"keep-alive", ": keep-alive\n\n", false},
+		{"role only", `data: {"choices":[{"delta":{"role":"assistant"}}]}`, false},
+		{"empty content", `data: {"choices":[{"delta":{"content":""}}]}`, false},
+		{"usage only", `data: {"choices":[],"usage":{"total_tokens":3}}`, false},
+		{"done", "data: [DONE]", false},
+		{"claude ping", "event: ping\ndata: {\"type\":\"ping\"}", false},
+		{"claude stop", `data: {"type":"message_delta","delta":{"stop_reason":"end_turn"}}`, false},
+		{"openai text", `data: {"choices":[{"delta":{"content":"Hi"}}]}`, true},
+		{"openai tool call", `data: {"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"name":"f"}}]}}]}`, true},
+		{"claude text", "event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"Hi\"}}", true},
+		{"gemini text", `data: {"candidates":[{"content":{"parts":[{"text":"Hi"}]}}]}`, true},
+		{"responses delta", `data: {"type":"response.output_text.delta","delta":"Hi"}`, true},
+		{"ollama", `{"message":{"role":"assistant","content":"Hi"},"done":false}`, true},
+	}
+	for _, tt := range tests {
+		if got := chunkHasContent([]byte(tt.payload)); got != tt.want {
+			t.Errorf("%s: chunkHasContent = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strings"
 	"sync"
@@ -20,6 +21,11 @@ func init() {
 		if err == nil {
 			return true
 		}
+		// Configured retryable errors count whatever their message says.
+		var re *retryableError
+		if errors.As(err, &re) {
+			return false
+		}
 		cat := CategorizeError(0, err.Error())
 		return cat.IsUserFault()
 	}
@@ -91,19 +97,37 @@ func (NoopHook) OnResult(context.Context, Result) {}
 type Manager struct {
 	store     Store
 	executors map[string]ProviderExecutor
-	selector  Selector
-	hook      Hook
-	mu        sync.RWMutex
-	auths     map[string]*Auth
-
-	providerCounter atomic.Uint64
-	providerStats   *ProviderStats
+	// baseExecutors holds executors as registered, before middleware wrapping,
+	// so optional interfaces such as RequestPreparer stay reachable.
+	baseExecutors map[string]ProviderExecutor
+	middleware    []ExecutorMiddleware
+	selector      Selector
+	hook          Hook
+	mu            sync.RWMutex
+	auths         map[string]*Auth
+
+	providerCounter  atomic.Uint64
+	providerStats    *ProviderStats
+	latency          *LatencyStats
+	cancelledStreams atomic.Int64
+
+	backpressuredStreams atomic.Int64
+	streamBufferSize     atomic.Int32
 
 	requestRetry     atomic.Int32
 	maxRetryInterval atomic.Int64
+	retryBudget      atomic.Pointer[RetryBudget]
+	streamIdle       atomic.Pointer[StreamIdleTimeoutFunc]
+	retryableErrors  atomic.Pointer[RetryableErrorFunc]
+	dailyQuota       atomic.Pointer[DailyQuota]
+	concurrency      *concurrencyTracker
+
+	preflightMu sync.RWMutex
+	preflight   map[string]PreflightResult
 
 	rtProvider RoundTripperProvider
 
+	refreshLocker RefreshLocker
 	refreshCancel context.CancelFunc
 
 	breakerMu sync.RWMutex
@@ -121,11 +145,14 @@ func NewManager(store Store, selector Selector, hook Hook) *Manager {
 	m := &Manager{
 		store:         store,
 		executors:     make(map[string]ProviderExecutor),
+		baseExecutors: make(map[string]ProviderExecutor),
 		selector:      selector,
 		hook:          hook,
 		auths:         make(map[string]*Auth),
 		providerStats: NewProviderStats(),
+		latency:       NewLatencyStats(),
 		breakers:      make(map[string]*resilience.CircuitBreaker),
+		concurrency:   newConcurrencyTracker(),
 	}
 	if lc, ok := selector.(SelectorLifecycle); ok {
 		lc.Start()
@@ -146,6 +173,32 @@ func (m *Manager) Stop() {
 	}
 }
 
+// SetSelector replaces the selector, stopping the previous one and starting
+// the new one when they need lifecycle management. A nil selector restores
+// round-robin selection.
+func (m *Manager) SetSelector(selector Selector) {
+	if selector == nil {
+		selector = &RoundRobinSelector{}
+	}
+	if lc, ok := selector.(SelectorLifecycle); ok {
+		lc.Start()
+	}
+	m.mu.Lock()
+	previous := m.selector
+	m.selector = selector
+	m.mu.Unlock()
+	if lc, ok := previous.(SelectorLifecycle); ok {
+		lc.Stop()
+	}
+}
+
+// Selector returns the selector in use.
+func (m *Manager) Selector() Selector {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.selector
+}
+
 // SetStore swaps the underlying persistence store.
 func (m *Manager) SetStore(store Store) {
 	m.mu.Lock()
@@ -153,6 +206,15 @@ func (m *Manager) SetStore(store Store) {
 	m.store = store
 }
 
+// SetRefreshLocker sets the lock held while refreshing an account, for
+// deployments whose store is shared but cannot lock by itself. A nil locker
+// falls back to the store's own lock, when it is a SharedStore.
+func (m *Manager) SetRefreshLocker(locker RefreshLocker) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.refreshLocker = locker
+}
+
 // SetRoundTripperProvider register a provider that returns a per-auth RoundTripper.
 func (m *Manager) SetRoundTripperProvider(p RoundTripperProvider) {
 	m.mu.Lock()
@@ -175,14 +237,30 @@ func (m *Manager) SetRetryConfig(retry int, maxRetryInterval time.Duration) {
 	m.maxRetryInterval.Store(maxRetryInterval.Nanoseconds())
 }
 
-// RegisterExecutor registers a provider executor with the manager.
+// Use appends executor middleware. Middleware applies to executors registered
+// before and after the call; the first middleware added is the outermost.
+func (m *Manager) Use(mws ...ExecutorMiddleware) {
+	if len(mws) == 0 {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.middleware = append(m.middleware, mws...)
+	for id, exec := range m.baseExecutors {
+		m.executors[id] = ChainExecutor(exec, m.middleware...)
+	}
+}
+
+// RegisterExecutor registers a provider executor with the manager, wrapped
+// in any middleware installed with Use.
 func (m *Manager) RegisterExecutor(executor ProviderExecutor) {
 	if executor == nil {
 		return
 	}
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	m.executors[executor.Identifier()] = executor
+	m.baseExecutors[executor.Identifier()] = executor
+	m.executors[executor.Identifier()] = ChainExecutor(executor, m.middleware...)
 }
 
 // UnregisterExecutor removes the executor associated with the provider key.
@@ -193,6 +271,7 @@ func (m *Manager) UnregisterExecutor(provider string) {
 	}
 	m.mu.Lock()
 	delete(m.executors, provider)
+	delete(m.baseExecutors, provider)
 	m.mu.Unlock()
 }
 
@@ -218,6 +297,10 @@ func (m *Manager) Update(ctx context.Context, auth *Auth) (*Auth, error) {
 	if auth == nil || auth.ID == "" {
 		return nil, nil
 	}
+	return m.update(ctx, auth, true), nil
+}
+
+func (m *Manager) update(ctx context.Context, auth *Auth, persist bool) *Auth {
 	m.mu.Lock()
 	if existing, ok := m.auths[auth.ID]; ok && existing != nil && !auth.indexAssigned && auth.Index == 0 {
 		auth.Index = existing.Index
@@ -226,9 +309,11 @@ func (m *Manager) Update(ctx context.Context, auth *Auth) (*Auth, error) {
 	auth.EnsureIndex()
 	m.auths[auth.ID] = auth.Clone()
 	m.mu.Unlock()
-	_ = m.persist(ctx, auth)
+	if persist {
+		_ = m.persist(ctx, auth)
+	}
 	m.hook.OnAuthUpdated(ctx, auth.Clone())
-	return auth.Clone(), nil
+	return auth.Clone()
 }
 
 // Load resets manager state from the backing store.
@@ -276,6 +361,9 @@ func (m *Manager) Execute(ctx context.Context, providers []string, req Request,
 			lastProvider = provider
 			return m.executeWithProvider(execCtx, provider, req, opts)
 		})
+		if attempt == 0 {
+			m.RetryBudget().RecordRequest(lastProvider)
+		}
 		latency := time.Since(start)
 
 		if errExec == nil {
@@ -289,7 +377,7 @@ func (m *Manager) Execute(ctx context.Context, providers []string, req Request,
 		lastErr = errExec
 
 		wait, shouldRetry := m.shouldRetryAfterError(errExec, attempt, attempts, selected, req.Model, maxWait)
-		if !shouldRetry {
+		if !shouldRetry || !m.spendRetry(lastProvider) {
 			break
 		}
 		if errWait := waitForCooldown(ctx, wait); errWait != nil {
@@ -325,6 +413,9 @@ func (m *Manager) ExecuteCount(ctx context.Context, providers []string, req Requ
 			lastProvider = provider
 			return m.executeCountWithProvider(execCtx, provider, req, opts)
 		})
+		if attempt == 0 {
+			m.RetryBudget().RecordRequest(lastProvider)
+		}
 		latency := time.Since(start)
 
 		if errExec == nil {
@@ -336,7 +427,7 @@ func (m *Manager) ExecuteCount(ctx context.Context, providers []string, req Requ
 		lastErr = errExec
 
 		wait, shouldRetry := m.shouldRetryAfterError(errExec, attempt, attempts, selected, req.Model, maxWait)
-		if !shouldRetry {
+		if !shouldRetry || !m.spendRetry(lastProvider) {
 			break
 		}
 		if errWait := waitForCooldown(ctx, wait); errWait != nil {
@@ -372,6 +463,9 @@ func (m *Manager) ExecuteStream(ctx context.Context, providers []string, req Req
 			lastProvider = provider
 			return m.executeStreamWithProvider(execCtx, provider, req, opts)
 		})
+		if attempt == 0 {
+			m.RetryBudget().RecordRequest(lastProvider)
+		}
 
 		if errStream == nil {
 			// Wrap channel to track completion for stats
@@ -382,7 +476,7 @@ func (m *Manager) ExecuteStream(ctx context.Context, providers []string, req Req
 		lastErr = errStream
 
 		wait, shouldRetry := m.shouldRetryAfterError(errStream, attempt, attempts, selected, req.Model, maxWait)
-		if !shouldRetry {
+		if !shouldRetry || !m.spendRetry(lastProvider) {
 			break
 		}
 		if errWait := waitForCooldown(ctx, wait); errWait != nil {
@@ -437,7 +531,10 @@ func (m *Manager) MarkResult(ctx context.Context, result Result) {
 				clearAuthStateOnSuccess(auth, now)
 			}
 		} else {
-			if result.Model != "" {
+			// Expired credentials fail every model, so they mark the account
+			// itself rather than the model.
+			reauth := result.Error != nil && CategorizeError(statusCodeFromResult(result.Error), result.Error.Message) == CategoryReauthRequired
+			if result.Model != "" && !reauth {
 				state := ensureModelState(auth, result.Model)
 				statusCode := statusCodeFromResult(result.Error)
 
@@ -602,20 +699,39 @@ func (m *Manager) pickNext(ctx context.Context, provider, model string, opts Opt
 		modelKey = strings.TrimSpace(model)
 	}
 	registryRef := registry.GetGlobalRegistry()
+	quota := m.dailyQuota.Load()
+	labels := LabelSelectorFromContext(ctx)
+	now := time.Now()
+	var quotaReset time.Time
+	saturated := false
 	for _, candidate := range m.auths {
-		if candidate.Provider != provider || candidate.Disabled {
+		if candidate.Provider != provider {
 			continue
 		}
 		if _, used := tried[candidate.ID]; used {
 			continue
 		}
-		if modelKey != "" && registryRef != nil && !registryRef.ClientSupportsModel(candidate.ID, modelKey) {
+		reason, resetAt := candidateSkipReason(candidate, modelKey, opts, labels, registryRef, quota, now)
+		if reason == skipReasonDailyQuota && (quotaReset.IsZero() || resetAt.Before(quotaReset)) {
+			quotaReset = resetAt
+		}
+		if reason != "" {
+			continue
+		}
+		if m.concurrency.full(candidate) {
+			saturated = true
 			continue
 		}
 		candidates = append(candidates, candidate)
 	}
 	if len(candidates) == 0 {
 		m.mu.RUnlock()
+		if saturated {
+			return nil, nil, newSaturatedError(provider, time.Second)
+		}
+		if !quotaReset.IsZero() {
+			return nil, nil, newDailyQuotaError(provider, quotaReset.Sub(now))
+		}
 		return nil, nil, &Error{Code: "auth_not_found", Message: "no auth available"}
 	}
 	selected, errPick := m.selector.Pick(ctx, provider, model, opts, candidates)
@@ -640,6 +756,43 @@ func (m *Manager) pickNext(ctx context.Context, provider, model string, opts Opt
 	return authCopy, executor, nil
 }
 
+// Reasons an auth is not a candidate for a request, as reported by ExplainRoute.
+const (
+	skipReasonDisabled    = "disabled"
+	skipReasonNotPinned   = "not the pinned account"
+	skipReasonLabels      = "labels do not match the client key"
+	skipReasonModel       = "model not supported by account"
+	skipReasonDailyQuota  = "daily quota exhausted"
+	skipReasonBusy        = "at concurrency limit"
+	skipReasonCooldown    = "cooling down"
+	skipReasonUnavailable = "unavailable"
+	skipReasonBreakerOpen = "circuit breaker open"
+	skipReasonNoExecutor  = "executor not registered"
+	skipReasonNoAccount   = "no available account"
+)
+
+// candidateSkipReason reports why candidate cannot serve modelKey for a
+// client limited to labels, or "" when it is a candidate. resetAt is when an
+// exhausted daily quota resets.
+func candidateSkipReason(candidate *Auth, modelKey string, opts Options, labels map[string]string, reg *registry.ModelRegistry, quota *DailyQuota, now time.Time) (reason string, resetAt time.Time) {
+	if candidate.Disabled {
+		return skipReasonDisabled, time.Time{}
+	}
+	if !candidate.MatchesLabels(labels) {
+		return skipReasonLabels, time.Time{}
+	}
+	if opts.AuthID != "" && candidate.ID != opts.AuthID {
+		return skipReasonNotPinned, time.Time{}
+	}
+	if modelKey != "" && reg != nil && !IsCatchAll(opts.Metadata) && !reg.ClientSupportsModel(candidate.ID, modelKey) {
+		return skipReasonModel, time.Time{}
+	}
+	if exhausted, resetAt := quota.exhausted(candidate, now); exhausted {
+		return skipReasonDailyQuota, resetAt
+	}
+	return "", time.Time{}
+}
+
 func (m *Manager) persist(ctx context.Context, auth *Auth) error {
 	if m.store == nil || auth == nil {
 		return nil
@@ -672,16 +825,38 @@ func (m *Manager) markRefreshPending(id string, now time.Time) bool {
 	return true
 }
 
-func (m *Manager) refreshAuth(ctx context.Context, id string) {
+// refreshAuth refreshes the credentials of the registered auth id and stores
+// the result. Failures are recorded on the auth and returned.
+func (m *Manager) refreshAuth(ctx context.Context, id string) error {
 	m.mu.RLock()
 	auth := m.auths[id]
 	var exec ProviderExecutor
 	if auth != nil {
 		exec = m.executors[auth.Provider]
 	}
+	locker := m.refreshLocker
+	if shared, ok := m.store.(SharedStore); ok && locker == nil {
+		locker = shared
+	}
+	loader, _ := m.store.(authLoader)
 	m.mu.RUnlock()
-	if auth == nil || exec == nil {
-		return
+	if auth == nil {
+		return &Error{Code: "auth_not_found", Message: "auth not registered"}
+	}
+	if exec == nil {
+		return &Error{Code: "provider_not_found", Message: "no executor registered for provider " + auth.Provider}
+	}
+	if locker != nil {
+		// Other instances refresh the same accounts: refresh under the lock,
+		// unless one of them already did while this one waited.
+		unlock, errLock := locker.LockRefresh(ctx, id)
+		if errLock != nil {
+			return errLock
+		}
+		defer unlock()
+		if loader != nil && m.adoptStoredRefresh(ctx, loader, auth) {
+			return nil
+		}
 	}
 	cloned := auth.Clone()
 	authUpdatedAt := auth.UpdatedAt
@@ -689,14 +864,8 @@ func (m *Manager) refreshAuth(ctx context.Context, id string) {
 	log.Debugf("refreshed %s, %s, %v", auth.Provider, auth.ID, err)
 	now := time.Now()
 	if err != nil {
-		m.mu.Lock()
-		if current := m.auths[id]; current != nil && current.UpdatedAt == authUpdatedAt {
-			current.NextRefreshAfter = now.Add(refreshFailureBackoff)
-			current.LastError = &Error{Message: err.Error()}
-			m.auths[id] = current
-		}
-		m.mu.Unlock()
-		return
+		m.markRefreshFailed(id, authUpdatedAt, err, now)
+		return err
 	}
 	if updated == nil {
 		updated = cloned
@@ -710,7 +879,87 @@ func (m *Manager) refreshAuth(ctx context.Context, id string) {
 	updated.NextRefreshAfter = time.Time{}
 	updated.LastError = nil
 	updated.UpdatedAt = now
-	_, _ = m.Update(ctx, updated)
+
+	// A rotated refresh token invalidates the previous one, so it must reach
+	// the store before the old value is dropped from memory.
+	persisted := false
+	if refreshTokenRotated(auth, updated) {
+		// Metadata now carries the new token; Storage would write the stale
+		// one. Only the stored copy drops it: executors such as Kiro refresh
+		// through the in-memory Storage.
+		record := updated.Clone()
+		record.Storage = nil
+		if errPersist := m.persist(ctx, record); errPersist != nil {
+			log.Errorf("failed to persist rotated refresh token for %s: %v", auth.ID, errPersist)
+			m.markRefreshFailed(id, authUpdatedAt, errPersist, now)
+			return errPersist
+		}
+		persisted = true
+	}
+	m.update(ctx, updated, !persisted)
+	return nil
+}
+
+// markRefreshFailed backs off the next refresh of id and records err, unless
+// the auth changed since the refresh began.
+func (m *Manager) markRefreshFailed(id string, authUpdatedAt time.Time, err error, now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	current := m.auths[id]
+	if current == nil || current.UpdatedAt != authUpdatedAt {
+		return
+	}
+	current.NextRefreshAfter = now.Add(refreshFailureBackoff)
+	current.LastError = &Error{Message: err.Error()}
+	if categoryFromError(err) == CategoryReauthRequired {
+		applyAuthFailureState(current, &Error{Message: err.Error(), HTTPStatus: statusCodeFromError(err), Category: CategoryReauthRequired}, nil, now)
+	}
+}
+
+// refreshTokenRotated reports whether a refresh returned a different refresh token.
+func refreshTokenRotated(before, after *Auth) bool {
+	next := refreshTokenOf(after)
+	return next != "" && next != refreshTokenOf(before)
+}
+
+func refreshTokenOf(auth *Auth) string {
+	if auth == nil || auth.Metadata == nil {
+		return ""
+	}
+	for _, key := range []string{"refresh_token", "refreshToken"} {
+		if v, ok := auth.Metadata[key].(string); ok && v != "" {
+			return v
+		}
+	}
+	if token, ok := auth.Metadata["token"].(map[string]any); ok {
+		if v, ok := token["refresh_token"].(string); ok {
+			return v
+		}
+	}
+	return ""
+}
+
+// RefreshCredentials runs the provider executor's Refresh against a copy of
+// auth without registering it, so callers can validate credentials before
+// committing them.
+func (m *Manager) RefreshCredentials(ctx context.Context, auth *Auth) (*Auth, error) {
+	if auth == nil {
+		return nil, &Error{Code: "auth_not_found", Message: "auth is nil"}
+	}
+	exec := m.executorFor(auth.Provider)
+	if exec == nil {
+		return nil, &Error{Code: "provider_not_found", Message: "no executor registered for provider " + auth.Provider}
+	}
+	cloned := auth.Clone()
+	updated, err := exec.Refresh(ctx, cloned)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		updated = cloned
+	}
+	updated.LastRefreshedAt = time.Now()
+	return updated, nil
 }
 
 func (m *Manager) executorFor(provider string) ProviderExecutor {
@@ -744,6 +993,51 @@ type RequestPreparer interface {
 	PrepareRequest(req *http.Request, auth *Auth) error
 }
 
+// ModelLister is an optional interface that provider executors can implement
+// to declare the models they serve. The service registers these models for
+// every auth of the executor's provider, so custom providers need no manual
+// registry calls.
+type ModelLister interface {
+	Models() []*registry.ModelInfo
+}
+
+// CapabilityDeclarer is an optional interface that provider executors can
+// implement to declare the request features a model supports, enabling
+// capability-based routing for listed models that leave Capabilities unset.
+type CapabilityDeclarer interface {
+	ModelCapabilities(modelID string) registry.Capability
+}
+
+// ExecutorModels returns the models declared by the executor registered for
+// provider through ModelLister, with capabilities filled in from
+// CapabilityDeclarer. It returns nil when the executor declares none.
+func (m *Manager) ExecutorModels(provider string) []*registry.ModelInfo {
+	m.mu.RLock()
+	exec := m.baseExecutors[provider]
+	m.mu.RUnlock()
+	lister, ok := exec.(ModelLister)
+	if !ok {
+		return nil
+	}
+	listed := lister.Models()
+	if len(listed) == 0 {
+		return nil
+	}
+	declarer, _ := exec.(CapabilityDeclarer)
+	models := make([]*registry.ModelInfo, 0, len(listed))
+	for _, model := range listed {
+		if model == nil || model.ID == "" {
+			continue
+		}
+		cp := *model
+		if cp.Capabilities == 0 && declarer != nil {
+			cp.Capabilities = declarer.ModelCapabilities(cp.ID)
+		}
+		models = append(models, &cp)
+	}
+	return models
+}
+
 // InjectCredentials delegates per-provider HTTP request preparation when supported.
 // If the registered executor for the auth provider implements RequestPreparer,
 // it will be invoked to modify the request (e.g., add headers).
@@ -755,7 +1049,7 @@ func (m *Manager) InjectCredentials(req *http.Request, authID string) error {
 	a := m.auths[authID]
 	var exec ProviderExecutor
 	if a != nil {
-		exec = m.executors[a.Provider]
+		exec = m.baseExecutors[a.Provider]
 	}
 	m.mu.RUnlock()
 	if a == nil || exec == nil {
@@ -799,3 +1093,15 @@ func (m *Manager) BreakerState(provider string) gobreaker.State {
 	}
 	return cb.State()
 }
+
+// SelectionStats returns per-auth selection counts when the selector tracks
+// them, or nil otherwise.
+func (m *Manager) SelectionStats() map[string]SelectionStats {
+	m.mu.RLock()
+	selector := m.selector
+	m.mu.RUnlock()
+	if r, ok := selector.(SelectionReporter); ok {
+		return r.SelectionStats()
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package provider
+
+import (
+	"context"
+	"time"
+
+	log "github.com/nghyane/llm-mux/internal/logging"
+)
+
+// ExecutorMiddleware decorates a ProviderExecutor with cross-cutting behavior.
+// Implementations must return an executor with the same Identifier as next.
+type ExecutorMiddleware func(next ProviderExecutor) ProviderExecutor
+
+// ChainExecutor wraps exec with mws. The first middleware is the outermost:
+// it sees each call first and its result last, so ChainExecutor(e, a, b)
+// runs a -> b -> e on the way in and e -> b -> a on the way out.
+func ChainExecutor(exec ProviderExecutor, mws ...ExecutorMiddleware) ProviderExecutor {
+	if exec == nil {
+		return nil
+	}
+	for i := len(mws) - 1; i >= 0; i-- {
+		if mws[i] == nil {
+			continue
+		}
+		if wrapped := mws[i](exec); wrapped != nil {
+			exec = wrapped
+		}
+	}
+	return exec
+}
+
+// ExecutorFuncs adapts individual functions to a ProviderExecutor, falling
+// back to Next for any function left nil. It is the building block for
+// middleware that only needs to intercept some calls.
+type ExecutorFuncs struct {
+	Next              ProviderExecutor
+	ExecuteFunc       func(ctx context.Context, auth *Auth, req Request, opts Options) (Response, error)
+	ExecuteStreamFunc func(ctx context.Context, auth *Auth, req Request, opts Options) (<-chan StreamChunk, error)
+	RefreshFunc       func(ctx context.Context, auth *Auth) (*Auth, error)
+	CountTokensFunc   func(ctx context.Context, auth *Auth, req Request, opts Options) (Response, error)
+}
+
+// Identifier implements ProviderExecutor.
+func (f *ExecutorFuncs) Identifier() string { return f.Next.Identifier() }
+
+// Execute implements ProviderExecutor.
+func (f *ExecutorFuncs) Execute(ctx context.Context, auth *Auth, req Request, opts Options) (Response, error) {
+	if f.ExecuteFunc != nil {
+		return f.ExecuteFunc(ctx, auth, req, opts)
+	}
+	return f.Next.Execute(ctx, auth, req, opts)
+}
+
+// ExecuteStream implements ProviderExecutor.
+func (f *ExecutorFuncs) ExecuteStream(ctx context.Context, auth *Auth, req Request, opts Options) (<-chan StreamChunk, error) {
+	if f.ExecuteStreamFunc != nil {
+		return f.ExecuteStreamFunc(ctx, auth, req, opts)
+	}
+	return f.Next.ExecuteStream(ctx, auth, req, opts)
+}
+
+// Refresh implements ProviderExecutor.
+func (f *ExecutorFuncs) Refresh(ctx context.Context, auth *Auth) (*Auth, error) {
+	if f.RefreshFunc != nil {
+		return f.RefreshFunc(ctx, auth)
+	}
+	return f.Next.Refresh(ctx, auth)
+}
+
+// CountTokens implements ProviderExecutor.
+func (f *ExecutorFuncs) CountTokens(ctx context.Context, auth *Auth, req Request, opts Options) (Response, error) {
+	if f.CountTokensFunc != nil {
+		return f.CountTokensFunc(ctx, auth, req, opts)
+	}
+	return f.Next.CountTokens(ctx, auth, req, opts)
+}
+
+// LoggingMiddleware logs every executor call at debug level with its
+// provider, model, auth and duration; failures are logged as warnings.
+// Streaming calls are timed until the upstream stream is open.
+func LoggingMiddleware() ExecutorMiddleware {
+	return func(next ProviderExecutor) ProviderExecutor {
+		provider := next.Identifier()
+		logCall := func(op string, auth *Auth, model string, start time.Time, err error) {
+			authID := ""
+			if auth != nil {
+				authID = auth.ID
+			}
+			if err != nil {
+				log.Warnf("executor %s %s: model=%s auth=%s duration=%s error=%v", provider, op, model, authID, time.Since(start), err)
+				return
+			}
+			log.Debugf("executor %s %s: model=%s auth=%s duration=%s", provider, op, model, authID, time.Since(start))
+		}
+		return &ExecutorFuncs{
+			Next: next,
+			ExecuteFunc: func(ctx context.Context, auth *Auth, req Request, opts Options) (Response, error) {
+				start := time.Now()
+				resp, err := next.Execute(ctx, auth, req, opts)
+				logCall("execute", auth, req.Model, start, err)
+				return resp, err
+			},
+			ExecuteStreamFunc: func(ctx context.Context, auth *Auth, req Request, opts Options) (<-chan StreamChunk, error) {
+				start := time.Now()
+				ch, err := next.ExecuteStream(ctx, auth, req, opts)
+				logCall("stream", auth, req.Model, start, err)
+				return ch, err
+			},
+			RefreshFunc: func(ctx context.Context, auth *Auth) (*Auth, error) {
+				start := time.Now()
+				updated, err := next.Refresh(ctx, auth)
+				logCall("refresh", auth, "", start, err)
+				return updated, err
+			},
+			CountTokensFunc: func(ctx context.Context, auth *Auth, req Request, opts Options) (Response, error) {
+				start := time.Now()
+				resp, err := next.CountTokens(ctx, auth, req, opts)
+				logCall("count_tokens", auth, req.Model, start, err)
+				return resp, err
+			},
+		}
+	}
+}
+
+// MetricsMiddleware records the outcome and latency of Execute and
+// ExecuteStream calls into stats, keyed by provider and model. Streaming
+// latency is measured until the upstream stream is open.
+func MetricsMiddleware(stats *ProviderStats) ExecutorMiddleware {
+	return func(next ProviderExecutor) ProviderExecutor {
+		if stats == nil {
+			return next
+		}
+		provider := next.Identifier()
+		record := func(model string, start time.Time, err error) {
+			if err != nil {
+				stats.RecordFailure(provider, model)
+				return
+			}
+			stats.RecordSuccess(provider, model, time.Since(start))
+		}
+		return &ExecutorFuncs{
+			Next: next,
+			ExecuteFunc: func(ctx context.Context, auth *Auth, req Request, opts Options) (Response, error) {
+				start := time.Now()
+				resp, err := next.Execute(ctx, auth, req, opts)
+				record(req.Model, start, err)
+				return resp, err
+			},
+			ExecuteStreamFunc: func(ctx context.Context, auth *Auth, req Request, opts Options) (<-chan StreamChunk, error) {
+				start := time.Now()
+				ch, err := next.ExecuteStream(ctx, auth, req, opts)
+				record(req.Model, start, err)
+				return ch, err
+			},
+		}
+	}
+}
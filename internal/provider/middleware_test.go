@@ -0,0 +1,106 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+// echoExecutor records the calls it receives and optionally fails them.
+type echoExecutor struct {
+	calls    *[]string
+	fail     bool
+	prepared bool
+}
+
+func (e *echoExecutor) Identifier() string { return "echo" }
+
+func (e *echoExecutor) Execute(context.Context, *Auth, Request, Options) (Response, error) {
+	*e.calls = append(*e.calls, "exec")
+	if e.fail {
+		return Response{}, errors.New("upstream failed")
+	}
+	return Response{Payload: []byte("ok")}, nil
+}
+
+func (e *echoExecutor) ExecuteStream(context.Context, *Auth, Request, Options) (<-chan StreamChunk, error) {
+	ch := make(chan StreamChunk)
+	close(ch)
+	return ch, nil
+}
+
+func (e *echoExecutor) Refresh(_ context.Context, auth *Auth) (*Auth, error) { return auth, nil }
+
+func (e *echoExecutor) CountTokens(context.Context, *Auth, Request, Options) (Response, error) {
+	return Response{}, nil
+}
+
+func (e *echoExecutor) PrepareRequest(*http.Request, *Auth) error {
+	e.prepared = true
+	return nil
+}
+
+func tracing(name string, calls *[]string) ExecutorMiddleware {
+	return func(next ProviderExecutor) ProviderExecutor {
+		return &ExecutorFuncs{
+			Next: next,
+			ExecuteFunc: func(ctx context.Context, auth *Auth, req Request, opts Options) (Response, error) {
+				*calls = append(*calls, name+">")
+				resp, err := next.Execute(ctx, auth, req, opts)
+				*calls = append(*calls, "<"+name)
+				return resp, err
+			},
+		}
+	}
+}
+
+func TestManagerUse_MiddlewareOrder(t *testing.T) {
+	var calls []string
+	exec := &echoExecutor{calls: &calls}
+
+	m := NewManager(nil, nil, nil)
+	m.Use(tracing("a", &calls))
+	m.RegisterExecutor(exec)
+	// Middleware added after registration still wraps the executor.
+	m.Use(tracing("b", &calls))
+	if _, err := m.Register(context.Background(), &Auth{ID: "echo-1", Provider: "echo"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := m.executeWithProvider(context.Background(), "echo", Request{}, Options{}); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	want := []string{"a>", "b>", "exec", "<b", "<a"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Fatalf("calls = %v, want %v", calls, want)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	if err := m.InjectCredentials(req, "echo-1"); err != nil {
+		t.Fatal(err)
+	}
+	if !exec.prepared {
+		t.Error("RequestPreparer on the wrapped executor was not reached")
+	}
+}
+
+func TestMetricsMiddleware_RecordsOutcomes(t *testing.T) {
+	var calls []string
+	stats := NewProviderStats()
+	ok := ChainExecutor(&echoExecutor{calls: &calls}, MetricsMiddleware(stats), LoggingMiddleware())
+	bad := ChainExecutor(&echoExecutor{calls: &calls, fail: true}, MetricsMiddleware(stats))
+
+	if ok.Identifier() != "echo" {
+		t.Fatalf("identifier = %q", ok.Identifier())
+	}
+	_, _ = ok.Execute(context.Background(), nil, Request{Model: "m"}, Options{})
+	_, _ = ok.Execute(context.Background(), nil, Request{Model: "m"}, Options{})
+	_, _ = bad.Execute(context.Background(), nil, Request{Model: "m"}, Options{})
+
+	got := stats.Stats()["echo:m"]
+	if got["success"] != 2 || got["failure"] != 1 {
+		t.Fatalf("stats = %v, want 2 successes and 1 failure", got)
+	}
+}
@@ -0,0 +1,56 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/registry"
+)
+
+// listingExecutor is a custom executor that declares its own models.
+type listingExecutor struct {
+	echoExecutor
+}
+
+func (e *listingExecutor) Identifier() string { return "acme" }
+
+func (e *listingExecutor) Models() []*registry.ModelInfo {
+	return []*registry.ModelInfo{
+		{ID: "acme-large"},
+		{ID: "acme-vision", Capabilities: registry.CapVision},
+		nil,
+	}
+}
+
+func (e *listingExecutor) ModelCapabilities(modelID string) registry.Capability {
+	if modelID == "acme-large" {
+		return registry.CapTools | registry.CapJSONSchema
+	}
+	return registry.CapTools
+}
+
+func TestManagerExecutorModels(t *testing.T) {
+	var calls []string
+	m := NewManager(nil, nil, nil)
+	m.RegisterExecutor(&echoExecutor{calls: &calls})
+	// Middleware must not hide the optional interfaces.
+	m.Use(LoggingMiddleware())
+	m.RegisterExecutor(&listingExecutor{echoExecutor{calls: &calls}})
+
+	if got := m.ExecutorModels("echo"); got != nil {
+		t.Fatalf("executor without ModelLister returned %v", got)
+	}
+
+	models := m.ExecutorModels("acme")
+	if len(models) != 2 {
+		t.Fatalf("models = %d, want 2", len(models))
+	}
+	want := map[string]registry.Capability{
+		"acme-large":  registry.CapTools | registry.CapJSONSchema,
+		"acme-vision": registry.CapVision,
+	}
+	for _, model := range models {
+		if model.Capabilities != want[model.ID] {
+			t.Errorf("%s capabilities = %s, want %s", model.ID, model.Capabilities, want[model.ID])
+		}
+	}
+}
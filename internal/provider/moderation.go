@@ -0,0 +1,26 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+)
+
+// Moderator is an optional interface that provider executors implement to
+// serve /v1/moderations. Moderate receives the client's OpenAI moderation
+// payload and returns the response in the OpenAI moderation shape.
+type Moderator interface {
+	Moderate(ctx context.Context, auth *Auth, req Request, opts Options) (Response, error)
+}
+
+// ExecuteModerate runs a moderation request on provider. Any auth of the
+// provider may serve it, since moderation models are not registered per auth.
+// It fails with 501 when the provider has no moderation support.
+func (m *Manager) ExecuteModerate(ctx context.Context, provider string, req Request, opts Options) (Response, error) {
+	m.mu.RLock()
+	moderator, ok := m.baseExecutors[provider].(Moderator)
+	m.mu.RUnlock()
+	if !ok {
+		return Response{}, &Error{Code: "moderation_not_supported", Message: "provider " + provider + " has no moderation API", HTTPStatus: http.StatusNotImplemented}
+	}
+	return m.executeWithAuths(ctx, provider, "", req, opts, moderator.Moderate)
+}
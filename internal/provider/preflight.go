@@ -0,0 +1,173 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/nghyane/llm-mux/internal/logging"
+)
+
+const (
+	defaultPreflightConcurrency = 4
+	defaultPreflightTimeout     = 15 * time.Second
+)
+
+// ErrCheckUnsupported is returned by a PreflightCheck that cannot validate an
+// account's credentials.
+var ErrCheckUnsupported = errors.New("credential check not supported")
+
+// PreflightCheck validates the credentials of an account that has no refresh
+// token, typically with a cheap authenticated call.
+type PreflightCheck func(ctx context.Context, auth *Auth) error
+
+// PreflightOptions configures Manager.Preflight.
+type PreflightOptions struct {
+	// Concurrency bounds how many accounts are checked at once. Defaults to 4.
+	Concurrency int
+	// Timeout bounds the check of each account. Defaults to 15s.
+	Timeout time.Duration
+	// Check validates accounts without a refresh token. Nil skips them.
+	Check PreflightCheck
+}
+
+// PreflightResult is the outcome of checking one account at startup.
+type PreflightResult struct {
+	AuthID   string `json:"-"`
+	Provider string `json:"-"`
+	Label    string `json:"-"`
+	// Valid reports whether the credentials were accepted.
+	Valid bool `json:"valid"`
+	// Skipped reports that the account could not be checked; it is neither
+	// valid nor invalid.
+	Skipped   bool      `json:"skipped,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Usable reports whether the account may serve requests: it was accepted or
+// could not be checked.
+func (r PreflightResult) Usable() bool { return r.Valid || r.Skipped }
+
+// Preflight validates the credentials of every enabled account concurrently.
+// Accounts with a refresh token are refreshed; others go through opts.Check.
+// Results are kept for PreflightResult and returned sorted by provider and ID.
+func (m *Manager) Preflight(ctx context.Context, opts PreflightOptions) []PreflightResult {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = defaultPreflightConcurrency
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultPreflightTimeout
+	}
+	var auths []*Auth
+	for _, a := range m.snapshotAuths() {
+		if !a.Disabled {
+			auths = append(auths, a)
+		}
+	}
+
+	results := make([]PreflightResult, len(auths))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(opts.Concurrency, len(auths)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = m.preflightAuth(ctx, auths[i], opts)
+			}
+		}()
+	}
+	for i := range auths {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Provider != results[j].Provider {
+			return results[i].Provider < results[j].Provider
+		}
+		return results[i].AuthID < results[j].AuthID
+	})
+	m.preflightMu.Lock()
+	m.preflight = make(map[string]PreflightResult, len(results))
+	for _, r := range results {
+		m.preflight[r.AuthID] = r
+	}
+	m.preflightMu.Unlock()
+	return results
+}
+
+// CheckAccount checks one account's credentials without changing them, for
+// health probes: an expired token fails and other accounts run opts.Check.
+// Unlike Preflight it never refreshes, so repeated checks cannot rotate or
+// rewrite stored tokens. The result is not recorded.
+func (m *Manager) CheckAccount(ctx context.Context, auth *Auth, opts PreflightOptions) PreflightResult {
+	result := PreflightResult{AuthID: auth.ID, Provider: auth.Provider, Label: auth.Label, CheckedAt: time.Now()}
+	if exp, ok := auth.ExpirationTime(); ok && exp.Before(result.CheckedAt) {
+		result.Error = "token expired at " + exp.Format(time.RFC3339)
+		return result
+	}
+	if opts.Check == nil {
+		result.Skipped = true
+		return result
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultPreflightTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+	switch err := opts.Check(ctx, auth); {
+	case errors.Is(err, ErrCheckUnsupported):
+		result.Skipped = true
+	case err != nil:
+		result.Error = err.Error()
+	default:
+		result.Valid = true
+	}
+	return result
+}
+
+func (m *Manager) preflightAuth(ctx context.Context, auth *Auth, opts PreflightOptions) PreflightResult {
+	result := PreflightResult{AuthID: auth.ID, Provider: auth.Provider, Label: auth.Label}
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	var err error
+	switch {
+	case refreshTokenOf(auth) != "":
+		err = m.refreshAuth(ctx, auth.ID)
+	case opts.Check != nil:
+		err = opts.Check(ctx, auth)
+	default:
+		err = ErrCheckUnsupported
+	}
+	result.CheckedAt = time.Now()
+	if errors.Is(err, ErrCheckUnsupported) {
+		// Without a way to call upstream, a known expiry is the only signal.
+		if exp, ok := auth.ExpirationTime(); ok && exp.Before(result.CheckedAt) {
+			err = errors.New("token expired at " + exp.Format(time.RFC3339))
+		} else {
+			result.Skipped = true
+			return result
+		}
+	}
+	if err != nil {
+		result.Error = err.Error()
+		log.Warnf("preflight: %s account %s failed credential check: %v", auth.Provider, auth.ID, err)
+		return result
+	}
+	result.Valid = true
+	return result
+}
+
+// PreflightResult returns the startup check of an account, if one ran.
+func (m *Manager) PreflightResult(authID string) (PreflightResult, bool) {
+	m.preflightMu.RLock()
+	defer m.preflightMu.RUnlock()
+	r, ok := m.preflight[authID]
+	return r, ok
+}
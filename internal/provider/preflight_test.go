@@ -0,0 +1,94 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// preflightExecutor rejects the refresh token "revoked".
+type preflightExecutor struct{}
+
+func (preflightExecutor) Identifier() string { return "oauth" }
+
+func (preflightExecutor) Execute(context.Context, *Auth, Request, Options) (Response, error) {
+	return Response{}, nil
+}
+
+func (preflightExecutor) ExecuteStream(context.Context, *Auth, Request, Options) (<-chan StreamChunk, error) {
+	return nil, nil
+}
+
+func (preflightExecutor) CountTokens(context.Context, *Auth, Request, Options) (Response, error) {
+	return Response{}, nil
+}
+
+func (preflightExecutor) Refresh(_ context.Context, auth *Auth) (*Auth, error) {
+	if refreshTokenOf(auth) == "revoked" {
+		return nil, errors.New("invalid_grant")
+	}
+	return auth, nil
+}
+
+func TestPreflight_ClassifiesAccounts(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.RegisterExecutor(preflightExecutor{})
+	ctx := context.Background()
+	for _, a := range []*Auth{
+		{ID: "oauth-ok", Provider: "oauth", Metadata: map[string]any{"refresh_token": "good"}},
+		{ID: "oauth-revoked", Provider: "oauth", Metadata: map[string]any{"refresh_token": "revoked"}},
+		{ID: "key-ok", Provider: "keyed", Attributes: map[string]string{"api_key": "ok"}},
+		{ID: "key-slow", Provider: "keyed", Attributes: map[string]string{"api_key": "slow"}},
+		{ID: "key-unknown", Provider: "keyed", Attributes: map[string]string{"api_key": "unknown"}},
+		{ID: "off", Provider: "keyed", Disabled: true},
+	} {
+		if _, err := m.Register(ctx, a); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var running, peak atomic.Int32
+	check := func(ctx context.Context, auth *Auth) error {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			if p := peak.Load(); n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		switch auth.Attributes["api_key"] {
+		case "slow":
+			<-ctx.Done()
+			return ctx.Err()
+		case "unknown":
+			return ErrCheckUnsupported
+		}
+		return nil
+	}
+
+	results := m.Preflight(ctx, PreflightOptions{Concurrency: 2, Timeout: 50 * time.Millisecond, Check: check})
+	if len(results) != 5 {
+		t.Fatalf("got %d results, want 5 (disabled accounts are skipped)", len(results))
+	}
+	want := map[string]struct{ valid, skipped bool }{
+		"oauth-ok":      {valid: true},
+		"oauth-revoked": {},
+		"key-ok":        {valid: true},
+		"key-slow":      {},
+		"key-unknown":   {skipped: true},
+	}
+	for _, r := range results {
+		w := want[r.AuthID]
+		if r.Valid != w.valid || r.Skipped != w.skipped {
+			t.Errorf("%s: valid=%v skipped=%v err=%q, want valid=%v skipped=%v", r.AuthID, r.Valid, r.Skipped, r.Error, w.valid, w.skipped)
+		}
+	}
+	if p := peak.Load(); p > 2 {
+		t.Errorf("%d checks ran at once, want at most 2", p)
+	}
+	if r, ok := m.PreflightResult("oauth-revoked"); !ok || r.Usable() || r.Error == "" {
+		t.Errorf("stored result = %+v, %v", r, ok)
+	}
+}
@@ -0,0 +1,68 @@
+package provider
+
+import (
+	"context"
+	"time"
+)
+
+// QuotaSelector prefers the available auth with the most daily quota
+// headroom, so usage spreads across accounts instead of one reaching its cap
+// while others sit idle. Auths with equal headroom, such as accounts without
+// a daily limit, are rotated as by RoundRobinSelector.
+type QuotaSelector struct {
+	RoundRobinSelector
+	quota func() *DailyQuota
+}
+
+// NewQuotaSelector returns a selector reading usage from the tracker quota
+// returns. A nil tracker leaves every auth with full headroom.
+func NewQuotaSelector(quota func() *DailyQuota) *QuotaSelector {
+	return &QuotaSelector{quota: quota}
+}
+
+// Pick selects the available auth furthest from its daily limit.
+func (s *QuotaSelector) Pick(ctx context.Context, provider, model string, opts Options, auths []*Auth) (*Auth, error) {
+	best, err := s.mostHeadroom(provider, model, auths)
+	if err != nil {
+		return nil, err
+	}
+	return s.RoundRobinSelector.Pick(ctx, provider, model, opts, best)
+}
+
+// Preview returns the auth Pick would select without recording the selection.
+func (s *QuotaSelector) Preview(ctx context.Context, provider, model string, opts Options, auths []*Auth) (*Auth, error) {
+	best, err := s.mostHeadroom(provider, model, auths)
+	if err != nil {
+		return nil, err
+	}
+	return s.RoundRobinSelector.Preview(ctx, provider, model, opts, best)
+}
+
+// mostHeadroom returns the available auths sharing the largest headroom.
+func (s *QuotaSelector) mostHeadroom(provider, model string, auths []*Auth) ([]*Auth, error) {
+	if len(auths) == 0 {
+		return nil, &Error{Code: "auth_not_found", Message: "no auth candidates"}
+	}
+	now := time.Now()
+	available, err := availableAuths(provider, model, auths, now)
+	if err != nil {
+		return nil, err
+	}
+	var quota *DailyQuota
+	if s.quota != nil {
+		quota = s.quota()
+	}
+	best := make([]*Auth, 0, len(available))
+	bestHeadroom := -1.0
+	for _, auth := range available {
+		headroom := quota.Headroom(auth, now)
+		switch {
+		case headroom > bestHeadroom:
+			best = append(best[:0], auth)
+			bestHeadroom = headroom
+		case headroom == bestHeadroom:
+			best = append(best, auth)
+		}
+	}
+	return best, nil
+}
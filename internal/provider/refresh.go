@@ -2,9 +2,11 @@ package provider
 
 import (
 	"context"
+	"reflect"
 	"strings"
 	"time"
 
+	"github.com/nghyane/llm-mux/internal/json"
 	log "github.com/nghyane/llm-mux/internal/logging"
 )
 
@@ -230,3 +232,46 @@ func lookupMetadataTime(meta map[string]any, keys ...string) (time.Time, bool) {
 	}
 	return time.Time{}, false
 }
+
+// adoptStoredRefresh takes over the credentials another instance saved for
+// auth since this one loaded it, and reports whether there were any.
+func (m *Manager) adoptStoredRefresh(ctx context.Context, store authLoader, auth *Auth) bool {
+	stored, err := store.Load(ctx, auth.ID)
+	if err != nil {
+		log.Warnf("failed to load stored credentials of %s before refresh: %v", auth.ID, err)
+		return false
+	}
+	if stored == nil || stored.Metadata == nil || metadataEqual(stored.Metadata, auth.Metadata) {
+		return false
+	}
+	updated := auth.Clone()
+	updated.Metadata = stored.Metadata
+	// Storage still holds the credentials this instance had.
+	updated.Storage = nil
+	now := time.Now()
+	updated.LastRefreshedAt = now
+	updated.NextRefreshAfter = time.Time{}
+	updated.LastError = nil
+	updated.UpdatedAt = now
+	m.update(ctx, updated, false)
+	log.Debugf("adopted credentials of %s refreshed by another instance", auth.ID)
+	return true
+}
+
+// metadataEqual compares metadata after a JSON round trip, so numbers read
+// from a store compare equal to the ints they were.
+func metadataEqual(a, b map[string]any) bool {
+	ja, errA := jsonRoundTrip(a)
+	jb, errB := jsonRoundTrip(b)
+	return errA == nil && errB == nil && reflect.DeepEqual(ja, jb)
+}
+
+func jsonRoundTrip(m map[string]any) (any, error) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	var out any
+	err = json.Unmarshal(data, &out)
+	return out, err
+}
@@ -0,0 +1,269 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+type memoryStore struct {
+	mu    sync.Mutex
+	saved map[string]*Auth
+}
+
+func (s *memoryStore) List(context.Context) ([]*Auth, error) { return nil, nil }
+
+func (s *memoryStore) Save(_ context.Context, auth *Auth) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.saved[auth.ID] = auth.Clone()
+	return auth.ID, nil
+}
+
+func (s *memoryStore) Delete(context.Context, string) error { return nil }
+
+func (s *memoryStore) refreshToken(id string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return refreshTokenOf(s.saved[id])
+}
+
+// staleStorage stands in for token storage captured at login, which still
+// holds the original refresh token.
+type staleStorage struct{}
+
+func (staleStorage) SaveTokenToFile(string) error { return nil }
+
+// rotatingExecutor issues a new refresh token on every refresh and rejects
+// any refresh token it has already consumed.
+type rotatingExecutor struct {
+	used  map[string]bool
+	count int
+}
+
+func (e *rotatingExecutor) Identifier() string { return "rotating" }
+
+func (e *rotatingExecutor) Execute(context.Context, *Auth, Request, Options) (Response, error) {
+	return Response{}, nil
+}
+
+func (e *rotatingExecutor) ExecuteStream(context.Context, *Auth, Request, Options) (<-chan StreamChunk, error) {
+	return nil, nil
+}
+
+func (e *rotatingExecutor) CountTokens(context.Context, *Auth, Request, Options) (Response, error) {
+	return Response{}, nil
+}
+
+func (e *rotatingExecutor) Refresh(_ context.Context, auth *Auth) (*Auth, error) {
+	current := refreshTokenOf(auth)
+	if e.used[current] {
+		return nil, fmt.Errorf("refresh token %s was already used", current)
+	}
+	e.used[current] = true
+	e.count++
+	auth.Metadata["refresh_token"] = fmt.Sprintf("rt-%d", e.count)
+	auth.Metadata["access_token"] = fmt.Sprintf("at-%d", e.count)
+	return auth, nil
+}
+
+func TestRefreshPersistsRotatedRefreshToken(t *testing.T) {
+	store := &memoryStore{saved: make(map[string]*Auth)}
+	exec := &rotatingExecutor{used: make(map[string]bool)}
+	m := NewManager(store, nil, nil)
+	m.RegisterExecutor(exec)
+	ctx := context.Background()
+	if _, err := m.Register(ctx, &Auth{
+		ID:       "acct",
+		Provider: "rotating",
+		Storage:  staleStorage{},
+		Metadata: map[string]any{"type": "rotating", "refresh_token": "rt-0"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 1; i <= 3; i++ {
+		m.refreshAuth(ctx, "acct")
+		want := fmt.Sprintf("rt-%d", i)
+		if got := store.refreshToken("acct"); got != want {
+			t.Fatalf("refresh %d: stored refresh token = %q, want %q", i, got, want)
+		}
+		if store.saved["acct"].Storage != nil {
+			t.Fatalf("refresh %d: store would persist stale token storage", i)
+		}
+		current, _ := m.GetByID("acct")
+		if current.LastError != nil {
+			t.Fatalf("refresh %d reused an old token: %s", i, current.LastError.Message)
+		}
+		if current.Storage == nil {
+			t.Fatalf("refresh %d: in-memory token storage was dropped", i)
+		}
+		if got := refreshTokenOf(current); got != want {
+			t.Fatalf("refresh %d: in-memory refresh token = %q, want %q", i, got, want)
+		}
+	}
+}
+
+// failingStore rejects every save.
+type failingStore struct{ memoryStore }
+
+func (s *failingStore) Save(context.Context, *Auth) (string, error) {
+	return "", errors.New("disk full")
+}
+
+func TestRefreshKeepsOldRecordWhenPersistFails(t *testing.T) {
+	store := &failingStore{memoryStore{saved: make(map[string]*Auth)}}
+	m := NewManager(store, nil, nil)
+	m.RegisterExecutor(&rotatingExecutor{used: make(map[string]bool)})
+	ctx := context.Background()
+	if _, err := m.Register(ctx, &Auth{
+		ID:       "acct",
+		Provider: "rotating",
+		Metadata: map[string]any{"type": "rotating", "refresh_token": "rt-0"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.refreshAuth(ctx, "acct"); err == nil {
+		t.Fatal("refresh reported success without persisting the rotated token")
+	}
+	current, _ := m.GetByID("acct")
+	if got := refreshTokenOf(current); got != "rt-0" {
+		t.Errorf("in-memory refresh token = %q, want the old rt-0", got)
+	}
+	if current.LastError == nil || current.NextRefreshAfter.IsZero() {
+		t.Errorf("failed persist was not recorded: %+v", current)
+	}
+}
+
+// loadableMemoryStore is a memoryStore shared by several instances that
+// cannot lock by itself.
+type loadableMemoryStore struct {
+	memoryStore
+}
+
+func (s *loadableMemoryStore) Load(_ context.Context, id string) (*Auth, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if a := s.saved[id]; a != nil {
+		return a.Clone(), nil
+	}
+	return nil, nil
+}
+
+// chanLocker is a refresh lock shared by every account.
+type chanLocker chan struct{}
+
+func (l chanLocker) LockRefresh(ctx context.Context, _ string) (func(), error) {
+	select {
+	case l <- struct{}{}:
+		return func() { <-l }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// sharedMemoryStore stands in for a store shared by several instances.
+type sharedMemoryStore struct {
+	loadableMemoryStore
+	chanLocker
+}
+
+// newRefreshInstances registers the same account with two managers on store,
+// as two instances of the server would.
+func newRefreshInstances(t *testing.T, store Store, exec ProviderExecutor) []*Manager {
+	t.Helper()
+	instances := make([]*Manager, 2)
+	for i := range instances {
+		m := NewManager(store, nil, nil)
+		m.RegisterExecutor(exec)
+		if _, err := m.Register(context.Background(), &Auth{
+			ID:       "acct",
+			Provider: "rotating",
+			Metadata: map[string]any{"type": "rotating", "refresh_token": "rt-0"},
+		}); err != nil {
+			t.Fatal(err)
+		}
+		instances[i] = m
+	}
+	return instances
+}
+
+// raceRefresh has every instance refresh the account at once and checks the
+// upstream refreshed it once, with all instances ending on its new token.
+func raceRefresh(t *testing.T, instances []*Manager, exec *rotatingExecutor) {
+	t.Helper()
+	var wg sync.WaitGroup
+	errs := make([]error, len(instances))
+	for i, m := range instances {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = m.refreshAuth(context.Background(), "acct")
+		}()
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("instance %d: %v", i, err)
+		}
+	}
+	if exec.count != 1 {
+		t.Fatalf("upstream refreshed %d times, want 1", exec.count)
+	}
+	for i, m := range instances {
+		current, _ := m.GetByID("acct")
+		if got := refreshTokenOf(current); got != "rt-1" {
+			t.Errorf("instance %d holds refresh token %q, want rt-1", i, got)
+		}
+	}
+}
+
+func TestRefreshSharedStoreRefreshesOnce(t *testing.T) {
+	store := &sharedMemoryStore{
+		loadableMemoryStore: loadableMemoryStore{memoryStore{saved: make(map[string]*Auth)}},
+		chanLocker:          make(chanLocker, 1),
+	}
+	exec := &rotatingExecutor{used: make(map[string]bool)}
+	ctx := context.Background()
+	instances := newRefreshInstances(t, store, exec)
+
+	// Both instances find the account due at once; only one may use rt-0.
+	raceRefresh(t, instances, exec)
+
+	// The next refresh again runs once and the other instance takes it over.
+	if err := instances[0].refreshAuth(ctx, "acct"); err != nil {
+		t.Fatal(err)
+	}
+	if err := instances[1].refreshAuth(ctx, "acct"); err != nil {
+		t.Fatal(err)
+	}
+	if current, _ := instances[1].GetByID("acct"); exec.count != 2 || refreshTokenOf(current) != "rt-2" {
+		t.Fatalf("after second round: %d refreshes, instance 1 holds %q", exec.count, refreshTokenOf(current))
+	}
+}
+
+func TestRefreshLockerRefreshesOnce(t *testing.T) {
+	store := &loadableMemoryStore{memoryStore{saved: make(map[string]*Auth)}}
+	exec := &rotatingExecutor{used: make(map[string]bool)}
+	instances := newRefreshInstances(t, store, exec)
+	locker := make(chanLocker, 1)
+	for _, m := range instances {
+		m.SetRefreshLocker(locker)
+	}
+	raceRefresh(t, instances, exec)
+}
+
+func TestMetadataEqual(t *testing.T) {
+	a := map[string]any{"access_token": "t", "expires_in": 3600, "scopes": []any{"a", "b"}}
+	b := map[string]any{"scopes": []any{"a", "b"}, "expires_in": float64(3600), "access_token": "t"}
+	if !metadataEqual(a, b) {
+		t.Error("metadata differing only in key order and number type compared unequal")
+	}
+	b["access_token"] = "rotated"
+	if metadataEqual(a, b) {
+		t.Error("metadata with a rotated token compared equal")
+	}
+}
@@ -3,6 +3,8 @@ package provider
 import (
 	"net/http"
 	"net/url"
+
+	"github.com/nghyane/llm-mux/internal/registry"
 )
 
 // Request encapsulates the translated payload that will be sent to a provider executor.
@@ -23,6 +25,11 @@ type Options struct {
 	SourceFormat    Format
 	Metadata        map[string]any
 	ForceRotate     bool
+	// AuthID pins execution to one account, bypassing selection.
+	AuthID string
+	// Required lists the capabilities the request needs. Of a model family's
+	// members on one provider, those lacking one are not used.
+	Required registry.Capability
 }
 
 // Response wraps either a full provider response or metadata for streaming flows.
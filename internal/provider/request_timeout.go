@@ -0,0 +1,24 @@
+package provider
+
+import (
+	"context"
+	"time"
+)
+
+type requestTimeoutKey struct{}
+
+// WithRequestTimeout returns a context whose upstream calls use timeout in
+// place of the provider's configured request timeout.
+func WithRequestTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, requestTimeoutKey{}, timeout)
+}
+
+// RequestTimeoutFromContext returns the per-request timeout attached to ctx,
+// if any.
+func RequestTimeoutFromContext(ctx context.Context) (time.Duration, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+	timeout, ok := ctx.Value(requestTimeoutKey{}).(time.Duration)
+	return timeout, ok && timeout > 0
+}
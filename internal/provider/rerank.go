@@ -0,0 +1,48 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/nghyane/llm-mux/internal/registry"
+)
+
+// Reranker is an optional interface that provider executors implement to serve
+// rerank requests. Rerank receives the client's /v1/rerank payload and returns
+// the scored documents in the same shape. Like Embedder, it bypasses executor
+// middleware.
+type Reranker interface {
+	Rerank(ctx context.Context, auth *Auth, req Request, opts Options) (Response, error)
+}
+
+// rerankerFor returns the Reranker registered for provider, if any.
+func (m *Manager) rerankerFor(provider string) (Reranker, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	reranker, ok := m.baseExecutors[provider].(Reranker)
+	return reranker, ok
+}
+
+// ExecuteRerank runs a rerank request against the providers serving
+// req.Model whose executor implements Reranker.
+func (m *Manager) ExecuteRerank(ctx context.Context, providers []string, req Request, opts Options) (Response, error) {
+	normalized := m.normalizeProviders(providers)
+	capable := normalized[:0:0]
+	for _, p := range normalized {
+		if _, ok := m.rerankerFor(p); ok {
+			capable = append(capable, p)
+		}
+	}
+	if len(capable) == 0 {
+		return Response{}, &Error{Code: "rerank_not_supported", Message: "no provider for model " + req.Model + " supports rerank", HTTPStatus: http.StatusBadRequest}
+	}
+	return m.executeCapable(ctx, capable, req.Model, func(execCtx context.Context, provider string) (Response, error) {
+		reranker, ok := m.rerankerFor(provider)
+		if !ok {
+			return Response{}, &Error{Code: "rerank_not_supported", Message: "provider " + provider + " does not support rerank", HTTPStatus: http.StatusBadRequest}
+		}
+		providerReq := req
+		providerReq.Model = registry.GetGlobalRegistry().GetModelIDForProvider(req.Model, provider, opts.Required)
+		return m.executeWithAuths(execCtx, provider, providerReq.Model, providerReq, opts, reranker.Rerank)
+	})
+}
@@ -0,0 +1,244 @@
+package provider
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/nghyane/llm-mux/internal/logging"
+)
+
+// retryBudgetSlots is the number of buckets a budget window is split into.
+const retryBudgetSlots = 10
+
+// RetryBudget allows retries only while they stay within a share of recent
+// requests. Requests and retries are counted over a sliding window, across
+// all providers and per provider; a retry needs room in both, so an outage
+// of one provider cannot spend the budget of the others. Once a budget is
+// spent, retries are refused until older requests leave the window, and
+// requests fail fast instead of piling more load on the upstream.
+type RetryBudget struct {
+	ratio      float64
+	minRetries int
+	slot       time.Duration
+	now        func() time.Time
+
+	mu      sync.Mutex
+	global  *budgetWindow
+	perProv map[string]*budgetWindow
+}
+
+// RetryBudgetState reports the use of one retry budget over the window.
+type RetryBudgetState struct {
+	Requests int `json:"requests"`
+	Retries  int `json:"retries"`
+	// Available is the number of retries still allowed in the window.
+	Available int `json:"available"`
+	// Exhausted is true while retries are refused.
+	Exhausted bool `json:"exhausted"`
+	// Denied counts the retries refused since startup.
+	Denied int64 `json:"denied"`
+}
+
+// RetryBudgetStatus reports the global budget and those of each provider.
+type RetryBudgetStatus struct {
+	Ratio      float64                     `json:"ratio"`
+	MinRetries int                         `json:"min_retries"`
+	Window     string                      `json:"window"`
+	Global     RetryBudgetState            `json:"global"`
+	Providers  map[string]RetryBudgetState `json:"providers,omitempty"`
+}
+
+type budgetSlot struct {
+	start    time.Time
+	requests int
+	retries  int
+}
+
+type budgetWindow struct {
+	slots  [retryBudgetSlots]budgetSlot
+	denied int64
+}
+
+// NewRetryBudget returns a budget allowing ratio retries per request, and at
+// least minRetries, over window.
+func NewRetryBudget(ratio float64, minRetries int, window time.Duration) *RetryBudget {
+	if minRetries < 0 {
+		minRetries = 0
+	}
+	slot := window / retryBudgetSlots
+	if slot <= 0 {
+		slot = time.Second
+	}
+	return &RetryBudget{
+		ratio:      ratio,
+		minRetries: minRetries,
+		slot:       slot,
+		now:        time.Now,
+		global:     &budgetWindow{},
+		perProv:    make(map[string]*budgetWindow),
+	}
+}
+
+// RecordRequest counts a request served by provider.
+func (b *RetryBudget) RecordRequest(provider string) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.now()
+	b.global.current(now, b.slot).requests++
+	b.windowFor(provider).current(now, b.slot).requests++
+}
+
+// Withdraw reports whether a retry after a failure on provider is allowed,
+// and counts it if so. A nil budget allows every retry.
+func (b *RetryBudget) Withdraw(provider string) bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.now()
+	prov := b.windowFor(provider)
+	if b.available(b.global, now) <= 0 {
+		b.global.denied++
+		return false
+	}
+	if b.available(prov, now) <= 0 {
+		prov.denied++
+		return false
+	}
+	b.global.current(now, b.slot).retries++
+	prov.current(now, b.slot).retries++
+	return true
+}
+
+// Status returns the current state of every budget.
+func (b *RetryBudget) Status() RetryBudgetStatus {
+	if b == nil {
+		return RetryBudgetStatus{}
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.now()
+	out := RetryBudgetStatus{
+		Ratio:      b.ratio,
+		MinRetries: b.minRetries,
+		Window:     (b.slot * retryBudgetSlots).String(),
+		Global:     b.state(b.global, now),
+		Providers:  make(map[string]RetryBudgetState, len(b.perProv)),
+	}
+	names := make([]string, 0, len(b.perProv))
+	for name := range b.perProv {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		out.Providers[name] = b.state(b.perProv[name], now)
+	}
+	return out
+}
+
+// windowFor returns the window of provider; b.mu must be held.
+func (b *RetryBudget) windowFor(provider string) *budgetWindow {
+	w := b.perProv[provider]
+	if w == nil {
+		w = &budgetWindow{}
+		b.perProv[provider] = w
+	}
+	return w
+}
+
+// available returns the retries w still allows; b.mu must be held.
+func (b *RetryBudget) available(w *budgetWindow, now time.Time) int {
+	requests, retries := w.totals(now, b.slot)
+	allowed := int(b.ratio * float64(requests))
+	if allowed < b.minRetries {
+		allowed = b.minRetries
+	}
+	return allowed - retries
+}
+
+func (b *RetryBudget) state(w *budgetWindow, now time.Time) RetryBudgetState {
+	requests, retries := w.totals(now, b.slot)
+	available := b.available(w, now)
+	if available < 0 {
+		available = 0
+	}
+	return RetryBudgetState{
+		Requests:  requests,
+		Retries:   retries,
+		Available: available,
+		Exhausted: available == 0,
+		Denied:    w.denied,
+	}
+}
+
+// current returns the slot for now, recycling it if it holds an older period.
+func (w *budgetWindow) current(now time.Time, slot time.Duration) *budgetSlot {
+	start := now.Truncate(slot)
+	s := &w.slots[(start.UnixNano()/int64(slot))%retryBudgetSlots]
+	if !s.start.Equal(start) {
+		*s = budgetSlot{start: start}
+	}
+	return s
+}
+
+// totals sums the slots still inside the window ending at now.
+func (w *budgetWindow) totals(now time.Time, slot time.Duration) (requests, retries int) {
+	oldest := now.Truncate(slot).Add(-slot * (retryBudgetSlots - 1))
+	for i := range w.slots {
+		if s := &w.slots[i]; !s.start.Before(oldest) && !s.start.After(now) {
+			requests += s.requests
+			retries += s.retries
+		}
+	}
+	return requests, retries
+}
+
+// SetRetryBudget installs the retry budget; nil allows every retry.
+func (m *Manager) SetRetryBudget(b *RetryBudget) {
+	if m == nil {
+		return
+	}
+	m.retryBudget.Store(b)
+}
+
+// ConfigureRetryBudget installs a budget of ratio retries per request, and at
+// least minRetries, over window; a ratio of zero removes it. The current
+// budget and its counters are kept when the settings are unchanged, so a
+// config reload does not reset them.
+func (m *Manager) ConfigureRetryBudget(ratio float64, minRetries int, window time.Duration) {
+	if m == nil {
+		return
+	}
+	if ratio <= 0 {
+		m.SetRetryBudget(nil)
+		return
+	}
+	next := NewRetryBudget(ratio, minRetries, window)
+	if cur := m.RetryBudget(); cur != nil && cur.ratio == next.ratio && cur.minRetries == next.minRetries && cur.slot == next.slot {
+		return
+	}
+	m.SetRetryBudget(next)
+}
+
+// RetryBudget returns the installed retry budget, or nil.
+func (m *Manager) RetryBudget() *RetryBudget {
+	if m == nil {
+		return nil
+	}
+	return m.retryBudget.Load()
+}
+
+// spendRetry reports whether the retry budget allows retrying a request that
+// failed on provider.
+func (m *Manager) spendRetry(provider string) bool {
+	if m.RetryBudget().Withdraw(provider) {
+		return true
+	}
+	log.Debugf("retry budget exhausted for %s, failing fast", provider)
+	return false
+}
@@ -0,0 +1,97 @@
+package provider
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryBudget_SustainedOutage(t *testing.T) {
+	b := NewRetryBudget(0.2, 5, 10*time.Second)
+	now := time.Unix(1_700_000_000, 0)
+	b.now = func() time.Time { return now }
+
+	// Every request fails and wants three retries, 20 requests per second.
+	retriesPerSecond := make([]int, 0, 30)
+	for sec := 0; sec < 30; sec++ {
+		if sec > 0 {
+			now = now.Add(time.Second)
+		}
+		retries := 0
+		for i := 0; i < 20; i++ {
+			b.RecordRequest("claude")
+			for attempt := 0; attempt < 3; attempt++ {
+				if !b.Withdraw("claude") {
+					break
+				}
+				retries++
+			}
+		}
+		retriesPerSecond = append(retriesPerSecond, retries)
+	}
+
+	// Without a budget each second would send 60 retries.
+	if retriesPerSecond[0] >= 60 {
+		t.Fatalf("first second sent %d retries, budget not applied", retriesPerSecond[0])
+	}
+	// Once the window is full, retries settle at the ratio: 0.2 * 20 = 4/s.
+	for sec := 10; sec < 30; sec++ {
+		if retriesPerSecond[sec] > 5 {
+			t.Errorf("second %d sent %d retries, want at most 5", sec, retriesPerSecond[sec])
+		}
+	}
+	status := b.Status()
+	if !status.Global.Exhausted || status.Global.Denied == 0 {
+		t.Errorf("global budget = %+v, want exhausted with denials", status.Global)
+	}
+	if status.Providers["claude"].Requests != 200 {
+		t.Errorf("claude requests in window = %d, want 200", status.Providers["claude"].Requests)
+	}
+
+	// After the outage the window drains and retries are allowed again.
+	now = now.Add(10 * time.Second)
+	if !b.Withdraw("claude") {
+		t.Error("retry refused after the window drained")
+	}
+}
+
+func TestRetryBudget_PerProvider(t *testing.T) {
+	b := NewRetryBudget(0.5, 1, 10*time.Second)
+	now := time.Unix(1_700_000_000, 0)
+	b.now = func() time.Time { return now }
+
+	for i := 0; i < 10; i++ {
+		b.RecordRequest("gemini")
+	}
+	b.RecordRequest("qwen")
+	// qwen's own budget allows a single retry even though gemini's traffic
+	// leaves room in the global one.
+	if !b.Withdraw("qwen") {
+		t.Fatal("first qwen retry refused")
+	}
+	if b.Withdraw("qwen") {
+		t.Error("qwen retried beyond its budget")
+	}
+	if !b.Withdraw("gemini") {
+		t.Error("gemini retry refused while its budget has room")
+	}
+}
+
+func TestConfigureRetryBudget_KeepsCounters(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.ConfigureRetryBudget(0.2, 10, 10*time.Second)
+	first := m.RetryBudget()
+	first.RecordRequest("claude")
+
+	m.ConfigureRetryBudget(0.2, 10, 10*time.Second)
+	if m.RetryBudget() != first {
+		t.Error("unchanged settings replaced the budget")
+	}
+	m.ConfigureRetryBudget(0.5, 10, 10*time.Second)
+	if m.RetryBudget() == first {
+		t.Error("changed settings kept the old budget")
+	}
+	m.ConfigureRetryBudget(0, 10, 10*time.Second)
+	if m.RetryBudget() != nil || !m.spendRetry("claude") {
+		t.Error("a zero ratio must remove the budget and allow retries")
+	}
+}
@@ -0,0 +1,113 @@
+package provider
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// ErrorMatcher recognizes an upstream response that is transient although
+// its status does not say so. Every field that is set must match: Status the
+// HTTP status, Body a case-insensitive substring of the body and Pattern the
+// body. A zero Status matches any status.
+type ErrorMatcher struct {
+	Status  int
+	Body    string
+	Pattern *regexp.Regexp
+}
+
+// Matches reports whether a response with status and body matches.
+func (em ErrorMatcher) Matches(status int, body string) bool {
+	if em.Status == 0 && em.Body == "" && em.Pattern == nil {
+		return false
+	}
+	if em.Status != 0 && em.Status != status {
+		return false
+	}
+	if em.Body != "" && !strings.Contains(strings.ToLower(body), strings.ToLower(em.Body)) {
+		return false
+	}
+	return em.Pattern == nil || em.Pattern.MatchString(body)
+}
+
+// RetryableErrorFunc returns the matchers of a provider's retryable errors.
+type RetryableErrorFunc func(provider string) []ErrorMatcher
+
+// SetRetryableErrors sets the matchers that turn upstream errors, and
+// successful responses whose body is an error, into transient failures that
+// are retried and count against the provider's circuit breaker. A nil func
+// removes every matcher.
+func (m *Manager) SetRetryableErrors(fn RetryableErrorFunc) {
+	if m == nil {
+		return
+	}
+	m.retryableErrors.Store(&fn)
+}
+
+func (m *Manager) retryableMatchers(provider string) []ErrorMatcher {
+	fn := m.retryableErrors.Load()
+	if fn == nil || *fn == nil {
+		return nil
+	}
+	return (*fn)(provider)
+}
+
+// classifyRetryable wraps err as a transient failure when it matches one of
+// provider's retryable errors. A successful response is checked too, for
+// upstreams that report errors with a 200; only non-streaming responses can
+// be, since a stream's status is sent before its body.
+func (m *Manager) classifyRetryable(provider string, resp Response, err error) error {
+	matchers := m.retryableMatchers(provider)
+	if len(matchers) == 0 {
+		return err
+	}
+	status, body := http.StatusOK, string(resp.Payload)
+	if err != nil {
+		status, body = statusCodeFromError(err), err.Error()
+	}
+	for _, em := range matchers {
+		if em.Matches(status, body) {
+			return &retryableError{cause: err, status: status, body: body}
+		}
+	}
+	return err
+}
+
+// retryableError is an upstream response matched by a retryable error
+// matcher. cause is nil when the upstream answered with a 200.
+type retryableError struct {
+	cause  error
+	status int
+	body   string
+}
+
+func (e *retryableError) Error() string {
+	if e.cause != nil {
+		return e.cause.Error()
+	}
+	return e.body
+}
+
+func (e *retryableError) Unwrap() error { return e.cause }
+
+// Category marks the response transient so it is retried whatever its
+// status and message would suggest.
+func (e *retryableError) Category() ErrorCategory { return CategoryTransient }
+
+// StatusCode keeps the upstream status, except that an error sent with a
+// success status is reported as a bad gateway.
+func (e *retryableError) StatusCode() int {
+	if e.status < http.StatusBadRequest {
+		return http.StatusBadGateway
+	}
+	return e.status
+}
+
+func (e *retryableError) Headers() http.Header {
+	var hp interface{ Headers() http.Header }
+	if errors.As(e.cause, &hp) && hp != nil {
+		return hp.Headers()
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"regexp"
+	"sync"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/resilience"
+)
+
+// scriptedExecutor answers each call with the next scripted reply and then
+// with "ok" once the script runs out.
+type scriptedExecutor struct {
+	mu      sync.Mutex
+	replies []scriptedReply
+	calls   []string
+}
+
+type scriptedReply struct {
+	payload string
+	err     error
+}
+
+func (e *scriptedExecutor) Identifier() string { return "scripted" }
+
+func (e *scriptedExecutor) next(auth *Auth) (Response, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.calls = append(e.calls, auth.ID)
+	if len(e.replies) == 0 {
+		return Response{Payload: []byte("ok")}, nil
+	}
+	reply := e.replies[0]
+	e.replies = e.replies[1:]
+	return Response{Payload: []byte(reply.payload)}, reply.err
+}
+
+func (e *scriptedExecutor) Execute(_ context.Context, auth *Auth, _ Request, _ Options) (Response, error) {
+	return e.next(auth)
+}
+
+func (e *scriptedExecutor) ExecuteStream(_ context.Context, auth *Auth, _ Request, _ Options) (<-chan StreamChunk, error) {
+	if _, err := e.next(auth); err != nil {
+		return nil, err
+	}
+	ch := make(chan StreamChunk)
+	close(ch)
+	return ch, nil
+}
+
+func (e *scriptedExecutor) Refresh(_ context.Context, auth *Auth) (*Auth, error) { return auth, nil }
+
+func (e *scriptedExecutor) CountTokens(context.Context, *Auth, Request, Options) (Response, error) {
+	return Response{}, nil
+}
+
+func newScriptedManager(t *testing.T, accounts int, matchers []ErrorMatcher, replies ...scriptedReply) (*Manager, *scriptedExecutor) {
+	t.Helper()
+	exec := &scriptedExecutor{replies: replies}
+	m := NewManager(nil, nil, nil)
+	m.RegisterExecutor(exec)
+	m.SetRetryConfig(1, 0)
+	m.SetRetryableErrors(func(provider string) []ErrorMatcher {
+		if provider == "scripted" {
+			return matchers
+		}
+		return nil
+	})
+	for i := range accounts {
+		if _, err := m.Register(context.Background(), &Auth{ID: "scripted-" + string(rune('a'+i)), Provider: "scripted"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return m, exec
+}
+
+var overloaded = &Error{Message: `{"error":{"type":"invalid_request_error","message":"Model overloaded, try again"}}`, HTTPStatus: http.StatusBadRequest}
+
+func TestRetryableErrors_BadRequestMatchedByBody(t *testing.T) {
+	matchers := []ErrorMatcher{{Status: http.StatusBadRequest, Body: "model overloaded"}}
+	m, exec := newScriptedManager(t, 1, matchers, scriptedReply{err: overloaded})
+
+	resp, err := m.Execute(context.Background(), []string{"scripted"}, Request{}, Options{})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if string(resp.Payload) != "ok" || len(exec.calls) != 2 {
+		t.Errorf("payload %q after %d calls, want ok after 2", resp.Payload, len(exec.calls))
+	}
+}
+
+func TestRetryableErrors_UnmatchedBadRequestFails(t *testing.T) {
+	matchers := []ErrorMatcher{{Status: http.StatusBadRequest, Pattern: regexp.MustCompile(`(?i)capacity`)}}
+	m, exec := newScriptedManager(t, 1, matchers, scriptedReply{err: overloaded})
+
+	_, err := m.Execute(context.Background(), []string{"scripted"}, Request{}, Options{})
+	if !errors.Is(err, overloaded) {
+		t.Fatalf("Execute error = %v, want the upstream 400", err)
+	}
+	if len(exec.calls) != 1 {
+		t.Errorf("%d calls, want 1", len(exec.calls))
+	}
+}
+
+func TestRetryableErrors_SuccessWithErrorBody(t *testing.T) {
+	matchers := []ErrorMatcher{{Status: http.StatusOK, Pattern: regexp.MustCompile(`^\{"error":`)}}
+	m, exec := newScriptedManager(t, 2, matchers, scriptedReply{payload: `{"error":{"message":"upstream busy"}}`})
+
+	resp, err := m.Execute(context.Background(), []string{"scripted"}, Request{}, Options{})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if string(resp.Payload) != "ok" || len(exec.calls) != 2 || exec.calls[0] == exec.calls[1] {
+		t.Errorf("payload %q from calls %v, want ok from the second account", resp.Payload, exec.calls)
+	}
+}
+
+func TestRetryableErrors_Stream(t *testing.T) {
+	matchers := []ErrorMatcher{{Body: "overloaded"}}
+	m, exec := newScriptedManager(t, 1, matchers, scriptedReply{err: overloaded})
+
+	out, err := m.ExecuteStream(context.Background(), []string{"scripted"}, Request{}, Options{})
+	if err != nil {
+		t.Fatalf("ExecuteStream: %v", err)
+	}
+	for range out {
+	}
+	if len(exec.calls) != 2 {
+		t.Errorf("%d calls, want 2", len(exec.calls))
+	}
+}
+
+func TestRetryableErrors_CountAgainstBreaker(t *testing.T) {
+	m, _ := newScriptedManager(t, 1, nil)
+	wrapped := m.classifyRetryable("scripted", Response{}, overloaded)
+	if wrapped != overloaded {
+		t.Fatalf("error wrapped without matchers: %v", wrapped)
+	}
+	m.SetRetryableErrors(func(string) []ErrorMatcher { return []ErrorMatcher{{Body: "overloaded"}} })
+	wrapped = m.classifyRetryable("scripted", Response{}, overloaded)
+	if resilience.DefaultIsSuccessful(wrapped) {
+		t.Error("a matched 400 does not trip the breaker")
+	}
+	if categoryFromError(wrapped) != CategoryTransient || statusCodeFromError(wrapped) != http.StatusBadRequest {
+		t.Errorf("category %v, status %d; want transient, 400", categoryFromError(wrapped), statusCodeFromError(wrapped))
+	}
+}
+
+func TestErrorMatcher(t *testing.T) {
+	tests := []struct {
+		name    string
+		matcher ErrorMatcher
+		status  int
+		body    string
+		want    bool
+	}{
+		{"empty matches nothing", ErrorMatcher{}, 400, "overloaded", false},
+		{"status only", ErrorMatcher{Status: 529}, 529, "", true},
+		{"status differs", ErrorMatcher{Status: 400, Body: "overloaded"}, 500, "overloaded", false},
+		{"body ignores case", ErrorMatcher{Body: "Overloaded"}, 400, "model OVERLOADED", true},
+		{"regex", ErrorMatcher{Status: 200, Pattern: regexp.MustCompile(`"code":\s*5\d\d`)}, 200, `{"code": 503}`, true},
+		{"body and regex must both match", ErrorMatcher{Body: "busy", Pattern: regexp.MustCompile(`retry`)}, 400, "busy", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.matcher.Matches(tt.status, tt.body); got != tt.want {
+				t.Errorf("Matches(%d, %q) = %v, want %v", tt.status, tt.body, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package provider
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/registry"
+	"github.com/sony/gobreaker"
+)
+
+// RouteExplanation describes how a request would be routed right now.
+type RouteExplanation struct {
+	// Model is the model as requested from the providers, after alias and
+	// family resolution.
+	Model string `json:"model"`
+	// Providers lists the candidate providers in the order they are tried.
+	Providers []ProviderDecision `json:"providers"`
+	// Selected is the account the request would go to, or nil when none can
+	// serve it.
+	Selected *RouteSelection `json:"selected,omitempty"`
+}
+
+// ProviderDecision explains one candidate provider.
+type ProviderDecision struct {
+	Provider string `json:"provider"`
+	// Model is the provider-specific model ID.
+	Model string `json:"model"`
+	// Skipped is why the provider is not used, empty when it is usable.
+	Skipped  string            `json:"skipped,omitempty"`
+	Accounts []AccountDecision `json:"accounts,omitempty"`
+}
+
+// AccountDecision explains one account of a candidate provider.
+type AccountDecision struct {
+	ID    string `json:"id"`
+	Label string `json:"label,omitempty"`
+	// Skipped is why the account is not used, empty when it is eligible.
+	Skipped string `json:"skipped,omitempty"`
+	// RetryAt is when a cooling down or exhausted account becomes eligible.
+	RetryAt *time.Time `json:"retry_at,omitempty"`
+	// Selected marks the account the selector would pick for this provider.
+	Selected bool `json:"selected,omitempty"`
+}
+
+// RouteSelection is the final routing decision.
+type RouteSelection struct {
+	Provider string `json:"provider"`
+	Model    string `json:"model"`
+	AuthID   string `json:"auth_id"`
+}
+
+// ExplainRoute reports how Execute would route a request for model across
+// providers without calling any upstream or recording a selection. It uses
+// the same provider ordering, circuit breakers, account filters and selector
+// as execution.
+func (m *Manager) ExplainRoute(ctx context.Context, providers []string, model string, opts Options) RouteExplanation {
+	out := RouteExplanation{Model: model}
+	for _, provider := range m.selectProviders(model, m.normalizeProviders(providers)) {
+		decision := m.explainProvider(ctx, provider, model, opts)
+		if out.Selected == nil {
+			for _, acc := range decision.Accounts {
+				if acc.Selected {
+					out.Selected = &RouteSelection{Provider: provider, Model: decision.Model, AuthID: acc.ID}
+				}
+			}
+		}
+		out.Providers = append(out.Providers, decision)
+	}
+	return out
+}
+
+// explainProvider mirrors executeWithProvider and pickNext for one provider.
+func (m *Manager) explainProvider(ctx context.Context, provider, model string, opts Options) ProviderDecision {
+	decision := ProviderDecision{
+		Provider: provider,
+		Model:    registry.GetGlobalRegistry().GetModelIDForProvider(model, provider, opts.Required),
+	}
+	if m.BreakerState(provider) == gobreaker.StateOpen {
+		decision.Skipped = skipReasonBreakerOpen
+		return decision
+	}
+	if m.executorFor(provider) == nil {
+		decision.Skipped = skipReasonNoExecutor
+		return decision
+	}
+
+	registryRef := registry.GetGlobalRegistry()
+	quota := m.dailyQuota.Load()
+	labels := LabelSelectorFromContext(ctx)
+	now := time.Now()
+	var candidates []*Auth
+	m.mu.RLock()
+	for _, auth := range m.auths {
+		if auth.Provider != provider {
+			continue
+		}
+		acc := AccountDecision{ID: auth.ID, Label: auth.Label}
+		reason, resetAt := candidateSkipReason(auth, decision.Model, opts, labels, registryRef, quota, now)
+		if reason == "" && m.concurrency.full(auth) {
+			reason = skipReasonBusy
+		}
+		if reason == "" {
+			candidates = append(candidates, auth.Clone())
+			// The selector skips accounts blocked for the model.
+			if blocked, why, next := isAuthBlockedForModel(auth, decision.Model, now); blocked {
+				switch why {
+				case blockReasonCooldown:
+					reason = skipReasonCooldown
+				case blockReasonDisabled:
+					reason = skipReasonDisabled
+				default:
+					reason = skipReasonUnavailable
+				}
+				resetAt = next
+			}
+		}
+		acc.Skipped = reason
+		if !resetAt.IsZero() {
+			acc.RetryAt = &resetAt
+		}
+		decision.Accounts = append(decision.Accounts, acc)
+	}
+	selector := m.selector
+	m.mu.RUnlock()
+	sort.Slice(decision.Accounts, func(i, j int) bool { return decision.Accounts[i].ID < decision.Accounts[j].ID })
+
+	var selected *Auth
+	if previewer, ok := selector.(SelectionPreviewer); ok && len(candidates) > 0 {
+		selected, _ = previewer.Preview(ctx, provider, decision.Model, opts, candidates)
+	} else if available, err := availableAuths(provider, decision.Model, candidates, now); err == nil && len(candidates) > 0 {
+		selected = available[0]
+	}
+	if selected == nil {
+		decision.Skipped = skipReasonNoAccount
+		return decision
+	}
+	for i := range decision.Accounts {
+		if decision.Accounts[i].ID == selected.ID {
+			decision.Accounts[i].Selected = true
+		}
+	}
+	return decision
+}
@@ -0,0 +1,69 @@
+package provider
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/registry"
+)
+
+func TestExplainRoute(t *testing.T) {
+	var calls []string
+	m := NewManager(nil, nil, nil)
+	m.RegisterExecutor(&echoExecutor{calls: &calls})
+
+	reg := registry.GetGlobalRegistry()
+	for _, id := range []string{"explain-a", "explain-b", "explain-c"} {
+		reg.RegisterClient(id, "echo", []*registry.ModelInfo{{ID: "explain-model"}})
+		defer reg.UnregisterClient(id)
+	}
+	reg.RegisterClient("explain-d", "echo", []*registry.ModelInfo{{ID: "other-model"}})
+	defer reg.UnregisterClient("explain-d")
+
+	retry := time.Now().Add(time.Minute)
+	auths := []*Auth{
+		{ID: "explain-a", Provider: "echo", ModelStates: map[string]*ModelState{
+			"explain-model": {Unavailable: true, NextRetryAfter: retry, Quota: QuotaState{Exceeded: true}},
+		}},
+		{ID: "explain-b", Provider: "echo"},
+		{ID: "explain-c", Provider: "echo", Disabled: true},
+		{ID: "explain-d", Provider: "echo"},
+	}
+	for _, a := range auths {
+		if _, err := m.Register(context.Background(), a); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := m.ExplainRoute(context.Background(), []string{"echo", "missing"}, "explain-model", Options{})
+	if got.Selected == nil || got.Selected.Provider != "echo" || got.Selected.AuthID != "explain-b" {
+		t.Fatalf("selected = %+v, want echo/explain-b", got.Selected)
+	}
+	if len(got.Providers) != 2 || got.Providers[1].Skipped != skipReasonNoExecutor {
+		t.Fatalf("providers = %+v", got.Providers)
+	}
+	want := map[string]string{
+		"explain-a": skipReasonCooldown,
+		"explain-b": "",
+		"explain-c": skipReasonDisabled,
+		"explain-d": skipReasonModel,
+	}
+	for _, acc := range got.Providers[0].Accounts {
+		if acc.Skipped != want[acc.ID] {
+			t.Errorf("%s skipped = %q, want %q", acc.ID, acc.Skipped, want[acc.ID])
+		}
+	}
+	if stats := m.SelectionStats(); stats["explain-b"].Selected != 0 {
+		t.Error("explaining a route must not record a selection")
+	}
+	if len(calls) != 0 {
+		t.Error("explaining a route must not call the executor")
+	}
+
+	// The explanation matches what execution then picks.
+	auth, _, err := m.pickNext(context.Background(), "echo", "explain-model", Options{}, map[string]struct{}{})
+	if err != nil || auth.ID != got.Selected.AuthID {
+		t.Fatalf("pickNext = %v, %v; explained %s", auth, err, got.Selected.AuthID)
+	}
+}
@@ -15,12 +15,60 @@ import (
 
 // RoundRobinSelector provides a simple provider scoped round-robin selection strategy.
 // It uses a sharded StickyStore for 60-second sticky sessions to maintain conversation continuity.
+// When rotating, it picks the least recently selected eligible auth, so load
+// stays even across accounts as they enter and leave cooldown.
 type RoundRobinSelector struct {
 	cursorMu sync.Mutex
-	cursors  map[string]int
+	seq      uint64
+	lastPick map[string]uint64 // auth ID -> seq of its last selection
+	counts   map[string]*SelectionStats
 	sticky   *StickyStore
 }
 
+// SelectionStats counts how often an auth was selected.
+type SelectionStats struct {
+	// Selected is the total number of times the auth was picked.
+	Selected int64 `json:"selected"`
+	// StickyHits is the subset of Selected served from a sticky session.
+	StickyHits int64 `json:"sticky_hits"`
+	// StickyFallbacks counts sticky sessions pinned to the auth that moved
+	// elsewhere because it was cooling down, disabled or unavailable.
+	StickyFallbacks int64 `json:"sticky_fallbacks"`
+}
+
+// SelectionReporter is optionally implemented by Selectors that track
+// per-auth selection counts.
+type SelectionReporter interface {
+	SelectionStats() map[string]SelectionStats
+}
+
+// SelectionPreviewer is optionally implemented by Selectors that can report
+// the auth Pick would return without recording the selection.
+type SelectionPreviewer interface {
+	Preview(ctx context.Context, provider, model string, opts Options, auths []*Auth) (*Auth, error)
+}
+
+// SelectionStats returns a snapshot of per-auth selection counts.
+func (s *RoundRobinSelector) SelectionStats() map[string]SelectionStats {
+	s.cursorMu.Lock()
+	defer s.cursorMu.Unlock()
+	out := make(map[string]SelectionStats, len(s.counts))
+	for id, c := range s.counts {
+		out[id] = *c
+	}
+	return out
+}
+
+// countLocked returns the counters for authID; cursorMu must be held.
+func (s *RoundRobinSelector) countLocked(authID string) *SelectionStats {
+	c := s.counts[authID]
+	if c == nil {
+		c = &SelectionStats{}
+		s.counts[authID] = c
+	}
+	return c
+}
+
 // Start launches the background cleanup goroutine for sticky sessions.
 func (s *RoundRobinSelector) Start() {
 	if s.sticky == nil {
@@ -128,8 +176,9 @@ func (s *RoundRobinSelector) Pick(ctx context.Context, provider, model string, o
 	}
 
 	s.cursorMu.Lock()
-	if s.cursors == nil {
-		s.cursors = make(map[string]int)
+	if s.lastPick == nil {
+		s.lastPick = make(map[string]uint64)
+		s.counts = make(map[string]*SelectionStats)
 	}
 	if s.sticky == nil {
 		s.sticky = NewStickyStore()
@@ -137,8 +186,77 @@ func (s *RoundRobinSelector) Pick(ctx context.Context, provider, model string, o
 	}
 	s.cursorMu.Unlock()
 
+	available, err := availableAuths(provider, model, auths, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	key := provider + ":" + model
+
+	stickyLost := ""
+	if !opts.ForceRotate {
+		if authID, ok := s.sticky.Get(key); ok {
+			for _, auth := range available {
+				if auth.ID == authID {
+					s.cursorMu.Lock()
+					s.seq++
+					s.lastPick[auth.ID] = s.seq
+					c := s.countLocked(auth.ID)
+					c.Selected++
+					c.StickyHits++
+					s.cursorMu.Unlock()
+					return auth, nil
+				}
+			}
+			stickyLost = authID
+		}
+	}
+
+	s.cursorMu.Lock()
+	selected := s.leastRecentLocked(available)
+	s.seq++
+	s.lastPick[selected.ID] = s.seq
+	s.countLocked(selected.ID).Selected++
+	if stickyLost != "" {
+		s.countLocked(stickyLost).StickyFallbacks++
+	}
+	s.cursorMu.Unlock()
+
+	s.sticky.Set(key, selected.ID)
+	return selected, nil
+}
+
+// Preview returns the auth Pick would select without recording the
+// selection or refreshing sticky sessions.
+func (s *RoundRobinSelector) Preview(ctx context.Context, provider, model string, opts Options, auths []*Auth) (*Auth, error) {
+	_ = ctx
+	if len(auths) == 0 {
+		return nil, &Error{Code: "auth_not_found", Message: "no auth candidates"}
+	}
+	available, err := availableAuths(provider, model, auths, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	s.cursorMu.Lock()
+	sticky := s.sticky
+	s.cursorMu.Unlock()
+	if !opts.ForceRotate && sticky != nil {
+		if authID, ok := sticky.Peek(provider + ":" + model); ok {
+			for _, auth := range available {
+				if auth.ID == authID {
+					return auth, nil
+				}
+			}
+		}
+	}
+	s.cursorMu.Lock()
+	defer s.cursorMu.Unlock()
+	return s.leastRecentLocked(available), nil
+}
+
+// availableAuths returns the auths not blocked for model, sorted by ID. When
+// none is available it returns a cooldown error if all are cooling down.
+func availableAuths(provider, model string, auths []*Auth, now time.Time) ([]*Auth, error) {
 	available := make([]*Auth, 0, len(auths))
-	now := time.Now()
 	cooldownCount := 0
 	var earliest time.Time
 	for i := 0; i < len(auths); i++ {
@@ -168,29 +286,20 @@ func (s *RoundRobinSelector) Pick(ctx context.Context, provider, model string, o
 	if len(available) > 1 {
 		sort.Slice(available, func(i, j int) bool { return available[i].ID < available[j].ID })
 	}
-	key := provider + ":" + model
+	return available, nil
+}
 
-	if !opts.ForceRotate {
-		if authID, ok := s.sticky.Get(key); ok {
-			for _, auth := range available {
-				if auth.ID == authID {
-					return auth, nil
-				}
-			}
+// leastRecentLocked returns the least recently selected of available; it is
+// sorted by ID, so ties (auths never selected) resolve deterministically.
+// cursorMu must be held.
+func (s *RoundRobinSelector) leastRecentLocked(available []*Auth) *Auth {
+	selected := available[0]
+	for _, auth := range available[1:] {
+		if s.lastPick[auth.ID] < s.lastPick[selected.ID] {
+			selected = auth
 		}
 	}
-
-	s.cursorMu.Lock()
-	index := s.cursors[key]
-	if index >= 1_000_000_000 || index < 0 {
-		index = 0
-	}
-	s.cursors[key] = index + 1
-	s.cursorMu.Unlock()
-
-	selected := available[index%len(available)]
-	s.sticky.Set(key, selected.ID)
-	return selected, nil
+	return selected
 }
 
 func isAuthBlockedForModel(auth *Auth, model string, now time.Time) (bool, blockReason, time.Time) {
@@ -120,6 +120,72 @@ func TestForceRotate(t *testing.T) {
 	}
 }
 
+func TestPickEvenDistribution(t *testing.T) {
+	selector := &RoundRobinSelector{}
+	selector.Start()
+	defer selector.Stop()
+
+	auths := []*Auth{
+		{ID: "auth1", Provider: "gemini"},
+		{ID: "auth2", Provider: "gemini"},
+		{ID: "auth3", Provider: "gemini"},
+	}
+
+	const picks = 10_000
+	for i := 0; i < picks; i++ {
+		// Cool auth2 down for a stretch so rotation has to route around it.
+		auths[1].Disabled = i >= 2000 && i < 2500
+		if _, err := selector.Pick(context.Background(), "gemini", "model", Options{ForceRotate: true}, auths); err != nil {
+			t.Fatalf("Pick failed: %v", err)
+		}
+	}
+
+	stats := selector.SelectionStats()
+	var total int64
+	for _, a := range auths {
+		got := stats[a.ID].Selected
+		total += got
+		// auth2 missed 500 picks; the others absorb them, 250 each.
+		if got < 3000 || got > 3600 {
+			t.Errorf("%s selected %d times, want near %d", a.ID, got, picks/3)
+		}
+	}
+	if total != picks {
+		t.Errorf("total selections = %d, want %d", total, picks)
+	}
+	// Once back, auth2 rejoins the rotation rather than being skipped.
+	if d := stats["auth1"].Selected - stats["auth2"].Selected; d > 250 || d < 0 {
+		t.Errorf("auth1-auth2 gap = %d", d)
+	}
+}
+
+func TestPickStickyFallbackStats(t *testing.T) {
+	selector := &RoundRobinSelector{}
+	selector.Start()
+	defer selector.Stop()
+
+	auths := []*Auth{
+		{ID: "auth1", Provider: "gemini"},
+		{ID: "auth2", Provider: "gemini"},
+	}
+
+	first, _ := selector.Pick(context.Background(), "gemini", "model", Options{}, auths)
+	_, _ = selector.Pick(context.Background(), "gemini", "model", Options{}, auths)
+	first.Disabled = true
+	moved, _ := selector.Pick(context.Background(), "gemini", "model", Options{}, auths)
+	if moved.ID == first.ID {
+		t.Fatal("sticky session kept a disabled auth")
+	}
+
+	stats := selector.SelectionStats()
+	if got := stats[first.ID]; got.Selected != 2 || got.StickyHits != 1 || got.StickyFallbacks != 1 {
+		t.Errorf("%s stats = %+v", first.ID, got)
+	}
+	if got := stats[moved.ID]; got.Selected != 1 || got.StickyHits != 0 {
+		t.Errorf("%s stats = %+v", moved.ID, got)
+	}
+}
+
 func TestGracefulShutdown(t *testing.T) {
 	selector := &RoundRobinSelector{}
 	selector.Start()
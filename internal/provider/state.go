@@ -189,6 +189,11 @@ func applyAuthFailureState(auth *Auth, resultErr *Error, retryAfter *time.Durati
 		}
 		auth.Quota.NextRecoverAt = next
 		auth.NextRetryAfter = next
+	case CategoryReauthRequired:
+		// Retrying cannot help until the account signs in again, which
+		// replaces the auth and clears this state.
+		auth.StatusMessage = StatusMessageReauthRequired
+		auth.NextRetryAfter = now.Add(12 * time.Hour)
 	case CategoryNotFound:
 		auth.StatusMessage = "not_found"
 		auth.NextRetryAfter = now.Add(12 * time.Hour)
@@ -63,6 +63,18 @@ func (s *StickyStore) getShard(key string) *stickyShard {
 	return s.shards[hashKey(key)%numStickyShards]
 }
 
+// Peek is like Get but does not extend the entry's lifetime.
+func (s *StickyStore) Peek(key string) (string, bool) {
+	shard := s.getShard(key)
+	shard.mu.RLock()
+	defer shard.mu.RUnlock()
+	entry, ok := shard.entries[key]
+	if !ok || time.Since(entry.lastUsed) >= stickyTTL {
+		return "", false
+	}
+	return entry.authID, true
+}
+
 // Get retrieves a sticky entry if it exists and is not expired.
 // Returns the authID and true if found and valid, empty string and false otherwise.
 func (s *StickyStore) Get(key string) (string, bool) {
@@ -11,3 +11,28 @@ type Store interface {
 	// Delete removes the auth record identified by id.
 	Delete(ctx context.Context, id string) error
 }
+
+// RefreshLocker serializes token refreshes of an account across instances.
+type RefreshLocker interface {
+	// LockRefresh blocks until the refresh lock of id is held or ctx is done.
+	// The returned function releases it.
+	LockRefresh(ctx context.Context, id string) (unlock func(), err error)
+}
+
+// SharedStore is a Store that several instances use at once. While one
+// instance refreshes an account it holds the account's refresh lock, so a
+// refresh token is only ever used once; the others load the record it saved
+// instead of refreshing themselves.
+type SharedStore interface {
+	Store
+	RefreshLocker
+	// Load returns the stored record of id, or nil when there is none.
+	Load(ctx context.Context, id string) (*Auth, error)
+}
+
+// authLoader is a store that can read back a single record. Stores that
+// cannot lock by themselves still implement it so a separate RefreshLocker
+// lets waiting instances pick up the refreshed record.
+type authLoader interface {
+	Load(ctx context.Context, id string) (*Auth, error)
+}
@@ -0,0 +1,61 @@
+package provider
+
+import (
+	"context"
+
+	log "github.com/nghyane/llm-mux/internal/logging"
+)
+
+// DefaultStreamBufferSize is how many chunks ExecuteStream holds for a
+// consumer that has fallen behind when no size is configured.
+const DefaultStreamBufferSize = 32
+
+// SetStreamBufferSize sets how many chunks ExecuteStream buffers ahead of its
+// consumer. Once the buffer is full the upstream is no longer read until the
+// consumer catches up, so a slow client bounds memory instead of growing it.
+// Values below one restore DefaultStreamBufferSize.
+func (m *Manager) SetStreamBufferSize(size int) {
+	if m == nil {
+		return
+	}
+	if size < 1 {
+		size = DefaultStreamBufferSize
+	}
+	m.streamBufferSize.Store(int32(size))
+}
+
+func (m *Manager) streamBufferLen() int {
+	if size := m.streamBufferSize.Load(); size > 0 {
+		return int(size)
+	}
+	return DefaultStreamBufferSize
+}
+
+// BackpressuredStreams returns how many streams filled their buffer and had
+// to wait for a slow consumer.
+func (m *Manager) BackpressuredStreams() int64 {
+	return m.backpressuredStreams.Load()
+}
+
+// sendStreamChunk hands chunk to the consumer. When the buffer is full the
+// stream is counted as backpressured, once, and the send blocks; the caller
+// stops reading the upstream until there is room again. It returns false if
+// ctx ends first.
+func (m *Manager) sendStreamChunk(ctx context.Context, out chan<- StreamChunk, chunk StreamChunk, engaged *bool) bool {
+	select {
+	case out <- chunk:
+		return true
+	default:
+	}
+	if !*engaged {
+		*engaged = true
+		m.backpressuredStreams.Add(1)
+		log.Debugf("stream buffer full (%d chunks), pausing upstream reads for a slow consumer", cap(out))
+	}
+	select {
+	case out <- chunk:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
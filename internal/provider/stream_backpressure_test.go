@@ -0,0 +1,100 @@
+package provider
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// floodExecutor streams chunks as fast as it is allowed to, up to limit
+// (unbounded when zero), counting how many it has produced.
+type floodExecutor struct {
+	limit    int
+	produced atomic.Int64
+}
+
+func (e *floodExecutor) Identifier() string { return "flood" }
+
+func (e *floodExecutor) Execute(context.Context, *Auth, Request, Options) (Response, error) {
+	return Response{}, nil
+}
+
+func (e *floodExecutor) Refresh(_ context.Context, auth *Auth) (*Auth, error) { return auth, nil }
+
+func (e *floodExecutor) CountTokens(context.Context, *Auth, Request, Options) (Response, error) {
+	return Response{}, nil
+}
+
+func (e *floodExecutor) ExecuteStream(ctx context.Context, _ *Auth, _ Request, _ Options) (<-chan StreamChunk, error) {
+	ch := make(chan StreamChunk)
+	go func() {
+		defer close(ch)
+		for i := 0; e.limit == 0 || i < e.limit; i++ {
+			select {
+			case ch <- StreamChunk{Payload: make([]byte, 1024)}:
+				e.produced.Add(1)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+func startFloodStream(t *testing.T, ctx context.Context, exec *floodExecutor, bufferSize int) (*Manager, <-chan StreamChunk) {
+	t.Helper()
+	m := NewManager(nil, nil, nil)
+	m.RegisterExecutor(exec)
+	m.SetStreamBufferSize(bufferSize)
+	if _, err := m.Register(context.Background(), &Auth{ID: "flood-1", Provider: "flood"}); err != nil {
+		t.Fatal(err)
+	}
+	out, err := m.executeStreamWithProvider(ctx, "flood", Request{}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m, out
+}
+
+func TestStreamBackpressure_SlowConsumerBoundsBuffering(t *testing.T) {
+	const bufferSize = 4
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	exec := &floodExecutor{}
+	m, out := startFloodStream(t, ctx, exec, bufferSize)
+
+	// The upstream could produce without end; a consumer that reads a few
+	// chunks slowly must keep it within the buffer plus the chunk in hand.
+	for i := 0; i < 5; i++ {
+		time.Sleep(20 * time.Millisecond)
+		<-out
+		if produced, read := exec.produced.Load(), int64(i+1); produced-read > bufferSize+1 {
+			t.Fatalf("upstream ran %d chunks ahead of the consumer, want at most %d", produced-read, bufferSize+1)
+		}
+	}
+	if got := m.BackpressuredStreams(); got != 1 {
+		t.Errorf("BackpressuredStreams = %d, want 1", got)
+	}
+
+	cancel()
+	for range out {
+	}
+}
+
+func TestStreamBackpressure_BufferedStreamNotCounted(t *testing.T) {
+	exec := &floodExecutor{limit: 3}
+	m, out := startFloodStream(t, context.Background(), exec, 8)
+	// Let the whole stream land in the buffer before reading it.
+	time.Sleep(20 * time.Millisecond)
+	received := 0
+	for range out {
+		received++
+	}
+	if received != 3 {
+		t.Fatalf("received %d chunks, want 3", received)
+	}
+	if got := m.BackpressuredStreams(); got != 0 {
+		t.Errorf("BackpressuredStreams = %d, want 0", got)
+	}
+}
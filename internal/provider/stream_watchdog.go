@@ -0,0 +1,71 @@
+package provider
+
+import (
+	"net/http"
+	"time"
+)
+
+// StreamIdleTimeoutFunc returns the read-idle limit for a provider's streams.
+// A zero duration disables the watchdog.
+type StreamIdleTimeoutFunc func(provider string) time.Duration
+
+// SetStreamIdleTimeout configures how long ExecuteStream waits for the next
+// chunk before aborting the upstream call and failing the stream.
+func (m *Manager) SetStreamIdleTimeout(fn StreamIdleTimeoutFunc) {
+	if m == nil {
+		return
+	}
+	m.streamIdle.Store(&fn)
+}
+
+func (m *Manager) streamIdleTimeout(provider string) time.Duration {
+	fn := m.streamIdle.Load()
+	if fn == nil || *fn == nil {
+		return 0
+	}
+	return (*fn)(provider)
+}
+
+// streamWatchdog fires when no chunk has been seen for idle.
+type streamWatchdog struct {
+	idle  time.Duration
+	timer *time.Timer
+}
+
+func newStreamWatchdog(idle time.Duration) *streamWatchdog {
+	w := &streamWatchdog{idle: idle}
+	if idle > 0 {
+		w.timer = time.NewTimer(idle)
+	}
+	return w
+}
+
+// expired returns a channel that receives once the idle window elapses; it
+// is nil, and so never ready, when the watchdog is disabled.
+func (w *streamWatchdog) expired() <-chan time.Time {
+	if w.timer == nil {
+		return nil
+	}
+	return w.timer.C
+}
+
+func (w *streamWatchdog) reset() {
+	if w.timer != nil {
+		w.timer.Reset(w.idle)
+	}
+}
+
+func (w *streamWatchdog) stop() {
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+}
+
+func (w *streamWatchdog) err() *Error {
+	return &Error{
+		Code:       "stream_stalled",
+		Message:    "upstream stream stalled: no data for " + w.idle.String(),
+		Retryable:  true,
+		HTTPStatus: http.StatusGatewayTimeout,
+	}
+}
@@ -0,0 +1,105 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// pacedExecutor streams chunks at a fixed interval and then either closes the
+// stream or hangs until the upstream context is cancelled.
+type pacedExecutor struct {
+	interval  time.Duration
+	chunks    int
+	stall     bool
+	cancelled chan struct{}
+}
+
+func (e *pacedExecutor) Identifier() string { return "paced" }
+
+func (e *pacedExecutor) Execute(context.Context, *Auth, Request, Options) (Response, error) {
+	return Response{}, nil
+}
+
+func (e *pacedExecutor) Refresh(_ context.Context, auth *Auth) (*Auth, error) { return auth, nil }
+
+func (e *pacedExecutor) CountTokens(context.Context, *Auth, Request, Options) (Response, error) {
+	return Response{}, nil
+}
+
+func (e *pacedExecutor) ExecuteStream(ctx context.Context, _ *Auth, _ Request, _ Options) (<-chan StreamChunk, error) {
+	ch := make(chan StreamChunk)
+	go func() {
+		defer close(ch)
+		for i := 0; i < e.chunks; i++ {
+			select {
+			case <-time.After(e.interval):
+			case <-ctx.Done():
+				return
+			}
+			ch <- StreamChunk{Payload: []byte("data")}
+		}
+		if e.stall {
+			<-ctx.Done()
+			close(e.cancelled)
+		}
+	}()
+	return ch, nil
+}
+
+func runPacedStream(t *testing.T, exec *pacedExecutor, idle time.Duration) (int, error) {
+	t.Helper()
+	m := NewManager(nil, nil, nil)
+	m.RegisterExecutor(exec)
+	m.SetStreamIdleTimeout(func(string) time.Duration { return idle })
+	if _, err := m.Register(context.Background(), &Auth{ID: "paced-1", Provider: "paced"}); err != nil {
+		t.Fatal(err)
+	}
+	out, err := m.executeStreamWithProvider(context.Background(), "paced", Request{}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	received := 0
+	for chunk := range out {
+		if chunk.Err != nil {
+			return received, chunk.Err
+		}
+		received++
+	}
+	return received, nil
+}
+
+func TestStreamWatchdog_SlowButProgressing(t *testing.T) {
+	// Each gap is well under the idle limit, while the whole stream takes
+	// several times longer than it.
+	exec := &pacedExecutor{interval: 40 * time.Millisecond, chunks: 8}
+	received, err := runPacedStream(t, exec, 150*time.Millisecond)
+	if err != nil {
+		t.Fatalf("progressing stream failed: %v", err)
+	}
+	if received != 8 {
+		t.Fatalf("received %d chunks, want 8", received)
+	}
+}
+
+func TestStreamWatchdog_StalledStream(t *testing.T) {
+	exec := &pacedExecutor{interval: 10 * time.Millisecond, chunks: 2, stall: true, cancelled: make(chan struct{})}
+	start := time.Now()
+	received, err := runPacedStream(t, exec, 100*time.Millisecond)
+	var stallErr *Error
+	if !errors.As(err, &stallErr) || stallErr.Code != "stream_stalled" {
+		t.Fatalf("error = %v, want stream_stalled", err)
+	}
+	if received != 2 {
+		t.Fatalf("received %d chunks before the stall, want 2", received)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("stall detected after %s", elapsed)
+	}
+	select {
+	case <-exec.cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("upstream context was not cancelled")
+	}
+}
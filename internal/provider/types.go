@@ -19,6 +19,7 @@ type Auth struct {
 	FileName         string                 `json:"-"`
 	Storage          baseauth.TokenStorage  `json:"-"`
 	Label            string                 `json:"label,omitempty"`
+	Labels           map[string]string      `json:"labels,omitempty"`
 	Status           Status                 `json:"status"`
 	StatusMessage    string                 `json:"status_message,omitempty"`
 	Disabled         bool                   `json:"disabled"`
@@ -82,6 +83,12 @@ func (a *Auth) Clone() *Auth {
 			copyAuth.Attributes[key] = value
 		}
 	}
+	if len(a.Labels) > 0 {
+		copyAuth.Labels = make(map[string]string, len(a.Labels))
+		for key, value := range a.Labels {
+			copyAuth.Labels[key] = value
+		}
+	}
 	if len(a.Metadata) > 0 {
 		copyAuth.Metadata = make(map[string]any, len(a.Metadata))
 		for key, value := range a.Metadata {
@@ -180,6 +187,12 @@ func (a *Auth) AccountInfo() (string, string) {
 	return "", ""
 }
 
+// ReauthRequired reports whether the account's credentials expired and need
+// a new login before it can serve requests again.
+func (a *Auth) ReauthRequired() bool {
+	return a != nil && a.Status == StatusError && a.StatusMessage == StatusMessageReauthRequired
+}
+
 // ExpirationTime attempts to extract the credential expiration timestamp from metadata.
 // It inspects common keys such as "expired", "expire", "expires_at", and also
 // nested "token" objects to remain compatible with legacy auth file formats.
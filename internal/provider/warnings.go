@@ -0,0 +1,28 @@
+package provider
+
+import "slices"
+
+// warningsMetadataKey holds translation warnings in request metadata.
+const warningsMetadataKey = "llm_mux_warnings"
+
+// AddWarnings records non-fatal notices for the client in request metadata.
+// Executors call it after translating a request; the API handler surfaces
+// them once the call succeeds. Duplicates are ignored.
+func AddWarnings(meta map[string]any, warnings ...string) {
+	if meta == nil || len(warnings) == 0 {
+		return
+	}
+	existing, _ := meta[warningsMetadataKey].([]string)
+	for _, w := range warnings {
+		if !slices.Contains(existing, w) {
+			existing = append(existing, w)
+		}
+	}
+	meta[warningsMetadataKey] = existing
+}
+
+// Warnings returns the notices recorded by AddWarnings.
+func Warnings(meta map[string]any) []string {
+	w, _ := meta[warningsMetadataKey].([]string)
+	return w
+}
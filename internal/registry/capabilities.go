@@ -0,0 +1,77 @@
+package registry
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Capability is a bit set of request features a model can serve.
+// A zero value means the model did not declare its capabilities.
+type Capability uint8
+
+const (
+	CapVision Capability = 1 << iota
+	CapTools
+	CapJSONSchema
+	CapThinking
+)
+
+var capabilityNames = []struct {
+	cap  Capability
+	name string
+}{
+	{CapVision, "vision"},
+	{CapTools, "tools"},
+	{CapJSONSchema, "json_schema"},
+	{CapThinking, "thinking"},
+}
+
+// Has reports whether c includes every capability in want.
+func (c Capability) Has(want Capability) bool {
+	return c&want == want
+}
+
+// Missing returns the capabilities in want that c lacks.
+func (c Capability) Missing(want Capability) Capability {
+	return want &^ c
+}
+
+// String returns a comma-separated list of capability names.
+func (c Capability) String() string {
+	if c == 0 {
+		return "none"
+	}
+	names := make([]string, 0, len(capabilityNames))
+	for _, n := range capabilityNames {
+		if c&n.cap != 0 {
+			names = append(names, n.name)
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// Supports reports whether a model declaring c can serve a request needing want.
+// Models without declared capabilities are treated as unrestricted.
+func (c Capability) Supports(want Capability) bool {
+	return c == 0 || c.Has(want)
+}
+
+// ParseCapabilities returns the capability set named by names, as listed by
+// Capability.String.
+func ParseCapabilities(names []string) (Capability, error) {
+	var c Capability
+	for _, name := range names {
+		found := false
+		for _, n := range capabilityNames {
+			if strings.EqualFold(strings.TrimSpace(name), n.name) {
+				c |= n.cap
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("unknown capability %q", name)
+		}
+	}
+	return c, nil
+}
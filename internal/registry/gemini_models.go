@@ -45,6 +45,12 @@ var claudeViaAntigravityModels = []*ModelInfo{
 		Desc("Claude Opus 4.5 with extended thinking via google antigravity").Version("4.5").Created(1761955200).Thinking(1024, 100000).B(),
 }
 
+// geminiEmbeddingModels defines embedding models served by the Gemini API (gemini only).
+var geminiEmbeddingModels = []*ModelInfo{
+	Gemini("gemini-embedding-001").Display("Gemini Embedding 001").
+		Desc("Text embedding model for semantic search, classification and clustering").Created(1752624000).Limits(2048, 0).B(),
+}
+
 // =============================================================================
 // Provider-Specific Hidden Models
 // =============================================================================
@@ -102,6 +108,13 @@ func GetGeminiModelsForProvider(providerType string) []*ModelInfo {
 		models = append(models, clone)
 	}
 
+	// Embeddings are only wired up for the Gemini API
+	if providerType == "gemini" {
+		for _, m := range geminiEmbeddingModels {
+			models = append(models, cloneModelWithType(m, providerType))
+		}
+	}
+
 	// Add Claude via Antigravity models only for gemini-cli
 	if providerType == "gemini-cli" {
 		for _, m := range claudeViaAntigravityModels {
@@ -135,6 +148,8 @@ func cloneModelWithType(src *ModelInfo, providerType string) *ModelInfo {
 		UpstreamName:               src.UpstreamName,
 		Hidden:                     src.Hidden,
 		Priority:                   src.Priority,
+		Capabilities:               src.Capabilities,
+		Category:                   src.Category,
 	}
 	if src.Thinking != nil {
 		clone.Thinking = &ThinkingSupport{
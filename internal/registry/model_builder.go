@@ -21,6 +21,9 @@ const (
 	geminiOutputLimit = 65536
 	claudeInputLimit  = 200000
 	claudeOutputLimit = 64000
+
+	// claudeContext1MBeta unlocks the 1M token context window on Sonnet 4 and 4.5.
+	claudeContext1MBeta = "context-1m-2025-08-07"
 )
 
 // =============================================================================
@@ -38,6 +41,7 @@ func Gemini(id string) *ModelBuilder {
 		InputTokenLimit:            geminiInputLimit,
 		OutputTokenLimit:           geminiOutputLimit,
 		SupportedGenerationMethods: defaultGeminiMethods,
+		Capabilities:               CapVision | CapTools | CapJSONSchema,
 	}}
 }
 
@@ -50,6 +54,7 @@ func Claude(id string) *ModelBuilder {
 		Type:             "claude",
 		InputTokenLimit:  claudeInputLimit,
 		OutputTokenLimit: claudeOutputLimit,
+		Capabilities:     CapVision | CapTools | CapThinking,
 	}}
 }
 
@@ -65,6 +70,7 @@ func ClaudeVia(id, provider string) *ModelBuilder {
 		InputTokenLimit:            claudeInputLimit,
 		OutputTokenLimit:           claudeOutputLimit,
 		SupportedGenerationMethods: defaultClaudeMethods,
+		Capabilities:               CapVision | CapTools | CapThinking,
 	}}
 }
 
@@ -77,57 +83,96 @@ func OpenAI(id string) *ModelBuilder {
 		Type:                "codex",
 		ContextLength:       400000,
 		MaxCompletionTokens: 128000,
+		Capabilities:        CapVision | CapTools | CapJSONSchema | CapThinking,
 	}}
 }
 
 // Kiro creates a builder for Kiro/Amazon Q models.
 func Kiro(id string) *ModelBuilder {
 	return &ModelBuilder{info: &ModelInfo{
-		ID:      id,
-		Object:  "model",
-		OwnedBy: "kiro",
-		Type:    "kiro",
+		ID:           id,
+		Object:       "model",
+		OwnedBy:      "kiro",
+		Type:         "kiro",
+		Capabilities: CapTools,
 	}}
 }
 
 // Copilot creates a builder for GitHub Copilot models.
 func Copilot(id string) *ModelBuilder {
 	return &ModelBuilder{info: &ModelInfo{
-		ID:       id,
-		Object:   "model",
-		OwnedBy:  "github-copilot",
-		Type:     "github-copilot",
-		Priority: 2, // Fallback
+		ID:           id,
+		Object:       "model",
+		OwnedBy:      "github-copilot",
+		Type:         "github-copilot",
+		Priority:     2, // Fallback
+		Capabilities: CapVision | CapTools,
 	}}
 }
 
 // IFlow creates a builder for iFlow models.
 func IFlow(id string) *ModelBuilder {
 	return &ModelBuilder{info: &ModelInfo{
-		ID:      id,
-		Object:  "model",
-		OwnedBy: "iflow",
-		Type:    "iflow",
+		ID:           id,
+		Object:       "model",
+		OwnedBy:      "iflow",
+		Type:         "iflow",
+		Capabilities: CapTools,
 	}}
 }
 
 // Cline creates a builder for Cline models.
 func Cline(id string) *ModelBuilder {
 	return &ModelBuilder{info: &ModelInfo{
-		ID:      id,
-		Object:  "model",
-		OwnedBy: "cline",
-		Type:    "cline",
+		ID:           id,
+		Object:       "model",
+		OwnedBy:      "cline",
+		Type:         "cline",
+		Capabilities: CapTools,
 	}}
 }
 
 // Qwen creates a builder for Qwen models.
 func Qwen(id string) *ModelBuilder {
 	return &ModelBuilder{info: &ModelInfo{
-		ID:      id,
-		Object:  "model",
-		OwnedBy: "qwen",
-		Type:    "qwen",
+		ID:           id,
+		Object:       "model",
+		OwnedBy:      "qwen",
+		Type:         "qwen",
+		Capabilities: CapTools,
+	}}
+}
+
+// Cohere creates a builder for Cohere models.
+func Cohere(id string) *ModelBuilder {
+	return &ModelBuilder{info: &ModelInfo{
+		ID:           id,
+		Object:       "model",
+		OwnedBy:      "cohere",
+		Type:         "cohere",
+		Capabilities: CapTools | CapJSONSchema,
+	}}
+}
+
+// XAI creates a builder for xAI Grok models.
+func XAI(id string) *ModelBuilder {
+	return &ModelBuilder{info: &ModelInfo{
+		ID:           id,
+		Object:       "model",
+		OwnedBy:      "xai",
+		Type:         "xai",
+		Capabilities: CapTools | CapJSONSchema,
+	}}
+}
+
+// Mistral creates a builder for Mistral models.
+func Mistral(id string) *ModelBuilder {
+	return &ModelBuilder{info: &ModelInfo{
+		ID:           id,
+		Object:       "model",
+		OwnedBy:      "mistral",
+		Type:         "mistral",
+		Capabilities: CapTools | CapJSONSchema,
 	}}
 }
 
@@ -150,6 +195,7 @@ func (b *ModelBuilder) Hidden() *ModelBuilder {
 // Thinking sets thinking support with min/max budget (dynamic allowed).
 func (b *ModelBuilder) Thinking(min, max int) *ModelBuilder {
 	b.info.Thinking = &ThinkingSupport{Min: min, Max: max, DynamicAllowed: true}
+	b.info.Capabilities |= CapThinking
 	return b
 }
 
@@ -158,6 +204,7 @@ func (b *ModelBuilder) ThinkingFull(min, max int, zeroAllowed, dynamicAllowed bo
 	b.info.Thinking = &ThinkingSupport{
 		Min: min, Max: max, ZeroAllowed: zeroAllowed, DynamicAllowed: dynamicAllowed,
 	}
+	b.info.Capabilities |= CapThinking
 	return b
 }
 
@@ -217,6 +264,24 @@ func (b *ModelBuilder) ProviderType(t string) *ModelBuilder {
 	return b
 }
 
+// Caps adds request capabilities to the model.
+func (b *ModelBuilder) Caps(c Capability) *ModelBuilder {
+	b.info.Capabilities |= c
+	return b
+}
+
+// Betas sets the anthropic-beta flags the model requires upstream.
+func (b *ModelBuilder) Betas(flags ...string) *ModelBuilder {
+	b.info.Betas = flags
+	return b
+}
+
+// Rerank marks the model as served only by /v1/rerank.
+func (b *ModelBuilder) Rerank() *ModelBuilder {
+	b.info.Category = ModelCategoryRerank
+	return b
+}
+
 // Priority sets routing priority (lower = higher priority).
 func (b *ModelBuilder) Priority(p int) *ModelBuilder {
 	b.info.Priority = p
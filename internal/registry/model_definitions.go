@@ -8,6 +8,7 @@ func GetClaudeModels() []*ModelInfo {
 	return []*ModelInfo{
 		Claude("claude-haiku-4-5-20251001").Display("Claude 4.5 Haiku").Created(1759276800).Context(200000, 64000).B(),
 		Claude("claude-sonnet-4-5-20250929").Display("Claude 4.5 Sonnet").Created(1759104000).Canonical("claude-sonnet-4-5").Context(200000, 64000).B(),
+		Claude("claude-sonnet-4-5-1m").Display("Claude 4.5 Sonnet 1M").Created(1759104000).Context(1000000, 64000).Limits(1000000, 64000).Betas(claudeContext1MBeta).B(),
 		Claude("claude-sonnet-4-5-thinking").Display("Claude 4.5 Sonnet Thinking").Created(1759104000).Context(200000, 64000).Thinking(1024, 100000).B(),
 		Claude("claude-opus-4-5-thinking").Display("Claude 4.5 Opus Thinking").Created(1761955200).Context(200000, 64000).Thinking(1024, 100000).B(),
 		Claude("claude-opus-4-5-thinking-low").Display("Claude 4.5 Opus Thinking Low").Created(1761955200).Context(200000, 64000).Thinking(1024, 100000).B(),
@@ -16,6 +17,7 @@ func GetClaudeModels() []*ModelInfo {
 		Claude("claude-opus-4-5-20251101").Display("Claude 4.5 Opus").Desc("Premium model combining maximum intelligence with practical performance").Created(1761955200).Canonical("claude-opus-4-5").Context(200000, 64000).B(),
 		Claude("claude-opus-4-1-20250805").Display("Claude 4.1 Opus").Created(1722945600).Context(200000, 32000).B(),
 		Claude("claude-opus-4-20250514").Display("Claude 4 Opus").Created(1715644800).Canonical("claude-opus-4").Context(200000, 32000).B(),
+		Claude("claude-sonnet-4-1m").Display("Claude 4 Sonnet 1M").Created(1715644800).Context(1000000, 64000).Limits(1000000, 64000).Betas(claudeContext1MBeta).B(),
 		Claude("claude-sonnet-4-20250514").Display("Claude 4 Sonnet").Created(1715644800).Canonical("claude-sonnet-4").Context(200000, 64000).B(),
 		Claude("claude-3-7-sonnet-20250219").Display("Claude 3.7 Sonnet").Created(1708300800).Context(128000, 8192).B(),
 		Claude("claude-3-5-haiku-20241022").Display("Claude 3.5 Haiku").Created(1729555200).Context(128000, 8192).B(),
@@ -62,17 +64,17 @@ func GetQwenModels() []*ModelInfo {
 	return []*ModelInfo{
 		Qwen("qwen3-coder-plus").Display("Qwen3 Coder Plus").Desc("Advanced code generation and understanding model").Created(1753228800).Version("3.0").Context(32768, 8192).B(),
 		Qwen("qwen3-coder-flash").Display("Qwen3 Coder Flash").Desc("Fast code generation model").Created(1753228800).Version("3.0").Context(8192, 2048).B(),
-		Qwen("vision-model").Display("Qwen3 Vision Model").Desc("Vision model model").Created(1758672000).Version("3.0").Context(32768, 2048).B(),
+		Qwen("vision-model").Display("Qwen3 Vision Model").Caps(CapVision).Desc("Vision model model").Created(1758672000).Version("3.0").Context(32768, 2048).B(),
 	}
 }
 
 // GetIFlowModels returns supported models for iFlow OAuth accounts.
 func GetIFlowModels() []*ModelInfo {
 	return []*ModelInfo{
-		IFlow("tstars2.0").Display("TStars-2.0").Desc("iFlow TStars-2.0 multimodal assistant").Created(1746489600).B(),
+		IFlow("tstars2.0").Display("TStars-2.0").Caps(CapVision).Desc("iFlow TStars-2.0 multimodal assistant").Created(1746489600).B(),
 		IFlow("qwen3-coder-plus").Display("Qwen3-Coder-Plus").Desc("Qwen3 Coder Plus code generation").Created(1753228800).B(),
 		IFlow("qwen3-max").Display("Qwen3-Max").Desc("Qwen3 flagship model").Created(1758672000).B(),
-		IFlow("qwen3-vl-plus").Display("Qwen3-VL-Plus").Desc("Qwen3 multimodal vision-language").Created(1758672000).B(),
+		IFlow("qwen3-vl-plus").Display("Qwen3-VL-Plus").Caps(CapVision).Desc("Qwen3 multimodal vision-language").Created(1758672000).B(),
 		IFlow("qwen3-max-preview").Display("Qwen3-Max-Preview").Desc("Qwen3 Max preview build").Created(1757030400).B(),
 		IFlow("kimi-k2-0905").Display("Kimi-K2-Instruct-0905").Desc("Moonshot Kimi K2 instruct 0905").Created(1757030400).B(),
 		IFlow("glm-4.6").Display("GLM-4.6").Desc("Zhipu GLM 4.6 general model").Created(1759190400).B(),
@@ -142,3 +144,44 @@ func GetKiroModels() []*ModelInfo {
 		Kiro("claude-3-5-haiku-20241022").Display("Claude 3.5 Haiku").Desc("Claude 3.5 Haiku via Kiro/Amazon Q").Created(1729555200).B(),
 	}
 }
+
+// GetCohereModels returns the standard Cohere model definitions.
+func GetCohereModels() []*ModelInfo {
+	return []*ModelInfo{
+		Cohere("command-a-03-2025").Display("Command A").Desc("Cohere's most capable chat model").Created(1741132800).Canonical("command-a").Context(256000, 8000).B(),
+		Cohere("command-r-plus-08-2024").Display("Command R+").Desc("Cohere Command R+ (08-2024)").Created(1722816000).Canonical("command-r-plus").Context(128000, 4000).B(),
+		Cohere("command-r-08-2024").Display("Command R").Desc("Cohere Command R (08-2024)").Created(1722816000).Canonical("command-r").Context(128000, 4000).B(),
+		Cohere("command-r7b-12-2024").Display("Command R7B").Desc("Cohere's small, fast Command model").Created(1734048000).Context(128000, 4000).B(),
+		Cohere("embed-v4.0").Display("Embed v4").Desc("Cohere multilingual embedding model").Created(1744848000).B(),
+		Cohere("rerank-v3.5").Display("Rerank v3.5").Desc("Cohere multilingual rerank model").Created(1733097600).Rerank().B(),
+	}
+}
+
+// GetXAIModels returns the standard xAI Grok model definitions.
+func GetXAIModels() []*ModelInfo {
+	return []*ModelInfo{
+		XAI("grok-4-0709").Display("Grok 4").Caps(CapVision).Desc("xAI's flagship reasoning model").Created(1752019200).Canonical("grok-4").Context(256000, 64000).B(),
+		XAI("grok-4-fast-reasoning").Display("Grok 4 Fast").Caps(CapVision).Desc("Grok 4 Fast with reasoning").Created(1758240000).Context(2000000, 30000).B(),
+		XAI("grok-4-fast-non-reasoning").Display("Grok 4 Fast (Non-Reasoning)").Caps(CapVision).Desc("Grok 4 Fast without reasoning").Created(1758240000).Context(2000000, 30000).B(),
+		XAI("grok-code-fast-1").Display("Grok Code Fast 1").Desc("xAI's fast agentic coding model").Created(1756166400).Context(256000, 10000).B(),
+		XAI("grok-3").Display("Grok 3").Desc("xAI Grok 3").Created(1744070400).Context(131072, 16384).B(),
+		XAI("grok-3-mini").Display("Grok 3 Mini").Desc("Small Grok 3 reasoning model with adjustable effort").Created(1744070400).Context(131072, 16384).B(),
+	}
+}
+
+// GetMistralModels returns the standard Mistral model definitions.
+func GetMistralModels() []*ModelInfo {
+	return []*ModelInfo{
+		Mistral("mistral-large-latest").Display("Mistral Large").Desc("Mistral's flagship model").Created(1731024000).Context(131072, 32768).B(),
+		Mistral("mistral-medium-latest").Display("Mistral Medium").Caps(CapVision).Desc("Mistral's balanced multimodal model").Created(1746576000).Context(131072, 32768).B(),
+		Mistral("mistral-small-latest").Display("Mistral Small").Caps(CapVision).Desc("Mistral's fast, cost-efficient model").Created(1742256000).Context(131072, 32768).B(),
+		Mistral("magistral-medium-latest").Display("Magistral Medium").Desc("Mistral's reasoning model").Created(1749513600).Context(40960, 40960).B(),
+		Mistral("magistral-small-latest").Display("Magistral Small").Desc("Mistral's small reasoning model").Created(1749513600).Context(40960, 40960).B(),
+		Mistral("codestral-latest").Display("Codestral").Desc("Mistral's code completion model").Created(1736726400).Context(256000, 32768).B(),
+		Mistral("devstral-medium-latest").Display("Devstral Medium").Desc("Mistral's agentic coding model").Created(1752105600).Context(131072, 32768).B(),
+		Mistral("devstral-small-latest").Display("Devstral Small").Desc("Mistral's small agentic coding model").Created(1752105600).Context(131072, 32768).B(),
+		Mistral("pixtral-large-latest").Display("Pixtral Large").Caps(CapVision).Desc("Mistral's large vision model").Created(1731974400).Context(131072, 32768).B(),
+		Mistral("ministral-8b-latest").Display("Ministral 8B").Desc("Mistral's 8B edge model").Created(1729036800).Context(131072, 32768).B(),
+		Mistral("ministral-3b-latest").Display("Ministral 3B").Desc("Mistral's 3B edge model").Created(1729036800).Context(131072, 32768).B(),
+	}
+}
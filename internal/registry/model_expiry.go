@@ -0,0 +1,159 @@
+package registry
+
+import (
+	"context"
+	"time"
+
+	log "github.com/nghyane/llm-mux/internal/logging"
+)
+
+// SetModelExpiry configures how long discovered models stay registered. A
+// discovered model expires ttl after the upstream last listed it, even when
+// the client keeps re-registering a cached listing. A model missing from a
+// newer listing is kept until grace has passed since it was last listed, so a
+// listing that briefly leaves a model out does not make it flap. A zero ttl
+// disables expiry; a zero grace drops missing models right away.
+func (r *ModelRegistry) SetModelExpiry(ttl, grace time.Duration) {
+	if r == nil {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.modelTTL = ttl
+	r.modelGrace = grace
+}
+
+// SetRefreshHandler installs the function Refresh calls to re-register the
+// models of every client, typically by listing the upstreams again.
+func (r *ModelRegistry) SetRefreshHandler(fn func(context.Context)) {
+	if r == nil {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.refresh = fn
+}
+
+// Refresh re-registers every client's models through the refresh handler and
+// then drops the discovered models that have expired. It returns the number
+// of expired registrations.
+func (r *ModelRegistry) Refresh(ctx context.Context) int {
+	if r == nil {
+		return 0
+	}
+	r.mutex.RLock()
+	refresh := r.refresh
+	r.mutex.RUnlock()
+	if refresh != nil {
+		refresh(ctx)
+	}
+	return r.ExpireStaleModels()
+}
+
+// ExpireStaleModels removes the discovered models whose TTL has passed from
+// every client and returns the number of expired registrations.
+func (r *ModelRegistry) ExpireStaleModels() int {
+	if r == nil {
+		return 0
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.modelTTL <= 0 {
+		return 0
+	}
+
+	now := time.Now()
+	expired := 0
+	for clientID, seen := range r.discoveredSeen {
+		stale := 0
+		for _, last := range seen {
+			if now.Sub(last) >= r.modelTTL {
+				stale++
+			}
+		}
+		if stale == 0 {
+			continue
+		}
+		expired += stale
+		provider := r.clientProviders[clientID]
+		models := make([]*ModelInfo, 0, len(r.clientModels[clientID]))
+		for _, modelID := range r.clientModels[clientID] {
+			reg, ok := r.models[registrationKey(provider, modelID)]
+			if !ok || reg.Info == nil {
+				continue
+			}
+			info := cloneModelInfo(reg.Info)
+			// Registrations are shared between clients; use this client's listing time.
+			info.DiscoveredAt = seen[modelID]
+			models = append(models, info)
+		}
+		r.registerClientInternal(clientID, provider, models)
+	}
+	if expired > 0 {
+		log.Infof("model registry: expired %d discovered models", expired)
+	}
+	return expired
+}
+
+// applyModelExpiry prepares the models a client registers: it drops
+// discovered models past their TTL, carries over previously discovered models
+// still within the grace period, and records when each was last listed.
+func (r *ModelRegistry) applyModelExpiry(clientID, provider string, models []*ModelInfo, now time.Time) []*ModelInfo {
+	previous := r.discoveredSeen[clientID]
+	seen := make(map[string]time.Time)
+	present := make(map[string]struct{}, len(models))
+	kept := make([]*ModelInfo, 0, len(models))
+	for _, model := range models {
+		if model == nil {
+			continue
+		}
+		present[model.ID] = struct{}{}
+		if model.DiscoveredAt.IsZero() {
+			kept = append(kept, model)
+			continue
+		}
+		if r.modelTTL > 0 && now.Sub(model.DiscoveredAt) >= r.modelTTL {
+			log.Debugf("model registry: %s of client %s expired", model.ID, clientID)
+			continue
+		}
+		if model.DiscoveredAt.After(seen[model.ID]) {
+			seen[model.ID] = model.DiscoveredAt
+		}
+		kept = append(kept, model)
+	}
+
+	oldProvider := r.clientProviders[clientID]
+	for modelID, last := range previous {
+		if _, ok := present[modelID]; ok {
+			continue
+		}
+		age := now.Sub(last)
+		if age >= r.modelGrace || (r.modelTTL > 0 && age >= r.modelTTL) {
+			continue
+		}
+		reg, ok := r.models[registrationKey(oldProvider, modelID)]
+		if !ok || reg.Info == nil {
+			continue
+		}
+		info := cloneModelInfo(reg.Info)
+		info.DiscoveredAt = last
+		seen[modelID] = last
+		kept = append(kept, info)
+		log.Debugf("model registry: keeping %s of client %s within the grace period", modelID, clientID)
+	}
+
+	if len(seen) > 0 {
+		r.discoveredSeen[clientID] = seen
+	} else {
+		delete(r.discoveredSeen, clientID)
+	}
+	return kept
+}
+
+// registrationKey returns the key of a model registration in r.models.
+func registrationKey(provider, modelID string) string {
+	if provider == "" {
+		return modelID
+	}
+	return provider + ":" + modelID
+}
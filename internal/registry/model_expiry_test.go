@@ -0,0 +1,100 @@
+package registry
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestModelExpiry_TTL(t *testing.T) {
+	r := newModelRegistry()
+	r.SetModelExpiry(time.Hour, 0)
+	now := time.Now()
+	r.RegisterClient("c1", "xai", []*ModelInfo{
+		{ID: "grok-static"},
+		{ID: "grok-fresh", DiscoveredAt: now},
+		{ID: "grok-stale", DiscoveredAt: now.Add(-2 * time.Hour)},
+	})
+	if r.ClientSupportsModel("c1", "grok-stale") {
+		t.Error("model listed past its TTL was registered")
+	}
+	if !r.ClientSupportsModel("c1", "grok-fresh") || !r.ClientSupportsModel("c1", "grok-static") {
+		t.Fatal("fresh and static models must be registered")
+	}
+
+	// The upstream stops answering: the cached listing ages out.
+	r.mutex.Lock()
+	r.discoveredSeen["c1"]["grok-fresh"] = now.Add(-time.Hour)
+	r.mutex.Unlock()
+	if got := r.ExpireStaleModels(); got != 1 {
+		t.Errorf("ExpireStaleModels = %d, want 1", got)
+	}
+	if r.ClientSupportsModel("c1", "grok-fresh") {
+		t.Error("expired model still registered")
+	}
+	if !r.ClientSupportsModel("c1", "grok-static") {
+		t.Error("static model must never expire")
+	}
+	if got := r.ExpireStaleModels(); got != 0 {
+		t.Errorf("second sweep expired %d models", got)
+	}
+}
+
+func TestModelExpiry_GracePeriod(t *testing.T) {
+	r := newModelRegistry()
+	r.SetModelExpiry(24*time.Hour, 2*time.Hour)
+	listed := time.Now().Add(-time.Hour)
+	r.RegisterClient("c1", "openai-compatibility", []*ModelInfo{
+		{ID: "m-kept", DiscoveredAt: listed},
+		{ID: "m-old", DiscoveredAt: listed},
+	})
+
+	// A newer listing leaves m-kept out: it stays within the grace period.
+	r.RegisterClient("c1", "openai-compatibility", []*ModelInfo{{ID: "m-old", DiscoveredAt: time.Now()}})
+	if !r.ClientSupportsModel("c1", "m-kept") {
+		t.Fatal("model missing from one listing dropped within the grace period")
+	}
+
+	// Once the grace period has passed it is dropped.
+	r.mutex.Lock()
+	r.discoveredSeen["c1"]["m-kept"] = time.Now().Add(-3 * time.Hour)
+	r.mutex.Unlock()
+	r.RegisterClient("c1", "openai-compatibility", []*ModelInfo{{ID: "m-old", DiscoveredAt: time.Now()}})
+	if r.ClientSupportsModel("c1", "m-kept") {
+		t.Error("model still registered after the grace period")
+	}
+	if !r.ClientSupportsModel("c1", "m-old") {
+		t.Error("listed model dropped")
+	}
+
+	r.UnregisterClient("c1")
+	if _, ok := r.discoveredSeen["c1"]; ok {
+		t.Error("unregistering a client must forget its listing times")
+	}
+}
+
+func TestModelRegistry_Refresh(t *testing.T) {
+	r := newModelRegistry()
+	r.SetModelExpiry(time.Hour, 0)
+	r.RegisterClient("c1", "mistral", []*ModelInfo{{ID: "mistral-gone", DiscoveredAt: time.Now()}})
+
+	calls := 0
+	r.SetRefreshHandler(func(context.Context) {
+		calls++
+		// A new model is listed; the other comes from an old cached listing.
+		r.RegisterClient("c1", "mistral", []*ModelInfo{
+			{ID: "mistral-new", DiscoveredAt: time.Now()},
+			{ID: "mistral-gone", DiscoveredAt: time.Now().Add(-2 * time.Hour)},
+		})
+	})
+	r.Refresh(context.Background())
+	if calls != 1 {
+		t.Fatalf("refresh handler called %d times", calls)
+	}
+	if !r.ClientSupportsModel("c1", "mistral-new") {
+		t.Error("refresh did not register the newly listed model")
+	}
+	if r.ClientSupportsModel("c1", "mistral-gone") {
+		t.Error("refresh kept a model past its TTL")
+	}
+}
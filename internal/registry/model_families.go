@@ -0,0 +1,430 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/json"
+	"github.com/nghyane/llm-mux/internal/persist"
+)
+
+// ModelFamiliesFileName is the file, stored next to the config file, that holds
+// families registered at runtime through the management API.
+const ModelFamiliesFileName = "model-families.json"
+
+// FamilyMember is a provider-specific model that serves a canonical model.
+type FamilyMember struct {
+	Provider string `json:"provider" yaml:"provider"`
+	Model    string `json:"model" yaml:"model"`
+}
+
+// ModelFamilies lists the built-in families keyed by canonical ID.
+// Members are ordered by routing preference.
+var ModelFamilies = map[string][]FamilyMember{
+	"claude-sonnet-4-5": {
+		{Provider: "claude", Model: "claude-sonnet-4-5-20250929"},
+		{Provider: "kiro", Model: "claude-sonnet-4-5-20250929"},
+		{Provider: "github-copilot", Model: "claude-sonnet-4.5"},
+	},
+	"claude-opus-4-5": {
+		{Provider: "claude", Model: "claude-opus-4-5-20251101"},
+		{Provider: "kiro", Model: "claude-opus-4-5-20251101"},
+		{Provider: "github-copilot", Model: "claude-opus-4.5"},
+	},
+	"claude-sonnet-4": {
+		{Provider: "claude", Model: "claude-sonnet-4-20250514"},
+		{Provider: "kiro", Model: "claude-sonnet-4-20250514"},
+		{Provider: "github-copilot", Model: "claude-sonnet-4"},
+	},
+	"claude-opus-4": {
+		{Provider: "claude", Model: "claude-opus-4-20250514"},
+		{Provider: "kiro", Model: "claude-opus-4-20250514"},
+	},
+	"gemini-2.5-pro": {
+		{Provider: "gemini", Model: "gemini-2.5-pro"},
+		{Provider: "vertex", Model: "gemini-2.5-pro"},
+		{Provider: "gemini-cli", Model: "gemini-2.5-pro"},
+		{Provider: "aistudio", Model: "gemini-2.5-pro"},
+		{Provider: "antigravity", Model: "gemini-2.5-pro"},
+		{Provider: "github-copilot", Model: "gemini-2.5-pro"},
+	},
+	"gemini-2.5-flash": {
+		{Provider: "gemini", Model: "gemini-2.5-flash"},
+		{Provider: "vertex", Model: "gemini-2.5-flash"},
+		{Provider: "gemini-cli", Model: "gemini-2.5-flash"},
+		{Provider: "aistudio", Model: "gemini-2.5-flash"},
+		{Provider: "antigravity", Model: "gemini-2.5-flash"},
+	},
+	"gemini-2.5-flash-lite": {
+		{Provider: "gemini", Model: "gemini-2.5-flash-lite"},
+		{Provider: "vertex", Model: "gemini-2.5-flash-lite"},
+		{Provider: "gemini-cli", Model: "gemini-2.5-flash-lite"},
+		{Provider: "aistudio", Model: "gemini-2.5-flash-lite"},
+		{Provider: "antigravity", Model: "gemini-2.5-flash-lite"},
+	},
+	"command-a": {
+		{Provider: "cohere", Model: "command-a-03-2025"},
+	},
+	"command-r-plus": {
+		{Provider: "cohere", Model: "command-r-plus-08-2024"},
+	},
+	"command-r": {
+		{Provider: "cohere", Model: "command-r-08-2024"},
+	},
+	"grok-4": {
+		{Provider: "xai", Model: "grok-4-0709"},
+	},
+	"grok-code-fast-1": {
+		{Provider: "xai", Model: "grok-code-fast-1"},
+		{Provider: "github-copilot", Model: "grok-code-fast-1"},
+		{Provider: "cline", Model: "x-ai/grok-code-fast-1"},
+	},
+}
+
+// EmbeddingModelFamilies lists the built-in families of embedding models.
+// They share the family table with ModelFamilies but are served only by
+// /v1/embeddings.
+var EmbeddingModelFamilies = map[string][]FamilyMember{
+	"gemini-embedding-001": {
+		{Provider: "gemini", Model: "gemini-embedding-001"},
+	},
+	"embed-v4.0": {
+		{Provider: "cohere", Model: "embed-v4.0"},
+	},
+}
+
+// familyTable merges the family sources. Precedence, lowest to highest:
+// built-in EmbeddingModelFamilies and ModelFamilies, the model-families config section, and families
+// registered at runtime through the management API.
+// Lookups are served from a precomputed snapshot guarded by mu.
+type familyTable struct {
+	mu sync.RWMutex
+	// configured holds families from the config file; they override built-ins.
+	configured map[string][]FamilyMember
+	// custom holds families registered at runtime; they override everything else.
+	custom map[string][]FamilyMember
+	// merged is the effective family set keyed by canonical ID.
+	merged map[string][]FamilyMember
+	// memberIndex maps member model IDs to their canonical ID.
+	memberIndex map[string]string
+}
+
+var families = newFamilyTable()
+
+func newFamilyTable() *familyTable {
+	t := &familyTable{
+		configured: make(map[string][]FamilyMember),
+		custom:     make(map[string][]FamilyMember),
+	}
+	t.rebuild()
+	return t
+}
+
+// rebuild recomputes merged and memberIndex. Must be called with mu held.
+func (t *familyTable) rebuild() {
+	merged := make(map[string][]FamilyMember, len(EmbeddingModelFamilies)+len(ModelFamilies)+len(t.configured)+len(t.custom))
+	for id, members := range EmbeddingModelFamilies {
+		merged[id] = members
+	}
+	for id, members := range ModelFamilies {
+		merged[id] = members
+	}
+	for id, members := range t.configured {
+		merged[id] = members
+	}
+	for id, members := range t.custom {
+		merged[id] = members
+	}
+
+	// Sort canonical IDs so member collisions resolve deterministically.
+	ids := make([]string, 0, len(merged))
+	for id := range merged {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	index := make(map[string]string)
+	for _, id := range ids {
+		for _, m := range merged[id] {
+			if _, taken := index[m.Model]; !taken {
+				index[m.Model] = id
+			}
+		}
+	}
+	t.merged = merged
+	t.memberIndex = index
+}
+
+// ErrModelFamilyNotFound is returned when no family has the requested canonical ID.
+var ErrModelFamilyNotFound = errors.New("model family not found")
+
+// CapabilityError reports that no member of a family can serve a request.
+type CapabilityError struct {
+	Family   string
+	Required Capability
+}
+
+func (e *CapabilityError) Error() string {
+	return fmt.Sprintf("no model in family %s supports the requested capabilities: %s", e.Family, e.Required)
+}
+
+// ResolveModelFamily returns the ordered members of the family identified by
+// canonicalID that support every capability in required. Members whose model is
+// not registered, or that declare no capabilities, are kept. A provider-prefixed
+// ID such as "kiro/claude-sonnet-4-5" resolves to that provider's members only.
+// The returned slice is a copy and safe to modify.
+func ResolveModelFamily(canonicalID string, required Capability) ([]FamilyMember, error) {
+	members, ok := lookupFamily(canonicalID)
+	if !ok {
+		provider, family, prefixed := strings.Cut(canonicalID, "/")
+		if prefixed {
+			members, _ = lookupFamily(family)
+			members = slices.DeleteFunc(members, func(m FamilyMember) bool { return !strings.EqualFold(m.Provider, provider) })
+		}
+		if len(members) == 0 {
+			return nil, ErrModelFamilyNotFound
+		}
+		canonicalID = family
+	}
+	if required == 0 {
+		return members, nil
+	}
+	r := GetGlobalRegistry()
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	out := members[:0]
+	for _, m := range members {
+		if r.memberSupportsLocked(m, required) {
+			out = append(out, m)
+		}
+	}
+	if len(out) == 0 {
+		return nil, &CapabilityError{Family: canonicalID, Required: required}
+	}
+	return out, nil
+}
+
+// memberSupportsLocked reports whether m can serve a request needing
+// required. Members whose model is not registered, or that declare no
+// capabilities, support everything. r.mutex must be held.
+func (r *ModelRegistry) memberSupportsLocked(m FamilyMember, required Capability) bool {
+	if required == 0 {
+		return true
+	}
+	reg, ok := r.models[m.Provider+":"+m.Model]
+	return !ok || reg == nil || reg.Info == nil || reg.Info.Capabilities.Supports(required)
+}
+
+// lookupFamily returns a copy of the members of canonicalID without
+// touching the model registry, so it is safe to call with the registry lock held.
+func lookupFamily(canonicalID string) ([]FamilyMember, bool) {
+	families.mu.RLock()
+	defer families.mu.RUnlock()
+	members, ok := families.merged[canonicalID]
+	if !ok {
+		return nil, false
+	}
+	return append([]FamilyMember(nil), members...), true
+}
+
+// GetCanonicalModelID returns the canonical ID of the family containing
+// modelID. Canonical IDs map to themselves; unknown IDs return "".
+func GetCanonicalModelID(modelID string) string {
+	families.mu.RLock()
+	defer families.mu.RUnlock()
+	if _, ok := families.merged[modelID]; ok {
+		return modelID
+	}
+	return families.memberIndex[modelID]
+}
+
+// IsCanonicalID reports whether id names a model family.
+func IsCanonicalID(id string) bool {
+	families.mu.RLock()
+	defer families.mu.RUnlock()
+	_, ok := families.merged[id]
+	return ok
+}
+
+// IsBuiltinModelFamily reports whether id is one of the compiled-in families.
+func IsBuiltinModelFamily(id string) bool {
+	if _, ok := EmbeddingModelFamilies[id]; ok {
+		return true
+	}
+	_, ok := ModelFamilies[id]
+	return ok
+}
+
+// IsEmbeddingModel reports whether modelID names an embedding model: a member
+// of a built-in embedding family, or any model whose ID mentions "embed".
+func IsEmbeddingModel(modelID string) bool {
+	if _, ok := EmbeddingModelFamilies[GetCanonicalModelID(modelID)]; ok {
+		return true
+	}
+	return strings.Contains(strings.ToLower(modelID), "embed")
+}
+
+// ListModelFamilies returns a copy of the effective family set.
+func ListModelFamilies() map[string][]FamilyMember {
+	families.mu.RLock()
+	defer families.mu.RUnlock()
+	out := make(map[string][]FamilyMember, len(families.merged))
+	for id, members := range families.merged {
+		out[id] = append([]FamilyMember(nil), members...)
+	}
+	return out
+}
+
+// CustomModelFamilies returns a copy of the families registered at runtime.
+func CustomModelFamilies() map[string][]FamilyMember {
+	families.mu.RLock()
+	defer families.mu.RUnlock()
+	out := make(map[string][]FamilyMember, len(families.custom))
+	for id, members := range families.custom {
+		out[id] = append([]FamilyMember(nil), members...)
+	}
+	return out
+}
+
+// SetConfigModelFamilies replaces the families defined in the config file.
+// Invalid entries are rejected as a whole so a bad edit keeps the previous set.
+func SetConfigModelFamilies(defs map[string][]FamilyMember) error {
+	configured := make(map[string][]FamilyMember, len(defs))
+	for id, members := range defs {
+		id, members, err := normalizeFamily(id, members)
+		if err != nil {
+			return err
+		}
+		configured[id] = members
+	}
+	families.mu.Lock()
+	defer families.mu.Unlock()
+	families.configured = configured
+	families.rebuild()
+	return nil
+}
+
+// RegisterModelFamily registers or overrides a family at runtime.
+func RegisterModelFamily(canonicalID string, members []FamilyMember) error {
+	canonicalID, members, err := normalizeFamily(canonicalID, members)
+	if err != nil {
+		return err
+	}
+	families.mu.Lock()
+	defer families.mu.Unlock()
+	families.custom[canonicalID] = members
+	families.rebuild()
+	return nil
+}
+
+// UnregisterModelFamily removes a runtime family. Any built-in or configured
+// family it overrode becomes effective again. Returns false if no custom family existed.
+func UnregisterModelFamily(canonicalID string) bool {
+	canonicalID = strings.TrimSpace(canonicalID)
+	families.mu.Lock()
+	defer families.mu.Unlock()
+	if _, ok := families.custom[canonicalID]; !ok {
+		return false
+	}
+	delete(families.custom, canonicalID)
+	families.rebuild()
+	return true
+}
+
+func normalizeFamily(canonicalID string, members []FamilyMember) (string, []FamilyMember, error) {
+	canonicalID = strings.TrimSpace(canonicalID)
+	if canonicalID == "" {
+		return "", nil, fmt.Errorf("canonical id is required")
+	}
+	if len(members) == 0 {
+		return "", nil, fmt.Errorf("family %s has no members", canonicalID)
+	}
+	out := make([]FamilyMember, 0, len(members))
+	seen := make(map[string]struct{}, len(members))
+	for i, m := range members {
+		p := strings.ToLower(strings.TrimSpace(m.Provider))
+		model := strings.TrimSpace(m.Model)
+		if p == "" || model == "" {
+			return "", nil, fmt.Errorf("family %s member %d: provider and model are required", canonicalID, i)
+		}
+		key := p + ":" + model
+		if _, dup := seen[key]; dup {
+			return "", nil, fmt.Errorf("family %s: duplicate member %s", canonicalID, key)
+		}
+		seen[key] = struct{}{}
+		out = append(out, FamilyMember{Provider: p, Model: model})
+	}
+	return canonicalID, out, nil
+}
+
+// ModelFamiliesPath returns the location of the runtime families file for the
+// given config file path. Without one it uses WRITABLE_PATH, then the llm-mux
+// config directory, rather than the working directory.
+func ModelFamiliesPath(configPath string) string {
+	if strings.TrimSpace(configPath) != "" {
+		return filepath.Join(filepath.Dir(configPath), ModelFamiliesFileName)
+	}
+	if dir := writablePath(); dir != "" {
+		return filepath.Join(dir, ModelFamiliesFileName)
+	}
+	if dir := config.CredentialsDir(); dir != "" {
+		return filepath.Join(dir, ModelFamiliesFileName)
+	}
+	return filepath.Join(os.TempDir(), "llm-mux", ModelFamiliesFileName)
+}
+
+func writablePath() string {
+	for _, key := range []string{"WRITABLE_PATH", "writable_path"} {
+		if value, ok := os.LookupEnv(key); ok {
+			if trimmed := strings.TrimSpace(value); trimmed != "" {
+				return filepath.Clean(trimmed)
+			}
+		}
+	}
+	return ""
+}
+
+// LoadModelFamilies replaces the runtime families with the content of path.
+// A missing file is not an error.
+func LoadModelFamilies(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("read model families: %w", err)
+	}
+	var raw map[string][]FamilyMember
+	if err = json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("parse model families: %w", err)
+	}
+	custom := make(map[string][]FamilyMember, len(raw))
+	for id, members := range raw {
+		id, members, err = normalizeFamily(id, members)
+		if err != nil {
+			return err
+		}
+		custom[id] = members
+	}
+	families.mu.Lock()
+	defer families.mu.Unlock()
+	families.custom = custom
+	families.rebuild()
+	return nil
+}
+
+// SaveModelFamilies writes the runtime families to path.
+func SaveModelFamilies(path string) error {
+	data, err := json.MarshalIndent(CustomModelFamilies(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return persist.WriteFile(path, data)
+}
@@ -0,0 +1,226 @@
+package registry
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func resetFamilies(t *testing.T) {
+	t.Helper()
+	families.mu.Lock()
+	families.configured = make(map[string][]FamilyMember)
+	families.custom = make(map[string][]FamilyMember)
+	families.rebuild()
+	families.mu.Unlock()
+}
+
+func TestRegisterModelFamilyOverridesBuiltin(t *testing.T) {
+	resetFamilies(t)
+	defer resetFamilies(t)
+
+	members := []FamilyMember{{Provider: "Kiro", Model: "claude-sonnet-4-5-20250929"}}
+	if err := RegisterModelFamily("claude-sonnet-4-5", members); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	got, err := ResolveModelFamily("claude-sonnet-4-5", 0)
+	if err != nil || len(got) != 1 || got[0].Provider != "kiro" {
+		t.Fatalf("expected override with single kiro member, got %+v", got)
+	}
+
+	if !UnregisterModelFamily("claude-sonnet-4-5") {
+		t.Fatal("expected custom family to be removed")
+	}
+	got, _ = ResolveModelFamily("claude-sonnet-4-5", 0)
+	if len(got) != len(ModelFamilies["claude-sonnet-4-5"]) {
+		t.Fatalf("expected built-in family restored, got %+v", got)
+	}
+	if UnregisterModelFamily("claude-sonnet-4-5") {
+		t.Fatal("built-in family must not be removable")
+	}
+}
+
+func TestConfigModelFamiliesPrecedence(t *testing.T) {
+	resetFamilies(t)
+	defer resetFamilies(t)
+
+	cfgDefs := map[string][]FamilyMember{
+		"claude-opus-4": {{Provider: "kiro", Model: "claude-opus-4-20250514"}},
+		"cfg-only":      {{Provider: "qwen", Model: "qwen3-coder-plus"}},
+	}
+	if err := SetConfigModelFamilies(cfgDefs); err != nil {
+		t.Fatalf("set config families: %v", err)
+	}
+
+	// Config overrides built-in.
+	got, _ := ResolveModelFamily("claude-opus-4", 0)
+	if len(got) != 1 || got[0].Provider != "kiro" {
+		t.Fatalf("expected config to override built-in, got %+v", got)
+	}
+	if !IsCanonicalID("cfg-only") {
+		t.Fatal("expected config-only family to be canonical")
+	}
+	// Untouched built-ins remain.
+	if !IsCanonicalID("claude-sonnet-4-5") {
+		t.Fatal("expected built-in family to remain")
+	}
+
+	// Runtime overrides config.
+	if err := RegisterModelFamily("cfg-only", []FamilyMember{{Provider: "iflow", Model: "qwen3-coder-plus"}}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	got, _ = ResolveModelFamily("cfg-only", 0)
+	if got[0].Provider != "iflow" {
+		t.Fatalf("expected runtime family to win, got %+v", got)
+	}
+	UnregisterModelFamily("cfg-only")
+	got, _ = ResolveModelFamily("cfg-only", 0)
+	if got[0].Provider != "qwen" {
+		t.Fatalf("expected config family after runtime removal, got %+v", got)
+	}
+
+	// Reload without the entry drops it and restores the built-in.
+	if err := SetConfigModelFamilies(nil); err != nil {
+		t.Fatalf("clear config families: %v", err)
+	}
+	if IsCanonicalID("cfg-only") {
+		t.Fatal("expected config-only family removed on reload")
+	}
+	got, _ = ResolveModelFamily("claude-opus-4", 0)
+	if len(got) != len(ModelFamilies["claude-opus-4"]) {
+		t.Fatalf("expected built-in restored, got %+v", got)
+	}
+}
+
+func TestConfigModelFamiliesInvalidKeepsPrevious(t *testing.T) {
+	resetFamilies(t)
+	defer resetFamilies(t)
+
+	if err := SetConfigModelFamilies(map[string][]FamilyMember{"good": {{Provider: "claude", Model: "m"}}}); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	bad := map[string][]FamilyMember{"bad": {{Provider: "", Model: "m"}}}
+	if err := SetConfigModelFamilies(bad); err == nil {
+		t.Fatal("expected error for invalid member")
+	}
+	if !IsCanonicalID("good") || IsCanonicalID("bad") {
+		t.Fatal("expected previous config families to stay active")
+	}
+}
+
+func TestRegisterModelFamilyValidation(t *testing.T) {
+	resetFamilies(t)
+	defer resetFamilies(t)
+
+	if err := RegisterModelFamily("", []FamilyMember{{Provider: "claude", Model: "x"}}); err == nil {
+		t.Error("expected error for empty canonical id")
+	}
+	if err := RegisterModelFamily("fam", nil); err == nil {
+		t.Error("expected error for empty members")
+	}
+	dup := []FamilyMember{{Provider: "claude", Model: "x"}, {Provider: "claude", Model: "x"}}
+	if err := RegisterModelFamily("fam", dup); err == nil {
+		t.Error("expected error for duplicate members")
+	}
+}
+
+func TestCanonicalLookup(t *testing.T) {
+	resetFamilies(t)
+	defer resetFamilies(t)
+
+	if err := RegisterModelFamily("my-model", []FamilyMember{{Provider: "qwen", Model: "qwen3-coder-plus"}}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if !IsCanonicalID("my-model") {
+		t.Error("expected my-model to be canonical")
+	}
+	if got := GetCanonicalModelID("qwen3-coder-plus"); got != "my-model" {
+		t.Errorf("GetCanonicalModelID = %q, want my-model", got)
+	}
+	if got := GetCanonicalModelID("unknown"); got != "" {
+		t.Errorf("GetCanonicalModelID(unknown) = %q, want empty", got)
+	}
+}
+
+func TestSaveLoadModelFamilies(t *testing.T) {
+	resetFamilies(t)
+	defer resetFamilies(t)
+
+	path := filepath.Join(t.TempDir(), ModelFamiliesFileName)
+	if err := RegisterModelFamily("persisted", []FamilyMember{{Provider: "claude", Model: "claude-opus-4-5-20251101"}}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if err := SaveModelFamilies(path); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	resetFamilies(t)
+	if IsCanonicalID("persisted") {
+		t.Fatal("expected family to be cleared")
+	}
+	if err := LoadModelFamilies(path); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if !IsCanonicalID("persisted") {
+		t.Fatal("expected family to be loaded from disk")
+	}
+}
+
+func TestModelFamiliesPath(t *testing.T) {
+	if got, want := ModelFamiliesPath("/etc/llm-mux/config.yaml"), filepath.Join("/etc/llm-mux", ModelFamiliesFileName); got != want {
+		t.Errorf("with config: %s, want %s", got, want)
+	}
+	writable := t.TempDir()
+	t.Setenv("WRITABLE_PATH", writable)
+	if got, want := ModelFamiliesPath(""), filepath.Join(writable, ModelFamiliesFileName); got != want {
+		t.Errorf("with WRITABLE_PATH: %s, want %s", got, want)
+	}
+	t.Setenv("WRITABLE_PATH", "")
+	xdg := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	if got, want := ModelFamiliesPath(""), filepath.Join(xdg, "llm-mux", ModelFamiliesFileName); got != want {
+		t.Errorf("without config: %s, want %s", got, want)
+	}
+}
+
+func TestModelFamiliesConcurrentAccess(t *testing.T) {
+	resetFamilies(t)
+	defer resetFamilies(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				_ = RegisterModelFamily("race", []FamilyMember{{Provider: "claude", Model: "m"}})
+				UnregisterModelFamily("race")
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				ResolveModelFamily("race", 0)
+				GetCanonicalModelID("m")
+				IsCanonicalID("claude-opus-4-5")
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestIsEmbeddingModel(t *testing.T) {
+	resetFamilies(t)
+	defer resetFamilies(t)
+
+	for _, id := range []string{"gemini-embedding-001", "text-embedding-3-small", "nomic-embed-text"} {
+		if !IsEmbeddingModel(id) {
+			t.Errorf("IsEmbeddingModel(%q) = false", id)
+		}
+	}
+	if IsEmbeddingModel("gemini-2.5-flash") {
+		t.Error("chat model classified as embedding model")
+	}
+	if !IsCanonicalID("gemini-embedding-001") || !IsBuiltinModelFamily("gemini-embedding-001") {
+		t.Error("embedding family not in the built-in family table")
+	}
+}
@@ -4,6 +4,7 @@
 package registry
 
 import (
+	"context"
 	"fmt"
 	"sort"
 	"strings"
@@ -45,6 +46,41 @@ type ModelInfo struct {
 
 	// Hidden marks the model as excluded from model listings.
 	Hidden bool `json:"-"`
+
+	// Capabilities lists the request features the model can serve.
+	// Used to route requests within a model family.
+	Capabilities Capability `json:"-"`
+
+	// Betas lists the anthropic-beta flags the upstream requires for this
+	// model, such as the 1M context window flag.
+	Betas []string `json:"-"`
+
+	// Category separates non-chat models, such as ModelCategoryRerank, from
+	// chat models. Empty means a chat model.
+	Category string `json:"-"`
+
+	// DiscoveredAt is when the upstream last listed the model; it is zero for
+	// built-in and configured models. Discovered models expire, see
+	// SetModelExpiry.
+	DiscoveredAt time.Time `json:"-"`
+}
+
+// ModelCategoryRerank marks models served only through /v1/rerank. They are
+// left out of model listings.
+const ModelCategoryRerank = "rerank"
+
+// MaxOutputTokens returns the most tokens the model can generate in one
+// response, or 0 when unknown. When both OutputTokenLimit and
+// MaxCompletionTokens are set the smaller one wins.
+func (m *ModelInfo) MaxOutputTokens() int {
+	if m == nil {
+		return 0
+	}
+	limit := m.OutputTokenLimit
+	if m.MaxCompletionTokens > 0 && (limit == 0 || m.MaxCompletionTokens < limit) {
+		limit = m.MaxCompletionTokens
+	}
+	return limit
 }
 
 // ThinkingSupport describes a model's supported internal reasoning budget range.
@@ -89,6 +125,13 @@ type ModelRegistry struct {
 	mutex *sync.RWMutex
 	// showProviderPrefixes controls whether to add visual provider prefixes to model IDs
 	showProviderPrefixes bool
+	// discoveredSeen maps client ID to when each of its discovered models was last listed
+	discoveredSeen map[string]map[string]time.Time
+	// modelTTL and modelGrace control when discovered models expire
+	modelTTL   time.Duration
+	modelGrace time.Duration
+	// refresh re-registers every client's models, see SetRefreshHandler
+	refresh func(context.Context)
 }
 
 // Global model registry instance
@@ -98,19 +141,25 @@ var registryOnce sync.Once
 // GetGlobalRegistry returns the global model registry instance
 func GetGlobalRegistry() *ModelRegistry {
 	registryOnce.Do(func() {
-		globalRegistry = &ModelRegistry{
-			models:               make(map[string]*ModelRegistration),
-			clientModels:         make(map[string][]string),
-			clientProviders:      make(map[string]string),
-			canonicalIndex:       make(map[string][]ProviderModelMapping),
-			modelIDIndex:         make(map[string][]string),
-			mutex:                &sync.RWMutex{},
-			showProviderPrefixes: false,
-		}
+		globalRegistry = newModelRegistry()
 	})
 	return globalRegistry
 }
 
+// newModelRegistry returns an empty registry.
+func newModelRegistry() *ModelRegistry {
+	return &ModelRegistry{
+		models:               make(map[string]*ModelRegistration),
+		clientModels:         make(map[string][]string),
+		clientProviders:      make(map[string]string),
+		canonicalIndex:       make(map[string][]ProviderModelMapping),
+		modelIDIndex:         make(map[string][]string),
+		discoveredSeen:       make(map[string]map[string]time.Time),
+		mutex:                &sync.RWMutex{},
+		showProviderPrefixes: false,
+	}
+}
+
 // SetShowProviderPrefixes configures whether to display provider prefixes in model IDs.
 // When enabled, model IDs will include visual prefixes like "[Gemini CLI] gemini-2.5-pro".
 // This is purely cosmetic and does not affect model routing.
@@ -131,8 +180,13 @@ func (r *ModelRegistry) SetShowProviderPrefixes(enabled bool) {
 func (r *ModelRegistry) RegisterClient(clientID, clientProvider string, models []*ModelInfo) {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
+	r.registerClientInternal(clientID, clientProvider, models)
+}
 
+// registerClientInternal performs the actual client registration (internal, no locking)
+func (r *ModelRegistry) registerClientInternal(clientID, clientProvider string, models []*ModelInfo) {
 	provider := strings.ToLower(clientProvider)
+	models = r.applyModelExpiry(clientID, provider, models, time.Now())
 	uniqueModelIDs := make([]string, 0, len(models))
 	rawModelIDs := make([]string, 0, len(models))
 	newModels := make(map[string]*ModelInfo, len(models))
@@ -506,6 +560,10 @@ func (r *ModelRegistry) GetProvidersWithModelID(modelID string) []ProviderModelM
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
+	if mappings := r.familyMappings(modelID); len(mappings) > 0 {
+		return mappings
+	}
+
 	// Check canonical index (returns provider-specific model IDs)
 	if mappings, ok := r.canonicalIndex[modelID]; ok && len(mappings) > 0 {
 		result := make([]ProviderModelMapping, 0, len(mappings))
@@ -532,9 +590,34 @@ func (r *ModelRegistry) GetProvidersWithModelID(modelID string) []ProviderModelM
 	return result
 }
 
+// familyMappings returns the registered members of the model family named by
+// modelID in family order. Must be called with mutex held.
+func (r *ModelRegistry) familyMappings(modelID string) []ProviderModelMapping {
+	members, ok := lookupFamily(modelID)
+	if !ok {
+		return nil
+	}
+	var result []ProviderModelMapping
+	for i, m := range members {
+		key := m.Provider + ":" + m.Model
+		if reg, ok := r.models[key]; ok && reg != nil && reg.Count > 0 {
+			result = append(result, ProviderModelMapping{
+				Provider: m.Provider,
+				ModelID:  m.Model,
+				Priority: i + 1,
+			})
+		}
+	}
+	return result
+}
+
 // findModelRegistration finds a model registration using canonical index or direct lookup.
 // Must be called with mutex held.
 func (r *ModelRegistry) findModelRegistration(modelID string) *ModelRegistration {
+	if mappings := r.familyMappings(modelID); len(mappings) > 0 {
+		return r.models[mappings[0].Provider+":"+mappings[0].ModelID]
+	}
+
 	// Check canonical index first - get first available provider's registration
 	if mappings, ok := r.canonicalIndex[modelID]; ok && len(mappings) > 0 {
 		for _, m := range mappings {
@@ -589,11 +672,31 @@ func (r *ModelRegistry) getModelProvidersInternal(modelID string) []string {
 }
 
 // GetModelIDForProvider translates a canonical model ID to provider-specific ID.
+// Of a family's members on provider, the first supporting every capability in
+// required is chosen, or the first member when none does.
 // Returns the original modelID if no translation is found.
-func (r *ModelRegistry) GetModelIDForProvider(modelID, provider string) string {
+func (r *ModelRegistry) GetModelIDForProvider(modelID, provider string, required Capability) string {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
+	if members, ok := lookupFamily(modelID); ok {
+		first := ""
+		for _, m := range members {
+			if m.Provider != provider {
+				continue
+			}
+			if r.memberSupportsLocked(m, required) {
+				return m.Model
+			}
+			if first == "" {
+				first = m.Model
+			}
+		}
+		if first != "" {
+			return first
+		}
+	}
+
 	// Check canonical index for translation
 	if mappings, ok := r.canonicalIndex[modelID]; ok {
 		for _, m := range mappings {
@@ -690,6 +793,7 @@ func (r *ModelRegistry) unregisterClientInternal(clientID string) {
 	}
 
 	delete(r.clientModels, clientID)
+	delete(r.discoveredSeen, clientID)
 	if hasProvider {
 		delete(r.clientProviders, clientID)
 	}
@@ -895,7 +999,7 @@ func (r *ModelRegistry) GetAvailableModels(handlerType string) []map[string]any
 	models := make([]map[string]any, 0, len(aggregated))
 
 	for _, agg := range aggregated {
-		if !agg.isAvailable {
+		if !agg.isAvailable || agg.info.Category == ModelCategoryRerank {
 			continue
 		}
 
@@ -926,6 +1030,14 @@ func (r *ModelRegistry) GetAvailableModels(handlerType string) []map[string]any
 	return models
 }
 
+// IsRerankModel reports whether modelID is registered as a rerank model.
+func (r *ModelRegistry) IsRerankModel(modelID string) bool {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	reg := r.findModelRegistration(modelID)
+	return reg != nil && reg.Info != nil && reg.Info.Category == ModelCategoryRerank
+}
+
 // GetModelCount returns the number of available clients for a specific model
 // Parameters:
 //   - modelID: The model ID to check
@@ -967,6 +1079,19 @@ func (r *ModelRegistry) GetModelProviders(modelID string) []string {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
+	if mappings := r.familyMappings(modelID); len(mappings) > 0 {
+		result := make([]string, 0, len(mappings))
+		seen := make(map[string]struct{}, len(mappings))
+		for _, m := range mappings {
+			if _, dup := seen[m.Provider]; dup {
+				continue
+			}
+			seen[m.Provider] = struct{}{}
+			result = append(result, m.Provider)
+		}
+		return result
+	}
+
 	if mappings, ok := r.canonicalIndex[modelID]; ok && len(mappings) > 0 {
 		type providerWithPriority struct {
 			provider string
@@ -0,0 +1,31 @@
+package registry
+
+import "testing"
+
+func TestRerankModelsLeftOutOfListings(t *testing.T) {
+	r := GetGlobalRegistry()
+	r.RegisterClient("rerank-test-1", "cohere", []*ModelInfo{
+		{ID: "rerank-test-model", Category: ModelCategoryRerank},
+		{ID: "rerank-test-chat"},
+	})
+	defer r.UnregisterClient("rerank-test-1")
+
+	if !r.IsRerankModel("rerank-test-model") {
+		t.Error("IsRerankModel(rerank-test-model) = false")
+	}
+	if r.IsRerankModel("rerank-test-chat") {
+		t.Error("chat model classified as rerank model")
+	}
+	var sawChat bool
+	for _, m := range r.GetAvailableModels("openai") {
+		switch m["id"] {
+		case "rerank-test-model":
+			t.Error("rerank model listed in /v1/models")
+		case "rerank-test-chat":
+			sawChat = true
+		}
+	}
+	if !sawChat {
+		t.Error("chat model from the same client missing from listing")
+	}
+}
@@ -0,0 +1,46 @@
+package registry
+
+import (
+	"slices"
+	"strings"
+)
+
+// PrefixModelID returns modelID in the provider-prefixed form that pins it to
+// provider, such as "claude/claude-sonnet-4-5".
+func PrefixModelID(provider, modelID string) string {
+	return provider + "/" + modelID
+}
+
+// SplitProviderPrefix splits a provider-prefixed model ID into the provider
+// and the model it pins. It only splits when the prefix names a provider with
+// registered clients and the whole ID is not itself a registered model, so
+// upstream IDs such as "openai/gpt-4o" from an OpenRouter-style provider keep
+// routing as before.
+func (r *ModelRegistry) SplitProviderPrefix(modelID string) (provider, model string, ok bool) {
+	prefix, model, ok := strings.Cut(modelID, "/")
+	if !ok || prefix == "" || model == "" {
+		return "", "", false
+	}
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	if len(r.getModelProvidersInternal(modelID)) > 0 {
+		return "", "", false
+	}
+	for _, p := range r.clientProviders {
+		if strings.EqualFold(p, prefix) {
+			return p, model, true
+		}
+	}
+	return "", "", false
+}
+
+// ModelHosts returns, sorted, the providers with an available client
+// registered for exactly modelID. Unlike GetModelProviders, family IDs are
+// not expanded to their members.
+func (r *ModelRegistry) ModelHosts(modelID string) []string {
+	r.mutex.RLock()
+	providers := r.getModelProvidersInternal(modelID)
+	r.mutex.RUnlock()
+	slices.Sort(providers)
+	return slices.Compact(providers)
+}
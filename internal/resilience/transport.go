@@ -155,7 +155,7 @@ func (c *TransportCache) GetOrCreate(proxyURLStr string) (*http.Transport, error
 
 	var transport *http.Transport
 	switch proxyURL.Scheme {
-	case "socks5":
+	case "socks5", "socks5h":
 		var proxyAuth *proxy.Auth
 		if proxyURL.User != nil {
 			username := proxyURL.User.Username()
@@ -43,7 +43,7 @@ func (e *AIStudioExecutor) Execute(ctx context.Context, auth *provider.Auth, req
 	if err != nil {
 		return resp, err
 	}
-	endpoint := e.buildEndpoint(req.Model, body.action, opts.Alt)
+	endpoint := e.buildEndpoint(auth, req.Model, body.action, opts.Alt)
 	wsReq := &wsrelay.HTTPRequest{
 		Method:  http.MethodPost,
 		URL:     endpoint,
@@ -87,7 +87,7 @@ func (e *AIStudioExecutor) ExecuteStream(ctx context.Context, auth *provider.Aut
 		return nil, err
 	}
 
-	endpoint := e.buildEndpoint(req.Model, body.action, opts.Alt)
+	endpoint := e.buildEndpoint(auth, req.Model, body.action, opts.Alt)
 	wsReq := &wsrelay.HTTPRequest{
 		Method:  http.MethodPost,
 		URL:     endpoint,
@@ -136,6 +136,7 @@ func (e *AIStudioExecutor) ExecuteStream(ctx context.Context, auth *provider.Aut
 
 	go func(first wsrelay.StreamEvent, inputTokens int64) {
 		defer close(out)
+		defer reporter.flush(ctx, false)
 		defer func() {
 			if r := recover(); r != nil {
 				log.Errorf("aistudio executor: panic in stream goroutine: %v", r)
@@ -143,6 +144,7 @@ func (e *AIStudioExecutor) ExecuteStream(ctx context.Context, auth *provider.Aut
 		}()
 
 		streamCtx := NewStreamContext()
+		streamCtx.RequestUsage(opts.OriginalRequest)
 		streamCtx.EstimatedInputTokens = inputTokens
 		messageID := "chatcmpl-" + req.Model
 		translator := NewStreamTranslator(e.cfg, opts.SourceFormat, opts.SourceFormat.String(), req.Model, messageID, streamCtx)
@@ -174,7 +176,7 @@ func (e *AIStudioExecutor) ExecuteStream(ctx context.Context, auth *provider.Aut
 						return false
 					}
 					if usage != nil {
-						reporter.publish(ctx, usage)
+						reporter.observe(usage)
 					}
 					for _, chunk := range chunks {
 						select {
@@ -259,7 +261,7 @@ func (e *AIStudioExecutor) CountTokens(ctx context.Context, auth *provider.Auth,
 	body.payload, _ = sjson.DeleteBytes(body.payload, "tools")
 	body.payload, _ = sjson.DeleteBytes(body.payload, "safetySettings")
 
-	endpoint := e.buildEndpoint(req.Model, "countTokens", "")
+	endpoint := e.buildEndpoint(auth, req.Model, "countTokens", "")
 	wsReq := &wsrelay.HTTPRequest{
 		Method:  http.MethodPost,
 		URL:     endpoint,
@@ -388,11 +390,11 @@ func (e *AIStudioExecutor) translateRequestWithTokens(req provider.Request, opts
 	return translatedPayload{payload: payload, action: action, toFormat: formatGemini}, translation.EstimatedInputTokens, nil
 }
 
-func (e *AIStudioExecutor) buildEndpoint(model, action, alt string) string {
+func (e *AIStudioExecutor) buildEndpoint(auth *provider.Auth, model, action, alt string) string {
 	ub := GetURLBuilder()
 	defer ub.Release()
 	ub.Grow(128)
-	ub.WriteString(GeminiDefaultBaseURL)
+	ub.WriteString(resolveGeminiBaseURL(auth))
 	ub.WriteString("/")
 	ub.WriteString(GeminiGLAPIVersion)
 	ub.WriteString("/models/")
@@ -467,7 +469,7 @@ func FetchAIStudioModels(ctx context.Context, auth *provider.Auth, relay *wsrela
 		authID = auth.ID
 	}
 
-	modelsURL := GeminiDefaultBaseURL + glAPIModelsPath
+	modelsURL := resolveGeminiBaseURL(auth) + glAPIModelsPath
 	wsReq := &wsrelay.HTTPRequest{
 		Method:  http.MethodGet,
 		URL:     modelsURL,
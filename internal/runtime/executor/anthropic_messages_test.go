@@ -0,0 +1,167 @@
+package executor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/translator/to_ir"
+	"github.com/tidwall/gjson"
+)
+
+// claudeSSEEvent is one parsed event of an Anthropic Messages stream.
+type claudeSSEEvent struct {
+	name string
+	data gjson.Result
+}
+
+// claudeEventsFromOpenAI runs OpenAI stream chunks through a StreamTranslator
+// for a Messages API client and returns the emitted events.
+func claudeEventsFromOpenAI(t *testing.T, chunks []string) []claudeSSEEvent {
+	t.Helper()
+	st := NewStreamTranslator(nil, provider.FromString("claude"), "claude", "gpt-4o", "msg_1", NewStreamContext())
+	var sse strings.Builder
+	for _, chunk := range chunks {
+		events, err := to_ir.ParseOpenAIChunk([]byte(chunk))
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := st.Translate(events)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, c := range result.Chunks {
+			sse.Write(c)
+		}
+	}
+	for _, c := range st.Flush() {
+		sse.Write(c)
+	}
+	var out []claudeSSEEvent
+	for _, block := range strings.Split(strings.TrimSpace(sse.String()), "\n\n") {
+		name, data, ok := strings.Cut(block, "\ndata: ")
+		if !ok {
+			t.Fatalf("malformed SSE event %q", block)
+		}
+		ev := claudeSSEEvent{name: strings.TrimPrefix(name, "event: "), data: gjson.Parse(data)}
+		if ev.data.Get("type").String() != ev.name {
+			t.Errorf("event %q carries type %q", ev.name, ev.data.Get("type").String())
+		}
+		out = append(out, ev)
+	}
+	return out
+}
+
+func TestMessagesStream_FromOpenAIUpstream(t *testing.T) {
+	events := claudeEventsFromOpenAI(t, []string{
+		`data: {"id":"c","choices":[{"index":0,"delta":{"role":"assistant","content":"Let me "}}]}`,
+		`data: {"id":"c","choices":[{"index":0,"delta":{"content":"check."}}]}`,
+		`data: {"id":"c","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"get_weather","arguments":""}}]}}]}`,
+		`data: {"id":"c","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"city\":"}}]}}]}`,
+		`data: {"id":"c","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"Paris\"}"}}]}}]}`,
+		`data: {"id":"c","choices":[{"index":0,"delta":{},"finish_reason":"tool_calls"}],"usage":{"prompt_tokens":21,"completion_tokens":9,"total_tokens":30}}`,
+		`data: [DONE]`,
+	})
+
+	var names []string
+	for _, ev := range events {
+		names = append(names, ev.name)
+	}
+	want := []string{
+		"message_start",
+		"content_block_start", "content_block_delta", "content_block_delta", "content_block_stop",
+		"content_block_start", "content_block_delta", "content_block_delta", "content_block_stop",
+		"message_delta", "message_stop",
+	}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Fatalf("events:\n got %v\nwant %v", names, want)
+	}
+
+	if got := events[0].data.Get("message.role").String(); got != "assistant" {
+		t.Errorf("message_start role = %q", got)
+	}
+	if got := events[1].data.Get("content_block.type").String(); got != "text" {
+		t.Errorf("first block = %q, want text", got)
+	}
+	tool := events[5].data
+	if tool.Get("index").Int() != 1 || tool.Get("content_block.type").String() != "tool_use" || tool.Get("content_block.name").String() != "get_weather" {
+		t.Errorf("tool block start = %s", tool.Raw)
+	}
+	var args string
+	for _, ev := range events[6:8] {
+		if ev.data.Get("index").Int() != 1 || ev.data.Get("delta.type").String() != "input_json_delta" {
+			t.Errorf("tool delta = %s", ev.data.Raw)
+		}
+		args += ev.data.Get("delta.partial_json").String()
+	}
+	if args != `{"city":"Paris"}` {
+		t.Errorf("tool input = %q", args)
+	}
+
+	delta := events[9].data
+	if got := delta.Get("delta.stop_reason").String(); got != "tool_use" {
+		t.Errorf("stop_reason = %q, want tool_use", got)
+	}
+	if in, o := delta.Get("usage.input_tokens").Int(), delta.Get("usage.output_tokens").Int(); in != 21 || o != 9 {
+		t.Errorf("usage = %d/%d, want 21/9", in, o)
+	}
+}
+
+func TestMessagesStream_TextOnlyMaxTokens(t *testing.T) {
+	events := claudeEventsFromOpenAI(t, []string{
+		`data: {"id":"c","choices":[{"index":0,"delta":{"content":"Once upon"}}]}`,
+		`data: {"id":"c","choices":[{"index":0,"delta":{},"finish_reason":"length"}]}`,
+		`data: {"id":"c","choices":[],"usage":{"prompt_tokens":5,"completion_tokens":2,"total_tokens":7}}`,
+		`data: [DONE]`,
+	})
+	var text, stop string
+	for _, ev := range events {
+		switch ev.name {
+		case "content_block_delta":
+			text += ev.data.Get("delta.text").String()
+		case "message_delta":
+			stop = ev.data.Get("delta.stop_reason").String()
+		}
+	}
+	if text != "Once upon" {
+		t.Errorf("text = %q", text)
+	}
+	if stop != "max_tokens" {
+		t.Errorf("stop_reason = %q, want max_tokens", stop)
+	}
+	if last := events[len(events)-1].name; last != "message_stop" {
+		t.Errorf("last event = %q, want message_stop", last)
+	}
+}
+
+func TestMessagesAPI_RoundTripThroughOpenAI(t *testing.T) {
+	payload := `{"model":"gpt-4o","max_tokens":128,"system":"Be brief.","messages":[{"role":"user","content":[{"type":"text","text":"Weather in Paris?"}]}],"tools":[{"name":"get_weather","description":"Look up weather","input_schema":{"type":"object","properties":{"city":{"type":"string"}}}}]}`
+	body, err := TranslateToOpenAI(nil, provider.FromString("claude"), "gpt-4o", []byte(payload), false, nil)
+	if err != nil {
+		t.Fatalf("TranslateToOpenAI: %v", err)
+	}
+	req := gjson.ParseBytes(body)
+	if req.Get("messages.0.role").String() != "system" || req.Get("messages.1.role").String() != "user" {
+		t.Errorf("messages = %s", req.Get("messages").Raw)
+	}
+	if got := req.Get("tools.0.function.name").String(); got != "get_weather" {
+		t.Errorf("tool = %q", got)
+	}
+
+	upstream := `{"id":"chatcmpl-1","object":"chat.completion","model":"gpt-4o","choices":[{"index":0,"message":{"role":"assistant","content":null,"tool_calls":[{"id":"call_1","type":"function","function":{"name":"get_weather","arguments":"{\"city\":\"Paris\"}"}}]},"finish_reason":"tool_calls"}],"usage":{"prompt_tokens":40,"completion_tokens":15,"total_tokens":55}}`
+	out, err := TranslateResponseNonStream(nil, provider.FromString("openai"), provider.FromString("claude"), []byte(upstream), "gpt-4o")
+	if err != nil {
+		t.Fatalf("TranslateResponseNonStream: %v", err)
+	}
+	resp := gjson.ParseBytes(out)
+	if resp.Get("type").String() != "message" || resp.Get("stop_reason").String() != "tool_use" {
+		t.Errorf("response = %s", out)
+	}
+	block := resp.Get(`content.#(type=="tool_use")`)
+	if block.Get("name").String() != "get_weather" || block.Get("input.city").String() != "Paris" {
+		t.Errorf("tool_use = %s", block.Raw)
+	}
+	if in, o := resp.Get("usage.input_tokens").Int(), resp.Get("usage.output_tokens").Int(); in != 40 || o != 15 {
+		t.Errorf("usage = %d/%d, want 40/15", in, o)
+	}
+}
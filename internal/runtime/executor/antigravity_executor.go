@@ -53,6 +53,7 @@ func (e *AntigravityExecutor) Identifier() string { return antigravityAuthType }
 func (e *AntigravityExecutor) PrepareRequest(_ *http.Request, _ *provider.Auth) error { return nil }
 
 func (e *AntigravityExecutor) Execute(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (resp provider.Response, err error) {
+	req.Model = thinkingModelFor(e.Identifier(), req.Model, req.Payload)
 	token, updatedAuth, errToken := e.ensureAccessToken(ctx, auth)
 	if errToken != nil {
 		return resp, errToken
@@ -170,6 +171,7 @@ func (e *AntigravityExecutor) Execute(ctx context.Context, auth *provider.Auth,
 }
 
 func (e *AntigravityExecutor) ExecuteStream(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (stream <-chan provider.StreamChunk, err error) {
+	req.Model = thinkingModelFor(e.Identifier(), req.Model, req.Payload)
 	ctx = context.WithValue(ctx, altContextKey{}, "")
 
 	token, updatedAuth, errToken := e.ensureAccessToken(ctx, auth)
@@ -267,6 +269,7 @@ func (e *AntigravityExecutor) ExecuteStream(ctx context.Context, auth *provider.
 		}
 
 		streamCtx := NewStreamContextWithTools(opts.OriginalRequest)
+		streamCtx.RequestUsage(opts.OriginalRequest)
 		streamCtx.EstimatedInputTokens = estimatedInputTokens
 		messageID := "chatcmpl-" + req.Model
 
@@ -516,15 +519,7 @@ func buildBaseURL(auth *provider.Auth) string {
 }
 
 func resolveUserAgent(auth *provider.Auth) string {
-	if auth != nil {
-		if ua := AttrStringValue(auth.Attributes, "user_agent"); ua != "" {
-			return ua
-		}
-		if ua := MetaStringValue(auth.Metadata, "user_agent"); ua != "" {
-			return ua
-		}
-	}
-	return DefaultAntigravityUserAgent
+	return accountUserAgent(auth, DefaultAntigravityUserAgent)
 }
 
 func antigravityBaseURLFallbackOrder(auth *provider.Auth) []string {
@@ -541,10 +536,10 @@ func resolveCustomAntigravityBaseURL(auth *provider.Auth) string {
 	if auth == nil {
 		return ""
 	}
-	if v := AttrStringValue(auth.Attributes, "base_url"); v != "" {
-		return strings.TrimSuffix(v, "/")
+	if v := BaseURLOverride(auth); v != "" {
+		return v
 	}
-	if v := MetaStringValue(auth.Metadata, "base_url"); v != "" {
+	if v := MetaStringValue(auth.Metadata, "base_url"); v != "" && config.ValidateBaseURL(v) == nil {
 		return strings.TrimSuffix(v, "/")
 	}
 	return ""
@@ -17,7 +17,16 @@ func (b *BaseExecutor) Config() *config.Config {
 	return b.Cfg
 }
 
-func (b *BaseExecutor) PrepareRequest(_ *http.Request, _ *provider.Auth) error {
+func (b *BaseExecutor) PrepareRequest(req *http.Request, auth *provider.Auth) error {
+	if req == nil {
+		return nil
+	}
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", accountUserAgent(auth, DefaultUserAgent()))
+	}
+	for k, v := range upstreamHeaders(b.Cfg, auth) {
+		req.Header.Set(k, v)
+	}
 	return nil
 }
 
@@ -0,0 +1,60 @@
+package executor
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/registry"
+	"github.com/tidwall/gjson"
+)
+
+func TestClaudeExecutor_ModelBetas(t *testing.T) {
+	reg := registry.GetGlobalRegistry()
+	reg.RegisterClient("betas-test", "claude", registry.GetClaudeModels())
+	defer reg.UnregisterClient("betas-test")
+
+	var betas, upstreamModel string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		betas = r.Header.Get("Anthropic-Beta")
+		body, _ := io.ReadAll(r.Body)
+		upstreamModel = gjson.GetBytes(body, "model").String()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"msg_1","type":"message","role":"assistant","content":[{"type":"text","text":"hi"}],"model":"claude","stop_reason":"end_turn","usage":{"input_tokens":1,"output_tokens":1}}`))
+	}))
+	defer srv.Close()
+
+	exec := NewClaudeExecutor(&config.Config{})
+	auth := &provider.Auth{Provider: "claude", Attributes: map[string]string{"api_key": "k", "base_url": srv.URL}}
+
+	tests := []struct {
+		model    string
+		upstream string
+		wantBeta bool
+	}{
+		{"claude-sonnet-4-5-1m", "claude-sonnet-4-5-20250929", true},
+		{"claude-haiku-4-5-20251001", "claude-haiku-4-5-20251001", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			payload := []byte(`{"model":"` + tt.model + `","max_tokens":16,"messages":[{"role":"user","content":"hi"}]}`)
+			_, err := exec.Execute(context.Background(), auth,
+				provider.Request{Model: tt.model, Payload: payload},
+				provider.Options{SourceFormat: provider.FromString("claude")})
+			if err != nil {
+				t.Fatalf("execute: %v", err)
+			}
+			if got := strings.Contains(betas, "context-1m-2025-08-07"); got != tt.wantBeta {
+				t.Errorf("anthropic-beta = %q, context-1m present = %v, want %v", betas, got, tt.wantBeta)
+			}
+			if upstreamModel != tt.upstream {
+				t.Errorf("upstream model = %q, want %q", upstreamModel, tt.upstream)
+			}
+		})
+	}
+}
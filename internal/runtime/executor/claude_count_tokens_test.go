@@ -0,0 +1,92 @@
+package executor
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/tidwall/gjson"
+)
+
+func TestClaudeExecutor_CountTokensNative(t *testing.T) {
+	var path string
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		body, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"input_tokens":1234}`))
+	}))
+	defer srv.Close()
+
+	exec := NewClaudeExecutor(&config.Config{})
+	auth := &provider.Auth{Provider: "claude", Attributes: map[string]string{"api_key": "k", "base_url": srv.URL}}
+
+	tests := []struct {
+		from    string
+		payload string
+		path    string
+	}{
+		{"claude", `{"model":"claude-sonnet-4-5","max_tokens":1024,"stream":true,"messages":[{"role":"user","content":"hi"}]}`, "input_tokens"},
+		{"openai", `{"model":"claude-sonnet-4-5","max_tokens":1024,"messages":[{"role":"user","content":"hi"}]}`, "usage.prompt_tokens"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.from, func(t *testing.T) {
+			resp, err := exec.CountTokens(context.Background(), auth,
+				provider.Request{Model: "claude-sonnet-4-5", Payload: []byte(tt.payload)},
+				provider.Options{SourceFormat: provider.FromString(tt.from)})
+			if err != nil {
+				t.Fatalf("count tokens: %v", err)
+			}
+			if path != "/v1/messages/count_tokens" {
+				t.Errorf("path = %q", path)
+			}
+			for _, field := range []string{"max_tokens", "stream"} {
+				if gjson.GetBytes(body, field).Exists() {
+					t.Errorf("count_tokens body carries %s: %s", field, body)
+				}
+			}
+			if !gjson.GetBytes(body, "messages").IsArray() {
+				t.Errorf("count_tokens body has no messages: %s", body)
+			}
+			if got := gjson.GetBytes(resp.Payload, tt.path).Int(); got != 1234 {
+				t.Errorf("%s = %d, want 1234 (payload %s)", tt.path, got, resp.Payload)
+			}
+		})
+	}
+}
+
+func TestClaudeExecutor_CountTokensFallback(t *testing.T) {
+	status := http.StatusNotFound
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"type":"error","error":{"type":"not_found_error","message":"Not Found"}}`))
+	}))
+	defer srv.Close()
+
+	exec := NewClaudeExecutor(&config.Config{})
+	auth := &provider.Auth{Provider: "claude", Attributes: map[string]string{"api_key": "k", "base_url": srv.URL}}
+	req := provider.Request{
+		Model:   "claude-sonnet-4-5",
+		Payload: []byte(`{"model":"claude-sonnet-4-5","max_tokens":16,"messages":[{"role":"user","content":"Count the tokens in this short sentence, please."}]}`),
+	}
+	opts := provider.Options{SourceFormat: provider.FromString("claude")}
+
+	resp, err := exec.CountTokens(context.Background(), auth, req, opts)
+	if err != nil {
+		t.Fatalf("count tokens: %v", err)
+	}
+	if got := gjson.GetBytes(resp.Payload, "input_tokens").Int(); got <= 0 {
+		t.Errorf("estimated input_tokens = %d, want > 0", got)
+	}
+
+	// Real upstream errors are not masked by the estimator.
+	status = http.StatusBadRequest
+	if _, err := exec.CountTokens(context.Background(), auth, req, opts); err == nil {
+		t.Fatal("400 from count_tokens was swallowed")
+	}
+}
@@ -50,9 +50,22 @@ func (p *claudeStreamProcessor) ProcessLine(line []byte) ([][]byte, *ir.Usage, e
 	if err != nil {
 		return nil, nil, err
 	}
+	if result.Usage == nil {
+		return result.Chunks, claudeMessageStartUsage(line), nil
+	}
 	return result.Chunks, result.Usage, nil
 }
 
+// claudeMessageStartUsage returns the usage carried by a message_start event.
+// Claude reports input tokens there and output tokens in message_delta.
+func claudeMessageStartUsage(line []byte) *ir.Usage {
+	data := ir.ExtractSSEData(line)
+	if len(data) == 0 || gjson.GetBytes(data, "type").String() != "message_start" {
+		return nil
+	}
+	return ir.ParseClaudeUsage(gjson.GetBytes(data, "message.usage"))
+}
+
 func (p *claudeStreamProcessor) ProcessDone() ([][]byte, error) {
 	return p.translator.Flush(), nil
 }
@@ -65,6 +78,9 @@ func (p *claudePassthroughProcessor) ProcessLine(line []byte) ([][]byte, *ir.Usa
 		return nil, nil, nil
 	}
 	usage := extractUsageFromEvents(events)
+	if usage == nil {
+		usage = claudeMessageStartUsage(line)
+	}
 	return nil, usage, nil
 }
 
@@ -79,6 +95,7 @@ func (e *ClaudeExecutor) Identifier() string { return "claude" }
 func (e *ClaudeExecutor) PrepareRequest(_ *http.Request, _ *provider.Auth) error { return nil }
 
 func (e *ClaudeExecutor) Execute(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (resp provider.Response, err error) {
+	req.Model = thinkingModelFor(e.Identifier(), req.Model, req.Payload)
 	apiKey, baseURL := claudeCreds(auth)
 
 	if baseURL == "" {
@@ -108,6 +125,7 @@ func (e *ClaudeExecutor) Execute(ctx context.Context, auth *provider.Auth, req p
 
 	var extraBetas []string
 	extraBetas, body = extractAndRemoveBetas(body)
+	extraBetas = append(extraBetas, getRequiredBetas(req.Model)...)
 
 	ub := GetURLBuilder()
 	defer ub.Release()
@@ -159,10 +177,12 @@ func (e *ClaudeExecutor) Execute(ctx context.Context, auth *provider.Auth, req p
 		for _, line := range lines {
 			if events, err := to_ir.ParseClaudeChunk(line); err == nil && len(events) > 0 {
 				if u := extractUsageFromEvents(events); u != nil {
-					reporter.publish(ctx, u)
+					reporter.observe(u)
 				}
 			}
+			reporter.observe(claudeMessageStartUsage(line))
 		}
+		reporter.flush(ctx, false)
 	} else {
 		reporter.publish(ctx, extractUsageFromClaudeResponse(data))
 	}
@@ -181,6 +201,7 @@ func (e *ClaudeExecutor) Execute(ctx context.Context, auth *provider.Auth, req p
 }
 
 func (e *ClaudeExecutor) ExecuteStream(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (stream <-chan provider.StreamChunk, err error) {
+	req.Model = thinkingModelFor(e.Identifier(), req.Model, req.Payload)
 	apiKey, baseURL := claudeCreds(auth)
 
 	if baseURL == "" {
@@ -206,6 +227,7 @@ func (e *ClaudeExecutor) ExecuteStream(ctx context.Context, auth *provider.Auth,
 
 	var extraBetas []string
 	extraBetas, body = extractAndRemoveBetas(body)
+	extraBetas = append(extraBetas, getRequiredBetas(req.Model)...)
 
 	ub := GetURLBuilder()
 	defer ub.Release()
@@ -253,6 +275,7 @@ func (e *ClaudeExecutor) ExecuteStream(ctx context.Context, auth *provider.Auth,
 	}
 
 	streamCtx := NewStreamContext()
+	streamCtx.RequestUsage(opts.OriginalRequest)
 	translator := NewStreamTranslator(e.cfg, from, from.String(), req.Model, "msg-"+req.Model, streamCtx)
 	processor := &claudeStreamProcessor{
 		translator: translator,
@@ -262,6 +285,9 @@ func (e *ClaudeExecutor) ExecuteStream(ctx context.Context, auth *provider.Auth,
 	}), nil
 }
 
+// CountTokens asks Anthropic's count_tokens endpoint for the exact input
+// token count. When the endpoint is unavailable (missing behind a proxy,
+// throttled or failing) the count is estimated locally instead.
 func (e *ClaudeExecutor) CountTokens(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (provider.Response, error) {
 	apiKey, baseURL := claudeCreds(auth)
 
@@ -270,8 +296,7 @@ func (e *ClaudeExecutor) CountTokens(ctx context.Context, auth *provider.Auth, r
 	}
 
 	from := opts.SourceFormat
-	stream := from.String() != "claude"
-	body, err := TranslateToClaude(e.cfg, from, req.Model, req.Payload, stream, req.Metadata)
+	body, irReq, err := TranslateToClaudeCountTokens(from, req.Model, req.Payload, req.Metadata)
 	if err != nil {
 		return provider.Response{}, err
 	}
@@ -287,6 +312,13 @@ func (e *ClaudeExecutor) CountTokens(ctx context.Context, auth *provider.Auth, r
 
 	var extraBetas []string
 	extraBetas, body = extractAndRemoveBetas(body)
+	extraBetas = append(extraBetas, getRequiredBetas(req.Model)...)
+
+	estimate := func(reason string) (provider.Response, error) {
+		log.Debugf("claude executor: count_tokens unavailable (%s), estimating locally", reason)
+		count := estimateInputTokens(modelForUpstream, irReq)
+		return provider.Response{Payload: buildTokenCountJSON(from, count)}, nil
+	}
 
 	ub := GetURLBuilder()
 	defer ub.Release()
@@ -306,13 +338,19 @@ func (e *ClaudeExecutor) CountTokens(ctx context.Context, auth *provider.Auth, r
 		if errors.Is(err, context.DeadlineExceeded) {
 			return provider.Response{}, NewTimeoutError("request timed out")
 		}
-		return provider.Response{}, err
+		if ctx.Err() != nil {
+			return provider.Response{}, err
+		}
+		return estimate(err.Error())
 	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		b, _ := io.ReadAll(resp.Body)
 		if errClose := resp.Body.Close(); errClose != nil {
 			log.Errorf("response body close error: %v", errClose)
 		}
+		if countTokensUnavailable(resp.StatusCode) {
+			return estimate(fmt.Sprintf("status %d", resp.StatusCode))
+		}
 		return provider.Response{}, NewStatusError(resp.StatusCode, string(b), nil)
 	}
 	decodedBody, err := decodeResponseBody(resp.Body, resp.Header.Get("Content-Encoding"))
@@ -331,7 +369,11 @@ func (e *ClaudeExecutor) CountTokens(ctx context.Context, auth *provider.Auth, r
 	if err != nil {
 		return provider.Response{}, err
 	}
-	return provider.Response{Payload: data}, nil
+	count := gjson.GetBytes(data, "input_tokens")
+	if !count.Exists() {
+		return provider.Response{}, fmt.Errorf("claude executor: count_tokens response has no input_tokens: %s", summarizeErrorBody(resp.Header.Get("Content-Type"), data))
+	}
+	return provider.Response{Payload: buildTokenCountJSON(from, count.Int())}, nil
 }
 
 func (e *ClaudeExecutor) Refresh(ctx context.Context, auth *provider.Auth) (*provider.Auth, error) {
@@ -447,8 +489,10 @@ func (e *ClaudeExecutor) resolveUpstreamModel(alias string, auth *provider.Auth)
 	switch alias {
 	case "claude-opus-4-5-thinking", "claude-opus-4-5-thinking-low", "claude-opus-4-5-thinking-medium", "claude-opus-4-5-thinking-high":
 		return "claude-opus-4-5-20251101"
-	case "claude-sonnet-4-5-thinking":
+	case "claude-sonnet-4-5-thinking", "claude-sonnet-4-5-1m":
 		return "claude-sonnet-4-5-20250929"
+	case "claude-sonnet-4-1m":
+		return "claude-sonnet-4-20250514"
 	}
 	entry := e.resolveClaudeConfig(auth)
 	if entry == nil {
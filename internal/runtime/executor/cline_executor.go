@@ -137,6 +137,7 @@ func (e *ClineExecutor) ExecuteStream(ctx context.Context, auth *provider.Auth,
 
 	messageID := "chatcmpl-" + req.Model
 	processor := NewOpenAIStreamProcessor(e.cfg, from, req.Model, messageID)
+	processor.ctx.RequestUsage(opts.OriginalRequest)
 	processor.Preprocess = clinePreprocess
 
 	return RunSSEStream(ctx, httpResp.Body, reporter, processor, StreamConfig{
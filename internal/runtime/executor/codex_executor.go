@@ -96,19 +96,21 @@ func (e *CodexExecutor) Execute(ctx context.Context, auth *provider.Auth, req pr
 			continue
 		}
 
-		if detail := extractUsageFromOpenAIResponse(line); detail != nil {
+		// The completed event wraps the final response object.
+		completed := []byte(gjson.GetBytes(line, "response").Raw)
+		if detail := extractUsageFromOpenAIResponse(completed); detail != nil {
 			reporter.publish(ctx, detail)
 		}
 
 		fromFormat := provider.FromString("codex")
-		translatedResp, err := TranslateResponseNonStream(e.cfg, fromFormat, from, line, req.Model)
+		translatedResp, err := TranslateResponseNonStream(e.cfg, fromFormat, from, completed, req.Model)
 		if err != nil {
 			return resp, err
 		}
 		if translatedResp != nil {
 			resp = provider.Response{Payload: translatedResp}
 		} else {
-			resp = provider.Response{Payload: line}
+			resp = provider.Response{Payload: completed}
 		}
 		return resp, nil
 	}
@@ -162,6 +164,15 @@ func (e *CodexExecutor) ExecuteStream(ctx context.Context, auth *provider.Auth,
 		return nil, NewStatusError(httpResp.StatusCode, string(data), nil)
 	}
 
+	// Responses API clients get the upstream events unchanged.
+	if from.String() == "codex" || from.String() == "openai-response" {
+		return RunSSEStream(ctx, httpResp.Body, reporter, &codexPassthroughProcessor{}, StreamConfig{
+			ExecutorName:       "codex",
+			SkipEmptyLines:     true,
+			PassthroughOnEmpty: true,
+		}), nil
+	}
+
 	messageID := "resp-" + req.Model
 	streamCtx := NewStreamContext()
 	translator := NewStreamTranslator(e.cfg, from, from.String(), req.Model, messageID, streamCtx)
@@ -175,6 +186,22 @@ func (e *CodexExecutor) ExecuteStream(ctx context.Context, auth *provider.Auth,
 	}), nil
 }
 
+// codexPassthroughProcessor forwards lines untouched and only observes
+// usage from the response.completed event.
+type codexPassthroughProcessor struct{}
+
+func (p *codexPassthroughProcessor) ProcessLine(line []byte) ([][]byte, *ir.Usage, error) {
+	events, err := to_ir.ParseOpenAIChunk(line)
+	if err != nil {
+		return nil, nil, nil
+	}
+	return nil, extractUsageFromEvents(events), nil
+}
+
+func (p *codexPassthroughProcessor) ProcessDone() ([][]byte, error) {
+	return nil, nil
+}
+
 type codexStreamProcessor struct {
 	translator *StreamTranslator
 }
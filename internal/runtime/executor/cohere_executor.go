@@ -0,0 +1,305 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/constant"
+	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/translator/from_ir"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/nghyane/llm-mux/internal/translator/to_ir"
+	"github.com/nghyane/llm-mux/internal/util"
+	"github.com/tidwall/sjson"
+)
+
+// cohereEmbedBatchLimit is the most texts Cohere accepts per embed call.
+const cohereEmbedBatchLimit = 96
+
+// CohereExecutor talks to Cohere's v1 chat, embed and rerank APIs.
+type CohereExecutor struct {
+	cfg *config.Config
+}
+
+func NewCohereExecutor(cfg *config.Config) *CohereExecutor { return &CohereExecutor{cfg: cfg} }
+
+func (e *CohereExecutor) Identifier() string { return constant.Cohere }
+
+func (e *CohereExecutor) PrepareRequest(_ *http.Request, _ *provider.Auth) error { return nil }
+
+func (e *CohereExecutor) Execute(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (resp provider.Response, err error) {
+	reporter := newUsageReporter(ctx, e.Identifier(), req.Model, auth)
+	defer reporter.trackFailure(ctx, &err)
+
+	from := opts.SourceFormat
+	body, err := TranslateToCohere(e.cfg, from, req.Model, req.Payload, req.Metadata)
+	if err != nil {
+		return resp, err
+	}
+	body = e.overrideModel(body, req.Model, auth)
+
+	httpResp, err := e.post(ctx, auth, "/v1/chat", body)
+	if err != nil {
+		return resp, err
+	}
+	defer func() {
+		if errClose := httpResp.Body.Close(); errClose != nil {
+			log.Errorf("cohere executor: close response body error: %v", errClose)
+		}
+	}()
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return resp, err
+	}
+	messages, usage, meta, err := to_ir.ParseCohereResponse(data)
+	if err != nil {
+		return resp, err
+	}
+	reporter.publish(ctx, usage)
+	reporter.ensurePublished(ctx)
+
+	out, err := NewResponseTranslator(e.cfg, from.String(), req.Model).Translate(messages, usage, meta)
+	if err != nil {
+		return resp, err
+	}
+	if out == nil {
+		return resp, fmt.Errorf("cohere executor: unsupported response format %s", from)
+	}
+	return provider.Response{Payload: out}, nil
+}
+
+func (e *CohereExecutor) ExecuteStream(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (stream <-chan provider.StreamChunk, err error) {
+	reporter := newUsageReporter(ctx, e.Identifier(), req.Model, auth)
+	defer reporter.trackFailure(ctx, &err)
+
+	from := opts.SourceFormat
+	body, err := TranslateToCohere(e.cfg, from, req.Model, req.Payload, req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	body = e.overrideModel(body, req.Model, auth)
+	body, _ = sjson.SetBytes(body, "stream", true)
+
+	httpResp, err := e.post(ctx, auth, "/v1/chat", body)
+	if err != nil {
+		return nil, err
+	}
+
+	streamCtx := NewStreamContext()
+	streamCtx.RequestUsage(opts.OriginalRequest)
+	processor := &cohereStreamProcessor{
+		state:      to_ir.NewCohereStreamState(),
+		translator: NewStreamTranslator(e.cfg, from, from.String(), req.Model, "chatcmpl-"+req.Model, streamCtx),
+	}
+	return RunSSEStream(ctx, httpResp.Body, reporter, processor, StreamConfig{
+		ExecutorName:    "cohere",
+		Preprocessor:    DataTagPreprocessor(),
+		EnsurePublished: true,
+	}), nil
+}
+
+// cohereStreamProcessor translates Cohere's newline-delimited JSON events.
+type cohereStreamProcessor struct {
+	state      *to_ir.CohereStreamState
+	translator *StreamTranslator
+}
+
+func (p *cohereStreamProcessor) ProcessLine(line []byte) ([][]byte, *ir.Usage, error) {
+	events, err := p.state.ProcessChunk(line)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(events) == 0 {
+		return nil, nil, nil
+	}
+	result, err := p.translator.Translate(events)
+	if err != nil {
+		return nil, nil, err
+	}
+	return result.Chunks, result.Usage, nil
+}
+
+func (p *cohereStreamProcessor) ProcessDone() ([][]byte, error) {
+	return p.translator.Flush(), nil
+}
+
+// CountTokens estimates locally; Cohere has no count endpoint for chat
+// requests.
+func (e *CohereExecutor) CountTokens(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (provider.Response, error) {
+	translated, err := TranslateToOpenAI(e.cfg, opts.SourceFormat, req.Model, req.Payload, false, nil)
+	if err != nil {
+		return provider.Response{}, err
+	}
+	enc, err := tokenizerForModel(req.Model)
+	if err != nil {
+		return provider.Response{}, fmt.Errorf("cohere executor: tokenizer init failed: %w", err)
+	}
+	count, err := countOpenAIChatTokens(enc, translated)
+	if err != nil {
+		return provider.Response{}, fmt.Errorf("cohere executor: token counting failed: %w", err)
+	}
+	return provider.Response{Payload: buildOpenAIUsageJSON(count)}, nil
+}
+
+// Embed forwards embeddings to /v1/embed in batches Cohere accepts.
+func (e *CohereExecutor) Embed(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (resp provider.Response, err error) {
+	reporter := newUsageReporter(ctx, e.Identifier(), req.Model, auth)
+	defer reporter.trackFailure(ctx, &err)
+
+	embedReq, err := parseEmbeddingRequest(opts.SourceFormat, req.Model, req.Payload)
+	if err != nil {
+		return resp, err
+	}
+	upstreamModel := e.resolveUpstreamModel(req.Model, auth)
+
+	result, err := embedInBatches(ctx, embedReq, cohereEmbedBatchLimit, func(ctx context.Context, batch *ir.EmbeddingRequest) (*ir.EmbeddingResponse, error) {
+		upstreamReq := *batch
+		upstreamReq.Model = upstreamModel
+		body, errBuild := from_ir.ToCohereEmbedRequest(&upstreamReq)
+		if errBuild != nil {
+			return nil, errBuild
+		}
+		httpResp, errPost := e.post(ctx, auth, "/v1/embed", body)
+		if errPost != nil {
+			return nil, errPost
+		}
+		defer func() { _ = httpResp.Body.Close() }()
+		data, errRead := io.ReadAll(httpResp.Body)
+		if errRead != nil {
+			return nil, errRead
+		}
+		return to_ir.ParseCohereEmbedResponse(data)
+	})
+	if err != nil {
+		return resp, err
+	}
+	estimateEmbeddingUsage(embedReq, result)
+	reporter.publish(ctx, result.Usage)
+	return buildEmbeddingResponse(embedReq, result)
+}
+
+// Rerank forwards the request to /v1/rerank.
+func (e *CohereExecutor) Rerank(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (resp provider.Response, err error) {
+	if opts.SourceFormat != provider.FormatOpenAI {
+		return resp, fmt.Errorf("rerank is not supported for %s requests", opts.SourceFormat)
+	}
+	rerankReq, err := to_ir.ParseRerankRequest(req.Payload)
+	if err != nil {
+		return resp, NewStatusError(http.StatusBadRequest, err.Error(), nil)
+	}
+	rerankReq.Model = req.Model
+	upstreamReq := *rerankReq
+	upstreamReq.Model = e.resolveUpstreamModel(req.Model, auth)
+	body, err := from_ir.ToRerankRequest(&upstreamReq)
+	if err != nil {
+		return resp, err
+	}
+
+	httpResp, err := e.post(ctx, auth, "/v1/rerank", body)
+	if err != nil {
+		return resp, err
+	}
+	defer func() { _ = httpResp.Body.Close() }()
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return resp, err
+	}
+	parsed, err := to_ir.ParseRerankResponse(data)
+	if err != nil {
+		return resp, err
+	}
+	parsed.Model = req.Model
+	out, err := from_ir.ToRerankResponse(rerankReq, parsed)
+	if err != nil {
+		return resp, err
+	}
+	return provider.Response{Payload: out}, nil
+}
+
+func (e *CohereExecutor) Refresh(ctx context.Context, auth *provider.Auth) (*provider.Auth, error) {
+	_ = ctx
+	return auth, nil
+}
+
+// post sends body to path on the configured Cohere endpoint and returns the
+// response when it has a 2xx status. The caller closes the body.
+func (e *CohereExecutor) post(ctx context.Context, auth *provider.Auth, path string, body []byte) (*http.Response, error) {
+	baseURL, apiKey := cohereCreds(auth)
+	if apiKey == "" {
+		return nil, NewStatusError(http.StatusUnauthorized, "missing cohere api key", nil)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(baseURL, "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+apiKey)
+	util.ApplyCustomHeadersFromAttrs(httpReq, auth.Attributes)
+
+	httpClient := newProxyAwareHTTPClient(ctx, e.cfg, auth, 0)
+	httpResp, err := httpClient.Do(httpReq)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, NewTimeoutError("request timed out")
+		}
+		return nil, err
+	}
+	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+		result := HandleHTTPError(httpResp, "cohere executor")
+		_ = httpResp.Body.Close()
+		return nil, result.Error
+	}
+	return httpResp, nil
+}
+
+func cohereCreds(auth *provider.Auth) (baseURL, apiKey string) {
+	if auth == nil {
+		return CohereDefaultBaseURL, ""
+	}
+	baseURL = BaseURLOverride(auth)
+	if baseURL == "" {
+		baseURL = CohereDefaultBaseURL
+	}
+	return baseURL, AttrStringValue(auth.Attributes, "api_key")
+}
+
+// resolveUpstreamModel maps a configured alias to the Cohere model name.
+// Models without an alias are sent unchanged.
+func (e *CohereExecutor) resolveUpstreamModel(alias string, auth *provider.Auth) string {
+	if e.cfg == nil || auth == nil {
+		return alias
+	}
+	_, apiKey := cohereCreds(auth)
+	for i := range e.cfg.Providers {
+		p := &e.cfg.Providers[i]
+		if p.Type != config.ProviderTypeCohere {
+			continue
+		}
+		for _, k := range p.GetAPIKeys() {
+			if strings.TrimSpace(k.Key) != apiKey {
+				continue
+			}
+			for _, m := range p.Models {
+				if m.Alias != "" && m.Name != "" && strings.EqualFold(m.Alias, alias) {
+					return m.Name
+				}
+			}
+		}
+	}
+	return alias
+}
+
+func (e *CohereExecutor) overrideModel(body []byte, model string, auth *provider.Auth) []byte {
+	if upstream := e.resolveUpstreamModel(model, auth); upstream != model {
+		body, _ = sjson.SetBytes(body, "model", upstream)
+	}
+	return body
+}
@@ -0,0 +1,77 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/tidwall/gjson"
+)
+
+func TestCohereExecutor_StreamEvents(t *testing.T) {
+	events := []string{
+		`{"is_finished":false,"event_type":"stream-start","generation_id":"g1"}`,
+		`{"is_finished":false,"event_type":"text-generation","text":"Let me "}`,
+		`{"is_finished":false,"event_type":"text-generation","text":"check."}`,
+		`{"is_finished":false,"event_type":"tool-calls-chunk","tool_call_delta":{"index":0,"name":"get_weather"}}`,
+		`{"is_finished":false,"event_type":"tool-calls-generation","tool_calls":[{"name":"get_weather","parameters":{"city":"Paris"}}]}`,
+		`{"is_finished":true,"event_type":"stream-end","finish_reason":"COMPLETE","response":{"meta":{"tokens":{"input_tokens":30,"output_tokens":9}}}}`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer k" {
+			t.Errorf("Authorization = %q", got)
+		}
+		var body bytes.Buffer
+		_, _ = body.ReadFrom(r.Body)
+		if !gjson.GetBytes(body.Bytes(), "stream").Bool() || gjson.GetBytes(body.Bytes(), "message").String() != "Weather?" {
+			t.Errorf("request body = %s", body.String())
+		}
+		w.Header().Set("Content-Type", "application/stream+json")
+		_, _ = w.Write([]byte(strings.Join(events, "\n") + "\n"))
+	}))
+	defer srv.Close()
+
+	exec := NewCohereExecutor(&config.Config{})
+	auth := &provider.Auth{Provider: "cohere", Attributes: map[string]string{"api_key": "k", "base_url": srv.URL}}
+	stream, err := exec.ExecuteStream(context.Background(), auth,
+		provider.Request{Model: "command-r-08-2024", Payload: []byte(`{"model":"command-r-08-2024","stream":true,"messages":[{"role":"user","content":"Weather?"}]}`)},
+		provider.Options{SourceFormat: provider.FormatOpenAI, Stream: true})
+	if err != nil {
+		t.Fatalf("ExecuteStream: %v", err)
+	}
+
+	var text strings.Builder
+	var toolName, toolArgs, finish string
+	for chunk := range stream {
+		if chunk.Err != nil {
+			t.Fatalf("stream error: %v", chunk.Err)
+		}
+		data := bytes.TrimSpace(bytes.TrimPrefix(bytes.TrimSpace(chunk.Payload), []byte("data:")))
+		choice := gjson.GetBytes(data, "choices.0")
+		text.WriteString(choice.Get("delta.content").String())
+		if tc := choice.Get("delta.tool_calls.0.function"); tc.Exists() {
+			toolName += tc.Get("name").String()
+			toolArgs += tc.Get("arguments").String()
+		}
+		if fr := choice.Get("finish_reason").String(); fr != "" {
+			finish = fr
+		}
+	}
+	if text.String() != "Let me check." {
+		t.Errorf("text = %q", text.String())
+	}
+	if toolName != "get_weather" || gjson.Get(toolArgs, "city").String() != "Paris" {
+		t.Errorf("tool call = %s(%s)", toolName, toolArgs)
+	}
+	if finish != "tool_calls" {
+		t.Errorf("finish_reason = %q, want tool_calls", finish)
+	}
+}
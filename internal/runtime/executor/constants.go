@@ -32,13 +32,15 @@ const (
 	CopilotOpenAIIntent         = "conversation-panel"
 	KiroDefaultBaseURL          = "https://codewhisperer.us-east-1.amazonaws.com/generateAssistantResponse"
 	IFlowDefaultEndpoint        = "/chat/completions"
+	CohereDefaultBaseURL        = "https://api.cohere.com"
+	XAIDefaultBaseURL           = "https://api.x.ai/v1"
+	MistralDefaultBaseURL       = "https://api.mistral.ai/v1"
 )
 
 const (
 	DefaultHTTPTimeout         = 60 * time.Second
 	DefaultRefreshSkew         = 3000 * time.Second
 	KiroRefreshSkew            = 5 * time.Minute
-	KiroRequestTimeout         = 120 * time.Second
 	GitHubCopilotTokenCacheTTL = 25 * time.Minute
 	TokenExpiryBuffer          = 5 * time.Minute
 )
@@ -4,6 +4,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/nghyane/llm-mux/internal/config"
+	log "github.com/nghyane/llm-mux/internal/logging"
 	"github.com/nghyane/llm-mux/internal/provider"
 )
 
@@ -30,8 +32,8 @@ func ExtractCreds(a *provider.Auth, cfg CredExtractorConfig) (token, url string)
 
 	if a.Attributes != nil {
 		token = trim(a.Attributes["api_key"])
-		url = trim(a.Attributes["base_url"])
 	}
+	url = BaseURLOverride(a)
 
 	if token == "" && a.Metadata != nil {
 		if v, ok := a.Metadata[cfg.MetadataTokenKey].(string); ok {
@@ -63,6 +65,25 @@ func ExtractCreds(a *provider.Auth, cfg CredExtractorConfig) (token, url string)
 	return token, url
 }
 
+// BaseURLOverride returns the account's base_url attribute without a trailing
+// slash, or "" when it is unset. The attribute replaces the executor's
+// built-in endpoint; values that are not absolute http(s) URLs are ignored
+// so credentials never go to an unexpected scheme.
+func BaseURLOverride(a *provider.Auth) string {
+	if a == nil {
+		return ""
+	}
+	raw := AttrStringValue(a.Attributes, "base_url")
+	if raw == "" {
+		return ""
+	}
+	if err := config.ValidateBaseURL(raw); err != nil {
+		log.Warnf("ignoring base_url of %s account %s: %v", a.Provider, a.ID, err)
+		return ""
+	}
+	return strings.TrimRight(raw, "/")
+}
+
 func ExtractRefreshToken(auth *provider.Auth) (string, bool) {
 	if auth == nil || auth.Metadata == nil {
 		return "", false
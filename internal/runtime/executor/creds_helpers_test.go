@@ -17,20 +17,20 @@ func TestExtractCreds_Claude(t *testing.T) {
 	auth := &provider.Auth{
 		Attributes: map[string]string{
 			"api_key":  "attr-key",
-			"base_url": "attr-url",
+			"base_url": "https://attr.example.com/",
 		},
 	}
 
 	token, url := ExtractCreds(auth, ClaudeCredsConfig)
-	if token != "attr-key" || url != "attr-url" {
-		t.Errorf("Expected token=%q, url=%q, got token=%q, url=%q", "attr-key", "attr-url", token, url)
+	if token != "attr-key" || url != "https://attr.example.com" {
+		t.Errorf("Expected token=%q, url=%q, got token=%q, url=%q", "attr-key", "https://attr.example.com", token, url)
 	}
 }
 
 func TestExtractCreds_Claude_MetadataFallback(t *testing.T) {
 	auth := &provider.Auth{
 		Attributes: map[string]string{
-			"base_url": "attr-url",
+			"base_url": "https://attr.example.com/",
 		},
 		Metadata: map[string]any{
 			"access_token": "meta-token",
@@ -38,8 +38,8 @@ func TestExtractCreds_Claude_MetadataFallback(t *testing.T) {
 	}
 
 	token, url := ExtractCreds(auth, ClaudeCredsConfig)
-	if token != "meta-token" || url != "attr-url" {
-		t.Errorf("Expected token=%q, url=%q, got token=%q, url=%q", "meta-token", "attr-url", token, url)
+	if token != "meta-token" || url != "https://attr.example.com" {
+		t.Errorf("Expected token=%q, url=%q, got token=%q, url=%q", "meta-token", "https://attr.example.com", token, url)
 	}
 }
 
@@ -75,7 +75,7 @@ func TestExtractCreds_IFlow_TrimWhitespace(t *testing.T) {
 	auth := &provider.Auth{
 		Attributes: map[string]string{
 			"api_key":  "  key-with-spaces  ",
-			"base_url": "  url-with-spaces  ",
+			"base_url": "  https://iflow.example.com  ",
 		},
 		Metadata: map[string]any{
 			"api_key":  "  meta-key  ",
@@ -84,8 +84,8 @@ func TestExtractCreds_IFlow_TrimWhitespace(t *testing.T) {
 	}
 
 	token, url := ExtractCreds(auth, IFlowCredsConfig)
-	if token != "key-with-spaces" || url != "url-with-spaces" {
-		t.Errorf("Expected trimmed token=%q, url=%q, got token=%q, url=%q", "key-with-spaces", "url-with-spaces", token, url)
+	if token != "key-with-spaces" || url != "https://iflow.example.com" {
+		t.Errorf("Expected trimmed token=%q, url=%q, got token=%q, url=%q", "key-with-spaces", "https://iflow.example.com", token, url)
 	}
 }
 
@@ -120,3 +120,47 @@ func TestExtractCreds_Gemini_Priority(t *testing.T) {
 		t.Errorf("Expected direct token priority, got token=%q", token)
 	}
 }
+
+func TestBaseURLOverride(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"", ""},
+		{"https://proxy.example.com/v1/", "https://proxy.example.com/v1"},
+		{" http://127.0.0.1:8080 ", "http://127.0.0.1:8080"},
+		{"ftp://proxy.example.com", ""},
+		{"proxy.example.com/v1", ""},
+		{"https://", ""},
+	}
+	for _, tt := range tests {
+		auth := &provider.Auth{Attributes: map[string]string{"base_url": tt.raw}}
+		if got := BaseURLOverride(auth); got != tt.want {
+			t.Errorf("BaseURLOverride(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestBaseURLOverride_ReplacesBuiltInEndpoints(t *testing.T) {
+	override := &provider.Auth{Attributes: map[string]string{"base_url": "https://proxy.example.com/"}}
+	invalid := &provider.Auth{Attributes: map[string]string{"base_url": "file:///etc/passwd"}}
+	tests := []struct {
+		name    string
+		resolve func(*provider.Auth) string
+		want    string
+		builtin string
+	}{
+		{"gemini", resolveGeminiBaseURL, "https://proxy.example.com", GeminiDefaultBaseURL},
+		{"gemini-cli", codeAssistBaseURL, "https://proxy.example.com", codeAssistEndpoint},
+		{"github-copilot", copilotBaseURL, "https://proxy.example.com", GitHubCopilotDefaultBaseURL},
+		{"kiro", kiroEndpoint, "https://proxy.example.com" + kiroChatPath, KiroDefaultBaseURL},
+	}
+	for _, tt := range tests {
+		if got := tt.resolve(override); got != tt.want {
+			t.Errorf("%s: override resolved to %q, want %q", tt.name, got, tt.want)
+		}
+		if got := tt.resolve(invalid); got != tt.builtin {
+			t.Errorf("%s: invalid override resolved to %q, want built-in %q", tt.name, got, tt.builtin)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/translator/from_ir"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/nghyane/llm-mux/internal/translator/to_ir"
+)
+
+const (
+	// geminiEmbedBatchLimit is the most requests batchEmbedContents accepts.
+	geminiEmbedBatchLimit = 100
+	// openAIEmbedBatchLimit is the most inputs OpenAI accepts per embeddings call.
+	openAIEmbedBatchLimit = 2048
+)
+
+// parseEmbeddingRequest converts the client payload into an embedding request
+// for model. Only the OpenAI embeddings format is accepted.
+func parseEmbeddingRequest(from provider.Format, model string, payload []byte) (*ir.EmbeddingRequest, error) {
+	if from != provider.FormatOpenAI {
+		return nil, fmt.Errorf("embeddings are not supported for %s requests", from)
+	}
+	req, err := to_ir.ParseOpenAIEmbeddingRequest(payload)
+	if err != nil {
+		return nil, NewStatusError(http.StatusBadRequest, err.Error(), nil)
+	}
+	req.Model = model
+	return req, nil
+}
+
+// embedInBatches embeds req.Inputs in batches of at most limit inputs and
+// reassembles the vectors in input order. Usage is summed across batches.
+func embedInBatches(ctx context.Context, req *ir.EmbeddingRequest, limit int, embed func(context.Context, *ir.EmbeddingRequest) (*ir.EmbeddingResponse, error)) (*ir.EmbeddingResponse, error) {
+	out := &ir.EmbeddingResponse{Model: req.Model, Vectors: make([][]float32, 0, len(req.Inputs))}
+	for start := 0; start < len(req.Inputs); start += limit {
+		end := min(start+limit, len(req.Inputs))
+		batch := *req
+		batch.Inputs = req.Inputs[start:end]
+		resp, err := embed(ctx, &batch)
+		if err != nil {
+			return nil, err
+		}
+		if len(resp.Vectors) != len(batch.Inputs) {
+			return nil, fmt.Errorf("upstream returned %d embeddings for %d inputs", len(resp.Vectors), len(batch.Inputs))
+		}
+		out.Vectors = append(out.Vectors, resp.Vectors...)
+		if resp.Usage != nil {
+			if out.Usage == nil {
+				out.Usage = &ir.Usage{}
+			}
+			out.Usage.PromptTokens += resp.Usage.PromptTokens
+			out.Usage.TotalTokens += resp.Usage.TotalTokens
+		}
+	}
+	return out, nil
+}
+
+// estimateEmbeddingUsage fills in usage for providers that report none.
+func estimateEmbeddingUsage(req *ir.EmbeddingRequest, resp *ir.EmbeddingResponse) {
+	if resp.Usage != nil {
+		return
+	}
+	enc, err := tokenizerForModel(req.Model)
+	if err != nil {
+		return
+	}
+	var count int64
+	for _, text := range req.Inputs {
+		if n, errCount := enc.Count(text); errCount == nil {
+			count += int64(n)
+		}
+	}
+	resp.Usage = &ir.Usage{PromptTokens: count, TotalTokens: count, Approximate: true}
+}
+
+// buildEmbeddingResponse renders resp for the client that sent req.
+func buildEmbeddingResponse(req *ir.EmbeddingRequest, resp *ir.EmbeddingResponse) (provider.Response, error) {
+	resp.Model = req.Model
+	payload, err := from_ir.ToOpenAIEmbeddingResponse(resp, req.EncodingFormat)
+	if err != nil {
+		return provider.Response{}, err
+	}
+	return provider.Response{Payload: payload}, nil
+}
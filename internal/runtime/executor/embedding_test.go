@@ -0,0 +1,108 @@
+package executor
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/tidwall/gjson"
+)
+
+// embeddingInputs builds an OpenAI embeddings payload with n numbered inputs.
+func embeddingInputs(model string, n int, extra string) []byte {
+	inputs := make([]string, n)
+	for i := range inputs {
+		inputs[i] = fmt.Sprintf("%q", fmt.Sprintf("text %d", i))
+	}
+	return []byte(fmt.Sprintf(`{"model":%q,"input":[%s]%s}`, model, strings.Join(inputs, ","), extra))
+}
+
+func TestGeminiExecutor_EmbedBatchesInOrder(t *testing.T) {
+	var batches []int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1beta/models/gemini-embedding-001:batchEmbedContents" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		body, _ := io.ReadAll(r.Body)
+		requests := gjson.GetBytes(body, "requests").Array()
+		batches = append(batches, len(requests))
+		// Echo each input's number back as its vector so order can be checked.
+		var out []string
+		for _, req := range requests {
+			var n int
+			fmt.Sscanf(req.Get("content.parts.0.text").String(), "text %d", &n)
+			out = append(out, fmt.Sprintf(`{"values":[%d,0.5]}`, n))
+		}
+		_, _ = fmt.Fprintf(w, `{"embeddings":[%s]}`, strings.Join(out, ","))
+	}))
+	defer srv.Close()
+
+	exec := NewGeminiExecutor(&config.Config{})
+	auth := &provider.Auth{Provider: "gemini", Attributes: map[string]string{"api_key": "k", "base_url": srv.URL}}
+	resp, err := exec.Embed(context.Background(), auth,
+		provider.Request{Model: "gemini-embedding-001", Payload: embeddingInputs("gemini-embedding-001", 250, "")},
+		provider.Options{SourceFormat: provider.FormatOpenAI})
+	if err != nil {
+		t.Fatalf("embed: %v", err)
+	}
+	if fmt.Sprint(batches) != "[100 100 50]" {
+		t.Errorf("batches = %v, want [100 100 50]", batches)
+	}
+	data := gjson.GetBytes(resp.Payload, "data").Array()
+	if len(data) != 250 {
+		t.Fatalf("got %d embeddings, want 250", len(data))
+	}
+	for i, d := range data {
+		if d.Get("index").Int() != int64(i) || d.Get("embedding.0").Int() != int64(i) {
+			t.Fatalf("embedding %d out of order: %s", i, d.Raw)
+		}
+	}
+	if gjson.GetBytes(resp.Payload, "object").String() != "list" || gjson.GetBytes(resp.Payload, "usage.prompt_tokens").Int() <= 0 {
+		t.Errorf("missing list envelope or usage: %s", gjson.GetBytes(resp.Payload, "usage").Raw)
+	}
+}
+
+func TestOpenAICompatExecutor_EmbedUsageAndBase64(t *testing.T) {
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/embeddings" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		body, _ = io.ReadAll(r.Body)
+		// Out-of-order data must be reordered by index.
+		_, _ = w.Write([]byte(`{"object":"list","model":"m","data":[
+			{"object":"embedding","index":1,"embedding":[2,2]},
+			{"object":"embedding","index":0,"embedding":[1,1]}],
+			"usage":{"prompt_tokens":7,"total_tokens":7}}`))
+	}))
+	defer srv.Close()
+
+	exec := NewOpenAICompatExecutor("compat", &config.Config{})
+	auth := &provider.Auth{Provider: "compat", Attributes: map[string]string{"api_key": "k", "base_url": srv.URL}}
+	resp, err := exec.Embed(context.Background(), auth,
+		provider.Request{Model: "m", Payload: []byte(`{"model":"m","input":["a","b"],"encoding_format":"base64","dimensions":2}`)},
+		provider.Options{SourceFormat: provider.FormatOpenAI})
+	if err != nil {
+		t.Fatalf("embed: %v", err)
+	}
+	if got := gjson.GetBytes(body, "encoding_format").String(); got != "float" {
+		t.Errorf("upstream encoding_format = %q, want float", got)
+	}
+	if gjson.GetBytes(body, "dimensions").Int() != 2 {
+		t.Errorf("dimensions not forwarded: %s", body)
+	}
+	if got := gjson.GetBytes(resp.Payload, "usage.prompt_tokens").Int(); got != 7 {
+		t.Errorf("prompt_tokens = %d, want upstream 7", got)
+	}
+	raw, err := base64.StdEncoding.DecodeString(gjson.GetBytes(resp.Payload, "data.0.embedding").String())
+	if err != nil || len(raw) != 8 || raw[3] != 0x3f || raw[2] != 0x80 {
+		t.Errorf("data.0.embedding is not base64 float32 [1,1]: % x (%v)", raw, err)
+	}
+}
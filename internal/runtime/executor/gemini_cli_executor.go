@@ -29,6 +29,15 @@ const (
 	codeAssistVersion  = "v1internal"
 )
 
+// codeAssistBaseURL returns the Code Assist endpoint for auth, honouring a
+// base_url override.
+func codeAssistBaseURL(auth *provider.Auth) string {
+	if base := BaseURLOverride(auth); base != "" {
+		return base
+	}
+	return codeAssistEndpoint
+}
+
 var geminiOauthScopes = []string{
 	"https://www.googleapis.com/auth/cloud-platform",
 	"https://www.googleapis.com/auth/userinfo.email",
@@ -96,7 +105,7 @@ func (e *GeminiCLIExecutor) Execute(ctx context.Context, auth *provider.Auth, re
 		ub := GetURLBuilder()
 		defer ub.Release()
 		ub.Grow(100)
-		ub.WriteString(codeAssistEndpoint)
+		ub.WriteString(codeAssistBaseURL(auth))
 		ub.WriteString("/")
 		ub.WriteString(codeAssistVersion)
 		ub.WriteString(":")
@@ -223,7 +232,7 @@ func (e *GeminiCLIExecutor) ExecuteStream(ctx context.Context, auth *provider.Au
 		ub := GetURLBuilder()
 		defer ub.Release()
 		ub.Grow(100)
-		ub.WriteString(codeAssistEndpoint)
+		ub.WriteString(codeAssistBaseURL(auth))
 		ub.WriteString("/")
 		ub.WriteString(codeAssistVersion)
 		ub.WriteString(":")
@@ -288,6 +297,7 @@ func (e *GeminiCLIExecutor) ExecuteStream(ctx context.Context, auth *provider.Au
 		}
 
 		streamCtx := NewStreamContext()
+		streamCtx.RequestUsage(opts.OriginalRequest)
 		streamCtx.EstimatedInputTokens = estimatedInputTokens
 		messageID := "chatcmpl-" + attemptModel
 
@@ -344,7 +354,7 @@ func (e *GeminiCLIExecutor) CountTokens(ctx context.Context, auth *provider.Auth
 		ub := GetURLBuilder()
 		defer ub.Release()
 		ub.Grow(100)
-		ub.WriteString(codeAssistEndpoint)
+		ub.WriteString(codeAssistBaseURL(auth))
 		ub.WriteString("/")
 		ub.WriteString(codeAssistVersion)
 		ub.WriteString(":")
@@ -642,7 +652,7 @@ func FetchGeminiCLIModels(ctx context.Context, auth *provider.Auth, cfg *config.
 	httpClient := newProxyAwareHTTPClient(ctx, cfg, auth, 0)
 
 	fetchCfg := CloudCodeFetchConfig{
-		BaseURLs:     []string{codeAssistEndpoint},
+		BaseURLs:     []string{codeAssistBaseURL(auth)},
 		Token:        tok.AccessToken,
 		ProviderType: "gemini-cli",
 		AliasFunc:    func(name string) string { return registry.GeminiUpstreamToID(name, nil) },
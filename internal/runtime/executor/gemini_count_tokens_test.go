@@ -0,0 +1,90 @@
+package executor
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/util"
+	"github.com/tidwall/gjson"
+)
+
+const geminiCountImagePayload = `{"model":"gemini-2.5-flash","messages":[
+	{"role":"system","content":"Describe images briefly."},
+	{"role":"user","content":[
+		{"type":"text","text":"What is in this picture?"},
+		{"type":"image_url","image_url":{"url":"data:image/png;base64,iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mNkYPhfDwAChwGA60e6kgAAAABJRU5ErkJggg=="}}
+	]}]}`
+
+func TestGeminiExecutor_CountTokensNative(t *testing.T) {
+	var path string
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		body, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"totalTokens":271,"promptTokensDetails":[{"modality":"TEXT","tokenCount":13},{"modality":"IMAGE","tokenCount":258}]}`))
+	}))
+	defer srv.Close()
+
+	exec := NewGeminiExecutor(&config.Config{})
+	auth := &provider.Auth{Provider: "gemini", Attributes: map[string]string{"api_key": "k", "base_url": srv.URL}}
+
+	resp, err := exec.CountTokens(context.Background(), auth,
+		provider.Request{Model: "gemini-2.5-flash", Payload: []byte(geminiCountImagePayload)},
+		provider.Options{SourceFormat: provider.FromString("openai")})
+	if err != nil {
+		t.Fatalf("count tokens: %v", err)
+	}
+	if path != "/v1beta/models/gemini-2.5-flash:countTokens" {
+		t.Errorf("path = %q", path)
+	}
+	gcr := gjson.GetBytes(body, "generateContentRequest")
+	if gcr.Get("model").String() != "models/gemini-2.5-flash" {
+		t.Errorf("generateContentRequest.model = %q", gcr.Get("model").String())
+	}
+	if !gcr.Get("systemInstruction").Exists() {
+		t.Errorf("system instruction not counted: %s", body)
+	}
+	if !gcr.Get(`contents.0.parts.#(inlineData).inlineData.mimeType`).Exists() {
+		t.Errorf("image part not sent for counting: %s", body)
+	}
+	if gcr.Get("generationConfig").Exists() {
+		t.Errorf("generationConfig sent to countTokens: %s", body)
+	}
+	if got := gjson.GetBytes(resp.Payload, "usage.prompt_tokens").Int(); got != 271 {
+		t.Errorf("prompt_tokens = %d, want 271 (payload %s)", got, resp.Payload)
+	}
+}
+
+func TestGeminiExecutor_CountTokensFallback(t *testing.T) {
+	status := http.StatusServiceUnavailable
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"error":{"code":503,"message":"The model is overloaded.","status":"UNAVAILABLE"}}`))
+	}))
+	defer srv.Close()
+
+	exec := NewGeminiExecutor(&config.Config{})
+	auth := &provider.Auth{Provider: "gemini", Attributes: map[string]string{"api_key": "k", "base_url": srv.URL}}
+	req := provider.Request{Model: "gemini-2.5-flash", Payload: []byte(geminiCountImagePayload)}
+	opts := provider.Options{SourceFormat: provider.FromString("openai")}
+
+	resp, err := exec.CountTokens(context.Background(), auth, req, opts)
+	if err != nil {
+		t.Fatalf("count tokens: %v", err)
+	}
+	// The estimate must include the image, not just the text.
+	if got := gjson.GetBytes(resp.Payload, "usage.prompt_tokens").Int(); got < util.ImageTokenCostOpenAI {
+		t.Errorf("estimated prompt_tokens = %d, want at least one image's worth", got)
+	}
+
+	status = http.StatusBadRequest
+	if _, err := exec.CountTokens(context.Background(), auth, req, opts); err == nil {
+		t.Fatal("400 from countTokens was swallowed")
+	}
+}
@@ -8,17 +8,18 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"strings"
 	"time"
 
 	"github.com/nghyane/llm-mux/internal/config"
 	log "github.com/nghyane/llm-mux/internal/logging"
 	"github.com/nghyane/llm-mux/internal/provider"
 	"github.com/nghyane/llm-mux/internal/registry"
+	"github.com/nghyane/llm-mux/internal/translator/from_ir"
 	"github.com/nghyane/llm-mux/internal/translator/ir"
 	"github.com/nghyane/llm-mux/internal/translator/preprocess"
 	"github.com/nghyane/llm-mux/internal/translator/to_ir"
 	"github.com/nghyane/llm-mux/internal/util"
+	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
@@ -223,6 +224,7 @@ func (e *GeminiExecutor) ExecuteStream(ctx context.Context, auth *provider.Auth,
 
 	go func() {
 		defer close(out)
+		defer reporter.flush(ctx, false)
 		defer func() {
 			if r := recover(); r != nil {
 				log.Errorf("gemini executor: panic in stream goroutine: %v", r)
@@ -238,6 +240,7 @@ func (e *GeminiExecutor) ExecuteStream(ctx context.Context, auth *provider.Auth,
 		scanner := bufio.NewScanner(httpResp.Body)
 		scanner.Buffer(buf, DefaultStreamBufferSize)
 		streamCtx := NewStreamContext()
+		streamCtx.RequestUsage(opts.OriginalRequest)
 		streamCtx.EstimatedInputTokens = estimatedInputTokens
 		messageID := "chatcmpl-" + req.Model
 		translator := NewStreamTranslator(e.cfg, from, from.String(), req.Model, messageID, streamCtx)
@@ -277,7 +280,7 @@ func (e *GeminiExecutor) ExecuteStream(ctx context.Context, auth *provider.Auth,
 				return
 			}
 			if usage != nil {
-				reporter.publish(ctx, usage)
+				reporter.observe(usage)
 			}
 			for _, chunk := range chunks {
 				select {
@@ -308,21 +311,22 @@ func (e *GeminiExecutor) ExecuteStream(ctx context.Context, auth *provider.Auth,
 	return stream, nil
 }
 
+// CountTokens calls Gemini's :countTokens endpoint, which also accounts for
+// inline images and documents. When the call fails for reasons other than
+// an invalid request or credentials, the count is estimated locally.
 func (e *GeminiExecutor) CountTokens(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (provider.Response, error) {
 	apiKey, bearer := geminiCreds(auth)
 
 	from := opts.SourceFormat
-	translatedReq, err := TranslateToGemini(e.cfg, from, req.Model, req.Payload, false, req.Metadata)
+	translatedReq, irReq, err := TranslateToGeminiCountTokens(from, req.Model, req.Payload, req.Metadata)
 	if err != nil {
 		return provider.Response{}, fmt.Errorf("translate request: %w", err)
 	}
-	if budgetOverride, includeOverride, ok := util.GeminiThinkingFromMetadata(req.Metadata); ok && util.ModelSupportsThinking(req.Model) {
-		translatedReq = util.ApplyGeminiThinkingConfig(translatedReq, budgetOverride, includeOverride)
+
+	estimate := func(reason string) (provider.Response, error) {
+		log.Debugf("gemini executor: countTokens failed (%s), estimating locally", reason)
+		return provider.Response{Payload: buildTokenCountJSON(from, estimateInputTokens(req.Model, irReq))}, nil
 	}
-	translatedReq = util.StripThinkingConfigIfUnsupported(req.Model, translatedReq)
-	translatedReq, _ = sjson.DeleteBytes(translatedReq, "tools")
-	translatedReq, _ = sjson.DeleteBytes(translatedReq, "generationConfig")
-	translatedReq, _ = sjson.DeleteBytes(translatedReq, "safetySettings")
 
 	baseURL := resolveGeminiBaseURL(auth)
 	ub := GetURLBuilder()
@@ -356,7 +360,10 @@ func (e *GeminiExecutor) CountTokens(ctx context.Context, auth *provider.Auth, r
 		if errors.Is(err, context.DeadlineExceeded) {
 			return provider.Response{}, NewTimeoutError("request timed out")
 		}
-		return provider.Response{}, err
+		if ctx.Err() != nil {
+			return provider.Response{}, err
+		}
+		return estimate(err.Error())
 	}
 	defer func() { _ = resp.Body.Close() }()
 
@@ -366,10 +373,77 @@ func (e *GeminiExecutor) CountTokens(ctx context.Context, auth *provider.Auth, r
 	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		log.Debugf("gemini executor: error status: %d, body: %s", resp.StatusCode, summarizeErrorBody(resp.Header.Get("Content-Type"), data))
+		if countTokensUnavailable(resp.StatusCode) {
+			return estimate(fmt.Sprintf("status %d", resp.StatusCode))
+		}
 		return provider.Response{}, NewStatusError(resp.StatusCode, string(data), nil)
 	}
 
-	return provider.Response{Payload: data}, nil
+	total := gjson.GetBytes(data, "totalTokens")
+	if !total.Exists() {
+		return estimate("response has no totalTokens")
+	}
+	if from.String() == "gemini" {
+		// Keep promptTokensDetails and cachedContentTokenCount for Gemini clients.
+		return provider.Response{Payload: data}, nil
+	}
+	return provider.Response{Payload: buildTokenCountJSON(from, total.Int())}, nil
+}
+
+// Embed serves embeddings through batchEmbedContents, splitting inputs into
+// batches the endpoint accepts.
+func (e *GeminiExecutor) Embed(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (resp provider.Response, err error) {
+	reporter := newUsageReporter(ctx, e.Identifier(), req.Model, auth)
+	defer reporter.trackFailure(ctx, &err)
+
+	embedReq, err := parseEmbeddingRequest(opts.SourceFormat, req.Model, req.Payload)
+	if err != nil {
+		return resp, err
+	}
+	apiKey, bearer := geminiCreds(auth)
+	url := resolveGeminiBaseURL(auth) + "/" + GeminiGLAPIVersion + "/models/" + req.Model + ":batchEmbedContents"
+	httpClient := newProxyAwareHTTPClient(ctx, e.cfg, auth, 0)
+
+	result, err := embedInBatches(ctx, embedReq, geminiEmbedBatchLimit, func(ctx context.Context, batch *ir.EmbeddingRequest) (*ir.EmbeddingResponse, error) {
+		body, errBuild := from_ir.ToGeminiBatchEmbedRequest(batch)
+		if errBuild != nil {
+			return nil, errBuild
+		}
+		httpReq, errReq := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if errReq != nil {
+			return nil, errReq
+		}
+		httpReq.Header.Set("Content-Type", "application/json")
+		if apiKey != "" {
+			httpReq.Header.Set("x-goog-api-key", apiKey)
+		} else {
+			httpReq.Header.Set("Authorization", "Bearer "+bearer)
+		}
+		applyGeminiHeaders(httpReq, auth)
+
+		httpResp, errDo := httpClient.Do(httpReq)
+		if errDo != nil {
+			if errors.Is(errDo, context.DeadlineExceeded) {
+				return nil, NewTimeoutError("request timed out")
+			}
+			return nil, errDo
+		}
+		defer func() { _ = httpResp.Body.Close() }()
+		if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+			return nil, HandleHTTPError(httpResp, "gemini executor").Error
+		}
+		data, errRead := io.ReadAll(httpResp.Body)
+		if errRead != nil {
+			return nil, errRead
+		}
+		return to_ir.ParseGeminiEmbeddingResponse(data)
+	})
+	if err != nil {
+		return resp, err
+	}
+	estimateEmbeddingUsage(embedReq, result)
+	reporter.publish(ctx, result.Usage)
+	return buildEmbeddingResponse(embedReq, result)
 }
 
 func (e *GeminiExecutor) Refresh(ctx context.Context, auth *provider.Auth) (*provider.Auth, error) {
@@ -430,7 +504,7 @@ func (e *GeminiExecutor) Refresh(ctx context.Context, auth *provider.Auth) (*pro
 	}
 	conf := &oauth2.Config{ClientID: clientID, ClientSecret: clientSecret, Endpoint: endpoint}
 
-	httpClient := util.SetProxy(&e.cfg.SDKConfig, &http.Client{})
+	httpClient := newProxyAwareHTTPClient(ctx, e.cfg, auth, 0)
 	ctx = context.WithValue(ctx, oauth2.HTTPClient, httpClient)
 
 	tok := &oauth2.Token{AccessToken: accessToken, RefreshToken: refreshToken}
@@ -477,8 +551,8 @@ func geminiCreds(a *provider.Auth) (apiKey, bearer string) {
 func resolveGeminiBaseURL(auth *provider.Auth) string {
 	base := GeminiDefaultBaseURL
 	if auth != nil {
-		if custom := AttrStringValue(auth.Attributes, "base_url"); custom != "" {
-			base = strings.TrimRight(custom, "/")
+		if custom := BaseURLOverride(auth); custom != "" {
+			base = custom
 		}
 	}
 	if base == "" {
@@ -0,0 +1,129 @@
+package executor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/nghyane/llm-mux/internal/translator/to_ir"
+	"github.com/tidwall/gjson"
+)
+
+func TestGeminiAPI_RoundTripThroughClaude(t *testing.T) {
+	from := provider.FromString("gemini")
+	payload := `{"systemInstruction":{"parts":[{"text":"Be brief."}]},"contents":[{"role":"user","parts":[{"text":"Weather in Paris?"}]}],"tools":[{"functionDeclarations":[{"name":"get_weather","description":"Look up weather","parameters":{"type":"OBJECT","properties":{"city":{"type":"STRING"}}}}]}],"generationConfig":{"maxOutputTokens":256}}`
+
+	body, err := TranslateToClaude(nil, from, "claude-sonnet-4-5", []byte(payload), false, nil)
+	if err != nil {
+		t.Fatalf("TranslateToClaude: %v", err)
+	}
+	req := gjson.ParseBytes(body)
+	if got := req.Get("messages.0.content.0.text").String(); got != "Weather in Paris?" {
+		t.Errorf("user text = %q (body %s)", got, body)
+	}
+	if got := req.Get("tools.0.name").String(); got != "get_weather" {
+		t.Errorf("tool = %q", got)
+	}
+	if got := req.Get("max_tokens").Int(); got != 256 {
+		t.Errorf("max_tokens = %d, want 256", got)
+	}
+	if !strings.Contains(req.Get("system").Raw, "Be brief.") {
+		t.Errorf("system = %s", req.Get("system").Raw)
+	}
+
+	upstream := `{"id":"msg_1","type":"message","role":"assistant","model":"claude-sonnet-4-5","content":[{"type":"text","text":"Checking."},{"type":"tool_use","id":"toolu_1","name":"get_weather","input":{"city":"Paris"}}],"stop_reason":"tool_use","usage":{"input_tokens":30,"output_tokens":12}}`
+	out, err := TranslateResponseNonStream(nil, provider.FromString("claude"), from, []byte(upstream), "claude-sonnet-4-5")
+	if err != nil {
+		t.Fatalf("TranslateResponseNonStream: %v", err)
+	}
+	cand := gjson.GetBytes(out, "candidates.0")
+	if cand.Get("content.role").String() != "model" || cand.Get("finishReason").String() != "STOP" {
+		t.Errorf("candidate = %s", cand.Raw)
+	}
+	if got := cand.Get("content.parts.0.text").String(); got != "Checking." {
+		t.Errorf("text = %q", got)
+	}
+	call := cand.Get("content.parts.1.functionCall")
+	if call.Get("name").String() != "get_weather" || call.Get("args.city").String() != "Paris" {
+		t.Errorf("functionCall = %s", call.Raw)
+	}
+	um := gjson.GetBytes(out, "usageMetadata")
+	if um.Get("promptTokenCount").Int() != 30 || um.Get("candidatesTokenCount").Int() != 12 || um.Get("totalTokenCount").Int() != 42 {
+		t.Errorf("usageMetadata = %s", um.Raw)
+	}
+}
+
+func TestGeminiAPI_NonStreamMaxTokens(t *testing.T) {
+	upstream := `{"id":"msg_1","type":"message","role":"assistant","model":"claude-sonnet-4-5","content":[{"type":"text","text":"Trunc"}],"stop_reason":"max_tokens","usage":{"input_tokens":3,"output_tokens":5}}`
+	out, err := TranslateResponseNonStream(nil, provider.FromString("claude"), provider.FromString("gemini"), []byte(upstream), "claude-sonnet-4-5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := gjson.GetBytes(out, "candidates.0.finishReason").String(); got != "MAX_TOKENS" {
+		t.Errorf("finishReason = %q, want MAX_TOKENS", got)
+	}
+}
+
+func TestGeminiAPI_StreamFromClaude(t *testing.T) {
+	st := NewStreamTranslator(nil, provider.FromString("gemini"), "gemini", "claude-sonnet-4-5", "msg_1", NewStreamContext())
+	state := ir.NewClaudeStreamParserState()
+	var chunks []gjson.Result
+	for _, line := range []string{
+		`data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+		`data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Check"}}`,
+		`data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"ing."}}`,
+		`data: {"type":"content_block_stop","index":0}`,
+		`data: {"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"toolu_1","name":"get_weather","input":{}}}`,
+		`data: {"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"{\"city\":"}}`,
+		`data: {"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"\"Paris\"}"}}`,
+		`data: {"type":"content_block_stop","index":1}`,
+		`data: {"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"input_tokens":30,"output_tokens":12}}`,
+		`data: {"type":"message_stop"}`,
+	} {
+		events, err := to_ir.ParseClaudeChunkWithState([]byte(line), state)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := st.Translate(events)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, c := range result.Chunks {
+			chunks = append(chunks, gjson.ParseBytes(c))
+		}
+	}
+	for _, c := range st.Flush() {
+		chunks = append(chunks, gjson.ParseBytes(c))
+	}
+	if len(chunks) == 0 {
+		t.Fatal("no chunks emitted")
+	}
+
+	var text string
+	var call gjson.Result
+	for _, c := range chunks {
+		if !c.Get("candidates").IsArray() {
+			t.Errorf("chunk without candidates: %s", c.Raw)
+		}
+		for _, part := range c.Get("candidates.0.content.parts").Array() {
+			text += part.Get("text").String()
+			if fc := part.Get("functionCall"); fc.Exists() {
+				call = fc
+			}
+		}
+	}
+	if text != "Checking." {
+		t.Errorf("streamed text = %q", text)
+	}
+	if call.Get("name").String() != "get_weather" || call.Get("args.city").String() != "Paris" {
+		t.Errorf("functionCall = %s", call.Raw)
+	}
+	last := chunks[len(chunks)-1]
+	if got := last.Get("candidates.0.finishReason").String(); got != "STOP" {
+		t.Errorf("finishReason = %q, want STOP", got)
+	}
+	if got := last.Get("usageMetadata.totalTokenCount").Int(); got != 42 {
+		t.Errorf("totalTokenCount = %d, want 42", got)
+	}
+}
@@ -1,14 +1,16 @@
 package executor
 
 import (
-	"bytes"
 	"context"
-	"errors"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"github.com/nghyane/llm-mux/internal/json"
 	"io"
 	"net/http"
+	"os"
 	"strings"
+	"sync"
 
 	vertexauth "github.com/nghyane/llm-mux/internal/auth/vertex"
 	"github.com/nghyane/llm-mux/internal/config"
@@ -36,7 +38,11 @@ type VertexAuthStrategy interface {
 type serviceAccountStrategy struct {
 	projectID string
 	location  string
+	// locations are the regions requests fail over through, primary first.
+	locations []string
 	saJSON    []byte
+	// baseURL replaces the regional endpoint when set.
+	baseURL string
 }
 
 func (s *serviceAccountStrategy) GetToken(ctx context.Context, cfg *config.Config, auth *provider.Auth) (string, error) {
@@ -44,7 +50,10 @@ func (s *serviceAccountStrategy) GetToken(ctx context.Context, cfg *config.Confi
 }
 
 func (s *serviceAccountStrategy) BuildURL(model, action string, opts provider.Options) string {
-	baseURL := vertexBaseURL(s.location)
+	baseURL := s.baseURL
+	if baseURL == "" {
+		baseURL = vertexBaseURL(s.location)
+	}
 	ub := GetURLBuilder()
 	defer ub.Release()
 	ub.Grow(150)
@@ -123,7 +132,7 @@ func (e *GeminiVertexExecutor) resolveStrategy(auth *provider.Auth) (VertexAuthS
 	if err != nil {
 		return nil, err
 	}
-	return &serviceAccountStrategy{projectID: projectID, location: location, saJSON: saJSON}, nil
+	return &serviceAccountStrategy{projectID: projectID, location: location, locations: vertexLocations(auth, location), saJSON: saJSON, baseURL: baseURL}, nil
 }
 
 func (e *GeminiVertexExecutor) Execute(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (provider.Response, error) {
@@ -152,45 +161,25 @@ func (e *GeminiVertexExecutor) executeWithStrategy(ctx context.Context, auth *pr
 		}
 	}
 
-	url := strategy.BuildURL(req.Model, action, opts)
-	if opts.Alt != "" && action != "countTokens" {
-		url = url + "?$alt=" + opts.Alt
-	}
 	if _, ok := strategy.(*apiKeyStrategy); ok {
 		body, _ = sjson.DeleteBytes(body, "session_id")
 	}
 
-	httpReq, errNewReq := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
-	if errNewReq != nil {
-		return resp, errNewReq
-	}
-	httpReq.Header.Set("Content-Type", "application/json")
-
-	token, errTok := strategy.GetToken(ctx, e.cfg, auth)
-	if errTok != nil {
-		log.Errorf("vertex executor: access token error: %v", errTok)
-		return resp, NewStatusError(500, "internal server error", nil)
-	}
-	strategy.ApplyAuth(httpReq, token)
-	applyGeminiHeaders(httpReq, auth)
-
-	httpClient := newProxyAwareHTTPClient(ctx, e.cfg, auth, 0)
-	httpResp, errDo := httpClient.Do(httpReq)
-	if errDo != nil {
-		if errors.Is(errDo, context.DeadlineExceeded) {
-			return resp, NewTimeoutError("request timed out")
+	httpResp, err := e.doVertexRequest(ctx, auth, strategy, body, func(s VertexAuthStrategy) string {
+		url := s.BuildURL(req.Model, action, opts)
+		if opts.Alt != "" && action != "countTokens" {
+			url = url + "?$alt=" + opts.Alt
 		}
-		return resp, errDo
+		return url
+	})
+	if err != nil {
+		return resp, err
 	}
 	defer func() {
 		if errClose := httpResp.Body.Close(); errClose != nil {
 			log.Errorf("vertex executor: close response body error: %v", errClose)
 		}
 	}()
-	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
-		result := HandleHTTPError(httpResp, "gemini-vertex executor")
-		return resp, result.Error
-	}
 	data, errRead := io.ReadAll(httpResp.Body)
 	if errRead != nil {
 		return resp, errRead
@@ -230,43 +219,21 @@ func (e *GeminiVertexExecutor) executeStreamWithStrategy(ctx context.Context, au
 	body := translation.Payload
 	body = util.StripThinkingConfigIfUnsupported(req.Model, body)
 
-	url := strategy.BuildURL(req.Model, "streamGenerateContent", opts)
-	if opts.Alt == "" {
-		url = url + "?alt=sse"
-	} else {
-		url = url + "?$alt=" + opts.Alt
-	}
 	body, _ = sjson.DeleteBytes(body, "session_id")
 
-	httpReq, errNewReq := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
-	if errNewReq != nil {
-		return nil, errNewReq
-	}
-	httpReq.Header.Set("Content-Type", "application/json")
-
-	token, errTok := strategy.GetToken(ctx, e.cfg, auth)
-	if errTok != nil {
-		log.Errorf("vertex executor: access token error: %v", errTok)
-		return nil, NewStatusError(500, "internal server error", nil)
-	}
-	strategy.ApplyAuth(httpReq, token)
-	applyGeminiHeaders(httpReq, auth)
-
-	httpClient := newProxyAwareHTTPClient(ctx, e.cfg, auth, 0)
-	httpResp, errDo := httpClient.Do(httpReq)
-	if errDo != nil {
-		if errors.Is(errDo, context.DeadlineExceeded) {
-			return nil, NewTimeoutError("request timed out")
+	httpResp, err := e.doVertexRequest(ctx, auth, strategy, body, func(s VertexAuthStrategy) string {
+		url := s.BuildURL(req.Model, "streamGenerateContent", opts)
+		if opts.Alt == "" {
+			return url + "?alt=sse"
 		}
-		return nil, errDo
-	}
-	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
-		result := HandleHTTPError(httpResp, "gemini-vertex executor")
-		_ = httpResp.Body.Close()
-		return nil, result.Error
+		return url + "?$alt=" + opts.Alt
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	streamCtx := NewStreamContext()
+	streamCtx.RequestUsage(opts.OriginalRequest)
 	streamCtx.EstimatedInputTokens = translation.EstimatedInputTokens
 	translator := NewStreamTranslator(e.cfg, from, from.String(), req.Model, "chatcmpl-"+req.Model, streamCtx)
 	processor := &vertexStreamProcessor{
@@ -299,39 +266,17 @@ func (e *GeminiVertexExecutor) countTokensWithStrategy(ctx context.Context, auth
 	translatedReq, _ = sjson.DeleteBytes(translatedReq, "generationConfig")
 	translatedReq, _ = sjson.DeleteBytes(translatedReq, "safetySettings")
 
-	url := strategy.BuildURL(req.Model, "countTokens", opts)
-
-	httpReq, errNewReq := http.NewRequestWithContext(respCtx, http.MethodPost, url, bytes.NewReader(translatedReq))
-	if errNewReq != nil {
-		return provider.Response{}, errNewReq
-	}
-	httpReq.Header.Set("Content-Type", "application/json")
-
-	token, errTok := strategy.GetToken(ctx, e.cfg, auth)
-	if errTok != nil {
-		log.Errorf("vertex executor: access token error: %v", errTok)
-		return provider.Response{}, NewStatusError(500, "internal server error", nil)
-	}
-	strategy.ApplyAuth(httpReq, token)
-	applyGeminiHeaders(httpReq, auth)
-
-	httpClient := newProxyAwareHTTPClient(ctx, e.cfg, auth, 0)
-	httpResp, errDo := httpClient.Do(httpReq)
-	if errDo != nil {
-		if errors.Is(errDo, context.DeadlineExceeded) {
-			return provider.Response{}, NewTimeoutError("request timed out")
-		}
-		return provider.Response{}, errDo
+	httpResp, err := e.doVertexRequest(respCtx, auth, strategy, translatedReq, func(s VertexAuthStrategy) string {
+		return s.BuildURL(req.Model, "countTokens", opts)
+	})
+	if err != nil {
+		return provider.Response{}, err
 	}
 	defer func() {
 		if errClose := httpResp.Body.Close(); errClose != nil {
 			log.Errorf("vertex executor: close response body error: %v", errClose)
 		}
 	}()
-	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
-		result := HandleHTTPError(httpResp, "gemini-vertex executor")
-		return provider.Response{}, result.Error
-	}
 	data, errRead := io.ReadAll(httpResp.Body)
 	if errRead != nil {
 		return provider.Response{}, errRead
@@ -373,32 +318,47 @@ func (p *vertexStreamProcessor) ProcessDone() ([][]byte, error) {
 	return p.translator.Flush(), nil
 }
 
+// vertexCreds returns the project, region and service account JSON for a.
+// Attributes, set for vertex providers in the config, take precedence over
+// the metadata of imported credential files. A nil serviceAccountJSON means
+// Application Default Credentials are used.
 func vertexCreds(a *provider.Auth) (projectID, location string, serviceAccountJSON []byte, err error) {
-	if a == nil || a.Metadata == nil {
-		return "", "", nil, fmt.Errorf("vertex executor: missing auth metadata")
-	}
-	if v, ok := a.Metadata["project_id"].(string); ok {
-		projectID = strings.TrimSpace(v)
+	if a == nil {
+		return "", "", nil, fmt.Errorf("vertex executor: missing auth")
 	}
+	projectID = AttrStringValue(a.Attributes, "project_id")
 	if projectID == "" {
-		if v, ok := a.Metadata["project"].(string); ok {
-			projectID = strings.TrimSpace(v)
-		}
+		projectID = strings.TrimSpace(getMetaString(a.Metadata, "project_id", "project"))
 	}
 	if projectID == "" {
 		return "", "", nil, fmt.Errorf("vertex executor: missing project_id in credentials")
 	}
-	if v, ok := a.Metadata["location"].(string); ok && strings.TrimSpace(v) != "" {
-		location = strings.TrimSpace(v)
-	} else {
+	location = AttrStringValue(a.Attributes, "location")
+	if location == "" {
+		location = strings.TrimSpace(getMetaString(a.Metadata, "location"))
+	}
+	if location == "" {
 		location = "us-central1"
 	}
+
 	var sa map[string]any
-	if raw, ok := a.Metadata["service_account"].(map[string]any); ok {
+	if path := AttrStringValue(a.Attributes, "credentials_file"); path != "" {
+		data, errRead := os.ReadFile(path)
+		if errRead != nil {
+			return "", "", nil, fmt.Errorf("vertex executor: read credentials file failed: %w", errRead)
+		}
+		if errUnmarshal := json.Unmarshal(data, &sa); errUnmarshal != nil {
+			return "", "", nil, fmt.Errorf("vertex executor: invalid credentials file: %w", errUnmarshal)
+		}
+	} else if raw, ok := a.Metadata["service_account"].(map[string]any); ok {
 		sa = raw
 	}
 	if sa == nil {
-		return "", "", nil, fmt.Errorf("vertex executor: missing service_account in credentials")
+		if a.Metadata != nil {
+			// Imported credential files always embed their service account.
+			return "", "", nil, fmt.Errorf("vertex executor: missing service_account in credentials")
+		}
+		return projectID, location, nil, nil
 	}
 	normalized, errNorm := vertexauth.NormalizeServiceAccountMap(sa)
 	if errNorm != nil {
@@ -417,8 +377,8 @@ func vertexAPICreds(a *provider.Auth) (apiKey, baseURL string) {
 	}
 	if a.Attributes != nil {
 		apiKey = a.Attributes["api_key"]
-		baseURL = a.Attributes["base_url"]
 	}
+	baseURL = BaseURLOverride(a)
 	if apiKey == "" && a.Metadata != nil {
 		if v, ok := a.Metadata["access_token"].(string); ok {
 			apiKey = v
@@ -441,21 +401,54 @@ func vertexBaseURL(location string) string {
 	return ub.String()
 }
 
+const vertexScope = "https://www.googleapis.com/auth/cloud-platform"
+
+// vertexTokenSources caches a token source per credential so access tokens
+// are reused across requests and refreshed shortly before they expire.
+var vertexTokenSources sync.Map // map[string]oauth2.TokenSource
+
 func vertexAccessToken(ctx context.Context, cfg *config.Config, auth *provider.Auth, saJSON []byte) (string, error) {
-	if httpClient := newProxyAwareHTTPClient(ctx, cfg, auth, 0); httpClient != nil {
-		ctx = context.WithValue(ctx, oauth2.HTTPClient, httpClient)
-	}
-	creds, errCreds := google.CredentialsFromJSON(ctx, saJSON, "https://www.googleapis.com/auth/cloud-platform")
-	if errCreds != nil {
-		return "", fmt.Errorf("vertex executor: parse service account json failed: %w", errCreds)
+	key := vertexTokenCacheKey(auth, saJSON)
+	src, ok := vertexTokenSources.Load(key)
+	if !ok {
+		// The token source outlives this request, so it must not hold ctx.
+		tokenCtx := context.Background()
+		if httpClient := newProxyAwareHTTPClient(tokenCtx, cfg, auth, 0); httpClient != nil {
+			tokenCtx = context.WithValue(tokenCtx, oauth2.HTTPClient, httpClient)
+		}
+		var creds *google.Credentials
+		var errCreds error
+		if saJSON == nil {
+			creds, errCreds = google.FindDefaultCredentials(tokenCtx, vertexScope)
+		} else {
+			creds, errCreds = google.CredentialsFromJSON(tokenCtx, saJSON, vertexScope)
+		}
+		if errCreds != nil {
+			return "", fmt.Errorf("vertex executor: load credentials failed: %w", errCreds)
+		}
+		src, _ = vertexTokenSources.LoadOrStore(key, oauth2.ReuseTokenSourceWithExpiry(nil, creds.TokenSource, TokenExpiryBuffer))
 	}
-	tok, errTok := creds.TokenSource.Token()
+	tok, errTok := src.(oauth2.TokenSource).Token()
 	if errTok != nil {
 		return "", fmt.Errorf("vertex executor: get access token failed: %w", errTok)
 	}
 	return tok.AccessToken, nil
 }
 
+// vertexTokenCacheKey identifies a credential and the proxy its tokens are
+// fetched through. Application Default Credentials share one entry.
+func vertexTokenCacheKey(auth *provider.Auth, saJSON []byte) string {
+	proxy := ""
+	if auth != nil {
+		proxy = auth.ProxyURL
+	}
+	if saJSON == nil {
+		return "adc|" + proxy
+	}
+	sum := sha256.Sum256(saJSON)
+	return hex.EncodeToString(sum[:]) + "|" + proxy
+}
+
 func FetchVertexModels(ctx context.Context, auth *provider.Auth, cfg *config.Config) []*registry.ModelInfo {
 	exec := &GeminiVertexExecutor{cfg: cfg}
 	strategy, err := exec.resolveStrategy(auth)
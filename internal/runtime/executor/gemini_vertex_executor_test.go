@@ -0,0 +1,159 @@
+package executor
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/json"
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+// writeServiceAccount writes a service account key whose token_uri points at
+// tokenURL and returns its path.
+func writeServiceAccount(t *testing.T, tokenURL string) string {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	pemKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	data, _ := json.Marshal(map[string]any{
+		"type":           "service_account",
+		"project_id":     "proj",
+		"private_key_id": "k1",
+		"private_key":    string(pemKey),
+		"client_email":   "sa@proj.iam.gserviceaccount.com",
+		"token_uri":      tokenURL,
+	})
+	path := filepath.Join(t.TempDir(), "sa.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("write key: %v", err)
+	}
+	return path
+}
+
+func TestVertexAccessToken_CachedAcrossRequests(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"ya29.token","token_type":"Bearer","expires_in":3600}`))
+	}))
+	defer srv.Close()
+
+	auth := &provider.Auth{Provider: "vertex", Attributes: map[string]string{
+		"project_id":       "proj",
+		"location":         "europe-west4",
+		"credentials_file": writeServiceAccount(t, srv.URL),
+	}}
+	projectID, location, saJSON, err := vertexCreds(auth)
+	if err != nil {
+		t.Fatalf("vertexCreds: %v", err)
+	}
+	if projectID != "proj" || location != "europe-west4" || saJSON == nil {
+		t.Fatalf("creds = %q %q %d bytes", projectID, location, len(saJSON))
+	}
+
+	for i := 0; i < 3; i++ {
+		token, err := vertexAccessToken(context.Background(), &config.Config{}, auth, saJSON)
+		if err != nil {
+			t.Fatalf("vertexAccessToken: %v", err)
+		}
+		if token != "ya29.token" {
+			t.Errorf("token = %q", token)
+		}
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("token endpoint called %d times, want 1", n)
+	}
+}
+
+func TestVertexCreds_ConfigWithoutKeyUsesADC(t *testing.T) {
+	auth := &provider.Auth{Provider: "vertex", Attributes: map[string]string{"project_id": "proj"}}
+	projectID, location, saJSON, err := vertexCreds(auth)
+	if err != nil {
+		t.Fatalf("vertexCreds: %v", err)
+	}
+	if projectID != "proj" || location != "us-central1" || saJSON != nil {
+		t.Errorf("creds = %q %q %s, want ADC with the default region", projectID, location, saJSON)
+	}
+
+	imported := &provider.Auth{Provider: "vertex", Metadata: map[string]any{"project_id": "proj"}}
+	if _, _, _, err := vertexCreds(imported); err == nil {
+		t.Error("imported credential without service_account accepted")
+	}
+}
+
+func TestVertexRequest_FailsOverToNextRegion(t *testing.T) {
+	var mu sync.Mutex
+	var hits []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if !strings.HasPrefix(r.URL.Path, "/v1/projects/") {
+			_, _ = w.Write([]byte(`{"access_token":"ya29.token","token_type":"Bearer","expires_in":3600}`))
+			return
+		}
+		region := strings.Split(r.URL.Path, "/")[5]
+		mu.Lock()
+		hits = append(hits, region)
+		mu.Unlock()
+		if region == "us-central1" {
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"error":{"code":429,"message":"quota exhausted","status":"RESOURCE_EXHAUSTED"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"candidates":[]}`))
+	}))
+	defer srv.Close()
+
+	auth := &provider.Auth{ID: "vertex-regions", Provider: "vertex", Attributes: map[string]string{
+		"project_id":       "proj",
+		"location":         "us-central1",
+		"locations":        "us-central1, europe-west4",
+		"credentials_file": writeServiceAccount(t, srv.URL),
+		"base_url":         srv.URL,
+	}}
+	e := NewGeminiVertexExecutor(&config.Config{})
+	strategy, err := e.resolveStrategy(auth)
+	if err != nil {
+		t.Fatalf("resolveStrategy: %v", err)
+	}
+	send := func() {
+		t.Helper()
+		resp, err := e.doVertexRequest(context.Background(), auth, strategy, []byte(`{}`), func(s VertexAuthStrategy) string {
+			return s.BuildURL("gemini-2.5-pro", "generateContent", provider.Options{})
+		})
+		if err != nil {
+			t.Fatalf("doVertexRequest: %v", err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("status = %d, want 200", resp.StatusCode)
+		}
+	}
+
+	send()
+	if want := []string{"us-central1", "europe-west4"}; strings.Join(hits, ",") != strings.Join(want, ",") {
+		t.Fatalf("regions tried = %v, want %v", hits, want)
+	}
+
+	// The throttled region is cooling down, so the next request starts in
+	// the healthy one.
+	hits = nil
+	send()
+	if len(hits) != 1 || hits[0] != "europe-west4" {
+		t.Errorf("regions tried after failover = %v, want [europe-west4]", hits)
+	}
+}
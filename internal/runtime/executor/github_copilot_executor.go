@@ -18,16 +18,25 @@ import (
 	"golang.org/x/sync/singleflight"
 )
 
+// GitHubCopilotExecutor calls the Copilot chat API. Requests authenticate with
+// a short-lived Copilot API token exchanged from the account's long-lived
+// GitHub token; the API token is cached per GitHub token and exchanged again
+// shortly before it expires, with concurrent requests sharing one exchange.
 type GitHubCopilotExecutor struct {
 	cfg     *config.Config
 	mu      sync.RWMutex
 	cache   map[string]*cachedCopilotToken
 	sfGroup singleflight.Group
+
+	// fetch exchanges a GitHub token for a Copilot API token; nil uses the
+	// Copilot token endpoint.
+	fetch func(ctx context.Context, accessToken string) (*copilotauth.CopilotAPIToken, error)
+	now   func() time.Time
 }
 
 type cachedCopilotToken struct {
 	token     string
-	expiresAt time.Time
+	refreshAt time.Time
 }
 
 func NewGitHubCopilotExecutor(cfg *config.Config) *GitHubCopilotExecutor {
@@ -55,7 +64,7 @@ func (e *GitHubCopilotExecutor) Execute(ctx context.Context, auth *provider.Auth
 	body = applyPayloadConfig(e.cfg, req.Model, body)
 	body, _ = sjson.SetBytes(body, "stream", false)
 
-	url := GitHubCopilotDefaultBaseURL + GitHubCopilotChatPath
+	url := copilotBaseURL(auth) + GitHubCopilotChatPath
 	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
 	if err != nil {
 		return resp, err
@@ -73,6 +82,9 @@ func (e *GitHubCopilotExecutor) Execute(ctx context.Context, auth *provider.Auth
 	defer func() { _ = httpResp.Body.Close() }()
 
 	if !isHTTPSuccessCode(httpResp.StatusCode) {
+		if httpResp.StatusCode == http.StatusUnauthorized {
+			e.invalidateAPIToken(auth)
+		}
 		result := HandleHTTPError(httpResp, "github-copilot executor")
 		return resp, result.Error
 	}
@@ -119,7 +131,7 @@ func (e *GitHubCopilotExecutor) ExecuteStream(ctx context.Context, auth *provide
 	body, _ = sjson.SetBytes(body, "stream", true)
 	body, _ = sjson.SetBytes(body, "stream_options.include_usage", true)
 
-	url := GitHubCopilotDefaultBaseURL + GitHubCopilotChatPath
+	url := copilotBaseURL(auth) + GitHubCopilotChatPath
 	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
 	if err != nil {
 		return nil, err
@@ -136,6 +148,9 @@ func (e *GitHubCopilotExecutor) ExecuteStream(ctx context.Context, auth *provide
 	}
 
 	if !isHTTPSuccessCode(httpResp.StatusCode) {
+		if httpResp.StatusCode == http.StatusUnauthorized {
+			e.invalidateAPIToken(auth)
+		}
 		result := HandleHTTPError(httpResp, "github-copilot executor")
 		_ = httpResp.Body.Close()
 		return nil, result.Error
@@ -143,6 +158,7 @@ func (e *GitHubCopilotExecutor) ExecuteStream(ctx context.Context, auth *provide
 
 	messageID := uuid.NewString()
 	processor := NewOpenAIStreamProcessor(e.cfg, from, req.Model, messageID)
+	processor.ctx.RequestUsage(opts.OriginalRequest)
 
 	return RunSSEStream(ctx, httpResp.Body, reporter, processor, StreamConfig{
 		ExecutorName:    "github-copilot executor",
@@ -160,20 +176,68 @@ func (e *GitHubCopilotExecutor) Refresh(ctx context.Context, auth *provider.Auth
 		return nil, NewStatusError(http.StatusUnauthorized, "missing auth", nil)
 	}
 
-	accessToken := MetaStringValue(auth.Metadata, "access_token")
-	if accessToken == "" {
+	if MetaStringValue(auth.Metadata, "access_token") == "" {
 		return auth, nil
 	}
 
-	copilotAuth := copilotauth.NewCopilotAuth(e.cfg)
-	_, err := copilotAuth.GetCopilotAPIToken(ctx, accessToken)
-	if err != nil {
+	// Validating through the cache spares requests a second exchange.
+	if _, err := e.ensureAPIToken(ctx, auth); err != nil {
 		return nil, NewStatusError(http.StatusUnauthorized, fmt.Sprintf("github-copilot token validation failed: %v", err), nil)
 	}
 
 	return auth, nil
 }
 
+func (e *GitHubCopilotExecutor) clock() time.Time {
+	if e.now != nil {
+		return e.now()
+	}
+	return time.Now()
+}
+
+func (e *GitHubCopilotExecutor) fetchAPIToken(ctx context.Context, accessToken string) (*copilotauth.CopilotAPIToken, error) {
+	if e.fetch != nil {
+		return e.fetch(ctx, accessToken)
+	}
+	return copilotauth.NewCopilotAuth(e.cfg).GetCopilotAPIToken(ctx, accessToken)
+}
+
+// cachedAPIToken returns the cached API token for accessToken while it is
+// not yet due for refresh.
+func (e *GitHubCopilotExecutor) cachedAPIToken(accessToken string) (string, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if cached, ok := e.cache[accessToken]; ok && e.clock().Before(cached.refreshAt) {
+		return cached.token, true
+	}
+	return "", false
+}
+
+// invalidateAPIToken drops the cached API token for auth, so the next request
+// exchanges a new one. It is called when Copilot rejects the token.
+func (e *GitHubCopilotExecutor) invalidateAPIToken(auth *provider.Auth) {
+	e.mu.Lock()
+	delete(e.cache, MetaStringValue(auth.Metadata, "access_token"))
+	e.mu.Unlock()
+}
+
+// copilotTokenRefreshAt returns when a token fetched at now should be
+// replaced: TokenExpiryBuffer before it expires, or earlier when GitHub
+// suggests so with refresh_in.
+func copilotTokenRefreshAt(token *copilotauth.CopilotAPIToken, now time.Time) time.Time {
+	expiresAt := now.Add(GitHubCopilotTokenCacheTTL)
+	if token.ExpiresAt > 0 {
+		expiresAt = time.Unix(token.ExpiresAt, 0)
+	}
+	refreshAt := expiresAt.Add(-TokenExpiryBuffer)
+	if token.RefreshIn > 0 {
+		if suggested := now.Add(time.Duration(token.RefreshIn) * time.Second); suggested.Before(refreshAt) {
+			refreshAt = suggested
+		}
+	}
+	return refreshAt
+}
+
 func (e *GitHubCopilotExecutor) ensureAPIToken(ctx context.Context, auth *provider.Auth) (string, error) {
 	if auth == nil {
 		return "", NewStatusError(http.StatusUnauthorized, "missing auth", nil)
@@ -184,35 +248,26 @@ func (e *GitHubCopilotExecutor) ensureAPIToken(ctx context.Context, auth *provid
 		return "", NewStatusError(http.StatusUnauthorized, "missing github access token", nil)
 	}
 
-	e.mu.RLock()
-	if cached, ok := e.cache[accessToken]; ok && cached.expiresAt.After(time.Now().Add(TokenExpiryBuffer)) {
-		e.mu.RUnlock()
-		return cached.token, nil
+	if token, ok := e.cachedAPIToken(accessToken); ok {
+		return token, nil
 	}
-	e.mu.RUnlock()
 
 	result, err, _ := e.sfGroup.Do(accessToken, func() (interface{}, error) {
-		e.mu.RLock()
-		if cached, ok := e.cache[accessToken]; ok && cached.expiresAt.After(time.Now().Add(TokenExpiryBuffer)) {
-			e.mu.RUnlock()
-			return cached.token, nil
+		if token, ok := e.cachedAPIToken(accessToken); ok {
+			return token, nil
 		}
-		e.mu.RUnlock()
 
-		copilotAuth := copilotauth.NewCopilotAuth(e.cfg)
-		apiToken, err := copilotAuth.GetCopilotAPIToken(ctx, accessToken)
+		// The exchange is shared by every waiting request, so one caller
+		// going away must not fail it for the rest.
+		apiToken, err := e.fetchAPIToken(context.WithoutCancel(ctx), accessToken)
 		if err != nil {
 			return "", NewStatusError(http.StatusUnauthorized, fmt.Sprintf("failed to get copilot api token: %v", err), nil)
 		}
 
-		expiresAt := time.Now().Add(GitHubCopilotTokenCacheTTL)
-		if apiToken.ExpiresAt > 0 {
-			expiresAt = time.Unix(apiToken.ExpiresAt, 0)
-		}
 		e.mu.Lock()
 		e.cache[accessToken] = &cachedCopilotToken{
 			token:     apiToken.Token,
-			expiresAt: expiresAt,
+			refreshAt: copilotTokenRefreshAt(apiToken, e.clock()),
 		}
 		e.mu.Unlock()
 
@@ -225,6 +280,15 @@ func (e *GitHubCopilotExecutor) ensureAPIToken(ctx context.Context, auth *provid
 	return result.(string), nil
 }
 
+// copilotBaseURL returns the Copilot API base for auth, honouring a base_url
+// override.
+func copilotBaseURL(auth *provider.Auth) string {
+	if base := BaseURLOverride(auth); base != "" {
+		return base
+	}
+	return GitHubCopilotDefaultBaseURL
+}
+
 func applyCopilotHeaders(r *http.Request, apiToken string, stream bool) {
 	ApplyAPIHeaders(r, HeaderConfig{
 		Token:     apiToken,
@@ -0,0 +1,92 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	copilotauth "github.com/nghyane/llm-mux/internal/auth/copilot"
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+func TestGitHubCopilotExecutor_APITokenCache(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	var fetches atomic.Int32
+	release := make(chan struct{})
+	e := NewGitHubCopilotExecutor(nil)
+	e.now = func() time.Time { return now }
+	e.fetch = func(_ context.Context, accessToken string) (*copilotauth.CopilotAPIToken, error) {
+		<-release
+		n := fetches.Add(1)
+		return &copilotauth.CopilotAPIToken{
+			Token:     fmt.Sprintf("%s-api-%d", accessToken, n),
+			ExpiresAt: now.Add(30 * time.Minute).Unix(),
+			RefreshIn: 1500,
+		}, nil
+	}
+	auth := &provider.Auth{ID: "copilot-1", Metadata: map[string]any{"access_token": "gh"}}
+
+	// Concurrent requests wait on one exchange.
+	var wg sync.WaitGroup
+	tokens := make([]string, 8)
+	for i := range tokens {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			token, err := e.ensureAPIToken(context.Background(), auth)
+			if err != nil {
+				t.Error(err)
+			}
+			tokens[i] = token
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if got := fetches.Load(); got != 1 {
+		t.Fatalf("concurrent requests made %d exchanges, want 1", got)
+	}
+	for _, token := range tokens {
+		if token != "gh-api-1" {
+			t.Fatalf("got token %q, want gh-api-1", token)
+		}
+	}
+
+	// Reused within its lifetime, refreshed once refresh_in has passed.
+	now = now.Add(20 * time.Minute)
+	if token, _ := e.ensureAPIToken(context.Background(), auth); token != "gh-api-1" || fetches.Load() != 1 {
+		t.Fatalf("token within its lifetime: %q after %d exchanges", token, fetches.Load())
+	}
+	now = now.Add(6 * time.Minute)
+	if token, _ := e.ensureAPIToken(context.Background(), auth); token != "gh-api-2" {
+		t.Fatalf("token due for refresh: got %q, want gh-api-2", token)
+	}
+
+	// A token Copilot rejected is exchanged again on the next request.
+	e.invalidateAPIToken(auth)
+	if token, _ := e.ensureAPIToken(context.Background(), auth); token != "gh-api-3" {
+		t.Fatalf("token after invalidation: got %q, want gh-api-3", token)
+	}
+}
+
+func TestCopilotTokenRefreshAt(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	tests := []struct {
+		name  string
+		token copilotauth.CopilotAPIToken
+		want  time.Time
+	}{
+		{"expiry only", copilotauth.CopilotAPIToken{ExpiresAt: now.Add(30 * time.Minute).Unix()}, now.Add(25 * time.Minute)},
+		{"refresh_in earlier", copilotauth.CopilotAPIToken{ExpiresAt: now.Add(30 * time.Minute).Unix(), RefreshIn: 600}, now.Add(10 * time.Minute)},
+		{"refresh_in later", copilotauth.CopilotAPIToken{ExpiresAt: now.Add(30 * time.Minute).Unix(), RefreshIn: 1790}, now.Add(25 * time.Minute)},
+		{"no expiry", copilotauth.CopilotAPIToken{}, now.Add(GitHubCopilotTokenCacheTTL - TokenExpiryBuffer)},
+	}
+	for _, tt := range tests {
+		if got := copilotTokenRefreshAt(&tt.token, now); !got.Equal(tt.want) {
+			t.Errorf("%s: refresh at %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/auth/iflow"
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+// ErrProbeUnsupported is returned when a provider has no HTTP endpoint to probe.
+var ErrProbeUnsupported = errors.New("health probe not supported for provider")
+
+const healthProbeTimeout = 5 * time.Second
+
+// probeBaseURLs lists the upstream hosts probed for providers whose credentials
+// do not carry a base URL.
+var probeBaseURLs = map[string]string{
+	"claude":         ClaudeDefaultBaseURL,
+	"codex":          CodexDefaultBaseURL,
+	"qwen":           QwenDefaultBaseURL,
+	"cline":          ClineDefaultBaseURL,
+	"gemini":         GeminiDefaultBaseURL,
+	"gemini-cli":     codeAssistEndpoint,
+	"antigravity":    AntigravityBaseURLProd,
+	"vertex":         "https://aiplatform.googleapis.com",
+	"github-copilot": GitHubCopilotDefaultBaseURL,
+	"kiro":           KiroDefaultBaseURL,
+	"iflow":          iflow.DefaultAPIBaseURL,
+}
+
+// ProbeUpstream performs a lightweight reachability check against the upstream
+// serving auth, honouring the account's proxy settings. Any HTTP response below
+// 500 counts as reachable; authentication is not exercised.
+func ProbeUpstream(ctx context.Context, cfg *config.Config, auth *provider.Auth) error {
+	if auth == nil {
+		return fmt.Errorf("auth is nil")
+	}
+	target := BaseURLOverride(auth)
+	if target == "" {
+		target = probeBaseURLs[strings.ToLower(auth.Provider)]
+	}
+	if target == "" {
+		return ErrProbeUnsupported
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, healthProbeTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := newProxyAwareHTTPClient(ctx, cfg, auth, healthProbeTimeout).Do(req)
+	if err != nil {
+		return err
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	_ = resp.Body.Close()
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("upstream returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// credentialCheck describes the authenticated models listing used to check an
+// API key: the endpoint relative to the base URL and how the key is sent.
+type credentialCheck struct {
+	baseURL string
+	path    string
+	header  string
+	prefix  string
+	extra   map[string]string
+}
+
+// credentialChecks lists API-key providers whose key format differs from the
+// OpenAI convention. Other providers with a base URL are checked with a
+// bearer token against {base_url}/models.
+var credentialChecks = map[string]credentialCheck{
+	"claude":  {baseURL: ClaudeDefaultBaseURL, path: "/v1/models", header: "x-api-key", extra: map[string]string{"Anthropic-Version": "2023-06-01"}},
+	"gemini":  {baseURL: GeminiDefaultBaseURL, path: "/v1beta/models", header: "x-goog-api-key"},
+	"cohere":  {baseURL: CohereDefaultBaseURL, path: "/v1/models", header: "Authorization", prefix: "Bearer "},
+	"xai":     {baseURL: XAIDefaultBaseURL, path: "/models", header: "Authorization", prefix: "Bearer "},
+	"mistral": {baseURL: MistralDefaultBaseURL, path: "/models", header: "Authorization", prefix: "Bearer "},
+}
+
+// CheckCredentials validates an API-key account by listing the upstream's
+// models. A 401 or 403 means the key was rejected. Accounts without an API
+// key, or providers without a known endpoint, return provider.ErrCheckUnsupported.
+func CheckCredentials(ctx context.Context, cfg *config.Config, auth *provider.Auth) error {
+	req, err := modelsListRequest(ctx, auth)
+	if err != nil {
+		return err
+	}
+	resp, err := newProxyAwareHTTPClient(ctx, cfg, auth, 0).Do(req)
+	if err != nil {
+		return err
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	_ = resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("credentials rejected with status %d", resp.StatusCode)
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return fmt.Errorf("upstream returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// modelsListRequest builds the authenticated models listing request for an
+// API-key account, or returns provider.ErrCheckUnsupported when there is none.
+func modelsListRequest(ctx context.Context, auth *provider.Auth) (*http.Request, error) {
+	if auth == nil {
+		return nil, fmt.Errorf("auth is nil")
+	}
+	apiKey, baseURL := ExtractCreds(auth, CredExtractorConfig{TrimWhitespace: true})
+	// Vertex-compatible endpoints have no models listing to check against.
+	if apiKey == "" || strings.EqualFold(auth.Provider, "vertex") {
+		return nil, provider.ErrCheckUnsupported
+	}
+	check, known := credentialChecks[strings.ToLower(auth.Provider)]
+	if !known {
+		if baseURL == "" {
+			return nil, provider.ErrCheckUnsupported
+		}
+		check = credentialCheck{path: "/models", header: "Authorization", prefix: "Bearer "}
+	}
+	if baseURL == "" {
+		baseURL = check.baseURL
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(baseURL, "/")+check.path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(check.header, check.prefix+apiKey)
+	for k, v := range check.extra {
+		req.Header.Set(k, v)
+	}
+	return req, nil
+}
@@ -73,13 +73,16 @@ func (e *IFlowExecutor) Execute(ctx context.Context, auth *provider.Auth, req pr
 
 	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
 		result := HandleHTTPError(httpResp, "iflow executor")
-		return resp, result.Error
+		return resp, iflowUpstreamError(auth, result.StatusCode, result.Body, result.Error)
 	}
 
 	data, err := io.ReadAll(httpResp.Body)
 	if err != nil {
 		return resp, err
 	}
+	if errEnvelope := iflowEnvelopeError(auth, data); errEnvelope != nil {
+		return resp, errEnvelope
+	}
 	reporter.publish(ctx, extractUsageFromOpenAIResponse(data))
 	reporter.ensurePublished(ctx)
 
@@ -140,11 +143,24 @@ func (e *IFlowExecutor) ExecuteStream(ctx context.Context, auth *provider.Auth,
 	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
 		result := HandleHTTPError(httpResp, "iflow executor")
 		_ = httpResp.Body.Close()
-		return nil, result.Error
+		return nil, iflowUpstreamError(auth, result.StatusCode, result.Body, result.Error)
+	}
+	// iFlow answers a rejected stream with a JSON error envelope, not SSE.
+	if !isStreamingResponse(httpResp) {
+		data, errRead := io.ReadAll(httpResp.Body)
+		_ = httpResp.Body.Close()
+		if errRead != nil {
+			return nil, errRead
+		}
+		if errEnvelope := iflowEnvelopeError(auth, data); errEnvelope != nil {
+			return nil, errEnvelope
+		}
+		return nil, NewStatusError(http.StatusBadGateway, "iflow executor: unexpected non-stream response: "+string(data), nil)
 	}
 
 	messageID := "chatcmpl-" + req.Model
 	processor := NewOpenAIStreamProcessor(e.cfg, from, req.Model, messageID)
+	processor.ctx.RequestUsage(opts.OriginalRequest)
 
 	return RunSSEStream(ctx, httpResp.Body, reporter, processor, StreamConfig{
 		ExecutorName:    "iflow executor",
@@ -203,6 +219,9 @@ func (e *IFlowExecutor) refreshCookieBased(ctx context.Context, auth *provider.A
 	keyData, err := svc.RefreshAPIKey(ctx, cookie, email)
 	if err != nil {
 		log.Errorf("iflow executor: cookie-based API key refresh failed: %v", err)
+		if errors.Is(err, iflowauth.ErrCookieExpired) {
+			return nil, iflowReauthError(auth)
+		}
 		return nil, err
 	}
 
@@ -278,6 +297,56 @@ func (e *IFlowExecutor) refreshOAuthBased(ctx context.Context, auth *provider.Au
 	return auth, nil
 }
 
+// iFlow error envelope codes meaning the API key expired or is no longer valid.
+var iflowExpiredCodes = map[string]struct{}{
+	"434": {}, // invalid API key
+	"439": {}, // API key expired
+}
+
+// iflowReauthError is returned once iFlow rejects an account's credentials
+// for good. The account is taken out of rotation and reported as needing a
+// new login instead of failing every request with an opaque upstream error.
+func iflowReauthError(auth *provider.Auth) error {
+	login := "-iflow-login"
+	if MetaStringValue(auth.Metadata, "cookie") != "" {
+		login = "-iflow-cookie"
+	}
+	return NewStatusError(http.StatusUnauthorized, fmt.Sprintf("iflow executor: credentials expired (%s); log in again with %s", provider.StatusMessageReauthRequired, login), nil)
+}
+
+// iflowUpstreamError maps a non-2xx iFlow response to the error returned to
+// the manager, recognizing rejected credentials.
+func iflowUpstreamError(auth *provider.Auth, status int, body []byte, err error) error {
+	if status == http.StatusUnauthorized {
+		return iflowReauthError(auth)
+	}
+	if errEnvelope := iflowEnvelopeError(auth, body); errEnvelope != nil {
+		return errEnvelope
+	}
+	return err
+}
+
+// iflowEnvelopeError returns the error carried by an iFlow error envelope,
+// {"status":"439","msg":"..."}, which iFlow sends with HTTP 200. It returns
+// nil for any other payload.
+func iflowEnvelopeError(auth *provider.Auth, body []byte) error {
+	if !gjson.ValidBytes(body) {
+		return nil
+	}
+	root := gjson.ParseBytes(body)
+	code := root.Get("status")
+	if !code.Exists() || root.Get("choices").Exists() {
+		return nil
+	}
+	if _, expired := iflowExpiredCodes[code.String()]; expired {
+		return iflowReauthError(auth)
+	}
+	if code.String() == "0" || code.String() == "200" {
+		return nil
+	}
+	return NewStatusError(http.StatusBadGateway, fmt.Sprintf("iflow executor: upstream error %s: %s", code.String(), root.Get("msg").String()), nil)
+}
+
 func applyIFlowHeaders(r *http.Request, apiKey string, stream bool) {
 	ApplyAPIHeaders(r, HeaderConfig{
 		Token:     apiKey,
@@ -0,0 +1,88 @@
+package executor
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/registry"
+)
+
+func TestIFlowExecutor_ExpiredCredentialsRequireReauth(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"status":"439","msg":"Your API Token has expired.","body":null}`)
+	}))
+	defer srv.Close()
+
+	m := provider.NewManager(nil, nil, nil)
+	m.RegisterExecutor(NewIFlowExecutor(nil))
+	auth := &provider.Auth{
+		ID:         "iflow-cookie",
+		Provider:   "iflow",
+		Attributes: map[string]string{"api_key": "sk-expired", "base_url": srv.URL},
+		Metadata:   map[string]any{"cookie": "BXAuth=x;", "email": "user@example.com"},
+	}
+	if _, err := m.Register(context.Background(), auth); err != nil {
+		t.Fatal(err)
+	}
+	registry.GetGlobalRegistry().RegisterClient(auth.ID, "iflow", []*registry.ModelInfo{{ID: "reauth-test-model"}})
+	defer registry.GetGlobalRegistry().UnregisterClient(auth.ID)
+
+	req := provider.Request{Model: "reauth-test-model", Payload: []byte(`{"model":"reauth-test-model","messages":[{"role":"user","content":"hi"}]}`)}
+	opts := provider.Options{SourceFormat: provider.FromString("openai")}
+	for name, call := range map[string]func() error{
+		"non-streaming": func() error {
+			_, err := NewIFlowExecutor(nil).Execute(context.Background(), auth, req, opts)
+			return err
+		},
+		"streaming": func() error {
+			_, err := NewIFlowExecutor(nil).ExecuteStream(context.Background(), auth, req, opts)
+			return err
+		},
+	} {
+		err := call()
+		se, ok := err.(StatusError)
+		if !ok || se.Category() != provider.CategoryReauthRequired || se.StatusCode() != http.StatusUnauthorized {
+			t.Fatalf("%s: error %v, want a re-auth required 401", name, err)
+		}
+	}
+
+	if _, err := m.Execute(context.Background(), []string{"iflow"}, req, opts); err == nil {
+		t.Fatal("call with expired credentials succeeded")
+	}
+	got, ok := m.GetByID(auth.ID)
+	if !ok || !got.ReauthRequired() || !got.Unavailable {
+		t.Fatalf("account after expired credentials: status %q message %q unavailable %v, want re-auth required", got.Status, got.StatusMessage, got.Unavailable)
+	}
+}
+
+func TestIFlowEnvelopeError(t *testing.T) {
+	auth := &provider.Auth{Provider: "iflow"}
+	tests := []struct {
+		name string
+		body string
+		want provider.ErrorCategory
+	}{
+		{"completion", `{"choices":[{"message":{"content":"hi"}}]}`, provider.CategoryUnknown},
+		{"success envelope", `{"status":"0","msg":"ok"}`, provider.CategoryUnknown},
+		{"expired", `{"status":"439","msg":"Your API Token has expired."}`, provider.CategoryReauthRequired},
+		{"invalid key", `{"status":"434","msg":"Invalid apiKey"}`, provider.CategoryReauthRequired},
+		{"other error", `{"status":"500","msg":"internal"}`, provider.CategoryTransient},
+		{"not json", `upstream down`, provider.CategoryUnknown},
+	}
+	for _, tt := range tests {
+		err := iflowEnvelopeError(auth, []byte(tt.body))
+		got := provider.CategoryUnknown
+		if se, ok := err.(StatusError); ok {
+			got = se.Category()
+		}
+		if got != tt.want {
+			t.Errorf("%s: category %s (err %v), want %s", tt.name, got, err, tt.want)
+		}
+	}
+}
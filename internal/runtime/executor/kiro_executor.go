@@ -23,10 +23,19 @@ import (
 	"github.com/nghyane/llm-mux/internal/translator/from_ir"
 	"github.com/nghyane/llm-mux/internal/translator/ir"
 	"github.com/nghyane/llm-mux/internal/translator/to_ir"
-	"github.com/nghyane/llm-mux/internal/util"
 )
 
-const kiroAPIURL = KiroDefaultBaseURL
+// kiroChatPath is appended to a base_url override; KiroDefaultBaseURL
+// already includes it.
+const kiroChatPath = "/generateAssistantResponse"
+
+// kiroEndpoint returns the chat endpoint for auth.
+func kiroEndpoint(auth *provider.Auth) string {
+	if base := BaseURLOverride(auth); base != "" {
+		return base + kiroChatPath
+	}
+	return KiroDefaultBaseURL
+}
 
 var kiroModelMapping = map[string]string{
 	"claude-sonnet-4-5":                  "CLAUDE_SONNET_4_5_20250929_V1_0",
@@ -131,7 +140,7 @@ func (e *KiroExecutor) prepareRequest(ctx context.Context, auth *provider.Auth,
 }
 
 func (e *KiroExecutor) buildHTTPRequest(rc *requestContext) (*http.Request, error) {
-	httpReq, err := http.NewRequestWithContext(rc.ctx, "POST", kiroAPIURL, bytes.NewReader(rc.kiroBody))
+	httpReq, err := http.NewRequestWithContext(rc.ctx, "POST", kiroEndpoint(rc.auth), bytes.NewReader(rc.kiroBody))
 	if err != nil {
 		return nil, err
 	}
@@ -156,12 +165,7 @@ func (e *KiroExecutor) Execute(ctx context.Context, auth *provider.Auth, req pro
 		return provider.Response{}, err
 	}
 
-	client := &http.Client{Timeout: KiroRequestTimeout}
-	if proxy := e.cfg.ProxyURL; proxy != "" {
-		util.SetProxy(&config.SDKConfig{ProxyURL: proxy}, client)
-	} else if auth.ProxyURL != "" {
-		util.SetProxy(&config.SDKConfig{ProxyURL: auth.ProxyURL}, client)
-	}
+	client := newProxyAwareHTTPClient(ctx, e.cfg, auth, 0)
 
 	resp, err := client.Do(httpReq)
 	if err != nil {
@@ -237,12 +241,7 @@ func (e *KiroExecutor) ExecuteStream(ctx context.Context, auth *provider.Auth, r
 	}
 	httpReq.Header.Set("Connection", "keep-alive")
 
-	client := &http.Client{}
-	if proxy := e.cfg.ProxyURL; proxy != "" {
-		util.SetProxy(&config.SDKConfig{ProxyURL: proxy}, client)
-	} else if auth.ProxyURL != "" {
-		util.SetProxy(&config.SDKConfig{ProxyURL: auth.ProxyURL}, client)
-	}
+	client := newProxyAwareHTTPClient(ctx, e.cfg, auth, 0)
 
 	resp, err := client.Do(httpReq)
 	if err != nil {
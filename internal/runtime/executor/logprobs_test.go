@@ -0,0 +1,76 @@
+package executor
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/tidwall/gjson"
+)
+
+// fakeLogprobsBackend is an OpenAI-compatible server that answers with token
+// logprobs and hands each request body to got.
+func fakeLogprobsBackend(t *testing.T, got chan<- []byte) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got <- body
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"id":"chatcmpl-1","object":"chat.completion","created":1,"model":"m","choices":[{"index":0,`+
+			`"message":{"role":"assistant","content":"Hi"},"finish_reason":"stop",`+
+			`"logprobs":{"content":[{"token":"Hi","logprob":-0.1,"bytes":[72,105],"top_logprobs":[{"token":"Hi","logprob":-0.1},{"token":"Hey","logprob":-2.5}]}]}}],`+
+			`"usage":{"prompt_tokens":3,"completion_tokens":1,"total_tokens":4}}`)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestOpenAICompatExecutor_Logprobs(t *testing.T) {
+	got := make(chan []byte, 1)
+	srv := fakeLogprobsBackend(t, got)
+	exec := NewOpenAICompatExecutor("openai-compatibility", &config.Config{})
+	auth := &provider.Auth{ID: "test", Provider: "openai-compatibility", Attributes: map[string]string{"base_url": srv.URL}}
+
+	payload := []byte(`{"model":"m","messages":[{"role":"user","content":"hi"}],"logprobs":true,"top_logprobs":2,"logprobs_required":true}`)
+	resp, err := exec.Execute(context.Background(), auth,
+		provider.Request{Model: "m", Payload: payload},
+		provider.Options{SourceFormat: provider.FromString("openai"), OriginalRequest: payload})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+
+	sent := <-got
+	if !gjson.GetBytes(sent, "logprobs").Bool() || gjson.GetBytes(sent, "top_logprobs").Int() != 2 {
+		t.Errorf("upstream request = %s, want logprobs and top_logprobs", sent)
+	}
+	if gjson.GetBytes(sent, "logprobs_required").Exists() {
+		t.Errorf("upstream request leaks logprobs_required: %s", sent)
+	}
+	lp := gjson.GetBytes(resp.Payload, "choices.0.logprobs.content.0")
+	if lp.Get("token").String() != "Hi" || lp.Get("logprob").Float() != -0.1 || len(lp.Get("top_logprobs").Array()) != 2 {
+		t.Errorf("response logprobs = %s", gjson.GetBytes(resp.Payload, "choices.0.logprobs").Raw)
+	}
+}
+
+func TestOpenAICompatExecutor_LogprobsFromGeminiSource(t *testing.T) {
+	got := make(chan []byte, 1)
+	srv := fakeLogprobsBackend(t, got)
+	exec := NewOpenAICompatExecutor("openai-compatibility", &config.Config{})
+	auth := &provider.Auth{ID: "test", Provider: "openai-compatibility", Attributes: map[string]string{"base_url": srv.URL}}
+
+	payload := []byte(`{"contents":[{"role":"user","parts":[{"text":"hi"}]}],"generationConfig":{"responseLogprobs":true,"logprobs":2}}`)
+	if _, err := exec.Execute(context.Background(), auth,
+		provider.Request{Model: "m", Payload: payload},
+		provider.Options{SourceFormat: provider.FromString("gemini"), OriginalRequest: payload}); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+
+	sent := <-got
+	if !gjson.GetBytes(sent, "logprobs").Bool() || gjson.GetBytes(sent, "top_logprobs").Int() != 2 {
+		t.Errorf("upstream request = %s, want logprobs and top_logprobs", sent)
+	}
+}
@@ -0,0 +1,258 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/constant"
+	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/nghyane/llm-mux/internal/util"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// mistralIgnoredFields are OpenAI request fields Mistral rejects that do not
+// change the output; they are dropped silently.
+var mistralIgnoredFields = [...]string{"stream_options", "user", "store", "service_tier", "metadata"}
+
+// mistralUnsupportedFields are OpenAI request fields Mistral rejects that the
+// client would notice missing; they are dropped with a warning.
+var mistralUnsupportedFields = [...]string{"logit_bias", "logprobs", "top_logprobs"}
+
+// MistralExecutor talks to Mistral La Plateforme's OpenAI-compatible chat API.
+type MistralExecutor struct {
+	cfg *config.Config
+}
+
+func NewMistralExecutor(cfg *config.Config) *MistralExecutor { return &MistralExecutor{cfg: cfg} }
+
+func (e *MistralExecutor) Identifier() string { return constant.Mistral }
+
+func (e *MistralExecutor) PrepareRequest(_ *http.Request, _ *provider.Auth) error { return nil }
+
+func (e *MistralExecutor) Execute(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (resp provider.Response, err error) {
+	reporter := newUsageReporter(ctx, e.Identifier(), req.Model, auth)
+	defer reporter.trackFailure(ctx, &err)
+
+	from := opts.SourceFormat
+	body, err := e.translate(from, req, false)
+	if err != nil {
+		return resp, err
+	}
+	httpResp, err := e.post(ctx, auth, body, false)
+	if err != nil {
+		return resp, err
+	}
+	defer func() {
+		if errClose := httpResp.Body.Close(); errClose != nil {
+			log.Errorf("mistral executor: close response body error: %v", errClose)
+		}
+	}()
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return resp, err
+	}
+	data = normalizeMistralChoices(data, "message")
+	reporter.publish(ctx, extractUsageFromOpenAIResponse(data))
+	reporter.ensurePublished(ctx)
+
+	translatedResp, err := TranslateResponseNonStream(e.cfg, provider.FromString("openai"), from, data, req.Model)
+	if err != nil {
+		return resp, err
+	}
+	if translatedResp != nil {
+		return provider.Response{Payload: translatedResp}, nil
+	}
+	return provider.Response{Payload: data}, nil
+}
+
+func (e *MistralExecutor) ExecuteStream(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (stream <-chan provider.StreamChunk, err error) {
+	reporter := newUsageReporter(ctx, e.Identifier(), req.Model, auth)
+	defer reporter.trackFailure(ctx, &err)
+
+	from := opts.SourceFormat
+	body, err := e.translate(from, req, true)
+	if err != nil {
+		return nil, err
+	}
+	httpResp, err := e.post(ctx, auth, body, true)
+	if err != nil {
+		return nil, err
+	}
+
+	processor := NewOpenAIStreamProcessor(e.cfg, from, req.Model, "chatcmpl-"+req.Model)
+	processor.ctx.RequestUsage(opts.OriginalRequest)
+	processor.Preprocess = func(line []byte, _ bool) []byte {
+		return normalizeMistralChoices(line, "delta")
+	}
+	return RunSSEStream(ctx, httpResp.Body, reporter, processor, StreamConfig{
+		ExecutorName:     "mistral",
+		Preprocessor:     DataTagPreprocessor(),
+		HandleDoneSignal: true,
+		EnsurePublished:  true,
+	}), nil
+}
+
+func (e *MistralExecutor) CountTokens(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (provider.Response, error) {
+	translated, err := TranslateToOpenAI(e.cfg, opts.SourceFormat, req.Model, req.Payload, false, nil)
+	if err != nil {
+		return provider.Response{}, err
+	}
+	enc, err := tokenizerForModel(req.Model)
+	if err != nil {
+		return provider.Response{}, fmt.Errorf("mistral executor: tokenizer init failed: %w", err)
+	}
+	count, err := countOpenAIChatTokens(enc, translated)
+	if err != nil {
+		return provider.Response{}, fmt.Errorf("mistral executor: token counting failed: %w", err)
+	}
+	return provider.Response{Payload: buildOpenAIUsageJSON(count)}, nil
+}
+
+func (e *MistralExecutor) Refresh(ctx context.Context, auth *provider.Auth) (*provider.Auth, error) {
+	_ = ctx
+	return auth, nil
+}
+
+// translate builds the chat completions body Mistral accepts from the OpenAI
+// translation of the request.
+func (e *MistralExecutor) translate(from provider.Format, req provider.Request, stream bool) ([]byte, error) {
+	body, err := TranslateToOpenAI(e.cfg, from, req.Model, req.Payload, stream, req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	body = mistralToolMessages(body)
+
+	if gjson.GetBytes(body, "tool_choice").String() == "required" {
+		body, _ = sjson.SetBytes(body, "tool_choice", "any")
+	}
+	if v := gjson.GetBytes(body, "max_completion_tokens"); v.Exists() {
+		if !gjson.GetBytes(body, "max_tokens").Exists() {
+			body, _ = sjson.SetRawBytes(body, "max_tokens", []byte(v.Raw))
+		}
+		body, _ = sjson.DeleteBytes(body, "max_completion_tokens")
+	}
+	if v := gjson.GetBytes(body, "seed"); v.Exists() {
+		body, _ = sjson.SetRawBytes(body, "random_seed", []byte(v.Raw))
+		body, _ = sjson.DeleteBytes(body, "seed")
+	}
+	for _, field := range mistralIgnoredFields {
+		body, _ = sjson.DeleteBytes(body, field)
+	}
+	var dropped []string
+	for _, field := range mistralUnsupportedFields {
+		if gjson.GetBytes(body, field).Exists() {
+			body, _ = sjson.DeleteBytes(body, field)
+			dropped = append(dropped, field)
+		}
+	}
+	if len(dropped) > 0 {
+		provider.AddWarnings(req.Metadata, fmt.Sprintf("mistral does not support %s; dropped", strings.Join(dropped, ", ")))
+	}
+	if stream {
+		body, _ = sjson.SetBytes(body, "stream", true)
+	}
+	return applyPayloadConfigWithRoot(e.cfg, req.Model, "openai", "", body), nil
+}
+
+// mistralToolMessages rewrites tool call IDs to the nine-character form
+// Mistral requires and names each tool result after the call it answers.
+func mistralToolMessages(body []byte) []byte {
+	names := make(map[string]string)
+	for i, msg := range gjson.GetBytes(body, "messages").Array() {
+		prefix := "messages." + strconv.Itoa(i)
+		switch msg.Get("role").String() {
+		case "assistant":
+			for j, tc := range msg.Get("tool_calls").Array() {
+				id := tc.Get("id").String()
+				names[id] = tc.Get("function.name").String()
+				if mid := ir.ToMistralToolID(id); mid != id {
+					body, _ = sjson.SetBytes(body, prefix+".tool_calls."+strconv.Itoa(j)+".id", mid)
+				}
+			}
+		case "tool":
+			id := msg.Get("tool_call_id").String()
+			if mid := ir.ToMistralToolID(id); mid != id {
+				body, _ = sjson.SetBytes(body, prefix+".tool_call_id", mid)
+			}
+			if name := names[id]; name != "" && !msg.Get("name").Exists() {
+				body, _ = sjson.SetBytes(body, prefix+".name", name)
+			}
+		}
+	}
+	return body
+}
+
+// normalizeMistralChoices rewrites a Mistral response or stream chunk into
+// the OpenAI shape: finish reasons from the OpenAI set, and tool calls with a
+// type, an index and string arguments. key is "message" for responses and
+// "delta" for stream chunks.
+func normalizeMistralChoices(data []byte, key string) []byte {
+	for i, choice := range gjson.GetBytes(data, "choices").Array() {
+		prefix := "choices." + strconv.Itoa(i)
+		if fr := choice.Get("finish_reason").String(); fr != "" {
+			if mapped := ir.MapFinishReasonToOpenAI(ir.MapMistralFinishReason(fr)); mapped != fr {
+				data, _ = sjson.SetBytes(data, prefix+".finish_reason", mapped)
+			}
+		}
+		for j, tc := range choice.Get(key + ".tool_calls").Array() {
+			tcPath := prefix + "." + key + ".tool_calls." + strconv.Itoa(j)
+			if !tc.Get("type").Exists() || tc.Get("type").Type == gjson.Null {
+				data, _ = sjson.SetBytes(data, tcPath+".type", "function")
+			}
+			if key == "delta" && !tc.Get("index").Exists() {
+				data, _ = sjson.SetBytes(data, tcPath+".index", j)
+			}
+			if args := tc.Get("function.arguments"); args.IsObject() {
+				data, _ = sjson.SetBytes(data, tcPath+".function.arguments", args.Raw)
+			}
+		}
+	}
+	return data
+}
+
+// post sends body to the chat completions endpoint and returns the response
+// when it has a 2xx status. The caller closes the body.
+func (e *MistralExecutor) post(ctx context.Context, auth *provider.Auth, body []byte, stream bool) (*http.Response, error) {
+	apiKey, baseURL := ExtractCreds(auth, CredExtractorConfig{TrimWhitespace: true})
+	if apiKey == "" {
+		return nil, NewStatusError(http.StatusUnauthorized, "missing mistral api key", nil)
+	}
+	if baseURL == "" {
+		baseURL = MistralDefaultBaseURL
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(baseURL, "/")+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	ApplyAPIHeaders(httpReq, HeaderConfig{
+		Token:         apiKey,
+		UserAgent:     "llm-mux",
+		StreamHeaders: map[string]string{"Cache-Control": "no-cache"},
+	}, stream)
+	util.ApplyCustomHeadersFromAttrs(httpReq, auth.Attributes)
+
+	httpClient := newProxyAwareHTTPClient(ctx, e.cfg, auth, 0)
+	httpResp, err := httpClient.Do(httpReq)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, NewTimeoutError("request timed out")
+		}
+		return nil, err
+	}
+	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+		result := HandleHTTPError(httpResp, "mistral executor")
+		_ = httpResp.Body.Close()
+		return nil, result.Error
+	}
+	return httpResp, nil
+}
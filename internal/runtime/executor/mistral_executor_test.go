@@ -0,0 +1,131 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/tidwall/gjson"
+)
+
+func TestMistralExecutor_ToolCallRoundTrip(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		var body bytes.Buffer
+		_, _ = body.ReadFrom(r.Body)
+		got := gjson.ParseBytes(body.Bytes())
+
+		callID := got.Get("messages.1.tool_calls.0.id").String()
+		if len(callID) != 9 || strings.ContainsAny(callID, "_-") {
+			t.Errorf("tool call id = %q, want nine alphanumerics", callID)
+		}
+		result := got.Get("messages.2")
+		if result.Get("role").String() != "tool" || result.Get("tool_call_id").String() != callID {
+			t.Errorf("tool result = %s, want tool_call_id %q", result.Raw, callID)
+		}
+		if result.Get("name").String() != "get_weather" {
+			t.Errorf("tool result name = %q", result.Get("name").String())
+		}
+		if got.Get("tool_choice").String() != "any" {
+			t.Errorf("tool_choice = %s, want any", got.Get("tool_choice").Raw)
+		}
+
+		// Mistral may omit the tool call type and send arguments as an object.
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"m1","object":"chat.completion","model":"mistral-large-latest",
+			"choices":[{"index":0,"message":{"role":"assistant","content":"","tool_calls":[
+				{"id":"Ab3dE6gH9","function":{"name":"get_weather","arguments":{"city":"Lyon"}}}]},"finish_reason":"tool_calls"}],
+			"usage":{"prompt_tokens":30,"completion_tokens":8,"total_tokens":38}}`))
+	}))
+	defer srv.Close()
+
+	exec := NewMistralExecutor(&config.Config{})
+	auth := &provider.Auth{Provider: "mistral", Attributes: map[string]string{"api_key": "mk", "base_url": srv.URL + "/v1"}}
+	payload := []byte(`{"model":"mistral-large-latest","max_tokens":512,"tool_choice":{"type":"any"},
+		"tools":[{"name":"get_weather","description":"Current weather","input_schema":{"type":"object","properties":{"city":{"type":"string"}},"required":["city"]}}],
+		"messages":[
+			{"role":"user","content":"Weather in Paris, then Lyon?"},
+			{"role":"assistant","content":[{"type":"tool_use","id":"toolu_01A09q90qw90lq917835lq9","name":"get_weather","input":{"city":"Paris"}}]},
+			{"role":"user","content":[{"type":"tool_result","tool_use_id":"toolu_01A09q90qw90lq917835lq9","content":"21C"}]}]}`)
+	resp, err := exec.Execute(context.Background(), auth,
+		provider.Request{Model: "mistral-large-latest", Payload: payload},
+		provider.Options{SourceFormat: provider.FormatClaude})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+
+	toolUse := gjson.GetBytes(resp.Payload, `content.#(type=="tool_use")`)
+	if toolUse.Get("id").String() == "" || toolUse.Get("name").String() != "get_weather" || toolUse.Get("input.city").String() != "Lyon" {
+		t.Errorf("tool_use = %s", resp.Payload)
+	}
+	if got := gjson.GetBytes(resp.Payload, "stop_reason").String(); got != "tool_use" {
+		t.Errorf("stop_reason = %q, want tool_use", got)
+	}
+}
+
+func TestMistralExecutor_StreamToolCallAndLength(t *testing.T) {
+	events := []string{
+		`data: {"id":"m2","object":"chat.completion.chunk","model":"mistral-small-latest","choices":[{"index":0,"delta":{"role":"assistant","content":"Checking."}}]}`,
+		`data: {"id":"m2","object":"chat.completion.chunk","model":"mistral-small-latest","choices":[{"index":0,"delta":{"tool_calls":[{"id":"Zx9Yw8Vu7","function":{"name":"get_weather","arguments":"{\"city\":\"Nice\"}"}}]},"finish_reason":"tool_calls"}]}`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body bytes.Buffer
+		_, _ = body.ReadFrom(r.Body)
+		if gjson.GetBytes(body.Bytes(), "stream_options").Exists() || gjson.GetBytes(body.Bytes(), "seed").Exists() {
+			t.Errorf("unsupported fields sent: %s", body.String())
+		}
+		if gjson.GetBytes(body.Bytes(), "random_seed").Int() != 7 {
+			t.Errorf("random_seed missing: %s", body.String())
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(strings.Join(events, "\n\n") + "\n\ndata: [DONE]\n\n"))
+	}))
+	defer srv.Close()
+
+	exec := NewMistralExecutor(&config.Config{})
+	auth := &provider.Auth{Provider: "mistral", Attributes: map[string]string{"api_key": "mk", "base_url": srv.URL}}
+	stream, err := exec.ExecuteStream(context.Background(), auth,
+		provider.Request{Model: "mistral-small-latest", Payload: []byte(`{"model":"mistral-small-latest","stream":true,"seed":7,
+			"stream_options":{"include_usage":true},"messages":[{"role":"user","content":"Weather in Nice?"}]}`)},
+		provider.Options{SourceFormat: provider.FormatOpenAI, Stream: true})
+	if err != nil {
+		t.Fatalf("ExecuteStream: %v", err)
+	}
+
+	var toolID, toolName, toolArgs, finish string
+	for chunk := range stream {
+		if chunk.Err != nil {
+			t.Fatalf("stream error: %v", chunk.Err)
+		}
+		data := bytes.TrimSpace(bytes.TrimPrefix(bytes.TrimSpace(chunk.Payload), []byte("data:")))
+		choice := gjson.GetBytes(data, "choices.0")
+		if tc := choice.Get("delta.tool_calls.0"); tc.Exists() {
+			toolID += tc.Get("id").String()
+			toolName += tc.Get("function.name").String()
+			toolArgs += tc.Get("function.arguments").String()
+		}
+		if fr := choice.Get("finish_reason").String(); fr != "" {
+			finish = fr
+		}
+	}
+	if toolID != "Zx9Yw8Vu7" || toolName != "get_weather" || gjson.Get(toolArgs, "city").String() != "Nice" {
+		t.Errorf("tool call = %s %s(%s)", toolID, toolName, toolArgs)
+	}
+	if finish != "tool_calls" {
+		t.Errorf("finish_reason = %q, want tool_calls", finish)
+	}
+}
+
+func TestNormalizeMistralChoices_ModelLength(t *testing.T) {
+	out := normalizeMistralChoices([]byte(`{"choices":[{"index":0,"delta":{"content":"x"},"finish_reason":"model_length"}]}`), "delta")
+	if got := gjson.GetBytes(out, "choices.0.finish_reason").String(); got != "length" {
+		t.Errorf("finish_reason = %q, want length", got)
+	}
+}
@@ -9,8 +9,10 @@ import (
 	"strings"
 	"time"
 
-	"github.com/nghyane/llm-mux/internal/registry"
+	"github.com/nghyane/llm-mux/internal/config"
 	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/registry"
 	"github.com/tidwall/gjson"
 )
 
@@ -243,3 +245,78 @@ func ParseGLAPIModels(body []byte, providerType string) []*registry.ModelInfo {
 
 	return models
 }
+
+// FetchListedModels lists the models an API-key account can use from its
+// provider's models endpoint, the same one CheckCredentials calls. It returns
+// nil when the account has no key, the provider has no listing, or the call
+// fails.
+func FetchListedModels(ctx context.Context, cfg *config.Config, auth *provider.Auth) []*registry.ModelInfo {
+	req, err := modelsListRequest(ctx, auth)
+	if err != nil {
+		return nil
+	}
+	providerType := strings.ToLower(auth.Provider)
+	resp, err := newProxyAwareHTTPClient(ctx, cfg, auth, 0).Do(req)
+	if err != nil {
+		log.Debugf("%s: models request error: %v", providerType, err)
+		return nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		log.Debugf("%s: models request failed with status %d", providerType, resp.StatusCode)
+		return nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Debugf("%s: failed to read models response: %v", providerType, err)
+		return nil
+	}
+	return ParseListedModels(body, providerType)
+}
+
+// ParseListedModels reads a models listing in the OpenAI and Anthropic shape
+// ({"data":[{"id":...}]}) or the Cohere shape ({"models":[{"name":...}]}).
+func ParseListedModels(body []byte, providerType string) []*registry.ModelInfo {
+	entries := gjson.GetBytes(body, "data")
+	idField := "id"
+	if !entries.IsArray() {
+		entries = gjson.GetBytes(body, "models")
+		idField = "name"
+	}
+	if !entries.IsArray() {
+		return nil
+	}
+
+	now := time.Now().Unix()
+	var models []*registry.ModelInfo
+	entries.ForEach(func(_, value gjson.Result) bool {
+		id := value.Get(idField).String()
+		if id == "" {
+			return true
+		}
+		created := value.Get("created").Int()
+		if created == 0 {
+			if t, err := time.Parse(time.RFC3339, value.Get("created_at").String()); err == nil {
+				created = t.Unix()
+			} else {
+				created = now
+			}
+		}
+		ownedBy := value.Get("owned_by").String()
+		if ownedBy == "" {
+			ownedBy = providerType
+		}
+		models = append(models, &registry.ModelInfo{
+			ID:            id,
+			Name:          id,
+			Object:        "model",
+			Created:       created,
+			OwnedBy:       ownedBy,
+			Type:          providerType,
+			DisplayName:   value.Get("display_name").String(),
+			ContextLength: int(value.Get("context_length").Int()),
+		})
+		return true
+	})
+	return models
+}
@@ -0,0 +1,74 @@
+package executor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+func TestFetchListedModels(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/models" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer xk" {
+			t.Errorf("Authorization = %q", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"object":"list","data":[
+			{"id":"grok-5","object":"model","created":1750000000,"owned_by":"xai"},
+			{"id":"grok-5-mini","object":"model","created":1750000001}]}`))
+	}))
+	defer srv.Close()
+
+	auth := &provider.Auth{ID: "a1", Provider: "xai", Attributes: map[string]string{"api_key": "xk", "base_url": srv.URL + "/v1"}}
+	models := FetchListedModels(context.Background(), &config.Config{}, auth)
+	if len(models) != 2 {
+		t.Fatalf("got %d models, want 2", len(models))
+	}
+	if m := models[0]; m.ID != "grok-5" || m.Created != 1750000000 || m.Type != "xai" {
+		t.Errorf("first model = %+v", m)
+	}
+	if got := models[1].OwnedBy; got != "xai" {
+		t.Errorf("owned_by fallback = %q, want xai", got)
+	}
+
+	if got := FetchListedModels(context.Background(), &config.Config{}, &provider.Auth{Provider: "xai"}); got != nil {
+		t.Errorf("account without a key listed %d models", len(got))
+	}
+}
+
+func TestFetchListedModels_UpstreamError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	auth := &provider.Auth{Provider: "openai-compatibility", Attributes: map[string]string{"api_key": "k", "base_url": srv.URL}}
+	if got := FetchListedModels(context.Background(), &config.Config{}, auth); got != nil {
+		t.Errorf("failed listing returned %d models", len(got))
+	}
+}
+
+func TestParseListedModels(t *testing.T) {
+	anthropic := ParseListedModels([]byte(`{"data":[{"type":"model","id":"claude-opus-5","display_name":"Claude Opus 5","created_at":"2026-05-01T00:00:00Z"}],"has_more":false}`), "claude")
+	if len(anthropic) != 1 {
+		t.Fatalf("got %d models, want 1", len(anthropic))
+	}
+	if m := anthropic[0]; m.DisplayName != "Claude Opus 5" || m.Created != 1777593600 || m.OwnedBy != "claude" {
+		t.Errorf("anthropic model = %+v", m)
+	}
+
+	cohere := ParseListedModels([]byte(`{"models":[{"name":"command-a-03-2025","context_length":256000},{"name":""}]}`), "cohere")
+	if len(cohere) != 1 || cohere[0].ID != "command-a-03-2025" || cohere[0].ContextLength != 256000 {
+		t.Errorf("cohere models = %+v", cohere)
+	}
+
+	if got := ParseListedModels([]byte(`{"error":"nope"}`), "xai"); got != nil {
+		t.Errorf("error body parsed as %d models", len(got))
+	}
+}
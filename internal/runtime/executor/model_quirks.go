@@ -1,9 +1,11 @@
 package executor
 
 import (
+	"slices"
 	"strings"
 
 	"github.com/nghyane/llm-mux/internal/registry"
+	"github.com/tidwall/gjson"
 )
 
 // ModelQuirks provides model-specific behavior detection.
@@ -44,15 +46,41 @@ func getThinkingVariant(model string) string {
 	return ""
 }
 
+// thinkingModelFor returns the -thinking variant of model when the request
+// asks for reasoning effort on a base model and prov serves that variant.
+// Otherwise model is returned unchanged.
+func thinkingModelFor(prov, model string, payload []byte) string {
+	if hasThinkingSuffix(model) || !wantsReasoningEffort(payload) {
+		return model
+	}
+	variant := getThinkingVariant(model)
+	if variant == "" || !slices.Contains(registry.GetGlobalRegistry().GetModelProviders(variant), prov) {
+		return model
+	}
+	return variant
+}
+
+// wantsReasoningEffort reports whether an OpenAI-style payload requests reasoning.
+func wantsReasoningEffort(payload []byte) bool {
+	for _, path := range [...]string{"reasoning_effort", "reasoning.effort"} {
+		if effort := gjson.GetBytes(payload, path).String(); effort != "" && effort != "none" {
+			return true
+		}
+	}
+	return false
+}
+
 // getOutputTokenLimit returns the max output tokens for a model.
 // Returns 0 if no limit is defined.
 func getOutputTokenLimit(model string) int {
+	return registry.GetGlobalRegistry().GetModelInfo(model).MaxOutputTokens()
+}
+
+// getRequiredBetas returns the anthropic-beta flags the registry lists for a model.
+func getRequiredBetas(model string) []string {
 	info := registry.GetGlobalRegistry().GetModelInfo(model)
 	if info == nil {
-		return 0
-	}
-	if info.OutputTokenLimit > 0 {
-		return info.OutputTokenLimit
+		return nil
 	}
-	return info.MaxCompletionTokens
+	return info.Betas
 }
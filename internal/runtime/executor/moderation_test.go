@@ -0,0 +1,58 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/tidwall/gjson"
+)
+
+func TestOpenAICompatExecutor_ModerateNormalizesCategories(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/moderations" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		// Mistral-style response: different category names and no flagged field.
+		_, _ = w.Write([]byte(`{"id":"mod-1","model":"mistral-moderation-latest","results":[{
+			"categories":{"hate_and_discrimination":false,"violence_and_threats":true,"selfharm":false,"pii":false},
+			"category_scores":{"hate_and_discrimination":0.01,"violence_and_threats":0.93,"selfharm":0.002,"pii":0.0}}]}`))
+	}))
+	defer srv.Close()
+
+	exec := NewOpenAICompatExecutor("mistral", &config.Config{})
+	auth := &provider.Auth{Provider: "mistral", Attributes: map[string]string{"api_key": "k", "base_url": srv.URL}}
+	resp, err := exec.Moderate(context.Background(), auth,
+		provider.Request{Model: "mistral-moderation-latest", Payload: []byte(`{"input":"I will hurt you"}`)},
+		provider.Options{SourceFormat: provider.FormatOpenAI})
+	if err != nil {
+		t.Fatalf("moderate: %v", err)
+	}
+	result := gjson.GetBytes(resp.Payload, "results.0")
+	if !result.Get("flagged").Bool() {
+		t.Errorf("flagged not derived from categories: %s", result.Raw)
+	}
+	if !result.Get("categories.violence").Bool() || result.Get("category_scores.violence").Float() != 0.93 {
+		t.Errorf("violence_and_threats not mapped to violence: %s", result.Raw)
+	}
+	if got := result.Get(`categories.sexual/minors`); !got.Exists() || got.Bool() {
+		t.Errorf("missing OpenAI category not reported as false: %s", result.Raw)
+	}
+}
+
+func TestOpenAICompatExecutor_ModerateUnsupported(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	exec := NewOpenAICompatExecutor("local", &config.Config{})
+	auth := &provider.Auth{Provider: "local", Attributes: map[string]string{"base_url": srv.URL}}
+	_, err := exec.Moderate(context.Background(), auth, provider.Request{Payload: []byte(`{"input":"hi"}`)}, provider.Options{})
+	var se StatusError
+	if !errors.As(err, &se) || se.StatusCode() != http.StatusNotImplemented {
+		t.Fatalf("err = %v, want 501", err)
+	}
+}
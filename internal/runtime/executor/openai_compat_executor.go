@@ -11,6 +11,9 @@ import (
 
 	"github.com/nghyane/llm-mux/internal/config"
 	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/translator/from_ir"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/nghyane/llm-mux/internal/translator/to_ir"
 	"github.com/nghyane/llm-mux/internal/util"
 	log "github.com/nghyane/llm-mux/internal/logging"
 	"github.com/tidwall/sjson"
@@ -58,7 +61,6 @@ func (e *OpenAICompatExecutor) Execute(ctx context.Context, auth *provider.Auth,
 	if apiKey != "" {
 		httpReq.Header.Set("Authorization", "Bearer "+apiKey)
 	}
-	httpReq.Header.Set("User-Agent", "cli-proxy-openai-compat")
 	var attrs map[string]string
 	if auth != nil {
 		attrs = auth.Attributes
@@ -130,7 +132,6 @@ func (e *OpenAICompatExecutor) ExecuteStream(ctx context.Context, auth *provider
 	if apiKey != "" {
 		httpReq.Header.Set("Authorization", "Bearer "+apiKey)
 	}
-	httpReq.Header.Set("User-Agent", "cli-proxy-openai-compat")
 	var attrs map[string]string
 	if auth != nil {
 		attrs = auth.Attributes
@@ -155,6 +156,7 @@ func (e *OpenAICompatExecutor) ExecuteStream(ctx context.Context, auth *provider
 
 	messageID := "chatcmpl-" + req.Model
 	processor := NewOpenAIStreamProcessor(e.cfg, from, req.Model, messageID)
+	processor.ctx.RequestUsage(opts.OriginalRequest)
 	return RunSSEStream(ctx, httpResp.Body, reporter, processor, StreamConfig{
 		ExecutorName:     "openai-compat",
 		Preprocessor:     DataTagPreprocessor(),
@@ -190,6 +192,206 @@ func (e *OpenAICompatExecutor) CountTokens(ctx context.Context, auth *provider.A
 	return provider.Response{Payload: usageJSON}, nil
 }
 
+// Embed forwards embeddings to the upstream /embeddings endpoint, splitting
+// inputs into batches the OpenAI API accepts.
+func (e *OpenAICompatExecutor) Embed(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (resp provider.Response, err error) {
+	reporter := newUsageReporter(ctx, e.Identifier(), req.Model, auth)
+	defer reporter.trackFailure(ctx, &err)
+
+	baseURL, apiKey := e.resolveCredentials(auth)
+	if baseURL == "" {
+		err = NewStatusError(http.StatusUnauthorized, "missing provider baseURL", nil)
+		return
+	}
+	embedReq, err := parseEmbeddingRequest(opts.SourceFormat, req.Model, req.Payload)
+	if err != nil {
+		return resp, err
+	}
+	upstreamModel := req.Model
+	if modelOverride := e.resolveUpstreamModel(req.Model, auth); modelOverride != "" {
+		upstreamModel = modelOverride
+	}
+	url := strings.TrimSuffix(baseURL, "/") + "/embeddings"
+	httpClient := newProxyAwareHTTPClient(ctx, e.cfg, auth, 0)
+
+	result, err := embedInBatches(ctx, embedReq, openAIEmbedBatchLimit, func(ctx context.Context, batch *ir.EmbeddingRequest) (*ir.EmbeddingResponse, error) {
+		upstreamReq := *batch
+		upstreamReq.Model = upstreamModel
+		body, errBuild := from_ir.ToOpenAIEmbeddingRequest(&upstreamReq)
+		if errBuild != nil {
+			return nil, errBuild
+		}
+		httpReq, errReq := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if errReq != nil {
+			return nil, errReq
+		}
+		httpReq.Header.Set("Content-Type", "application/json")
+		if apiKey != "" {
+			httpReq.Header.Set("Authorization", "Bearer "+apiKey)
+		}
+		var attrs map[string]string
+		if auth != nil {
+			attrs = auth.Attributes
+		}
+		util.ApplyCustomHeadersFromAttrs(httpReq, attrs)
+
+		httpResp, errDo := httpClient.Do(httpReq)
+		if errDo != nil {
+			if errors.Is(errDo, context.DeadlineExceeded) {
+				return nil, NewTimeoutError("request timed out")
+			}
+			return nil, errDo
+		}
+		defer func() { _ = httpResp.Body.Close() }()
+		if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+			return nil, HandleHTTPError(httpResp, "openai-compat executor").Error
+		}
+		data, errRead := io.ReadAll(httpResp.Body)
+		if errRead != nil {
+			return nil, errRead
+		}
+		return to_ir.ParseOpenAIEmbeddingResponse(data)
+	})
+	if err != nil {
+		return resp, err
+	}
+	estimateEmbeddingUsage(embedReq, result)
+	reporter.publish(ctx, result.Usage)
+	return buildEmbeddingResponse(embedReq, result)
+}
+
+// Moderate forwards the request to the upstream /moderations endpoint and
+// normalizes the categories into the OpenAI moderation shape.
+func (e *OpenAICompatExecutor) Moderate(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (resp provider.Response, err error) {
+	baseURL, apiKey := e.resolveCredentials(auth)
+	if baseURL == "" {
+		return resp, NewStatusError(http.StatusUnauthorized, "missing provider baseURL", nil)
+	}
+	model := req.Model
+	if modelOverride := e.resolveUpstreamModel(req.Model, auth); modelOverride != "" {
+		model = modelOverride
+	}
+	body := e.overrideModel(req.Payload, model)
+
+	url := strings.TrimSuffix(baseURL, "/") + "/moderations"
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return resp, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+	var attrs map[string]string
+	if auth != nil {
+		attrs = auth.Attributes
+	}
+	util.ApplyCustomHeadersFromAttrs(httpReq, attrs)
+
+	httpClient := newProxyAwareHTTPClient(ctx, e.cfg, auth, 0)
+	httpResp, err := httpClient.Do(httpReq)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return resp, NewTimeoutError("request timed out")
+		}
+		return resp, err
+	}
+	defer func() { _ = httpResp.Body.Close() }()
+	if httpResp.StatusCode == http.StatusNotFound || httpResp.StatusCode == http.StatusMethodNotAllowed {
+		// The upstream speaks OpenAI chat but has no moderation API.
+		_, _ = io.Copy(io.Discard, httpResp.Body)
+		return resp, NewStatusError(http.StatusNotImplemented, fmt.Sprintf("provider %s has no moderation API", e.provider), nil)
+	}
+	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+		return resp, HandleHTTPError(httpResp, "openai-compat executor").Error
+	}
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return resp, err
+	}
+	parsed, err := to_ir.ParseOpenAIModerationResponse(data)
+	if err != nil {
+		return resp, err
+	}
+	out, err := from_ir.ToOpenAIModerationResponse(parsed)
+	if err != nil {
+		return resp, err
+	}
+	return provider.Response{Payload: out}, nil
+}
+
+// Rerank forwards the request to the upstream /rerank endpoint (Cohere, Jina,
+// Voyage) and normalizes the scored results.
+func (e *OpenAICompatExecutor) Rerank(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (resp provider.Response, err error) {
+	reporter := newUsageReporter(ctx, e.Identifier(), req.Model, auth)
+	defer reporter.trackFailure(ctx, &err)
+
+	baseURL, apiKey := e.resolveCredentials(auth)
+	if baseURL == "" {
+		err = NewStatusError(http.StatusUnauthorized, "missing provider baseURL", nil)
+		return
+	}
+	if opts.SourceFormat != provider.FormatOpenAI {
+		return resp, fmt.Errorf("rerank is not supported for %s requests", opts.SourceFormat)
+	}
+	rerankReq, err := to_ir.ParseRerankRequest(req.Payload)
+	if err != nil {
+		return resp, NewStatusError(http.StatusBadRequest, err.Error(), nil)
+	}
+	rerankReq.Model = req.Model
+	upstreamReq := *rerankReq
+	if modelOverride := e.resolveUpstreamModel(req.Model, auth); modelOverride != "" {
+		upstreamReq.Model = modelOverride
+	}
+	body, err := from_ir.ToRerankRequest(&upstreamReq)
+	if err != nil {
+		return resp, err
+	}
+
+	url := strings.TrimSuffix(baseURL, "/") + "/rerank"
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return resp, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+	var attrs map[string]string
+	if auth != nil {
+		attrs = auth.Attributes
+	}
+	util.ApplyCustomHeadersFromAttrs(httpReq, attrs)
+
+	httpClient := newProxyAwareHTTPClient(ctx, e.cfg, auth, 0)
+	httpResp, err := httpClient.Do(httpReq)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return resp, NewTimeoutError("request timed out")
+		}
+		return resp, err
+	}
+	defer func() { _ = httpResp.Body.Close() }()
+	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+		return resp, HandleHTTPError(httpResp, "openai-compat executor").Error
+	}
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return resp, err
+	}
+	parsed, err := to_ir.ParseRerankResponse(data)
+	if err != nil {
+		return resp, err
+	}
+	parsed.Model = req.Model
+	reporter.publish(ctx, parsed.Usage)
+	out, err := from_ir.ToRerankResponse(rerankReq, parsed)
+	if err != nil {
+		return resp, err
+	}
+	return provider.Response{Payload: out}, nil
+}
+
 func (e *OpenAICompatExecutor) Refresh(ctx context.Context, auth *provider.Auth) (*provider.Auth, error) {
 	_ = ctx
 	return auth, nil
@@ -199,7 +401,7 @@ func (e *OpenAICompatExecutor) resolveCredentials(auth *provider.Auth) (baseURL,
 	if auth == nil {
 		return "", ""
 	}
-	baseURL = AttrStringValue(auth.Attributes, "base_url")
+	baseURL = BaseURLOverride(auth)
 	apiKey = AttrStringValue(auth.Attributes, "api_key")
 	return
 }
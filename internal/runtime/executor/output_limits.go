@@ -0,0 +1,49 @@
+package executor
+
+import (
+	"fmt"
+
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// outputLimitWarning describes a max_tokens value lowered to the model limit.
+func outputLimitWarning(model string, requested, limit int) string {
+	return fmt.Sprintf("max_tokens %d exceeds the %d token output limit of %s; clamped to %d", requested, limit, model, limit)
+}
+
+// applyOutputTokenLimit clamps a max_tokens above the model's output limit
+// instead of letting the upstream reject the request, and records a warning.
+// Providers that require max_tokens get the limit as their default later, in
+// preprocessing.
+func applyOutputTokenLimit(req *ir.UnifiedChatRequest) {
+	if req.MaxTokens == nil {
+		return
+	}
+	limit := getOutputTokenLimit(req.Model)
+	if limit <= 0 || *req.MaxTokens <= limit {
+		return
+	}
+	req.AddWarning("%s", outputLimitWarning(req.Model, *req.MaxTokens, limit))
+	*req.MaxTokens = limit
+}
+
+// clampPayloadOutputTokens applies the same limit to OpenAI payloads that are
+// passed through without an IR round trip.
+func clampPayloadOutputTokens(model string, payload []byte, metadata map[string]any) []byte {
+	limit := getOutputTokenLimit(model)
+	if limit <= 0 {
+		return payload
+	}
+	for _, field := range [...]string{"max_tokens", "max_completion_tokens"} {
+		v := gjson.GetBytes(payload, field)
+		if v.Type != gjson.Number || v.Int() <= int64(limit) {
+			continue
+		}
+		provider.AddWarnings(metadata, outputLimitWarning(model, int(v.Int()), limit))
+		payload, _ = sjson.SetBytes(payload, field, limit)
+	}
+	return payload
+}
@@ -0,0 +1,56 @@
+package executor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/registry"
+	"github.com/tidwall/gjson"
+)
+
+func TestOutputTokenLimit(t *testing.T) {
+	reg := registry.GetGlobalRegistry()
+	reg.RegisterClient("limits-test", "claude", []*registry.ModelInfo{
+		registry.Claude("claude-limits-test").Context(200000, 8192).B(),
+	})
+	defer reg.UnregisterClient("limits-test")
+
+	tests := []struct {
+		name    string
+		payload string
+		want    int64
+		warned  bool
+	}{
+		{"over limit", `{"max_tokens":100000,"messages":[{"role":"user","content":"hi"}]}`, 8192, true},
+		{"missing", `{"messages":[{"role":"user","content":"hi"}]}`, 8192, false},
+		{"within limit", `{"max_tokens":1024,"messages":[{"role":"user","content":"hi"}]}`, 1024, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meta := map[string]any{}
+			body, err := TranslateToClaude(&config.Config{}, provider.FromString("openai"), "claude-limits-test", []byte(tt.payload), false, meta)
+			if err != nil {
+				t.Fatalf("translate: %v", err)
+			}
+			if got := gjson.GetBytes(body, "max_tokens").Int(); got != tt.want {
+				t.Errorf("max_tokens = %d, want %d", got, tt.want)
+			}
+			warnings := provider.Warnings(meta)
+			warned := len(warnings) == 1 && strings.Contains(warnings[0], "clamped to 8192")
+			if warned != tt.warned {
+				t.Errorf("warnings = %q, want clamp warning = %v", warnings, tt.warned)
+			}
+		})
+	}
+
+	meta := map[string]any{}
+	out, err := TranslateToOpenAI(&config.Config{}, provider.FromString("openai"), "claude-limits-test", []byte(`{"max_completion_tokens":50000}`), false, meta)
+	if err != nil {
+		t.Fatalf("passthrough: %v", err)
+	}
+	if got := gjson.GetBytes(out, "max_completion_tokens").Int(); got != 8192 || len(provider.Warnings(meta)) != 1 {
+		t.Errorf("passthrough max_completion_tokens = %d, warnings = %q", got, provider.Warnings(meta))
+	}
+}
@@ -1,75 +1,11 @@
 package executor
 
 import (
-	"strings"
-
 	"github.com/nghyane/llm-mux/internal/config"
-	"github.com/tidwall/gjson"
-	"github.com/tidwall/sjson"
 )
 
 // applyPayloadConfigToIR applies payload configuration rules (defaults and overrides)
 // from the config to the translated payload.
 func applyPayloadConfigToIR(cfg *config.Config, model string, payload []byte) []byte {
-	if cfg == nil || len(payload) == 0 {
-		return payload
-	}
-
-	// Apply defaults (only if path doesn't exist)
-	for _, rule := range cfg.Payload.Default {
-		if matchesPayloadRule(rule, model, "gemini") {
-			for path, value := range rule.Params {
-				fullPath := "request." + path
-				if !gjson.GetBytes(payload, fullPath).Exists() {
-					payload, _ = sjson.SetBytes(payload, fullPath, value)
-				}
-			}
-		}
-	}
-
-	// Apply overrides (always set)
-	for _, rule := range cfg.Payload.Override {
-		if matchesPayloadRule(rule, model, "gemini") {
-			for path, value := range rule.Params {
-				fullPath := "request." + path
-				payload, _ = sjson.SetBytes(payload, fullPath, value)
-			}
-		}
-	}
-
-	return payload
-}
-
-// matchesPayloadRule checks if a rule applies to the given model and protocol.
-func matchesPayloadRule(rule config.PayloadRule, model, protocol string) bool {
-	for _, m := range rule.Models {
-		if m.Protocol != "" && m.Protocol != protocol {
-			continue
-		}
-		if matchesPattern(m.Name, model) {
-			return true
-		}
-	}
-	return false
-}
-
-// matchesPattern checks if name matches a glob-style pattern.
-// Supports: exact match, "*" (all), "*suffix", "prefix*", "*contains*"
-func matchesPattern(pattern, name string) bool {
-	if pattern == name {
-		return true
-	}
-	if pattern == "*" {
-		return true
-	}
-	if strings.HasPrefix(pattern, "*") && strings.HasSuffix(pattern, "*") {
-		return strings.Contains(name, pattern[1:len(pattern)-1])
-	}
-	if strings.HasPrefix(pattern, "*") {
-		return strings.HasSuffix(name, pattern[1:])
-	}
-	if strings.HasSuffix(pattern, "*") {
-		return strings.HasPrefix(name, pattern[:len(pattern)-1])
-	}
-	return false
+	return applyPayloadConfigWithRoot(cfg, model, "gemini", "request", payload)
 }
@@ -12,6 +12,11 @@ func applyPayloadConfig(cfg *config.Config, model string, payload []byte) []byte
 	return applyPayloadConfigWithRoot(cfg, model, "", "", payload)
 }
 
+// applyPayloadConfigWithRoot applies the payload default and override rules
+// matching model to the parameters under root. Defaults only fill missing
+// values; overrides replace client values unless the config gives clients
+// precedence, in which case they only fill missing values too, ahead of
+// defaults.
 func applyPayloadConfigWithRoot(cfg *config.Config, model, protocol, root string, payload []byte) []byte {
 	if cfg == nil || len(payload) == 0 {
 		return payload
@@ -24,10 +29,20 @@ func applyPayloadConfigWithRoot(cfg *config.Config, model, protocol, root string
 	if model == "" {
 		return payload
 	}
-	out := payload
+	if rules.ClientFirst() {
+		out := applyPayloadRules(payload, rules.Override, model, protocol, root, false)
+		return applyPayloadRules(out, rules.Default, model, protocol, root, false)
+	}
+	out := applyPayloadRules(payload, rules.Default, model, protocol, root, false)
+	return applyPayloadRules(out, rules.Override, model, protocol, root, true)
+}
 
-	for i := range rules.Default {
-		rule := &rules.Default[i]
+// applyPayloadRules sets the params of every rule matching model. Values
+// already present are kept unless replace is set.
+func applyPayloadRules(payload []byte, rules []config.PayloadRule, model, protocol, root string, replace bool) []byte {
+	out := payload
+	for i := range rules {
+		rule := &rules[i]
 		if !payloadRuleMatchesModel(rule, model, protocol) {
 			continue
 		}
@@ -36,25 +51,7 @@ func applyPayloadConfigWithRoot(cfg *config.Config, model, protocol, root string
 			if fullPath == "" {
 				continue
 			}
-			if gjson.GetBytes(out, fullPath).Exists() {
-				continue
-			}
-			updated, errSet := sjson.SetBytes(out, fullPath, value)
-			if errSet != nil {
-				continue
-			}
-			out = updated
-		}
-	}
-
-	for i := range rules.Override {
-		rule := &rules.Override[i]
-		if !payloadRuleMatchesModel(rule, model, protocol) {
-			continue
-		}
-		for path, value := range rule.Params {
-			fullPath := buildPayloadPath(root, path)
-			if fullPath == "" {
+			if !replace && gjson.GetBytes(out, fullPath).Exists() {
 				continue
 			}
 			updated, errSet := sjson.SetBytes(out, fullPath, value)
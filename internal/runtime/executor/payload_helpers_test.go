@@ -0,0 +1,49 @@
+package executor
+
+import (
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/tidwall/gjson"
+)
+
+func TestApplyPayloadConfig_Precedence(t *testing.T) {
+	rule := func(params map[string]any) []config.PayloadRule {
+		return []config.PayloadRule{{Models: []config.PayloadModelRule{{Name: "claude-*"}}, Params: params}}
+	}
+	payload := config.PayloadConfig{
+		Default:  rule(map[string]any{"temperature": 0.2, "max_tokens": 1000, "top_p": 0.9}),
+		Override: rule(map[string]any{"temperature": 0.7, "max_tokens": 8192}),
+	}
+	client := []byte(`{"temperature":1}`)
+
+	tests := []struct {
+		name       string
+		precedence string
+		want       map[string]float64
+	}{
+		{"override forces values", "", map[string]float64{"temperature": 0.7, "max_tokens": 8192, "top_p": 0.9}},
+		{"explicit override", config.PayloadPrecedenceOverride, map[string]float64{"temperature": 0.7, "max_tokens": 8192, "top_p": 0.9}},
+		{"client keeps its values", config.PayloadPrecedenceClient, map[string]float64{"temperature": 1, "max_tokens": 8192, "top_p": 0.9}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload.Precedence = tt.precedence
+			cfg := &config.Config{Payload: payload}
+			out := applyPayloadConfig(cfg, "claude-sonnet-4-5", client)
+			for path, want := range tt.want {
+				if got := gjson.GetBytes(out, path).Float(); got != want {
+					t.Errorf("%s = %v, want %v (payload %s)", path, got, want, out)
+				}
+			}
+		})
+	}
+
+	cfg := &config.Config{Payload: payload}
+	if out := applyPayloadConfig(cfg, "gpt-4o", client); string(out) != string(client) {
+		t.Errorf("rules applied to a non-matching model: %s", out)
+	}
+	if out := applyPayloadConfigToIR(cfg, "claude-sonnet-4-5", []byte(`{"request":{}}`)); gjson.GetBytes(out, "request.max_tokens").Int() != 8192 {
+		t.Errorf("IR payload rules not applied under request: %s", out)
+	}
+}
@@ -2,6 +2,7 @@ package executor
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"net/url"
 	"strings"
@@ -13,7 +14,103 @@ import (
 	"golang.org/x/net/proxy"
 )
 
+// newProxyAwareHTTPClient builds an HTTP client honouring proxy settings and,
+// when enabled, forwarding the inbound X-Request-ID upstream. Requests the
+// executor sends without a User-Agent get the account's.
+//
+// A zero timeout selects the provider's configured timeouts: an overall limit
+// for non-streaming calls and a read-idle limit for streams. A timeout the
+// client set with X-LLM-Mux-Timeout replaces the overall limit and also bounds
+// streams. A positive timeout is applied to the whole call as-is.
 func newProxyAwareHTTPClient(ctx context.Context, cfg *config.Config, auth *provider.Auth, timeout time.Duration) *http.Client {
+	httpClient := newBaseProxyAwareHTTPClient(ctx, cfg, auth, timeout)
+	if timeout <= 0 {
+		base := httpClient.Transport
+		if base == nil {
+			base = http.DefaultTransport
+		}
+		timeouts, clientTimeout := callTimeouts(ctx, cfg, auth)
+		httpClient.Transport = &timeoutTransport{base: base, timeouts: timeouts, clientTimeout: clientTimeout}
+	}
+	if cfg == nil || !cfg.DisableResponseLengthCheck {
+		base := httpClient.Transport
+		if base == nil {
+			base = http.DefaultTransport
+		}
+		httpClient.Transport = &lengthCheckTransport{base: base}
+	}
+	if cfg != nil && cfg.ForwardRequestID {
+		if requestID := log.RequestIDFromContext(ctx); requestID != "" {
+			base := httpClient.Transport
+			if base == nil {
+				base = http.DefaultTransport
+			}
+			httpClient.Transport = &requestIDTransport{base: base, requestID: requestID}
+		}
+	}
+	base := httpClient.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	httpClient.Transport = &userAgentTransport{base: base, userAgent: accountUserAgent(auth, DefaultUserAgent())}
+	if headers := upstreamHeaders(cfg, auth); len(headers) > 0 {
+		base := httpClient.Transport
+		if base == nil {
+			base = http.DefaultTransport
+		}
+		httpClient.Transport = &headerTransport{base: base, headers: headers}
+	}
+	return httpClient
+}
+
+// upstreamHeaders resolves the configured upstream headers for auth.
+func upstreamHeaders(cfg *config.Config, auth *provider.Auth) map[string]string {
+	if cfg == nil || len(cfg.UpstreamHeaders) == 0 {
+		return nil
+	}
+	var data config.HeaderTemplateData
+	if auth != nil {
+		data = config.HeaderTemplateData{
+			ID:         auth.ID,
+			Label:      auth.Label,
+			Provider:   auth.Provider,
+			Attributes: auth.Attributes,
+			Metadata:   auth.Metadata,
+		}
+	}
+	return cfg.UpstreamHeadersFor(data.Provider, data)
+}
+
+// headerTransport sets the configured upstream headers on every request,
+// replacing executor defaults of the same name.
+type headerTransport struct {
+	base    http.RoundTripper
+	headers map[string]string
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// requestIDTransport adds the inbound request ID to upstream requests that do not set one.
+type requestIDTransport struct {
+	base      http.RoundTripper
+	requestID string
+}
+
+func (t *requestIDTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get(log.RequestIDHeader) == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set(log.RequestIDHeader, t.requestID)
+	}
+	return t.base.RoundTrip(req)
+}
+
+func newBaseProxyAwareHTTPClient(ctx context.Context, cfg *config.Config, auth *provider.Auth, timeout time.Duration) *http.Client {
 	httpClient := &http.Client{}
 	if timeout > 0 {
 		httpClient.Timeout = timeout
@@ -28,13 +125,26 @@ func newProxyAwareHTTPClient(ctx context.Context, cfg *config.Config, auth *prov
 		proxyURL = strings.TrimSpace(cfg.ProxyURL)
 	}
 
+	timeouts := transportTimeouts(ctx, cfg, auth)
 	if proxyURL != "" {
-		transport := buildProxyTransport(proxyURL)
-		if transport != nil {
-			httpClient.Transport = transport
+		transport, err := buildProxyTransport(proxyURL)
+		if err != nil {
+			// Never fall back to a direct connection: the proxy may be what
+			// keeps this account's traffic on an allowed egress.
+			if auth != nil {
+				err = fmt.Errorf("invalid proxy for %s account %s: %w", auth.Provider, auth.ID, err)
+			} else {
+				err = fmt.Errorf("invalid proxy: %w", err)
+			}
+			log.Error(err.Error())
+			httpClient.Transport = proxyErrorTransport{err: err}
 			return httpClient
 		}
-		log.Debugf("failed to setup proxy from URL: %s, falling back to context transport", proxyURL)
+		if customTransportTimeouts(timeouts) {
+			applyTransportTimeouts(transport, timeouts)
+		}
+		httpClient.Transport = transport
+		return httpClient
 	}
 
 	if rt, ok := ctx.Value("cliproxy.roundtripper").(http.RoundTripper); ok && rt != nil {
@@ -42,39 +152,64 @@ func newProxyAwareHTTPClient(ctx context.Context, cfg *config.Config, auth *prov
 		return httpClient
 	}
 
-	httpClient.Transport = SharedTransport
+	httpClient.Transport = sharedTransportFor(timeouts)
 	return httpClient
 }
 
-func buildProxyTransport(proxyURLStr string) *http.Transport {
-	if proxyURLStr == "" {
-		return nil
+// proxyErrorTransport fails every request of an account whose proxy URL
+// cannot be used.
+type proxyErrorTransport struct {
+	err error
+}
+
+func (t proxyErrorTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		_ = req.Body.Close()
 	}
+	return nil, t.err
+}
 
-	parsedURL, errParse := url.Parse(proxyURLStr)
-	if errParse != nil {
-		log.Errorf("parse proxy URL failed: %v", errParse)
-		return nil
+// buildProxyTransport returns a transport that dials through the http, https
+// or socks5 proxy at proxyURLStr.
+func buildProxyTransport(proxyURLStr string) (*http.Transport, error) {
+	if err := config.ValidateProxyURL(proxyURLStr); err != nil {
+		return nil, err
+	}
+	parsedURL, err := url.Parse(strings.TrimSpace(proxyURLStr))
+	if err != nil {
+		return nil, err
 	}
 
 	switch parsedURL.Scheme {
-	case "socks5":
-		var proxyAuth *proxy.Auth
-		if parsedURL.User != nil {
-			username := parsedURL.User.Username()
-			password, _ := parsedURL.User.Password()
-			proxyAuth = &proxy.Auth{User: username, Password: password}
-		}
-		dialer, errSOCKS5 := proxy.SOCKS5("tcp", parsedURL.Host, proxyAuth, proxy.Direct)
-		if errSOCKS5 != nil {
-			log.Errorf("create SOCKS5 dialer failed: %v", errSOCKS5)
-			return nil
+	case "socks5", "socks5h":
+		dialer, err := socks5Dialer(parsedURL)
+		if err != nil {
+			return nil, err
 		}
-		return SOCKS5Transport(dialer.Dial)
-	case "http", "https":
-		return ProxyTransport(parsedURL)
+		transport := baseTransport()
+		transport.DialContext = dialer.DialContext
+		return transport, nil
 	default:
-		log.Errorf("unsupported proxy scheme: %s", parsedURL.Scheme)
-		return nil
+		return ProxyTransport(parsedURL), nil
+	}
+}
+
+// socks5Dialer returns a dialer connecting through the SOCKS5 proxy at u,
+// authenticating with the URL's username and password when it has them.
+// Target hostnames are resolved by the proxy.
+func socks5Dialer(u *url.URL) (proxy.ContextDialer, error) {
+	var proxyAuth *proxy.Auth
+	if u.User != nil {
+		password, _ := u.User.Password()
+		proxyAuth = &proxy.Auth{User: u.User.Username(), Password: password}
+	}
+	dialer, err := proxy.SOCKS5("tcp", u.Host, proxyAuth, newDialer())
+	if err != nil {
+		return nil, fmt.Errorf("create SOCKS5 dialer: %w", err)
+	}
+	contextDialer, ok := dialer.(proxy.ContextDialer)
+	if !ok {
+		return nil, fmt.Errorf("SOCKS5 dialer does not support contexts")
 	}
+	return contextDialer, nil
 }
@@ -131,6 +131,7 @@ func (e *QwenExecutor) ExecuteStream(ctx context.Context, auth *provider.Auth, r
 
 	messageID := "chatcmpl-" + req.Model
 	processor := NewOpenAIStreamProcessor(e.cfg, from, req.Model, messageID)
+	processor.ctx.RequestUsage(opts.OriginalRequest)
 
 	return RunSSEStream(ctx, httpResp.Body, reporter, processor, StreamConfig{
 		ExecutorName:     "qwen executor",
@@ -0,0 +1,50 @@
+package executor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+func TestForwardRequestID(t *testing.T) {
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get(log.RequestIDHeader)
+	}))
+	defer srv.Close()
+
+	send := func(cfg *config.Config, ctx context.Context, executorID string) string {
+		t.Helper()
+		got = ""
+		req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+		if executorID != "" {
+			req.Header.Set(log.RequestIDHeader, executorID)
+		}
+		resp, err := newProxyAwareHTTPClient(ctx, cfg, &provider.Auth{Provider: "groq"}, 0).Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+		return got
+	}
+	ctx := log.WithRequestID(context.Background(), "trace-42")
+	forward := &config.Config{ForwardRequestID: true}
+
+	if id := send(forward, ctx, ""); id != "trace-42" {
+		t.Errorf("forwarded request ID = %q, want trace-42", id)
+	}
+	if id := send(&config.Config{}, ctx, ""); id != "" {
+		t.Errorf("request ID %q sent with forward-request-id off", id)
+	}
+	if id := send(forward, context.Background(), ""); id != "" {
+		t.Errorf("request ID %q sent without one on the context", id)
+	}
+	if id := send(forward, ctx, "executor-id"); id != "executor-id" {
+		t.Errorf("executor's request ID replaced by %q", id)
+	}
+}
@@ -0,0 +1,96 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/tidwall/gjson"
+)
+
+func TestOpenAICompatExecutor_RerankCohereStyle(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rerank" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		body, _ := io.ReadAll(r.Body)
+		if got := gjson.GetBytes(body, "documents.1").String(); got != "Paris is the capital of France" {
+			t.Errorf("documents not sent as strings: %s", body)
+		}
+		if gjson.GetBytes(body, "return_documents").Exists() {
+			t.Errorf("return_documents forwarded upstream: %s", body)
+		}
+		_, _ = w.Write([]byte(`{"id":"rr-1","results":[
+			{"index":2,"relevance_score":0.10},
+			{"index":1,"relevance_score":0.98},
+			{"index":0,"relevance_score":0.40}],
+			"meta":{"billed_units":{"search_units":1}}}`))
+	}))
+	defer srv.Close()
+
+	exec := NewOpenAICompatExecutor("cohere", &config.Config{})
+	auth := &provider.Auth{Provider: "cohere", Attributes: map[string]string{"api_key": "k", "base_url": srv.URL}}
+	payload := `{"model":"rerank-v3.5","query":"capital of France","top_n":2,"return_documents":true,
+		"documents":["Berlin is in Germany",{"text":"Paris is the capital of France"},"Bananas are yellow"]}`
+	resp, err := exec.Rerank(context.Background(), auth,
+		provider.Request{Model: "rerank-v3.5", Payload: []byte(payload)},
+		provider.Options{SourceFormat: provider.FormatOpenAI})
+	if err != nil {
+		t.Fatalf("rerank: %v", err)
+	}
+	results := gjson.GetBytes(resp.Payload, "results").Array()
+	if len(results) != 2 {
+		t.Fatalf("results = %s, want top 2", resp.Payload)
+	}
+	if results[0].Get("index").Int() != 1 || results[1].Get("index").Int() != 0 {
+		t.Errorf("results not ordered by score: %s", resp.Payload)
+	}
+	if got := results[0].Get("document.text").String(); got != "Paris is the capital of France" {
+		t.Errorf("document text = %q", got)
+	}
+	if got := gjson.GetBytes(resp.Payload, "model").String(); got != "rerank-v3.5" {
+		t.Errorf("model = %q", got)
+	}
+}
+
+func TestOpenAICompatExecutor_RerankVoyageStyle(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"object":"list","data":[{"index":1,"relevance_score":0.7},{"index":0,"relevance_score":0.2}],"usage":{"total_tokens":42}}`))
+	}))
+	defer srv.Close()
+
+	exec := NewOpenAICompatExecutor("voyage", &config.Config{})
+	auth := &provider.Auth{Provider: "voyage", Attributes: map[string]string{"base_url": srv.URL}}
+	resp, err := exec.Rerank(context.Background(), auth,
+		provider.Request{Model: "rerank-2", Payload: []byte(`{"query":"q","documents":["a","b"]}`)},
+		provider.Options{SourceFormat: provider.FormatOpenAI})
+	if err != nil {
+		t.Fatalf("rerank: %v", err)
+	}
+	if got := gjson.GetBytes(resp.Payload, "results.0.index").Int(); got != 1 {
+		t.Errorf("top result index = %d, want 1", got)
+	}
+	if gjson.GetBytes(resp.Payload, "results.0.document").Exists() {
+		t.Errorf("document returned without return_documents: %s", resp.Payload)
+	}
+	if got := gjson.GetBytes(resp.Payload, "usage.total_tokens").Int(); got != 42 {
+		t.Errorf("usage.total_tokens = %d, want 42", got)
+	}
+}
+
+func TestOpenAICompatExecutor_RerankRejectsEmptyDocuments(t *testing.T) {
+	exec := NewOpenAICompatExecutor("cohere", &config.Config{})
+	auth := &provider.Auth{Provider: "cohere", Attributes: map[string]string{"base_url": "http://127.0.0.1:1"}}
+	_, err := exec.Rerank(context.Background(), auth,
+		provider.Request{Model: "rerank-v3.5", Payload: []byte(`{"query":"q","documents":[]}`)},
+		provider.Options{SourceFormat: provider.FormatOpenAI})
+	var se StatusError
+	if !errors.As(err, &se) || se.StatusCode() != http.StatusBadRequest {
+		t.Fatalf("err = %v, want 400", err)
+	}
+}
@@ -0,0 +1,70 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+// ErrTruncatedResponse is returned when a non-streaming response body ends
+// before all the bytes its Content-Length announced arrived.
+var ErrTruncatedResponse = errors.New("upstream response truncated")
+
+// truncatedResponseError reports a short body as a retryable 502, so the
+// call moves on to another account instead of returning partial JSON.
+type truncatedResponseError struct {
+	want, got int64
+}
+
+func (e *truncatedResponseError) Error() string {
+	if e.want < 0 {
+		return fmt.Sprintf("%v after %d bytes", ErrTruncatedResponse, e.got)
+	}
+	return fmt.Sprintf("%v: received %d of %d bytes", ErrTruncatedResponse, e.got, e.want)
+}
+
+func (e *truncatedResponseError) StatusCode() int { return http.StatusBadGateway }
+
+func (e *truncatedResponseError) Category() provider.ErrorCategory { return provider.CategoryTransient }
+
+func (e *truncatedResponseError) Unwrap() error { return ErrTruncatedResponse }
+
+// lengthCheckTransport fails reads of non-streaming bodies that end short of
+// their Content-Length. Streams are left alone; their idle timeout covers a
+// stalled upstream.
+type lengthCheckTransport struct {
+	base http.RoundTripper
+}
+
+func (t *lengthCheckTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.Body == nil || resp.Body == http.NoBody || isStreamingResponse(resp) {
+		return resp, err
+	}
+	resp.Body = &lengthCheckedBody{ReadCloser: resp.Body, want: resp.ContentLength}
+	return resp, nil
+}
+
+// lengthCheckedBody counts the bytes read and turns an early end of the body
+// into a truncatedResponseError. want is -1 when the length is unknown, as
+// for bodies the transport decompressed; only a connection lost mid-body is
+// caught then.
+type lengthCheckedBody struct {
+	io.ReadCloser
+	want, got int64
+}
+
+func (b *lengthCheckedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.got += int64(n)
+	switch {
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return n, &truncatedResponseError{want: b.want, got: b.got}
+	case err == io.EOF && b.want >= 0 && b.got < b.want:
+		return n, &truncatedResponseError{want: b.want, got: b.got}
+	}
+	return n, err
+}
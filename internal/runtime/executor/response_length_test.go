@@ -0,0 +1,131 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+const completionBody = `{"id":"c1","object":"chat.completion","model":"mistral-large-latest",` +
+	`"choices":[{"index":0,"message":{"role":"assistant","content":"hello"},"finish_reason":"stop"}],` +
+	`"usage":{"prompt_tokens":3,"completion_tokens":1,"total_tokens":4}}`
+
+// truncatingServer answers with a Content-Length longer than the body it
+// sends before closing the connection, for requests whose key is "short".
+func truncatingServer(t *testing.T, calls *atomic.Int32) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.Header.Get("Authorization") != "Bearer short" {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = io.WriteString(w, completionBody)
+			return
+		}
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		_, _ = buf.WriteString("HTTP/1.1 200 OK\r\nContent-Type: application/json\r\nContent-Length: 200\r\n\r\n")
+		_, _ = buf.WriteString(completionBody[:40])
+		_ = buf.Flush()
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func mistralAuth(id, key, baseURL string) *provider.Auth {
+	return &provider.Auth{ID: id, Provider: "mistral", Attributes: map[string]string{"api_key": key, "base_url": baseURL + "/v1"}}
+}
+
+var mistralRequest = provider.Request{
+	Model:   "mistral-large-latest",
+	Payload: []byte(`{"model":"mistral-large-latest","messages":[{"role":"user","content":"hi"}]}`),
+}
+
+func TestResponseLengthCheck_ShortReadIsRetryable(t *testing.T) {
+	var calls atomic.Int32
+	srv := truncatingServer(t, &calls)
+	exec := NewMistralExecutor(&config.Config{})
+	resp, err := exec.Execute(context.Background(), mistralAuth("a", "short", srv.URL), mistralRequest,
+		provider.Options{SourceFormat: provider.FormatOpenAI})
+	if !errors.Is(err, ErrTruncatedResponse) {
+		t.Fatalf("error = %v, want ErrTruncatedResponse", err)
+	}
+	if len(resp.Payload) != 0 {
+		t.Errorf("partial body passed through: %q", resp.Payload)
+	}
+	var se interface{ StatusCode() int }
+	if !errors.As(err, &se) || se.StatusCode() != http.StatusBadGateway {
+		t.Errorf("error status = %v, want 502", se)
+	}
+	if !provider.CategorizeError(http.StatusBadGateway, err.Error()).ShouldFallback() {
+		t.Error("truncated response is not retried on another account")
+	}
+}
+
+func TestResponseLengthCheck_ManagerMovesToNextAccount(t *testing.T) {
+	var calls atomic.Int32
+	srv := truncatingServer(t, &calls)
+	m := provider.NewManager(nil, nil, nil)
+	m.RegisterExecutor(NewMistralExecutor(&config.Config{}))
+	// Register in the order the round-robin selector tries them.
+	for _, auth := range []*provider.Auth{mistralAuth("a-short", "short", srv.URL), mistralAuth("b-ok", "ok", srv.URL)} {
+		if _, err := m.Register(context.Background(), auth); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req := mistralRequest
+	req.Model = ""
+	for range 2 {
+		resp, err := m.Execute(context.Background(), []string{"mistral"}, req, provider.Options{SourceFormat: provider.FormatOpenAI})
+		if err != nil {
+			t.Fatalf("Execute: %v", err)
+		}
+		if !strings.Contains(string(resp.Payload), `"hello"`) {
+			t.Fatalf("payload = %q, want the complete response", resp.Payload)
+		}
+	}
+}
+
+// shortBody ends cleanly before the announced length, as a transport that
+// does not enforce Content-Length would deliver it.
+type shortBodyTransport struct{}
+
+func (shortBodyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode:    http.StatusOK,
+		Header:        http.Header{"Content-Type": {"application/json"}},
+		Body:          io.NopCloser(strings.NewReader(`{"id":`)),
+		ContentLength: 100,
+		Request:       req,
+	}, nil
+}
+
+func TestLengthCheckTransport(t *testing.T) {
+	read := func(rt http.RoundTripper) error {
+		req, _ := http.NewRequest(http.MethodGet, "http://upstream.test/", nil)
+		resp, err := rt.RoundTrip(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		_, err = io.ReadAll(resp.Body)
+		return err
+	}
+	err := read(&lengthCheckTransport{base: shortBodyTransport{}})
+	if !errors.Is(err, ErrTruncatedResponse) || err.Error() != "upstream response truncated: received 6 of 100 bytes" {
+		t.Fatalf("error = %v", err)
+	}
+	if err = read(shortBodyTransport{}); err != nil {
+		t.Fatalf("unchecked read = %v", err)
+	}
+}
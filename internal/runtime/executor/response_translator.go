@@ -12,6 +12,7 @@ import (
 	"github.com/nghyane/llm-mux/internal/translator/ir"
 	"github.com/nghyane/llm-mux/internal/translator/to_ir"
 	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
 )
 
 // =============================================================================
@@ -58,7 +59,11 @@ func (t *ResponseTranslator) Translate(messages []ir.Message, usage *ir.Usage, m
 		}
 		return from_ir.ToOpenAIChatCompletion(messages, usage, t.model, t.messageID)
 	case "claude":
-		return from_ir.ToClaudeResponse(messages, usage, t.model, t.messageID)
+		out, err := from_ir.ToClaudeResponse(messages, usage, t.model, t.messageID)
+		if err == nil && meta != nil && meta.ContentFilter != nil {
+			out, err = sjson.SetBytes(out, "stop_reason", ir.ClaudeStopRefusal)
+		}
+		return out, err
 	case "ollama":
 		return from_ir.ToOllamaChatResponse(messages, usage, t.model)
 	case "gemini", "gemini-cli":
@@ -96,7 +101,11 @@ func parseClaudeResponse(response []byte) (*ParsedResponse, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &ParsedResponse{Messages: messages, Usage: usage}, nil
+	parsed := &ParsedResponse{Messages: messages, Usage: usage}
+	if sr := gjson.GetBytes(response, "stop_reason").String(); ir.IsSafetyFinish(ir.MapClaudeFinishReason(sr)) {
+		parsed.Meta = &ir.OpenAIMeta{NativeFinishReason: sr, ContentFilter: ir.NewSafetyBlock(sr, nil)}
+	}
+	return parsed, nil
 }
 
 // parseGeminiResponse parses Gemini format to IR with metadata.
@@ -150,6 +159,12 @@ func TranslateResponseNonStream(cfg *config.Config, from, to provider.Format, re
 	if err != nil {
 		return nil, err
 	}
+	if parsed != nil && parsed.Meta != nil && parsed.Meta.ContentFilter != nil && cfg != nil && cfg.StrictSafetyBlocks {
+		return nil, ir.SafetyBlockError(parsed.Meta.ContentFilter)
+	}
+	if cfg != nil && cfg.RepairToolArguments {
+		ir.RepairToolCallArgs(parsed.Messages)
+	}
 
 	// Convert IR to target format
 	translator := NewResponseTranslator(cfg, toStr, model)
@@ -159,7 +174,16 @@ func TranslateResponseNonStream(cfg *config.Config, from, to provider.Format, re
 		translator.messageID = parsed.Meta.ResponseID
 	}
 
-	return translator.Translate(parsed.Messages, parsed.Usage, parsed.Meta)
+	out, err := translator.Translate(parsed.Messages, parsed.Usage, parsed.Meta)
+	if err != nil || out == nil {
+		return out, err
+	}
+	// The Gemini renderer always reports STOP; carry over the upstream reason
+	// so truncated or filtered responses keep their finishReason.
+	if (toStr == "gemini" || toStr == "gemini-cli") && gjson.GetBytes(out, "candidates.0").Exists() {
+		return sjson.SetBytes(out, "candidates.0.finishReason", ir.MapFinishReasonToGemini(responseFinishReason(fromStr, response)))
+	}
+	return out, nil
 }
 
 // handlePassthrough returns response bytes if passthrough is needed, nil otherwise.
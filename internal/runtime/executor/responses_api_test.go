@@ -0,0 +1,156 @@
+package executor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/nghyane/llm-mux/internal/translator/to_ir"
+	"github.com/tidwall/gjson"
+)
+
+func TestResponsesAPI_RoundTripThroughClaude(t *testing.T) {
+	from := provider.FromString("openai-response")
+	payload := `{"model":"claude-sonnet-4-5","instructions":"Be brief.","input":[{"role":"user","content":[{"type":"input_text","text":"Weather in Paris?"}]}],"tools":[{"type":"function","name":"get_weather","parameters":{"type":"object","properties":{"city":{"type":"string"}}}}],"max_output_tokens":256}`
+
+	body, err := TranslateToClaude(nil, from, "claude-sonnet-4-5", []byte(payload), false, nil)
+	if err != nil {
+		t.Fatalf("TranslateToClaude: %v", err)
+	}
+	req := gjson.ParseBytes(body)
+	if got := req.Get("messages.0.content.0.text").String(); got != "Weather in Paris?" {
+		t.Errorf("user text = %q (body %s)", got, body)
+	}
+	if got := req.Get("tools.0.name").String(); got != "get_weather" {
+		t.Errorf("tool = %q", got)
+	}
+	if !strings.Contains(req.Get("system").Raw, "Be brief.") {
+		t.Errorf("system = %s", req.Get("system").Raw)
+	}
+
+	upstream := `{"id":"msg_1","type":"message","role":"assistant","model":"claude-sonnet-4-5","content":[{"type":"text","text":"Checking."},{"type":"tool_use","id":"toolu_1","name":"get_weather","input":{"city":"Paris"}}],"stop_reason":"tool_use","usage":{"input_tokens":30,"output_tokens":12}}`
+	out, err := TranslateResponseNonStream(nil, provider.FromString("claude"), from, []byte(upstream), "claude-sonnet-4-5")
+	if err != nil {
+		t.Fatalf("TranslateResponseNonStream: %v", err)
+	}
+	resp := gjson.ParseBytes(out)
+	if resp.Get("object").String() != "response" || resp.Get("status").String() != "completed" {
+		t.Errorf("response = %s", out)
+	}
+	if got := resp.Get("output.0.content.0.text").String(); got != "Checking." {
+		t.Errorf("message text = %q", got)
+	}
+	call := resp.Get(`output.#(type=="function_call")`)
+	if call.Get("call_id").String() != "toolu_1" || call.Get("name").String() != "get_weather" || call.Get("arguments").String() != `{"city":"Paris"}` {
+		t.Errorf("function_call = %s", call.Raw)
+	}
+	if in, o := resp.Get("usage.input_tokens").Int(), resp.Get("usage.output_tokens").Int(); in != 30 || o != 12 {
+		t.Errorf("usage = %d/%d, want 30/12", in, o)
+	}
+}
+
+// responsesEvents runs Claude stream lines through a StreamTranslator for a
+// Responses API client and returns the emitted events.
+func responsesEvents(t *testing.T, lines []string) []gjson.Result {
+	t.Helper()
+	st := NewStreamTranslator(nil, provider.FromString("openai-response"), "openai-response", "claude-sonnet-4-5", "resp-1", NewStreamContext())
+	state := ir.NewClaudeStreamParserState()
+	var sse strings.Builder
+	for _, line := range lines {
+		events, err := to_ir.ParseClaudeChunkWithState([]byte(line), state)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := st.Translate(events)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, c := range result.Chunks {
+			sse.Write(c)
+		}
+	}
+	for _, c := range st.Flush() {
+		sse.Write(c)
+	}
+	var out []gjson.Result
+	for _, line := range strings.Split(sse.String(), "\n") {
+		if data, ok := strings.CutPrefix(line, "data: "); ok {
+			out = append(out, gjson.Parse(data))
+		}
+	}
+	return out
+}
+
+func TestResponsesAPI_StreamingEvents(t *testing.T) {
+	events := responsesEvents(t, []string{
+		`data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+		`data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Check"}}`,
+		`data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"ing."}}`,
+		`data: {"type":"content_block_stop","index":0}`,
+		`data: {"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"toolu_1","name":"get_weather","input":{}}}`,
+		`data: {"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"{\"city\":\"Paris\"}"}}`,
+		`data: {"type":"content_block_stop","index":1}`,
+		`data: {"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"input_tokens":30,"output_tokens":12}}`,
+		`data: {"type":"message_stop"}`,
+	})
+
+	var types []string
+	var text string
+	for i, ev := range events {
+		types = append(types, ev.Get("type").String())
+		if got := ev.Get("sequence_number").Int(); got != int64(i+1) {
+			t.Errorf("event %d sequence_number = %d", i, got)
+		}
+		if ev.Get("type").String() == "response.output_text.delta" {
+			text += ev.Get("delta").String()
+		}
+	}
+	want := []string{
+		"response.created", "response.in_progress",
+		"response.output_item.added", "response.content_part.added",
+		"response.output_text.delta", "response.output_text.delta",
+		"response.output_item.added", "response.function_call_arguments.delta",
+		"response.output_text.done", "response.content_part.done", "response.output_item.done",
+		"response.function_call_arguments.done", "response.output_item.done",
+		"response.completed",
+	}
+	if strings.Join(types, ",") != strings.Join(want, ",") {
+		t.Fatalf("event types:\n got %v\nwant %v", types, want)
+	}
+	if text != "Checking." {
+		t.Errorf("streamed text = %q", text)
+	}
+
+	completed := events[len(events)-1].Get("response")
+	if completed.Get("status").String() != "completed" {
+		t.Errorf("status = %q", completed.Get("status").String())
+	}
+	if got := completed.Get("output.0.content.0.text").String(); got != "Checking." {
+		t.Errorf("completed message text = %q", got)
+	}
+	call := completed.Get("output.1")
+	if call.Get("type").String() != "function_call" || call.Get("call_id").String() != "toolu_1" || call.Get("arguments").String() != `{"city":"Paris"}` {
+		t.Errorf("completed function_call = %s", call.Raw)
+	}
+	if in, o := completed.Get("usage.input_tokens").Int(), completed.Get("usage.output_tokens").Int(); in != 30 || o != 12 {
+		t.Errorf("usage = %d/%d, want 30/12", in, o)
+	}
+}
+
+func TestResponsesAPI_StreamingIncomplete(t *testing.T) {
+	events := responsesEvents(t, []string{
+		`data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+		`data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Trunc"}}`,
+		`data: {"type":"content_block_stop","index":0}`,
+		`data: {"type":"message_delta","delta":{"stop_reason":"max_tokens"},"usage":{"output_tokens":5}}`,
+		`data: {"type":"message_stop"}`,
+	})
+	last := events[len(events)-1]
+	if last.Get("type").String() != "response.incomplete" {
+		t.Fatalf("last event = %s", last.Get("type").String())
+	}
+	if got := last.Get("response.incomplete_details.reason").String(); got != "max_output_tokens" {
+		t.Errorf("incomplete reason = %q", got)
+	}
+}
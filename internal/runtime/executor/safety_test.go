@@ -0,0 +1,154 @@
+package executor
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/nghyane/llm-mux/internal/translator/to_ir"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	geminiPromptBlocked = `{"promptFeedback":{"blockReason":"PROHIBITED_CONTENT","safetyRatings":[{"category":"HARM_CATEGORY_DANGEROUS_CONTENT","probability":"HIGH"},{"category":"HARM_CATEGORY_HARASSMENT","probability":"NEGLIGIBLE"}]},"usageMetadata":{"promptTokenCount":7,"totalTokenCount":7}}`
+	geminiSafetyFinish  = `{"candidates":[{"finishReason":"SAFETY","safetyRatings":[{"category":"HARM_CATEGORY_HARASSMENT","probability":"HIGH","blocked":true}]}],"usageMetadata":{"promptTokenCount":5,"totalTokenCount":5}}`
+	claudeRefusal       = `{"id":"msg_1","type":"message","role":"assistant","content":[],"model":"claude","stop_reason":"refusal","usage":{"input_tokens":4,"output_tokens":0}}`
+)
+
+func TestTranslateResponseNonStream_SafetyBlocks(t *testing.T) {
+	tests := []struct {
+		name        string
+		from        string
+		body        string
+		blockReason string
+		blockedBy   string
+	}{
+		{"gemini prompt blockReason", "gemini", geminiPromptBlocked, "PROHIBITED_CONTENT", "HARM_CATEGORY_DANGEROUS_CONTENT"},
+		{"gemini SAFETY finish", "gemini", geminiSafetyFinish, "SAFETY", "HARM_CATEGORY_HARASSMENT"},
+		{"anthropic refusal", "claude", claudeRefusal, "refusal", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := TranslateResponseNonStream(&config.Config{}, provider.FromString(tt.from), provider.FromString("openai"), []byte(tt.body), "m")
+			if err != nil {
+				t.Fatalf("translate: %v", err)
+			}
+			choice := gjson.GetBytes(out, "choices.0")
+			if !choice.Exists() {
+				t.Fatalf("no choice in %s", out)
+			}
+			if got := choice.Get("finish_reason").String(); got != "content_filter" {
+				t.Errorf("finish_reason = %q, want content_filter", got)
+			}
+			if got := choice.Get("content_filter_results.block_reason").String(); got != tt.blockReason {
+				t.Errorf("block_reason = %q, want %q", got, tt.blockReason)
+			}
+			if got := choice.Get("content_filter_results.blocked_by").String(); got != tt.blockedBy {
+				t.Errorf("blocked_by = %q, want %q", got, tt.blockedBy)
+			}
+			if got := choice.Get("message.role").String(); got != "assistant" {
+				t.Errorf("message.role = %q, want assistant", got)
+			}
+		})
+	}
+}
+
+func TestTranslateResponseNonStream_SafetyBlockToClaude(t *testing.T) {
+	out, err := TranslateResponseNonStream(&config.Config{}, provider.FromString("gemini"), provider.FromString("claude"), []byte(geminiSafetyFinish), "m")
+	if err != nil {
+		t.Fatalf("translate: %v", err)
+	}
+	if got := gjson.GetBytes(out, "stop_reason").String(); got != "refusal" {
+		t.Errorf("stop_reason = %q, want refusal", got)
+	}
+}
+
+func TestTranslateResponseNonStream_StrictSafetyBlocks(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.StrictSafetyBlocks = true
+	for _, from := range []string{"gemini", "claude"} {
+		body := geminiPromptBlocked
+		if from == "claude" {
+			body = claudeRefusal
+		}
+		_, err := TranslateResponseNonStream(cfg, provider.FromString(from), provider.FromString("openai"), []byte(body), "m")
+		var se interface{ StatusCode() int }
+		if !errors.As(err, &se) || se.StatusCode() != http.StatusBadRequest {
+			t.Errorf("%s: err = %v, want 400 safety error", from, err)
+		}
+	}
+}
+
+func TestStreamTranslator_SafetyBlocks(t *testing.T) {
+	claudeDelta := `{"type":"message_delta","delta":{"stop_reason":"refusal"},"usage":{"output_tokens":0}}`
+	tests := []struct {
+		name  string
+		parse func() ([]ir.UnifiedEvent, error)
+		want  string
+	}{
+		{"gemini prompt blockReason", func() ([]ir.UnifiedEvent, error) { return to_ir.ParseGeminiChunk([]byte(geminiPromptBlocked)) }, "PROHIBITED_CONTENT"},
+		{"gemini SAFETY finish", func() ([]ir.UnifiedEvent, error) { return to_ir.ParseGeminiChunk([]byte(geminiSafetyFinish)) }, "SAFETY"},
+		{"anthropic refusal", func() ([]ir.UnifiedEvent, error) { return to_ir.ParseClaudeChunk([]byte(claudeDelta)) }, "refusal"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := tt.parse()
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+
+			openai := NewStreamTranslator(&config.Config{}, provider.FromString("gemini"), "openai", "m", "chatcmpl-1", NewStreamContext())
+			res, err := openai.Translate(events)
+			if err != nil {
+				t.Fatalf("translate: %v", err)
+			}
+			last := res.Chunks[len(res.Chunks)-1]
+			data := last[len("data: "):]
+			if got := gjson.GetBytes(data, "choices.0.finish_reason").String(); got != "content_filter" {
+				t.Errorf("finish_reason = %q, want content_filter in %s", got, last)
+			}
+			if got := gjson.GetBytes(data, "choices.0.content_filter_results.block_reason").String(); got != tt.want {
+				t.Errorf("block_reason = %q, want %q", got, tt.want)
+			}
+
+			claude := NewStreamTranslator(&config.Config{}, provider.FromString("gemini"), "claude", "m", "msg-1", NewStreamContext())
+			res, err = claude.Translate(events)
+			if err != nil {
+				t.Fatalf("translate: %v", err)
+			}
+			var stopReason string
+			for _, c := range res.Chunks {
+				for _, line := range splitSSEData(c) {
+					if sr := gjson.GetBytes(line, "delta.stop_reason"); sr.Exists() {
+						stopReason = sr.String()
+					}
+				}
+			}
+			if stopReason != "refusal" {
+				t.Errorf("claude stop_reason = %q, want refusal", stopReason)
+			}
+
+			strict := &config.Config{}
+			strict.StrictSafetyBlocks = true
+			st := NewStreamTranslator(strict, provider.FromString("gemini"), "openai", "m", "chatcmpl-1", NewStreamContext())
+			if _, err := st.Translate(events); err == nil {
+				t.Error("strict mode: expected an error for a blocked stream")
+			}
+		})
+	}
+}
+
+// splitSSEData returns the JSON payload of every data line in an SSE chunk.
+func splitSSEData(chunk []byte) [][]byte {
+	var out [][]byte
+	for _, line := range bytes.Split(chunk, []byte("\n")) {
+		if data, ok := bytes.CutPrefix(line, []byte("data: ")); ok {
+			out = append(out, data)
+		}
+	}
+	return out
+}
@@ -0,0 +1,303 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/nghyane/llm-mux/internal/translator/to_ir"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// =============================================================================
+// Stream Adapters - Streaming <-> Non-Streaming
+// =============================================================================
+
+// StreamModeMiddleware serves each call in the mode the account's upstream
+// supports, set by its stream_mode attribute or metadata. A non-streaming
+// request to a "stream" account is buffered from its stream, and a streaming
+// request to a "non-stream" account is chunked from its response. cfg
+// returns the current configuration.
+func StreamModeMiddleware(cfg func() *config.Config) provider.ExecutorMiddleware {
+	return func(next provider.ProviderExecutor) provider.ProviderExecutor {
+		return &provider.ExecutorFuncs{
+			Next: next,
+			ExecuteFunc: func(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (provider.Response, error) {
+				if accountStreamMode(auth) == config.StreamModeStream {
+					return ExecuteViaStream(ctx, cfg(), next, auth, req, opts)
+				}
+				return next.Execute(ctx, auth, req, opts)
+			},
+			ExecuteStreamFunc: func(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (<-chan provider.StreamChunk, error) {
+				if accountStreamMode(auth) == config.StreamModeNonStream {
+					return StreamViaExecute(ctx, cfg(), next, auth, req, opts)
+				}
+				return next.ExecuteStream(ctx, auth, req, opts)
+			},
+		}
+	}
+}
+
+// accountStreamMode returns the upstream mode auth is restricted to, or ""
+// when it serves both.
+func accountStreamMode(auth *provider.Auth) string {
+	if auth == nil {
+		return ""
+	}
+	if mode := AttrStringValue(auth.Attributes, "stream_mode"); mode != "" {
+		return mode
+	}
+	return MetaStringValue(auth.Metadata, "stream_mode")
+}
+
+// ExecuteViaStream serves a non-streaming request from exec's streaming
+// path, buffering the stream into one response.
+func ExecuteViaStream(ctx context.Context, cfg *config.Config, exec provider.ProviderExecutor, auth *provider.Auth, req provider.Request, opts provider.Options) (provider.Response, error) {
+	opts.Stream = true
+	stream, err := exec.ExecuteStream(ctx, auth, req, opts)
+	if err != nil {
+		return provider.Response{}, err
+	}
+	var chunks [][]byte
+	for chunk := range stream {
+		// Keep draining after an error so the producer can finish.
+		if chunk.Err != nil && err == nil {
+			err = chunk.Err
+		}
+		if len(chunk.Payload) > 0 {
+			chunks = append(chunks, chunk.Payload)
+		}
+	}
+	if err != nil {
+		return provider.Response{}, err
+	}
+	out, err := BufferStream(cfg, opts.SourceFormat, req.Model, chunks)
+	if err != nil {
+		return provider.Response{}, err
+	}
+	return provider.Response{Payload: out}, nil
+}
+
+// StreamViaExecute serves a streaming request from exec's non-streaming
+// path, replaying the response as synthetic stream chunks.
+func StreamViaExecute(ctx context.Context, cfg *config.Config, exec provider.ProviderExecutor, auth *provider.Auth, req provider.Request, opts provider.Options) (<-chan provider.StreamChunk, error) {
+	opts.Stream = false
+	resp, err := exec.Execute(ctx, auth, req, opts)
+	if err != nil {
+		return nil, err
+	}
+	chunks, err := ChunkResponse(cfg, opts.SourceFormat, req.Model, resp.Payload)
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan provider.StreamChunk, len(chunks))
+	for _, chunk := range chunks {
+		out <- provider.StreamChunk{Payload: chunk}
+	}
+	close(out)
+	return out, nil
+}
+
+// BufferStream collects the chunks of a stream already rendered in format
+// into the single response a non-streaming client of that format expects.
+func BufferStream(cfg *config.Config, format provider.Format, model string, chunks [][]byte) ([]byte, error) {
+	to := format.String()
+	parse, err := streamChunkParser(to)
+	if err != nil {
+		return nil, err
+	}
+	acc := ir.NewStreamAccumulator()
+	var responseID string
+	for _, chunk := range chunks {
+		for _, line := range bytes.Split(chunk, []byte("\n")) {
+			line = bytes.TrimSpace(line)
+			if len(line) == 0 || line[0] == ':' || bytes.HasPrefix(line, []byte("event:")) {
+				continue
+			}
+			data := ir.ExtractSSEData(line)
+			if responseID == "" {
+				responseID = gjson.GetBytes(data, "id").String()
+				if responseID == "" {
+					responseID = gjson.GetBytes(data, "message.id").String()
+				}
+			}
+			events, err := parse(line)
+			if err != nil {
+				return nil, err
+			}
+			acc.Add(events...)
+		}
+	}
+	if err := acc.Err(); err != nil {
+		return nil, err
+	}
+
+	var meta *ir.OpenAIMeta
+	finish := acc.FinishReason()
+	if cf := acc.ContentFilter(); cf != nil {
+		meta = &ir.OpenAIMeta{ContentFilter: ir.ParseContentFilter(cf)}
+	}
+	if meta != nil && cfg != nil && cfg.StrictSafetyBlocks {
+		return nil, ir.SafetyBlockError(meta.ContentFilter)
+	}
+	translator := NewResponseTranslator(cfg, to, model)
+	if responseID != "" {
+		translator.messageID = responseID
+	}
+	out, err := translator.Translate(acc.Messages(), acc.Usage(), meta)
+	if err != nil || out == nil {
+		return out, err
+	}
+	// The renderers infer the finish reason from the content; restore the
+	// one the stream reported (length, stop_sequence, ...).
+	switch to {
+	case "openai", "cline":
+		if meta == nil {
+			return sjson.SetBytes(out, "choices.0.finish_reason", ir.MapFinishReasonToOpenAI(finish))
+		}
+	case "claude":
+		return sjson.SetBytes(out, "stop_reason", ir.MapFinishReasonToClaude(finish))
+	case "gemini", "gemini-cli":
+		return sjson.SetBytes(out, "candidates.0.finishReason", ir.MapFinishReasonToGemini(finish))
+	}
+	return out, nil
+}
+
+// streamChunkParser returns the parser for stream lines in format.
+func streamChunkParser(format string) (func([]byte) ([]ir.UnifiedEvent, error), error) {
+	switch format {
+	case "openai", "cline":
+		return to_ir.ParseOpenAIChunk, nil
+	case "gemini", "gemini-cli":
+		return to_ir.ParseGeminiChunk, nil
+	case "claude":
+		state := ir.NewClaudeStreamParserState()
+		return func(line []byte) ([]ir.UnifiedEvent, error) {
+			// message_start carries the input token count, which the
+			// chunk parser leaves to the stream translator.
+			if msg := gjson.GetBytes(ir.ExtractSSEData(line), "message"); msg.Exists() {
+				if u := msg.Get("usage"); u.Exists() {
+					return []ir.UnifiedEvent{{Type: ir.EventTypeStreamMeta, Usage: ir.ParseClaudeUsage(u)}}, nil
+				}
+				return nil, nil
+			}
+			return to_ir.ParseClaudeChunkWithState(line, state)
+		}, nil
+	default:
+		return nil, fmt.Errorf("buffering a %s stream is not supported", format)
+	}
+}
+
+// ChunkResponse splits a complete response in format into the stream chunks
+// a streaming client of that format expects.
+func ChunkResponse(cfg *config.Config, format provider.Format, model string, response []byte) ([][]byte, error) {
+	to := format.String()
+	if !streamableFormat(to) {
+		return nil, fmt.Errorf("streaming a %s response is not supported", to)
+	}
+	parsed, err := parseSourceResponse(to, response)
+	if err != nil {
+		return nil, err
+	}
+	events := ir.ResponseEvents(parsed.Messages, parsed.Usage, responseFinishReason(to, response))
+	messageID := generateMessageID(to, model)
+	if parsed.Meta != nil {
+		if parsed.Meta.ResponseID != "" {
+			messageID = parsed.Meta.ResponseID
+		}
+		if parsed.Meta.ContentFilter != nil {
+			events[len(events)-1].ContentFilter = parsed.Meta.ContentFilter
+		}
+	} else if id := gjson.GetBytes(response, "id").String(); id != "" {
+		messageID = id
+	}
+
+	st := NewStreamTranslator(cfg, format, to, model, messageID, NewStreamContext())
+	result, err := st.Translate(events)
+	if err != nil {
+		return nil, err
+	}
+	return append(result.Chunks, st.Flush()...), nil
+}
+
+// responseFinishReason reads the finish reason of a complete response.
+func responseFinishReason(format string, response []byte) ir.FinishReason {
+	switch format {
+	case "openai", "cline":
+		return ir.MapOpenAIFinishReason(gjson.GetBytes(response, "choices.0.finish_reason").String())
+	case "claude":
+		return ir.MapClaudeFinishReason(gjson.GetBytes(response, "stop_reason").String())
+	case "gemini", "gemini-cli":
+		r := gjson.GetBytes(response, "candidates.0.finishReason")
+		if !r.Exists() {
+			r = gjson.GetBytes(response, "response.candidates.0.finishReason")
+		}
+		return ir.MapGeminiFinishReason(r.String())
+	}
+	return ir.FinishReasonUnknown
+}
+
+// streamableFormat reports whether StreamTranslator renders chunks for
+// clients of format that can also be buffered back into one response.
+func streamableFormat(format string) bool {
+	switch format {
+	case "openai", "cline", "claude", "gemini", "gemini-cli":
+		return true
+	}
+	return false
+}
+
+// ChunkTranslator converts an upstream stream, one line at a time, into the
+// chunks a streaming client of another format expects.
+type ChunkTranslator struct {
+	parse      func([]byte) ([]ir.UnifiedEvent, error)
+	translator *StreamTranslator
+}
+
+// NewChunkTranslator returns a ChunkTranslator for a stream in format from
+// rendered for clients of format to.
+func NewChunkTranslator(cfg *config.Config, from, to provider.Format, model string) (*ChunkTranslator, error) {
+	toStr := to.String()
+	if !streamableFormat(toStr) {
+		return nil, fmt.Errorf("streaming a %s response is not supported", toStr)
+	}
+	streamCtx := NewStreamContext()
+	t := &ChunkTranslator{translator: NewStreamTranslator(cfg, to, toStr, model, generateMessageID(toStr, model), streamCtx)}
+	switch from.String() {
+	case "openai", "cline":
+		t.parse = to_ir.ParseOpenAIChunk
+	case "gemini", "gemini-cli":
+		t.parse = to_ir.ParseGeminiChunk
+	case "claude":
+		state := streamCtx.ClaudeState.ParserState
+		t.parse = func(line []byte) ([]ir.UnifiedEvent, error) {
+			return to_ir.ParseClaudeChunkWithState(line, state)
+		}
+	default:
+		return nil, fmt.Errorf("translating a %s stream is not supported", from)
+	}
+	return t, nil
+}
+
+// Translate converts one line of the upstream stream. Lines that carry no
+// event, such as SSE event names and keep-alives, yield no chunks.
+func (t *ChunkTranslator) Translate(line []byte) ([][]byte, error) {
+	events, err := t.parse(line)
+	if err != nil || len(events) == 0 {
+		return nil, err
+	}
+	result, err := t.translator.Translate(events)
+	if err != nil {
+		return nil, err
+	}
+	return result.Chunks, nil
+}
+
+// Flush returns the chunks held back until the stream ends.
+func (t *ChunkTranslator) Flush() [][]byte {
+	return t.translator.Flush()
+}
@@ -0,0 +1,321 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/tidwall/gjson"
+)
+
+// fixedExecutor answers Execute with response and ExecuteStream with chunks.
+type fixedExecutor struct {
+	response []byte
+	chunks   []string
+}
+
+func (fixedExecutor) Identifier() string { return "fixed" }
+
+func (e fixedExecutor) Execute(context.Context, *provider.Auth, provider.Request, provider.Options) (provider.Response, error) {
+	return provider.Response{Payload: e.response}, nil
+}
+
+func (e fixedExecutor) ExecuteStream(context.Context, *provider.Auth, provider.Request, provider.Options) (<-chan provider.StreamChunk, error) {
+	out := make(chan provider.StreamChunk, len(e.chunks))
+	for _, c := range e.chunks {
+		out <- provider.StreamChunk{Payload: []byte(c)}
+	}
+	close(out)
+	return out, nil
+}
+
+func (fixedExecutor) Refresh(_ context.Context, auth *provider.Auth) (*provider.Auth, error) {
+	return auth, nil
+}
+
+func (fixedExecutor) CountTokens(context.Context, *provider.Auth, provider.Request, provider.Options) (provider.Response, error) {
+	return provider.Response{}, nil
+}
+
+func TestExecuteViaStream_OpenAI(t *testing.T) {
+	exec := fixedExecutor{chunks: []string{
+		`data: {"id":"chatcmpl-1","choices":[{"index":0,"delta":{"role":"assistant","content":"Hel"}}]}` + "\n\n",
+		`data: {"id":"chatcmpl-1","choices":[{"index":0,"delta":{"content":"lo"}}]}` + "\n\n",
+		`data: {"id":"chatcmpl-1","choices":[{"index":0,"delta":{},"finish_reason":"length"}]}` + "\n\n",
+		`data: {"id":"chatcmpl-1","choices":[],"usage":{"prompt_tokens":7,"completion_tokens":2,"total_tokens":9}}` + "\n\n",
+		"data: [DONE]\n\n",
+	}}
+	resp, err := ExecuteViaStream(context.Background(), nil, exec, nil,
+		provider.Request{Model: "gpt-4o"}, provider.Options{SourceFormat: provider.FromString("openai")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := gjson.ParseBytes(resp.Payload)
+	if got := out.Get("choices.0.message.content").String(); got != "Hello" {
+		t.Errorf("content = %q, want Hello", got)
+	}
+	if got := out.Get("choices.0.finish_reason").String(); got != "length" {
+		t.Errorf("finish_reason = %q, want length", got)
+	}
+	if p, c, tot := out.Get("usage.prompt_tokens").Int(), out.Get("usage.completion_tokens").Int(), out.Get("usage.total_tokens").Int(); p != 7 || c != 2 || tot != 9 {
+		t.Errorf("usage = %d/%d/%d, want 7/2/9", p, c, tot)
+	}
+}
+
+func TestExecuteViaStream_OpenAIToolCalls(t *testing.T) {
+	exec := fixedExecutor{chunks: []string{
+		`data: {"id":"c","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"get_weather","arguments":""}}]}}]}`,
+		`data: {"id":"c","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"city\":"}}]}}]}`,
+		`data: {"id":"c","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"Paris\"}"}}]}}]}`,
+		`data: {"id":"c","choices":[{"index":0,"delta":{},"finish_reason":"tool_calls"}],"usage":{"prompt_tokens":3,"completion_tokens":5,"total_tokens":8}}`,
+	}}
+	resp, err := ExecuteViaStream(context.Background(), nil, exec, nil,
+		provider.Request{Model: "gpt-4o"}, provider.Options{SourceFormat: provider.FromString("openai")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := gjson.ParseBytes(resp.Payload)
+	call := out.Get("choices.0.message.tool_calls.0")
+	if call.Get("function.name").String() != "get_weather" || call.Get("function.arguments").String() != `{"city":"Paris"}` {
+		t.Errorf("tool call = %s", call.Raw)
+	}
+	if got := out.Get("choices.0.finish_reason").String(); got != "tool_calls" {
+		t.Errorf("finish_reason = %q, want tool_calls", got)
+	}
+	if got := out.Get("usage.total_tokens").Int(); got != 8 {
+		t.Errorf("total_tokens = %d, want 8", got)
+	}
+}
+
+func TestExecuteViaStream_Claude(t *testing.T) {
+	exec := fixedExecutor{chunks: []string{
+		"event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"id\":\"msg_1\",\"type\":\"message\",\"role\":\"assistant\",\"content\":[],\"usage\":{\"input_tokens\":12,\"output_tokens\":1}}}\n\n",
+		"event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":0,\"content_block\":{\"type\":\"text\",\"text\":\"\"}}\n\n",
+		"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"Hi there\"}}\n\n",
+		"event: content_block_stop\ndata: {\"type\":\"content_block_stop\",\"index\":0}\n\n",
+		"event: message_delta\ndata: {\"type\":\"message_delta\",\"delta\":{\"stop_reason\":\"max_tokens\"},\"usage\":{\"output_tokens\":4}}\n\n",
+		"event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n",
+	}}
+	resp, err := ExecuteViaStream(context.Background(), nil, exec, nil,
+		provider.Request{Model: "claude-sonnet-4-5"}, provider.Options{SourceFormat: provider.FromString("claude")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := gjson.ParseBytes(resp.Payload)
+	if got := out.Get("content.0.text").String(); got != "Hi there" {
+		t.Errorf("text = %q", got)
+	}
+	if got := out.Get("id").String(); got != "msg_1" {
+		t.Errorf("id = %q, want msg_1", got)
+	}
+	if got := out.Get("stop_reason").String(); got != "max_tokens" {
+		t.Errorf("stop_reason = %q, want max_tokens", got)
+	}
+	if in, o := out.Get("usage.input_tokens").Int(), out.Get("usage.output_tokens").Int(); in != 12 || o != 4 {
+		t.Errorf("usage = %d/%d, want 12/4", in, o)
+	}
+}
+
+func TestExecuteViaStream_StreamError(t *testing.T) {
+	exec := fixedExecutor{chunks: []string{
+		"event: error\ndata: {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\",\"message\":\"Overloaded\"}}\n\n",
+	}}
+	_, err := ExecuteViaStream(context.Background(), nil, exec, nil,
+		provider.Request{Model: "claude-sonnet-4-5"}, provider.Options{SourceFormat: provider.FromString("claude")})
+	if err == nil {
+		t.Fatal("expected the stream error to be returned")
+	}
+}
+
+func TestStreamViaExecute_OpenAI(t *testing.T) {
+	exec := fixedExecutor{response: []byte(`{"id":"chatcmpl-9","object":"chat.completion","model":"gpt-4o","choices":[{"index":0,"message":{"role":"assistant","content":"Sure.","tool_calls":[{"id":"call_1","type":"function","function":{"name":"lookup","arguments":"{\"q\":1}"}}]},"finish_reason":"tool_calls"}],"usage":{"prompt_tokens":10,"completion_tokens":6,"total_tokens":16}}`)}
+	stream, err := StreamViaExecute(context.Background(), nil, exec, nil,
+		provider.Request{Model: "gpt-4o"}, provider.Options{SourceFormat: provider.FromString("openai"), Stream: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var content, args, finish string
+	var usage gjson.Result
+	for chunk := range stream {
+		if chunk.Err != nil {
+			t.Fatal(chunk.Err)
+		}
+		data := gjson.ParseBytes([]byte(strings.TrimPrefix(strings.TrimSpace(string(chunk.Payload)), "data: ")))
+		content += data.Get("choices.0.delta.content").String()
+		args += data.Get("choices.0.delta.tool_calls.0.function.arguments").String()
+		if r := data.Get("choices.0.finish_reason").String(); r != "" {
+			finish = r
+		}
+		if u := data.Get("usage"); u.Exists() {
+			usage = u
+		}
+	}
+	if content != "Sure." || args != `{"q":1}` {
+		t.Errorf("content = %q, args = %q", content, args)
+	}
+	if finish != "tool_calls" {
+		t.Errorf("finish_reason = %q, want tool_calls", finish)
+	}
+	if usage.Get("prompt_tokens").Int() != 10 || usage.Get("completion_tokens").Int() != 6 {
+		t.Errorf("usage = %s", usage.Raw)
+	}
+}
+
+func TestStreamViaExecute_Claude(t *testing.T) {
+	exec := fixedExecutor{response: []byte(`{"id":"msg_2","type":"message","role":"assistant","content":[{"type":"text","text":"Truncated"}],"model":"claude","stop_reason":"max_tokens","usage":{"input_tokens":20,"output_tokens":8}}`)}
+	stream, err := StreamViaExecute(context.Background(), nil, exec, nil,
+		provider.Request{Model: "claude-sonnet-4-5"}, provider.Options{SourceFormat: provider.FromString("claude"), Stream: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sse strings.Builder
+	for chunk := range stream {
+		sse.Write(chunk.Payload)
+	}
+	var text, stop string
+	var outputTokens int64
+	for _, line := range strings.Split(sse.String(), "\n") {
+		data, ok := strings.CutPrefix(line, "data: ")
+		if !ok {
+			continue
+		}
+		ev := gjson.Parse(data)
+		switch ev.Get("type").String() {
+		case "content_block_delta":
+			text += ev.Get("delta.text").String()
+		case "message_delta":
+			stop = ev.Get("delta.stop_reason").String()
+			outputTokens = ev.Get("usage.output_tokens").Int()
+		}
+	}
+	if text != "Truncated" {
+		t.Errorf("text = %q", text)
+	}
+	if stop != "max_tokens" {
+		t.Errorf("stop_reason = %q, want max_tokens", stop)
+	}
+	if outputTokens != 8 {
+		t.Errorf("output_tokens = %d, want 8", outputTokens)
+	}
+}
+
+func TestStreamViaExecute_GeminiFinishReason(t *testing.T) {
+	exec := fixedExecutor{response: []byte(`{"candidates":[{"content":{"role":"model","parts":[{"text":"cut"}]},"finishReason":"MAX_TOKENS"}],"usageMetadata":{"promptTokenCount":4,"candidatesTokenCount":2,"totalTokenCount":6}}`)}
+	stream, err := StreamViaExecute(context.Background(), nil, exec, nil,
+		provider.Request{Model: "gemini-2.5-pro"}, provider.Options{SourceFormat: provider.FromString("gemini"), Stream: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var last gjson.Result
+	for chunk := range stream {
+		last = gjson.ParseBytes(chunk.Payload)
+	}
+	if got := last.Get("candidates.0.finishReason").String(); got != "MAX_TOKENS" {
+		t.Errorf("finishReason = %q, want MAX_TOKENS", got)
+	}
+	if got := last.Get("usageMetadata.totalTokenCount").Int(); got != 6 {
+		t.Errorf("totalTokenCount = %d, want 6", got)
+	}
+}
+
+// singleModeExecutor serves only the mode it has a reply for and fails
+// calls in the other.
+type singleModeExecutor struct {
+	fixedExecutor
+	calls *[]string
+}
+
+func (e singleModeExecutor) Execute(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (provider.Response, error) {
+	*e.calls = append(*e.calls, "execute")
+	if e.response == nil {
+		return provider.Response{}, errors.New("upstream only streams")
+	}
+	return e.fixedExecutor.Execute(ctx, auth, req, opts)
+}
+
+func (e singleModeExecutor) ExecuteStream(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (<-chan provider.StreamChunk, error) {
+	*e.calls = append(*e.calls, "stream")
+	if e.chunks == nil {
+		return nil, errors.New("upstream cannot stream")
+	}
+	return e.fixedExecutor.ExecuteStream(ctx, auth, req, opts)
+}
+
+func newStreamModeManager(t *testing.T, exec singleModeExecutor, mode string) *provider.Manager {
+	t.Helper()
+	m := provider.NewManager(nil, nil, nil)
+	m.Use(StreamModeMiddleware(func() *config.Config { return nil }))
+	m.RegisterExecutor(exec)
+	auth := &provider.Auth{ID: "fixed-1", Provider: "fixed", Attributes: map[string]string{"stream_mode": mode}}
+	if _, err := m.Register(context.Background(), auth); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestStreamModeMiddleware_StreamOnlyAccount(t *testing.T) {
+	var calls []string
+	exec := singleModeExecutor{calls: &calls, fixedExecutor: fixedExecutor{chunks: []string{
+		`data: {"id":"chatcmpl-1","choices":[{"index":0,"delta":{"role":"assistant","content":"Hi"}}]}` + "\n\n",
+		`data: {"id":"chatcmpl-1","choices":[{"index":0,"delta":{},"finish_reason":"stop"}],"usage":{"prompt_tokens":4,"completion_tokens":1,"total_tokens":5}}` + "\n\n",
+	}}}
+	m := newStreamModeManager(t, exec, config.StreamModeStream)
+
+	resp, err := m.Execute(context.Background(), []string{"fixed"}, provider.Request{}, provider.Options{SourceFormat: provider.FromString("openai")})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	out := gjson.ParseBytes(resp.Payload)
+	if out.Get("choices.0.message.content").String() != "Hi" || out.Get("usage.total_tokens").Int() != 5 {
+		t.Errorf("response = %s", resp.Payload)
+	}
+	if len(calls) != 1 || calls[0] != "stream" {
+		t.Errorf("upstream calls = %v, want one stream", calls)
+	}
+}
+
+func TestStreamModeMiddleware_NonStreamAccount(t *testing.T) {
+	var calls []string
+	exec := singleModeExecutor{calls: &calls, fixedExecutor: fixedExecutor{
+		response: []byte(`{"id":"chatcmpl-2","object":"chat.completion","choices":[{"index":0,"message":{"role":"assistant","content":"Hello"},"finish_reason":"length"}],"usage":{"prompt_tokens":3,"completion_tokens":2,"total_tokens":5}}`),
+	}}
+	m := newStreamModeManager(t, exec, config.StreamModeNonStream)
+
+	stream, err := m.ExecuteStream(context.Background(), []string{"fixed"}, provider.Request{}, provider.Options{SourceFormat: provider.FromString("openai"), Stream: true})
+	if err != nil {
+		t.Fatalf("ExecuteStream: %v", err)
+	}
+	var content, finish string
+	for chunk := range stream {
+		if chunk.Err != nil {
+			t.Fatal(chunk.Err)
+		}
+		data := gjson.ParseBytes([]byte(strings.TrimPrefix(strings.TrimSpace(string(chunk.Payload)), "data: ")))
+		content += data.Get("choices.0.delta.content").String()
+		if r := data.Get("choices.0.finish_reason").String(); r != "" {
+			finish = r
+		}
+	}
+	if content != "Hello" || finish != "length" {
+		t.Errorf("content = %q, finish_reason = %q", content, finish)
+	}
+	if len(calls) != 1 || calls[0] != "execute" {
+		t.Errorf("upstream calls = %v, want one execute", calls)
+	}
+}
+
+func TestStreamModeMiddleware_DefaultPassesThrough(t *testing.T) {
+	var calls []string
+	exec := singleModeExecutor{calls: &calls, fixedExecutor: fixedExecutor{response: []byte(`{}`)}}
+	m := newStreamModeManager(t, exec, "")
+
+	if _, err := m.ExecuteStream(context.Background(), []string{"fixed"}, provider.Request{}, provider.Options{SourceFormat: provider.FromString("openai"), Stream: true}); err == nil {
+		t.Fatal("a streaming call to an account without stream-mode reached a non-streaming path")
+	}
+	if len(calls) == 0 || calls[0] != "stream" {
+		t.Errorf("upstream calls = %v, want stream", calls)
+	}
+}
@@ -8,17 +8,27 @@ import (
 
 type StreamContext struct {
 	ClaudeState          *from_ir.ClaudeStreamState
+	ResponsesState       *from_ir.ResponsesStreamState
 	ToolCallIndex        int
 	HasToolCalls         bool
 	FinishSent           bool
 	ReasoningCharsAccum  int
 	ToolSchemaCtx        *ir.ToolSchemaContext
 	EstimatedInputTokens int64
+
+	// IncludeUsage is set when the client asked for a final usage chunk
+	// (stream_options.include_usage); usageRequest is its original body.
+	IncludeUsage     bool
+	usageRequest     []byte
+	UsageSeen        bool
+	OutputCharsAccum int
+	usageSynthesized bool
 }
 
 func NewStreamContext() *StreamContext {
 	return &StreamContext{
-		ClaudeState: from_ir.NewClaudeStreamState(),
+		ClaudeState:    from_ir.NewClaudeStreamState(),
+		ResponsesState: from_ir.NewResponsesStreamState(),
 	}
 }
 
@@ -48,3 +58,37 @@ func (s *StreamContext) AccumulateReasoning(text string) {
 func (s *StreamContext) EstimateReasoningTokens() int32 {
 	return int32(s.ReasoningCharsAccum / 3)
 }
+
+// RequestUsage enables usage synthesis when the client's OpenAI request set
+// stream_options.include_usage.
+func (s *StreamContext) RequestUsage(originalRequest []byte) {
+	if gjson.GetBytes(originalRequest, "stream_options.include_usage").Bool() {
+		s.IncludeUsage = true
+		s.usageRequest = originalRequest
+	}
+}
+
+func (s *StreamContext) AccumulateOutput(text string) {
+	s.OutputCharsAccum += len(text)
+}
+
+// synthesizeUsage estimates usage for a stream whose upstream reported none.
+// Input tokens come from the translation estimate when known, otherwise from
+// tokenizing the client's request; output tokens from the streamed text.
+func (s *StreamContext) synthesizeUsage(model string) *ir.Usage {
+	prompt := s.EstimatedInputTokens
+	if prompt == 0 {
+		if enc, err := tokenizerForModel(model); err == nil {
+			prompt, _ = countOpenAIChatTokens(enc, s.usageRequest)
+		}
+	}
+	reasoning := s.EstimateReasoningTokens()
+	completion := int64(s.OutputCharsAccum/4) + int64(reasoning)
+	return &ir.Usage{
+		PromptTokens:       prompt,
+		CompletionTokens:   completion,
+		TotalTokens:        prompt + completion,
+		ThoughtsTokenCount: reasoning,
+		Approximate:        true,
+	}
+}
@@ -66,6 +66,19 @@ func (t *StreamTranslator) Translate(events []ir.UnifiedEvent) (*StreamTranslati
 	for i := range events {
 		event := &events[i]
 
+		// Strict mode fails the stream instead of finishing with content_filter
+		if event.Type == ir.EventTypeFinish && event.ContentFilter != nil && t.cfg != nil && t.cfg.StrictSafetyBlocks {
+			return nil, ir.SafetyBlockError(ir.ParseContentFilter(event.ContentFilter))
+		}
+
+		// OpenAI-style upstreams report usage in a chunk after the finish
+		// chunk; forward it as the client's usage chunk.
+		if event.Type == ir.EventTypeFinish && t.ctx.FinishSent && !t.ctx.UsageSeen && t.wantsUsageChunk() && hasReportedUsage(event.Usage) {
+			t.ctx.UsageSeen = true
+			allChunks = append(allChunks, from_ir.ToOpenAIUsageChunk(event.Usage, t.model, t.messageID))
+			continue
+		}
+
 		// Apply preprocessing (state tracking, deduplication)
 		if t.preprocess(event) {
 			continue // skip event
@@ -97,13 +110,46 @@ func (t *StreamTranslator) Translate(events []ir.UnifiedEvent) (*StreamTranslati
 	}, nil
 }
 
-// Flush returns any buffered chunks (call on stream end)
+// Flush returns any buffered chunks (call on stream end). When the client
+// asked for usage and the upstream never reported any, an approximate usage
+// chunk is appended.
 func (t *StreamTranslator) Flush() [][]byte {
-	return t.buffer.Flush()
+	chunks := t.buffer.Flush()
+	if t.wantsUsageChunk() && t.ctx.FinishSent && !t.ctx.UsageSeen && !t.ctx.usageSynthesized {
+		t.ctx.usageSynthesized = true
+		chunks = append(chunks, from_ir.ToOpenAIUsageChunk(t.ctx.synthesizeUsage(t.model), t.model, t.messageID))
+	}
+	return chunks
+}
+
+// wantsUsageChunk reports whether the client expects a trailing usage chunk.
+func (t *StreamTranslator) wantsUsageChunk() bool {
+	return t.ctx.IncludeUsage && (t.to == "openai" || t.to == "cline")
+}
+
+// hasReportedUsage reports whether u carries token counts from the upstream.
+func hasReportedUsage(u *ir.Usage) bool {
+	return u != nil && (u.PromptTokens > 0 || u.CompletionTokens > 0 || u.TotalTokens > 0)
 }
 
 // preprocess handles state tracking (tool calls, reasoning, finish dedup)
 func (t *StreamTranslator) preprocess(event *ir.UnifiedEvent) bool {
+	if hasReportedUsage(event.Usage) {
+		t.ctx.UsageSeen = true
+	}
+
+	// Track visible output for usage synthesis
+	switch event.Type {
+	case ir.EventTypeToken:
+		t.ctx.AccumulateOutput(event.Content)
+		t.ctx.AccumulateOutput(event.Refusal)
+	case ir.EventTypeToolCall, ir.EventTypeToolCallDelta:
+		if event.ToolCall != nil {
+			t.ctx.AccumulateOutput(event.ToolCall.Name)
+			t.ctx.AccumulateOutput(event.ToolCall.Args)
+		}
+	}
+
 	// Track tool calls - mark HasToolCalls but don't increment index yet
 	// Index increment happens in convertEvent to maintain correct 0-based indexing
 	if event.Type == ir.EventTypeToolCall {
@@ -164,6 +210,12 @@ func (t *StreamTranslator) convertEvent(event *ir.UnifiedEvent) ([]byte, error)
 		return from_ir.ToGeminiChunk(*event, t.model)
 	case "ollama":
 		return from_ir.ToOllamaChatChunk(*event, t.model)
+	case "codex", "openai-response":
+		events, err := from_ir.ToResponsesAPIChunk(*event, t.model, t.ctx.ResponsesState)
+		if err != nil || len(events) == 0 {
+			return nil, err
+		}
+		return []byte(strings.Join(events, "")), nil
 	default:
 		return nil, nil // unsupported format
 	}
@@ -0,0 +1,99 @@
+package executor
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/tidwall/gjson"
+)
+
+const includeUsageRequest = `{"model":"gpt-4o","stream":true,"stream_options":{"include_usage":true},"messages":[{"role":"user","content":"Say hello to the world"}]}`
+
+// runOpenAIStream feeds SSE data lines through an OpenAI stream processor and
+// returns the usage objects of every emitted chunk that has one.
+func runOpenAIStream(t *testing.T, request string, lines ...string) []gjson.Result {
+	t.Helper()
+	p := NewOpenAIStreamProcessor(&config.Config{}, provider.FromString("openai"), "gpt-4o", "chatcmpl-1")
+	p.ctx.RequestUsage([]byte(request))
+	var out [][]byte
+	for _, line := range lines {
+		chunks, _, err := p.ProcessLine([]byte(line))
+		if err != nil {
+			t.Fatalf("process %s: %v", line, err)
+		}
+		out = append(out, chunks...)
+	}
+	done, _ := p.ProcessDone()
+	out = append(out, done...)
+
+	var usages []gjson.Result
+	for _, chunk := range out {
+		data := bytes.TrimSpace(bytes.TrimPrefix(bytes.TrimSpace(chunk), []byte("data:")))
+		if u := gjson.GetBytes(data, "usage"); u.Exists() {
+			usages = append(usages, u)
+		}
+	}
+	return usages
+}
+
+func TestStreamUsage_SynthesizedWhenUpstreamOmitsIt(t *testing.T) {
+	usages := runOpenAIStream(t, includeUsageRequest,
+		`{"choices":[{"index":0,"delta":{"content":"Hello there, world!"}}]}`,
+		`{"choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`,
+	)
+	if len(usages) != 1 {
+		t.Fatalf("usage chunks = %d, want 1", len(usages))
+	}
+	u := usages[0]
+	if !u.Get("approximate").Bool() {
+		t.Errorf("synthesized usage not flagged approximate: %s", u.Raw)
+	}
+	if u.Get("prompt_tokens").Int() <= 0 || u.Get("completion_tokens").Int() <= 0 {
+		t.Errorf("usage = %s, want positive estimates", u.Raw)
+	}
+	if u.Get("total_tokens").Int() != u.Get("prompt_tokens").Int()+u.Get("completion_tokens").Int() {
+		t.Errorf("total_tokens mismatch: %s", u.Raw)
+	}
+}
+
+func TestStreamUsage_UpstreamUsageTakesPrecedence(t *testing.T) {
+	usages := runOpenAIStream(t, includeUsageRequest,
+		`{"choices":[{"index":0,"delta":{"content":"Hello"}}]}`,
+		`{"choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`,
+		`{"choices":[],"usage":{"prompt_tokens":11,"completion_tokens":2,"total_tokens":13}}`,
+	)
+	if len(usages) != 1 {
+		t.Fatalf("usage chunks = %d, want 1", len(usages))
+	}
+	if usages[0].Get("prompt_tokens").Int() != 11 || usages[0].Get("approximate").Exists() {
+		t.Errorf("usage = %s, want upstream values", usages[0].Raw)
+	}
+}
+
+func TestStreamUsage_NotRequested(t *testing.T) {
+	usages := runOpenAIStream(t, `{"model":"gpt-4o","stream":true,"messages":[]}`,
+		`{"choices":[{"index":0,"delta":{"content":"Hello"}}]}`,
+		`{"choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`,
+	)
+	if len(usages) != 0 {
+		t.Errorf("usage chunks = %d, want 0", len(usages))
+	}
+}
+
+func TestStreamUsage_FinishUsageFromTranslatedProvider(t *testing.T) {
+	ctx := NewStreamContext()
+	ctx.RequestUsage([]byte(includeUsageRequest))
+	tr := NewStreamTranslator(&config.Config{}, provider.FromString("openai"), "openai", "gemini-2.5-pro", "chatcmpl-1", ctx)
+	if _, err := tr.Translate([]ir.UnifiedEvent{
+		{Type: ir.EventTypeToken, Content: "Hi"},
+		{Type: ir.EventTypeFinish, FinishReason: ir.FinishReasonStop, Usage: &ir.Usage{PromptTokens: 5, CompletionTokens: 1, TotalTokens: 6}},
+	}); err != nil {
+		t.Fatalf("translate: %v", err)
+	}
+	if flushed := tr.Flush(); len(flushed) != 0 {
+		t.Errorf("flush emitted %d chunks after upstream usage, want 0", len(flushed))
+	}
+}
@@ -121,6 +121,8 @@ func RunSSEStream(
 
 	go func() {
 		defer close(out)
+		// Publish whatever usage was seen if the stream ends early.
+		defer reporter.flush(ctx, false)
 		defer func() {
 			if r := recover(); r != nil {
 				log.Errorf("%s: panic in stream goroutine: %v", cfg.ExecutorName, r)
@@ -206,7 +208,7 @@ func RunSSEStream(
 			}
 
 			if usage != nil && reporter != nil {
-				reporter.publish(ctx, usage)
+				reporter.observe(usage)
 			}
 
 			if len(chunks) > 0 {
@@ -247,9 +249,7 @@ func RunSSEStream(
 			return
 		}
 
-		if cfg.EnsurePublished && reporter != nil {
-			reporter.ensurePublished(ctx)
-		}
+		reporter.flush(ctx, cfg.EnsurePublished)
 	}()
 
 	return out
@@ -0,0 +1,86 @@
+package executor
+
+import (
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/registry"
+	"github.com/tidwall/gjson"
+)
+
+func TestReasoningEffortTranslation(t *testing.T) {
+	tests := []struct {
+		effort       string
+		claude       int64
+		gemini       int64
+		gemini3Level string
+	}{
+		{"low", 4096, 1024, "LOW"},
+		{"medium", 10000, 8192, "HIGH"},
+		{"high", 24000, 24576, "HIGH"},
+	}
+	cfg := &config.Config{}
+	openai := provider.FromString("openai")
+	for _, tt := range tests {
+		t.Run(tt.effort, func(t *testing.T) {
+			chat := []byte(`{"messages":[{"role":"user","content":"hi"}],"max_tokens":32000,"reasoning_effort":"` + tt.effort + `"}`)
+
+			body, err := TranslateToClaude(cfg, openai, "claude-effort-test", chat, false, nil)
+			if err != nil {
+				t.Fatalf("claude: %v", err)
+			}
+			if got := gjson.GetBytes(body, "thinking.budget_tokens").Int(); got != tt.claude {
+				t.Errorf("claude budget_tokens = %d, want %d", got, tt.claude)
+			}
+
+			body, err = TranslateToGemini(cfg, openai, "gemini-2.5-effort-test", chat, false, nil)
+			if err != nil {
+				t.Fatalf("gemini: %v", err)
+			}
+			if got := gjson.GetBytes(body, "generationConfig.thinkingConfig.thinkingBudget").Int(); got != tt.gemini {
+				t.Errorf("gemini thinkingBudget = %d, want %d", got, tt.gemini)
+			}
+
+			body, err = TranslateToGemini(cfg, openai, "gemini-3-pro-effort-test", chat, false, nil)
+			if err != nil {
+				t.Fatalf("gemini 3: %v", err)
+			}
+			if got := gjson.GetBytes(body, "generationConfig.thinkingConfig.thinkingLevel").String(); got != tt.gemini3Level {
+				t.Errorf("gemini 3 thinkingLevel = %q, want %q", got, tt.gemini3Level)
+			}
+
+			responses := []byte(`{"input":"hi","reasoning":{"effort":"` + tt.effort + `"}}`)
+			body, err = TranslateToOpenAI(cfg, provider.FromString("codex"), "gpt-effort-test", responses, false, nil)
+			if err != nil {
+				t.Fatalf("openai: %v", err)
+			}
+			if got := gjson.GetBytes(body, "reasoning_effort").String(); got != tt.effort {
+				t.Errorf("openai reasoning_effort = %q, want %q", got, tt.effort)
+			}
+		})
+	}
+}
+
+func TestThinkingModelFor(t *testing.T) {
+	reg := registry.GetGlobalRegistry()
+	reg.RegisterClient("effort-variant-test", "antigravity", []*registry.ModelInfo{
+		{ID: "effort-base"},
+		{ID: "effort-base-thinking", Thinking: &registry.ThinkingSupport{Min: 1024, Max: 32000}},
+	})
+	defer reg.UnregisterClient("effort-variant-test")
+
+	effort := []byte(`{"reasoning_effort":"high"}`)
+	if got := thinkingModelFor("antigravity", "effort-base", effort); got != "effort-base-thinking" {
+		t.Errorf("with effort = %q, want effort-base-thinking", got)
+	}
+	if got := thinkingModelFor("antigravity", "effort-base", []byte(`{"reasoning_effort":"none"}`)); got != "effort-base" {
+		t.Errorf("effort none = %q, want effort-base", got)
+	}
+	if got := thinkingModelFor("antigravity", "effort-base", []byte(`{}`)); got != "effort-base" {
+		t.Errorf("no effort = %q, want effort-base", got)
+	}
+	if got := thinkingModelFor("claude", "effort-base", effort); got != "effort-base" {
+		t.Errorf("other provider = %q, want effort-base", got)
+	}
+}
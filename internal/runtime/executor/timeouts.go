@@ -0,0 +1,233 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+var (
+	// ErrRequestTimeout is returned when a non-streaming call exceeds its overall timeout.
+	ErrRequestTimeout = errors.New("upstream request timed out")
+	// ErrStreamIdleTimeout is returned when a stream delivers no data within the idle timeout.
+	ErrStreamIdleTimeout = errors.New("upstream stream idle timeout")
+)
+
+// streamingContentTypes identifies responses that are consumed incrementally
+// and therefore exempt from the overall request timeout.
+var streamingContentTypes = map[string]struct{}{
+	"text/event-stream":                  {},
+	"application/x-ndjson":               {},
+	"application/vnd.amazon.eventstream": {},
+}
+
+// ProviderTimeouts reports the timeouts applied to outbound calls for auth.
+func ProviderTimeouts(cfg *config.Config, auth *provider.Auth) config.EffectiveTimeouts {
+	var name string
+	if auth != nil {
+		name = auth.Provider
+	}
+	return cfg.ProviderTimeouts(name)
+}
+
+// callTimeouts reports the timeouts for a call made with ctx: the provider's,
+// with the request timeout replaced by the client's X-LLM-Mux-Timeout, if
+// one was set. The second result reports whether it was.
+func callTimeouts(ctx context.Context, cfg *config.Config, auth *provider.Auth) (config.EffectiveTimeouts, bool) {
+	t := ProviderTimeouts(cfg, auth)
+	override, ok := provider.RequestTimeoutFromContext(ctx)
+	if ok {
+		t.Request = override
+	}
+	return t, ok
+}
+
+// transportTimeouts reports the limits of the pooled transport used for a
+// call made with ctx. Transports are pooled per timeout set, so a client
+// timeout longer than the provider's selects the pool sized for the server
+// maximum instead of one pool per requested value; the exact timeout is
+// enforced by timeoutTransport.
+func transportTimeouts(ctx context.Context, cfg *config.Config, auth *provider.Auth) config.EffectiveTimeouts {
+	t := ProviderTimeouts(cfg, auth)
+	if override, ok := provider.RequestTimeoutFromContext(ctx); ok && cfg != nil && override > t.Request {
+		t.Request = cfg.RequestTimeoutLimit()
+	}
+	return t
+}
+
+// customTransportTimeouts reports whether t changes the transport-level
+// limits away from the built-in ones.
+func customTransportTimeouts(t config.EffectiveTimeouts) bool {
+	return t.Connect != config.DefaultConnectTimeout || t.Request != config.DefaultRequestTimeout
+}
+
+var (
+	timeoutTransportsMu sync.Mutex
+	timeoutTransports   = make(map[config.EffectiveTimeouts]*http.Transport)
+)
+
+// sharedTransportFor returns the pooled transport for a timeout set. Providers
+// on the built-in connect and request timeouts share SharedTransport; others
+// get a dedicated pool whose dial and response-header limits follow their
+// configuration, so a long non-streaming call is not cut off while the
+// upstream is still generating.
+func sharedTransportFor(t config.EffectiveTimeouts) *http.Transport {
+	if !customTransportTimeouts(t) {
+		return SharedTransport
+	}
+	key := config.EffectiveTimeouts{Connect: t.Connect, Request: t.Request}
+	timeoutTransportsMu.Lock()
+	defer timeoutTransportsMu.Unlock()
+	if tr, ok := timeoutTransports[key]; ok {
+		return tr
+	}
+	tr := baseTransport()
+	applyTransportTimeouts(tr, t)
+	timeoutTransports[key] = tr
+	return tr
+}
+
+// applyTransportTimeouts sets dial and response-header limits on tr. SOCKS5
+// transports keep their proxy dialer.
+func applyTransportTimeouts(tr *http.Transport, t config.EffectiveTimeouts) {
+	tr.ResponseHeaderTimeout = t.Request
+	if tr.DialContext == nil {
+		tr.DialContext = (&net.Dialer{Timeout: t.Connect, KeepAlive: TransportConfig.KeepAlive}).DialContext
+	}
+}
+
+// timeoutTransport enforces the overall timeout on non-streaming calls and the
+// idle timeout on streaming ones. Whether a call streams is decided from the
+// response content type. A timeout set by the client bounds streams as well.
+type timeoutTransport struct {
+	base     http.RoundTripper
+	timeouts config.EffectiveTimeouts
+	// clientTimeout marks timeouts.Request as set by the client.
+	clientTimeout bool
+}
+
+func (t *timeoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithCancelCause(req.Context())
+	var overall *time.Timer
+	if limit := t.timeouts.Overall(false); limit > 0 {
+		overall = time.AfterFunc(limit, func() { cancel(ErrRequestTimeout) })
+	}
+	stop := func() {
+		if overall != nil {
+			overall.Stop()
+		}
+	}
+
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		stop()
+		cause := context.Cause(ctx)
+		cancel(nil)
+		if errors.Is(cause, ErrRequestTimeout) {
+			return nil, fmt.Errorf("%w after %s", ErrRequestTimeout, t.timeouts.Request)
+		}
+		return nil, err
+	}
+	if isStreamingResponse(resp) {
+		if !t.clientTimeout {
+			stop()
+		}
+		body := newIdleTimeoutBody(ctx, resp.Body, t.timeouts.StreamIdle, cancel)
+		body.limit, body.release = t.timeouts.Request, stop
+		resp.Body = body
+		return resp, nil
+	}
+	resp.Body = &deadlineBody{ReadCloser: resp.Body, ctx: ctx, limit: t.timeouts.Request, release: func() {
+		stop()
+		cancel(nil)
+	}}
+	return resp, nil
+}
+
+func isStreamingResponse(resp *http.Response) bool {
+	mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	_, ok := streamingContentTypes[strings.ToLower(mediaType)]
+	return ok
+}
+
+// deadlineBody reports ErrRequestTimeout when the overall timeout fires while
+// the body is being read, and releases the timer on close.
+type deadlineBody struct {
+	io.ReadCloser
+	ctx     context.Context
+	limit   time.Duration
+	release func()
+}
+
+func (b *deadlineBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && errors.Is(context.Cause(b.ctx), ErrRequestTimeout) {
+		return n, fmt.Errorf("%w after %s", ErrRequestTimeout, b.limit)
+	}
+	return n, err
+}
+
+func (b *deadlineBody) Close() error {
+	b.release()
+	return b.ReadCloser.Close()
+}
+
+// idleTimeoutBody cancels the request when no data arrives for idle. When
+// the overall timeout still runs, limit and release report and stop it.
+type idleTimeoutBody struct {
+	io.ReadCloser
+	ctx     context.Context
+	idle    time.Duration
+	timer   *time.Timer
+	cancel  context.CancelCauseFunc
+	limit   time.Duration
+	release func()
+}
+
+func newIdleTimeoutBody(ctx context.Context, body io.ReadCloser, idle time.Duration, cancel context.CancelCauseFunc) *idleTimeoutBody {
+	b := &idleTimeoutBody{ReadCloser: body, ctx: ctx, idle: idle, cancel: cancel}
+	if idle > 0 {
+		b.timer = time.AfterFunc(idle, func() { cancel(ErrStreamIdleTimeout) })
+	}
+	return b
+}
+
+func (b *idleTimeoutBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 && b.timer != nil {
+		b.timer.Reset(b.idle)
+	}
+	if err != nil {
+		switch cause := context.Cause(b.ctx); {
+		case errors.Is(cause, ErrStreamIdleTimeout):
+			return n, fmt.Errorf("%w: no data for %s", ErrStreamIdleTimeout, b.idle)
+		case errors.Is(cause, ErrRequestTimeout):
+			return n, fmt.Errorf("%w after %s", ErrRequestTimeout, b.limit)
+		}
+	}
+	return n, err
+}
+
+func (b *idleTimeoutBody) Close() error {
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+	if b.release != nil {
+		b.release()
+	}
+	b.cancel(nil)
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,140 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+func TestProviderTimeouts_StreamingExemptFromOverall(t *testing.T) {
+	cfg := &config.Config{Timeouts: map[string]config.ProviderTimeouts{
+		"default": {Request: 30},
+		"gemini":  {Request: 600, StreamIdle: 90},
+	}}
+	got := ProviderTimeouts(cfg, &provider.Auth{Provider: "gemini"})
+	if got.Overall(false) != 600*time.Second || got.StreamIdle != 90*time.Second {
+		t.Fatalf("gemini timeouts = %+v", got)
+	}
+	if got.Overall(true) != 0 {
+		t.Fatalf("streaming overall = %s, want 0", got.Overall(true))
+	}
+	other := ProviderTimeouts(cfg, &provider.Auth{Provider: "claude"})
+	if other.Overall(false) != 30*time.Second || other.Connect != config.DefaultConnectTimeout {
+		t.Fatalf("claude timeouts = %+v", other)
+	}
+	if (&config.Config{Timeouts: map[string]config.ProviderTimeouts{"claude": {Request: -1}}}).ValidateTimeouts() == nil {
+		t.Fatal("negative timeout accepted")
+	}
+}
+
+func TestTimeoutTransport_AbortsStalledStream(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w, "data: {}\n\n")
+		w.(http.Flusher).Flush()
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	client := &http.Client{Transport: &timeoutTransport{
+		base:     http.DefaultTransport,
+		timeouts: config.EffectiveTimeouts{Request: 50 * time.Millisecond, StreamIdle: 200 * time.Millisecond},
+	}}
+	req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, srv.URL, nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("stream request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	// The overall limit is shorter than the idle limit; the stream must
+	// survive it and only be aborted once idle.
+	_, err = io.ReadAll(resp.Body)
+	if !errors.Is(err, ErrStreamIdleTimeout) {
+		t.Fatalf("read error = %v, want ErrStreamIdleTimeout", err)
+	}
+}
+
+func TestTimeoutTransport_OverallLimitsNonStreaming(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(2 * time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: &timeoutTransport{
+		base:     http.DefaultTransport,
+		timeouts: config.EffectiveTimeouts{Request: 50 * time.Millisecond},
+	}}
+	req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, srv.URL, nil)
+	resp, err := client.Do(req)
+	if err == nil {
+		resp.Body.Close()
+	}
+	if !errors.Is(err, ErrRequestTimeout) {
+		t.Fatalf("error = %v, want ErrRequestTimeout", err)
+	}
+}
+
+func TestClientTimeout_OverridesProviderTimeout(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/stream" {
+			w.Header().Set("Content-Type", "text/event-stream")
+			for {
+				_, _ = io.WriteString(w, "data: {}\n\n")
+				w.(http.Flusher).Flush()
+				select {
+				case <-time.After(20 * time.Millisecond):
+				case <-r.Context().Done():
+					return
+				}
+			}
+		}
+		select {
+		case <-release:
+		case <-time.After(150 * time.Millisecond):
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	cfg := &config.Config{Timeouts: map[string]config.ProviderTimeouts{"default": {Request: 1}}}
+	auth := &provider.Auth{Provider: "claude"}
+	get := func(ctx context.Context, path string) error {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+path, nil)
+		resp, err := newProxyAwareHTTPClient(ctx, cfg, auth, 0).Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		_, err = io.ReadAll(resp.Body)
+		return err
+	}
+
+	short := provider.WithRequestTimeout(context.Background(), 50*time.Millisecond)
+	if err := get(short, "/slow"); !errors.Is(err, ErrRequestTimeout) {
+		t.Fatalf("slow call with a shorter client timeout: error = %v, want ErrRequestTimeout", err)
+	}
+	// The stream never stalls, so only the client timeout can end it.
+	if err := get(short, "/stream"); !errors.Is(err, ErrRequestTimeout) {
+		t.Fatalf("stream with a client timeout: error = %v, want ErrRequestTimeout", err)
+	}
+	if err := get(context.Background(), "/slow"); err != nil {
+		t.Fatalf("slow call without a client timeout: %v", err)
+	}
+}
@@ -3,10 +3,13 @@ package executor
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"strings"
 
 	"github.com/nghyane/llm-mux/internal/config"
 	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/nghyane/llm-mux/internal/util"
 	"github.com/tidwall/gjson"
 	"github.com/tiktoken-go/tokenizer"
 )
@@ -74,6 +77,39 @@ func buildOpenAIUsageJSON(count int64) []byte {
 	return []byte(fmt.Sprintf(`{"usage":{"prompt_tokens":%d,"completion_tokens":0,"total_tokens":%d}}`, count, count))
 }
 
+// countTokensUnavailable reports whether a count-tokens endpoint status means
+// the count should be estimated locally rather than failing the request:
+// the endpoint is missing, throttled or failing upstream.
+func countTokensUnavailable(status int) bool {
+	switch status {
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusTooManyRequests:
+		return true
+	}
+	return status >= http.StatusInternalServerError
+}
+
+// estimateInputTokens counts irReq locally with the model's tokenizer,
+// falling back to tiktoken when that tokenizer is unavailable.
+func estimateInputTokens(model string, irReq *ir.UnifiedChatRequest) int64 {
+	if count := util.CountTokensFromIR(model, irReq); count > 0 {
+		return count
+	}
+	return util.CountTiktokenTokens(model, irReq)
+}
+
+// buildTokenCountJSON renders an input token count in the response shape of
+// the client's count-tokens endpoint.
+func buildTokenCountJSON(from provider.Format, count int64) []byte {
+	switch from.String() {
+	case "claude":
+		return []byte(fmt.Sprintf(`{"input_tokens":%d}`, count))
+	case "gemini", "gemini-cli":
+		return []byte(fmt.Sprintf(`{"totalTokens":%d}`, count))
+	default:
+		return buildOpenAIUsageJSON(count)
+	}
+}
+
 func collectOpenAIMessages(messages gjson.Result, segments *[]string) {
 	if !messages.Exists() || !messages.IsArray() {
 		return
@@ -12,6 +12,7 @@ import (
 	"github.com/nghyane/llm-mux/internal/translator/preprocess"
 	"github.com/nghyane/llm-mux/internal/translator/to_ir"
 	"github.com/nghyane/llm-mux/internal/util"
+	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
 
@@ -52,6 +53,7 @@ func TranslateToGeminiWithTokens(cfg *config.Config, from provider.Format, model
 	if err != nil {
 		return nil, err
 	}
+	provider.AddWarnings(metadata, irReq.Warnings...)
 
 	result := &TranslationResult{
 		Payload: applyPayloadConfigToIR(cfg, model, geminiJSON),
@@ -90,6 +92,7 @@ func TranslateToGeminiCLIWithTokens(cfg *config.Config, from provider.Format, mo
 	if err != nil {
 		return nil, err
 	}
+	provider.AddWarnings(metadata, irReq.Warnings...)
 
 	result := &TranslationResult{
 		Payload: applyPayloadConfigToIR(cfg, model, convertedJSON),
@@ -115,6 +118,7 @@ func convertRequestToIR(from provider.Format, model string, payload []byte, meta
 	if model != "" {
 		irReq.Model = model
 	}
+	ir.ResolveEffortBudget(irReq)
 
 	if metadata != nil {
 		if irReq.Metadata == nil {
@@ -142,6 +146,7 @@ func convertRequestToIR(from provider.Format, model string, payload []byte, meta
 	}
 
 	normalizeIRLimits(irReq.Model, irReq)
+	applyOutputTokenLimit(irReq)
 	preprocess.Apply(irReq)
 
 	return irReq, nil
@@ -180,16 +185,6 @@ func normalizeIRLimits(model string, req *ir.UnifiedChatRequest) {
 		b := int32(budget)
 		req.Thinking.ThinkingBudget = &b
 	}
-
-	if req.MaxTokens != nil {
-		limit := info.OutputTokenLimit
-		if limit == 0 {
-			limit = info.MaxCompletionTokens
-		}
-		if limit > 0 && *req.MaxTokens > limit {
-			*req.MaxTokens = limit
-		}
-	}
 }
 
 func TranslateToGeminiCLI(cfg *config.Config, from provider.Format, model string, payload []byte, streaming bool, metadata map[string]any) ([]byte, error) {
@@ -222,7 +217,29 @@ func TranslateToCodex(cfg *config.Config, from provider.Format, model string, pa
 	if err != nil {
 		return nil, err
 	}
-	return from_ir.ToOpenAIRequestFmt(irReq, from_ir.FormatResponsesAPI)
+	if err := ir.DropUnsupportedLogprobs(irReq, "codex"); err != nil {
+		return nil, err
+	}
+	body, err := from_ir.ToOpenAIRequestFmt(irReq, from_ir.FormatResponsesAPI)
+	if err != nil {
+		return nil, err
+	}
+	provider.AddWarnings(metadata, irReq.Warnings...)
+	return body, nil
+}
+
+// TranslateToCohere builds a Cohere /v1/chat request body.
+func TranslateToCohere(cfg *config.Config, from provider.Format, model string, payload []byte, metadata map[string]any) ([]byte, error) {
+	irReq, err := convertRequestToIR(from, model, payload, metadata)
+	if err != nil {
+		return nil, err
+	}
+	body, err := from_ir.ToCohereRequest(irReq)
+	if err != nil {
+		return nil, err
+	}
+	provider.AddWarnings(metadata, irReq.Warnings...)
+	return applyPayloadConfigWithRoot(cfg, model, "cohere", "", body), nil
 }
 
 func TranslateToClaude(cfg *config.Config, from provider.Format, model string, payload []byte, streaming bool, metadata map[string]any) ([]byte, error) {
@@ -230,12 +247,82 @@ func TranslateToClaude(cfg *config.Config, from provider.Format, model string, p
 	if err != nil {
 		return nil, err
 	}
-	return translator.ConvertRequest("claude", irReq)
+	body, err := translator.ConvertRequest("claude", irReq)
+	if err != nil {
+		return nil, err
+	}
+	provider.AddWarnings(metadata, irReq.Warnings...)
+	return body, nil
+}
+
+// claudeCountTokensFields are the Messages API fields accepted by
+// /v1/messages/count_tokens; the endpoint rejects anything else.
+var claudeCountTokensFields = [...]string{"model", "system", "messages", "tools", "tool_choice", "thinking"}
+
+// TranslateToClaudeCountTokens builds an Anthropic count_tokens body and
+// returns the IR it was built from so callers can estimate locally.
+func TranslateToClaudeCountTokens(from provider.Format, model string, payload []byte, metadata map[string]any) ([]byte, *ir.UnifiedChatRequest, error) {
+	irReq, err := convertRequestToIR(from, model, payload, metadata)
+	if err != nil {
+		return nil, nil, err
+	}
+	full, err := translator.ConvertRequest("claude", irReq)
+	if err != nil {
+		return nil, nil, err
+	}
+	body := []byte(`{}`)
+	for _, field := range claudeCountTokensFields {
+		if v := gjson.GetBytes(full, field); v.Exists() {
+			body, _ = sjson.SetRawBytes(body, field, []byte(v.Raw))
+		}
+	}
+	return body, irReq, nil
+}
+
+// geminiCountTokensFields are the GenerateContentRequest fields that count
+// toward input tokens.
+var geminiCountTokensFields = [...]string{"contents", "systemInstruction", "tools", "toolConfig", "cachedContent"}
+
+// TranslateToGeminiCountTokens builds a Gemini :countTokens body and returns
+// the IR it was built from so callers can estimate locally. The request is
+// sent as generateContentRequest so system instructions and tools are
+// counted alongside contents, including inline images and documents.
+func TranslateToGeminiCountTokens(from provider.Format, model string, payload []byte, metadata map[string]any) ([]byte, *ir.UnifiedChatRequest, error) {
+	if from.String() == "gemini" {
+		// Gemini clients may already wrap their request for countTokens.
+		if inner := gjson.GetBytes(payload, "generateContentRequest"); inner.IsObject() {
+			payload = []byte(inner.Raw)
+		}
+	}
+	irReq, err := convertRequestToIR(from, model, payload, metadata)
+	if err != nil {
+		return nil, nil, err
+	}
+	full, err := translator.ConvertRequest("gemini", irReq)
+	if err != nil {
+		return nil, nil, err
+	}
+	body := []byte(`{"generateContentRequest":{}}`)
+	body, _ = sjson.SetBytes(body, "generateContentRequest.model", "models/"+model)
+	for _, field := range geminiCountTokensFields {
+		if v := gjson.GetBytes(full, field); v.Exists() {
+			body, _ = sjson.SetRawBytes(body, "generateContentRequest."+field, []byte(v.Raw))
+		}
+	}
+	return body, irReq, nil
 }
 
 func TranslateToOpenAI(cfg *config.Config, from provider.Format, model string, payload []byte, streaming bool, metadata map[string]any) ([]byte, error) {
 	fromStr := from.String()
 	if fromStr == "openai" || fromStr == "cline" {
+		// prompt_cache, logprobs_required and safety_settings are llm-mux
+		// extensions; upstreams never see them.
+		for _, field := range [...]string{ir.PromptCacheField, ir.LogprobsRequiredField, ir.SafetySettingsField} {
+			if gjson.GetBytes(payload, field).Exists() {
+				payload, _ = sjson.DeleteBytes(payload, field)
+			}
+		}
+		payload = clampPayloadOutputTokens(model, payload, metadata)
 		return applyPayloadConfigToIR(cfg, model, payload), nil
 	}
 
@@ -247,6 +334,7 @@ func TranslateToOpenAI(cfg *config.Config, from provider.Format, model string, p
 	if err != nil {
 		return nil, err
 	}
+	provider.AddWarnings(metadata, irReq.Warnings...)
 	return applyPayloadConfigToIR(cfg, model, openaiJSON), nil
 }
 
@@ -0,0 +1,95 @@
+package executor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+func TestUpstreamHeaders_AppliedToOutboundRequests(t *testing.T) {
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+	}))
+	defer srv.Close()
+
+	cfg := &config.Config{UpstreamHeaders: map[string]config.ProviderHeaders{
+		"default": {Headers: map[string]string{"X-Org-Id": "acme", "X-Team": "core"}},
+		"claude": {Headers: map[string]string{
+			"X-Team":         "claude-{{attr.team}}",
+			"X-Project":      "{{meta.project_id}}",
+			"X-Account":      "{{auth.id}}",
+			"X-Missing":      "{{attr.absent}}",
+			"Authorization":  "Bearer config",
+			"anthropic-beta": "beta-a",
+		}},
+	}}
+	auth := &provider.Auth{
+		ID:         "claude-1",
+		Provider:   "claude",
+		Attributes: map[string]string{"team": "research"},
+		Metadata:   map[string]any{"project_id": "p-42"},
+	}
+
+	client := newProxyAwareHTTPClient(context.Background(), cfg, auth, 0)
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	req.Header.Set("Authorization", "Bearer executor")
+	req.Header.Set("Anthropic-Beta", "default-beta")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	want := map[string]string{
+		"X-Org-Id":       "acme",
+		"X-Team":         "claude-research",
+		"X-Project":      "p-42",
+		"X-Account":      "claude-1",
+		"Anthropic-Beta": "beta-a",
+		"Authorization":  "Bearer executor",
+	}
+	for k, v := range want {
+		if got.Get(k) != v {
+			t.Errorf("%s = %q, want %q", k, got.Get(k), v)
+		}
+	}
+	if _, ok := got["X-Missing"]; ok {
+		t.Error("header with empty rendered value was sent")
+	}
+	if req.Header.Get("X-Org-Id") != "" {
+		t.Error("caller's request was mutated")
+	}
+}
+
+func TestUpstreamHeaders_ReservedRequireAllowOverride(t *testing.T) {
+	cfg := &config.Config{UpstreamHeaders: map[string]config.ProviderHeaders{
+		"openai": {Headers: map[string]string{"authorization": "Bearer x"}},
+	}}
+	if cfg.ValidateUpstreamHeaders() == nil {
+		t.Fatal("reserved header accepted without allow-override")
+	}
+
+	cfg.UpstreamHeaders["openai"] = config.ProviderHeaders{
+		Headers:       map[string]string{"authorization": "Bearer x"},
+		AllowOverride: []string{"Authorization"},
+	}
+	if err := cfg.ValidateUpstreamHeaders(); err != nil {
+		t.Fatalf("allowed override rejected: %v", err)
+	}
+	got := cfg.UpstreamHeadersFor("openai", config.HeaderTemplateData{})
+	if got["Authorization"] != "Bearer x" {
+		t.Fatalf("headers = %v", got)
+	}
+
+	bad := &config.Config{UpstreamHeaders: map[string]config.ProviderHeaders{
+		"openai": {Headers: map[string]string{"X-Org": "{{env.HOME}}"}},
+	}}
+	if bad.ValidateUpstreamHeaders() == nil {
+		t.Fatal("unknown template accepted")
+	}
+}
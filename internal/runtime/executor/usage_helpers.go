@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	log "github.com/nghyane/llm-mux/internal/logging"
 	"github.com/nghyane/llm-mux/internal/provider"
 	"github.com/nghyane/llm-mux/internal/translator/ir"
 	"github.com/nghyane/llm-mux/internal/translator/to_ir"
@@ -23,9 +24,15 @@ type usageReporter struct {
 	authID      string
 	authIndex   uint64
 	apiKey      string
+	clientKey   string
 	source      string
 	requestedAt time.Time
 	once        sync.Once
+
+	// pending merges the usage events seen on a stream so the record published
+	// at the end reflects the final counts rather than the first partial ones.
+	mu      sync.Mutex
+	pending *ir.Usage
 }
 
 func newUsageReporter(ctx context.Context, provider, model string, auth *provider.Auth) *usageReporter {
@@ -35,12 +42,18 @@ func newUsageReporter(ctx context.Context, provider, model string, auth *provide
 		model:       model,
 		requestedAt: time.Now(),
 		apiKey:      apiKey,
+		clientKey:   clientKeyFromContext(ctx),
 		source:      resolveUsageSource(auth, apiKey),
 	}
 	if auth != nil {
 		reporter.authID = auth.ID
 		reporter.authIndex = auth.EnsureIndex()
 	}
+	log.UpdateRequestMeta(ctx, func(m *log.RequestMeta) {
+		m.Provider = provider
+		m.Model = model
+		m.Attempts++
+	})
 	return reporter
 }
 
@@ -93,11 +106,18 @@ func (r *usageReporter) publishWithOutcome(ctx context.Context, u *ir.Usage, fai
 		return
 	}
 	r.once.Do(func() {
+		if u != nil {
+			log.UpdateRequestMeta(ctx, func(m *log.RequestMeta) {
+				m.InputTokens += u.PromptTokens
+				m.OutputTokens += u.CompletionTokens
+			})
+		}
 		usage.PublishRecord(ctx, usage.Record{
 			Provider:    r.provider,
 			Model:       r.model,
 			Source:      r.source,
 			APIKey:      r.apiKey,
+			ClientKey:   r.clientKey,
 			AuthID:      r.authID,
 			AuthIndex:   r.authIndex,
 			RequestedAt: r.requestedAt,
@@ -107,6 +127,63 @@ func (r *usageReporter) publishWithOutcome(ctx context.Context, u *ir.Usage, fai
 	})
 }
 
+// observe records a usage event from a stream without publishing it. Providers
+// report cumulative counts in several events (Claude sends input tokens in
+// message_start and output tokens in message_delta), so each field keeps its
+// largest value.
+func (r *usageReporter) observe(u *ir.Usage) {
+	if r == nil || u == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.pending == nil {
+		r.pending = &ir.Usage{}
+	}
+	mergeStreamUsage(r.pending, u)
+}
+
+// flush publishes the usage merged by observe. When nothing was observed and
+// ensure is set, an empty record is published so the request is still counted.
+func (r *usageReporter) flush(ctx context.Context, ensure bool) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	u := r.pending
+	r.pending = nil
+	r.mu.Unlock()
+	if u != nil {
+		if u.TotalTokens < u.PromptTokens+u.CompletionTokens {
+			u.TotalTokens = u.PromptTokens + u.CompletionTokens
+		}
+		r.publish(ctx, u)
+	}
+	if ensure {
+		r.ensurePublished(ctx)
+	}
+}
+
+func mergeStreamUsage(dst, src *ir.Usage) {
+	dst.PromptTokens = max(dst.PromptTokens, src.PromptTokens)
+	dst.CompletionTokens = max(dst.CompletionTokens, src.CompletionTokens)
+	dst.TotalTokens = max(dst.TotalTokens, src.TotalTokens)
+	dst.ThoughtsTokenCount = max(dst.ThoughtsTokenCount, src.ThoughtsTokenCount)
+	dst.CachedTokens = max(dst.CachedTokens, src.CachedTokens)
+	dst.AudioTokens = max(dst.AudioTokens, src.AudioTokens)
+	dst.AcceptedPredictionTokens = max(dst.AcceptedPredictionTokens, src.AcceptedPredictionTokens)
+	dst.RejectedPredictionTokens = max(dst.RejectedPredictionTokens, src.RejectedPredictionTokens)
+	dst.CacheCreationInputTokens = max(dst.CacheCreationInputTokens, src.CacheCreationInputTokens)
+	dst.CacheReadInputTokens = max(dst.CacheReadInputTokens, src.CacheReadInputTokens)
+	dst.ToolUsePromptTokens = max(dst.ToolUsePromptTokens, src.ToolUsePromptTokens)
+	if src.PromptTokensDetails != nil {
+		dst.PromptTokensDetails = src.PromptTokensDetails
+	}
+	if src.CompletionTokensDetails != nil {
+		dst.CompletionTokensDetails = src.CompletionTokensDetails
+	}
+}
+
 func (r *usageReporter) ensurePublished(ctx context.Context) {
 	if r == nil {
 		return
@@ -117,6 +194,7 @@ func (r *usageReporter) ensurePublished(ctx context.Context) {
 			Model:       r.model,
 			Source:      r.source,
 			APIKey:      r.apiKey,
+			ClientKey:   r.clientKey,
 			AuthID:      r.authID,
 			AuthIndex:   r.authIndex,
 			RequestedAt: r.requestedAt,
@@ -147,6 +225,15 @@ func apiKeyFromContext(ctx context.Context) string {
 	return ""
 }
 
+// clientKeyFromContext returns the log-safe client key label of the request.
+func clientKeyFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	ginCtx, _ := ctx.Value("gin").(*gin.Context)
+	return log.ClientKeyLabel(ginCtx)
+}
+
 func resolveUsageSource(auth *provider.Auth, ctxAPIKey string) string {
 	if auth != nil {
 		provider := strings.TrimSpace(auth.Provider)
@@ -0,0 +1,51 @@
+package executor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/nghyane/llm-mux/internal/usage"
+)
+
+type captureUsagePlugin struct {
+	model   string
+	records chan usage.Record
+}
+
+func (p *captureUsagePlugin) HandleUsage(_ context.Context, record usage.Record) {
+	if record.Model == p.model {
+		p.records <- record
+	}
+}
+
+func TestUsageReporter_StreamPublishesFinalUsage(t *testing.T) {
+	plugin := &captureUsagePlugin{model: "stream-usage-test", records: make(chan usage.Record, 4)}
+	usage.RegisterPlugin(plugin)
+
+	// Claude reports input tokens in message_start and output tokens in the
+	// final message_delta; the published record must carry both.
+	start := claudeMessageStartUsage([]byte(`data: {"type":"message_start","message":{"usage":{"input_tokens":120,"output_tokens":1}}}`))
+	if start == nil || start.PromptTokens != 120 {
+		t.Fatalf("message_start usage = %+v", start)
+	}
+	reporter := &usageReporter{provider: "claude", model: plugin.model}
+	reporter.observe(start)
+	reporter.observe(&ir.Usage{PromptTokens: 120, CompletionTokens: 85, TotalTokens: 205})
+	reporter.flush(context.Background(), true)
+
+	select {
+	case rec := <-plugin.records:
+		if rec.Usage == nil || rec.Usage.PromptTokens != 120 || rec.Usage.CompletionTokens != 85 || rec.Usage.TotalTokens != 205 {
+			t.Fatalf("record usage = %+v", rec.Usage)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no usage record published")
+	}
+	select {
+	case rec := <-plugin.records:
+		t.Fatalf("unexpected second record: %+v", rec)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,44 @@
+package executor
+
+import (
+	"net/http"
+
+	"github.com/nghyane/llm-mux/internal/buildinfo"
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+// DefaultUserAgent returns the User-Agent sent to upstreams that do not
+// require a specific client string.
+func DefaultUserAgent() string {
+	return "llm-mux/" + buildinfo.Version
+}
+
+// accountUserAgent returns the User-Agent configured for auth through its
+// user_agent attribute or metadata, or fallback when it has none.
+func accountUserAgent(auth *provider.Auth, fallback string) string {
+	if auth != nil {
+		if ua := AttrStringValue(auth.Attributes, "user_agent"); ua != "" {
+			return ua
+		}
+		if ua := MetaStringValue(auth.Metadata, "user_agent"); ua != "" {
+			return ua
+		}
+	}
+	return fallback
+}
+
+// userAgentTransport sets the account's User-Agent on requests the executor
+// left without one. Executors whose upstream expects a specific client
+// string set it themselves, and it is kept.
+type userAgentTransport struct {
+	base      http.RoundTripper
+	userAgent string
+}
+
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("User-Agent") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", t.userAgent)
+	}
+	return t.base.RoundTrip(req)
+}
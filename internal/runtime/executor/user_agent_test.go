@@ -0,0 +1,61 @@
+package executor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+func TestUserAgent(t *testing.T) {
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("User-Agent")
+	}))
+	defer srv.Close()
+
+	send := func(cfg *config.Config, auth *provider.Auth, executorUA string) string {
+		t.Helper()
+		got = ""
+		req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+		if executorUA != "" {
+			req.Header.Set("User-Agent", executorUA)
+		}
+		resp, err := newProxyAwareHTTPClient(context.Background(), cfg, auth, 0).Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+		return got
+	}
+	configured := &provider.Auth{Provider: "groq", Attributes: map[string]string{"user_agent": "acme-gateway/2"}}
+	fromFile := &provider.Auth{Provider: "qwen", Metadata: map[string]any{"user_agent": "acme-cli/1"}}
+
+	if ua := send(nil, &provider.Auth{Provider: "groq"}, ""); ua != DefaultUserAgent() {
+		t.Errorf("default User-Agent = %q, want %q", ua, DefaultUserAgent())
+	}
+	if ua := send(nil, configured, ""); ua != "acme-gateway/2" {
+		t.Errorf("configured User-Agent = %q", ua)
+	}
+	if ua := send(nil, fromFile, ""); ua != "acme-cli/1" {
+		t.Errorf("auth file User-Agent = %q", ua)
+	}
+	if ua := send(nil, configured, DefaultClaudeUserAgent); ua != DefaultClaudeUserAgent {
+		t.Errorf("provider-required User-Agent replaced by %q", ua)
+	}
+
+	cfg := &config.Config{UpstreamHeaders: map[string]config.ProviderHeaders{
+		"groq": {Headers: map[string]string{"User-Agent": "forced/1"}},
+	}}
+	if ua := send(cfg, configured, ""); ua != "forced/1" {
+		t.Errorf("upstream-headers User-Agent = %q, want forced/1", ua)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if err := (&BaseExecutor{}).PrepareRequest(req, configured); err != nil || req.Header.Get("User-Agent") != "acme-gateway/2" {
+		t.Errorf("PrepareRequest set User-Agent %q, err %v", req.Header.Get("User-Agent"), err)
+	}
+}
@@ -0,0 +1,202 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+// vertexRegionCooldown is how long a region that throttled or failed is
+// tried only after the account's other regions, unless the upstream asked
+// for a different delay.
+const vertexRegionCooldown = 30 * time.Second
+
+// regionalStrategy is implemented by Vertex strategies that can send the
+// same request to several regions.
+type regionalStrategy interface {
+	// Regions returns the regions to try, primary first.
+	Regions() []string
+	// InRegion returns the strategy targeting region.
+	InRegion(region string) VertexAuthStrategy
+}
+
+// Regions implements regionalStrategy.
+func (s *serviceAccountStrategy) Regions() []string {
+	if len(s.locations) == 0 {
+		return []string{s.location}
+	}
+	return s.locations
+}
+
+// InRegion implements regionalStrategy.
+func (s *serviceAccountStrategy) InRegion(region string) VertexAuthStrategy {
+	regional := *s
+	regional.location = region
+	return &regional
+}
+
+// vertexLocations returns the ordered regions of a: primary first, then the
+// failover regions from the "locations" attribute or metadata.
+func vertexLocations(a *provider.Auth, primary string) []string {
+	var extra []string
+	if a != nil {
+		if raw := AttrStringValue(a.Attributes, "locations"); raw != "" {
+			extra = strings.Split(raw, ",")
+		} else if list, ok := a.Metadata["locations"].([]any); ok {
+			for _, v := range list {
+				if s, ok := v.(string); ok {
+					extra = append(extra, s)
+				}
+			}
+		}
+	}
+	locations := []string{primary}
+	for _, loc := range extra {
+		loc = strings.TrimSpace(loc)
+		if loc != "" && !slices.Contains(locations, loc) {
+			locations = append(locations, loc)
+		}
+	}
+	return locations
+}
+
+// regionHealth tracks, per account, the regions that recently throttled or
+// failed. It is separate from the circuit breaker, which judges the account
+// as a whole: a throttled region only moves to the back of the order.
+type regionHealth struct {
+	mu    sync.Mutex
+	until map[string]time.Time
+	now   func() time.Time
+}
+
+var vertexRegionHealth = &regionHealth{}
+
+func (h *regionHealth) clock() time.Time {
+	if h.now != nil {
+		return h.now()
+	}
+	return time.Now()
+}
+
+// order returns regions with the healthy ones first, each group keeping the
+// configured order. Regions still cooling down are kept as a last resort.
+func (h *regionHealth) order(authID string, regions []string) []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	now := h.clock()
+	healthy := make([]string, 0, len(regions))
+	var cooling []string
+	for _, r := range regions {
+		if until, ok := h.until[authID+"|"+r]; ok && now.Before(until) {
+			cooling = append(cooling, r)
+			continue
+		}
+		healthy = append(healthy, r)
+	}
+	return append(healthy, cooling...)
+}
+
+// fail moves region to the back of the order for retryAfter, or the default
+// cooldown when the upstream gave none.
+func (h *regionHealth) fail(authID, region string, retryAfter *time.Duration) {
+	cooldown := vertexRegionCooldown
+	if retryAfter != nil && *retryAfter > 0 {
+		cooldown = *retryAfter
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.until == nil {
+		h.until = make(map[string]time.Time)
+	}
+	h.until[authID+"|"+region] = h.clock().Add(cooldown)
+}
+
+func (h *regionHealth) recover(authID, region string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.until, authID+"|"+region)
+}
+
+// isRegionalFailure reports whether status suggests the region, rather than
+// the request, is at fault: throttling or an upstream outage.
+func isRegionalFailure(status int) bool {
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
+
+// doVertexRequest posts body to the URL buildURL gives for each region of
+// strategy in turn, healthiest first, until one answers without a regional
+// failure. Other errors are returned at once. The caller closes the body of
+// the returned response.
+func (e *GeminiVertexExecutor) doVertexRequest(ctx context.Context, auth *provider.Auth, strategy VertexAuthStrategy, body []byte, buildURL func(VertexAuthStrategy) string) (*http.Response, error) {
+	token, errTok := strategy.GetToken(ctx, e.cfg, auth)
+	if errTok != nil {
+		log.Errorf("vertex executor: access token error: %v", errTok)
+		return nil, NewStatusError(500, "internal server error", nil)
+	}
+
+	authID := ""
+	if auth != nil {
+		authID = auth.ID
+	}
+	regions := []string{""}
+	regional, isRegional := strategy.(regionalStrategy)
+	if isRegional {
+		regions = vertexRegionHealth.order(authID, regional.Regions())
+	}
+
+	httpClient := newProxyAwareHTTPClient(ctx, e.cfg, auth, 0)
+	for i, region := range regions {
+		target := strategy
+		if isRegional {
+			target = regional.InRegion(region)
+		}
+		hasNext := i+1 < len(regions)
+
+		httpReq, errNewReq := http.NewRequestWithContext(ctx, http.MethodPost, buildURL(target), bytes.NewReader(body))
+		if errNewReq != nil {
+			return nil, errNewReq
+		}
+		httpReq.Header.Set("Content-Type", "application/json")
+		target.ApplyAuth(httpReq, token)
+		applyGeminiHeaders(httpReq, auth)
+
+		httpResp, errDo := httpClient.Do(httpReq)
+		if errDo != nil {
+			if hasNext && ctx.Err() == nil {
+				log.Debugf("vertex executor: request error in region %s, trying next region: %v", region, errDo)
+				vertexRegionHealth.fail(authID, region, nil)
+				continue
+			}
+			if errors.Is(errDo, context.DeadlineExceeded) {
+				return nil, NewTimeoutError("request timed out")
+			}
+			return nil, errDo
+		}
+		if httpResp.StatusCode >= 200 && httpResp.StatusCode < 300 {
+			if isRegional {
+				vertexRegionHealth.recover(authID, region)
+			}
+			return httpResp, nil
+		}
+
+		result := HandleHTTPError(httpResp, "gemini-vertex executor")
+		_ = httpResp.Body.Close()
+		if isRegional && isRegionalFailure(result.StatusCode) {
+			vertexRegionHealth.fail(authID, region, ParseQuotaRetryDelay(result.Body))
+			if hasNext {
+				log.Debugf("vertex executor: status %d in region %s, trying next region", result.StatusCode, region)
+				continue
+			}
+		}
+		return nil, result.Error
+	}
+	return nil, NewStatusError(http.StatusServiceUnavailable, "vertex executor: no region available", nil)
+}
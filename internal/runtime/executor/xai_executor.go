@@ -0,0 +1,204 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/constant"
+	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/util"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// xaiExtensionFields are xAI request fields with no equivalent in other
+// formats. They are copied from the client payload whatever its format, so
+// Claude or Gemini clients can still turn on Live Search.
+var xaiExtensionFields = [...]string{"search_parameters"}
+
+// xaiReasoningUnsupported are parameters Grok reasoning models reject.
+var xaiReasoningUnsupported = [...]string{"presence_penalty", "frequency_penalty", "stop"}
+
+// XAIExecutor talks to xAI's OpenAI-compatible chat completions API.
+type XAIExecutor struct {
+	cfg *config.Config
+}
+
+func NewXAIExecutor(cfg *config.Config) *XAIExecutor { return &XAIExecutor{cfg: cfg} }
+
+func (e *XAIExecutor) Identifier() string { return constant.XAI }
+
+func (e *XAIExecutor) PrepareRequest(_ *http.Request, _ *provider.Auth) error { return nil }
+
+func (e *XAIExecutor) Execute(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (resp provider.Response, err error) {
+	reporter := newUsageReporter(ctx, e.Identifier(), req.Model, auth)
+	defer reporter.trackFailure(ctx, &err)
+
+	from := opts.SourceFormat
+	body, err := e.translate(from, req, false)
+	if err != nil {
+		return resp, err
+	}
+	httpResp, err := e.post(ctx, auth, body, false)
+	if err != nil {
+		return resp, err
+	}
+	defer func() {
+		if errClose := httpResp.Body.Close(); errClose != nil {
+			log.Errorf("xai executor: close response body error: %v", errClose)
+		}
+	}()
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return resp, err
+	}
+	reporter.publish(ctx, extractUsageFromOpenAIResponse(data))
+	reporter.ensurePublished(ctx)
+
+	translatedResp, err := TranslateResponseNonStream(e.cfg, provider.FromString("openai"), from, data, req.Model)
+	if err != nil {
+		return resp, err
+	}
+	if translatedResp != nil {
+		return provider.Response{Payload: translatedResp}, nil
+	}
+	return provider.Response{Payload: data}, nil
+}
+
+func (e *XAIExecutor) ExecuteStream(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (stream <-chan provider.StreamChunk, err error) {
+	reporter := newUsageReporter(ctx, e.Identifier(), req.Model, auth)
+	defer reporter.trackFailure(ctx, &err)
+
+	from := opts.SourceFormat
+	body, err := e.translate(from, req, true)
+	if err != nil {
+		return nil, err
+	}
+	httpResp, err := e.post(ctx, auth, body, true)
+	if err != nil {
+		return nil, err
+	}
+
+	processor := NewOpenAIStreamProcessor(e.cfg, from, req.Model, "chatcmpl-"+req.Model)
+	processor.ctx.RequestUsage(opts.OriginalRequest)
+	return RunSSEStream(ctx, httpResp.Body, reporter, processor, StreamConfig{
+		ExecutorName:     "xai",
+		Preprocessor:     DataTagPreprocessor(),
+		HandleDoneSignal: true,
+		EnsurePublished:  true,
+	}), nil
+}
+
+func (e *XAIExecutor) CountTokens(ctx context.Context, auth *provider.Auth, req provider.Request, opts provider.Options) (provider.Response, error) {
+	translated, err := TranslateToOpenAI(e.cfg, opts.SourceFormat, req.Model, req.Payload, false, nil)
+	if err != nil {
+		return provider.Response{}, err
+	}
+	enc, err := tokenizerForModel(req.Model)
+	if err != nil {
+		return provider.Response{}, fmt.Errorf("xai executor: tokenizer init failed: %w", err)
+	}
+	count, err := countOpenAIChatTokens(enc, translated)
+	if err != nil {
+		return provider.Response{}, fmt.Errorf("xai executor: token counting failed: %w", err)
+	}
+	return provider.Response{Payload: buildOpenAIUsageJSON(count)}, nil
+}
+
+func (e *XAIExecutor) Refresh(ctx context.Context, auth *provider.Auth) (*provider.Auth, error) {
+	_ = ctx
+	return auth, nil
+}
+
+// translate builds the chat completions body: the OpenAI translation plus
+// xAI extension fields, minus parameters the model rejects.
+func (e *XAIExecutor) translate(from provider.Format, req provider.Request, stream bool) ([]byte, error) {
+	body, err := TranslateToOpenAI(e.cfg, from, req.Model, req.Payload, stream, req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	if from != provider.FormatOpenAI {
+		for _, field := range xaiExtensionFields {
+			if v := gjson.GetBytes(req.Payload, field); v.Exists() {
+				body, _ = sjson.SetRawBytes(body, field, []byte(v.Raw))
+			}
+		}
+	}
+	if isXAIReasoningModel(req.Model) {
+		dropped := make([]string, 0, len(xaiReasoningUnsupported)+1)
+		for _, field := range xaiReasoningUnsupported {
+			if gjson.GetBytes(body, field).Exists() {
+				body, _ = sjson.DeleteBytes(body, field)
+				dropped = append(dropped, field)
+			}
+		}
+		// Only grok-3-mini takes reasoning_effort; the others always reason.
+		if !strings.HasPrefix(req.Model, "grok-3-mini") && gjson.GetBytes(body, "reasoning_effort").Exists() {
+			body, _ = sjson.DeleteBytes(body, "reasoning_effort")
+			dropped = append(dropped, "reasoning_effort")
+		}
+		if len(dropped) > 0 {
+			provider.AddWarnings(req.Metadata, fmt.Sprintf("%s does not accept %s; dropped", req.Model, strings.Join(dropped, ", ")))
+		}
+	}
+	if stream {
+		body, _ = sjson.SetBytes(body, "stream", true)
+	}
+	return applyPayloadConfigWithRoot(e.cfg, req.Model, "openai", "", body), nil
+}
+
+// isXAIReasoningModel reports whether model always reasons. Reasoning
+// models reject the sampling penalties and stop sequences.
+func isXAIReasoningModel(model string) bool {
+	switch {
+	case strings.Contains(model, "non-reasoning"):
+		return false
+	case strings.HasPrefix(model, "grok-4"), strings.HasPrefix(model, "grok-3-mini"), strings.HasPrefix(model, "grok-code"):
+		return true
+	}
+	return false
+}
+
+// post sends body to the chat completions endpoint and returns the response
+// when it has a 2xx status. The caller closes the body.
+func (e *XAIExecutor) post(ctx context.Context, auth *provider.Auth, body []byte, stream bool) (*http.Response, error) {
+	apiKey, baseURL := ExtractCreds(auth, CredExtractorConfig{TrimWhitespace: true})
+	if apiKey == "" {
+		return nil, NewStatusError(http.StatusUnauthorized, "missing xai api key", nil)
+	}
+	if baseURL == "" {
+		baseURL = XAIDefaultBaseURL
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(baseURL, "/")+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	ApplyAPIHeaders(httpReq, HeaderConfig{
+		Token:         apiKey,
+		UserAgent:     "llm-mux",
+		StreamHeaders: map[string]string{"Cache-Control": "no-cache"},
+	}, stream)
+	util.ApplyCustomHeadersFromAttrs(httpReq, auth.Attributes)
+
+	httpClient := newProxyAwareHTTPClient(ctx, e.cfg, auth, 0)
+	httpResp, err := httpClient.Do(httpReq)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, NewTimeoutError("request timed out")
+		}
+		return nil, err
+	}
+	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+		result := HandleHTTPError(httpResp, "xai executor")
+		_ = httpResp.Body.Close()
+		return nil, result.Error
+	}
+	return httpResp, nil
+}
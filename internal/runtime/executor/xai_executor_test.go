@@ -0,0 +1,114 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/tidwall/gjson"
+)
+
+func TestXAIExecutor_ChatFromClaude(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer xk" {
+			t.Errorf("Authorization = %q", got)
+		}
+		var body bytes.Buffer
+		_, _ = body.ReadFrom(r.Body)
+		got := gjson.ParseBytes(body.Bytes())
+		if got.Get("messages.0.role").String() != "system" || got.Get("messages.1.content").String() != "News today?" {
+			t.Errorf("messages = %s", got.Get("messages").Raw)
+		}
+		if got.Get("search_parameters.mode").String() != "on" {
+			t.Errorf("search_parameters not passed through: %s", body.String())
+		}
+		if got.Get("stop").Exists() {
+			t.Errorf("stop sent to a reasoning model: %s", got.Get("stop").Raw)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"c1","object":"chat.completion","model":"grok-4-0709",
+			"choices":[{"index":0,"message":{"role":"assistant","content":"Quiet day."},"finish_reason":"stop"}],
+			"usage":{"prompt_tokens":12,"completion_tokens":3,"total_tokens":15}}`))
+	}))
+	defer srv.Close()
+
+	exec := NewXAIExecutor(&config.Config{})
+	auth := &provider.Auth{Provider: "xai", Attributes: map[string]string{"api_key": "xk", "base_url": srv.URL + "/v1"}}
+	metadata := map[string]any{}
+	resp, err := exec.Execute(context.Background(), auth,
+		provider.Request{Model: "grok-4-0709", Metadata: metadata, Payload: []byte(`{"model":"grok-4-0709","max_tokens":256,
+			"system":"Be brief.","stop_sequences":["END"],"search_parameters":{"mode":"on"},
+			"messages":[{"role":"user","content":"News today?"}]}`)},
+		provider.Options{SourceFormat: provider.FormatClaude})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if got := gjson.GetBytes(resp.Payload, "content.0.text").String(); got != "Quiet day." {
+		t.Errorf("response = %s", resp.Payload)
+	}
+	if gjson.GetBytes(resp.Payload, "usage.input_tokens").Int() != 12 {
+		t.Errorf("usage = %s", gjson.GetBytes(resp.Payload, "usage").Raw)
+	}
+	if len(provider.Warnings(metadata)) == 0 {
+		t.Error("no warning for the dropped stop sequences")
+	}
+}
+
+func TestXAIExecutor_Stream(t *testing.T) {
+	events := []string{
+		`data: {"id":"c1","object":"chat.completion.chunk","model":"grok-3","choices":[{"index":0,"delta":{"role":"assistant","content":"Hel"}}]}`,
+		`data: {"id":"c1","object":"chat.completion.chunk","model":"grok-3","choices":[{"index":0,"delta":{"content":"lo"}}]}`,
+		`data: {"id":"c1","object":"chat.completion.chunk","model":"grok-3","choices":[{"index":0,"delta":{},"finish_reason":"stop"}],"usage":{"prompt_tokens":5,"completion_tokens":2,"total_tokens":7}}`,
+		`data: [DONE]`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body bytes.Buffer
+		_, _ = body.ReadFrom(r.Body)
+		if !gjson.GetBytes(body.Bytes(), "stream").Bool() {
+			t.Errorf("request body = %s", body.String())
+		}
+		if gjson.GetBytes(body.Bytes(), "frequency_penalty").Float() != 0.5 {
+			t.Errorf("frequency_penalty dropped for non-reasoning grok-3: %s", body.String())
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(strings.Join(events, "\n\n") + "\n\n"))
+	}))
+	defer srv.Close()
+
+	exec := NewXAIExecutor(&config.Config{})
+	auth := &provider.Auth{Provider: "xai", Attributes: map[string]string{"api_key": "xk", "base_url": srv.URL}}
+	stream, err := exec.ExecuteStream(context.Background(), auth,
+		provider.Request{Model: "grok-3", Payload: []byte(`{"model":"grok-3","stream":true,"frequency_penalty":0.5,"messages":[{"role":"user","content":"Hi"}]}`)},
+		provider.Options{SourceFormat: provider.FormatOpenAI, Stream: true})
+	if err != nil {
+		t.Fatalf("ExecuteStream: %v", err)
+	}
+
+	var text strings.Builder
+	var finish string
+	for chunk := range stream {
+		if chunk.Err != nil {
+			t.Fatalf("stream error: %v", chunk.Err)
+		}
+		data := bytes.TrimSpace(bytes.TrimPrefix(bytes.TrimSpace(chunk.Payload), []byte("data:")))
+		choice := gjson.GetBytes(data, "choices.0")
+		text.WriteString(choice.Get("delta.content").String())
+		if fr := choice.Get("finish_reason").String(); fr != "" {
+			finish = fr
+		}
+	}
+	if text.String() != "Hello" {
+		t.Errorf("text = %q", text.String())
+	}
+	if finish != "stop" {
+		t.Errorf("finish_reason = %q, want stop", finish)
+	}
+}
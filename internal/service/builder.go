@@ -5,12 +5,15 @@ package service
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/nghyane/llm-mux/internal/access"
 	"github.com/nghyane/llm-mux/internal/api"
 	"github.com/nghyane/llm-mux/internal/auth/login"
 	"github.com/nghyane/llm-mux/internal/config"
+	log "github.com/nghyane/llm-mux/internal/logging"
 	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/runtime/executor"
 )
 
 // Builder constructs a Service instance with customizable providers.
@@ -25,6 +28,10 @@ type Builder struct {
 	accessManager  *access.Manager
 	coreManager    *provider.Manager
 	serverOptions  []api.ServerOption
+	middleware     []provider.ExecutorMiddleware
+
+	managementAddr string
+	managementKey  string
 }
 
 // Hooks allows callers to plug into service lifecycle stages.
@@ -38,6 +45,14 @@ type Hooks struct {
 	// OnAfterStart is called after the service has started successfully,
 	// providing access to the service instance for additional operations.
 	OnAfterStart func(*Service)
+
+	// OnPreflight is called with the startup credential check results when
+	// preflight is enabled. Returning an error stops the service.
+	OnPreflight func(*Service, []provider.PreflightResult) error
+
+	// OnShutdownStart is called once when shutdown begins, before the server
+	// stops accepting requests and starts draining in-flight ones.
+	OnShutdownStart func(*Service)
 }
 
 // NewBuilder creates a Builder with default dependencies left unset.
@@ -115,6 +130,14 @@ func (b *Builder) WithCoreAuthManager(mgr *provider.Manager) *Builder {
 	return b
 }
 
+// WithExecutorMiddleware appends middleware that wraps every provider executor.
+// The first middleware given is the outermost: it sees each call first and
+// its result last.
+func (b *Builder) WithExecutorMiddleware(mws ...provider.ExecutorMiddleware) *Builder {
+	b.middleware = append(b.middleware, mws...)
+	return b
+}
+
 // WithServerOptions appends server configuration options used during construction.
 func (b *Builder) WithServerOptions(opts ...api.ServerOption) *Builder {
 	b.serverOptions = append(b.serverOptions, opts...)
@@ -130,6 +153,16 @@ func (b *Builder) WithLocalManagementPassword(password string) *Builder {
 	return b
 }
 
+// WithManagementListener binds the management API to addr (host:port), apart
+// from the public API, so the two can be firewalled differently. key is the
+// management key required on that listener; when empty the configured
+// management key applies.
+func (b *Builder) WithManagementListener(addr, key string) *Builder {
+	b.managementAddr = strings.TrimSpace(addr)
+	b.managementKey = strings.TrimSpace(key)
+	return b
+}
+
 // Build validates inputs, applies defaults, and returns a ready-to-run service.
 func (b *Builder) Build() (*Service, error) {
 	if b.cfg == nil {
@@ -138,6 +171,15 @@ func (b *Builder) Build() (*Service, error) {
 	if b.configPath == "" {
 		return nil, fmt.Errorf("cliproxy: configuration path is required")
 	}
+	serverOptions := append([]api.ServerOption(nil), b.serverOptions...)
+	if b.managementAddr != "" {
+		if err := api.ValidateManagementAddress(b.managementAddr, b.cfg.Port); err != nil {
+			return nil, fmt.Errorf("cliproxy: %w", err)
+		}
+		serverOptions = append(serverOptions, api.WithManagementListener(b.managementAddr, b.managementKey))
+	} else if b.managementKey != "" {
+		return nil, fmt.Errorf("cliproxy: a management key requires a management listener address")
+	}
 
 	tokenProvider := b.tokenProvider
 	if tokenProvider == nil {
@@ -170,6 +212,14 @@ func (b *Builder) Build() (*Service, error) {
 	}
 	accessManager.SetProviders(providers)
 
+	encrypted, err := login.ConfigureTokenEncryptionFromEnv(b.cfg.TokenKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	if encrypted {
+		log.Info("token files in auth-dir are encrypted at rest")
+	}
+
 	coreManager := b.coreManager
 	if coreManager == nil {
 		tokenStore := login.GetTokenStore()
@@ -178,9 +228,11 @@ func (b *Builder) Build() (*Service, error) {
 		}
 		coreManager = provider.NewManager(tokenStore, nil, nil)
 	}
+	if locker := login.GetRefreshLocker(); locker != nil {
+		coreManager.SetRefreshLocker(locker)
+	}
 	// Attach a default RoundTripper provider so providers can opt-in per-auth transports.
 	coreManager.SetRoundTripperProvider(newDefaultRoundTripperProvider())
-
 	service := &Service{
 		cfg:            b.cfg,
 		configPath:     b.configPath,
@@ -191,7 +243,14 @@ func (b *Builder) Build() (*Service, error) {
 		authManager:    authManager,
 		accessManager:  accessManager,
 		coreManager:    coreManager,
-		serverOptions:  append([]api.ServerOption(nil), b.serverOptions...),
+		serverOptions:  serverOptions,
 	}
+	coreManager.Use(b.middleware...)
+	// Adapts calls to accounts whose upstream serves only one mode, below
+	// custom middleware so they see the mode the client asked for.
+	coreManager.Use(executor.StreamModeMiddleware(service.currentConfig))
+	// Innermost, so faults look like upstream failures to all other
+	// middleware. It does nothing unless fault-injection is enabled.
+	coreManager.Use(provider.FaultInjectionMiddleware())
 	return service, nil
 }
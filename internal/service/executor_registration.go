@@ -65,6 +65,12 @@ func registerProviderExecutor(a *provider.Auth, cfg *config.Config, coreManager
 		coreManager.RegisterExecutor(executor.NewKiroExecutor(cfg))
 	case "github-copilot":
 		coreManager.RegisterExecutor(executor.NewGitHubCopilotExecutor(cfg))
+	case "cohere":
+		coreManager.RegisterExecutor(executor.NewCohereExecutor(cfg))
+	case "xai":
+		coreManager.RegisterExecutor(executor.NewXAIExecutor(cfg))
+	case "mistral":
+		coreManager.RegisterExecutor(executor.NewMistralExecutor(cfg))
 	default:
 		providerKey := strings.ToLower(strings.TrimSpace(a.Provider))
 		if providerKey == "" {
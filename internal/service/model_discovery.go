@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/registry"
+	"github.com/nghyane/llm-mux/internal/runtime/executor"
+)
+
+// discoveredModels is the cached models listing of one account.
+type discoveredModels struct {
+	models    []*ModelInfo
+	fetchedAt time.Time
+}
+
+var (
+	discoveryMu    sync.Mutex
+	discoveryCache = make(map[string]discoveredModels)
+)
+
+// discoverModels returns the models listed by the account's provider when
+// model discovery is enabled. Listings are cached per account for the refresh
+// interval; when a fetch fails the previous listing is kept and the registry
+// expires its models once their TTL has passed.
+func discoverModels(a *provider.Auth, cfg *config.Config) []*ModelInfo {
+	if a == nil || cfg == nil || !cfg.ModelDiscovery.Enabled {
+		return nil
+	}
+	discoveryMu.Lock()
+	entry, cached := discoveryCache[a.ID]
+	discoveryMu.Unlock()
+	if !cached || time.Since(entry.fetchedAt) >= cfg.ModelDiscovery.Interval() {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		models := executor.FetchListedModels(ctx, cfg, a)
+		cancel()
+		if len(models) > 0 {
+			now := time.Now()
+			for _, m := range models {
+				m.DiscoveredAt = now
+			}
+			entry.models = models
+			log.Debugf("model discovery: %s listed %d models", a.ID, len(models))
+		} else if cached {
+			log.Debugf("model discovery: keeping the previous listing of %s", a.ID)
+		}
+		entry.fetchedAt = time.Now()
+		discoveryMu.Lock()
+		discoveryCache[a.ID] = entry
+		discoveryMu.Unlock()
+	}
+	// The registry and applyProviderPriority modify the entries they get.
+	out := make([]*ModelInfo, len(entry.models))
+	for i, m := range entry.models {
+		clone := *m
+		out[i] = &clone
+	}
+	return out
+}
+
+// invalidateDiscoveredModels makes the next registration of every account
+// list its provider's models again.
+func invalidateDiscoveredModels() {
+	discoveryMu.Lock()
+	for id, entry := range discoveryCache {
+		entry.fetchedAt = time.Time{}
+		discoveryCache[id] = entry
+	}
+	discoveryMu.Unlock()
+}
+
+// forgetDiscoveredModels drops the cached listing of a removed account.
+func forgetDiscoveredModels(authID string) {
+	discoveryMu.Lock()
+	delete(discoveryCache, authID)
+	discoveryMu.Unlock()
+}
+
+// withDiscoveredModels appends the account's discovered models that known
+// does not already contain. Non-empty modelType and ownedBy override the
+// values reported by the listing, so the new models route like known ones.
+func withDiscoveredModels(known []*ModelInfo, a *provider.Auth, cfg *config.Config, modelType, ownedBy string) []*ModelInfo {
+	discovered := discoverModels(a, cfg)
+	if len(discovered) == 0 {
+		return known
+	}
+	seen := make(map[string]struct{}, len(known))
+	for _, m := range known {
+		seen[m.ID] = struct{}{}
+	}
+	for _, m := range discovered {
+		if _, ok := seen[m.ID]; ok {
+			continue
+		}
+		seen[m.ID] = struct{}{}
+		if modelType != "" {
+			m.Type = modelType
+		}
+		if ownedBy != "" {
+			m.OwnedBy = ownedBy
+		}
+		if m.DisplayName == "" {
+			m.DisplayName = m.ID
+		}
+		known = append(known, m)
+	}
+	return known
+}
+
+// applyModelExpiry installs the configured expiry of discovered models on
+// the registry.
+func applyModelExpiry(cfg *config.Config) {
+	if cfg == nil {
+		return
+	}
+	registry.GetGlobalRegistry().SetModelExpiry(cfg.ModelDiscovery.ModelTTL(), cfg.ModelDiscovery.Grace())
+}
+
+// refreshModels lists every account's models again and re-registers them.
+// It is the registry's refresh handler, run periodically and on demand.
+func (s *Service) refreshModels(context.Context) {
+	if s.coreManager == nil {
+		return
+	}
+	invalidateDiscoveredModels()
+	for _, a := range s.coreManager.List() {
+		if a == nil || a.Disabled {
+			continue
+		}
+		s.registerModelsForAuth(a)
+	}
+}
+
+// runModelDiscovery refreshes the registry once per refresh interval, so
+// listings and dynamically fetched models stay current and models the
+// upstreams stopped listing expire, until ctx ends.
+func (s *Service) runModelDiscovery(ctx context.Context) {
+	reg := registry.GetGlobalRegistry()
+	for {
+		s.cfgMu.RLock()
+		cfg := s.cfg
+		s.cfgMu.RUnlock()
+		timer := time.NewTimer(cfg.ModelDiscovery.Interval())
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		if cfg.ModelDiscovery.Enabled {
+			reg.Refresh(ctx)
+		}
+	}
+}
@@ -14,7 +14,7 @@ import (
 )
 
 // registerModelsForAuth (re)binds provider models in the global registry using the core auth ID as client identifier.
-func registerModelsForAuth(a *provider.Auth, cfg *config.Config, wsGateway *wsrelay.Manager) {
+func registerModelsForAuth(a *provider.Auth, cfg *config.Config, wsGateway *wsrelay.Manager, mgr *provider.Manager) {
 	if a == nil || a.ID == "" {
 		log.Debugf("registerModelsForAuth: auth is nil or empty ID")
 		return
@@ -71,6 +71,11 @@ func registerModelsForAuth(a *provider.Auth, cfg *config.Config, wsGateway *wsre
 				models = buildVertexCompatConfigModels(entry)
 			}
 		}
+		if authKind == "service_account" {
+			if entry := resolveVertexProvider(a, cfg); entry != nil {
+				excluded = entry.ExcludedModels
+			}
+		}
 		models = applyExcludedModels(models, excluded)
 	case "gemini-cli":
 		// Try dynamic fetch first, fallback to static
@@ -107,6 +112,7 @@ func registerModelsForAuth(a *provider.Auth, cfg *config.Config, wsGateway *wsre
 				excluded = entry.ExcludedModels
 			}
 		}
+		models = withDiscoveredModels(models, a, cfg, "claude", "")
 		models = applyExcludedModels(models, excluded)
 	case "codex":
 		models = registry.GetOpenAIModels()
@@ -115,6 +121,7 @@ func registerModelsForAuth(a *provider.Auth, cfg *config.Config, wsGateway *wsre
 				excluded = entry.ExcludedModels
 			}
 		}
+		models = withDiscoveredModels(models, a, cfg, "codex", "")
 		models = applyExcludedModels(models, excluded)
 	case "qwen":
 		models = registry.GetQwenModels()
@@ -131,7 +138,38 @@ func registerModelsForAuth(a *provider.Auth, cfg *config.Config, wsGateway *wsre
 	case "github-copilot":
 		models = registry.GetGitHubCopilotModels()
 		models = applyExcludedModels(models, excluded)
+	case "cohere":
+		models = registry.GetCohereModels()
+		if entry := resolveProvider(a, cfg, config.ProviderTypeCohere); entry != nil {
+			if len(entry.Models) > 0 {
+				models = buildCohereConfigModels(entry)
+			}
+			excluded = entry.ExcludedModels
+		}
+		models = withDiscoveredModels(models, a, cfg, "cohere", "")
+		models = applyExcludedModels(models, excluded)
+	case "xai":
+		models = registry.GetXAIModels()
+		if entry := resolveProvider(a, cfg, config.ProviderTypeXAI); entry != nil {
+			excluded = entry.ExcludedModels
+		}
+		models = withDiscoveredModels(models, a, cfg, "xai", "")
+		models = applyExcludedModels(models, excluded)
+	case "mistral":
+		models = registry.GetMistralModels()
+		if entry := resolveProvider(a, cfg, config.ProviderTypeMistral); entry != nil {
+			excluded = entry.ExcludedModels
+		}
+		models = withDiscoveredModels(models, a, cfg, "mistral", "")
+		models = applyExcludedModels(models, excluded)
 	default:
+		// Custom executors may declare their own models.
+		if !compatDetected && mgr != nil {
+			if listed := mgr.ExecutorModels(a.Provider); len(listed) > 0 {
+				models = applyExcludedModels(listed, excluded)
+				break
+			}
+		}
 		handleOpenAICompatProvider(a, compatProviderKey, compatDisplayName, compatDetected, cfg)
 		return
 	}
@@ -209,8 +247,11 @@ func handleOpenAICompatProvider(a *provider.Auth, compatProviderKey, compatDispl
 					OwnedBy:     p.Name,
 					Type:        "openai-compatibility",
 					DisplayName: m.Name,
+					Category:    modelCategory(m.Type),
 				})
 			}
+			ms = withDiscoveredModels(ms, a, cfg, "openai-compatibility", p.Name)
+			ms = applyExcludedModels(ms, p.ExcludedModels)
 			if len(ms) > 0 {
 				if providerKey == "" {
 					providerKey = "openai-compatibility"
@@ -255,6 +296,21 @@ func resolveProvider(auth *provider.Auth, cfg *config.Config, providerType confi
 	return nil
 }
 
+// resolveVertexProvider finds the vertex provider entry an auth was built from.
+func resolveVertexProvider(auth *provider.Auth, cfg *config.Config) *config.Provider {
+	if auth == nil || cfg == nil {
+		return nil
+	}
+	project := strings.TrimSpace(auth.Attributes["project_id"])
+	for i := range cfg.Providers {
+		p := &cfg.Providers[i]
+		if p.Type == config.ProviderTypeVertex && p.Project == project {
+			return p
+		}
+	}
+	return nil
+}
+
 // oauthExcludedModels returns the list of models excluded for OAuth authentication.
 func oauthExcludedModels(providerName, authKind string, cfg *config.Config) []string {
 	if cfg == nil {
@@ -262,7 +318,7 @@ func oauthExcludedModels(providerName, authKind string, cfg *config.Config) []st
 	}
 	authKindKey := strings.ToLower(strings.TrimSpace(authKind))
 	providerKey := strings.ToLower(strings.TrimSpace(providerName))
-	if authKindKey == "apikey" {
+	if authKindKey == "apikey" || authKindKey == "service_account" {
 		return nil
 	}
 	return cfg.OAuthExcludedModels[providerKey]
@@ -283,3 +339,11 @@ func applyProviderPriority(models []*ModelInfo, providerName string, cfg *config
 	}
 	return models
 }
+
+// modelCategory maps a configured model type onto a registry category.
+func modelCategory(modelType string) string {
+	if strings.EqualFold(strings.TrimSpace(modelType), registry.ModelCategoryRerank) {
+		return registry.ModelCategoryRerank
+	}
+	return ""
+}
@@ -93,7 +93,7 @@ func (p *defaultRoundTripperProvider) RoundTripperFor(auth *provider.Auth) http.
 	}
 	var transport *http.Transport
 	switch proxyURL.Scheme {
-	case "socks5":
+	case "socks5", "socks5h":
 		var proxyAuth *proxy.Auth
 		if proxyURL.User != nil {
 			username := proxyURL.User.Username()
@@ -14,9 +14,12 @@ import (
 
 	"github.com/nghyane/llm-mux/internal/access"
 	"github.com/nghyane/llm-mux/internal/api"
+	"github.com/nghyane/llm-mux/internal/api/middleware"
 	"github.com/nghyane/llm-mux/internal/auth/login"
 	"github.com/nghyane/llm-mux/internal/config"
 	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/registry"
+	"github.com/nghyane/llm-mux/internal/runtime/executor"
 	"github.com/nghyane/llm-mux/internal/usage"
 	"github.com/nghyane/llm-mux/internal/util"
 	"github.com/nghyane/llm-mux/internal/watcher"
@@ -269,6 +272,7 @@ func (s *Service) applyCoreAuthRemoval(ctx context.Context, id string) {
 		return
 	}
 	GlobalModelRegistry().UnregisterClient(id)
+	forgetDiscoveredModels(id)
 	if existing, ok := s.coreManager.GetByID(id); ok && existing != nil {
 		existing.Disabled = true
 		existing.Status = provider.StatusDisabled
@@ -284,6 +288,180 @@ func (s *Service) applyRetryConfig(cfg *config.Config) {
 	}
 	maxInterval := time.Duration(cfg.MaxRetryInterval) * time.Second
 	s.coreManager.SetRetryConfig(cfg.RequestRetry, maxInterval)
+	s.coreManager.ConfigureRetryBudget(cfg.RetryBudget.Ratio, cfg.RetryBudget.Floor(), cfg.RetryBudget.WindowDuration())
+	s.coreManager.SetStreamIdleTimeout(func(provider string) time.Duration {
+		return cfg.ProviderTimeouts(provider).StreamIdle
+	})
+	s.coreManager.SetStreamBufferSize(cfg.StreamBufferSize)
+	s.coreManager.SetConcurrencyLimits(func(name string) provider.ConcurrencyLimit {
+		c := cfg.ProviderConcurrency(name)
+		return provider.ConcurrencyLimit{PerAccount: c.MaxPerAccount, QueueDepth: c.QueueDepth, QueueWait: c.QueueWaitDuration()}
+	})
+	s.coreManager.SetRetryableErrors(retryableErrorMatchers(cfg))
+}
+
+// retryableErrorMatchers compiles the configured retryable errors once,
+// keyed by lower-cased provider name.
+func retryableErrorMatchers(cfg *config.Config) provider.RetryableErrorFunc {
+	if len(cfg.RetryableErrors) == 0 {
+		return nil
+	}
+	byProvider := make(map[string][]provider.ErrorMatcher, len(cfg.RetryableErrors))
+	for name, rules := range cfg.RetryableErrors {
+		key := strings.ToLower(strings.TrimSpace(name))
+		for _, r := range rules {
+			pattern, err := r.Pattern()
+			if err != nil {
+				log.Warnf("retryable-errors.%s: skipping body-regex %q: %v", name, r.BodyRegex, err)
+				continue
+			}
+			byProvider[key] = append(byProvider[key], provider.ErrorMatcher{Status: r.Status, Body: r.Body, Pattern: pattern})
+		}
+	}
+	return func(name string) []provider.ErrorMatcher {
+		return byProvider[strings.ToLower(strings.TrimSpace(name))]
+	}
+}
+
+// applyDailyQuotaConfig installs the configured per-account daily limits on
+// the core manager, keeping counters across reloads. Counters are loaded from
+// and saved to the configured state file.
+func (s *Service) applyDailyQuotaConfig(cfg *config.Config) {
+	if s == nil || s.coreManager == nil || cfg == nil {
+		return
+	}
+	quota := s.coreManager.DailyQuota()
+	if !cfg.DailyQuota.Enabled() {
+		if quota != nil {
+			s.coreManager.SetDailyQuota(nil)
+			if err := quota.StopPersistence(); err != nil {
+				log.Warnf("failed to save daily quota counters: %v", err)
+			}
+		}
+		return
+	}
+	limits := cfg.DailyQuota
+	limit := func(a *provider.Auth) provider.DailyLimit {
+		requests, tokens := limits.LimitFor(a.Provider, a.ID, a.FileName, a.Label)
+		return provider.DailyLimit{Requests: requests, Tokens: tokens}
+	}
+	if quota == nil {
+		quota = provider.NewDailyQuota(limits.ResetHour, limits.Location(), limit)
+	} else {
+		quota.Configure(limits.ResetHour, limits.Location(), limit)
+	}
+	if err := quota.StartPersistence(limits.CountersPath()); err != nil {
+		log.Warnf("failed to load daily quota counters: %v", err)
+	}
+	s.coreManager.SetDailyQuota(quota)
+}
+
+// applySelectionConfig installs the configured account selection strategy,
+// keeping the current selector when the strategy did not change.
+func (s *Service) applySelectionConfig(cfg *config.Config) {
+	if s == nil || s.coreManager == nil || cfg == nil {
+		return
+	}
+	_, quota := s.coreManager.Selector().(*provider.QuotaSelector)
+	switch strategy := cfg.SelectionStrategy(); {
+	case strategy == config.SelectionQuota && !quota:
+		s.coreManager.SetSelector(provider.NewQuotaSelector(s.coreManager.DailyQuota))
+	case strategy != config.SelectionQuota && quota:
+		s.coreManager.SetSelector(nil)
+	}
+}
+
+// dailyQuotaUsage counts the tokens of each successful request against the
+// account's daily quota.
+type dailyQuotaUsage struct {
+	manager *provider.Manager
+}
+
+// HandleUsage implements usage.Plugin.
+func (p dailyQuotaUsage) HandleUsage(_ context.Context, record usage.Record) {
+	if record.Failed || record.Usage == nil {
+		return
+	}
+	tokens := record.Usage.TotalTokens
+	if tokens == 0 {
+		tokens = record.Usage.PromptTokens + record.Usage.CompletionTokens
+	}
+	p.manager.RecordDailyTokens(record.AuthID, tokens)
+}
+
+// runPreflight checks the credentials of every loaded account and fails when a
+// required provider has no usable account or the OnPreflight hook objects.
+func (s *Service) runPreflight(ctx context.Context) error {
+	if s == nil || s.coreManager == nil || s.cfg == nil || !s.cfg.Preflight.Enabled {
+		return nil
+	}
+	// Auths synthesized from the config arrive through the update queue;
+	// register them now so the check sees every account.
+	if s.watcher != nil {
+		for _, a := range s.watcher.SnapshotAuths() {
+			s.applyCoreAuthAddOrUpdate(ctx, a)
+		}
+	}
+	cfg := s.cfg
+	results := s.coreManager.Preflight(ctx, provider.PreflightOptions{
+		Concurrency: cfg.Preflight.Concurrency,
+		Timeout:     time.Duration(cfg.Preflight.Timeout) * time.Second,
+		Check: func(ctx context.Context, auth *provider.Auth) error {
+			return executor.CheckCredentials(ctx, cfg, auth)
+		},
+	})
+
+	usable := make(map[string]int)
+	valid, invalid := 0, 0
+	for _, r := range results {
+		if r.Usable() {
+			usable[strings.ToLower(r.Provider)]++
+		}
+		switch {
+		case r.Valid:
+			valid++
+		case !r.Skipped:
+			invalid++
+		}
+	}
+	log.Infof("preflight: %d accounts valid, %d invalid, %d unchecked", valid, invalid, len(results)-valid-invalid)
+
+	for _, required := range cfg.Preflight.RequiredProviders {
+		if usable[strings.ToLower(strings.TrimSpace(required))] == 0 {
+			return fmt.Errorf("preflight: no usable account for required provider %s", required)
+		}
+	}
+	if s.hooks.OnPreflight != nil {
+		return s.hooks.OnPreflight(s, results)
+	}
+	return nil
+}
+
+// applyModelFamilies publishes the config-defined model families to the registry.
+// Invalid definitions are logged and the previously applied set stays active.
+func applyModelFamilies(cfg *config.Config) {
+	if cfg == nil {
+		return
+	}
+	defs := make(map[string][]registry.FamilyMember, len(cfg.ModelFamilies))
+	for id, members := range cfg.ModelFamilies {
+		converted := make([]registry.FamilyMember, len(members))
+		for i, m := range members {
+			converted[i] = registry.FamilyMember{Provider: m.Provider, Model: m.Model}
+		}
+		defs[id] = converted
+	}
+	if err := registry.SetConfigModelFamilies(defs); err != nil {
+		log.Warnf("ignoring invalid model-families config: %v", err)
+	}
+}
+
+// applyLogRedaction installs the configured extra redaction names for request logs.
+func applyLogRedaction(cfg *config.Config) {
+	if cfg == nil {
+		return
+	}
+	log.SetRedaction(cfg.LogRedaction.Headers, cfg.LogRedaction.Fields)
 }
 
 func openAICompatInfoFromAuth(a *provider.Auth) (providerKey string, compatName string, ok bool) {
@@ -314,6 +492,13 @@ func (s *Service) ensureExecutorsForAuth(a *provider.Auth) {
 }
 
 // rebindExecutors refreshes provider executors so they observe the latest configuration.
+// currentConfig returns the configuration in effect.
+func (s *Service) currentConfig() *config.Config {
+	s.cfgMu.RLock()
+	defer s.cfgMu.RUnlock()
+	return s.cfg
+}
+
 func (s *Service) rebindExecutors() {
 	if s == nil {
 		return
@@ -340,10 +525,10 @@ func (s *Service) Run(ctx context.Context) error {
 
 	usage.StartDefault(ctx)
 
-	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer shutdownCancel()
 	defer func() {
-		if err := s.Shutdown(shutdownCtx); err != nil {
+		// The drain deadline is applied by Shutdown; a fresh context keeps
+		// in-flight requests alive after ctx has been cancelled by a signal.
+		if err := s.Shutdown(context.Background()); err != nil {
 			log.Errorf("service shutdown returned error: %v", err)
 		}
 	}()
@@ -353,6 +538,20 @@ func (s *Service) Run(ctx context.Context) error {
 	}
 
 	s.applyRetryConfig(s.cfg)
+	s.applyDailyQuotaConfig(s.cfg)
+	s.applySelectionConfig(s.cfg)
+	if s.coreManager != nil {
+		usage.RegisterPlugin(dailyQuotaUsage{manager: s.coreManager})
+	}
+	applyModelFamilies(s.cfg)
+	applyModelExpiry(s.cfg)
+	applyLogRedaction(s.cfg)
+
+	if s.configPath != "" {
+		if errFamilies := registry.LoadModelFamilies(registry.ModelFamiliesPath(s.configPath)); errFamilies != nil {
+			log.Warnf("failed to load model families: %v", errFamilies)
+		}
+	}
 
 	if s.coreManager != nil {
 		if errLoad := s.coreManager.Load(ctx); errLoad != nil {
@@ -422,6 +621,8 @@ func (s *Service) Run(ctx context.Context) error {
 	if s.hooks.OnAfterStart != nil {
 		s.hooks.OnAfterStart(s)
 	}
+	// Executors registered by hooks may declare models for auths loaded earlier.
+	s.registerExecutorModels()
 
 	var watcherWrapper *WatcherWrapper
 	reloadCallback := func(newCfg *config.Config) {
@@ -434,6 +635,11 @@ func (s *Service) Run(ctx context.Context) error {
 			return
 		}
 		s.applyRetryConfig(newCfg)
+		s.applyDailyQuotaConfig(newCfg)
+		s.applySelectionConfig(newCfg)
+		applyModelFamilies(newCfg)
+		applyModelExpiry(newCfg)
+		applyLogRedaction(newCfg)
 		if s.server != nil {
 			s.server.UpdateClients(newCfg)
 		}
@@ -466,12 +672,18 @@ func (s *Service) Run(ctx context.Context) error {
 	}
 	log.Info("file watcher started for config and auth directory changes")
 
+	if err = s.runPreflight(ctx); err != nil {
+		return err
+	}
+
 	// Prefer core auth manager auto refresh if available.
 	if s.coreManager != nil {
 		interval := 15 * time.Minute
 		s.coreManager.StartAutoRefresh(context.Background(), interval)
 		log.Infof("core auth auto-refresh started (interval=%s)", interval)
 	}
+	registry.GetGlobalRegistry().SetRefreshHandler(s.refreshModels)
+	go s.runModelDiscovery(ctx)
 
 	select {
 	case <-ctx.Done():
@@ -483,11 +695,13 @@ func (s *Service) Run(ctx context.Context) error {
 }
 
 // Shutdown gracefully stops background workers and the HTTP server.
-// It ensures all resources are properly cleaned up and connections are closed.
+// The server stops accepting new requests and waits up to the configured
+// shutdown-drain-timeout for in-flight requests, including streams, before
+// forcing the remaining connections closed.
 // The shutdown is idempotent and can be called multiple times safely.
 //
 // Parameters:
-//   - ctx: The context for controlling the shutdown timeout
+//   - ctx: The context for controlling the shutdown timeout; the drain timeout is applied on top of it
 //
 // Returns:
 //   - error: An error if shutdown fails
@@ -500,6 +714,9 @@ func (s *Service) Shutdown(ctx context.Context) error {
 		if ctx == nil {
 			ctx = context.Background()
 		}
+		if s.hooks.OnShutdownStart != nil {
+			s.hooks.OnShutdownStart(s)
+		}
 
 		if s.watcherCancel != nil {
 			s.watcherCancel()
@@ -527,7 +744,10 @@ func (s *Service) Shutdown(ctx context.Context) error {
 		}
 
 		if s.server != nil {
-			shutdownCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+			s.cfgMu.RLock()
+			drainTimeout := s.cfg.DrainTimeout()
+			s.cfgMu.RUnlock()
+			shutdownCtx, cancel := context.WithTimeout(ctx, drainTimeout)
 			defer cancel()
 			if err := s.server.Stop(shutdownCtx); err != nil {
 				log.Errorf("error stopping API server: %v", err)
@@ -537,11 +757,26 @@ func (s *Service) Shutdown(ctx context.Context) error {
 			}
 		}
 
+		if s.coreManager != nil {
+			if err := s.coreManager.DailyQuota().StopPersistence(); err != nil {
+				log.Errorf("failed to save daily quota counters: %v", err)
+			}
+		}
+
 		usage.StopDefault()
 	})
 	return shutdownErr
 }
 
+// DrainStats reports how many requests were drained or forcibly terminated
+// while the HTTP server was shutting down.
+func (s *Service) DrainStats() middleware.DrainStats {
+	if s == nil {
+		return middleware.DrainStats{}
+	}
+	return s.server.DrainStats()
+}
+
 func (s *Service) ensureAuthDir() error {
 	authDir, err := util.ResolveAuthDir(s.cfg.AuthDir)
 	if err != nil {
@@ -565,12 +800,26 @@ func (s *Service) ensureAuthDir() error {
 	return nil
 }
 
+// registerExecutorModels registers models for every auth whose executor
+// declares them through provider.ModelLister.
+func (s *Service) registerExecutorModels() {
+	if s == nil || s.coreManager == nil {
+		return
+	}
+	for _, a := range s.coreManager.List() {
+		if a == nil || a.Disabled || len(s.coreManager.ExecutorModels(a.Provider)) == 0 {
+			continue
+		}
+		s.registerModelsForAuth(a)
+	}
+}
+
 // registerModelsForAuth delegates model registration to extracted function.
 func (s *Service) registerModelsForAuth(a *provider.Auth) {
 	if s == nil {
 		return
 	}
-	registerModelsForAuth(a, s.cfg, s.wsGateway)
+	registerModelsForAuth(a, s.cfg, s.wsGateway, s.coreManager)
 }
 
 func applyExcludedModels(models []*ModelInfo, excluded []string) []*ModelInfo {
@@ -727,3 +976,42 @@ func buildClaudeConfigModels(entry *config.Provider) []*ModelInfo {
 	}
 	return out
 }
+
+func buildCohereConfigModels(entry *config.Provider) []*ModelInfo {
+	if entry == nil || len(entry.Models) == 0 {
+		return nil
+	}
+	now := time.Now().Unix()
+	out := make([]*ModelInfo, 0, len(entry.Models))
+	seen := make(map[string]struct{}, len(entry.Models))
+	for i := range entry.Models {
+		model := entry.Models[i]
+		name := strings.TrimSpace(model.Name)
+		alias := strings.TrimSpace(model.Alias)
+		if alias == "" {
+			alias = name
+		}
+		if alias == "" {
+			continue
+		}
+		key := strings.ToLower(alias)
+		if _, exists := seen[key]; exists {
+			continue
+		}
+		seen[key] = struct{}{}
+		display := name
+		if display == "" {
+			display = alias
+		}
+		out = append(out, &ModelInfo{
+			ID:          alias,
+			Object:      "model",
+			Created:     now,
+			OwnedBy:     "cohere",
+			Type:        "cohere",
+			DisplayName: display,
+			Category:    modelCategory(model.Type),
+		})
+	}
+	return out
+}
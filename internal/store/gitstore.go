@@ -18,6 +18,7 @@ import (
 	"github.com/go-git/go-git/v6/plumbing/object"
 	"github.com/go-git/go-git/v6/plumbing/transport"
 	"github.com/go-git/go-git/v6/plumbing/transport/http"
+	"github.com/nghyane/llm-mux/internal/persist"
 	"github.com/nghyane/llm-mux/internal/provider"
 )
 
@@ -259,13 +260,8 @@ func (s *GitTokenStore) Save(_ context.Context, auth *provider.Auth) (string, er
 		} else if !os.IsNotExist(errRead) {
 			return "", fmt.Errorf("auth filestore: read existing failed: %w", errRead)
 		}
-		tmp := path + ".tmp"
-		if errWrite := os.WriteFile(tmp, raw, 0o600); errWrite != nil {
-			return "", fmt.Errorf("auth filestore: write temp failed: %w", errWrite)
-		}
-		if errRename := os.Rename(tmp, path); errRename != nil {
-			_ = os.Remove(tmp) // cleanup temp file on rename failure
-			return "", fmt.Errorf("auth filestore: rename failed: %w", errRename)
+		if errWrite := persist.WriteFile(path, raw); errWrite != nil {
+			return "", fmt.Errorf("auth filestore: write auth file: %w", errWrite)
 		}
 	default:
 		return "", fmt.Errorf("auth filestore: nothing to persist for %s", auth.ID)
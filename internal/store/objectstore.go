@@ -15,9 +15,11 @@ import (
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
 	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/persist"
 	"github.com/nghyane/llm-mux/internal/provider"
 	log "github.com/nghyane/llm-mux/internal/logging"
 )
@@ -25,6 +27,11 @@ import (
 const (
 	objectStoreConfigKey  = "config/config.yaml"
 	objectStoreAuthPrefix = "auths"
+	objectStoreLockPrefix = "locks"
+	// objectRefreshLockTTL bounds how long a crashed instance blocks
+	// refreshes of the account it was refreshing.
+	objectRefreshLockTTL = time.Minute
+	objectLockRetry      = 250 * time.Millisecond
 )
 
 // ObjectStoreConfig captures configuration for the object storage-backed token store.
@@ -199,13 +206,8 @@ func (s *ObjectTokenStore) Save(ctx context.Context, auth *provider.Auth) (strin
 		} else if !errors.Is(errRead, fs.ErrNotExist) {
 			return "", fmt.Errorf("object store: read existing metadata: %w", errRead)
 		}
-		tmp := path + ".tmp"
-		if errWrite := os.WriteFile(tmp, raw, 0o600); errWrite != nil {
-			return "", fmt.Errorf("object store: write temp auth file: %w", errWrite)
-		}
-		if errRename := os.Rename(tmp, path); errRename != nil {
-			_ = os.Remove(tmp) // cleanup temp file on rename failure
-			return "", fmt.Errorf("object store: rename auth file: %w", errRename)
+		if errWrite := persist.WriteFile(path, raw); errWrite != nil {
+			return "", fmt.Errorf("object store: write auth file: %w", errWrite)
 		}
 	default:
 		return "", fmt.Errorf("object store: nothing to persist for %s", auth.ID)
@@ -282,6 +284,166 @@ func (s *ObjectTokenStore) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+// Load fetches the record of id as last saved by any instance.
+func (s *ObjectTokenStore) Load(ctx context.Context, id string) (*provider.Auth, error) {
+	path, err := s.resolveDeletePath(id)
+	if err != nil {
+		return nil, err
+	}
+	rel, err := filepath.Rel(s.authDir, path)
+	if err != nil {
+		return nil, fmt.Errorf("object store: resolve auth relative path: %w", err)
+	}
+	key := s.prefixedKey(objectStoreAuthPrefix + "/" + filepath.ToSlash(rel))
+	object, err := s.client.GetObject(ctx, s.cfg.Bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("object store: fetch auth %s: %w", key, err)
+	}
+	defer object.Close()
+	data, err := io.ReadAll(object)
+	if err != nil {
+		if isObjectNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("object store: read auth %s: %w", key, err)
+	}
+	metadata := make(map[string]any)
+	if err = json.Unmarshal(data, &metadata); err != nil {
+		return nil, fmt.Errorf("object store: unmarshal auth %s: %w", key, err)
+	}
+	providerName := strings.TrimSpace(valueAsString(metadata["type"]))
+	if providerName == "" {
+		providerName = "unknown"
+	}
+	attr := map[string]string{"path": path}
+	if email := strings.TrimSpace(valueAsString(metadata["email"])); email != "" {
+		attr["email"] = email
+	}
+	relID := normalizeAuthID(rel)
+	return &provider.Auth{
+		ID:         relID,
+		Provider:   providerName,
+		FileName:   relID,
+		Label:      labelFor(metadata),
+		Status:     provider.StatusActive,
+		Attributes: attr,
+		Metadata:   metadata,
+	}, nil
+}
+
+// objectLock is the content of a refresh lock object.
+type objectLock struct {
+	Token   string    `json:"token"`
+	Expires time.Time `json:"expires"`
+}
+
+// LockRefresh takes the refresh lock of id, shared by all instances. The lock
+// is an object created only if absent; one left by a crashed instance is
+// taken over once it has expired.
+func (s *ObjectTokenStore) LockRefresh(ctx context.Context, id string) (func(), error) {
+	path, err := s.resolveDeletePath(id)
+	if err != nil {
+		return nil, err
+	}
+	rel, err := filepath.Rel(s.authDir, path)
+	if err != nil {
+		return nil, fmt.Errorf("object store: resolve auth relative path: %w", err)
+	}
+	key := s.prefixedKey(objectStoreLockPrefix + "/" + filepath.ToSlash(rel))
+	token := uuid.NewString()
+	for {
+		taken, errLock := s.tryLock(ctx, key, token)
+		if errLock != nil {
+			return nil, fmt.Errorf("object store: lock %s: %w", key, errLock)
+		}
+		if taken {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("object store: lock %s: %w", key, ctx.Err())
+		case <-time.After(objectLockRetry):
+		}
+	}
+	return func() {
+		releaseCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		// Only an unexpired lock is removed: once it expires another
+		// instance may take it over, and that lock must stay.
+		held, _, errRead := s.readLock(releaseCtx, key)
+		if errRead != nil || held == nil || held.Token != token || !time.Now().Before(held.Expires) {
+			return
+		}
+		if errRemove := s.client.RemoveObject(releaseCtx, s.cfg.Bucket, key, minio.RemoveObjectOptions{}); errRemove != nil {
+			log.WithError(errRemove).Warnf("object store: release lock %s", key)
+		}
+	}, nil
+}
+
+// tryLock makes one attempt to take the lock at key for token, and reports
+// false while another instance holds it. An expired lock is replaced only if
+// its ETag is unchanged since it was read, so when several instances find it
+// expired exactly one takes it over.
+func (s *ObjectTokenStore) tryLock(ctx context.Context, key, token string) (bool, error) {
+	body, _ := json.Marshal(objectLock{Token: token, Expires: time.Now().Add(objectRefreshLockTTL)})
+	opts := minio.PutObjectOptions{ContentType: "application/json"}
+	opts.SetMatchETagExcept("*")
+	_, err := s.client.PutObject(ctx, s.cfg.Bucket, key, bytes.NewReader(body), int64(len(body)), opts)
+	if err == nil {
+		return true, nil
+	}
+	if minio.ToErrorResponse(err).StatusCode != http.StatusPreconditionFailed {
+		return false, err
+	}
+	held, etag, err := s.readLock(ctx, key)
+	if err != nil || held == nil || !time.Now().After(held.Expires) {
+		return false, nil
+	}
+	return s.takeOverLock(ctx, key, etag, body)
+}
+
+// takeOverLock replaces the expired lock at key, last read with etag, by
+// body. It reports false when another instance changed the lock first.
+func (s *ObjectTokenStore) takeOverLock(ctx context.Context, key, etag string, body []byte) (bool, error) {
+	opts := minio.PutObjectOptions{ContentType: "application/json"}
+	opts.SetMatchETag(etag)
+	_, err := s.client.PutObject(ctx, s.cfg.Bucket, key, bytes.NewReader(body), int64(len(body)), opts)
+	if err == nil {
+		log.Warnf("object store: took over expired refresh lock %s", key)
+		return true, nil
+	}
+	switch minio.ToErrorResponse(err).StatusCode {
+	case http.StatusPreconditionFailed, http.StatusNotFound:
+		return false, nil
+	}
+	return false, err
+}
+
+// readLock returns the lock at key and its ETag, or nil when there is none.
+func (s *ObjectTokenStore) readLock(ctx context.Context, key string) (*objectLock, string, error) {
+	object, err := s.client.GetObject(ctx, s.cfg.Bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, "", err
+	}
+	defer object.Close()
+	info, err := object.Stat()
+	if err != nil {
+		if isObjectNotFound(err) {
+			return nil, "", nil
+		}
+		return nil, "", err
+	}
+	data, err := io.ReadAll(object)
+	if err != nil {
+		return nil, "", err
+	}
+	var lock objectLock
+	if err = json.Unmarshal(data, &lock); err != nil {
+		return nil, "", err
+	}
+	return &lock, info.ETag, nil
+}
+
 // PersistAuthFiles uploads the provided auth files to the object storage backend.
 func (s *ObjectTokenStore) PersistAuthFiles(ctx context.Context, _ string, paths ...string) error {
 	if len(paths) == 0 {
@@ -0,0 +1,224 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/nghyane/llm-mux/internal/json"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/store/storetest"
+)
+
+// fakeS3 serves the part of the S3 API the object store uses: path-style
+// buckets, objects, listing and If-None-Match and If-Match conditional puts.
+type fakeS3 struct {
+	mu      sync.Mutex
+	buckets map[string]map[string][]byte
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	objects, exists := f.buckets[bucket]
+	if key == "" {
+		switch {
+		case r.Method == http.MethodPut:
+			f.buckets[bucket] = map[string][]byte{}
+		case !exists:
+			s3Error(w, http.StatusNotFound, "NoSuchBucket")
+		case r.URL.Query().Has("location"):
+			_, _ = io.WriteString(w, `<LocationConstraint>us-east-1</LocationConstraint>`)
+		case r.Method == http.MethodGet:
+			f.list(w, objects, r.URL.Query())
+		}
+		return
+	}
+	if !exists {
+		s3Error(w, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+	switch r.Method {
+	case http.MethodPut:
+		current, ok := objects[key]
+		if ok && r.Header.Get("If-None-Match") == "*" {
+			s3Error(w, http.StatusPreconditionFailed, "PreconditionFailed")
+			return
+		}
+		if match := r.Header.Get("If-Match"); match != "" && (!ok || match != etag(current)) {
+			s3Error(w, http.StatusPreconditionFailed, "PreconditionFailed")
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		if strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+			data = decodeAWSChunked(data)
+		}
+		objects[key] = data
+		w.Header().Set("ETag", etag(data))
+	case http.MethodGet, http.MethodHead:
+		data, ok := objects[key]
+		if !ok {
+			s3Error(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		w.Header().Set("ETag", etag(data))
+		w.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(data)
+		}
+	case http.MethodDelete:
+		delete(objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func etag(data []byte) string {
+	sum := md5.Sum(data)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+func (f *fakeS3) list(w http.ResponseWriter, objects map[string][]byte, query url.Values) {
+	type content struct {
+		Key  string
+		Size int
+	}
+	result := struct {
+		XMLName  xml.Name `xml:"ListBucketResult"`
+		Prefix   string
+		KeyCount int
+		Contents []content
+	}{Prefix: query.Get("prefix")}
+	for key, data := range objects {
+		if strings.HasPrefix(key, result.Prefix) {
+			result.Contents = append(result.Contents, content{Key: key, Size: len(data)})
+		}
+	}
+	sort.Slice(result.Contents, func(i, j int) bool { return result.Contents[i].Key < result.Contents[j].Key })
+	result.KeyCount = len(result.Contents)
+	w.Header().Set("Content-Type", "application/xml")
+	_ = xml.NewEncoder(w).Encode(result)
+}
+
+// decodeAWSChunked strips the chunk framing of a streaming-signed upload:
+// "<hex size>;chunk-signature=...\r\n<data>\r\n", ending with a zero size.
+func decodeAWSChunked(body []byte) []byte {
+	var out []byte
+	for len(body) > 0 {
+		header, rest, ok := strings.Cut(string(body), "\r\n")
+		if !ok {
+			break
+		}
+		sizeHex, _, _ := strings.Cut(header, ";")
+		size, err := strconv.ParseInt(sizeHex, 16, 64)
+		if err != nil || size == 0 || int(size) > len(rest) {
+			break
+		}
+		out = append(out, rest[:size]...)
+		body = []byte(strings.TrimPrefix(rest[size:], "\r\n"))
+	}
+	return out
+}
+
+func s3Error(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	_, _ = io.WriteString(w, "<Error><Code>"+code+"</Code><Message>"+code+"</Message></Error>")
+}
+
+func newObjectBackend(t *testing.T) storetest.Opener {
+	open := openObjectStores(t)
+	return func(t *testing.T) provider.Store { return open(t) }
+}
+
+// openObjectStores starts a fake S3 server and returns a function opening
+// stores on it.
+func openObjectStores(t *testing.T) func(t *testing.T) *ObjectTokenStore {
+	server := httptest.NewServer(&fakeS3{buckets: map[string]map[string][]byte{}})
+	t.Cleanup(server.Close)
+	return func(t *testing.T) *ObjectTokenStore {
+		s, err := NewObjectTokenStore(ObjectStoreConfig{
+			Endpoint:  strings.TrimPrefix(server.URL, "http://"),
+			Bucket:    "tokens",
+			AccessKey: "access",
+			SecretKey: "secret",
+			Region:    "us-east-1",
+			LocalRoot: t.TempDir(),
+			PathStyle: true,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = s.Bootstrap(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+}
+
+func TestObjectTokenStoreConformance(t *testing.T) {
+	storetest.Run(t, newObjectBackend)
+}
+
+func TestObjectTokenStore_ExpiredLockTakenOverOnce(t *testing.T) {
+	open := openObjectStores(t)
+	a, b := open(t), open(t)
+	ctx := context.Background()
+	key := a.prefixedKey(objectStoreLockPrefix + "/claude-me.json")
+	expired, _ := json.Marshal(objectLock{Token: "crashed", Expires: time.Now().Add(-time.Minute)})
+	if _, err := a.client.PutObject(ctx, a.cfg.Bucket, key, bytes.NewReader(expired), int64(len(expired)), minio.PutObjectOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Both instances find the same expired lock before either replaces it.
+	_, etagA, errA := a.readLock(ctx, key)
+	_, etagB, errB := b.readLock(ctx, key)
+	if errA != nil || errB != nil || etagA == "" || etagA != etagB {
+		t.Fatalf("readLock: %q %v, %q %v", etagA, errA, etagB, errB)
+	}
+	if taken, err := a.takeOverLock(ctx, key, etagA, []byte(`{"token":"a"}`)); !taken || err != nil {
+		t.Fatalf("first takeover = %v, %v", taken, err)
+	}
+	if taken, err := b.takeOverLock(ctx, key, etagB, []byte(`{"token":"b"}`)); taken || err != nil {
+		t.Fatalf("second takeover = %v, %v; want it to lose", taken, err)
+	}
+	if held, _, _ := a.readLock(ctx, key); held == nil || held.Token != "a" {
+		t.Errorf("lock holder = %+v, want a", held)
+	}
+}
+
+func TestObjectTokenStore_LockRefreshTakesOverExpiredLock(t *testing.T) {
+	s := openObjectStores(t)(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	key := s.prefixedKey(objectStoreLockPrefix + "/claude-me.json")
+	expired, _ := json.Marshal(objectLock{Token: "crashed", Expires: time.Now().Add(-time.Minute)})
+	if _, err := s.client.PutObject(ctx, s.cfg.Bucket, key, bytes.NewReader(expired), int64(len(expired)), minio.PutObjectOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	unlock, err := s.LockRefresh(ctx, "claude-me.json")
+	if err != nil {
+		t.Fatalf("LockRefresh: %v", err)
+	}
+	if held, _, _ := s.readLock(ctx, key); held == nil || held.Token == "crashed" {
+		t.Errorf("lock after takeover = %+v", held)
+	}
+	unlock()
+	if held, _, _ := s.readLock(ctx, key); held != nil {
+		t.Errorf("lock left after release: %+v", held)
+	}
+}
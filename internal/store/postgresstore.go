@@ -16,6 +16,7 @@ import (
 
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/persist"
 	"github.com/nghyane/llm-mux/internal/provider"
 	log "github.com/nghyane/llm-mux/internal/logging"
 )
@@ -230,13 +231,8 @@ func (s *PostgresStore) Save(ctx context.Context, auth *provider.Auth) (string,
 		} else if !errors.Is(errRead, fs.ErrNotExist) {
 			return "", fmt.Errorf("postgres store: read existing metadata: %w", errRead)
 		}
-		tmp := path + ".tmp"
-		if errWrite := os.WriteFile(tmp, raw, 0o600); errWrite != nil {
-			return "", fmt.Errorf("postgres store: write temp auth file: %w", errWrite)
-		}
-		if errRename := os.Rename(tmp, path); errRename != nil {
-			_ = os.Remove(tmp) // cleanup temp file on rename failure
-			return "", fmt.Errorf("postgres store: rename auth file: %w", errRename)
+		if errWrite := persist.WriteFile(path, raw); errWrite != nil {
+			return "", fmt.Errorf("postgres store: write auth file: %w", errWrite)
 		}
 	default:
 		return "", fmt.Errorf("postgres store: nothing to persist for %s", auth.ID)
@@ -281,37 +277,11 @@ func (s *PostgresStore) List(ctx context.Context) ([]*provider.Auth, error) {
 		if err = rows.Scan(&id, &payload, &createdAt, &updatedAt); err != nil {
 			return nil, fmt.Errorf("postgres store: scan auth row: %w", err)
 		}
-		path, errPath := s.absoluteAuthPath(id)
-		if errPath != nil {
-			log.WithError(errPath).Warnf("postgres store: skipping auth %s outside spool", id)
-			continue
-		}
-		metadata := make(map[string]any)
-		if err = json.Unmarshal([]byte(payload), &metadata); err != nil {
-			log.WithError(err).Warnf("postgres store: skipping auth %s with invalid json", id)
+		auth, errRecord := s.authFromRecord(id, payload, createdAt, updatedAt)
+		if errRecord != nil {
+			log.WithError(errRecord).Warnf("postgres store: skipping auth %s", id)
 			continue
 		}
-		providerName := strings.TrimSpace(valueAsString(metadata["type"]))
-		if providerName == "" {
-			providerName = "unknown"
-		}
-		attr := map[string]string{"path": path}
-		if email := strings.TrimSpace(valueAsString(metadata["email"])); email != "" {
-			attr["email"] = email
-		}
-		auth := &provider.Auth{
-			ID:               normalizeAuthID(id),
-			Provider:         providerName,
-			FileName:         normalizeAuthID(id),
-			Label:            labelFor(metadata),
-			Status:           provider.StatusActive,
-			Attributes:       attr,
-			Metadata:         metadata,
-			CreatedAt:        createdAt,
-			UpdatedAt:        updatedAt,
-			LastRefreshedAt:  time.Time{},
-			NextRefreshAfter: time.Time{},
-		}
 		auths = append(auths, auth)
 	}
 	if err = rows.Err(); err != nil {
@@ -320,6 +290,65 @@ func (s *PostgresStore) List(ctx context.Context) ([]*provider.Auth, error) {
 	return auths, nil
 }
 
+// Load returns the record of id as last saved by any instance.
+func (s *PostgresStore) Load(ctx context.Context, id string) (*provider.Auth, error) {
+	path, err := s.resolveDeletePath(strings.TrimSpace(id))
+	if err != nil {
+		return nil, err
+	}
+	relID, err := s.relativeAuthID(path)
+	if err != nil {
+		return nil, err
+	}
+	query := fmt.Sprintf("SELECT content, created_at, updated_at FROM %s WHERE id = $1", s.fullTableName(s.cfg.AuthTable))
+	var (
+		payload   string
+		createdAt time.Time
+		updatedAt time.Time
+	)
+	err = s.db.QueryRowContext(ctx, query, relID).Scan(&payload, &createdAt, &updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("postgres store: load auth %s: %w", relID, err)
+	}
+	return s.authFromRecord(relID, payload, createdAt, updatedAt)
+}
+
+// authFromRecord builds the auth of a stored record.
+func (s *PostgresStore) authFromRecord(id, payload string, createdAt, updatedAt time.Time) (*provider.Auth, error) {
+	path, err := s.absoluteAuthPath(id)
+	if err != nil {
+		return nil, fmt.Errorf("outside spool: %w", err)
+	}
+	metadata := make(map[string]any)
+	if err = json.Unmarshal([]byte(payload), &metadata); err != nil {
+		return nil, fmt.Errorf("invalid json: %w", err)
+	}
+	providerName := strings.TrimSpace(valueAsString(metadata["type"]))
+	if providerName == "" {
+		providerName = "unknown"
+	}
+	attr := map[string]string{"path": path}
+	if email := strings.TrimSpace(valueAsString(metadata["email"])); email != "" {
+		attr["email"] = email
+	}
+	return &provider.Auth{
+		ID:               normalizeAuthID(id),
+		Provider:         providerName,
+		FileName:         normalizeAuthID(id),
+		Label:            labelFor(metadata),
+		Status:           provider.StatusActive,
+		Attributes:       attr,
+		Metadata:         metadata,
+		CreatedAt:        createdAt,
+		UpdatedAt:        updatedAt,
+		LastRefreshedAt:  time.Time{},
+		NextRefreshAfter: time.Time{},
+	}, nil
+}
+
 // Delete removes an auth file and the corresponding database record.
 func (s *PostgresStore) Delete(ctx context.Context, id string) error {
 	id = strings.TrimSpace(id)
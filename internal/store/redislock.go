@@ -0,0 +1,114 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// redisRefreshLockTTL bounds how long a crashed instance blocks refreshes
+	// of the account it was refreshing.
+	redisRefreshLockTTL = time.Minute
+	redisLockRetry      = 100 * time.Millisecond
+)
+
+// redisUnlockScript deletes a lock only while it still holds the caller's
+// token, so an expired lock taken over by another instance is left alone.
+var redisUnlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// RedisLockConfig captures configuration for the Redis refresh lock.
+type RedisLockConfig struct {
+	// URL is a redis:// or rediss:// URL, including credentials and database.
+	URL string
+	// Prefix namespaces the keys, so several deployments can share a server.
+	Prefix string
+}
+
+// RedisRefreshLocker holds refresh locks in Redis. It lets instances sharing
+// a store that cannot lock by itself, such as PostgreSQL, refresh each account
+// one at a time.
+type RedisRefreshLocker struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisRefreshLocker connects to Redis.
+func NewRedisRefreshLocker(ctx context.Context, cfg RedisLockConfig) (*RedisRefreshLocker, error) {
+	cfg.URL = strings.TrimSpace(cfg.URL)
+	cfg.Prefix = strings.Trim(strings.TrimSpace(cfg.Prefix), ":")
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("redis lock: URL is required")
+	}
+	if cfg.Prefix == "" {
+		cfg.Prefix = defaultRedisPrefix
+	}
+	options, err := redis.ParseURL(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("redis lock: parse URL: %w", err)
+	}
+	client := redis.NewClient(options)
+	if err = client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("redis lock: ping: %w", err)
+	}
+	return &RedisRefreshLocker{client: client, prefix: cfg.Prefix}, nil
+}
+
+// Close releases the Redis connection pool.
+func (l *RedisRefreshLocker) Close() error {
+	if l == nil || l.client == nil {
+		return nil
+	}
+	return l.client.Close()
+}
+
+// LockRefresh takes the refresh lock of id. The lock expires on its own if
+// its holder stops without releasing it.
+func (l *RedisRefreshLocker) LockRefresh(ctx context.Context, id string) (func(), error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return nil, fmt.Errorf("redis lock: id is empty")
+	}
+	unlock, err := lockRedis(ctx, l.client, l.prefix+":lock:"+id)
+	if err != nil {
+		return nil, fmt.Errorf("redis lock: lock %s: %w", id, err)
+	}
+	return unlock, nil
+}
+
+// lockRedis waits until key is set to a token of its own and returns the
+// function deleting it again.
+func lockRedis(ctx context.Context, client *redis.Client, key string) (func(), error) {
+	token := uuid.NewString()
+	for {
+		ok, err := client.SetNX(ctx, key, token, redisRefreshLockTTL).Result()
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(redisLockRetry):
+		}
+	}
+	return func() {
+		releaseCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := redisUnlockScript.Run(releaseCtx, client, []string{key}, token).Err(); err != nil {
+			log.WithError(err).Warnf("redis lock: release %s", key)
+		}
+	}, nil
+}
@@ -0,0 +1,24 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/store/storetest"
+)
+
+func TestRedisRefreshLockerConformance(t *testing.T) {
+	storetest.RunRefreshLocker(t, func(t *testing.T) func(t *testing.T) provider.RefreshLocker {
+		server := miniredis.RunT(t)
+		return func(t *testing.T) provider.RefreshLocker {
+			l, err := NewRedisRefreshLocker(context.Background(), RedisLockConfig{URL: "redis://" + server.Addr()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { _ = l.Close() })
+			return l
+		}
+	})
+}
@@ -0,0 +1,473 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/nghyane/llm-mux/internal/config"
+	"github.com/nghyane/llm-mux/internal/json"
+	log "github.com/nghyane/llm-mux/internal/logging"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/redis/go-redis/v9"
+)
+
+const defaultRedisPrefix = "llm-mux"
+
+// RedisStoreConfig captures configuration for the Redis-backed token store.
+type RedisStoreConfig struct {
+	// URL is a redis:// or rediss:// URL, including credentials and database.
+	URL string
+	// Prefix namespaces the keys, so several deployments can share a server.
+	Prefix    string
+	LocalRoot string
+}
+
+// RedisTokenStore persists configuration and authentication metadata in Redis
+// so several instances share the same accounts. Auth records live in one hash
+// keyed by file name; files are mirrored to a local workspace so existing
+// file-based flows continue to operate.
+type RedisTokenStore struct {
+	client     *redis.Client
+	cfg        RedisStoreConfig
+	spoolRoot  string
+	configPath string
+	authDir    string
+	mu         sync.Mutex
+}
+
+// NewRedisTokenStore connects to Redis and prepares the local workspace.
+func NewRedisTokenStore(ctx context.Context, cfg RedisStoreConfig) (*RedisTokenStore, error) {
+	cfg.URL = strings.TrimSpace(cfg.URL)
+	cfg.Prefix = strings.Trim(strings.TrimSpace(cfg.Prefix), ":")
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("redis store: URL is required")
+	}
+	if cfg.Prefix == "" {
+		cfg.Prefix = defaultRedisPrefix
+	}
+	options, err := redis.ParseURL(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("redis store: parse URL: %w", err)
+	}
+
+	root := strings.TrimSpace(cfg.LocalRoot)
+	if root == "" {
+		if cwd, errWd := os.Getwd(); errWd == nil {
+			root = filepath.Join(cwd, "redisstore")
+		} else {
+			root = filepath.Join(os.TempDir(), "redisstore")
+		}
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("redis store: resolve spool directory: %w", err)
+	}
+	configDir := filepath.Join(absRoot, "config")
+	authDir := filepath.Join(absRoot, "auths")
+	if err = os.MkdirAll(configDir, 0o700); err != nil {
+		return nil, fmt.Errorf("redis store: create config directory: %w", err)
+	}
+	if err = os.MkdirAll(authDir, 0o700); err != nil {
+		return nil, fmt.Errorf("redis store: create auth directory: %w", err)
+	}
+
+	client := redis.NewClient(options)
+	if err = client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("redis store: ping: %w", err)
+	}
+	return &RedisTokenStore{
+		client:     client,
+		cfg:        cfg,
+		spoolRoot:  absRoot,
+		configPath: filepath.Join(configDir, "config.yaml"),
+		authDir:    authDir,
+	}, nil
+}
+
+// Close releases the Redis connection pool.
+func (s *RedisTokenStore) Close() error {
+	if s == nil || s.client == nil {
+		return nil
+	}
+	return s.client.Close()
+}
+
+// SetBaseDir implements the optional interface used by authenticators; it is a no-op because
+// the Redis-backed store controls its own workspace.
+func (s *RedisTokenStore) SetBaseDir(string) {}
+
+// ConfigPath returns the managed configuration file path inside the spool directory.
+func (s *RedisTokenStore) ConfigPath() string {
+	if s == nil {
+		return ""
+	}
+	return s.configPath
+}
+
+// AuthDir returns the local directory containing mirrored auth files.
+func (s *RedisTokenStore) AuthDir() string {
+	if s == nil {
+		return ""
+	}
+	return s.authDir
+}
+
+// WorkDir exposes the root spool directory used for mirroring.
+func (s *RedisTokenStore) WorkDir() string {
+	if s == nil {
+		return ""
+	}
+	return s.spoolRoot
+}
+
+// Bootstrap synchronizes configuration and auth records from Redis into the local workspace.
+func (s *RedisTokenStore) Bootstrap(ctx context.Context) error {
+	if s == nil {
+		return fmt.Errorf("redis store: not initialized")
+	}
+	if err := s.syncConfigFromRedis(ctx); err != nil {
+		return err
+	}
+	return s.syncAuthFromRedis(ctx)
+}
+
+// Save writes authentication metadata to the local mirror and to Redis. Both
+// writes replace the previous record whole, so readers never see a partial one.
+func (s *RedisTokenStore) Save(ctx context.Context, auth *provider.Auth) (string, error) {
+	if auth == nil {
+		return "", fmt.Errorf("redis store: auth is nil")
+	}
+	path, err := s.resolveAuthPath(auth)
+	if err != nil {
+		return "", err
+	}
+	if auth.Disabled {
+		if _, statErr := os.Stat(path); errors.Is(statErr, fs.ErrNotExist) {
+			return "", nil
+		}
+	}
+	relID, err := s.relativeAuthID(path)
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err = os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return "", fmt.Errorf("redis store: create auth directory: %w", err)
+	}
+	tmp := path + ".tmp"
+	switch {
+	case auth.Storage != nil:
+		if err = auth.Storage.SaveTokenToFile(tmp); err != nil {
+			_ = os.Remove(tmp)
+			return "", err
+		}
+	case auth.Metadata != nil:
+		raw, errMarshal := json.Marshal(auth.Metadata)
+		if errMarshal != nil {
+			return "", fmt.Errorf("redis store: marshal metadata: %w", errMarshal)
+		}
+		if errWrite := os.WriteFile(tmp, raw, 0o600); errWrite != nil {
+			return "", fmt.Errorf("redis store: write temp auth file: %w", errWrite)
+		}
+	default:
+		return "", fmt.Errorf("redis store: nothing to persist for %s", auth.ID)
+	}
+	data, err := os.ReadFile(tmp)
+	if err != nil {
+		_ = os.Remove(tmp)
+		return "", fmt.Errorf("redis store: read temp auth file: %w", err)
+	}
+	if err = s.client.HSet(ctx, s.authsKey(), relID, data).Err(); err != nil {
+		_ = os.Remove(tmp)
+		return "", fmt.Errorf("redis store: save auth %s: %w", relID, err)
+	}
+	if err = os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return "", fmt.Errorf("redis store: rename auth file: %w", err)
+	}
+
+	if auth.Attributes == nil {
+		auth.Attributes = make(map[string]string)
+	}
+	auth.Attributes["path"] = path
+	if strings.TrimSpace(auth.FileName) == "" {
+		auth.FileName = auth.ID
+	}
+	return path, nil
+}
+
+// List enumerates all auth records stored in Redis.
+func (s *RedisTokenStore) List(ctx context.Context) ([]*provider.Auth, error) {
+	records, err := s.client.HGetAll(ctx, s.authsKey()).Result()
+	if err != nil {
+		return nil, fmt.Errorf("redis store: list auth: %w", err)
+	}
+	auths := make([]*provider.Auth, 0, len(records))
+	for id, payload := range records {
+		auth, errRecord := s.authFromRecord(id, []byte(payload))
+		if errRecord != nil {
+			log.WithError(errRecord).Warnf("redis store: skipping auth %s", id)
+			continue
+		}
+		auths = append(auths, auth)
+	}
+	return auths, nil
+}
+
+// Load returns the record of id as last saved by any instance.
+func (s *RedisTokenStore) Load(ctx context.Context, id string) (*provider.Auth, error) {
+	relID, err := s.relativeAuthID(s.resolveDeletePath(id))
+	if err != nil {
+		return nil, err
+	}
+	payload, err := s.client.HGet(ctx, s.authsKey(), relID).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("redis store: load auth %s: %w", relID, err)
+	}
+	return s.authFromRecord(relID, payload)
+}
+
+// Delete removes an auth file locally and from Redis.
+func (s *RedisTokenStore) Delete(ctx context.Context, id string) error {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return fmt.Errorf("redis store: id is empty")
+	}
+	path := s.resolveDeletePath(id)
+	relID, err := s.relativeAuthID(path)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err = os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("redis store: delete auth file: %w", err)
+	}
+	if err = s.client.HDel(ctx, s.authsKey(), relID).Err(); err != nil {
+		return fmt.Errorf("redis store: delete auth %s: %w", relID, err)
+	}
+	return nil
+}
+
+// LockRefresh takes the refresh lock of id, shared by all instances. The lock
+// expires on its own if its holder stops without releasing it.
+func (s *RedisTokenStore) LockRefresh(ctx context.Context, id string) (func(), error) {
+	relID, err := s.relativeAuthID(s.resolveDeletePath(id))
+	if err != nil {
+		return nil, err
+	}
+	unlock, err := lockRedis(ctx, s.client, s.key("lock", relID))
+	if err != nil {
+		return nil, fmt.Errorf("redis store: lock %s: %w", relID, err)
+	}
+	return unlock, nil
+}
+
+// PersistAuthFiles stores the provided auth file changes in Redis.
+func (s *RedisTokenStore) PersistAuthFiles(ctx context.Context, _ string, paths ...string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, p := range paths {
+		trimmed := strings.TrimSpace(p)
+		if trimmed == "" {
+			continue
+		}
+		relID, err := s.relativeAuthID(trimmed)
+		if err != nil {
+			log.WithError(err).Warnf("redis store: ignoring auth path %s", trimmed)
+			continue
+		}
+		path := filepath.Join(s.authDir, filepath.FromSlash(relID))
+		data, err := os.ReadFile(path)
+		switch {
+		case errors.Is(err, fs.ErrNotExist) || (err == nil && len(data) == 0):
+			err = s.client.HDel(ctx, s.authsKey(), relID).Err()
+		case err != nil:
+			return fmt.Errorf("redis store: read auth file: %w", err)
+		default:
+			err = s.client.HSet(ctx, s.authsKey(), relID, data).Err()
+		}
+		if err != nil {
+			return fmt.Errorf("redis store: persist auth %s: %w", relID, err)
+		}
+	}
+	return nil
+}
+
+// PersistConfig mirrors the local configuration file to Redis.
+func (s *RedisTokenStore) PersistConfig(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.configPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return s.client.Del(ctx, s.key("config")).Err()
+		}
+		return fmt.Errorf("redis store: read config file: %w", err)
+	}
+	if err = s.client.Set(ctx, s.key("config"), normalizeLineEndings(string(data)), 0).Err(); err != nil {
+		return fmt.Errorf("redis store: save config: %w", err)
+	}
+	return nil
+}
+
+// syncConfigFromRedis writes the stored config to disk or seeds Redis from the embedded template.
+func (s *RedisTokenStore) syncConfigFromRedis(ctx context.Context) error {
+	content, err := s.client.Get(ctx, s.key("config")).Result()
+	switch {
+	case errors.Is(err, redis.Nil):
+		if _, errStat := os.Stat(s.configPath); errors.Is(errStat, fs.ErrNotExist) {
+			if errWrite := os.WriteFile(s.configPath, config.GenerateDefaultConfigYAML(), 0o600); errWrite != nil {
+				return fmt.Errorf("redis store: write config from template: %w", errWrite)
+			}
+		}
+		data, errRead := os.ReadFile(s.configPath)
+		if errRead != nil {
+			return fmt.Errorf("redis store: read local config: %w", errRead)
+		}
+		if errSet := s.client.Set(ctx, s.key("config"), normalizeLineEndings(string(data)), 0).Err(); errSet != nil {
+			return fmt.Errorf("redis store: seed config: %w", errSet)
+		}
+	case err != nil:
+		return fmt.Errorf("redis store: load config: %w", err)
+	default:
+		if err = os.WriteFile(s.configPath, []byte(normalizeLineEndings(content)), 0o600); err != nil {
+			return fmt.Errorf("redis store: write config to spool: %w", err)
+		}
+	}
+	return nil
+}
+
+// syncAuthFromRedis populates the local auth directory from Redis.
+func (s *RedisTokenStore) syncAuthFromRedis(ctx context.Context) error {
+	records, err := s.client.HGetAll(ctx, s.authsKey()).Result()
+	if err != nil {
+		return fmt.Errorf("redis store: load auth: %w", err)
+	}
+	if err = os.RemoveAll(s.authDir); err != nil {
+		return fmt.Errorf("redis store: reset auth directory: %w", err)
+	}
+	if err = os.MkdirAll(s.authDir, 0o700); err != nil {
+		return fmt.Errorf("redis store: recreate auth directory: %w", err)
+	}
+	for id, payload := range records {
+		path, errPath := s.absoluteAuthPath(id)
+		if errPath != nil {
+			log.WithError(errPath).Warnf("redis store: skipping auth %s outside spool", id)
+			continue
+		}
+		if err = os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			return fmt.Errorf("redis store: create auth subdir: %w", err)
+		}
+		if err = os.WriteFile(path, []byte(payload), 0o600); err != nil {
+			return fmt.Errorf("redis store: write auth file: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *RedisTokenStore) authFromRecord(id string, payload []byte) (*provider.Auth, error) {
+	path, err := s.absoluteAuthPath(id)
+	if err != nil {
+		return nil, err
+	}
+	metadata := make(map[string]any)
+	if err = json.Unmarshal(payload, &metadata); err != nil {
+		return nil, fmt.Errorf("unmarshal auth json: %w", err)
+	}
+	providerName := strings.TrimSpace(valueAsString(metadata["type"]))
+	if providerName == "" {
+		providerName = "unknown"
+	}
+	attr := map[string]string{"path": path}
+	if email := strings.TrimSpace(valueAsString(metadata["email"])); email != "" {
+		attr["email"] = email
+	}
+	return &provider.Auth{
+		ID:         normalizeAuthID(id),
+		Provider:   providerName,
+		FileName:   normalizeAuthID(id),
+		Label:      labelFor(metadata),
+		Status:     provider.StatusActive,
+		Attributes: attr,
+		Metadata:   metadata,
+	}, nil
+}
+
+func (s *RedisTokenStore) key(parts ...string) string {
+	return s.cfg.Prefix + ":" + strings.Join(parts, ":")
+}
+
+func (s *RedisTokenStore) authsKey() string {
+	return s.key("auths")
+}
+
+func (s *RedisTokenStore) resolveAuthPath(auth *provider.Auth) (string, error) {
+	if auth.Attributes != nil {
+		if p := strings.TrimSpace(auth.Attributes["path"]); p != "" {
+			return p, nil
+		}
+	}
+	if fileName := strings.TrimSpace(auth.FileName); fileName != "" {
+		if filepath.IsAbs(fileName) {
+			return fileName, nil
+		}
+		return filepath.Join(s.authDir, fileName), nil
+	}
+	if auth.ID == "" {
+		return "", fmt.Errorf("redis store: missing id")
+	}
+	if filepath.IsAbs(auth.ID) {
+		return auth.ID, nil
+	}
+	return filepath.Join(s.authDir, filepath.FromSlash(auth.ID)), nil
+}
+
+func (s *RedisTokenStore) resolveDeletePath(id string) string {
+	if strings.ContainsRune(id, os.PathSeparator) || filepath.IsAbs(id) {
+		return id
+	}
+	return filepath.Join(s.authDir, filepath.FromSlash(id))
+}
+
+func (s *RedisTokenStore) relativeAuthID(path string) (string, error) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(s.authDir, path)
+	}
+	rel, err := filepath.Rel(s.authDir, filepath.Clean(path))
+	if err != nil {
+		return "", fmt.Errorf("redis store: compute relative path: %w", err)
+	}
+	if strings.HasPrefix(rel, "..") {
+		return "", fmt.Errorf("redis store: path %s outside managed directory", path)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+func (s *RedisTokenStore) absoluteAuthPath(id string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(id))
+	if strings.HasPrefix(clean, "..") || filepath.IsAbs(clean) {
+		return "", fmt.Errorf("redis store: invalid auth identifier %s", id)
+	}
+	return filepath.Join(s.authDir, clean), nil
+}
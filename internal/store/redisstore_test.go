@@ -0,0 +1,62 @@
+package store
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/store/storetest"
+)
+
+func newRedisBackend(t *testing.T) storetest.Opener {
+	server := miniredis.RunT(t)
+	return func(t *testing.T) provider.Store {
+		s, err := NewRedisTokenStore(context.Background(), RedisStoreConfig{
+			URL:       "redis://" + server.Addr(),
+			LocalRoot: t.TempDir(),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = s.Close() })
+		return s
+	}
+}
+
+func TestRedisTokenStoreConformance(t *testing.T) {
+	storetest.Run(t, newRedisBackend)
+}
+
+func TestRedisTokenStoreBootstrap(t *testing.T) {
+	open := newRedisBackend(t)
+	ctx := context.Background()
+	first := open(t).(*RedisTokenStore)
+	if err := first.Bootstrap(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(first.ConfigPath(), []byte("port: 9999\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := first.PersistConfig(ctx); err != nil {
+		t.Fatal(err)
+	}
+	auth := &provider.Auth{ID: "team/qwen.json", FileName: "team/qwen.json", Metadata: map[string]any{"type": "qwen"}}
+	if _, err := first.Save(ctx, auth); err != nil {
+		t.Fatal(err)
+	}
+
+	// A second instance mirrors what the first stored.
+	second := open(t).(*RedisTokenStore)
+	if err := second.Bootstrap(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(second.ConfigPath()); err != nil || string(data) != "port: 9999\n" {
+		t.Fatalf("mirrored config = %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(second.AuthDir(), "team", "qwen.json")); err != nil {
+		t.Fatalf("auth not mirrored: %v", err)
+	}
+}
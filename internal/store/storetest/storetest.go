@@ -0,0 +1,220 @@
+// Package storetest checks that token store backends behave alike. Each
+// backend's tests call Run with a way to open stores on a fresh backend.
+package storetest
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/provider"
+)
+
+// Opener opens a store on the backend under test. Stores from the same
+// Opener share the backend, as separate instances of the server would.
+type Opener func(t *testing.T) provider.Store
+
+// Run checks the operations every store supports and, for stores that
+// implement provider.SharedStore, loading another instance's records and the
+// refresh lock. newBackend is called once per check and must return an
+// Opener for an empty backend.
+func Run(t *testing.T, newBackend func(t *testing.T) Opener) {
+	t.Run("SaveAndList", func(t *testing.T) { testSaveAndList(t, newBackend(t)) })
+	t.Run("SaveReplaces", func(t *testing.T) { testSaveReplaces(t, newBackend(t)) })
+	t.Run("Delete", func(t *testing.T) { testDelete(t, newBackend(t)) })
+	t.Run("LoadSharedRecord", func(t *testing.T) { testLoadSharedRecord(t, newBackend(t)) })
+	t.Run("RefreshLock", func(t *testing.T) { testRefreshLock(t, newBackend(t)) })
+}
+
+const authID = "claude-user@example.com.json"
+
+func newAuth(accessToken string) *provider.Auth {
+	return &provider.Auth{
+		ID:       authID,
+		Provider: "claude",
+		FileName: authID,
+		Metadata: map[string]any{
+			"type":          "claude",
+			"email":         "user@example.com",
+			"access_token":  accessToken,
+			"refresh_token": "rt-" + accessToken,
+		},
+	}
+}
+
+func find(t *testing.T, s provider.Store, id string) (*provider.Auth, int) {
+	t.Helper()
+	auths, err := s.List(context.Background())
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	var found *provider.Auth
+	count := 0
+	for _, a := range auths {
+		if a != nil && a.ID == id {
+			found = a
+			count++
+		}
+	}
+	return found, count
+}
+
+func testSaveAndList(t *testing.T, open Opener) {
+	s := open(t)
+	path, err := s.Save(context.Background(), newAuth("at-1"))
+	if err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if path == "" {
+		t.Fatal("Save returned no path")
+	}
+	got, _ := find(t, s, authID)
+	if got == nil {
+		t.Fatalf("List does not return saved auth %s", authID)
+	}
+	if got.Provider != "claude" || got.Metadata["access_token"] != "at-1" || got.Metadata["email"] != "user@example.com" {
+		t.Errorf("listed auth = provider %q metadata %v", got.Provider, got.Metadata)
+	}
+	if got.Attributes["path"] == "" {
+		t.Error("listed auth has no path attribute")
+	}
+}
+
+func testSaveReplaces(t *testing.T, open Opener) {
+	s := open(t)
+	ctx := context.Background()
+	for _, token := range []string{"at-1", "at-2"} {
+		if _, err := s.Save(ctx, newAuth(token)); err != nil {
+			t.Fatalf("Save %s: %v", token, err)
+		}
+	}
+	got, count := find(t, s, authID)
+	if count != 1 || got.Metadata["access_token"] != "at-2" {
+		t.Fatalf("after two saves List has %d records, latest %v; want one with at-2", count, got)
+	}
+}
+
+func testDelete(t *testing.T, open Opener) {
+	s := open(t)
+	ctx := context.Background()
+	if _, err := s.Save(ctx, newAuth("at-1")); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if err := s.Delete(ctx, authID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if got, _ := find(t, s, authID); got != nil {
+		t.Fatal("List still returns deleted auth")
+	}
+	if err := s.Delete(ctx, authID); err != nil {
+		t.Errorf("deleting a missing auth: %v", err)
+	}
+}
+
+func shared(t *testing.T, open Opener) (provider.SharedStore, provider.SharedStore) {
+	t.Helper()
+	a, ok := open(t).(provider.SharedStore)
+	if !ok {
+		t.Skip("store is not shared between instances")
+	}
+	return a, open(t).(provider.SharedStore)
+}
+
+func testLoadSharedRecord(t *testing.T, open Opener) {
+	a, b := shared(t, open)
+	ctx := context.Background()
+	if got, err := b.Load(ctx, authID); err != nil || got != nil {
+		t.Fatalf("Load before save = %v, %v; want nil, nil", got, err)
+	}
+	for _, token := range []string{"at-1", "at-2"} {
+		if _, err := a.Save(ctx, newAuth(token)); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+		got, err := b.Load(ctx, authID)
+		if err != nil || got == nil {
+			t.Fatalf("other instance Load = %v, %v", got, err)
+		}
+		if got.ID != authID || got.Metadata["access_token"] != token || got.Metadata["refresh_token"] != "rt-"+token {
+			t.Fatalf("other instance loaded %s with %v, want %s", got.ID, got.Metadata, token)
+		}
+	}
+	if err := a.Delete(ctx, authID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if got, err := b.Load(ctx, authID); err != nil || got != nil {
+		t.Fatalf("Load after delete = %v, %v; want nil, nil", got, err)
+	}
+}
+
+func testRefreshLock(t *testing.T, open Opener) {
+	a, b := shared(t, open)
+	checkRefreshLock(t, a, b)
+}
+
+// RunRefreshLocker checks a refresh lock on its own, for locks kept apart
+// from the token store. Lockers from the same call of newBackend share the
+// backend.
+func RunRefreshLocker(t *testing.T, newBackend func(t *testing.T) func(t *testing.T) provider.RefreshLocker) {
+	open := newBackend(t)
+	checkRefreshLock(t, open(t), open(t))
+}
+
+// checkRefreshLock checks that a and b, two instances on the same backend,
+// never hold the lock of one account at the same time.
+func checkRefreshLock(t *testing.T, a, b provider.RefreshLocker) {
+	ctx := context.Background()
+
+	unlock, err := a.LockRefresh(ctx, authID)
+	if err != nil {
+		t.Fatalf("LockRefresh: %v", err)
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	if _, err = b.LockRefresh(waitCtx, authID); err == nil {
+		t.Fatal("second instance acquired a held lock")
+	}
+	cancel()
+	other, err := b.LockRefresh(ctx, "other-account.json")
+	if err != nil {
+		t.Fatalf("lock of another account: %v", err)
+	}
+	other()
+	unlock()
+	waitCtx, cancel = context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	unlock, err = b.LockRefresh(waitCtx, authID)
+	if err != nil {
+		t.Fatalf("LockRefresh after release: %v", err)
+	}
+	unlock()
+
+	// Instances contending for the lock hold it one at a time.
+	var holders, overlaps atomic.Int32
+	var wg sync.WaitGroup
+	for i := range 6 {
+		s := a
+		if i%2 == 1 {
+			s = b
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock, err := s.LockRefresh(waitCtx, authID)
+			if err != nil {
+				t.Errorf("LockRefresh: %v", err)
+				return
+			}
+			if holders.Add(1) > 1 {
+				overlaps.Add(1)
+			}
+			time.Sleep(20 * time.Millisecond)
+			holders.Add(-1)
+			unlock()
+		}()
+	}
+	wg.Wait()
+	if n := overlaps.Load(); n > 0 {
+		t.Fatalf("lock was held by two callers at once %d times", n)
+	}
+}
@@ -44,7 +44,7 @@ func (claudeConverter) ConvertRequest(req *ir.UnifiedChatRequest) ([]byte, error
 }
 
 func (claudeConverter) ToResponse(messages []ir.Message, usage *ir.Usage, model string) ([]byte, error) {
-	return ToClaudeResponse(messages, usage, model, "")
+	return ToClaudeResponse(messages, usage, model, "msg-"+model)
 }
 
 func (claudeConverter) ToChunk(event ir.UnifiedEvent, model string) ([]byte, error) {
@@ -60,7 +60,7 @@ func (openaiConverter) ConvertRequest(req *ir.UnifiedChatRequest) ([]byte, error
 }
 
 func (openaiConverter) ToResponse(messages []ir.Message, usage *ir.Usage, model string) ([]byte, error) {
-	return ToOpenAIChatCompletion(messages, usage, model, "")
+	return ToOpenAIChatCompletion(messages, usage, model, "chatcmpl-"+model)
 }
 
 func (openaiConverter) ToChunk(event ir.UnifiedEvent, model string) ([]byte, error) {
@@ -92,7 +92,7 @@ func (kiroConverter) ConvertRequest(req *ir.UnifiedChatRequest) ([]byte, error)
 }
 
 func (kiroConverter) ToResponse(messages []ir.Message, usage *ir.Usage, model string) ([]byte, error) {
-	return ToOpenAIChatCompletion(messages, usage, model, "")
+	return ToOpenAIChatCompletion(messages, usage, model, "chatcmpl-"+model)
 }
 
 func (kiroConverter) ToChunk(event ir.UnifiedEvent, model string) ([]byte, error) {
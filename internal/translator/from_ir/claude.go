@@ -11,6 +11,42 @@ import (
 
 type ClaudeProvider struct{}
 
+// claudeMaxCacheBreakpoints is the number of cache_control blocks Anthropic
+// accepts in one request.
+const claudeMaxCacheBreakpoints = 4
+
+// buildClaudeCacheControl renders cc as an Anthropic cache_control object.
+// The TTL is kept in seconds in the IR and sent as a duration string.
+func buildClaudeCacheControl(cc *ir.CacheControl) map[string]any {
+	typ := cc.Type
+	if typ == "" {
+		typ = ir.CacheControlEphemeral
+	}
+	out := map[string]any{"type": typ}
+	if cc.TTL != nil && *cc.TTL > 0 {
+		switch ttl := *cc.TTL; {
+		case ttl%3600 == 0:
+			out["ttl"] = fmt.Sprintf("%dh", ttl/3600)
+		case ttl%60 == 0:
+			out["ttl"] = fmt.Sprintf("%dm", ttl/60)
+		default:
+			out["ttl"] = fmt.Sprintf("%ds", ttl)
+		}
+	}
+	return out
+}
+
+// markLastClaudeBlock attaches cc to the final content block, which is where
+// Anthropic expects a message's cache breakpoint.
+func markLastClaudeBlock(parts []any, cc map[string]any) {
+	if cc == nil || len(parts) == 0 {
+		return
+	}
+	if block, ok := parts[len(parts)-1].(map[string]any); ok {
+		block["cache_control"] = cc
+	}
+}
+
 type ClaudeStreamState struct {
 	MessageID        string
 	Model            string
@@ -22,6 +58,12 @@ type ClaudeStreamState struct {
 	HasTextContent   bool
 	FinishSent       bool
 	ParserState      *ir.ClaudeStreamParserState
+
+	// A tool_use block stays open while an upstream streams its arguments
+	// across events; it is closed by the next block or the finish.
+	ToolBlockOpen    bool
+	ToolBlockIndex   int
+	ToolBlockHasArgs bool
 }
 
 func NewClaudeStreamState() *ClaudeStreamState {
@@ -29,6 +71,9 @@ func NewClaudeStreamState() *ClaudeStreamState {
 }
 
 func (p *ClaudeProvider) ConvertRequest(req *ir.UnifiedChatRequest) ([]byte, error) {
+	if err := ir.DropUnsupportedLogprobs(req, "claude"); err != nil {
+		return nil, err
+	}
 	userID := "llm-mux-user"
 	if v, ok := req.Metadata[ir.MetaOpenAIUser].(string); ok && v != "" {
 		userID = v
@@ -65,41 +110,54 @@ func (p *ClaudeProvider) ConvertRequest(req *ir.UnifiedChatRequest) ([]byte, err
 		}
 	}
 
+	// Anthropic accepts a limited number of cache breakpoints; keep the latest
+	// ones since each caches the whole prefix before it.
+	messages := ir.NormalizeSystemMessages(req.Messages)
+	skipBreakpoints := 0
+	for _, m := range messages {
+		if m.CacheControl != nil {
+			skipBreakpoints++
+		}
+	}
+	skipBreakpoints -= claudeMaxCacheBreakpoints
+	cacheControlFor := func(m ir.Message) map[string]any {
+		if m.CacheControl == nil {
+			return nil
+		}
+		if skipBreakpoints > 0 {
+			skipBreakpoints--
+			return nil
+		}
+		return buildClaudeCacheControl(m.CacheControl)
+	}
+
 	var msgs []any
-	for _, m := range req.Messages {
+	for _, m := range messages {
 		switch m.Role {
 		case ir.RoleSystem:
 			if text := ir.CombineTextParts(m); text != "" {
-				root["system"] = text
+				if cc := cacheControlFor(m); cc != nil {
+					root["system"] = []any{map[string]any{"type": ir.ClaudeBlockText, "text": text, "cache_control": cc}}
+				} else {
+					root["system"] = text
+				}
 			}
 		case ir.RoleUser:
 			if ps := ir.BuildClaudeContentParts(m, false, false); len(ps) > 0 {
-				obj := map[string]any{"role": ir.ClaudeRoleUser, "content": ps}
-				if m.CacheControl != nil {
-					cc := map[string]any{"type": m.CacheControl.Type}
-					if m.CacheControl.TTL != nil {
-						cc["ttl"] = *m.CacheControl.TTL
-					}
-					obj["cache_control"] = cc
-				}
-				msgs = append(msgs, obj)
+				markLastClaudeBlock(ps, cacheControlFor(m))
+				msgs = append(msgs, map[string]any{"role": ir.ClaudeRoleUser, "content": ps})
 			}
 		case ir.RoleAssistant:
 			if ps := ir.BuildClaudeContentParts(m, len(m.ToolCalls) > 0, thinkingEnabled); len(ps) > 0 {
-				obj := map[string]any{"role": ir.ClaudeRoleAssistant, "content": ps}
-				if m.CacheControl != nil {
-					cc := map[string]any{"type": m.CacheControl.Type}
-					if m.CacheControl.TTL != nil {
-						cc["ttl"] = *m.CacheControl.TTL
-					}
-					obj["cache_control"] = cc
-				}
-				msgs = append(msgs, obj)
+				markLastClaudeBlock(ps, cacheControlFor(m))
+				msgs = append(msgs, map[string]any{"role": ir.ClaudeRoleAssistant, "content": ps})
 			}
 		case ir.RoleTool:
+			cc := cacheControlFor(m)
+			var last map[string]any
 			for _, p := range m.Content {
 				if p.Type == ir.ContentTypeToolResult && p.ToolResult != nil {
-					tr := map[string]any{"type": ir.ClaudeBlockToolResult, "tool_use_id": p.ToolResult.ToolCallID}
+					tr := map[string]any{"type": ir.ClaudeBlockToolResult, "tool_use_id": ir.ToClaudeToolID(p.ToolResult.ToolCallID)}
 					if p.ToolResult.IsError {
 						tr["is_error"] = true
 					}
@@ -139,8 +197,12 @@ func (p *ClaudeProvider) ConvertRequest(req *ir.UnifiedChatRequest) ([]byte, err
 						tr["content"] = p.ToolResult.Result
 					}
 					msgs = append(msgs, map[string]any{"role": ir.ClaudeRoleUser, "content": []any{tr}})
+					last = tr
 				}
 			}
+			if last != nil && cc != nil {
+				last["cache_control"] = cc
+			}
 		}
 	}
 	root["messages"] = msgs
@@ -177,23 +239,23 @@ func (p *ClaudeProvider) ConvertRequest(req *ir.UnifiedChatRequest) ([]byte, err
 		}
 	}
 
-	if req.ToolChoice == "none" {
-		tools = nil
-	}
-
 	if len(tools) > 0 {
 		root["tools"] = tools
-		tc := map[string]any{}
-		switch req.ToolChoice {
-		case "function":
-			tc = map[string]any{"type": "tool", "name": req.ToolChoiceFunction}
-		case "required", "any":
+		var tc map[string]any
+		if name := ir.ForcedToolName(req); name != "" {
+			tc = map[string]any{"type": "tool", "name": name}
+		} else if ir.RequiresToolCall(req) {
 			tc = map[string]any{"type": "any"}
-		case "auto":
-			tc = map[string]any{"type": "auto"}
+		} else {
+			switch req.ToolChoice {
+			case ir.ToolChoiceAuto:
+				tc = map[string]any{"type": "auto"}
+			case ir.ToolChoiceNone:
+				tc = map[string]any{"type": "none"}
+			}
 		}
-		if len(tc) > 0 {
-			if req.ParallelToolCalls != nil && !*req.ParallelToolCalls {
+		if tc != nil {
+			if req.ParallelToolCalls != nil && !*req.ParallelToolCalls && tc["type"] != "none" {
 				tc["disable_parallel_tool_use"] = true
 			}
 			root["tool_choice"] = tc
@@ -275,6 +337,9 @@ func (p *ClaudeProvider) ParseStreamChunkWithState(cj []byte, state *ir.ClaudeSt
 func ToClaudeSSE(ev ir.UnifiedEvent, state *ClaudeStreamState) ([]byte, error) {
 	res := ir.GetStringBuilder()
 	defer ir.PutStringBuilder(res)
+	if ev.Type != ir.EventTypeToolCall && ev.Type != ir.EventTypeToolCallDelta && ev.Type != ir.EventTypeStreamMeta {
+		closeToolBlockTo(res, state)
+	}
 	switch ev.Type {
 	case ir.EventTypeStreamMeta:
 		if state != nil && !state.MessageStartSent && ev.StreamMeta != nil {
@@ -313,9 +378,9 @@ func ToClaudeSSE(ev ir.UnifiedEvent, state *ClaudeStreamState) ([]byte, error) {
 	case ir.EventTypeFinish:
 		if state != nil && !state.FinishSent {
 			state.FinishSent = true
-			emitFinishTo(res, ev.Usage, state)
+			emitFinishTo(res, ev.Usage, ev.FinishReason, state)
 		} else if state == nil {
-			emitFinishTo(res, ev.Usage, nil)
+			emitFinishTo(res, ev.Usage, ev.FinishReason, nil)
 		}
 	case ir.EventTypeError:
 		res.WriteString(formatSSE(ir.ClaudeSSEError, map[string]any{"type": ir.ClaudeSSEError, "error": map[string]any{"type": "api_error", "message": ev.Error.Error()}}))
@@ -424,6 +489,16 @@ func emitRedactedThinkingDeltaTo(res *strings.Builder, d string, s *ClaudeStream
 }
 
 func emitToolCallTo(res *strings.Builder, tc *ir.ToolCall, s *ClaudeStreamState) {
+	// OpenAI-style upstreams send a call's arguments in later fragments that
+	// carry neither ID nor name; they continue the open block.
+	if s != nil && s.ToolBlockOpen && tc.ID == "" && tc.Name == "" {
+		if tc.Args != "" {
+			s.ToolBlockHasArgs = true
+			res.WriteString(formatSSE(ir.ClaudeSSEContentBlockDelta, map[string]any{"type": ir.ClaudeSSEContentBlockDelta, "index": s.ToolBlockIndex, "delta": map[string]any{"type": "input_json_delta", "partial_json": tc.Args}}))
+		}
+		return
+	}
+	closeToolBlockTo(res, s)
 	if s != nil && s.TextBlockStarted && s.CurrentBlockType == ir.ClaudeBlockThinking && len(tc.ThoughtSignature) > 0 {
 		res.WriteString(formatSSE(ir.ClaudeSSEContentBlockDelta, map[string]any{"type": ir.ClaudeSSEContentBlockDelta, "index": s.TextBlockIndex, "delta": map[string]any{"type": "signature_delta", "signature": string(tc.ThoughtSignature)}}))
 	}
@@ -437,6 +512,13 @@ func emitToolCallTo(res *strings.Builder, tc *ir.ToolCall, s *ClaudeStreamState)
 		s.TextBlockIndex++
 	}
 	res.WriteString(formatSSE(ir.ClaudeSSEContentBlockStart, map[string]any{"type": ir.ClaudeSSEContentBlockStart, "index": idx, "content_block": map[string]any{"type": ir.ClaudeBlockToolUse, "id": ir.ToClaudeToolID(tc.ID), "name": tc.Name, "input": map[string]any{}}}))
+	if s != nil {
+		s.ToolBlockOpen, s.ToolBlockIndex, s.ToolBlockHasArgs = true, idx, tc.Args != ""
+		if tc.Args != "" {
+			res.WriteString(formatSSE(ir.ClaudeSSEContentBlockDelta, map[string]any{"type": ir.ClaudeSSEContentBlockDelta, "index": idx, "delta": map[string]any{"type": "input_json_delta", "partial_json": tc.Args}}))
+		}
+		return
+	}
 	args := tc.Args
 	if args == "" {
 		args = "{}"
@@ -445,7 +527,19 @@ func emitToolCallTo(res *strings.Builder, tc *ir.ToolCall, s *ClaudeStreamState)
 	res.WriteString(formatSSE(ir.ClaudeSSEContentBlockStop, map[string]any{"type": ir.ClaudeSSEContentBlockStop, "index": idx}))
 }
 
-func emitFinishTo(res *strings.Builder, us *ir.Usage, s *ClaudeStreamState) {
+// closeToolBlockTo closes the open tool_use block, if any.
+func closeToolBlockTo(res *strings.Builder, s *ClaudeStreamState) {
+	if s == nil || !s.ToolBlockOpen {
+		return
+	}
+	if !s.ToolBlockHasArgs {
+		res.WriteString(formatSSE(ir.ClaudeSSEContentBlockDelta, map[string]any{"type": ir.ClaudeSSEContentBlockDelta, "index": s.ToolBlockIndex, "delta": map[string]any{"type": "input_json_delta", "partial_json": "{}"}}))
+	}
+	res.WriteString(formatSSE(ir.ClaudeSSEContentBlockStop, map[string]any{"type": ir.ClaudeSSEContentBlockStop, "index": s.ToolBlockIndex}))
+	s.ToolBlockOpen = false
+}
+
+func emitFinishTo(res *strings.Builder, us *ir.Usage, reason ir.FinishReason, s *ClaudeStreamState) {
 	if s != nil && s.TextBlockStarted {
 		res.WriteString(formatSSE(ir.ClaudeSSEContentBlockStop, map[string]any{"type": ir.ClaudeSSEContentBlockStop, "index": s.TextBlockIndex}))
 		s.TextBlockStarted, s.TextBlockIndex, s.CurrentBlockType = false, s.TextBlockIndex+1, ""
@@ -455,7 +549,7 @@ func emitFinishTo(res *strings.Builder, us *ir.Usage, s *ClaudeStreamState) {
 		res.WriteString(formatSSE(ir.ClaudeSSEContentBlockDelta, map[string]any{"type": ir.ClaudeSSEContentBlockDelta, "index": s.TextBlockIndex, "delta": map[string]any{"type": "text_delta", "text": " "}}))
 		res.WriteString(formatSSE(ir.ClaudeSSEContentBlockStop, map[string]any{"type": ir.ClaudeSSEContentBlockStop, "index": s.TextBlockIndex}))
 	}
-	sr := ir.ClaudeStopEndTurn
+	sr := ir.MapFinishReasonToClaude(reason)
 	if s != nil && s.HasToolCalls {
 		sr = ir.ClaudeStopToolUse
 	}
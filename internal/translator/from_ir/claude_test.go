@@ -0,0 +1,104 @@
+package from_ir
+
+import (
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/tidwall/gjson"
+)
+
+func TestClaudeProvider_CacheControlOnBlocks(t *testing.T) {
+	ttl := int64(3600)
+	cache := &ir.CacheControl{Type: ir.CacheControlEphemeral}
+	req := &ir.UnifiedChatRequest{
+		Model: "claude-sonnet-4-20250514",
+		Messages: []ir.Message{
+			{Role: ir.RoleSystem, Content: []ir.ContentPart{{Type: ir.ContentTypeText, Text: "System"}}, CacheControl: cache},
+			{Role: ir.RoleUser, Content: []ir.ContentPart{{Type: ir.ContentTypeText, Text: "One"}}, CacheControl: cache},
+			{Role: ir.RoleAssistant, Content: []ir.ContentPart{{Type: ir.ContentTypeText, Text: "Two"}}, CacheControl: cache},
+			{Role: ir.RoleUser, Content: []ir.ContentPart{{Type: ir.ContentTypeText, Text: "Three"}}, CacheControl: cache},
+			{Role: ir.RoleUser, Content: []ir.ContentPart{
+				{Type: ir.ContentTypeText, Text: "Four"},
+				{Type: ir.ContentTypeText, Text: "Five"},
+			}, CacheControl: &ir.CacheControl{Type: ir.CacheControlEphemeral, TTL: &ttl}},
+		},
+		MaxTokens: ir.Ptr(1024),
+	}
+
+	payload, err := (&ClaudeProvider{}).ConvertRequest(req)
+	if err != nil {
+		t.Fatalf("ConvertRequest failed: %v", err)
+	}
+	parsed := gjson.ParseBytes(payload)
+
+	// Five breakpoints were requested; the earliest one (system) is dropped.
+	if parsed.Get("system").Type != gjson.String {
+		t.Errorf("system = %s, want plain string", parsed.Get("system").Raw)
+	}
+	msgs := parsed.Get("messages").Array()
+	for i, m := range msgs {
+		if m.Get("cache_control").Exists() {
+			t.Errorf("message %d has message-level cache_control", i)
+		}
+		blocks := m.Get("content").Array()
+		if !blocks[len(blocks)-1].Get("cache_control").Exists() {
+			t.Errorf("message %d: last block not marked: %s", i, m.Raw)
+		}
+	}
+	last := msgs[len(msgs)-1].Get("content").Array()
+	if last[0].Get("cache_control").Exists() {
+		t.Error("only the final block should carry cache_control")
+	}
+	if got := last[1].Get("cache_control.ttl").String(); got != "1h" {
+		t.Errorf("ttl = %q, want 1h", got)
+	}
+}
+
+func TestClaudeProvider_CachedSystemPrompt(t *testing.T) {
+	req := &ir.UnifiedChatRequest{
+		Model: "claude-sonnet-4-20250514",
+		Messages: []ir.Message{
+			{Role: ir.RoleSystem, Content: []ir.ContentPart{{Type: ir.ContentTypeText, Text: "System"}}, CacheControl: &ir.CacheControl{Type: ir.CacheControlEphemeral}},
+			{Role: ir.RoleUser, Content: []ir.ContentPart{{Type: ir.ContentTypeText, Text: "Hi"}}},
+		},
+		MaxTokens: ir.Ptr(1024),
+	}
+	payload, err := (&ClaudeProvider{}).ConvertRequest(req)
+	if err != nil {
+		t.Fatalf("ConvertRequest failed: %v", err)
+	}
+	system := gjson.GetBytes(payload, "system.0")
+	if system.Get("text").String() != "System" || system.Get("cache_control.type").String() != "ephemeral" {
+		t.Errorf("system = %s", gjson.GetBytes(payload, "system").Raw)
+	}
+}
+
+func TestClaudeProvider_ToolResultIDsMatchToolUse(t *testing.T) {
+	result := func(id string) []ir.ContentPart {
+		return []ir.ContentPart{{Type: ir.ContentTypeToolResult, ToolResult: &ir.ToolResultPart{ToolCallID: id, Result: "18C"}}}
+	}
+	req := &ir.UnifiedChatRequest{
+		Model: "claude-sonnet-4-20250514",
+		Messages: []ir.Message{
+			{Role: ir.RoleUser, Content: []ir.ContentPart{{Type: ir.ContentTypeText, Text: "Weather?"}}},
+			{Role: ir.RoleAssistant, ToolCalls: []ir.ToolCall{{ID: "call_1", Name: "get_weather", Args: `{}`}}},
+			{Role: ir.RoleTool, Content: result("call_1")},
+			{Role: ir.RoleAssistant, ToolCalls: []ir.ToolCall{{ID: "fc-2", Name: "get_weather", Args: `{}`}}},
+			{Role: ir.RoleUser, Content: result("fc-2")},
+		},
+		MaxTokens: ir.Ptr(1024),
+	}
+
+	payload, err := (&ClaudeProvider{}).ConvertRequest(req)
+	if err != nil {
+		t.Fatalf("ConvertRequest failed: %v", err)
+	}
+	msgs := gjson.GetBytes(payload, "messages").Array()
+	for _, pair := range [][2]int{{1, 2}, {3, 4}} {
+		use := msgs[pair[0]].Get(`content.#(type=="tool_use").id`).String()
+		res := msgs[pair[1]].Get(`content.#(type=="tool_result").tool_use_id`).String()
+		if use == "" || res != use {
+			t.Errorf("tool_use id %q answered by tool_result id %q", use, res)
+		}
+	}
+}
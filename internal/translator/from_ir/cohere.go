@@ -0,0 +1,264 @@
+package from_ir
+
+import (
+	"strings"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/nghyane/llm-mux/internal/json"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+)
+
+// Cohere chat roles used in chat_history.
+const (
+	cohereRoleUser    = "USER"
+	cohereRoleChatbot = "CHATBOT"
+	cohereRoleTool    = "TOOL"
+)
+
+// ToCohereRequest converts the IR to a Cohere /v1/chat request. System
+// messages become the preamble, the final user turn becomes message and
+// everything before it becomes chat_history. When the conversation ends with
+// tool results they are sent as tool_results with an empty message.
+//
+// Cohere tool calls carry no IDs, so each tool result is sent with the name
+// and parameters of the call it answers. Images and files are not supported
+// and are dropped with a warning.
+func ToCohereRequest(req *ir.UnifiedChatRequest) ([]byte, error) {
+	if err := ir.DropUnsupportedLogprobs(req, "cohere"); err != nil {
+		return nil, err
+	}
+	body := map[string]any{"model": req.Model}
+
+	messages := ir.NormalizeSystemMessages(req.Messages)
+	var preamble []string
+	var turns []ir.Message
+	for _, msg := range messages {
+		if msg.Role == ir.RoleSystem {
+			if text := ir.CombineTextParts(msg); text != "" {
+				preamble = append(preamble, text)
+			}
+			continue
+		}
+		turns = append(turns, msg)
+	}
+	if len(preamble) > 0 {
+		body["preamble"] = strings.Join(preamble, "\n\n")
+	}
+
+	calls := make(map[string]ir.ToolCall)
+	for _, msg := range turns {
+		for _, tc := range msg.ToolCalls {
+			calls[tc.ID] = tc
+		}
+	}
+
+	// Split off the trailing user message or run of tool results.
+	tail := len(turns)
+	for tail > 0 && turns[tail-1].Role == ir.RoleTool {
+		tail--
+	}
+	message := ""
+	var toolResults []any
+	if tail < len(turns) {
+		for _, msg := range turns[tail:] {
+			toolResults = append(toolResults, cohereToolResults(msg, calls)...)
+		}
+	} else if tail > 0 && turns[tail-1].Role == ir.RoleUser {
+		tail--
+		message = ir.CombineTextParts(turns[tail])
+	}
+	body["message"] = message
+	if len(toolResults) > 0 {
+		body["tool_results"] = toolResults
+	}
+
+	history := make([]any, 0, tail)
+	for _, msg := range turns[:tail] {
+		switch msg.Role {
+		case ir.RoleUser:
+			history = append(history, map[string]any{"role": cohereRoleUser, "message": ir.CombineTextParts(msg)})
+		case ir.RoleAssistant:
+			entry := map[string]any{"role": cohereRoleChatbot, "message": ir.CombineTextParts(msg)}
+			if len(msg.ToolCalls) > 0 {
+				toolCalls := make([]any, len(msg.ToolCalls))
+				for i, tc := range msg.ToolCalls {
+					toolCalls[i] = cohereToolCall(tc)
+				}
+				entry["tool_calls"] = toolCalls
+			}
+			history = append(history, entry)
+		case ir.RoleTool:
+			history = append(history, map[string]any{"role": cohereRoleTool, "tool_results": cohereToolResults(msg, calls)})
+		}
+	}
+	if len(history) > 0 {
+		body["chat_history"] = history
+	}
+	if hasMediaParts(turns) {
+		req.AddWarning("cohere does not accept images or files; they were dropped")
+	}
+
+	if len(req.Tools) > 0 && req.ToolChoice != "none" {
+		tools := make([]any, len(req.Tools))
+		for i, t := range req.Tools {
+			tools[i] = cohereTool(t)
+		}
+		body["tools"] = tools
+	}
+
+	if req.Temperature != nil {
+		body["temperature"] = *req.Temperature
+	}
+	if req.TopP != nil {
+		body["p"] = *req.TopP
+	}
+	if req.TopK != nil {
+		body["k"] = *req.TopK
+	}
+	if req.MaxTokens != nil {
+		body["max_tokens"] = *req.MaxTokens
+	}
+	if len(req.StopSequences) > 0 {
+		body["stop_sequences"] = req.StopSequences
+	}
+	if req.FrequencyPenalty != nil {
+		body["frequency_penalty"] = *req.FrequencyPenalty
+	}
+	if req.PresencePenalty != nil {
+		body["presence_penalty"] = *req.PresencePenalty
+	}
+	if seed, ok := req.Metadata[ir.MetaOpenAISeed]; ok {
+		body["seed"] = seed
+	}
+	if req.ResponseSchema != nil {
+		body["response_format"] = map[string]any{"type": "json_object", "schema": req.ResponseSchema}
+	}
+	return json.Marshal(body)
+}
+
+// hasMediaParts reports whether any message carries content other than text,
+// reasoning and tool results, or a tool result carries images or files.
+func hasMediaParts(messages []ir.Message) bool {
+	for _, msg := range messages {
+		for _, part := range msg.Content {
+			switch part.Type {
+			case ir.ContentTypeImage, ir.ContentTypeFile, ir.ContentTypeAudio, ir.ContentTypeVideo:
+				return true
+			}
+		}
+	}
+	return ir.HasToolResultMedia(messages)
+}
+
+// cohereToolCall renders a call in Cohere's {name, parameters} shape.
+func cohereToolCall(tc ir.ToolCall) map[string]any {
+	params := ir.ParseToolCallArgs(tc.Args)
+	if params == nil {
+		params = map[string]any{}
+	}
+	return map[string]any{"name": tc.Name, "parameters": params}
+}
+
+// cohereToolResults converts the tool results in msg, pairing each with the
+// call it answers. Outputs must be a list of objects: JSON objects and arrays
+// of objects are sent as-is, anything else is wrapped as {"output": text}.
+func cohereToolResults(msg ir.Message, calls map[string]ir.ToolCall) []any {
+	var out []any
+	for _, part := range msg.Content {
+		if part.Type != ir.ContentTypeToolResult || part.ToolResult == nil {
+			continue
+		}
+		tr := part.ToolResult
+		call, ok := calls[tr.ToolCallID]
+		if !ok {
+			call = ir.ToolCall{ID: tr.ToolCallID}
+		}
+		out = append(out, map[string]any{
+			"call":    cohereToolCall(call),
+			"outputs": cohereToolOutputs(tr),
+		})
+	}
+	return out
+}
+
+func cohereToolOutputs(tr *ir.ToolResultPart) []any {
+	key := "output"
+	if tr.IsError {
+		key = "error"
+	}
+	parsed := gjson.Parse(tr.Result)
+	switch {
+	case gjson.Valid(tr.Result) && parsed.IsObject() && !tr.IsError:
+		return []any{json.RawMessage(parsed.Raw)}
+	case gjson.Valid(tr.Result) && parsed.IsArray() && !tr.IsError:
+		outputs := make([]any, 0, len(parsed.Array()))
+		for _, item := range parsed.Array() {
+			if !item.IsObject() {
+				return []any{map[string]any{key: tr.Result}}
+			}
+			outputs = append(outputs, json.RawMessage(item.Raw))
+		}
+		return outputs
+	}
+	return []any{map[string]any{key: tr.Result}}
+}
+
+// cohereTool converts a JSON Schema tool definition into Cohere's
+// parameter_definitions. Only top-level properties are mapped; nested
+// objects and arrays keep their type but lose their inner schema.
+func cohereTool(t ir.ToolDefinition) map[string]any {
+	defs := map[string]any{}
+	props, _ := t.Parameters["properties"].(map[string]any)
+	required := map[string]bool{}
+	if list, ok := t.Parameters["required"].([]any); ok {
+		for _, name := range list {
+			if s, ok := name.(string); ok {
+				required[s] = true
+			}
+		}
+	}
+	for name, raw := range props {
+		schema, _ := raw.(map[string]any)
+		def := map[string]any{
+			"type":     cohereParamType(schema),
+			"required": required[name],
+		}
+		if desc, ok := schema["description"].(string); ok && desc != "" {
+			def["description"] = desc
+		}
+		defs[name] = def
+	}
+	return map[string]any{
+		"name":                  t.Name,
+		"description":           t.Description,
+		"parameter_definitions": defs,
+	}
+}
+
+// cohereParamType maps a JSON Schema type to the Python-style type names
+// Cohere expects.
+func cohereParamType(schema map[string]any) string {
+	typ, _ := schema["type"].(string)
+	switch typ {
+	case "string":
+		return "str"
+	case "integer":
+		return "int"
+	case "number":
+		return "float"
+	case "boolean":
+		return "bool"
+	case "array":
+		items, _ := schema["items"].(map[string]any)
+		if items != nil {
+			if inner, _ := items["type"].(string); inner != "" {
+				return "List[" + cohereParamType(items) + "]"
+			}
+		}
+		return "list"
+	case "object":
+		return "dict"
+	}
+	return "str"
+}
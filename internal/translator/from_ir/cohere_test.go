@@ -0,0 +1,121 @@
+package from_ir
+
+import (
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/translator/to_ir"
+	"github.com/tidwall/gjson"
+)
+
+func TestToCohereRequest_ToolRoundTrip(t *testing.T) {
+	body := `{"model":"command-r-08-2024","temperature":0.3,"messages":[
+		{"role":"system","content":"Be brief."},
+		{"role":"user","content":"Weather in Paris?"},
+		{"role":"assistant","content":null,"tool_calls":[{"id":"call_1","type":"function","function":{"name":"get_weather","arguments":"{\"city\":\"Paris\"}"}}]},
+		{"role":"tool","tool_call_id":"call_1","content":"{\"temp\":21}"}],
+		"tools":[{"type":"function","function":{"name":"get_weather","description":"Current weather",
+			"parameters":{"type":"object","properties":{"city":{"type":"string","description":"City name"},"days":{"type":"integer"}},"required":["city"]}}}]}`
+	req, err := to_ir.ParseOpenAIRequest([]byte(body))
+	if err != nil {
+		t.Fatalf("ParseOpenAIRequest: %v", err)
+	}
+	out, err := ToCohereRequest(req)
+	if err != nil {
+		t.Fatalf("ToCohereRequest: %v", err)
+	}
+	got := gjson.ParseBytes(out)
+
+	if got.Get("preamble").String() != "Be brief." {
+		t.Errorf("preamble = %s", got.Get("preamble").Raw)
+	}
+	if got.Get("message").String() != "" {
+		t.Errorf("message = %s, want empty when sending tool results", got.Get("message").Raw)
+	}
+	if got.Get("temperature").Float() != 0.3 {
+		t.Errorf("temperature = %s", got.Get("temperature").Raw)
+	}
+	history := got.Get("chat_history").Array()
+	if len(history) != 2 || history[0].Get("role").String() != "USER" || history[1].Get("role").String() != "CHATBOT" {
+		t.Fatalf("chat_history = %s", got.Get("chat_history").Raw)
+	}
+	if history[1].Get("tool_calls.0.parameters.city").String() != "Paris" {
+		t.Errorf("history tool call = %s", history[1].Raw)
+	}
+	result := got.Get("tool_results.0")
+	if result.Get("call.name").String() != "get_weather" || result.Get("call.parameters.city").String() != "Paris" {
+		t.Errorf("tool result call = %s", result.Raw)
+	}
+	if result.Get("outputs.0.temp").Int() != 21 {
+		t.Errorf("tool result outputs = %s", result.Get("outputs").Raw)
+	}
+	params := got.Get("tools.0.parameter_definitions")
+	if params.Get("city.type").String() != "str" || !params.Get("city.required").Bool() || params.Get("city.description").String() != "City name" {
+		t.Errorf("city parameter = %s", params.Get("city").Raw)
+	}
+	if params.Get("days.type").String() != "int" || params.Get("days.required").Bool() {
+		t.Errorf("days parameter = %s", params.Get("days").Raw)
+	}
+
+	// The model answers with another tool call; it comes back with a fresh ID.
+	messages, usage, meta, err := to_ir.ParseCohereResponse([]byte(`{"response_id":"r1","text":"",
+		"tool_calls":[{"name":"get_weather","parameters":{"city":"Lyon"}}],"finish_reason":"COMPLETE",
+		"meta":{"tokens":{"input_tokens":40,"output_tokens":12}}}`))
+	if err != nil {
+		t.Fatalf("ParseCohereResponse: %v", err)
+	}
+	if len(messages) != 1 || len(messages[0].ToolCalls) != 1 {
+		t.Fatalf("messages = %+v", messages)
+	}
+	call := messages[0].ToolCalls[0]
+	if call.ID == "" || call.Name != "get_weather" || gjson.Get(call.Args, "city").String() != "Lyon" {
+		t.Errorf("tool call = %+v", call)
+	}
+	if usage == nil || usage.PromptTokens != 40 || usage.CompletionTokens != 12 || usage.TotalTokens != 52 {
+		t.Errorf("usage = %+v", usage)
+	}
+	if meta.ResponseID != "r1" || meta.ContentFilter != nil {
+		t.Errorf("meta = %+v", meta)
+	}
+}
+
+func TestToCohereRequest_UserMessageAndDroppedImages(t *testing.T) {
+	body := `{"model":"command-a-03-2025","messages":[
+		{"role":"user","content":"hi"},
+		{"role":"assistant","content":"hello"},
+		{"role":"user","content":[{"type":"text","text":"what is this?"},{"type":"image_url","image_url":{"url":"data:image/png;base64,AAAA"}}]}],
+		"tools":[{"type":"function","function":{"name":"f","parameters":{"type":"object"}}}],"tool_choice":"none"}`
+	req, err := to_ir.ParseOpenAIRequest([]byte(body))
+	if err != nil {
+		t.Fatalf("ParseOpenAIRequest: %v", err)
+	}
+	out, err := ToCohereRequest(req)
+	if err != nil {
+		t.Fatalf("ToCohereRequest: %v", err)
+	}
+	got := gjson.ParseBytes(out)
+	if got.Get("message").String() != "what is this?" {
+		t.Errorf("message = %s", got.Get("message").Raw)
+	}
+	if n := len(got.Get("chat_history").Array()); n != 2 {
+		t.Errorf("chat_history has %d turns, want 2", n)
+	}
+	if got.Get("tools").Exists() {
+		t.Errorf("tools sent with tool_choice none: %s", got.Get("tools").Raw)
+	}
+	if got.Get("tool_results").Exists() {
+		t.Errorf("unexpected tool_results: %s", got.Get("tool_results").Raw)
+	}
+	if len(req.Warnings) != 1 {
+		t.Errorf("warnings = %v, want one for the dropped image", req.Warnings)
+	}
+}
+
+func TestParseCohereResponse_ToxicFinishIsContentFilter(t *testing.T) {
+	_, _, meta, err := to_ir.ParseCohereResponse([]byte(`{"text":"","finish_reason":"ERROR_TOXIC"}`))
+	if err != nil {
+		t.Fatalf("ParseCohereResponse: %v", err)
+	}
+	if meta.ContentFilter == nil {
+		t.Errorf("content filter not set: %+v", meta)
+	}
+}
@@ -0,0 +1,102 @@
+package from_ir
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"math"
+
+	"github.com/nghyane/llm-mux/internal/json"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+)
+
+// ToOpenAIEmbeddingRequest builds an OpenAI embeddings request. Vectors are
+// always requested as floats so they can be reassembled across batches.
+func ToOpenAIEmbeddingRequest(req *ir.EmbeddingRequest) ([]byte, error) {
+	body := map[string]any{
+		"model":           req.Model,
+		"input":           req.Inputs,
+		"encoding_format": "float",
+	}
+	if req.Dimensions > 0 {
+		body["dimensions"] = req.Dimensions
+	}
+	if req.User != "" {
+		body["user"] = req.User
+	}
+	return json.Marshal(body)
+}
+
+type geminiEmbedContentRequest struct {
+	Model                string `json:"model"`
+	Content              any    `json:"content"`
+	OutputDimensionality int    `json:"outputDimensionality,omitempty"`
+}
+
+// ToGeminiBatchEmbedRequest builds a Gemini batchEmbedContents request with
+// one embedContent request per input.
+func ToGeminiBatchEmbedRequest(req *ir.EmbeddingRequest) ([]byte, error) {
+	requests := make([]geminiEmbedContentRequest, len(req.Inputs))
+	for i, text := range req.Inputs {
+		requests[i] = geminiEmbedContentRequest{
+			Model:                "models/" + req.Model,
+			Content:              map[string]any{"parts": []any{map[string]string{"text": text}}},
+			OutputDimensionality: req.Dimensions,
+		}
+	}
+	return json.Marshal(map[string]any{"requests": requests})
+}
+
+type openaiEmbedding struct {
+	Object    string `json:"object"`
+	Index     int    `json:"index"`
+	Embedding any    `json:"embedding"`
+}
+
+// ToOpenAIEmbeddingResponse renders resp in the OpenAI embeddings shape.
+// encodingFormat "base64" packs each vector as little-endian float32s.
+func ToOpenAIEmbeddingResponse(resp *ir.EmbeddingResponse, encodingFormat string) ([]byte, error) {
+	data := make([]openaiEmbedding, len(resp.Vectors))
+	for i, vec := range resp.Vectors {
+		data[i] = openaiEmbedding{Object: "embedding", Index: i, Embedding: vec}
+		if encodingFormat == ir.EmbeddingEncodingBase64 {
+			data[i].Embedding = encodeFloat32Base64(vec)
+		}
+	}
+	usage := map[string]any{"prompt_tokens": int64(0), "total_tokens": int64(0)}
+	if us := resp.Usage; us != nil {
+		usage["prompt_tokens"] = us.PromptTokens
+		usage["total_tokens"] = us.TotalTokens
+		if us.Approximate {
+			usage["approximate"] = true
+		}
+	}
+	return json.Marshal(map[string]any{
+		"object": "list",
+		"data":   data,
+		"model":  resp.Model,
+		"usage":  usage,
+	})
+}
+
+func encodeFloat32Base64(vec []float32) string {
+	buf := make([]byte, 4*len(vec))
+	for i, f := range vec {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(f))
+	}
+	return base64.StdEncoding.EncodeToString(buf)
+}
+
+// ToCohereEmbedRequest builds a Cohere /v1/embed request. Inputs are embedded
+// as search documents, the usual choice for texts stored in a vector index.
+func ToCohereEmbedRequest(req *ir.EmbeddingRequest) ([]byte, error) {
+	body := map[string]any{
+		"model":           req.Model,
+		"texts":           req.Inputs,
+		"input_type":      "search_document",
+		"embedding_types": []string{"float"},
+	}
+	if req.Dimensions > 0 {
+		body["output_dimension"] = req.Dimensions
+	}
+	return json.Marshal(body)
+}
@@ -58,7 +58,7 @@ func (p *GeminiProvider) applyGenerationConfig(root map[string]any, req *ir.Unif
 		gc["maxOutputTokens"] = *req.MaxTokens
 	}
 	if len(req.StopSequences) > 0 {
-		gc["stopSequences"] = req.StopSequences
+		gc["stopSequences"] = ir.LimitStopSequences(req, ir.MaxStopSequencesGemini, "gemini")
 	}
 	if req.FrequencyPenalty != nil {
 		gc["frequencyPenalty"] = *req.FrequencyPenalty
@@ -128,14 +128,15 @@ func (p *GeminiProvider) applyMessages(root map[string]any, req *ir.UnifiedChatR
 	if len(req.Messages) == 0 {
 		return nil
 	}
-	toolIDToName, toolResults := ir.BuildToolMaps(req.Messages)
-	coalescer := ir.GetContentCoalescer(len(req.Messages) * 2)
+	messages := ir.NormalizeSystemMessages(req.Messages)
+	toolIDToName, toolResults := ir.BuildToolMaps(messages)
+	coalescer := ir.GetContentCoalescer(len(messages) * 2)
 
-	for i := range req.Messages {
-		msg := &req.Messages[i]
+	for i := range messages {
+		msg := &messages[i]
 		switch msg.Role {
 		case ir.RoleSystem:
-			if text := p.extractSystemText(msg); text != "" {
+			if text := ir.CombineTextParts(*msg); text != "" {
 				root["systemInstruction"] = map[string]any{"role": "user", "parts": []any{map[string]any{"text": text}}}
 			}
 		case ir.RoleUser:
@@ -154,15 +155,6 @@ func (p *GeminiProvider) applyMessages(root map[string]any, req *ir.UnifiedChatR
 	return nil
 }
 
-func (p *GeminiProvider) extractSystemText(msg *ir.Message) string {
-	for i := range msg.Content {
-		if msg.Content[i].Type == ir.ContentTypeText {
-			return msg.Content[i].Text
-		}
-	}
-	return ""
-}
-
 func (p *GeminiProvider) buildAssistantAndToolParts(msg *ir.Message, toolIDToName map[string]string, toolResults map[string]*ir.ToolResultPart, model string) (modelParts, responseParts []any) {
 	for i := range msg.Content {
 		cp := &msg.Content[i]
@@ -197,13 +189,7 @@ func (p *GeminiProvider) buildAssistantAndToolParts(msg *ir.Message, toolIDToNam
 		if name, ok := toolIDToName[id]; ok {
 			if res, ok := toolResults[id]; ok {
 				responseParts = append(responseParts, map[string]any{"functionResponse": map[string]any{"name": name, "id": id, "response": buildFunctionResponseObject(res.Result, res.IsError)}})
-				for _, img := range res.Images {
-					if img.Data != "" {
-						responseParts = append(responseParts, map[string]any{"inlineData": map[string]any{"mimeType": img.MimeType, "data": img.Data}})
-					} else if img.URL != "" {
-						responseParts = append(responseParts, map[string]any{"fileData": map[string]any{"fileUri": img.URL, "mimeType": img.MimeType}})
-					}
-				}
+				responseParts = append(responseParts, toolResultMediaParts(res)...)
 			}
 		}
 	}
@@ -263,17 +249,16 @@ func (p *GeminiProvider) applyTools(root map[string]any, req *ir.UnifiedChatRequ
 
 	if len(req.Tools) > 0 {
 		mode, allowed := "AUTO", []string(nil)
-		switch req.ToolChoice {
-		case "none":
+		switch {
+		case ir.ForcedToolName(req) != "":
+			mode, allowed = "ANY", []string{ir.ForcedToolName(req)}
+		case ir.RequiresToolCall(req):
+			// allowedFunctionNames is only honoured in ANY mode.
+			mode, allowed = "ANY", req.AllowedTools
+		case req.ToolChoice == ir.ToolChoiceNone:
 			mode = "NONE"
-		case "required", "any":
-			mode = "ANY"
-		case "validated":
+		case req.ToolChoice == "validated":
 			mode = "VALIDATED"
-		case "function":
-			if req.ToolChoiceFunction != "" {
-				mode, allowed = "ANY", []string{req.ToolChoiceFunction}
-			}
 		}
 		fc := map[string]any{"mode": mode}
 		if len(allowed) > 0 {
@@ -284,16 +269,32 @@ func (p *GeminiProvider) applyTools(root map[string]any, req *ir.UnifiedChatRequ
 	return nil
 }
 
+// applySafetySettings sends the default thresholds with those the request
+// set replacing the defaults of their categories.
 func (p *GeminiProvider) applySafetySettings(root map[string]any, req *ir.UnifiedChatRequest) {
-	if len(req.SafetySettings) > 0 {
-		s := make([]any, len(req.SafetySettings))
-		for i, v := range req.SafetySettings {
-			s[i] = map[string]any{"category": v.Category, "threshold": v.Threshold}
-		}
-		root["safetySettings"] = s
-	} else {
+	if len(req.SafetySettings) == 0 {
 		root["safetySettings"] = ir.DefaultGeminiSafetySettings()
+		return
+	}
+	overrides := make(map[string]string, len(req.SafetySettings))
+	for _, v := range req.SafetySettings {
+		overrides[v.Category] = v.Threshold
 	}
+	var s []any
+	for _, d := range ir.DefaultGeminiSafetySettings() {
+		if threshold, ok := overrides[d["category"]]; ok {
+			d["threshold"] = threshold
+			delete(overrides, d["category"])
+		}
+		s = append(s, map[string]any{"category": d["category"], "threshold": d["threshold"]})
+	}
+	for _, v := range req.SafetySettings {
+		if _, ok := overrides[v.Category]; ok {
+			s = append(s, map[string]any{"category": v.Category, "threshold": v.Threshold})
+			delete(overrides, v.Category)
+		}
+	}
+	root["safetySettings"] = s
 }
 
 func (p *GeminiProvider) fixImageAspectRatioForPreview(root map[string]any, aspectRatio string) {
@@ -410,7 +411,7 @@ func ToGeminiChunk(event ir.UnifiedEvent, model string) ([]byte, error) {
 			candidate["content"].(map[string]any)["parts"] = []any{p}
 		}
 	case ir.EventTypeFinish:
-		candidate["finishReason"] = "STOP"
+		candidate["finishReason"] = ir.MapFinishReasonToGemini(event.FinishReason)
 		if event.GroundingMetadata != nil {
 			candidate["groundingMetadata"] = buildGroundingMetadataMap(event.GroundingMetadata)
 		}
@@ -499,6 +500,9 @@ func (p *VertexEnvelopeProvider) ConvertRequest(req *ir.UnifiedChatRequest) ([]b
 
 func (p *VertexEnvelopeProvider) buildInnerRequest(req *ir.UnifiedChatRequest) (any, error) {
 	if ir.IsClaudeModel(req.Model) {
+		if err := ir.DropUnsupportedLogprobs(req, "claude"); err != nil {
+			return nil, err
+		}
 		return p.buildClaudeInnerRequest(req), nil
 	}
 	return json.RawMessage(mustConvertGemini(req)), nil
@@ -510,18 +514,16 @@ func mustConvertGemini(req *ir.UnifiedChatRequest) []byte {
 }
 
 func (p *VertexEnvelopeProvider) buildClaudeInnerRequest(req *ir.UnifiedChatRequest) map[string]any {
+	messages := ir.NormalizeSystemMessages(req.Messages)
 	root := map[string]any{
-		"contents": p.buildClaudeContents(req),
+		"contents": p.buildClaudeContents(messages),
 	}
 
-	for _, m := range req.Messages {
-		if m.Role == ir.RoleSystem {
-			if text := ir.CombineTextParts(m); text != "" {
-				root["systemInstruction"] = map[string]any{
-					"role":  "user",
-					"parts": []any{map[string]any{"text": text}},
-				}
-				break
+	if len(messages) > 0 && messages[0].Role == ir.RoleSystem {
+		if text := ir.CombineTextParts(messages[0]); text != "" {
+			root["systemInstruction"] = map[string]any{
+				"role":  "user",
+				"parts": []any{map[string]any{"text": text}},
 			}
 		}
 	}
@@ -538,12 +540,12 @@ func (p *VertexEnvelopeProvider) buildClaudeInnerRequest(req *ir.UnifiedChatRequ
 	return root
 }
 
-func (p *VertexEnvelopeProvider) buildClaudeContents(req *ir.UnifiedChatRequest) []any {
+func (p *VertexEnvelopeProvider) buildClaudeContents(messages []ir.Message) []any {
 	var contents []any
-	toolIDToName, toolResults := ir.BuildToolMaps(req.Messages)
+	toolIDToName, toolResults := ir.BuildToolMaps(messages)
 
-	for i := range req.Messages {
-		msg := &req.Messages[i]
+	for i := range messages {
+		msg := &messages[i]
 		if msg.Role == ir.RoleSystem {
 			continue
 		}
@@ -650,6 +652,28 @@ func (p *VertexEnvelopeProvider) buildClaudeToolResultParts(msg *ir.Message, too
 					"response": resp,
 				},
 			})
+			parts = append(parts, toolResultMediaParts(tr)...)
+		}
+	}
+	return parts
+}
+
+// toolResultMediaParts returns the images and files of a tool result as the
+// parts that follow its functionResponse.
+func toolResultMediaParts(tr *ir.ToolResultPart) []any {
+	var parts []any
+	for _, img := range tr.Images {
+		if img.Data != "" {
+			parts = append(parts, map[string]any{"inlineData": map[string]any{"mimeType": img.MimeType, "data": img.Data}})
+		} else if img.URL != "" {
+			parts = append(parts, map[string]any{"fileData": map[string]any{"fileUri": img.URL, "mimeType": img.MimeType}})
+		}
+	}
+	for _, f := range tr.Files {
+		if f.FileData != "" {
+			parts = append(parts, map[string]any{"inlineData": map[string]any{"mimeType": f.MimeType, "data": f.FileData}})
+		} else if f.FileURL != "" {
+			parts = append(parts, map[string]any{"fileData": map[string]any{"fileUri": f.FileURL, "mimeType": f.MimeType}})
 		}
 	}
 	return parts
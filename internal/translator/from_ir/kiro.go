@@ -10,9 +10,14 @@ import (
 type KiroProvider struct{}
 
 func (p *KiroProvider) ConvertRequest(req *ir.UnifiedChatRequest) ([]byte, error) {
+	if err := ir.DropUnsupportedLogprobs(req, "kiro"); err != nil {
+		return nil, err
+	}
+	ir.DropToolResultMedia(req, "kiro")
 	tools := extractTools(req.Tools)
-	systemPrompt := extractSystemPrompt(req.Messages)
-	history, currentMessage := processMessages(req.Messages, tools, req.Model)
+	messages := ir.NormalizeSystemMessages(req.Messages)
+	systemPrompt := extractSystemPrompt(messages)
+	history, currentMessage := processMessages(messages, tools, req.Model)
 
 	injectSystemPrompt(systemPrompt, &history, currentMessage, req.Model)
 
@@ -0,0 +1,71 @@
+package from_ir
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/nghyane/llm-mux/internal/translator/to_ir"
+	"github.com/tidwall/gjson"
+)
+
+func logprobsRequest(t *testing.T, extra string) *ir.UnifiedChatRequest {
+	t.Helper()
+	body := `{"model":"m","messages":[{"role":"user","content":"hi"}],"logprobs":true,"top_logprobs":3` + extra + `}`
+	req, err := to_ir.ParseOpenAIRequest([]byte(body))
+	if err != nil {
+		t.Fatalf("ParseOpenAIRequest: %v", err)
+	}
+	return req
+}
+
+func TestLogprobs_OpenAIPassthrough(t *testing.T) {
+	payload, err := ToOpenAIRequest(logprobsRequest(t, ""))
+	if err != nil {
+		t.Fatalf("ToOpenAIRequest: %v", err)
+	}
+	if !gjson.GetBytes(payload, "logprobs").Bool() || gjson.GetBytes(payload, "top_logprobs").Int() != 3 {
+		t.Errorf("payload = %s, want logprobs and top_logprobs", payload)
+	}
+}
+
+func TestLogprobs_FromGeminiRequest(t *testing.T) {
+	req, err := to_ir.ParseGeminiRequest([]byte(`{"contents":[{"role":"user","parts":[{"text":"hi"}]}],"generationConfig":{"responseLogprobs":true,"logprobs":2}}`))
+	if err != nil {
+		t.Fatalf("ParseGeminiRequest: %v", err)
+	}
+	payload, err := ToOpenAIRequest(req)
+	if err != nil {
+		t.Fatalf("ToOpenAIRequest: %v", err)
+	}
+	if !gjson.GetBytes(payload, "logprobs").Bool() || gjson.GetBytes(payload, "top_logprobs").Int() != 2 {
+		t.Errorf("payload = %s, want logprobs and top_logprobs", payload)
+	}
+}
+
+func TestLogprobs_UnsupportedProviderWarns(t *testing.T) {
+	req := logprobsRequest(t, "")
+	payload, err := (&ClaudeProvider{}).ConvertRequest(req)
+	if err != nil {
+		t.Fatalf("ConvertRequest: %v", err)
+	}
+	if gjson.GetBytes(payload, "logprobs").Exists() {
+		t.Errorf("claude payload carries logprobs: %s", payload)
+	}
+	if len(req.Warnings) != 1 {
+		t.Errorf("warnings = %v, want one", req.Warnings)
+	}
+}
+
+func TestLogprobs_UnsupportedProviderRequired(t *testing.T) {
+	req := logprobsRequest(t, `,"`+ir.LogprobsRequiredField+`":true`)
+	_, err := (&ClaudeProvider{}).ConvertRequest(req)
+	var invalid *ir.InvalidRequestError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("err = %v, want InvalidRequestError", err)
+	}
+	if invalid.StatusCode() != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", invalid.StatusCode())
+	}
+}
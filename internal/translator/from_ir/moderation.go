@@ -0,0 +1,42 @@
+package from_ir
+
+import (
+	"github.com/nghyane/llm-mux/internal/json"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+)
+
+type openaiModerationResult struct {
+	Flagged        bool               `json:"flagged"`
+	Categories     map[string]bool    `json:"categories"`
+	CategoryScores map[string]float64 `json:"category_scores"`
+}
+
+// ToOpenAIModerationResponse renders resp in the OpenAI moderation shape.
+// Every OpenAI category is present; ones the provider did not score are
+// reported as not flagged with a zero score.
+func ToOpenAIModerationResponse(resp *ir.ModerationResponse) ([]byte, error) {
+	results := make([]openaiModerationResult, len(resp.Results))
+	for i, r := range resp.Results {
+		out := openaiModerationResult{
+			Flagged:        r.Flagged,
+			Categories:     make(map[string]bool, len(ir.ModerationCategories)),
+			CategoryScores: make(map[string]float64, len(ir.ModerationCategories)),
+		}
+		for _, name := range ir.ModerationCategories {
+			out.Categories[name] = false
+			out.CategoryScores[name] = 0
+		}
+		for name, hit := range r.Categories {
+			out.Categories[name] = hit
+		}
+		for name, score := range r.Scores {
+			out.CategoryScores[name] = score
+		}
+		results[i] = out
+	}
+	return json.Marshal(map[string]any{
+		"id":      resp.ID,
+		"model":   resp.Model,
+		"results": results,
+	})
+}
@@ -21,6 +21,7 @@ func ToOllamaRequest(req *ir.UnifiedChatRequest) ([]byte, error) {
 }
 
 func convertToOllamaChatRequest(req *ir.UnifiedChatRequest) ([]byte, error) {
+	ir.DropToolResultMedia(req, "ollama")
 	m := map[string]any{"model": req.Model, "messages": []any{}, "stream": req.Metadata["stream"] == true, "options": buildOllamaOptions(req)}
 	for _, msg := range req.Messages {
 		if mo := convertMessageToOllama(msg); mo != nil {
@@ -56,7 +57,14 @@ func convertToOllamaGenerateRequest(req *ir.UnifiedChatRequest) ([]byte, error)
 	for _, msg := range req.Messages {
 		switch msg.Role {
 		case ir.RoleSystem:
-			sp = ir.CombineTextParts(msg)
+			// Generate has a single system field, so every system message
+			// is merged into it in order.
+			if t := ir.CombineTextParts(msg); t != "" {
+				if sp != "" {
+					sp += ir.SystemPromptSeparator
+				}
+				sp += t
+			}
 		case ir.RoleUser:
 			up = ir.CombineTextParts(msg)
 			for _, p := range msg.Content {
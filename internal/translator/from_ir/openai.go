@@ -28,6 +28,7 @@ func ToOpenAIRequestFmt(req *ir.UnifiedChatRequest, format OpenAIRequestFormat)
 }
 
 func convertToChatCompletionsRequest(req *ir.UnifiedChatRequest) ([]byte, error) {
+	ir.DropToolResultMedia(req, "openai")
 	m := map[string]any{"model": req.Model, "messages": []any{}}
 	if req.Temperature != nil {
 		m["temperature"] = *req.Temperature
@@ -41,10 +42,18 @@ func convertToChatCompletionsRequest(req *ir.UnifiedChatRequest) ([]byte, error)
 	if len(req.StopSequences) > 0 {
 		m["stop"] = req.StopSequences
 	}
+	if ir.WantsLogprobs(req) {
+		m["logprobs"] = true
+		if req.TopLogprobs != nil {
+			m["top_logprobs"] = *req.TopLogprobs
+		}
+	}
 	if req.Prediction != nil && req.Prediction.Content != "" {
 		m["prediction"] = map[string]any{"type": req.Prediction.Type, "content": req.Prediction.Content}
 	}
-	if req.Thinking != nil && req.Thinking.IncludeThoughts {
+	if req.Thinking != nil && req.Thinking.Effort != "" && req.Thinking.Effort != ir.ReasoningEffortNone {
+		m["reasoning_effort"] = req.Thinking.Effort
+	} else if req.Thinking != nil && req.Thinking.IncludeThoughts {
 		b := 0
 		if req.Thinking.ThinkingBudget != nil {
 			b = int(*req.Thinking.ThinkingBudget)
@@ -106,14 +115,16 @@ func convertToChatCompletionsRequest(req *ir.UnifiedChatRequest) ([]byte, error)
 		m["tools"] = tools
 	}
 
-	if req.ToolChoice == "function" && req.ToolChoiceFunction != "" {
-		tc := map[string]any{"type": "function", "function": map[string]any{"name": req.ToolChoiceFunction}}
-		if len(req.AllowedTools) > 0 {
-			tc["allowed_tools"] = req.AllowedTools
-		}
-		m["tool_choice"] = tc
+	if name := ir.ForcedToolName(req); name != "" {
+		m["tool_choice"] = map[string]any{"type": "function", "function": map[string]any{"name": name}}
+	} else if len(req.AllowedTools) > 0 && req.ToolChoice != ir.ToolChoiceNone {
+		allowed := make([]any, len(req.AllowedTools))
+		for i, n := range req.AllowedTools {
+			allowed[i] = map[string]any{"type": "function", "function": map[string]any{"name": n}}
+		}
+		m["tool_choice"] = map[string]any{"type": "allowed_tools", "allowed_tools": map[string]any{"mode": openAIToolChoiceMode(req), "tools": allowed}}
 	} else if req.ToolChoice != "" {
-		m["tool_choice"] = req.ToolChoice
+		m["tool_choice"] = openAIToolChoiceMode(req)
 	}
 	if req.ParallelToolCalls != nil {
 		m["parallel_tool_calls"] = *req.ParallelToolCalls
@@ -152,6 +163,7 @@ func convertToChatCompletionsRequest(req *ir.UnifiedChatRequest) ([]byte, error)
 }
 
 func convertToResponsesAPIRequest(req *ir.UnifiedChatRequest) ([]byte, error) {
+	ir.DropToolResultMedia(req, "openai")
 	m := map[string]any{"model": req.Model}
 	if req.Temperature != nil {
 		m["temperature"] = *req.Temperature
@@ -182,8 +194,12 @@ func convertToResponsesAPIRequest(req *ir.UnifiedChatRequest) ([]byte, error) {
 	}
 
 	var input []any
+	skipInstructions := req.Instructions != ""
 	for _, msg := range req.Messages {
-		if msg.Role == ir.RoleSystem && req.Instructions != "" {
+		// Instructions are sent as their own field; other system messages
+		// stay in the input at their position.
+		if skipInstructions && msg.Role == ir.RoleSystem && ir.CombineTextParts(msg) == req.Instructions {
+			skipInstructions = false
 			continue
 		}
 		if item := convertMessageToResponsesInput(msg); item != nil {
@@ -245,10 +261,16 @@ func convertToResponsesAPIRequest(req *ir.UnifiedChatRequest) ([]byte, error) {
 		m["tools"] = tools
 	}
 
-	if req.ToolChoice == "function" && req.ToolChoiceFunction != "" {
-		m["tool_choice"] = map[string]any{"type": "function", "function": map[string]any{"name": req.ToolChoiceFunction}}
+	if name := ir.ForcedToolName(req); name != "" {
+		m["tool_choice"] = map[string]any{"type": "function", "name": name}
+	} else if len(req.AllowedTools) > 0 && req.ToolChoice != ir.ToolChoiceNone {
+		allowed := make([]any, len(req.AllowedTools))
+		for i, n := range req.AllowedTools {
+			allowed[i] = map[string]any{"type": "function", "name": n}
+		}
+		m["tool_choice"] = map[string]any{"type": "allowed_tools", "mode": openAIToolChoiceMode(req), "tools": allowed}
 	} else if req.ToolChoice != "" {
-		m["tool_choice"] = req.ToolChoice
+		m["tool_choice"] = openAIToolChoiceMode(req)
 	}
 	if req.ParallelToolCalls != nil {
 		m["parallel_tool_calls"] = *req.ParallelToolCalls
@@ -475,6 +497,22 @@ func ToOpenAIChatCompletionMeta(ms []ir.Message, us *ir.Usage, model, mid string
 				co["logprobs"] = meta.Logprobs
 			}
 		}
+		if meta != nil && meta.ContentFilter != nil {
+			co["finish_reason"] = ir.OpenAIFinishReasonContentFilter
+			co["content_filter_results"] = meta.ContentFilter.ToMap()
+		}
+		res["choices"] = []any{co}
+	} else if meta != nil && meta.ContentFilter != nil {
+		// Blocked before any content was produced; still answer with a choice
+		co := map[string]any{
+			"index":                  0,
+			"finish_reason":          ir.OpenAIFinishReasonContentFilter,
+			"message":                map[string]any{"role": string(ir.RoleAssistant), "content": nil},
+			"content_filter_results": meta.ContentFilter.ToMap(),
+		}
+		if meta.NativeFinishReason != "" {
+			co["native_finish_reason"] = meta.NativeFinishReason
+		}
 		res["choices"] = []any{co}
 	}
 	if us != nil {
@@ -486,8 +524,25 @@ func ToOpenAIChatCompletionMeta(ms []ir.Message, us *ir.Usage, model, mid string
 	return json.Marshal(res)
 }
 
+// addCacheUsage reports prompt-cache reads and writes alongside the standard
+// usage fields, using Anthropic's names since OpenAI has no cache-write field.
+func addCacheUsage(um map[string]any, us *ir.Usage) {
+	if us == nil {
+		return
+	}
+	if us.CacheReadInputTokens > 0 {
+		um["cache_read_input_tokens"] = us.CacheReadInputTokens
+	}
+	if us.CacheCreationInputTokens > 0 {
+		um["cache_creation_input_tokens"] = us.CacheCreationInputTokens
+	}
+}
+
 func buildUsageMap(us *ir.Usage, meta *ir.OpenAIMeta) map[string]any {
 	um := map[string]any{"prompt_tokens": us.PromptTokens, "completion_tokens": us.CompletionTokens, "total_tokens": us.TotalTokens}
+	if us.Approximate {
+		um["approximate"] = true
+	}
 	pd := map[string]any{}
 	if us.PromptTokensDetails != nil {
 		if us.PromptTokensDetails.CachedTokens > 0 {
@@ -502,6 +557,7 @@ func buildUsageMap(us *ir.Usage, meta *ir.OpenAIMeta) map[string]any {
 	if len(pd) > 0 {
 		um["prompt_tokens_details"] = pd
 	}
+	addCacheUsage(um, us)
 	cd := map[string]any{}
 	var tt int32
 	if meta != nil && meta.ThoughtsTokenCount > 0 {
@@ -540,6 +596,14 @@ func ToOpenAIChunk(ev ir.UnifiedEvent, model, mid string, ci int) ([]byte, error
 	return ToOpenAIChunkMeta(ev, model, mid, ci, nil)
 }
 
+// ToOpenAIUsageChunk builds the trailing chunk OpenAI sends when
+// stream_options.include_usage is set: no choices, only usage.
+func ToOpenAIUsageChunk(us *ir.Usage, model, mid string) []byte {
+	ch := map[string]any{"id": mid, "object": "chat.completion.chunk", "created": time.Now().Unix(), "model": model, "choices": []any{}, "usage": buildUsageMap(us, nil)}
+	jb, _ := json.Marshal(ch)
+	return ir.BuildSSEChunk(jb)
+}
+
 type openaiTextChunk struct {
 	ID      string `json:"id"`
 	Object  string `json:"object"`
@@ -662,6 +726,17 @@ func ToOpenAIChunkMeta(ev ir.UnifiedEvent, model, mid string, ci int, meta *ir.O
 	return ir.BuildSSEChunk(jb), nil
 }
 
+// openAIToolChoiceMode maps the IR tool choice onto OpenAI's mode strings.
+func openAIToolChoiceMode(req *ir.UnifiedChatRequest) string {
+	switch {
+	case ir.RequiresToolCall(req):
+		return ir.ToolChoiceRequired
+	case req.ToolChoice == ir.ToolChoiceNone:
+		return ir.ToolChoiceNone
+	}
+	return ir.ToolChoiceAuto
+}
+
 func convertMessageToOpenAI(msg ir.Message) map[string]any {
 	var res map[string]any
 	switch msg.Role {
@@ -676,13 +751,8 @@ func convertMessageToOpenAI(msg ir.Message) map[string]any {
 	case ir.RoleTool:
 		res = buildOpenAIToolMessage(msg)
 	}
-	if res != nil && msg.CacheControl != nil {
-		cc := map[string]any{"type": msg.CacheControl.Type}
-		if msg.CacheControl.TTL != nil {
-			cc["ttl"] = *msg.CacheControl.TTL
-		}
-		res["cache_control"] = cc
-	}
+	// Cache hints are dropped: OpenAI-format upstreams cache prompts
+	// automatically and reject unknown message fields.
 	return res
 }
 
@@ -803,6 +873,7 @@ func ToResponsesAPIResponse(ms []ir.Message, us *ir.Usage, model string, meta *i
 		if ct > 0 {
 			rum["input_tokens_details"] = map[string]any{"cached_tokens": ct}
 		}
+		addCacheUsage(rum, us)
 		od := map[string]any{}
 		var tt int64
 		if us != nil && us.CompletionTokensDetails != nil && us.CompletionTokensDetails.ReasoningTokens > 0 {
@@ -834,17 +905,45 @@ type ResponsesStreamState struct {
 	ResponseID      string
 	Created         int64
 	Started         bool
+	NextOutputIndex int
 	ReasoningID     string
+	ReasoningIndex  int
 	MsgID           string
+	MsgIndex        int
 	TextBuffer      strings.Builder
 	ReasoningBuffer strings.Builder
-	FuncCallIDs     map[int]string
-	FuncNames       map[int]string
-	FuncArgsBuffer  map[int]*strings.Builder
+	FuncCalls       []*ResponsesFuncCall
+	funcByID        map[string]*ResponsesFuncCall
+	funcByIndex     map[int]*ResponsesFuncCall
+}
+
+// ResponsesFuncCall is a function_call output item whose arguments are
+// still streaming.
+type ResponsesFuncCall struct {
+	ItemID      string
+	CallID      string
+	Name        string
+	OutputIndex int
+	Args        strings.Builder
 }
 
 func NewResponsesStreamState() *ResponsesStreamState {
-	return &ResponsesStreamState{FuncCallIDs: make(map[int]string), FuncNames: make(map[int]string), FuncArgsBuffer: make(map[int]*strings.Builder)}
+	return &ResponsesStreamState{funcByID: make(map[string]*ResponsesFuncCall), funcByIndex: make(map[int]*ResponsesFuncCall)}
+}
+
+// funcCall returns the function call ev continues, or nil when ev starts a
+// new one. Deltas without an ID continue the call at their stream index.
+func (s *ResponsesStreamState) funcCall(ev ir.UnifiedEvent) *ResponsesFuncCall {
+	if ev.ToolCall.ID != "" {
+		return s.funcByID[ev.ToolCall.ID]
+	}
+	if fc := s.funcByIndex[ev.ToolCallIndex]; fc != nil {
+		return fc
+	}
+	if ev.ToolCall.Name == "" && len(s.FuncCalls) > 0 {
+		return s.FuncCalls[len(s.FuncCalls)-1]
+	}
+	return nil
 }
 
 func formatResponsesSSE(et string, jb []byte) string {
@@ -867,105 +966,143 @@ func ToResponsesAPIChunk(ev ir.UnifiedEvent, model string, s *ResponsesStreamSta
 		s.ResponseID, s.Created = fmt.Sprintf("resp_%d", time.Now().UnixNano()), time.Now().Unix()
 	}
 	ns := func() int { s.Seq++; return s.Seq }
+	emit := func(out []string, et string, fields map[string]any) []string {
+		fields["type"], fields["sequence_number"] = et, ns()
+		b, _ := json.Marshal(fields)
+		return append(out, formatResponsesSSE(et, b))
+	}
 	out := make([]string, 0, 4)
 	if !s.Started {
 		for _, t := range []string{"response.created", "response.in_progress"} {
-			b, _ := json.Marshal(map[string]any{"type": t, "sequence_number": ns(), "response": map[string]any{"id": s.ResponseID, "object": "response", "created_at": s.Created, "status": "in_progress"}})
-			out = append(out, formatResponsesSSE(t, b))
+			out = emit(out, t, map[string]any{"response": map[string]any{"id": s.ResponseID, "object": "response", "created_at": s.Created, "status": "in_progress", "model": model, "output": []any{}}})
 		}
 		s.Started = true
 	}
 	switch ev.Type {
 	case ir.EventTypeToken:
+		if ev.Content == "" {
+			break
+		}
 		if s.MsgID == "" {
-			s.MsgID = fmt.Sprintf("msg_%s", s.ResponseID)
-			b1, _ := json.Marshal(map[string]any{"type": "response.output_item.added", "sequence_number": ns(), "output_index": 0, "item": map[string]any{"id": s.MsgID, "type": "message", "status": "in_progress", "role": "assistant", "content": []any{}}})
-			out = append(out, formatResponsesSSE("response.output_item.added", b1))
-			b2, _ := json.Marshal(map[string]any{"type": "response.content_part.added", "sequence_number": ns(), "item_id": s.MsgID, "output_index": 0, "content_index": 0, "part": map[string]any{"type": "output_text", "text": ""}})
-			out = append(out, formatResponsesSSE("response.content_part.added", b2))
+			s.MsgID, s.MsgIndex = fmt.Sprintf("msg_%s", s.ResponseID), s.NextOutputIndex
+			s.NextOutputIndex++
+			out = emit(out, "response.output_item.added", map[string]any{"output_index": s.MsgIndex, "item": map[string]any{"id": s.MsgID, "type": "message", "status": "in_progress", "role": "assistant", "content": []any{}}})
+			out = emit(out, "response.content_part.added", map[string]any{"item_id": s.MsgID, "output_index": s.MsgIndex, "content_index": 0, "part": map[string]any{"type": "output_text", "text": "", "annotations": []any{}}})
 		}
 		s.TextBuffer.WriteString(ev.Content)
-		b, _ := json.Marshal(map[string]any{"type": "response.output_text.delta", "sequence_number": ns(), "item_id": s.MsgID, "output_index": 0, "content_index": 0, "delta": ev.Content})
-		out = append(out, formatResponsesSSE("response.output_text.delta", b))
+		out = emit(out, "response.output_text.delta", map[string]any{"item_id": s.MsgID, "output_index": s.MsgIndex, "content_index": 0, "delta": ev.Content})
 	case ir.EventTypeReasoning, ir.EventTypeReasoningSummary:
 		t := ev.Reasoning
 		if ev.Type == ir.EventTypeReasoningSummary {
 			t = ev.ReasoningSummary
 		}
+		if t == "" {
+			break
+		}
 		if s.ReasoningID == "" {
-			s.ReasoningID = fmt.Sprintf("rs_%s", s.ResponseID)
-			b, _ := json.Marshal(map[string]any{"type": "response.output_item.added", "sequence_number": ns(), "output_index": 0, "item": map[string]any{"id": s.ReasoningID, "type": "reasoning", "status": "in_progress", "summary": []any{}}})
-			out = append(out, formatResponsesSSE("response.output_item.added", b))
+			s.ReasoningID, s.ReasoningIndex = fmt.Sprintf("rs_%s", s.ResponseID), s.NextOutputIndex
+			s.NextOutputIndex++
+			out = emit(out, "response.output_item.added", map[string]any{"output_index": s.ReasoningIndex, "item": map[string]any{"id": s.ReasoningID, "type": "reasoning", "status": "in_progress", "summary": []any{}}})
 		}
 		s.ReasoningBuffer.WriteString(t)
-		b, _ := json.Marshal(map[string]any{"type": "response.reasoning_summary_text.delta", "sequence_number": ns(), "item_id": s.ReasoningID, "output_index": 0, "content_index": 0, "delta": t})
-		out = append(out, formatResponsesSSE("response.reasoning_summary_text.delta", b))
-	case ir.EventTypeToolCall:
-		idx := ev.ToolCallIndex
-		if _, ok := s.FuncCallIDs[idx]; !ok {
-			s.FuncCallIDs[idx], s.FuncNames[idx] = fmt.Sprintf("fc_%s", ev.ToolCall.ID), ev.ToolCall.Name
-			b, _ := json.Marshal(map[string]any{"type": "response.output_item.added", "sequence_number": ns(), "output_index": idx, "item": map[string]any{"id": s.FuncCallIDs[idx], "type": "function_call", "status": "in_progress", "call_id": ev.ToolCall.ID, "name": ev.ToolCall.Name, "arguments": ""}})
-			out = append(out, formatResponsesSSE("response.output_item.added", b))
+		out = emit(out, "response.reasoning_summary_text.delta", map[string]any{"item_id": s.ReasoningID, "output_index": s.ReasoningIndex, "summary_index": 0, "delta": t})
+	case ir.EventTypeToolCall, ir.EventTypeToolCallDelta:
+		if ev.ToolCall == nil {
+			break
+		}
+		fc := s.funcCall(ev)
+		if fc == nil {
+			fc = &ResponsesFuncCall{ItemID: fmt.Sprintf("fc_%s", ev.ToolCall.ID), CallID: ev.ToolCall.ID, Name: ev.ToolCall.Name, OutputIndex: s.NextOutputIndex}
+			s.NextOutputIndex++
+			s.FuncCalls = append(s.FuncCalls, fc)
+			if fc.CallID != "" {
+				s.funcByID[fc.CallID] = fc
+			}
+			s.funcByIndex[ev.ToolCallIndex] = fc
+			out = emit(out, "response.output_item.added", map[string]any{"output_index": fc.OutputIndex, "item": map[string]any{"id": fc.ItemID, "type": "function_call", "status": "in_progress", "call_id": fc.CallID, "name": fc.Name, "arguments": ""}})
+		} else if ev.Type == ir.EventTypeToolCall && ev.ToolCall.ID != "" && ev.ToolCall.Name != "" {
+			// A complete call for one already streaming carries its final
+			// arguments; they are reported when the response finishes.
+			fc.Args.Reset()
+			fc.Args.WriteString(ev.ToolCall.Args)
+			break
 		}
 		if ev.ToolCall.Args != "" {
-			b, _ := json.Marshal(map[string]any{"type": "response.function_call_arguments.delta", "sequence_number": ns(), "item_id": s.FuncCallIDs[idx], "output_index": idx, "delta": ev.ToolCall.Args})
-			out = append(out, formatResponsesSSE("response.function_call_arguments.delta", b))
+			fc.Args.WriteString(ev.ToolCall.Args)
+			out = emit(out, "response.function_call_arguments.delta", map[string]any{"item_id": fc.ItemID, "output_index": fc.OutputIndex, "delta": ev.ToolCall.Args})
 		}
-		b, _ := json.Marshal(map[string]any{"type": "response.output_item.done", "sequence_number": ns(), "item_id": s.FuncCallIDs[idx], "output_index": idx, "item": map[string]any{"id": s.FuncCallIDs[idx], "type": "function_call", "status": "completed", "call_id": ev.ToolCall.ID, "name": ev.ToolCall.Name, "arguments": ev.ToolCall.Args}})
-		out = append(out, formatResponsesSSE("response.output_item.done", b))
-	case ir.EventTypeToolCallDelta:
-		idx := ev.ToolCallIndex
-		if _, ok := s.FuncCallIDs[idx]; !ok {
-			s.FuncCallIDs[idx] = fmt.Sprintf("fc_%s", ev.ToolCall.ID)
-			b, _ := json.Marshal(map[string]any{"type": "response.output_item.added", "sequence_number": ns(), "output_index": idx, "item": map[string]any{"id": s.FuncCallIDs[idx], "type": "function_call", "status": "in_progress", "call_id": ev.ToolCall.ID, "name": "", "arguments": ""}})
-			out = append(out, formatResponsesSSE("response.output_item.added", b))
-		}
-		if s.FuncArgsBuffer[idx] == nil {
-			s.FuncArgsBuffer[idx] = &strings.Builder{}
-		}
-		s.FuncArgsBuffer[idx].WriteString(ev.ToolCall.Args)
-		b, _ := json.Marshal(map[string]any{"type": "response.function_call_arguments.delta", "sequence_number": ns(), "item_id": s.FuncCallIDs[idx], "output_index": idx, "delta": ev.ToolCall.Args})
-		out = append(out, formatResponsesSSE("response.function_call_arguments.delta", b))
 	case ir.EventTypeFinish:
-		t, r := s.TextBuffer.String(), s.ReasoningBuffer.String()
-		if s.MsgID != "" {
-			b1, _ := json.Marshal(map[string]any{"type": "response.content_part.done", "sequence_number": ns(), "item_id": s.MsgID, "output_index": 0, "content_index": 0, "part": map[string]any{"type": "output_text", "text": t}})
-			out = append(out, formatResponsesSSE("response.content_part.done", b1))
-			b2, _ := json.Marshal(map[string]any{"type": "response.output_item.done", "sequence_number": ns(), "output_index": 0, "item": map[string]any{"id": s.MsgID, "type": "message", "status": "completed", "role": "assistant", "content": []any{map[string]any{"type": "output_text", "text": t}}}})
-			out = append(out, formatResponsesSSE("response.output_item.done", b2))
-		}
+		output := make([]any, s.NextOutputIndex)
 		if s.ReasoningID != "" {
-			b, _ := json.Marshal(map[string]any{"type": "response.output_item.done", "sequence_number": ns(), "output_index": 0, "item": map[string]any{"id": s.ReasoningID, "type": "reasoning", "status": "completed", "summary": []any{map[string]any{"type": "summary_text", "text": r}}}})
-			out = append(out, formatResponsesSSE("response.output_item.done", b))
+			item := map[string]any{"id": s.ReasoningID, "type": "reasoning", "status": "completed", "summary": []any{map[string]any{"type": "summary_text", "text": s.ReasoningBuffer.String()}}}
+			output[s.ReasoningIndex] = item
+			out = emit(out, "response.output_item.done", map[string]any{"output_index": s.ReasoningIndex, "item": item})
 		}
-		um := map[string]any{}
-		if ev.Usage != nil {
-			um = map[string]any{"input_tokens": ev.Usage.PromptTokens, "output_tokens": ev.Usage.CompletionTokens, "total_tokens": ev.Usage.TotalTokens}
-			var ct int64
-			if ev.Usage.PromptTokensDetails != nil && ev.Usage.PromptTokensDetails.CachedTokens > 0 {
-				ct = ev.Usage.PromptTokensDetails.CachedTokens
-			} else if ev.Usage.CachedTokens > 0 {
-				ct = ev.Usage.CachedTokens
-			}
-			if ct > 0 {
-				um["input_tokens_details"] = map[string]any{"cached_tokens": ct}
-			}
-			var rt int64
-			if ev.Usage.CompletionTokensDetails != nil && ev.Usage.CompletionTokensDetails.ReasoningTokens > 0 {
-				rt = ev.Usage.CompletionTokensDetails.ReasoningTokens
-			} else if ev.Usage.ThoughtsTokenCount > 0 {
-				rt = int64(ev.Usage.ThoughtsTokenCount)
-			}
-			if rt > 0 {
-				um["output_tokens_details"] = map[string]any{"reasoning_tokens": rt}
-			}
-		}
-		b, _ := json.Marshal(map[string]any{"type": "response.done", "sequence_number": ns(), "response": map[string]any{"id": s.ResponseID, "object": "response", "created_at": s.Created, "status": "completed", "usage": um}})
-		out = append(out, formatResponsesSSE("response.done", b))
+		if s.MsgID != "" {
+			part := map[string]any{"type": "output_text", "text": s.TextBuffer.String(), "annotations": []any{}}
+			out = emit(out, "response.output_text.done", map[string]any{"item_id": s.MsgID, "output_index": s.MsgIndex, "content_index": 0, "text": part["text"]})
+			out = emit(out, "response.content_part.done", map[string]any{"item_id": s.MsgID, "output_index": s.MsgIndex, "content_index": 0, "part": part})
+			item := map[string]any{"id": s.MsgID, "type": "message", "status": "completed", "role": "assistant", "content": []any{part}}
+			output[s.MsgIndex] = item
+			out = emit(out, "response.output_item.done", map[string]any{"output_index": s.MsgIndex, "item": item})
+		}
+		for _, fc := range s.FuncCalls {
+			args := fc.Args.String()
+			out = emit(out, "response.function_call_arguments.done", map[string]any{"item_id": fc.ItemID, "output_index": fc.OutputIndex, "arguments": args})
+			item := map[string]any{"id": fc.ItemID, "type": "function_call", "status": "completed", "call_id": fc.CallID, "name": fc.Name, "arguments": args}
+			output[fc.OutputIndex] = item
+			out = emit(out, "response.output_item.done", map[string]any{"output_index": fc.OutputIndex, "item": item})
+		}
+		resp := map[string]any{"id": s.ResponseID, "object": "response", "created_at": s.Created, "status": "completed", "model": model, "output": output, "usage": buildResponsesUsageMap(ev.Usage)}
+		et := "response.completed"
+		if reason := responsesIncompleteReason(ev.FinishReason); reason != "" {
+			et, resp["status"], resp["incomplete_details"] = "response.incomplete", "incomplete", map[string]any{"reason": reason}
+		}
+		out = emit(out, et, map[string]any{"response": resp})
 	}
 	return out, nil
 }
 
+// responsesIncompleteReason returns the incomplete_details reason for a
+// response that stopped early, or "" when it completed.
+func responsesIncompleteReason(reason ir.FinishReason) string {
+	switch {
+	case reason == ir.FinishReasonMaxTokens:
+		return "max_output_tokens"
+	case ir.IsSafetyFinish(reason):
+		return "content_filter"
+	}
+	return ""
+}
+
+// buildResponsesUsageMap renders usage in the Responses API shape.
+func buildResponsesUsageMap(us *ir.Usage) map[string]any {
+	if us == nil {
+		return map[string]any{}
+	}
+	um := map[string]any{"input_tokens": us.PromptTokens, "output_tokens": us.CompletionTokens, "total_tokens": us.TotalTokens}
+	var ct int64
+	if us.PromptTokensDetails != nil && us.PromptTokensDetails.CachedTokens > 0 {
+		ct = us.PromptTokensDetails.CachedTokens
+	} else if us.CachedTokens > 0 {
+		ct = us.CachedTokens
+	}
+	if ct > 0 {
+		um["input_tokens_details"] = map[string]any{"cached_tokens": ct}
+	}
+	addCacheUsage(um, us)
+	var rt int64
+	if us.CompletionTokensDetails != nil && us.CompletionTokensDetails.ReasoningTokens > 0 {
+		rt = us.CompletionTokensDetails.ReasoningTokens
+	} else if us.ThoughtsTokenCount > 0 {
+		rt = int64(us.ThoughtsTokenCount)
+	}
+	if rt > 0 {
+		um["output_tokens_details"] = map[string]any{"reasoning_tokens": rt}
+	}
+	return um
+}
+
 func buildOpenAIGroundingMetadata(gm *ir.GroundingMetadata) map[string]any {
 	if gm == nil {
 		return nil
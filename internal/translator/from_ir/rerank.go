@@ -0,0 +1,59 @@
+package from_ir
+
+import (
+	"github.com/nghyane/llm-mux/internal/json"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+)
+
+// ToRerankRequest builds a Cohere-style rerank request body. Documents are
+// sent as plain strings and never echoed back by the upstream.
+func ToRerankRequest(req *ir.RerankRequest) ([]byte, error) {
+	body := map[string]any{
+		"model":     req.Model,
+		"query":     req.Query,
+		"documents": req.Documents,
+	}
+	if req.TopN > 0 {
+		body["top_n"] = req.TopN
+	}
+	return json.Marshal(body)
+}
+
+type rerankDocument struct {
+	Text string `json:"text"`
+}
+
+type rerankResult struct {
+	Index          int             `json:"index"`
+	RelevanceScore float64         `json:"relevance_score"`
+	Document       *rerankDocument `json:"document,omitempty"`
+}
+
+// ToRerankResponse renders resp in the /v1/rerank response shape. Results
+// keep the order of resp, are cut to req.TopN when the upstream returned more,
+// and carry the document text when req.ReturnDocuments is set.
+func ToRerankResponse(req *ir.RerankRequest, resp *ir.RerankResponse) ([]byte, error) {
+	results := make([]rerankResult, 0, len(resp.Results))
+	for _, r := range resp.Results {
+		if r.Index < 0 || r.Index >= len(req.Documents) {
+			continue
+		}
+		if req.TopN > 0 && len(results) == req.TopN {
+			break
+		}
+		out := rerankResult{Index: r.Index, RelevanceScore: r.Score}
+		if req.ReturnDocuments {
+			out.Document = &rerankDocument{Text: req.Documents[r.Index]}
+		}
+		results = append(results, out)
+	}
+	body := map[string]any{
+		"id":      resp.ID,
+		"model":   resp.Model,
+		"results": results,
+	}
+	if us := resp.Usage; us != nil {
+		body["usage"] = map[string]any{"total_tokens": us.TotalTokens}
+	}
+	return json.Marshal(body)
+}
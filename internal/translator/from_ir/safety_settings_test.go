@@ -0,0 +1,67 @@
+package from_ir
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/nghyane/llm-mux/internal/translator/to_ir"
+	"github.com/tidwall/gjson"
+)
+
+func TestSafetySettings_Gemini(t *testing.T) {
+	body := `{"model":"m","messages":[{"role":"user","content":"hi"}],"safety_settings":[
+		{"category":"HARM_CATEGORY_HARASSMENT","threshold":"BLOCK_ONLY_HIGH"},
+		{"category":"harm_category_dangerous_content","threshold":"block_medium_and_above"}]}`
+	req, err := to_ir.ParseOpenAIRequest([]byte(body))
+	if err != nil {
+		t.Fatalf("ParseOpenAIRequest: %v", err)
+	}
+	payload, err := (&GeminiProvider{}).ConvertRequest(req)
+	if err != nil {
+		t.Fatalf("ConvertRequest: %v", err)
+	}
+	got := map[string]string{}
+	for _, s := range gjson.GetBytes(payload, "safetySettings").Array() {
+		got[s.Get("category").String()] = s.Get("threshold").String()
+	}
+	want := map[string]string{
+		"HARM_CATEGORY_HARASSMENT":        "BLOCK_ONLY_HIGH",
+		"HARM_CATEGORY_DANGEROUS_CONTENT": "BLOCK_MEDIUM_AND_ABOVE",
+		"HARM_CATEGORY_HATE_SPEECH":       "OFF",
+		"HARM_CATEGORY_SEXUALLY_EXPLICIT": "OFF",
+		"HARM_CATEGORY_CIVIC_INTEGRITY":   "BLOCK_NONE",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("safetySettings = %v, want %v", got, want)
+	}
+	for category, threshold := range want {
+		if got[category] != threshold {
+			t.Errorf("%s = %q, want %q", category, got[category], threshold)
+		}
+	}
+
+	claude, err := (&ClaudeProvider{}).ConvertRequest(req)
+	if err != nil {
+		t.Fatalf("claude ConvertRequest: %v", err)
+	}
+	if strings.Contains(string(claude), "HARM_CATEGORY") {
+		t.Errorf("safety settings reached a non-Gemini request: %s", claude)
+	}
+}
+
+func TestSafetySettings_Invalid(t *testing.T) {
+	for _, settings := range []string{
+		`[{"category":"HARM_CATEGORY_VIOLENCE","threshold":"OFF"}]`,
+		`[{"category":"HARM_CATEGORY_HARASSMENT","threshold":"BLOCK_SOME"}]`,
+		`[{"category":"HARM_CATEGORY_HARASSMENT"}]`,
+		`{"HARM_CATEGORY_HARASSMENT":"OFF"}`,
+	} {
+		_, err := to_ir.ParseOpenAIRequest([]byte(`{"model":"m","messages":[{"role":"user","content":"hi"}],"safety_settings":` + settings + `}`))
+		var invalid *ir.InvalidRequestError
+		if !errors.As(err, &invalid) || !strings.HasPrefix(invalid.Message, ir.SafetySettingsField) {
+			t.Errorf("%s: err = %v, want an invalid safety_settings error", settings, err)
+		}
+	}
+}
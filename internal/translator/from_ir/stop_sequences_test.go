@@ -0,0 +1,110 @@
+package from_ir
+
+import (
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/nghyane/llm-mux/internal/translator/to_ir"
+	"github.com/tidwall/gjson"
+)
+
+// stopRequest builds an OpenAI request with the given stop JSON.
+func stopRequest(t *testing.T, stop string) *ir.UnifiedChatRequest {
+	t.Helper()
+	body := `{"model":"m","messages":[{"role":"user","content":"hi"}],"stop":` + stop + `}`
+	req, err := to_ir.ParseOpenAIRequest([]byte(body))
+	if err != nil {
+		t.Fatalf("ParseOpenAIRequest: %v", err)
+	}
+	return req
+}
+
+func TestStopSequences_Request(t *testing.T) {
+	tests := []struct {
+		name string
+		stop string
+		want string
+	}{
+		{name: "string", stop: `"END"`, want: `["END"]`},
+		{name: "array", stop: `["END","STOP"]`, want: `["END","STOP"]`},
+		{name: "empty entries dropped", stop: `["END",""]`, want: `["END"]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claude, err := (&ClaudeProvider{}).ConvertRequest(stopRequest(t, tt.stop))
+			if err != nil {
+				t.Fatalf("claude ConvertRequest: %v", err)
+			}
+			if got := gjson.GetBytes(claude, "stop_sequences").Raw; got != tt.want {
+				t.Errorf("claude stop_sequences = %s, want %s", got, tt.want)
+			}
+
+			gemini, err := (&GeminiProvider{}).ConvertRequest(stopRequest(t, tt.stop))
+			if err != nil {
+				t.Fatalf("gemini ConvertRequest: %v", err)
+			}
+			if got := gjson.GetBytes(gemini, "generationConfig.stopSequences").Raw; got != tt.want {
+				t.Errorf("gemini stopSequences = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStopSequences_GeminiLimit(t *testing.T) {
+	req := stopRequest(t, `["a","b","c","d","e","f","g"]`)
+	payload, err := (&GeminiProvider{}).ConvertRequest(req)
+	if err != nil {
+		t.Fatalf("ConvertRequest: %v", err)
+	}
+	if got, want := gjson.GetBytes(payload, "generationConfig.stopSequences").Raw, `["a","b","c","d","e"]`; got != want {
+		t.Errorf("stopSequences = %s, want %s", got, want)
+	}
+	if len(req.Warnings) != 1 {
+		t.Fatalf("warnings = %v, want one", req.Warnings)
+	}
+
+	// Anthropic has no comparable cap, so the full list goes through.
+	claude, err := (&ClaudeProvider{}).ConvertRequest(stopRequest(t, `["a","b","c","d","e","f","g"]`))
+	if err != nil {
+		t.Fatalf("claude ConvertRequest: %v", err)
+	}
+	if n := len(gjson.GetBytes(claude, "stop_sequences").Array()); n != 7 {
+		t.Errorf("claude stop_sequences = %d entries, want 7", n)
+	}
+}
+
+func TestStopSequences_ParseNative(t *testing.T) {
+	claude, err := to_ir.ParseClaudeRequest([]byte(`{"model":"m","max_tokens":10,"messages":[{"role":"user","content":"hi"}],"stop_sequences":["END"]}`))
+	if err != nil {
+		t.Fatalf("ParseClaudeRequest: %v", err)
+	}
+	openai, err := ToOpenAIRequest(claude)
+	if err != nil {
+		t.Fatalf("ToOpenAIRequest: %v", err)
+	}
+	if got := gjson.GetBytes(openai, "stop").Raw; got != `["END"]` {
+		t.Errorf("openai stop = %s, want [\"END\"]", got)
+	}
+}
+
+func TestStopSequences_FinishReason(t *testing.T) {
+	events, err := to_ir.ParseClaudeChunk([]byte(`data: {"type":"message_delta","delta":{"stop_reason":"stop_sequence","stop_sequence":"END"},"usage":{"output_tokens":3}}`))
+	if err != nil {
+		t.Fatalf("ParseClaudeChunk: %v", err)
+	}
+	if len(events) != 1 || events[0].FinishReason != ir.FinishReasonStopSequence {
+		t.Fatalf("events = %+v, want one stop_sequence finish", events)
+	}
+
+	chunk, err := ToOpenAIChunk(events[0], "m", "chatcmpl-1", 0)
+	if err != nil {
+		t.Fatalf("ToOpenAIChunk: %v", err)
+	}
+	if got := gjson.GetBytes(ir.ExtractSSEData(chunk), "choices.0.finish_reason").String(); got != "stop" {
+		t.Errorf("finish_reason = %q, want stop", got)
+	}
+
+	if got := ir.MapFinishReasonToClaude(events[0].FinishReason); got != "stop_sequence" {
+		t.Errorf("claude stop_reason = %q, want stop_sequence", got)
+	}
+}
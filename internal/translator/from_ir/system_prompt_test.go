@@ -0,0 +1,88 @@
+package from_ir
+
+import (
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/nghyane/llm-mux/internal/translator/to_ir"
+	"github.com/tidwall/gjson"
+)
+
+// systemPromptRequest is an OpenAI request with two leading system messages
+// and one that arrives after the first exchange.
+func systemPromptRequest(t *testing.T) *ir.UnifiedChatRequest {
+	t.Helper()
+	body := `{"model":"m","messages":[` +
+		`{"role":"system","content":"You are helpful."},` +
+		`{"role":"system","content":"Be brief."},` +
+		`{"role":"user","content":"hi"},` +
+		`{"role":"assistant","content":"hello"},` +
+		`{"role":"system","content":"Answer in French."},` +
+		`{"role":"user","content":"how are you?"}]}`
+	req, err := to_ir.ParseOpenAIRequest([]byte(body))
+	if err != nil {
+		t.Fatalf("ParseOpenAIRequest: %v", err)
+	}
+	return req
+}
+
+const (
+	wantMergedSystem = "You are helpful.\n\nBe brief."
+	wantLateSystem   = ir.LateSystemPrefix + "Answer in French."
+)
+
+func TestClaudeProvider_SystemMessages(t *testing.T) {
+	payload, err := (&ClaudeProvider{}).ConvertRequest(systemPromptRequest(t))
+	if err != nil {
+		t.Fatalf("ConvertRequest: %v", err)
+	}
+	parsed := gjson.ParseBytes(payload)
+
+	if got := parsed.Get("system").String(); got != wantMergedSystem {
+		t.Errorf("system = %q, want %q", got, wantMergedSystem)
+	}
+	msgs := parsed.Get("messages").Array()
+	if len(msgs) != 4 {
+		t.Fatalf("messages = %d, want 4: %s", len(msgs), parsed.Get("messages").Raw)
+	}
+	if msgs[2].Get("role").String() != "user" || msgs[2].Get("content.0.text").String() != wantLateSystem {
+		t.Errorf("messages[2] = %s, want late system as user turn", msgs[2].Raw)
+	}
+}
+
+func TestGeminiProvider_SystemMessages(t *testing.T) {
+	payload, err := (&GeminiProvider{}).ConvertRequest(systemPromptRequest(t))
+	if err != nil {
+		t.Fatalf("ConvertRequest: %v", err)
+	}
+	parsed := gjson.ParseBytes(payload)
+
+	if got := parsed.Get("systemInstruction.parts.0.text").String(); got != wantMergedSystem {
+		t.Errorf("systemInstruction = %q, want %q", got, wantMergedSystem)
+	}
+	found := false
+	for _, c := range parsed.Get("contents").Array() {
+		for _, p := range c.Get("parts").Array() {
+			if p.Get("text").String() == wantLateSystem {
+				found = c.Get("role").String() == "user"
+			}
+		}
+	}
+	if !found {
+		t.Errorf("late system message not kept as user turn: %s", parsed.Get("contents").Raw)
+	}
+}
+
+func TestToOpenAIRequest_SystemMessagesInPlace(t *testing.T) {
+	payload, err := ToOpenAIRequest(systemPromptRequest(t))
+	if err != nil {
+		t.Fatalf("ConvertRequest: %v", err)
+	}
+	msgs := gjson.GetBytes(payload, "messages").Array()
+	if len(msgs) != 6 {
+		t.Fatalf("messages = %d, want 6", len(msgs))
+	}
+	if msgs[4].Get("role").String() != "system" {
+		t.Errorf("messages[4].role = %s, want system", msgs[4].Get("role").String())
+	}
+}
@@ -0,0 +1,172 @@
+package from_ir
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/nghyane/llm-mux/internal/translator/to_ir"
+	"github.com/tidwall/gjson"
+)
+
+// toolChoiceRequest builds an OpenAI request with two tools and the given
+// tool_choice JSON.
+func toolChoiceRequest(t *testing.T, toolChoice string) *ir.UnifiedChatRequest {
+	t.Helper()
+	body := `{"model":"m","messages":[{"role":"user","content":"hi"}],"tools":[` +
+		`{"type":"function","function":{"name":"get_weather","parameters":{"type":"object"}}},` +
+		`{"type":"function","function":{"name":"get_time","parameters":{"type":"object"}}}],` +
+		`"tool_choice":` + toolChoice + `}`
+	req, err := to_ir.ParseOpenAIRequest([]byte(body))
+	if err != nil {
+		t.Fatalf("ParseOpenAIRequest: %v", err)
+	}
+	return req
+}
+
+var toolChoiceVariants = []struct {
+	name   string
+	choice string
+	claude string
+	gemini string
+	openai string
+}{
+	{
+		name:   "auto",
+		choice: `"auto"`,
+		claude: `{"type":"auto"}`,
+		gemini: `{"mode":"AUTO"}`,
+		openai: `"auto"`,
+	},
+	{
+		name:   "none",
+		choice: `"none"`,
+		claude: `{"type":"none"}`,
+		gemini: `{"mode":"NONE"}`,
+		openai: `"none"`,
+	},
+	{
+		name:   "required",
+		choice: `"required"`,
+		claude: `{"type":"any"}`,
+		gemini: `{"mode":"ANY"}`,
+		openai: `"required"`,
+	},
+	{
+		name:   "named function",
+		choice: `{"type":"function","function":{"name":"get_weather"}}`,
+		claude: `{"type":"tool","name":"get_weather"}`,
+		gemini: `{"mode":"ANY","allowedFunctionNames":["get_weather"]}`,
+		openai: `{"type":"function","function":{"name":"get_weather"}}`,
+	},
+	{
+		name:   "responses named function",
+		choice: `{"type":"function","name":"get_time"}`,
+		claude: `{"type":"tool","name":"get_time"}`,
+		gemini: `{"mode":"ANY","allowedFunctionNames":["get_time"]}`,
+		openai: `{"type":"function","function":{"name":"get_time"}}`,
+	},
+	{
+		name:   "allowed tools required",
+		choice: `{"type":"allowed_tools","allowed_tools":{"mode":"required","tools":[{"type":"function","function":{"name":"get_weather"}},{"type":"function","function":{"name":"get_time"}}]}}`,
+		claude: `{"type":"any"}`,
+		gemini: `{"mode":"ANY","allowedFunctionNames":["get_weather","get_time"]}`,
+		openai: `{"type":"allowed_tools","allowed_tools":{"mode":"required","tools":[{"type":"function","function":{"name":"get_weather"}},{"type":"function","function":{"name":"get_time"}}]}}`,
+	},
+}
+
+func TestToolChoice_Claude(t *testing.T) {
+	for _, tc := range toolChoiceVariants {
+		t.Run(tc.name, func(t *testing.T) {
+			payload, err := (&ClaudeProvider{}).ConvertRequest(toolChoiceRequest(t, tc.choice))
+			if err != nil {
+				t.Fatalf("ConvertRequest: %v", err)
+			}
+			assertJSONEqual(t, gjson.GetBytes(payload, "tool_choice").Raw, tc.claude)
+			if n := len(gjson.GetBytes(payload, "tools").Array()); n != 2 {
+				t.Errorf("tools = %d, want 2", n)
+			}
+		})
+	}
+}
+
+func TestToolChoice_Gemini(t *testing.T) {
+	for _, tc := range toolChoiceVariants {
+		t.Run(tc.name, func(t *testing.T) {
+			payload, err := (&GeminiProvider{}).ConvertRequest(toolChoiceRequest(t, tc.choice))
+			if err != nil {
+				t.Fatalf("ConvertRequest: %v", err)
+			}
+			assertJSONEqual(t, gjson.GetBytes(payload, "toolConfig.functionCallingConfig").Raw, tc.gemini)
+		})
+	}
+}
+
+func TestToolChoice_OpenAI(t *testing.T) {
+	for _, tc := range toolChoiceVariants {
+		t.Run(tc.name, func(t *testing.T) {
+			payload, err := ToOpenAIRequest(toolChoiceRequest(t, tc.choice))
+			if err != nil {
+				t.Fatalf("ToOpenAIRequest: %v", err)
+			}
+			assertJSONEqual(t, gjson.GetBytes(payload, "tool_choice").Raw, tc.openai)
+		})
+	}
+}
+
+func TestToolChoice_ParsedFromClaudeAndGemini(t *testing.T) {
+	claudeReq, err := to_ir.ParseClaudeRequest([]byte(`{"model":"m","max_tokens":10,"messages":[{"role":"user","content":"hi"}],` +
+		`"tools":[{"name":"get_weather","input_schema":{"type":"object"}}],"tool_choice":{"type":"tool","name":"get_weather"}}`))
+	if err != nil {
+		t.Fatalf("ParseClaudeRequest: %v", err)
+	}
+	if got := ir.ForcedToolName(claudeReq); got != "get_weather" {
+		t.Errorf("claude forced tool = %q", got)
+	}
+
+	geminiReq, err := to_ir.ParseGeminiRequest([]byte(`{"contents":[{"role":"user","parts":[{"text":"hi"}]}],` +
+		`"tools":[{"functionDeclarations":[{"name":"get_weather"}]}],` +
+		`"toolConfig":{"functionCallingConfig":{"mode":"ANY","allowedFunctionNames":["get_weather"]}}}`))
+	if err != nil {
+		t.Fatalf("ParseGeminiRequest: %v", err)
+	}
+	if geminiReq.ToolChoice != ir.ToolChoiceFunction || geminiReq.ToolChoiceFunction != "get_weather" {
+		t.Errorf("gemini tool choice = %q/%q", geminiReq.ToolChoice, geminiReq.ToolChoiceFunction)
+	}
+	payload, err := (&ClaudeProvider{}).ConvertRequest(geminiReq)
+	if err != nil {
+		t.Fatalf("ConvertRequest: %v", err)
+	}
+	assertJSONEqual(t, gjson.GetBytes(payload, "tool_choice").Raw, `{"type":"tool","name":"get_weather"}`)
+}
+
+func TestToolChoice_GeminiForcedCallFinishesWithToolCalls(t *testing.T) {
+	resp := `{"candidates":[{"content":{"role":"model","parts":[{"functionCall":{"name":"get_weather","args":{}}}]},"finishReason":"STOP"}]}`
+	candidates, _, _, err := to_ir.ParseGeminiResponseCandidates([]byte(resp), nil)
+	if err != nil || len(candidates) != 1 {
+		t.Fatalf("ParseGeminiResponseCandidates: %v (%d candidates)", err, len(candidates))
+	}
+	if candidates[0].FinishReason != ir.FinishReasonToolCalls {
+		t.Errorf("finish reason = %q, want %q", candidates[0].FinishReason, ir.FinishReasonToolCalls)
+	}
+}
+
+func assertJSONEqual(t *testing.T, got, want string) {
+	t.Helper()
+	if gjson.Parse(got).String() == "" && want != "" {
+		t.Fatalf("missing value, want %s", want)
+	}
+	if normalizeJSON(t, got) != normalizeJSON(t, want) {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func normalizeJSON(t *testing.T, s string) string {
+	t.Helper()
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatalf("invalid JSON %q: %v", s, err)
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
@@ -0,0 +1,99 @@
+package from_ir
+
+import (
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/nghyane/llm-mux/internal/translator/to_ir"
+	"github.com/tidwall/gjson"
+)
+
+const imageToolResultRequest = `{"model":"m","messages":[
+	{"role":"user","content":"Take a screenshot"},
+	{"role":"assistant","tool_calls":[{"id":"call_1","type":"function","function":{"name":"screenshot","arguments":"{}"}}]},
+	{"role":"tool","tool_call_id":"call_1","content":[
+		{"type":"text","text":"Captured."},
+		{"type":"image_url","image_url":{"url":"data:image/png;base64,iVBORw0KGgo="}},
+		{"type":"text","text":"1280x720"}]}]}`
+
+func parseImageToolResult(t *testing.T) *ir.UnifiedChatRequest {
+	t.Helper()
+	req, err := to_ir.ParseOpenAIRequest([]byte(imageToolResultRequest))
+	if err != nil {
+		t.Fatalf("ParseOpenAIRequest: %v", err)
+	}
+	return req
+}
+
+func TestToolResultWithImage_Claude(t *testing.T) {
+	req := parseImageToolResult(t)
+	out, err := (&ClaudeProvider{}).ConvertRequest(req)
+	if err != nil {
+		t.Fatalf("ConvertRequest: %v", err)
+	}
+	var result gjson.Result
+	for _, m := range gjson.GetBytes(out, "messages").Array() {
+		for _, block := range m.Get("content").Array() {
+			if block.Get("type").String() == ir.ClaudeBlockToolResult {
+				result = block
+			}
+		}
+	}
+	content := result.Get("content").Array()
+	if len(content) != 2 {
+		t.Fatalf("tool_result content = %s, want text and image blocks", result.Get("content").Raw)
+	}
+	if content[0].Get("text").String() != "Captured.\n1280x720" {
+		t.Errorf("text block = %s", content[0].Raw)
+	}
+	if content[1].Get("type").String() != ir.ClaudeBlockImage || content[1].Get("source.media_type").String() != "image/png" || content[1].Get("source.data").String() != "iVBORw0KGgo=" {
+		t.Errorf("image block = %s", content[1].Raw)
+	}
+	if len(req.Warnings) != 0 {
+		t.Errorf("warnings = %v, want none", req.Warnings)
+	}
+}
+
+func TestToolResultWithImage_Gemini(t *testing.T) {
+	req := parseImageToolResult(t)
+	out, err := (&GeminiProvider{}).ConvertRequest(req)
+	if err != nil {
+		t.Fatalf("ConvertRequest: %v", err)
+	}
+	var parts []gjson.Result
+	for _, c := range gjson.GetBytes(out, "contents").Array() {
+		if c.Get("parts.0.functionResponse").Exists() {
+			parts = c.Get("parts").Array()
+		}
+	}
+	if len(parts) != 2 {
+		t.Fatalf("function response turn has %d parts, want functionResponse and inlineData", len(parts))
+	}
+	if got := parts[0].Get("functionResponse.response.content").String(); got != "Captured.\n1280x720" {
+		t.Errorf("functionResponse content = %q", got)
+	}
+	if parts[1].Get("inlineData.mimeType").String() != "image/png" || parts[1].Get("inlineData.data").String() != "iVBORw0KGgo=" {
+		t.Errorf("image part = %s", parts[1].Raw)
+	}
+}
+
+func TestToolResultWithImage_TextOnlyProvidersWarn(t *testing.T) {
+	for name, convert := range map[string]func(*ir.UnifiedChatRequest) ([]byte, error){
+		"openai": ToOpenAIRequest,
+		"ollama": ToOllamaRequest,
+		"kiro":   (&KiroProvider{}).ConvertRequest,
+	} {
+		req := parseImageToolResult(t)
+		out, err := convert(req)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !gjson.ValidBytes(out) {
+			t.Fatalf("%s: invalid request %s", name, out)
+		}
+		want := name + " does not accept images or files in tool results; they were dropped"
+		if len(req.Warnings) != 1 || req.Warnings[0] != want {
+			t.Errorf("%s: warnings = %v, want %q", name, req.Warnings, want)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+// Package golden runs the translation fixtures shared by the translator's
+// golden-file tests. Each fixture in testdata/<source format>/<case>.json is
+// translated by the caller for every target format it lists, and the output
+// is compared with <out dir>/<source format>/<case>.<target>.golden.
+//
+// Run a test with -update to rewrite its golden files.
+package golden
+
+import (
+	"bytes"
+	// encoding/json sorts map keys, which keeps normalized output stable.
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files")
+
+// Fixture is one translation case.
+type Fixture struct {
+	// Name is "<source format>/<case>".
+	Name string `json:"-"`
+	// Kind is request, response or stream.
+	Kind  string   `json:"kind"`
+	From  string   `json:"from"`
+	To    []string `json:"to"`
+	Model string   `json:"model"`
+	// Input is the request or response body, or for a stream the lines the
+	// upstream sends, one string each.
+	Input json.RawMessage `json:"input"`
+}
+
+// StreamLines returns the lines of a stream fixture.
+func (fx Fixture) StreamLines() ([]string, error) {
+	var lines []string
+	err := json.Unmarshal(fx.Input, &lines)
+	return lines, err
+}
+
+// fixtureDir is the directory holding the fixtures, next to this file.
+func fixtureDir() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "testdata")
+}
+
+// Fixtures loads every fixture.
+func Fixtures(t *testing.T) []Fixture {
+	t.Helper()
+	dir := fixtureDir()
+	paths, err := filepath.Glob(filepath.Join(dir, "*", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatalf("no fixtures in %s", dir)
+	}
+	fixtures := make([]Fixture, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var fx Fixture
+		if err = json.Unmarshal(data, &fx); err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		rel, _ := filepath.Rel(dir, strings.TrimSuffix(path, ".json"))
+		fx.Name = filepath.ToSlash(rel)
+		fixtures = append(fixtures, fx)
+	}
+	return fixtures
+}
+
+// Run translates every fixture to each of its targets with translate and
+// compares the normalized output with the golden files under outDir.
+func Run(t *testing.T, outDir string, translate func(fx Fixture, to string) ([]byte, error)) {
+	t.Helper()
+	for _, fx := range Fixtures(t) {
+		for _, to := range fx.To {
+			t.Run(fx.Name+"_to_"+to, func(t *testing.T) {
+				got, err := translate(fx, to)
+				if err != nil {
+					t.Fatalf("%s %s -> %s: %v", fx.Kind, fx.From, to, err)
+				}
+				compare(t, filepath.Join(outDir, filepath.FromSlash(fx.Name)+"."+to+".golden"), Normalize(t, got))
+			})
+		}
+	}
+}
+
+func compare(t *testing.T, golden string, got []byte) {
+	t.Helper()
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(golden), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(golden, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("%v (run the test with -update to create it)", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output differs from %s:\n%s", golden, got)
+	}
+}
+
+// timestampKeys name fields that carry the time of translation.
+var timestampKeys = map[string]bool{"created": true, "created_at": true, "createTime": true}
+
+// generatedIDs match IDs minted during translation: UUIDs, random tool call
+// IDs and time-based response IDs.
+var generatedIDs = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	{regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`), "<uuid>"},
+	{regexp.MustCompile(`\b(call|toolu)_[A-Za-z0-9]{20,}\b`), "${1}_<generated>"},
+	{regexp.MustCompile(`\b(call|resp)_\d{10,}(_\d+)?\b`), "${1}_<generated>"},
+}
+
+// Normalize makes translator output comparable across runs: JSON is
+// re-encoded with sorted keys and timestamps and generated IDs are replaced
+// with placeholders. A JSON body is indented; a stream keeps one line per
+// SSE field.
+func Normalize(t *testing.T, out []byte) []byte {
+	t.Helper()
+	var v any
+	if json.Unmarshal(out, &v) == nil {
+		return replaceGeneratedIDs(encodeJSON(t, scrubTimestamps(v), "  "))
+	}
+	var b bytes.Buffer
+	for _, line := range strings.Split(string(out), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		prefix, payload := "", line
+		if p, ok := strings.CutPrefix(line, "data: "); ok {
+			prefix, payload = "data: ", p
+		}
+		if json.Unmarshal([]byte(payload), &v) == nil {
+			b.WriteString(prefix)
+			b.Write(encodeJSON(t, scrubTimestamps(v), ""))
+			continue
+		}
+		b.WriteString(line + "\n")
+	}
+	return replaceGeneratedIDs(b.Bytes())
+}
+
+// encodeJSON encodes v with sorted keys and a trailing newline, leaving
+// placeholders such as <timestamp> unescaped.
+func encodeJSON(t *testing.T, v any, indent string) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", indent)
+	if err := enc.Encode(v); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+func scrubTimestamps(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, child := range v {
+			if timestampKeys[k] {
+				v[k] = "<timestamp>"
+				continue
+			}
+			v[k] = scrubTimestamps(child)
+		}
+	case []any:
+		for i, child := range v {
+			v[i] = scrubTimestamps(child)
+		}
+	}
+	return v
+}
+
+func replaceGeneratedIDs(out []byte) []byte {
+	for _, id := range generatedIDs {
+		out = id.re.ReplaceAll(out, []byte(id.repl))
+	}
+	return out
+}
@@ -0,0 +1,30 @@
+package golden
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	out := []byte(`{"id":"resp_1760000000123456789","created":1760000000,"choices":[{"tool_calls":[` +
+		`{"id":"call_aB3dE5fG7hI9jK1lM3nO5pQ7"},{"id":"call_1"}]}],"request_id":"123e4567-e89b-12d3-a456-426614174000"}`)
+	got := string(Normalize(t, out))
+	for _, want := range []string{`"created": "<timestamp>"`, `"resp_<generated>"`, `"call_<generated>"`, `"call_1"`, `"<uuid>"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("normalized output lacks %s:\n%s", want, got)
+		}
+	}
+}
+
+func TestFixtures(t *testing.T) {
+	for _, fx := range Fixtures(t) {
+		if fx.Kind == "stream" {
+			if _, err := fx.StreamLines(); err != nil {
+				t.Errorf("%s: stream input must be a list of lines: %v", fx.Name, err)
+			}
+		}
+		if !strings.HasPrefix(fx.Name, fx.From+"/") || len(fx.To) == 0 {
+			t.Errorf("%s: fixture from %q lists targets %v", fx.Name, fx.From, fx.To)
+		}
+	}
+}
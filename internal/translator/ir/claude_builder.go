@@ -22,6 +22,7 @@ const (
 	ClaudeStopEndTurn           = "end_turn"
 	ClaudeStopToolUse           = "tool_use"
 	ClaudeStopMaxTokens         = "max_tokens"
+	ClaudeStopRefusal           = "refusal"
 	ClaudeSSEMessageStart       = "message_start"
 	ClaudeSSEContentBlockStart  = "content_block_start"
 	ClaudeSSEContentBlockDelta  = "content_block_delta"
@@ -42,6 +43,9 @@ type ClaudeStreamParserState struct {
 	ToolUseArgs              map[int]*strings.Builder
 	CurrentThinkingSignature string
 	BlockTypes               map[int]string
+	// StartUsage is the input usage reported by message_start, which
+	// message_delta leaves out.
+	StartUsage *Usage
 }
 
 func NewClaudeStreamParserState() *ClaudeStreamParserState {
@@ -82,6 +86,33 @@ func ParseClaudeUsage(usage gjson.Result) *Usage {
 	return u
 }
 
+// MergeClaudeStartUsage fills the input token counts missing from a
+// message_delta usage with those reported by message_start.
+func MergeClaudeStartUsage(start, delta *Usage) *Usage {
+	if start == nil {
+		return delta
+	}
+	if delta == nil {
+		u := *start
+		return &u
+	}
+	u := *delta
+	if u.PromptTokens == 0 {
+		u.PromptTokens = start.PromptTokens
+	}
+	if u.CacheCreationInputTokens == 0 {
+		u.CacheCreationInputTokens = start.CacheCreationInputTokens
+	}
+	if u.CacheReadInputTokens == 0 {
+		u.CacheReadInputTokens = start.CacheReadInputTokens
+	}
+	if u.PromptTokensDetails == nil {
+		u.PromptTokensDetails = start.PromptTokensDetails
+	}
+	u.TotalTokens = u.PromptTokens + u.CompletionTokens
+	return &u
+}
+
 // ParseClaudeContentBlock parses a Claude content block into IR Message parts.
 func ParseClaudeContentBlock(block gjson.Result, msg *Message) {
 	switch block.Get("type").String() {
@@ -366,14 +397,18 @@ func ParseClaudeContentBlockStop(parsed gjson.Result, state *ClaudeStreamParserS
 // ParseClaudeMessageDelta parses Claude message_delta into IR events.
 func ParseClaudeMessageDelta(parsed gjson.Result) []UnifiedEvent {
 	finishReason := FinishReasonUnknown
+	var contentFilter any
 	if delta := parsed.Get("delta"); delta.Exists() {
 		if sr := delta.Get("stop_reason"); sr.Exists() {
 			finishReason = MapClaudeFinishReason(sr.String())
+			if IsSafetyFinish(finishReason) {
+				contentFilter = NewSafetyBlock(sr.String(), nil).ToMap()
+			}
 		}
 	}
 	var usage *Usage
 	if u := parsed.Get("usage"); u.Exists() {
 		usage = ParseClaudeUsage(u)
 	}
-	return []UnifiedEvent{{Type: EventTypeFinish, Usage: usage, FinishReason: finishReason}}
+	return []UnifiedEvent{{Type: EventTypeFinish, Usage: usage, FinishReason: finishReason, ContentFilter: contentFilter}}
 }
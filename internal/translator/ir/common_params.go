@@ -55,6 +55,7 @@ func ExtractMaxTokens(root gjson.Result, keys ...string) *int {
 }
 
 // ExtractStopSequences extracts stop sequences as array or single string.
+// Empty entries are dropped since no provider accepts them.
 func ExtractStopSequences(root gjson.Result, keys ...string) []string {
 	if len(keys) == 0 {
 		keys = []string{"stop", "stop_sequences", "stopSequences"}
@@ -64,7 +65,9 @@ func ExtractStopSequences(root gjson.Result, keys ...string) []string {
 			if v.IsArray() {
 				var result []string
 				for _, s := range v.Array() {
-					result = append(result, s.String())
+					if str := s.String(); str != "" {
+						result = append(result, str)
+					}
 				}
 				return result
 			}
@@ -131,6 +134,7 @@ func ApplyOpenAIExtendedParams(req *UnifiedChatRequest, root gjson.Result) {
 	req.FrequencyPenalty = ExtractFrequencyPenalty(root)
 	req.PresencePenalty = ExtractPresencePenalty(root)
 	req.Logprobs = ExtractLogprobs(root)
+	req.LogprobsRequired = root.Get(LogprobsRequiredField).Bool()
 	req.TopLogprobs = ExtractTopLogprobs(root)
 	req.CandidateCount = ExtractCandidateCount(root)
 }
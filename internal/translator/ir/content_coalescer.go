@@ -1,13 +1,21 @@
 package ir
 
-import "sync"
+import (
+	"strings"
+	"sync"
+)
 
 type geminiContent struct {
 	role  string
 	parts []any
+	// responses marks a function response turn, which holds only
+	// functionResponse parts and the media attached to them.
+	responses bool
 }
 
-// ContentCoalescer merges consecutive same-role contents efficiently.
+// ContentCoalescer merges consecutive same-role contents efficiently. It never
+// merges across a boundary Gemini rejects: text after a functionCall stays in
+// a new content, and function responses are kept apart from other user parts.
 type ContentCoalescer struct {
 	contents []geminiContent
 	lastRole string
@@ -37,15 +45,41 @@ func (c *ContentCoalescer) Emit(role string, parts []any) {
 	if len(parts) == 0 {
 		return
 	}
+	responses := partHasKey(parts[0], "functionResponse")
 	if role == c.lastRole && len(c.contents) > 0 {
 		last := &c.contents[len(c.contents)-1]
-		last.parts = append(last.parts, parts...)
-		return
+		if c.canMerge(last, parts, responses) {
+			last.parts = append(last.parts, parts...)
+			return
+		}
 	}
-	c.contents = append(c.contents, geminiContent{role: role, parts: parts})
+	c.contents = append(c.contents, geminiContent{role: role, parts: parts, responses: responses})
 	c.lastRole = role
 }
 
+// canMerge reports whether parts may join last without producing a content
+// the API rejects.
+func (c *ContentCoalescer) canMerge(last *geminiContent, parts []any, responses bool) bool {
+	if last.responses != responses {
+		return false
+	}
+	// Parallel function calls may share a content; anything else after a
+	// call starts a new one.
+	if partHasKey(last.parts[len(last.parts)-1], "functionCall") {
+		return partHasKey(parts[0], "functionCall")
+	}
+	return true
+}
+
+func partHasKey(part any, key string) bool {
+	m, ok := part.(map[string]any)
+	if !ok {
+		return false
+	}
+	_, has := m[key]
+	return has
+}
+
 func (c *ContentCoalescer) Build() []any {
 	if len(c.contents) == 0 {
 		return nil
@@ -59,3 +93,86 @@ func (c *ContentCoalescer) Build() []any {
 	}
 	return result
 }
+
+// ClaudeBlockCoalescer builds Claude content arrays, merging adjacent plain
+// text blocks into one and dropping empty text so providers never receive
+// redundant or invalid text entries. Non-text blocks keep their position.
+type ClaudeBlockCoalescer struct {
+	blocks  []any
+	text    strings.Builder
+	pending string
+	merged  bool
+}
+
+var claudeBlockCoalescerPool = sync.Pool{
+	New: func() any {
+		return &ClaudeBlockCoalescer{blocks: make([]any, 0, 16)}
+	},
+}
+
+func GetClaudeBlockCoalescer(capacity int) *ClaudeBlockCoalescer {
+	c := claudeBlockCoalescerPool.Get().(*ClaudeBlockCoalescer)
+	if cap(c.blocks) < capacity {
+		c.blocks = make([]any, 0, capacity)
+	}
+	return c
+}
+
+func PutClaudeBlockCoalescer(c *ClaudeBlockCoalescer) {
+	clear(c.blocks)
+	c.blocks = c.blocks[:0]
+	c.text.Reset()
+	c.pending = ""
+	c.merged = false
+	claudeBlockCoalescerPool.Put(c)
+}
+
+// EmitText queues plain text; it is merged with any directly preceding text.
+func (c *ClaudeBlockCoalescer) EmitText(text string) {
+	if text == "" {
+		return
+	}
+	if c.pending == "" {
+		c.pending = text
+		return
+	}
+	if !c.merged {
+		c.text.WriteString(c.pending)
+		c.merged = true
+	}
+	c.text.WriteString(text)
+}
+
+// EmitBlock appends a non-mergeable block, flushing queued text before it.
+func (c *ClaudeBlockCoalescer) EmitBlock(block map[string]any) {
+	if block == nil {
+		return
+	}
+	c.flushText()
+	c.blocks = append(c.blocks, block)
+}
+
+func (c *ClaudeBlockCoalescer) flushText() {
+	if c.pending == "" {
+		return
+	}
+	text := c.pending
+	if c.merged {
+		text = c.text.String()
+		c.text.Reset()
+		c.merged = false
+	}
+	c.pending = ""
+	c.blocks = append(c.blocks, map[string]any{"type": ClaudeBlockText, "text": text})
+}
+
+// Build returns the coalesced blocks. The result does not alias pooled memory.
+func (c *ClaudeBlockCoalescer) Build() []any {
+	c.flushText()
+	if len(c.blocks) == 0 {
+		return nil
+	}
+	result := make([]any, len(c.blocks))
+	copy(result, c.blocks)
+	return result
+}
@@ -0,0 +1,220 @@
+package ir
+
+import (
+	"strconv"
+	"testing"
+)
+
+func blockTypes(blocks []any) []string {
+	types := make([]string, len(blocks))
+	for i, b := range blocks {
+		types[i], _ = b.(map[string]any)["type"].(string)
+	}
+	return types
+}
+
+func TestClaudeBlockCoalescer_MergesAdjacentText(t *testing.T) {
+	c := GetClaudeBlockCoalescer(4)
+	defer PutClaudeBlockCoalescer(c)
+
+	c.EmitText("Hello")
+	c.EmitText("")
+	c.EmitText(", ")
+	c.EmitText("world")
+
+	out := c.Build()
+	if len(out) != 1 {
+		t.Fatalf("len = %d, want 1", len(out))
+	}
+	if got := out[0].(map[string]any)["text"]; got != "Hello, world" {
+		t.Errorf("text = %q, want %q", got, "Hello, world")
+	}
+}
+
+func TestClaudeBlockCoalescer_EmptyInput(t *testing.T) {
+	c := GetClaudeBlockCoalescer(0)
+	defer PutClaudeBlockCoalescer(c)
+
+	c.EmitText("")
+	if out := c.Build(); out != nil {
+		t.Errorf("Build() = %v, want nil", out)
+	}
+}
+
+func TestBuildClaudeContentParts_MixedTextAndToolUse(t *testing.T) {
+	msg := Message{
+		Role: RoleAssistant,
+		Content: []ContentPart{
+			{Type: ContentTypeText, Text: "Let me "},
+			{Type: ContentTypeText, Text: ""},
+			{Type: ContentTypeText, Text: "check."},
+			{Type: ContentTypeImage, Image: &ImagePart{URL: "https://example.com/a.png"}},
+			{Type: ContentTypeText, Text: "Cited", Citations: []*TextCitation{{Type: "char_location"}}},
+			{Type: ContentTypeText, Text: "Done"},
+		},
+		ToolCalls: []ToolCall{
+			{ID: "toolu_1", Name: "lookup", Args: `{"q":"a"}`},
+			{ID: "toolu_2", Name: "lookup", Args: `{"q":"b"}`},
+		},
+	}
+
+	out := BuildClaudeContentParts(msg, true, false)
+
+	want := []string{ClaudeBlockText, ClaudeBlockImage, ClaudeBlockText, ClaudeBlockText, ClaudeBlockToolUse, ClaudeBlockToolUse}
+	got := blockTypes(out)
+	if len(got) != len(want) {
+		t.Fatalf("types = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("types = %v, want %v", got, want)
+		}
+	}
+	if text := out[0].(map[string]any)["text"]; text != "Let me check." {
+		t.Errorf("merged text = %q, want %q", text, "Let me check.")
+	}
+	if _, ok := out[2].(map[string]any)["citations"]; !ok {
+		t.Errorf("cited text block lost its citations")
+	}
+	if text := out[3].(map[string]any)["text"]; text != "Done" {
+		t.Errorf("text after citation = %q, want %q", text, "Done")
+	}
+	if id := out[4].(map[string]any)["id"]; id != "toolu_1" {
+		t.Errorf("first tool_use id = %v, want toolu_1", id)
+	}
+	if id := out[5].(map[string]any)["id"]; id != "toolu_2" {
+		t.Errorf("second tool_use id = %v, want toolu_2", id)
+	}
+}
+
+func TestBuildClaudeContentParts_KeepsUserTextBlocks(t *testing.T) {
+	msg := Message{
+		Role: RoleUser,
+		Content: []ContentPart{
+			{Type: ContentTypeText, Text: "Four"},
+			{Type: ContentTypeText},
+			{Type: ContentTypeText, Text: "Five"},
+		},
+	}
+	out := BuildClaudeContentParts(msg, false, false)
+	if len(out) != 2 {
+		t.Fatalf("len = %d, want 2 (empty dropped, user blocks kept)", len(out))
+	}
+	if text := out[1].(map[string]any)["text"]; text != "Five" {
+		t.Errorf("out[1] text = %q, want Five", text)
+	}
+}
+
+func TestBuildClaudeContentParts_OnlyEmptyText(t *testing.T) {
+	msg := Message{
+		Role:    RoleUser,
+		Content: []ContentPart{{Type: ContentTypeText}, {Type: ContentTypeText}},
+	}
+	if out := BuildClaudeContentParts(msg, false, false); len(out) != 0 {
+		t.Errorf("out = %v, want no blocks", out)
+	}
+}
+
+// fragmentedMessage mimics a streamed assistant turn stored as many text
+// fragments around a tool call.
+func fragmentedMessage() Message {
+	msg := Message{Role: RoleAssistant}
+	for i := range 32 {
+		msg.Content = append(msg.Content, ContentPart{Type: ContentTypeText, Text: "chunk " + strconv.Itoa(i) + " "})
+		if i%8 == 7 {
+			msg.Content = append(msg.Content, ContentPart{Type: ContentTypeText})
+		}
+	}
+	msg.ToolCalls = []ToolCall{{ID: "toolu_1", Name: "lookup", Args: `{"q":"a"}`}}
+	return msg
+}
+
+// naiveClaudeBlocks is the one-block-per-part construction the coalescer replaces.
+func naiveClaudeBlocks(msg Message) []any {
+	parts := make([]any, 0, len(msg.Content)+len(msg.ToolCalls))
+	for i := range msg.Content {
+		if msg.Content[i].Type == ContentTypeText {
+			parts = append(parts, map[string]any{"type": ClaudeBlockText, "text": msg.Content[i].Text})
+		}
+	}
+	for i := range msg.ToolCalls {
+		tc := &msg.ToolCalls[i]
+		parts = append(parts, map[string]any{"type": ClaudeBlockToolUse, "id": tc.ID, "name": tc.Name, "input": ParseToolCallArgs(tc.Args)})
+	}
+	return parts
+}
+
+func BenchmarkClaudeBlocks_Naive(b *testing.B) {
+	msg := fragmentedMessage()
+	b.ReportAllocs()
+	for b.Loop() {
+		_ = naiveClaudeBlocks(msg)
+	}
+}
+
+func BenchmarkClaudeBlocks_Coalesced(b *testing.B) {
+	msg := fragmentedMessage()
+	b.ReportAllocs()
+	for b.Loop() {
+		_ = BuildClaudeContentParts(msg, true, false)
+	}
+}
+
+func contentShape(contents []any) []string {
+	shape := make([]string, len(contents))
+	for i, c := range contents {
+		content := c.(map[string]any)
+		kinds := content["role"].(string) + ":"
+		for j, p := range content["parts"].([]any) {
+			if j > 0 {
+				kinds += ","
+			}
+			for _, key := range []string{"text", "functionCall", "functionResponse", "inlineData"} {
+				if _, ok := p.(map[string]any)[key]; ok {
+					kinds += key
+					break
+				}
+			}
+		}
+		shape[i] = kinds
+	}
+	return shape
+}
+
+func TestContentCoalescer_FunctionCallBoundaries(t *testing.T) {
+	text := func(s string) any { return map[string]any{"text": s} }
+	call := func(name string) any { return map[string]any{"functionCall": map[string]any{"name": name}} }
+	response := func(name string) any { return map[string]any{"functionResponse": map[string]any{"name": name}} }
+	image := map[string]any{"inlineData": map[string]any{"mimeType": "image/png"}}
+
+	c := GetContentCoalescer(8)
+	defer PutContentCoalescer(c)
+
+	c.Emit("user", []any{text("hi")})
+	c.Emit("user", []any{text("weather?")})
+	c.Emit("model", []any{text("checking"), call("weather")})
+	c.Emit("model", []any{call("time")})
+	c.Emit("user", []any{response("weather"), image})
+	c.Emit("user", []any{response("time")})
+	c.Emit("user", []any{text("thanks")})
+	c.Emit("model", []any{call("log")})
+	c.Emit("model", []any{text("done")})
+
+	got := contentShape(c.Build())
+	want := []string{
+		"user:text,text",
+		"model:text,functionCall,functionCall",
+		"user:functionResponse,inlineData,functionResponse",
+		"user:text",
+		"model:functionCall",
+		"model:text",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("contents = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("contents = %v, want %v", got, want)
+		}
+	}
+}
@@ -123,3 +123,51 @@ func (c *ContentFilterResult) IsBlocked() bool {
 	}
 	return false
 }
+
+// IsSafetyFinish reports whether the provider stopped generation for a
+// safety or policy reason rather than completing normally.
+func IsSafetyFinish(reason FinishReason) bool {
+	switch reason {
+	case FinishReasonContentFilter, FinishReasonBlocklist,
+		FinishReasonProhibitedContent, FinishReasonSPII,
+		FinishReasonImageSafety, FinishReasonRecitation:
+		return true
+	}
+	return false
+}
+
+// NewSafetyBlock describes a safety termination. nativeReason is the
+// provider's own value (Gemini "SAFETY", "PROHIBITED_CONTENT", a prompt
+// blockReason, or Anthropic "refusal"); ratings may be nil.
+func NewSafetyBlock(nativeReason string, ratings []*SafetyRating) *ContentFilterResult {
+	result := &ContentFilterResult{BlockReason: nativeReason, Filtered: true}
+	for _, r := range ratings {
+		if r == nil || !r.Blocked && !isHighProbability(r.Probability) {
+			continue
+		}
+		result.Ratings = append(result.Ratings, r)
+		if result.BlockedBy == "" {
+			result.BlockedBy = r.Category
+		}
+	}
+	return result
+}
+
+// isHighProbability matches prompt feedback ratings, which carry no blocked
+// flag; the categories that triggered a block are rated HIGH or MEDIUM.
+func isHighProbability(p string) bool {
+	return p == "HIGH" || p == "MEDIUM"
+}
+
+// SafetyBlockError is returned instead of an empty response when the server
+// runs in strict safety mode.
+func SafetyBlockError(filter *ContentFilterResult) error {
+	msg := "response blocked by provider safety filter"
+	if filter != nil && filter.BlockReason != "" {
+		msg += ": " + filter.BlockReason
+	}
+	if filter != nil && filter.BlockedBy != "" {
+		msg += " (" + filter.BlockedBy + ")"
+	}
+	return &InvalidRequestError{Message: msg}
+}
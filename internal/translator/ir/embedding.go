@@ -0,0 +1,24 @@
+package ir
+
+// EmbeddingEncodingBase64 asks for vectors as base64 little-endian float32 arrays.
+const EmbeddingEncodingBase64 = "base64"
+
+// EmbeddingRequest is the provider-neutral form of an embeddings request.
+type EmbeddingRequest struct {
+	Model string
+	// Inputs holds the texts to embed, in request order.
+	Inputs []string
+	// Dimensions asks for truncated vectors when > 0.
+	Dimensions int
+	// EncodingFormat only shapes the client response; upstreams are always
+	// asked for floats.
+	EncodingFormat string
+	User           string
+}
+
+// EmbeddingResponse holds one vector per input, in input order.
+type EmbeddingResponse struct {
+	Model   string
+	Vectors [][]float32
+	Usage   *Usage
+}
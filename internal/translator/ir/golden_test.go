@@ -0,0 +1,122 @@
+package ir_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/translator"
+	"github.com/nghyane/llm-mux/internal/translator/from_ir"
+	"github.com/nghyane/llm-mux/internal/translator/golden"
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+	"github.com/nghyane/llm-mux/internal/translator/to_ir"
+)
+
+// TestTranslationGolden renders the shared fixtures through the translator
+// registry; the outputs are in testdata/golden.
+func TestTranslationGolden(t *testing.T) {
+	golden.Run(t, filepath.Join("testdata", "golden"), translateFixture)
+}
+
+func translateFixture(fx golden.Fixture, to string) ([]byte, error) {
+	switch fx.Kind {
+	case "request":
+		req, err := translator.ParseRequest(fx.From, fx.Input)
+		if err != nil {
+			return nil, err
+		}
+		if fx.Model != "" {
+			req.Model = fx.Model
+		}
+		return translator.ConvertRequest(to, req)
+	case "response":
+		parser, ok := translator.GetRegistry().GetToIR(fx.From)
+		if !ok {
+			return nil, fmt.Errorf("no parser for %s", fx.From)
+		}
+		messages, usage, err := parser.ParseResponse(fx.Input)
+		if err != nil {
+			return nil, err
+		}
+		converter, ok := translator.GetRegistry().GetFromIR(to)
+		if !ok {
+			return nil, fmt.Errorf("no converter for %s", to)
+		}
+		return converter.ToResponse(messages, usage, fx.Model)
+	case "stream":
+		lines, err := fx.StreamLines()
+		if err != nil {
+			return nil, fmt.Errorf("stream input must be a list of lines: %w", err)
+		}
+		return translateStream(fx.From, to, fx.Model, lines)
+	}
+	return nil, fmt.Errorf("unknown fixture kind %q", fx.Kind)
+}
+
+// translateStream parses each upstream line to events and renders them as
+// the server's stream translator does: stream metadata first, tool calls
+// numbered in order of arrival and only the first finish event kept.
+func translateStream(from, to, model string, lines []string) ([]byte, error) {
+	var parse func([]byte) ([]ir.UnifiedEvent, error)
+	switch from {
+	case "openai":
+		parse = to_ir.ParseOpenAIChunk
+	case "gemini":
+		parse = to_ir.ParseGeminiChunk
+	case "claude":
+		state := ir.NewClaudeStreamParserState()
+		parse = func(line []byte) ([]ir.UnifiedEvent, error) { return to_ir.ParseClaudeChunkWithState(line, state) }
+	default:
+		return nil, fmt.Errorf("no stream parser for %s", from)
+	}
+
+	claudeState := from_ir.NewClaudeStreamState()
+	toolCalls := 0
+	finished := false
+	render := func(ev ir.UnifiedEvent) ([]byte, error) {
+		switch to {
+		case "openai":
+			idx := 0
+			if ev.Type == ir.EventTypeToolCall {
+				idx = toolCalls
+				toolCalls++
+			} else if ev.Type == ir.EventTypeToolCallDelta && toolCalls > 0 {
+				idx = toolCalls - 1
+			}
+			return from_ir.ToOpenAIChunk(ev, model, "chatcmpl-golden", idx)
+		case "claude":
+			return from_ir.ToClaudeSSE(ev, claudeState)
+		case "gemini":
+			return from_ir.ToGeminiChunk(ev, model)
+		}
+		return nil, fmt.Errorf("no stream renderer for %s", to)
+	}
+
+	out, err := render(ir.UnifiedEvent{Type: ir.EventTypeStreamMeta, StreamMeta: &ir.StreamMeta{MessageID: "msg-golden", Model: model}})
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range lines {
+		events, err := parse([]byte(line))
+		if err != nil {
+			return nil, err
+		}
+		for _, ev := range events {
+			if ev.Type == ir.EventTypeFinish {
+				if finished {
+					continue
+				}
+				finished = true
+			}
+			chunk, err := render(ev)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, chunk...)
+			if len(chunk) > 0 && chunk[len(chunk)-1] != '\n' {
+				out = append(out, '\n')
+			}
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,127 @@
+package ir
+
+import (
+	stdjson "encoding/json"
+	"fmt"
+	"strings"
+)
+
+// RepairJSON fixes the mistakes models commonly make in tool-call
+// arguments: markdown code fences, trailing commas, raw control characters
+// and stray backslashes inside strings, and output truncated before its
+// closing quotes and brackets. It returns the repaired text and true when
+// the result is valid JSON, or s unchanged and false when it is not.
+func RepairJSON(s string) (string, bool) {
+	if validJSON(s) {
+		return s, true
+	}
+	src := stripCodeFence(strings.TrimSpace(s))
+
+	var out strings.Builder
+	out.Grow(len(src) + 8)
+	var closers []byte
+	inString := false
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		if inString {
+			switch {
+			case c == '"':
+				inString = false
+				out.WriteByte(c)
+			case c == '\\':
+				if i+1 < len(src) && strings.IndexByte(`"\/bfnrtu`, src[i+1]) >= 0 {
+					out.WriteByte(c)
+					out.WriteByte(src[i+1])
+					i++
+				} else {
+					out.WriteString(`\\`)
+				}
+			case c == '\n':
+				out.WriteString(`\n`)
+			case c == '\r':
+				out.WriteString(`\r`)
+			case c == '\t':
+				out.WriteString(`\t`)
+			case c < 0x20:
+				fmt.Fprintf(&out, `\u%04x`, c)
+			default:
+				out.WriteByte(c)
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			inString = true
+		case '{':
+			closers = append(closers, '}')
+		case '[':
+			closers = append(closers, ']')
+		case '}', ']':
+			trimTrailingComma(&out)
+			if n := len(closers); n > 0 && closers[n-1] == c {
+				closers = closers[:n-1]
+			}
+		}
+		out.WriteByte(c)
+	}
+	if inString {
+		out.WriteByte('"')
+	}
+	for i := len(closers) - 1; i >= 0; i-- {
+		trimTrailingComma(&out)
+		out.WriteByte(closers[i])
+	}
+
+	repaired := out.String()
+	if !validJSON(repaired) {
+		return s, false
+	}
+	return repaired, true
+}
+
+// validJSON checks s with encoding/json rather than internal/json: sonic's
+// Valid accepts raw control characters and invalid escapes inside strings,
+// which are exactly the mistakes RepairJSON exists to fix.
+func validJSON(s string) bool {
+	return stdjson.Valid([]byte(s))
+}
+
+// RepairToolCallArgs runs RepairJSON over the arguments of every tool call in
+// messages, leaving arguments it cannot repair as they were.
+func RepairToolCallArgs(messages []Message) {
+	for i := range messages {
+		for j := range messages[i].ToolCalls {
+			tc := &messages[i].ToolCalls[j]
+			if tc.Args == "" {
+				continue
+			}
+			if repaired, ok := RepairJSON(tc.Args); ok {
+				tc.Args = repaired
+			}
+		}
+	}
+}
+
+// stripCodeFence removes a markdown code fence wrapped around s.
+func stripCodeFence(s string) string {
+	if !strings.HasPrefix(s, "```") || !strings.HasSuffix(s, "```") || len(s) < 6 {
+		return s
+	}
+	body := s[3 : len(s)-3]
+	if nl := strings.IndexByte(body, '\n'); nl >= 0 && !strings.ContainsAny(body[:nl], "{[\"") {
+		body = body[nl+1:]
+	}
+	return strings.TrimSpace(body)
+}
+
+// trimTrailingComma drops a comma, and the whitespace after it, from the end
+// of b.
+func trimTrailingComma(b *strings.Builder) {
+	s := strings.TrimRight(b.String(), " \t\r\n")
+	if !strings.HasSuffix(s, ",") {
+		return
+	}
+	s = s[:len(s)-1]
+	b.Reset()
+	b.WriteString(s)
+}
@@ -0,0 +1,61 @@
+package ir
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestRepairJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"valid", `{"a":1}`, `{"a":1}`},
+		{"trailing comma in object", `{"a":1,"b":2,}`, `{"a":1,"b":2}`},
+		{"trailing comma in array", `{"ids":[1,2,3, ]}`, `{"ids":[1,2,3]}`},
+		{"raw newline in string", "{\"text\":\"line one\nline two\"}", `{"text":"line one\nline two"}`},
+		{"raw tab in string", "{\"text\":\"a\tb\"}", `{"text":"a\tb"}`},
+		{"stray backslash", `{"path":"C:\Users\me"}`, `{"path":"C:\\Users\\me"}`},
+		{"code fence", "```json\n{\"a\":1}\n```", `{"a":1}`},
+		{"truncated string", `{"query":"weather in Par`, `{"query":"weather in Par"}`},
+		{"truncated nesting", `{"a":{"b":[1,2,`, `{"a":{"b":[1,2]}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := RepairJSON(tt.input)
+			if !ok {
+				t.Fatalf("RepairJSON(%q) failed", tt.input)
+			}
+			var gotV, wantV any
+			if err := json.Unmarshal([]byte(got), &gotV); err != nil {
+				t.Fatalf("repaired %q is not JSON: %v", got, err)
+			}
+			json.Unmarshal([]byte(tt.want), &wantV)
+			if !reflect.DeepEqual(gotV, wantV) {
+				t.Errorf("RepairJSON(%q) = %s, want %s", tt.input, got, tt.want)
+			}
+		})
+	}
+
+	for _, bad := range []string{`{"a":}`, `not json at all`, `{"a" 1}`} {
+		if got, ok := RepairJSON(bad); ok || got != bad {
+			t.Errorf("RepairJSON(%q) = %q, %v; want the input back and false", bad, got, ok)
+		}
+	}
+}
+
+func TestRepairToolCallArgs(t *testing.T) {
+	messages := []Message{{Role: RoleAssistant, ToolCalls: []ToolCall{
+		{ID: "call_1", Name: "search", Args: `{"q":"go",}`},
+		{ID: "call_2", Name: "noop", Args: `{"a" 1}`},
+	}}}
+	RepairToolCallArgs(messages)
+	if got := messages[0].ToolCalls[0].Args; got != `{"q":"go"}` {
+		t.Errorf("repairable args = %s", got)
+	}
+	if got := messages[0].ToolCalls[1].Args; got != `{"a" 1}` {
+		t.Errorf("unrepairable args = %s, want them unchanged", got)
+	}
+}
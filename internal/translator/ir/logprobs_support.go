@@ -0,0 +1,45 @@
+package ir
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// LogprobsRequiredField is the OpenAI-format request extension that makes
+// logprobs mandatory:
+//
+//	"logprobs": true, "top_logprobs": 5, "logprobs_required": true
+//
+// Without it, providers that cannot return logprobs answer without them and
+// the response carries an X-LLM-Mux-Warnings header instead.
+const LogprobsRequiredField = "logprobs_required"
+
+// InvalidRequestError reports a request the target provider cannot serve as
+// asked. It surfaces to the client as HTTP 400.
+type InvalidRequestError struct {
+	Message string
+}
+
+func (e *InvalidRequestError) Error() string { return e.Message }
+
+// StatusCode implements provider.StatusCodeError.
+func (e *InvalidRequestError) StatusCode() int { return http.StatusBadRequest }
+
+// WantsLogprobs reports whether the request asks for token logprobs.
+func WantsLogprobs(req *UnifiedChatRequest) bool {
+	return req.Logprobs != nil && *req.Logprobs
+}
+
+// DropUnsupportedLogprobs is called by converters for providers that cannot
+// return logprobs. It fails when the client marked logprobs as required and
+// otherwise records a warning that they will be missing.
+func DropUnsupportedLogprobs(req *UnifiedChatRequest, provider string) error {
+	if !WantsLogprobs(req) {
+		return nil
+	}
+	if req.LogprobsRequired {
+		return &InvalidRequestError{Message: fmt.Sprintf("%s does not support logprobs", provider)}
+	}
+	req.AddWarning("%s does not support logprobs; they were omitted", provider)
+	return nil
+}
@@ -0,0 +1,23 @@
+package ir
+
+// ModerationCategories lists the OpenAI moderation categories every
+// normalized result reports, in OpenAI's order.
+var ModerationCategories = []string{
+	"harassment", "harassment/threatening", "hate", "hate/threatening",
+	"illicit", "illicit/violent", "self-harm", "self-harm/instructions",
+	"self-harm/intent", "sexual", "sexual/minors", "violence", "violence/graphic",
+}
+
+// ModerationResponse is the provider-neutral form of a moderation response.
+type ModerationResponse struct {
+	ID      string
+	Model   string
+	Results []ModerationResult
+}
+
+// ModerationResult holds the verdict for one input.
+type ModerationResult struct {
+	Flagged    bool
+	Categories map[string]bool
+	Scores     map[string]float64
+}
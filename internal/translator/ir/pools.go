@@ -100,11 +100,29 @@ var (
 // SSE Chunk Pools - Optimized for streaming responses
 // -----------------------------------------------------------------------------
 
+// SSE chunk buffers are pooled in two tiers by capacity: small ones for
+// typical text deltas and large ones for tool-call payloads, which would
+// otherwise be reallocated on every chunk. Larger buffers are not pooled.
+const (
+	sseSmallBufSize = 512
+	sseSmallBufMax  = 4 << 10
+	sseLargeBufSize = 16 << 10
+	sseLargeBufMax  = 64 << 10
+)
+
 // sseChunkPool provides reusable byte slices for SSE chunk building.
 var sseChunkPool = sync.Pool{
 	New: func() any {
 		// Typical SSE chunk: "data: {...}\n\n" - allocate 512 bytes
-		b := make([]byte, 0, 512)
+		b := make([]byte, 0, sseSmallBufSize)
+		return &b
+	},
+}
+
+// sseLargeChunkPool provides byte slices for chunks above sseSmallBufMax.
+var sseLargeChunkPool = sync.Pool{
+	New: func() any {
+		b := make([]byte, 0, sseLargeBufSize)
 		return &b
 	},
 }
@@ -114,20 +132,48 @@ func GetSSEChunkBuf() []byte {
 	return (*bp)[:0]
 }
 
-// PutSSEChunkBuf returns an SSE chunk buffer to the pool.
+// getSSEChunkBufSized returns an empty buffer with room for size bytes, taken
+// from the tier that fits it.
+func getSSEChunkBufSized(size int) []byte {
+	pool := sseChunkTier(size)
+	if pool == nil {
+		return make([]byte, 0, size)
+	}
+	bp := pool.Get().(*[]byte)
+	if cap(*bp) < size {
+		pool.Put(bp)
+		return make([]byte, 0, size)
+	}
+	return (*bp)[:0]
+}
+
+// PutSSEChunkBuf returns an SSE chunk buffer to the pool of its tier.
 func PutSSEChunkBuf(b []byte) {
-	if cap(b) >= 512 && cap(b) <= 4096 {
+	if cap(b) < sseSmallBufSize {
+		return
+	}
+	if pool := sseChunkTier(cap(b)); pool != nil {
 		bp := b[:0]
-		sseChunkPool.Put(&bp)
+		pool.Put(&bp)
+	}
+}
+
+// sseChunkTier returns the pool holding buffers of capacity n, or nil when
+// buffers that large are not pooled.
+func sseChunkTier(n int) *sync.Pool {
+	switch {
+	case n <= sseSmallBufMax:
+		return &sseChunkPool
+	case n <= sseLargeBufMax:
+		return &sseLargeChunkPool
+	default:
+		return nil
 	}
 }
 
 func BuildSSEChunk(jsonData []byte) []byte {
 	size := 6 + len(jsonData) + 2 // "data: " + json + "\n\n"
-	buf := GetSSEChunkBuf()
-	if cap(buf) < size {
-		buf = make([]byte, 0, size)
-	}
+	buf := getSSEChunkBufSized(size)
 	buf = append(buf, "data: "...)
 	buf = append(buf, jsonData...)
 	buf = append(buf, "\n\n"...)
@@ -136,10 +182,7 @@ func BuildSSEChunk(jsonData []byte) []byte {
 
 func BuildSSEEvent(eventType string, jsonData []byte) []byte {
 	size := 7 + len(eventType) + 7 + len(jsonData) + 2
-	buf := GetSSEChunkBuf()
-	if cap(buf) < size {
-		buf = make([]byte, 0, size)
-	}
+	buf := getSSEChunkBufSized(size)
 	buf = append(buf, "event: "...)
 	buf = append(buf, eventType...)
 	buf = append(buf, "\ndata: "...)
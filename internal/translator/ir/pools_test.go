@@ -0,0 +1,72 @@
+package ir
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSSEChunkTier(t *testing.T) {
+	cases := []struct {
+		capacity int
+		tier     string
+	}{
+		{capacity: 512, tier: "small"},
+		{capacity: sseSmallBufMax, tier: "small"},
+		{capacity: sseSmallBufMax + 1, tier: "large"},
+		{capacity: sseLargeBufSize, tier: "large"},
+		{capacity: sseLargeBufMax, tier: "large"},
+		{capacity: sseLargeBufMax + 1, tier: "none"},
+	}
+	for _, tc := range cases {
+		var got string
+		switch sseChunkTier(tc.capacity) {
+		case &sseChunkPool:
+			got = "small"
+		case &sseLargeChunkPool:
+			got = "large"
+		case nil:
+			got = "none"
+		}
+		if got != tc.tier {
+			t.Errorf("tier for cap %d = %s, want %s", tc.capacity, got, tc.tier)
+		}
+	}
+}
+
+func TestBuildSSEChunk_LargePayload(t *testing.T) {
+	payload := bytes.Repeat([]byte("x"), 20<<10)
+	for i := 0; i < 3; i++ {
+		buf := BuildSSEChunk(payload)
+		if len(buf) != len(payload)+8 || !bytes.HasPrefix(buf, []byte("data: ")) || !bytes.HasSuffix(buf, []byte("\n\n")) {
+			t.Fatalf("chunk %d malformed: len %d", i, len(buf))
+		}
+		PutSSEChunkBuf(buf)
+	}
+
+	// A small chunk never comes back with a large buffer's contents.
+	small := BuildSSEChunk([]byte(`{"a":1}`))
+	if string(small) != "data: {\"a\":1}\n\n" {
+		t.Errorf("small chunk = %q", small)
+	}
+}
+
+// largeToolCallChunk is a tool-call delta the size of a large file edit.
+var largeToolCallChunk = bytes.Repeat([]byte(`{"arguments":"0123456789abcdef"}`), 300)
+
+func BenchmarkSSEChunk_LargeUnpooled(b *testing.B) {
+	b.ReportAllocs()
+	for b.Loop() {
+		buf := make([]byte, 0, len(largeToolCallChunk)+8)
+		buf = append(buf, "data: "...)
+		buf = append(buf, largeToolCallChunk...)
+		buf = append(buf, "\n\n"...)
+		_ = buf
+	}
+}
+
+func BenchmarkSSEChunk_LargePooled(b *testing.B) {
+	b.ReportAllocs()
+	for b.Loop() {
+		PutSSEChunkBuf(BuildSSEChunk(largeToolCallChunk))
+	}
+}
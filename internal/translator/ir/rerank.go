@@ -0,0 +1,29 @@
+package ir
+
+// RerankRequest is the provider-neutral form of a rerank request.
+type RerankRequest struct {
+	Model string
+	Query string
+	// Documents holds the texts to score, in request order.
+	Documents []string
+	// TopN limits the results to the best N documents when > 0.
+	TopN int
+	// ReturnDocuments asks for each result to echo its document text. It only
+	// shapes the client response; the text is filled in locally.
+	ReturnDocuments bool
+}
+
+// RerankResult scores one document of the request.
+type RerankResult struct {
+	// Index is the position of the document in RerankRequest.Documents.
+	Index int
+	Score float64
+}
+
+// RerankResponse holds the scored documents, best first.
+type RerankResponse struct {
+	ID      string
+	Model   string
+	Results []RerankResult
+	Usage   *Usage
+}
@@ -216,7 +216,8 @@ func BuildClaudeContentParts(msg Message, includeToolCalls bool, thinkingEnabled
 	if includeToolCalls {
 		capacity += len(msg.ToolCalls)
 	}
-	parts := make([]any, 0, capacity)
+	blocks := GetClaudeBlockCoalescer(capacity)
+	defer PutClaudeBlockCoalescer(blocks)
 
 	// Check if we have thinking content and text/tool content
 	hasThinking := false
@@ -259,7 +260,7 @@ func BuildClaudeContentParts(msg Message, includeToolCalls bool, thinkingEnabled
 			if p.Reasoning != "" {
 				thinkingBlock := map[string]any{"type": ClaudeBlockThinking, "thinking": p.Reasoning}
 				thinkingBlock["signature"] = string(p.ThoughtSignature)
-				parts = append(parts, thinkingBlock)
+				blocks.EmitBlock(thinkingBlock)
 			}
 		case ContentTypeRedactedThinking:
 			// CRITICAL: Skip redacted thinking blocks when thinking is disabled
@@ -267,13 +268,17 @@ func BuildClaudeContentParts(msg Message, includeToolCalls bool, thinkingEnabled
 				continue
 			}
 			if p.RedactedData != "" {
-				parts = append(parts, map[string]any{
+				blocks.EmitBlock(map[string]any{
 					"type": ClaudeBlockRedactedThinking,
 					"data": p.RedactedData,
 				})
 			}
 		case ContentTypeText:
-			if p.Text != "" {
+			// Assistant text arrives as output fragments, so adjacent plain text is
+			// merged; user text blocks are kept as sent. Cited text keeps its own block.
+			if msg.Role == RoleAssistant && len(p.Citations) == 0 {
+				blocks.EmitText(p.Text)
+			} else if p.Text != "" {
 				textBlock := map[string]any{"type": ClaudeBlockText, "text": p.Text}
 				if len(p.Citations) > 0 {
 					citations := make([]any, len(p.Citations))
@@ -329,7 +334,7 @@ func BuildClaudeContentParts(msg Message, includeToolCalls bool, thinkingEnabled
 					}
 					textBlock["citations"] = citations
 				}
-				parts = append(parts, textBlock)
+				blocks.EmitBlock(textBlock)
 			}
 		case ContentTypeImage:
 			if p.Image != nil {
@@ -355,7 +360,7 @@ func BuildClaudeContentParts(msg Message, includeToolCalls bool, thinkingEnabled
 					}
 				}
 				if _, hasSource := imgBlock["source"]; hasSource {
-					parts = append(parts, imgBlock)
+					blocks.EmitBlock(imgBlock)
 				}
 			}
 		case ContentTypeFile:
@@ -384,7 +389,7 @@ func BuildClaudeContentParts(msg Message, includeToolCalls bool, thinkingEnabled
 				}
 				if len(source) > 0 {
 					docBlock["source"] = source
-					parts = append(parts, docBlock)
+					blocks.EmitBlock(docBlock)
 				}
 			}
 		case ContentTypeToolResult:
@@ -392,7 +397,7 @@ func BuildClaudeContentParts(msg Message, includeToolCalls bool, thinkingEnabled
 				// Build the tool_result block
 				toolResultBlock := map[string]any{
 					"type":        ClaudeBlockToolResult,
-					"tool_use_id": p.ToolResult.ToolCallID,
+					"tool_use_id": ToClaudeToolID(p.ToolResult.ToolCallID),
 				}
 				// Add is_error if tool execution failed
 				if p.ToolResult.IsError {
@@ -476,7 +481,7 @@ func BuildClaudeContentParts(msg Message, includeToolCalls bool, thinkingEnabled
 					toolResultBlock["content"] = p.ToolResult.Result
 				}
 
-				parts = append(parts, toolResultBlock)
+				blocks.EmitBlock(toolResultBlock)
 			}
 		}
 	}
@@ -485,17 +490,17 @@ func BuildClaudeContentParts(msg Message, includeToolCalls bool, thinkingEnabled
 			tc := &msg.ToolCalls[i]
 			toolUse := map[string]any{"type": ClaudeBlockToolUse, "id": ToClaudeToolID(tc.ID), "name": tc.Name}
 			toolUse["input"] = ParseToolCallArgs(tc.Args)
-			parts = append(parts, toolUse)
+			blocks.EmitBlock(toolUse)
 		}
 	}
 
 	// Client requirement: Response must have text or tool calls, not just thinking
 	// If we only have thinking content (no text, no tool calls), add text block with space
 	if hasThinking && !hasNonThinkingContent && len(msg.ToolCalls) == 0 {
-		parts = append(parts, map[string]any{"type": ClaudeBlockText, "text": " "})
+		blocks.EmitText(" ")
 	}
 
-	return parts
+	return blocks.Build()
 }
 
 // BuildUsageMap builds a usage statistics map with detailed token breakdown
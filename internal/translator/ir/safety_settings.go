@@ -0,0 +1,62 @@
+package ir
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// SafetySettingsField is the OpenAI-format request extension that sets Gemini
+// safety thresholds per harm category:
+//
+//	"safety_settings": [{"category": "HARM_CATEGORY_HARASSMENT", "threshold": "BLOCK_ONLY_HIGH"}]
+//
+// Categories left out keep the default threshold. Other providers ignore it.
+const SafetySettingsField = "safety_settings"
+
+// geminiHarmCategories lists the harm categories Gemini accepts.
+var geminiHarmCategories = []string{
+	"HARM_CATEGORY_HARASSMENT",
+	"HARM_CATEGORY_HATE_SPEECH",
+	"HARM_CATEGORY_SEXUALLY_EXPLICIT",
+	"HARM_CATEGORY_DANGEROUS_CONTENT",
+	"HARM_CATEGORY_CIVIC_INTEGRITY",
+}
+
+// geminiHarmThresholds lists the block thresholds Gemini accepts.
+var geminiHarmThresholds = []string{
+	"BLOCK_LOW_AND_ABOVE",
+	"BLOCK_MEDIUM_AND_ABOVE",
+	"BLOCK_ONLY_HIGH",
+	"BLOCK_NONE",
+	"OFF",
+}
+
+// ParseSafetySettings reads the safety_settings extension. Names are matched
+// case-insensitively; an unknown category or threshold is an
+// InvalidRequestError naming the allowed values.
+func ParseSafetySettings(v gjson.Result) ([]SafetySetting, error) {
+	if !v.Exists() || v.Type == gjson.Null {
+		return nil, nil
+	}
+	if !v.IsArray() {
+		return nil, &InvalidRequestError{Message: SafetySettingsField + " must be an array of {category, threshold} objects"}
+	}
+	var settings []SafetySetting
+	for i, item := range v.Array() {
+		category := strings.ToUpper(strings.TrimSpace(item.Get("category").String()))
+		threshold := strings.ToUpper(strings.TrimSpace(item.Get("threshold").String()))
+		if !slices.Contains(geminiHarmCategories, category) {
+			return nil, &InvalidRequestError{Message: fmt.Sprintf("%s[%d].category: %q is not one of %s",
+				SafetySettingsField, i, item.Get("category").String(), strings.Join(geminiHarmCategories, ", "))}
+		}
+		if !slices.Contains(geminiHarmThresholds, threshold) {
+			return nil, &InvalidRequestError{Message: fmt.Sprintf("%s[%d].threshold: %q is not one of %s",
+				SafetySettingsField, i, item.Get("threshold").String(), strings.Join(geminiHarmThresholds, ", "))}
+		}
+		settings = append(settings, SafetySetting{Category: category, Threshold: threshold})
+	}
+	return settings, nil
+}
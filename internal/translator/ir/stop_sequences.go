@@ -0,0 +1,16 @@
+package ir
+
+// MaxStopSequencesGemini is the most stop sequences Gemini accepts; longer
+// lists are rejected with INVALID_ARGUMENT.
+const MaxStopSequencesGemini = 5
+
+// LimitStopSequences returns the stop sequences of req that provider accepts,
+// keeping the first max. When some are dropped a warning is recorded on req.
+func LimitStopSequences(req *UnifiedChatRequest, max int, provider string) []string {
+	if len(req.StopSequences) <= max {
+		return req.StopSequences
+	}
+	req.AddWarning("%s accepts at most %d stop sequences; %d were dropped",
+		provider, max, len(req.StopSequences)-max)
+	return req.StopSequences[:max]
+}
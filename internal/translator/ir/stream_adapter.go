@@ -0,0 +1,202 @@
+package ir
+
+import "strings"
+
+// StreamAccumulator rebuilds a complete response from streamed events, so a
+// client that did not ask to stream can be served from a streaming upstream.
+type StreamAccumulator struct {
+	reasoning strings.Builder
+	signature []byte
+	text      strings.Builder
+	refusal   strings.Builder
+	toolCalls []ToolCall
+	toolByID  map[string]int
+	toolByIdx map[int]int
+	usage     *Usage
+	finish    FinishReason
+	filter    any
+	err       error
+}
+
+// NewStreamAccumulator returns an empty accumulator.
+func NewStreamAccumulator() *StreamAccumulator {
+	return &StreamAccumulator{toolByID: make(map[string]int), toolByIdx: make(map[int]int)}
+}
+
+// Add folds events into the response.
+func (a *StreamAccumulator) Add(events ...UnifiedEvent) {
+	for i := range events {
+		ev := &events[i]
+		switch ev.Type {
+		case EventTypeToken:
+			a.text.WriteString(ev.Content)
+			a.refusal.WriteString(ev.Refusal)
+		case EventTypeReasoning:
+			a.reasoning.WriteString(ev.Reasoning)
+			if len(ev.ThoughtSignature) > 0 {
+				a.signature = ev.ThoughtSignature
+			}
+		case EventTypeReasoningSummary:
+			a.reasoning.WriteString(ev.ReasoningSummary)
+		case EventTypeToolCall, EventTypeToolCallDelta:
+			a.addToolCall(ev)
+		case EventTypeError:
+			if a.err == nil {
+				a.err = ev.Error
+			}
+		case EventTypeFinish:
+			// Stream terminators ([DONE], message_stop) report a plain stop
+			// after the real reason, so a stop never replaces another reason.
+			if r := ev.FinishReason; r != "" && r != FinishReasonUnknown && (a.finish == "" || a.finish == FinishReasonStop) {
+				a.finish = r
+			}
+			if ev.ContentFilter != nil {
+				a.filter = ev.ContentFilter
+			}
+		}
+		if ev.Usage != nil {
+			a.usage = mergeStreamUsage(a.usage, ev.Usage)
+		}
+	}
+}
+
+// addToolCall starts a tool call or extends one seen earlier. Deltas carry
+// the call's ID or stream index and a fragment of its arguments; an event
+// naming a known call carries its complete arguments.
+func (a *StreamAccumulator) addToolCall(ev *UnifiedEvent) {
+	tc := ev.ToolCall
+	if tc == nil {
+		return
+	}
+	pos, known := -1, false
+	if tc.ID != "" {
+		pos, known = a.toolByID[tc.ID]
+	} else if pos, known = a.toolByIdx[ev.ToolCallIndex]; !known && tc.Name == "" && len(a.toolCalls) > 0 {
+		pos, known = len(a.toolCalls)-1, true
+	}
+	if !known {
+		a.toolCalls = append(a.toolCalls, ToolCall{ID: tc.ID, Name: tc.Name, Args: tc.Args, ThoughtSignature: tc.ThoughtSignature})
+		pos = len(a.toolCalls) - 1
+		if tc.ID != "" {
+			a.toolByID[tc.ID] = pos
+		}
+		a.toolByIdx[ev.ToolCallIndex] = pos
+		return
+	}
+	call := &a.toolCalls[pos]
+	if ev.Type == EventTypeToolCall && tc.ID != "" && tc.Name != "" {
+		call.Name, call.Args = tc.Name, tc.Args
+	} else {
+		if call.Name == "" {
+			call.Name = tc.Name
+		}
+		call.Args += tc.Args
+	}
+	if len(tc.ThoughtSignature) > 0 {
+		call.ThoughtSignature = tc.ThoughtSignature
+	}
+}
+
+// mergeStreamUsage combines usage reported across a stream. Providers send
+// running totals, or split input and output counts between events, so the
+// largest value of each field is the final one.
+func mergeStreamUsage(acc, u *Usage) *Usage {
+	if acc == nil {
+		cp := *u
+		return &cp
+	}
+	acc.PromptTokens = max(acc.PromptTokens, u.PromptTokens)
+	acc.CompletionTokens = max(acc.CompletionTokens, u.CompletionTokens)
+	acc.ThoughtsTokenCount = max(acc.ThoughtsTokenCount, u.ThoughtsTokenCount)
+	acc.CachedTokens = max(acc.CachedTokens, u.CachedTokens)
+	acc.CacheCreationInputTokens = max(acc.CacheCreationInputTokens, u.CacheCreationInputTokens)
+	acc.CacheReadInputTokens = max(acc.CacheReadInputTokens, u.CacheReadInputTokens)
+	acc.TotalTokens = max(acc.TotalTokens, u.TotalTokens, acc.PromptTokens+acc.CompletionTokens)
+	if u.PromptTokensDetails != nil {
+		acc.PromptTokensDetails = u.PromptTokensDetails
+	}
+	if u.CompletionTokensDetails != nil {
+		acc.CompletionTokensDetails = u.CompletionTokensDetails
+	}
+	acc.Approximate = acc.Approximate && u.Approximate
+	return acc
+}
+
+// Err returns the first error event of the stream.
+func (a *StreamAccumulator) Err() error { return a.err }
+
+// Messages returns the assistant message built from the stream, or nil when
+// the stream produced nothing.
+func (a *StreamAccumulator) Messages() []Message {
+	msg := Message{Role: RoleAssistant, Refusal: a.refusal.String()}
+	if a.reasoning.Len() > 0 || len(a.signature) > 0 {
+		msg.Content = append(msg.Content, ContentPart{Type: ContentTypeReasoning, Reasoning: a.reasoning.String(), ThoughtSignature: a.signature})
+	}
+	if a.text.Len() > 0 {
+		msg.Content = append(msg.Content, ContentPart{Type: ContentTypeText, Text: a.text.String()})
+	}
+	msg.ToolCalls = a.toolCalls
+	if len(msg.Content) == 0 && len(msg.ToolCalls) == 0 && msg.Refusal == "" {
+		return nil
+	}
+	return []Message{msg}
+}
+
+// Usage returns the usage reported by the stream, or nil.
+func (a *StreamAccumulator) Usage() *Usage { return a.usage }
+
+// FinishReason returns why generation stopped: the reason the stream
+// reported, or tool_calls when it ended with tool calls.
+func (a *StreamAccumulator) FinishReason() FinishReason {
+	switch {
+	case len(a.toolCalls) > 0 && (a.finish == "" || a.finish == FinishReasonStop):
+		return FinishReasonToolCalls
+	case a.finish == "":
+		return FinishReasonStop
+	}
+	return a.finish
+}
+
+// ContentFilter returns the safety block reported by the stream, or nil.
+func (a *StreamAccumulator) ContentFilter() any { return a.filter }
+
+// ResponseEvents splits a complete response into the events a streaming
+// upstream would have sent: reasoning, text and tool calls in order, then a
+// finish event carrying usage. It lets a streaming client be served from a
+// non-streaming upstream.
+func ResponseEvents(messages []Message, usage *Usage, finish FinishReason) []UnifiedEvent {
+	var events []UnifiedEvent
+	toolIndex := 0
+	for _, msg := range messages {
+		if msg.Role != RoleAssistant && msg.Role != "" {
+			continue
+		}
+		for _, part := range msg.Content {
+			switch part.Type {
+			case ContentTypeReasoning:
+				events = append(events, UnifiedEvent{Type: EventTypeReasoning, Reasoning: part.Reasoning, ThoughtSignature: part.ThoughtSignature})
+			case ContentTypeRedactedThinking:
+				events = append(events, UnifiedEvent{Type: EventTypeReasoning, RedactedData: part.RedactedData})
+			case ContentTypeText:
+				if part.Text != "" {
+					events = append(events, UnifiedEvent{Type: EventTypeToken, Content: part.Text})
+				}
+			}
+		}
+		if msg.Refusal != "" {
+			events = append(events, UnifiedEvent{Type: EventTypeToken, Refusal: msg.Refusal})
+		}
+		for i := range msg.ToolCalls {
+			tc := msg.ToolCalls[i]
+			events = append(events, UnifiedEvent{Type: EventTypeToolCall, ToolCall: &tc, ToolCallIndex: toolIndex})
+			toolIndex++
+		}
+	}
+	if finish == "" || finish == FinishReasonUnknown {
+		finish = FinishReasonStop
+		if toolIndex > 0 {
+			finish = FinishReasonToolCalls
+		}
+	}
+	return append(events, UnifiedEvent{Type: EventTypeFinish, FinishReason: finish, Usage: usage})
+}
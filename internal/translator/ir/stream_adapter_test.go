@@ -0,0 +1,85 @@
+package ir
+
+import "testing"
+
+func TestStreamAccumulator_RebuildsResponse(t *testing.T) {
+	a := NewStreamAccumulator()
+	a.Add(
+		UnifiedEvent{Type: EventTypeReasoning, Reasoning: "think"},
+		UnifiedEvent{Type: EventTypeToken, Content: "Hel"},
+		UnifiedEvent{Type: EventTypeToken, Content: "lo"},
+		UnifiedEvent{Type: EventTypeToolCall, ToolCall: &ToolCall{ID: "call_1", Name: "f", Args: ""}, ToolCallIndex: 0},
+		UnifiedEvent{Type: EventTypeToolCall, ToolCall: &ToolCall{Args: `{"a":`}, ToolCallIndex: 0},
+		UnifiedEvent{Type: EventTypeToolCall, ToolCall: &ToolCall{Args: `1}`}, ToolCallIndex: 0},
+		UnifiedEvent{Type: EventTypeFinish, FinishReason: FinishReasonMaxTokens, Usage: &Usage{PromptTokens: 5}},
+		// Terminators and trailing usage chunks must not overwrite what came before.
+		UnifiedEvent{Type: EventTypeFinish, FinishReason: FinishReasonStop, Usage: &Usage{CompletionTokens: 3}},
+	)
+
+	msgs := a.Messages()
+	if len(msgs) != 1 {
+		t.Fatalf("got %d messages, want 1", len(msgs))
+	}
+	m := msgs[0]
+	if len(m.Content) != 2 || m.Content[0].Reasoning != "think" || m.Content[1].Text != "Hello" {
+		t.Errorf("content = %+v", m.Content)
+	}
+	if len(m.ToolCalls) != 1 || m.ToolCalls[0].Name != "f" || m.ToolCalls[0].Args != `{"a":1}` {
+		t.Errorf("tool calls = %+v", m.ToolCalls)
+	}
+	if got := a.FinishReason(); got != FinishReasonMaxTokens {
+		t.Errorf("finish = %q, want max_tokens", got)
+	}
+	if u := a.Usage(); u == nil || u.PromptTokens != 5 || u.CompletionTokens != 3 || u.TotalTokens != 8 {
+		t.Errorf("usage = %+v", u)
+	}
+}
+
+func TestStreamAccumulator_FinishReason(t *testing.T) {
+	a := NewStreamAccumulator()
+	if got := a.FinishReason(); got != FinishReasonStop {
+		t.Errorf("empty stream finish = %q, want stop", got)
+	}
+	a.Add(UnifiedEvent{Type: EventTypeToolCall, ToolCall: &ToolCall{ID: "c", Name: "f", Args: "{}"}})
+	a.Add(UnifiedEvent{Type: EventTypeFinish, FinishReason: FinishReasonStop})
+	if got := a.FinishReason(); got != FinishReasonToolCalls {
+		t.Errorf("finish = %q, want tool_calls", got)
+	}
+}
+
+func TestResponseEvents(t *testing.T) {
+	usage := &Usage{PromptTokens: 4, CompletionTokens: 2, TotalTokens: 6}
+	msgs := []Message{{
+		Role:      RoleAssistant,
+		Content:   []ContentPart{{Type: ContentTypeReasoning, Reasoning: "r"}, {Type: ContentTypeText, Text: "hi"}},
+		ToolCalls: []ToolCall{{ID: "a", Name: "f"}, {ID: "b", Name: "g"}},
+	}}
+	events := ResponseEvents(msgs, usage, "")
+
+	want := []EventType{EventTypeReasoning, EventTypeToken, EventTypeToolCall, EventTypeToolCall, EventTypeFinish}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d", len(events), len(want))
+	}
+	for i, ev := range events {
+		if ev.Type != want[i] {
+			t.Errorf("event %d = %s, want %s", i, ev.Type, want[i])
+		}
+	}
+	if events[3].ToolCallIndex != 1 {
+		t.Errorf("second tool call index = %d, want 1", events[3].ToolCallIndex)
+	}
+	last := events[len(events)-1]
+	if last.FinishReason != FinishReasonToolCalls || last.Usage != usage {
+		t.Errorf("finish event = %+v", last)
+	}
+
+	// Round trip: accumulating the events gives the response back.
+	a := NewStreamAccumulator()
+	a.Add(ResponseEvents(msgs, usage, FinishReasonMaxTokens)...)
+	if got := a.FinishReason(); got != FinishReasonMaxTokens {
+		t.Errorf("round-trip finish = %q, want max_tokens", got)
+	}
+	if got := a.Messages(); len(got) != 1 || len(got[0].ToolCalls) != 2 || got[0].Content[1].Text != "hi" {
+		t.Errorf("round-trip messages = %+v", got)
+	}
+}
@@ -0,0 +1,72 @@
+package ir
+
+import "strings"
+
+// SystemPromptSeparator joins the text of system messages merged into one
+// system prompt.
+const SystemPromptSeparator = "\n\n"
+
+// LateSystemPrefix marks a system message that arrived after the conversation
+// started and was folded into a user turn.
+const LateSystemPrefix = "System: "
+
+// NormalizeSystemMessages prepares messages for providers that take a single
+// system prompt outside the conversation (Claude, Gemini, Ollama generate).
+//
+// Every system message before the first user, assistant or tool turn is merged,
+// in order, into one text-only system message at the front; the last cache
+// control among them is kept. A system message that appears later is an
+// instruction for that point in the conversation, so it stays in place as a
+// user turn prefixed with LateSystemPrefix instead of being hoisted above
+// earlier turns. The input slice is not modified.
+func NormalizeSystemMessages(messages []Message) []Message {
+	hasSystem := false
+	for i := range messages {
+		if messages[i].Role == RoleSystem {
+			hasSystem = true
+			break
+		}
+	}
+	if !hasSystem {
+		return messages
+	}
+
+	out := make([]Message, 0, len(messages))
+	var texts []string
+	var cache *CacheControl
+	leading := true
+	for _, m := range messages {
+		if m.Role != RoleSystem {
+			leading = false
+			out = append(out, m)
+			continue
+		}
+		text := CombineTextParts(m)
+		if leading {
+			if text != "" {
+				texts = append(texts, text)
+			}
+			if m.CacheControl != nil {
+				cache = m.CacheControl
+			}
+			continue
+		}
+		if text == "" {
+			continue
+		}
+		out = append(out, Message{
+			Role:         RoleUser,
+			Content:      []ContentPart{{Type: ContentTypeText, Text: LateSystemPrefix + text}},
+			CacheControl: m.CacheControl,
+		})
+	}
+	if len(texts) == 0 {
+		return out
+	}
+	system := Message{
+		Role:         RoleSystem,
+		Content:      []ContentPart{{Type: ContentTypeText, Text: strings.Join(texts, SystemPromptSeparator)}},
+		CacheControl: cache,
+	}
+	return append([]Message{system}, out...)
+}
@@ -0,0 +1,88 @@
+package ir
+
+import "testing"
+
+func textMessage(role Role, text string) Message {
+	return Message{Role: role, Content: []ContentPart{{Type: ContentTypeText, Text: text}}}
+}
+
+func TestNormalizeSystemMessages_MergesLeading(t *testing.T) {
+	cache := &CacheControl{Type: CacheControlEphemeral}
+	second := textMessage(RoleSystem, "Be brief.")
+	second.CacheControl = cache
+	in := []Message{
+		textMessage(RoleSystem, "You are helpful."),
+		textMessage(RoleSystem, ""),
+		second,
+		textMessage(RoleUser, "hi"),
+	}
+
+	out := NormalizeSystemMessages(in)
+	if len(out) != 2 {
+		t.Fatalf("len = %d, want 2", len(out))
+	}
+	if out[0].Role != RoleSystem {
+		t.Fatalf("out[0].Role = %s, want system", out[0].Role)
+	}
+	if got, want := CombineTextParts(out[0]), "You are helpful.\n\nBe brief."; got != want {
+		t.Errorf("system text = %q, want %q", got, want)
+	}
+	if out[0].CacheControl != cache {
+		t.Errorf("system cache control not carried over")
+	}
+	if out[1].Role != RoleUser || CombineTextParts(out[1]) != "hi" {
+		t.Errorf("out[1] = %+v, want user hi", out[1])
+	}
+	if len(in) != 4 || in[0].Role != RoleSystem {
+		t.Errorf("input slice was modified")
+	}
+}
+
+func TestNormalizeSystemMessages_LateSystemStaysInPlace(t *testing.T) {
+	in := []Message{
+		textMessage(RoleSystem, "Rules."),
+		textMessage(RoleUser, "hi"),
+		textMessage(RoleAssistant, "hello"),
+		textMessage(RoleSystem, "Answer in French."),
+		textMessage(RoleUser, "how are you?"),
+	}
+
+	out := NormalizeSystemMessages(in)
+	if len(out) != 5 {
+		t.Fatalf("len = %d, want 5", len(out))
+	}
+	wantRoles := []Role{RoleSystem, RoleUser, RoleAssistant, RoleUser, RoleUser}
+	for i, r := range wantRoles {
+		if out[i].Role != r {
+			t.Errorf("out[%d].Role = %s, want %s", i, out[i].Role, r)
+		}
+	}
+	if got, want := CombineTextParts(out[3]), LateSystemPrefix+"Answer in French."; got != want {
+		t.Errorf("late system text = %q, want %q", got, want)
+	}
+}
+
+func TestNormalizeSystemMessages_OnlyLateSystem(t *testing.T) {
+	in := []Message{
+		textMessage(RoleUser, "hi"),
+		textMessage(RoleSystem, "Be brief."),
+	}
+
+	out := NormalizeSystemMessages(in)
+	if len(out) != 2 {
+		t.Fatalf("len = %d, want 2", len(out))
+	}
+	for i := range out {
+		if out[i].Role == RoleSystem {
+			t.Errorf("out[%d] is a system message, want none hoisted", i)
+		}
+	}
+}
+
+func TestNormalizeSystemMessages_NoSystem(t *testing.T) {
+	in := []Message{textMessage(RoleUser, "hi")}
+	out := NormalizeSystemMessages(in)
+	if len(out) != 1 || &out[0] != &in[0] {
+		t.Errorf("messages without system role should be returned unchanged")
+	}
+}
@@ -55,6 +55,72 @@ func EffortToBudget(effort string) (budget int, include bool) {
 	}
 }
 
+// effortBudgets holds the thinking budget for each reasoning_effort level.
+type effortBudgets struct {
+	minimal, low, medium, high, xhigh int
+	// auto is used for unrecognized efforts; -1 asks for the model default.
+	auto int
+}
+
+var (
+	// Anthropic rejects budgets under 1024 and has no dynamic budget, and the
+	// budget must stay below max_tokens (32000 on Opus 4.1).
+	claudeEffortBudgets = effortBudgets{minimal: 1024, low: 4096, medium: 10000, high: 24000, xhigh: 31999, auto: 10000}
+	// Gemini 2.5 Flash tops out at 24576; Pro allows 32768.
+	geminiEffortBudgets = effortBudgets{minimal: 128, low: 1024, medium: 8192, high: 24576, xhigh: 32768, auto: -1}
+)
+
+func (b effortBudgets) budget(effort string) (int, bool) {
+	switch strings.ToLower(effort) {
+	case "none":
+		return 0, false
+	case "minimal":
+		return b.minimal, true
+	case "low":
+		return b.low, true
+	case "medium":
+		return b.medium, true
+	case "high":
+		return b.high, true
+	case "xhigh":
+		return b.xhigh, true
+	default:
+		return b.auto, true
+	}
+}
+
+// EffortToBudgetFor converts reasoning_effort to a thinking budget sized for
+// the provider family serving model. Models outside the Claude and Gemini
+// families use EffortToBudget.
+func EffortToBudgetFor(model, effort string) (budget int, include bool) {
+	switch {
+	case IsClaude(model):
+		return claudeEffortBudgets.budget(effort)
+	case strings.Contains(strings.ToLower(model), "gemini"):
+		return geminiEffortBudgets.budget(effort)
+	default:
+		return EffortToBudget(effort)
+	}
+}
+
+// ResolveEffortBudget re-derives the thinking budget of a request that set
+// reasoning_effort, now that the target model is known. A budget the client
+// gave explicitly (one that differs from the generic effort mapping) is kept.
+func ResolveEffortBudget(req *UnifiedChatRequest) {
+	if req == nil || req.Thinking == nil || req.Thinking.Effort == "" {
+		return
+	}
+	effort := string(req.Thinking.Effort)
+	if req.Thinking.ThinkingBudget != nil {
+		if generic, _ := EffortToBudget(effort); int(*req.Thinking.ThinkingBudget) != generic {
+			return
+		}
+	}
+	budget, include := EffortToBudgetFor(req.Model, effort)
+	req.Thinking.ThinkingBudget = Ptr(int32(budget))
+	req.Thinking.IncludeThoughts = include
+}
+
 func BudgetToEffort(budget int, defaultForZero string) string {
 	if budget <= 0 {
 		return defaultForZero
@@ -0,0 +1,28 @@
+package ir
+
+// ForcedToolName returns the single function the model must call, or "" when
+// the tool choice does not force one. A named-function choice forces its name;
+// "required" restricted to one allowed tool forces that tool.
+func ForcedToolName(req *UnifiedChatRequest) string {
+	switch req.ToolChoice {
+	case ToolChoiceFunction:
+		return req.ToolChoiceFunction
+	case ToolChoiceRequired, ToolChoiceAny:
+		if len(req.AllowedTools) == 1 {
+			return req.AllowedTools[0]
+		}
+	}
+	return ""
+}
+
+// RequiresToolCall reports whether the tool choice obliges the model to call
+// some tool.
+func RequiresToolCall(req *UnifiedChatRequest) bool {
+	switch req.ToolChoice {
+	case ToolChoiceRequired, ToolChoiceAny:
+		return true
+	case ToolChoiceFunction:
+		return req.ToolChoiceFunction != ""
+	}
+	return false
+}
@@ -0,0 +1,23 @@
+package ir
+
+// HasToolResultMedia reports whether any tool result in messages carries
+// images or files.
+func HasToolResultMedia(messages []Message) bool {
+	for _, msg := range messages {
+		for _, part := range msg.Content {
+			if tr := part.ToolResult; tr != nil && (len(tr.Images) > 0 || len(tr.Files) > 0) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// DropToolResultMedia is called by converters for providers whose tool results
+// carry text only. When a tool result has images or files, which the converter
+// leaves out, a warning is recorded on req.
+func DropToolResultMedia(req *UnifiedChatRequest, provider string) {
+	if HasToolResultMedia(req.Messages) {
+		req.AddWarning("%s does not accept images or files in tool results; they were dropped", provider)
+	}
+}
@@ -174,6 +174,9 @@ type Usage struct {
 	ToolUsePromptTokens      int64 // Gemini: tokens used for tool/function call context
 	PromptTokensDetails      *PromptTokensDetails
 	CompletionTokensDetails  *CompletionTokensDetails
+	// Approximate marks usage estimated locally because the provider did not
+	// report any.
+	Approximate bool
 }
 
 type PromptTokensDetails struct {
@@ -197,9 +200,10 @@ type OpenAIMeta struct {
 	NativeFinishReason string
 	ThoughtsTokenCount int32 // Matches SDK int32
 	Logprobs           any
-	GroundingMetadata  *GroundingMetadata // Google Search grounding metadata
-	PromptFeedback     *PromptFeedback    // Prompt-level safety feedback
-	ServiceTier        string             // OpenAI service tier used for the request
+	GroundingMetadata  *GroundingMetadata   // Google Search grounding metadata
+	PromptFeedback     *PromptFeedback      // Prompt-level safety feedback
+	ContentFilter      *ContentFilterResult // Set when the provider blocked the response for safety
+	ServiceTier        string               // OpenAI service tier used for the request
 }
 
 // SafetyRating represents content safety evaluation
@@ -451,6 +455,19 @@ type CacheControl struct {
 	TTL  *int64
 }
 
+// CacheControlEphemeral is the cache type applied to content marked through
+// the prompt_cache request extension.
+const CacheControlEphemeral = "ephemeral"
+
+// PromptCacheField is the OpenAI-format request extension that marks the
+// system prompt or individual messages as cacheable:
+//
+//	"prompt_cache": {"system": true, "messages": [3], "cached_content": "cachedContents/abc"}
+//
+// "messages" holds indices into the request's messages array; cached_content
+// references a Gemini cached content. Upstreams without caching ignore it.
+const PromptCacheField = "prompt_cache"
+
 type Message struct {
 	Role         Role
 	Content      []ContentPart
@@ -488,6 +505,7 @@ type UnifiedChatRequest struct {
 	FrequencyPenalty *float64
 	PresencePenalty  *float64
 	Logprobs         *bool
+	LogprobsRequired bool // Fail rather than omit logprobs on providers without them
 	TopLogprobs      *int
 	CandidateCount   *int
 	Thinking         *ThinkingConfig
@@ -519,6 +537,11 @@ type UnifiedChatRequest struct {
 	// OpenAI high priority features
 	Prediction    *PredictionConfig    // Predicted output for speculative decoding
 	StreamOptions *StreamOptionsConfig // Stream configuration options
+
+	// Warnings collects non-fatal notices from request conversion, such as
+	// parameters an upstream could not take in full. Record them with
+	// AddWarning; they reach the client in the X-LLM-Mux-Warnings header.
+	Warnings []string
 }
 
 // FunctionCallingConfig controls function calling behavior.
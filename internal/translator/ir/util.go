@@ -2,6 +2,7 @@ package ir
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"fmt"
 	"github.com/nghyane/llm-mux/internal/json"
 	"strings"
@@ -241,6 +242,35 @@ func ToKiroToolID(id string) string {
 	return id
 }
 
+// ToMistralToolID converts a tool ID to the nine alphanumeric characters
+// Mistral requires. Conforming IDs are kept; others are hashed, so a tool
+// call and its result still pair up after conversion.
+func ToMistralToolID(id string) string {
+	if isMistralToolID(id) {
+		return id
+	}
+	const charset = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+	sum := sha256.Sum256([]byte(id))
+	b := make([]byte, 9)
+	for i := range b {
+		b[i] = charset[int(sum[i])%len(charset)]
+	}
+	return string(b)
+}
+
+func isMistralToolID(id string) bool {
+	if len(id) != 9 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
+
 // GenerateUUID generates a UUID v4 string using pooled buffers to reduce allocations.
 func GenerateUUID() string {
 	bp := GetUUIDBuf()
@@ -477,6 +507,8 @@ func MapClaudeFinishReason(claudeReason string) FinishReason {
 		return FinishReasonMaxTokens
 	case "tool_use":
 		return FinishReasonToolCalls
+	case "refusal":
+		return FinishReasonContentFilter
 	default:
 		return FinishReasonUnknown
 	}
@@ -492,6 +524,25 @@ func MapOpenAIFinishReason(openaiReason string) FinishReason {
 		return FinishReasonToolCalls
 	case "content_filter":
 		return FinishReasonContentFilter
+	case "error":
+		return FinishReasonError
+	default:
+		return FinishReasonUnknown
+	}
+}
+
+// MapMistralFinishReason maps Mistral's finish reasons, which extend the
+// OpenAI set with "model_length" for an exhausted context window.
+func MapMistralFinishReason(mistralReason string) FinishReason {
+	switch mistralReason {
+	case "stop":
+		return FinishReasonStop
+	case "length", "model_length":
+		return FinishReasonMaxTokens
+	case "tool_calls":
+		return FinishReasonToolCalls
+	case "error":
+		return FinishReasonError
 	default:
 		return FinishReasonUnknown
 	}
@@ -529,12 +580,33 @@ func MapFinishReasonToClaude(reason FinishReason) string {
 	case FinishReasonContentFilter, FinishReasonBlocklist,
 		FinishReasonProhibitedContent, FinishReasonSPII,
 		FinishReasonImageSafety, FinishReasonRecitation:
-		return "end_turn" // Claude doesn't have content_filter equivalent
+		return ClaudeStopRefusal
 	default:
 		return "end_turn"
 	}
 }
 
+func MapFinishReasonToGemini(reason FinishReason) string {
+	switch reason {
+	case FinishReasonMaxTokens:
+		return GeminiFinishReasonMAX_TOKENS
+	case FinishReasonContentFilter:
+		return GeminiFinishReasonSAFETY
+	case FinishReasonRecitation:
+		return GeminiFinishReasonRECITATION
+	case FinishReasonBlocklist:
+		return GeminiFinishReasonBLOCKLIST
+	case FinishReasonProhibitedContent:
+		return GeminiFinishReasonPROHIBITED_CONTENT
+	case FinishReasonSPII:
+		return GeminiFinishReasonSPII
+	case FinishReasonImageSafety:
+		return GeminiFinishReasonIMAGE_SAFETY
+	default:
+		return GeminiFinishReasonSTOP
+	}
+}
+
 func MapStandardRole(role string) Role {
 	switch role {
 	case "system", "developer":
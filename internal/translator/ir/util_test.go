@@ -298,6 +298,7 @@ func TestMapClaudeFinishReason(t *testing.T) {
 		{"stop_sequence", FinishReasonStopSequence},
 		{"max_tokens", FinishReasonMaxTokens},
 		{"tool_use", FinishReasonToolCalls},
+		{"refusal", FinishReasonContentFilter},
 		{"unknown_value", FinishReasonUnknown},
 		{"", FinishReasonUnknown},
 	}
@@ -322,6 +323,7 @@ func TestMapOpenAIFinishReason(t *testing.T) {
 		{"tool_calls", FinishReasonToolCalls},
 		{"function_call", FinishReasonToolCalls},
 		{"content_filter", FinishReasonContentFilter},
+		{"error", FinishReasonError},
 		{"unknown_value", FinishReasonUnknown},
 		{"", FinishReasonUnknown},
 	}
@@ -336,6 +338,29 @@ func TestMapOpenAIFinishReason(t *testing.T) {
 	}
 }
 
+func TestMapMistralFinishReason(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected FinishReason
+	}{
+		{"stop", FinishReasonStop},
+		{"length", FinishReasonMaxTokens},
+		{"model_length", FinishReasonMaxTokens},
+		{"tool_calls", FinishReasonToolCalls},
+		{"error", FinishReasonError},
+		{"", FinishReasonUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result := MapMistralFinishReason(tt.input)
+			if result != tt.expected {
+				t.Errorf("MapMistralFinishReason(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
 func TestMapFinishReasonToOpenAI(t *testing.T) {
 	tests := []struct {
 		input    FinishReason
@@ -374,12 +399,12 @@ func TestMapFinishReasonToClaude(t *testing.T) {
 		{FinishReasonMaxTokens, "max_tokens"},
 		{FinishReasonToolCalls, "tool_use"},
 		{FinishReasonStopSequence, "stop_sequence"},
-		{FinishReasonContentFilter, "end_turn"},
-		{FinishReasonBlocklist, "end_turn"},
-		{FinishReasonProhibitedContent, "end_turn"},
-		{FinishReasonSPII, "end_turn"},
-		{FinishReasonImageSafety, "end_turn"},
-		{FinishReasonRecitation, "end_turn"},
+		{FinishReasonContentFilter, "refusal"},
+		{FinishReasonBlocklist, "refusal"},
+		{FinishReasonProhibitedContent, "refusal"},
+		{FinishReasonSPII, "refusal"},
+		{FinishReasonImageSafety, "refusal"},
+		{FinishReasonRecitation, "refusal"},
 		{FinishReasonUnknown, "end_turn"},
 	}
 
@@ -397,7 +422,7 @@ func TestMapFinishReasonToClaude(t *testing.T) {
 
 func TestFinishReasonRoundTrip_OpenAI(t *testing.T) {
 	// Test that common OpenAI reasons round-trip correctly
-	tests := []string{"stop", "length", "tool_calls", "content_filter"}
+	tests := []string{"stop", "length", "tool_calls", "content_filter", "error"}
 
 	for _, openaiReason := range tests {
 		t.Run(openaiReason, func(t *testing.T) {
@@ -412,7 +437,7 @@ func TestFinishReasonRoundTrip_OpenAI(t *testing.T) {
 
 func TestFinishReasonRoundTrip_Claude(t *testing.T) {
 	// Test that common Claude reasons round-trip correctly
-	tests := []string{"end_turn", "max_tokens", "tool_use", "stop_sequence"}
+	tests := []string{"end_turn", "max_tokens", "tool_use", "stop_sequence", "refusal"}
 
 	for _, claudeReason := range tests {
 		t.Run(claudeReason, func(t *testing.T) {
@@ -0,0 +1,18 @@
+package ir
+
+import (
+	"fmt"
+	"slices"
+)
+
+// AddWarning records a non-fatal notice for the client on req, formatted as
+// by fmt.Sprintf. A notice already recorded is not repeated.
+func (req *UnifiedChatRequest) AddWarning(format string, args ...any) {
+	if req == nil {
+		return
+	}
+	w := fmt.Sprintf(format, args...)
+	if !slices.Contains(req.Warnings, w) {
+		req.Warnings = append(req.Warnings, w)
+	}
+}
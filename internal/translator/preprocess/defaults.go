@@ -6,16 +6,21 @@ import (
 )
 
 func applyProviderDefaults(req *ir.UnifiedChatRequest, info *registry.ModelInfo) {
-	applyClaudeDefaults(req)
+	applyClaudeDefaults(req, info)
 }
 
-func applyClaudeDefaults(req *ir.UnifiedChatRequest) {
+// applyClaudeDefaults fills in fields Anthropic requires. A missing max_tokens
+// defaults to the model's output limit when the registry knows it.
+func applyClaudeDefaults(req *ir.UnifiedChatRequest, info *registry.ModelInfo) {
 	if !ir.IsClaudeModel(req.Model) {
 		return
 	}
 
 	if req.MaxTokens == nil || *req.MaxTokens == 0 {
 		defaultMax := ir.ClaudeDefaultMaxTokens
+		if limit := info.MaxOutputTokens(); limit > 0 {
+			defaultMax = limit
+		}
 		req.MaxTokens = &defaultMax
 	}
 
@@ -5,30 +5,12 @@ import (
 	"github.com/nghyane/llm-mux/internal/translator/ir"
 )
 
+// applyLimits clamps request fields to what the model accepts. max_tokens is
+// clamped earlier by the executor, which also warns the client.
 func applyLimits(req *ir.UnifiedChatRequest, info *registry.ModelInfo) {
-	clampMaxTokens(req, info)
 	clampCandidateCount(req, info)
 }
 
-func clampMaxTokens(req *ir.UnifiedChatRequest, info *registry.ModelInfo) {
-	if req.MaxTokens == nil {
-		return
-	}
-
-	if info == nil {
-		return
-	}
-
-	limit := info.OutputTokenLimit
-	if limit == 0 {
-		limit = info.MaxCompletionTokens
-	}
-
-	if limit > 0 && *req.MaxTokens > limit {
-		*req.MaxTokens = limit
-	}
-}
-
 func clampCandidateCount(req *ir.UnifiedChatRequest, info *registry.ModelInfo) {
 	if req.CandidateCount == nil {
 		return
@@ -1,6 +1,8 @@
 package to_ir
 
 import (
+	"fmt"
+
 	"github.com/nghyane/llm-mux/internal/translator"
 	"github.com/nghyane/llm-mux/internal/translator/ir"
 )
@@ -12,11 +14,11 @@ func (openAIParser) Parse(payload []byte) (*ir.UnifiedChatRequest, error) {
 }
 
 func (openAIParser) ParseResponse(payload []byte) ([]ir.Message, *ir.Usage, error) {
-	return nil, nil, nil
+	return ParseOpenAIResponse(payload)
 }
 
 func (openAIParser) ParseChunk(payload []byte) ([]ir.UnifiedEvent, error) {
-	return nil, nil
+	return ParseOpenAIChunk(payload)
 }
 
 func (openAIParser) Format() string { return "openai" }
@@ -28,11 +30,13 @@ func (claudeParser) Parse(payload []byte) (*ir.UnifiedChatRequest, error) {
 }
 
 func (claudeParser) ParseResponse(payload []byte) ([]ir.Message, *ir.Usage, error) {
-	return nil, nil, nil
+	return ParseClaudeResponse(payload)
 }
 
+// ParseChunk parses one Claude stream event on its own; tool call arguments
+// and message_start usage need ParseClaudeChunkWithState.
 func (claudeParser) ParseChunk(payload []byte) ([]ir.UnifiedEvent, error) {
-	return nil, nil
+	return ParseClaudeChunk(payload)
 }
 
 func (claudeParser) Format() string { return "claude" }
@@ -44,11 +48,12 @@ func (geminiParser) Parse(payload []byte) (*ir.UnifiedChatRequest, error) {
 }
 
 func (geminiParser) ParseResponse(payload []byte) ([]ir.Message, *ir.Usage, error) {
-	return nil, nil, nil
+	_, messages, usage, err := ParseGeminiResponse(payload)
+	return messages, usage, err
 }
 
 func (geminiParser) ParseChunk(payload []byte) ([]ir.UnifiedEvent, error) {
-	return nil, nil
+	return ParseGeminiChunk(payload)
 }
 
 func (geminiParser) Format() string { return "gemini" }
@@ -59,12 +64,13 @@ func (ollamaParser) Parse(payload []byte) (*ir.UnifiedChatRequest, error) {
 	return ParseOllamaRequest(payload)
 }
 
-func (ollamaParser) ParseResponse(payload []byte) ([]ir.Message, *ir.Usage, error) {
-	return nil, nil, nil
+// Ollama is only a client format; no upstream sends Ollama responses.
+func (ollamaParser) ParseResponse([]byte) ([]ir.Message, *ir.Usage, error) {
+	return nil, nil, fmt.Errorf("parsing ollama responses is not supported")
 }
 
-func (ollamaParser) ParseChunk(payload []byte) ([]ir.UnifiedEvent, error) {
-	return nil, nil
+func (ollamaParser) ParseChunk([]byte) ([]ir.UnifiedEvent, error) {
+	return nil, fmt.Errorf("parsing ollama stream chunks is not supported")
 }
 
 func (ollamaParser) Format() string { return "ollama" }
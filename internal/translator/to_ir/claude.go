@@ -3,6 +3,7 @@ package to_ir
 import (
 	"bytes"
 	"strings"
+	"time"
 
 	"github.com/nghyane/llm-mux/internal/json"
 	"github.com/nghyane/llm-mux/internal/translator/ir"
@@ -29,6 +30,7 @@ func ParseClaudeRequest(rawJSON []byte) (*ir.UnifiedChatRequest, error) {
 
 	if system := parsed.Get("system"); system.Exists() {
 		var text string
+		var cacheControl *ir.CacheControl
 		if system.Type == gjson.String {
 			text = system.String()
 		} else {
@@ -37,13 +39,17 @@ func ParseClaudeRequest(rawJSON []byte) (*ir.UnifiedChatRequest, error) {
 				if p.Get("type").String() == "text" {
 					parts = append(parts, p.Get("text").String())
 				}
+				if cc := parseClaudeCacheControl(p.Get("cache_control")); cc != nil {
+					cacheControl = cc
+				}
 			}
 			text = strings.Join(parts, "\n")
 		}
 		if text != "" {
 			req.Messages = append(req.Messages, ir.Message{
-				Role:    ir.RoleSystem,
-				Content: []ir.ContentPart{{Type: ir.ContentTypeText, Text: text}},
+				Role:         ir.RoleSystem,
+				Content:      []ir.ContentPart{{Type: ir.ContentTypeText, Text: text}},
+				CacheControl: cacheControl,
 			})
 		}
 	}
@@ -178,19 +184,18 @@ func parseClaudeMessage(m gjson.Result) ir.Message {
 		role = ir.RoleAssistant
 	}
 
-	msg := ir.Message{Role: role}
-	if cc := m.Get("cache_control"); cc.IsObject() {
-		msg.CacheControl = &ir.CacheControl{Type: cc.Get("type").String()}
-		if v := cc.Get("ttl"); v.Exists() {
-			msg.CacheControl.TTL = ir.Ptr(v.Int())
-		}
-	}
+	msg := ir.Message{Role: role, CacheControl: parseClaudeCacheControl(m.Get("cache_control"))}
 
 	content := m.Get("content")
 	if content.Type == gjson.String {
 		msg.Content = append(msg.Content, ir.ContentPart{Type: ir.ContentTypeText, Text: content.String()})
 	} else {
 		for _, block := range content.Array() {
+			// Breakpoints are tracked per message; a marked block caches the
+			// prefix up to the end of its message.
+			if cc := parseClaudeCacheControl(block.Get("cache_control")); cc != nil {
+				msg.CacheControl = cc
+			}
 			switch t := block.Get("type").String(); t {
 			case "text":
 				msg.Content = append(msg.Content, ir.ContentPart{Type: ir.ContentTypeText, Text: block.Get("text").String()})
@@ -325,8 +330,16 @@ func ParseClaudeChunkWithState(rawJSON []byte, state *ir.ClaudeStreamParserState
 		return ir.ParseClaudeStreamDeltaWithState(parsed, state), nil
 	case "content_block_stop":
 		return ir.ParseClaudeContentBlockStop(parsed, state), nil
+	case "message_start":
+		if state != nil {
+			state.StartUsage = ir.ParseClaudeUsage(parsed.Get("message.usage"))
+		}
 	case "message_delta":
-		return ir.ParseClaudeMessageDelta(parsed), nil
+		events := ir.ParseClaudeMessageDelta(parsed)
+		if state != nil {
+			events[0].Usage = ir.MergeClaudeStartUsage(state.StartUsage, events[0].Usage)
+		}
+		return events, nil
 	case "message_stop":
 		return []ir.UnifiedEvent{{Type: ir.EventTypeFinish, FinishReason: ir.FinishReasonStop}}, nil
 	case "error":
@@ -335,6 +348,23 @@ func ParseClaudeChunkWithState(rawJSON []byte, state *ir.ClaudeStreamParserState
 	return nil, nil
 }
 
+// parseClaudeCacheControl returns the cache marker in cc, or nil.
+func parseClaudeCacheControl(cc gjson.Result) *ir.CacheControl {
+	if !cc.IsObject() {
+		return nil
+	}
+	out := &ir.CacheControl{Type: cc.Get("type").String()}
+	if v := cc.Get("ttl"); v.Type == gjson.String {
+		// Anthropic expresses the TTL as a duration ("5m", "1h").
+		if d, err := time.ParseDuration(v.String()); err == nil {
+			out.TTL = ir.Ptr(int64(d / time.Second))
+		}
+	} else if v.Exists() {
+		out.TTL = ir.Ptr(v.Int())
+	}
+	return out
+}
+
 type ClaudeAPIError struct{ Message string }
 
 func (e *ClaudeAPIError) Error() string { return e.Message }
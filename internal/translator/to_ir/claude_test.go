@@ -636,3 +636,20 @@ func TestParseClaudeRequest_CacheControl(t *testing.T) {
 		t.Errorf("CacheControl.Type = %q, want %q", msg.CacheControl.Type, "ephemeral")
 	}
 }
+
+func TestParseClaudeChunkWithState_MessageStartUsage(t *testing.T) {
+	state := ir.NewClaudeStreamParserState()
+	start := `data: {"type":"message_start","message":{"usage":{"input_tokens":42,"cache_read_input_tokens":10,"output_tokens":1}}}`
+	if events, err := ParseClaudeChunkWithState([]byte(start), state); err != nil || len(events) != 0 {
+		t.Fatalf("message_start = %v, %v; want no events", events, err)
+	}
+	delta := `data: {"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":20}}`
+	events, err := ParseClaudeChunkWithState([]byte(delta), state)
+	if err != nil || len(events) != 1 || events[0].Usage == nil {
+		t.Fatalf("message_delta = %v, %v", events, err)
+	}
+	u := events[0].Usage
+	if u.PromptTokens != 42 || u.CompletionTokens != 20 || u.TotalTokens != 62 || u.CacheReadInputTokens != 10 {
+		t.Errorf("usage = %+v, want the input counts from message_start", u)
+	}
+}
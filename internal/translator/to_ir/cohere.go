@@ -0,0 +1,140 @@
+package to_ir
+
+import (
+	"fmt"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+)
+
+// ParseCohereResponse parses a non-streaming Cohere /v1/chat response. Cohere
+// tool calls have no IDs, so each one is given a generated ID.
+func ParseCohereResponse(rawJSON []byte) ([]ir.Message, *ir.Usage, *ir.OpenAIMeta, error) {
+	root, err := ir.ParseAndValidateJSON(rawJSON)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	msg := ir.Message{Role: ir.RoleAssistant}
+	if text := root.Get("text").String(); text != "" {
+		msg.Content = append(msg.Content, ir.ContentPart{Type: ir.ContentTypeText, Text: text})
+	}
+	for _, tc := range root.Get("tool_calls").Array() {
+		msg.ToolCalls = append(msg.ToolCalls, parseCohereToolCall(tc))
+	}
+
+	native := root.Get("finish_reason").String()
+	meta := &ir.OpenAIMeta{
+		ResponseID:         root.Get("response_id").String(),
+		NativeFinishReason: native,
+	}
+	if mapCohereFinishReason(native) == ir.FinishReasonContentFilter {
+		meta.ContentFilter = ir.NewSafetyBlock(native, nil)
+	}
+
+	var messages []ir.Message
+	if len(msg.Content) > 0 || len(msg.ToolCalls) > 0 {
+		messages = []ir.Message{msg}
+	}
+	return messages, parseCohereUsage(root.Get("meta")), meta, nil
+}
+
+// CohereStreamState tracks a Cohere chat stream across events.
+type CohereStreamState struct {
+	toolCallCount int
+}
+
+// NewCohereStreamState returns the state for a new stream.
+func NewCohereStreamState() *CohereStreamState {
+	return &CohereStreamState{}
+}
+
+// ProcessChunk converts one line of a Cohere /v1/chat stream into IR events.
+// Text arrives in text-generation events; tool calls are taken complete from
+// tool-calls-generation, and the per-token tool-calls-chunk events are
+// ignored. stream-end carries the finish reason and usage.
+func (s *CohereStreamState) ProcessChunk(rawJSON []byte) ([]ir.UnifiedEvent, error) {
+	if len(rawJSON) == 0 {
+		return nil, nil
+	}
+	root, err := ir.ParseAndValidateJSON(rawJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	switch root.Get("event_type").String() {
+	case "text-generation":
+		if text := root.Get("text").String(); text != "" {
+			return []ir.UnifiedEvent{{Type: ir.EventTypeToken, Content: text}}, nil
+		}
+	case "tool-calls-generation":
+		var events []ir.UnifiedEvent
+		for _, tc := range root.Get("tool_calls").Array() {
+			call := parseCohereToolCall(tc)
+			events = append(events, ir.UnifiedEvent{Type: ir.EventTypeToolCall, ToolCall: &call, ToolCallIndex: s.toolCallCount})
+			s.toolCallCount++
+		}
+		return events, nil
+	case "stream-end":
+		native := root.Get("finish_reason").String()
+		if native == "ERROR" {
+			return []ir.UnifiedEvent{{Type: ir.EventTypeError, Error: fmt.Errorf("cohere: %s", root.Get("error").String())}}, nil
+		}
+		reason := mapCohereFinishReason(native)
+		if s.toolCallCount > 0 && reason == ir.FinishReasonStop {
+			reason = ir.FinishReasonToolCalls
+		}
+		event := ir.UnifiedEvent{
+			Type:         ir.EventTypeFinish,
+			FinishReason: reason,
+			Usage:        parseCohereUsage(root.Get("response.meta")),
+		}
+		if reason == ir.FinishReasonContentFilter {
+			event.ContentFilter = ir.NewSafetyBlock(native, nil)
+		}
+		return []ir.UnifiedEvent{event}, nil
+	}
+	return nil, nil
+}
+
+func parseCohereToolCall(tc gjson.Result) ir.ToolCall {
+	args := tc.Get("parameters").Raw
+	if args == "" {
+		args = "{}"
+	}
+	return ir.ToolCall{ID: ir.GenToolCallID(), Name: tc.Get("name").String(), Args: args}
+}
+
+// parseCohereUsage reads token counts from a response's meta object,
+// preferring meta.tokens over meta.billed_units.
+func parseCohereUsage(meta gjson.Result) *ir.Usage {
+	tokens := meta.Get("tokens")
+	if !tokens.Exists() {
+		tokens = meta.Get("billed_units")
+	}
+	if !tokens.Exists() {
+		return nil
+	}
+	usage := &ir.Usage{
+		PromptTokens:     tokens.Get("input_tokens").Int(),
+		CompletionTokens: tokens.Get("output_tokens").Int(),
+	}
+	usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+	return usage
+}
+
+func mapCohereFinishReason(reason string) ir.FinishReason {
+	switch reason {
+	case "COMPLETE":
+		return ir.FinishReasonStop
+	case "STOP_SEQUENCE":
+		return ir.FinishReasonStopSequence
+	case "MAX_TOKENS":
+		return ir.FinishReasonMaxTokens
+	case "ERROR_TOXIC":
+		return ir.FinishReasonContentFilter
+	case "ERROR", "ERROR_LIMIT", "USER_CANCEL":
+		return ir.FinishReasonError
+	}
+	return ir.FinishReasonStop
+}
@@ -0,0 +1,124 @@
+package to_ir
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+)
+
+// ParseOpenAIEmbeddingRequest parses a /v1/embeddings body. input may be a
+// string or an array of strings; token-ID arrays are rejected because the
+// upstream tokenizer is not known here.
+func ParseOpenAIEmbeddingRequest(rawJSON []byte) (*ir.EmbeddingRequest, error) {
+	root, err := ir.ParseAndValidateJSON(rawJSON)
+	if err != nil {
+		return nil, err
+	}
+	req := &ir.EmbeddingRequest{
+		Model:          root.Get("model").String(),
+		Dimensions:     int(root.Get("dimensions").Int()),
+		EncodingFormat: root.Get("encoding_format").String(),
+		User:           root.Get("user").String(),
+	}
+
+	input := root.Get("input")
+	switch {
+	case input.Type == gjson.String:
+		req.Inputs = []string{input.String()}
+	case input.IsArray():
+		items := input.Array()
+		req.Inputs = make([]string, 0, len(items))
+		for i, item := range items {
+			if item.Type != gjson.String {
+				return nil, fmt.Errorf("input[%d]: only string inputs are supported", i)
+			}
+			req.Inputs = append(req.Inputs, item.String())
+		}
+	default:
+		return nil, fmt.Errorf("input must be a string or an array of strings")
+	}
+	if len(req.Inputs) == 0 {
+		return nil, fmt.Errorf("input must not be empty")
+	}
+	for i, text := range req.Inputs {
+		if text == "" {
+			return nil, fmt.Errorf("input[%d] must not be empty", i)
+		}
+	}
+	if req.EncodingFormat != "" && req.EncodingFormat != "float" && req.EncodingFormat != ir.EmbeddingEncodingBase64 {
+		return nil, fmt.Errorf("unsupported encoding_format %q", req.EncodingFormat)
+	}
+	return req, nil
+}
+
+// ParseOpenAIEmbeddingResponse parses an OpenAI embeddings response requested
+// with encoding_format "float". Vectors are ordered by their index field.
+func ParseOpenAIEmbeddingResponse(rawJSON []byte) (*ir.EmbeddingResponse, error) {
+	root, err := ir.ParseAndValidateJSON(rawJSON)
+	if err != nil {
+		return nil, err
+	}
+	data := root.Get("data").Array()
+	sort.SliceStable(data, func(i, j int) bool {
+		return data[i].Get("index").Int() < data[j].Get("index").Int()
+	})
+	resp := &ir.EmbeddingResponse{
+		Model:   root.Get("model").String(),
+		Vectors: make([][]float32, 0, len(data)),
+	}
+	for _, item := range data {
+		resp.Vectors = append(resp.Vectors, parseFloatVector(item.Get("embedding")))
+	}
+	if u := root.Get("usage"); u.Exists() {
+		resp.Usage = &ir.Usage{
+			PromptTokens: u.Get("prompt_tokens").Int(),
+			TotalTokens:  u.Get("total_tokens").Int(),
+		}
+	}
+	return resp, nil
+}
+
+// ParseGeminiEmbeddingResponse parses a batchEmbedContents response. Gemini
+// does not report token usage for embeddings.
+func ParseGeminiEmbeddingResponse(rawJSON []byte) (*ir.EmbeddingResponse, error) {
+	root, err := ir.ParseAndValidateJSON(rawJSON)
+	if err != nil {
+		return nil, err
+	}
+	embeddings := root.Get("embeddings").Array()
+	resp := &ir.EmbeddingResponse{Vectors: make([][]float32, 0, len(embeddings))}
+	for _, e := range embeddings {
+		resp.Vectors = append(resp.Vectors, parseFloatVector(e.Get("values")))
+	}
+	return resp, nil
+}
+
+// ParseCohereEmbedResponse parses a Cohere /v1/embed response requested with
+// embedding_types ["float"]. Usage is read from meta.billed_units.
+func ParseCohereEmbedResponse(rawJSON []byte) (*ir.EmbeddingResponse, error) {
+	root, err := ir.ParseAndValidateJSON(rawJSON)
+	if err != nil {
+		return nil, err
+	}
+	embeddings := root.Get("embeddings.float").Array()
+	resp := &ir.EmbeddingResponse{Vectors: make([][]float32, 0, len(embeddings))}
+	for _, e := range embeddings {
+		resp.Vectors = append(resp.Vectors, parseFloatVector(e))
+	}
+	if tokens := root.Get("meta.billed_units.input_tokens"); tokens.Exists() {
+		resp.Usage = &ir.Usage{PromptTokens: tokens.Int(), TotalTokens: tokens.Int()}
+	}
+	return resp, nil
+}
+
+func parseFloatVector(v gjson.Result) []float32 {
+	values := v.Array()
+	out := make([]float32, len(values))
+	for i, f := range values {
+		out[i] = float32(f.Float())
+	}
+	return out
+}
@@ -43,6 +43,12 @@ func ParseGeminiRequest(rawJSON []byte) (*ir.UnifiedChatRequest, error) {
 		req.TopP = ir.ExtractTopP(gc, "topP")
 		req.TopK = ir.ExtractTopK(gc, "topK")
 		req.StopSequences = ir.ExtractStopSequences(gc, "stopSequences")
+		if v := gc.Get("responseLogprobs"); v.Exists() {
+			req.Logprobs = ir.Ptr(v.Bool())
+		}
+		if v := gc.Get("logprobs"); v.Exists() {
+			req.TopLogprobs = ir.Ptr(int(v.Int()))
+		}
 
 		if tc := gc.Get("thinkingConfig"); tc.Exists() {
 			req.Thinking = &ir.ThinkingConfig{
@@ -154,6 +160,12 @@ func ParseGeminiRequest(rawJSON []byte) (*ir.UnifiedChatRequest, error) {
 		for _, name := range tc.Get("allowed_function_names").Array() {
 			req.AllowedTools = append(req.AllowedTools, name.String())
 		}
+		// ANY restricted to a single function is a forced call.
+		if req.ToolChoice == ir.ToolChoiceRequired && len(req.AllowedTools) == 1 {
+			req.ToolChoice = ir.ToolChoiceFunction
+			req.ToolChoiceFunction = req.AllowedTools[0]
+			req.AllowedTools = nil
+		}
 	}
 
 	return req, nil
@@ -335,6 +347,10 @@ func ParseGeminiResponseCandidates(rawJSON []byte, schemaCtx *ir.ToolSchemaConte
 		if fr := candidate.Get("finishReason"); fr.Exists() {
 			finishReason = ir.MapGeminiFinishReason(fr.String())
 		}
+		// Gemini finishes forced function calls with STOP.
+		if finishReason == ir.FinishReasonStop && len(msg.ToolCalls) > 0 {
+			finishReason = ir.FinishReasonToolCalls
+		}
 
 		var groundingMeta *ir.GroundingMetadata
 		if gm := candidate.Get("groundingMetadata"); gm.Exists() {
@@ -580,6 +596,12 @@ func ParseGeminiChunkWithContext(rawJSON []byte, schemaCtx *ir.ToolSchemaContext
 		}
 	}
 
+	safetyBlock := parseGeminiSafetyBlock(parsed)
+	if safetyBlock != nil && finishReason == "" {
+		// A blocked prompt yields no candidates, only promptFeedback
+		finishReason = ir.FinishReasonContentFilter
+	}
+
 	if finishReason != "" || usage != nil {
 		if finishReason == "" {
 			finishReason = ir.FinishReasonStop
@@ -598,12 +620,18 @@ func ParseGeminiChunkWithContext(rawJSON []byte, schemaCtx *ir.ToolSchemaContext
 			logprobs = parseGeminiLogprobs(candidates[0])
 		}
 
+		var contentFilter any
+		if safetyBlock != nil {
+			contentFilter = safetyBlock.ToMap()
+		}
+
 		events = append(events, ir.UnifiedEvent{
 			Type:              ir.EventTypeFinish,
 			Usage:             usage,
 			FinishReason:      finishReason,
 			GroundingMetadata: groundingMeta,
 			Logprobs:          logprobs,
+			ContentFilter:     contentFilter,
 		})
 	}
 
@@ -639,6 +667,7 @@ func parsePromptFeedback(parsed gjson.Result) *ir.PromptFeedback {
 		feedback.SafetyRatings = append(feedback.SafetyRatings, &ir.SafetyRating{
 			Category:    r.Get("category").String(),
 			Probability: r.Get("probability").String(),
+			Blocked:     r.Get("blocked").Bool(),
 		})
 	}
 	return feedback
@@ -744,9 +773,27 @@ func parseGeminiMeta(parsed gjson.Result) *ir.OpenAIMeta {
 		meta.NativeFinishReason = candidates[0].Get("finishReason").String()
 		meta.Logprobs = parseGeminiLogprobs(candidates[0])
 	}
+	meta.ContentFilter = parseGeminiSafetyBlock(parsed)
 	return meta
 }
 
+// parseGeminiSafetyBlock reports a prompt blockReason or a safety finishReason
+// on the first candidate; such responses usually carry no content at all.
+func parseGeminiSafetyBlock(parsed gjson.Result) *ir.ContentFilterResult {
+	if pf := parsePromptFeedback(parsed); pf != nil && pf.BlockReason != "" {
+		return ir.NewSafetyBlock(pf.BlockReason, pf.SafetyRatings)
+	}
+	candidates := parsed.Get("candidates").Array()
+	if len(candidates) == 0 {
+		return nil
+	}
+	fr := candidates[0].Get("finishReason").String()
+	if !ir.IsSafetyFinish(ir.MapGeminiFinishReason(fr)) {
+		return nil
+	}
+	return ir.NewSafetyBlock(fr, parseGeminiSafetyRatings(candidates[0]))
+}
+
 func parseGeminiUsage(parsed gjson.Result) *ir.Usage {
 	u := parsed.Get("usageMetadata")
 	if !u.Exists() {
@@ -762,6 +809,7 @@ func parseGeminiUsage(parsed gjson.Result) *ir.Usage {
 
 	if tokens := u.Get("cachedContentTokenCount").Int(); tokens > 0 {
 		usage.PromptTokensDetails = &ir.PromptTokensDetails{CachedTokens: tokens}
+		usage.CacheReadInputTokens = tokens
 	}
 	if tokens := u.Get("toolUsePromptTokenCount").Int(); tokens > 0 {
 		usage.ToolUsePromptTokens = tokens
@@ -772,12 +820,14 @@ func parseGeminiUsage(parsed gjson.Result) *ir.Usage {
 	return usage
 }
 
+// parseGeminiLogprobs returns the candidate's token logprobs in OpenAI shape.
+// avgLogprobs alone is not reported: Gemini sends it on every candidate,
+// whether or not logprobs were requested, and it has no OpenAI equivalent.
 func parseGeminiLogprobs(candidate gjson.Result) any {
 	if lr := candidate.Get("logprobsResult"); lr.Exists() {
-		return convertGeminiLogprobsToOpenAI(lr)
-	}
-	if avg := candidate.Get("avgLogprobs"); avg.Exists() {
-		return map[string]any{"avg_logprob": avg.Float()}
+		if lp := convertGeminiLogprobsToOpenAI(lr); lp != nil {
+			return lp
+		}
 	}
 	return nil
 }
@@ -914,3 +914,38 @@ func TestMergeConsecutiveModelThinking_MultipleToolCalls(t *testing.T) {
 		}
 	}
 }
+
+func TestParseGeminiResponseCandidates_Logprobs(t *testing.T) {
+	input := `{"candidates":[{"content":{"role":"model","parts":[{"text":"Hi"}]},"finishReason":"STOP","avgLogprobs":-0.1,
+		"logprobsResult":{"chosenCandidates":[{"token":"Hi","logProbability":-0.1}],
+		"topCandidates":[{"candidates":[{"token":"Hi","logProbability":-0.1},{"token":"Hey","logProbability":-2.5}]}]}}]}`
+
+	candidates, _, _, err := ParseGeminiResponseCandidates([]byte(input), nil)
+	if err != nil {
+		t.Fatalf("ParseGeminiResponseCandidates failed: %v", err)
+	}
+	lp, ok := candidates[0].Logprobs.(map[string]any)
+	if !ok {
+		t.Fatalf("Logprobs = %#v, want OpenAI-shaped map", candidates[0].Logprobs)
+	}
+	content := lp["content"].([]any)
+	token := content[0].(map[string]any)
+	if token["token"] != "Hi" || token["logprob"] != -0.1 {
+		t.Errorf("token = %v", token)
+	}
+	if tops := token["top_logprobs"].([]any); len(tops) != 2 {
+		t.Errorf("top_logprobs = %v, want 2 entries", tops)
+	}
+}
+
+func TestParseGeminiResponseCandidates_AvgLogprobsOnly(t *testing.T) {
+	input := `{"candidates":[{"content":{"role":"model","parts":[{"text":"Hi"}]},"finishReason":"STOP","avgLogprobs":-0.1}]}`
+
+	candidates, _, _, err := ParseGeminiResponseCandidates([]byte(input), nil)
+	if err != nil {
+		t.Fatalf("ParseGeminiResponseCandidates failed: %v", err)
+	}
+	if candidates[0].Logprobs != nil {
+		t.Errorf("Logprobs = %#v, want nil without logprobsResult", candidates[0].Logprobs)
+	}
+}
@@ -0,0 +1,62 @@
+package to_ir
+
+import (
+	"github.com/tidwall/gjson"
+
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+)
+
+// moderationCategoryAliases maps category names used by OpenAI-compatible
+// moderation APIs (Mistral) onto the OpenAI categories.
+var moderationCategoryAliases = map[string]string{
+	"hate_and_discrimination":        "hate",
+	"violence_and_threats":           "violence",
+	"selfharm":                       "self-harm",
+	"dangerous_and_criminal_content": "illicit",
+}
+
+// ParseOpenAIModerationResponse parses a moderation response from an
+// OpenAI-compatible API. Category names are mapped onto OpenAI's, and flagged
+// is derived from the categories when the upstream omits it.
+func ParseOpenAIModerationResponse(rawJSON []byte) (*ir.ModerationResponse, error) {
+	root, err := ir.ParseAndValidateJSON(rawJSON)
+	if err != nil {
+		return nil, err
+	}
+	resp := &ir.ModerationResponse{
+		ID:    root.Get("id").String(),
+		Model: root.Get("model").String(),
+	}
+	for _, r := range root.Get("results").Array() {
+		result := ir.ModerationResult{
+			Categories: make(map[string]bool),
+			Scores:     make(map[string]float64),
+		}
+		r.Get("categories").ForEach(func(k, v gjson.Result) bool {
+			name := moderationCategoryName(k.String())
+			result.Categories[name] = result.Categories[name] || v.Bool()
+			return true
+		})
+		r.Get("category_scores").ForEach(func(k, v gjson.Result) bool {
+			name := moderationCategoryName(k.String())
+			result.Scores[name] = max(result.Scores[name], v.Float())
+			return true
+		})
+		if flagged := r.Get("flagged"); flagged.Exists() {
+			result.Flagged = flagged.Bool()
+		} else {
+			for _, hit := range result.Categories {
+				result.Flagged = result.Flagged || hit
+			}
+		}
+		resp.Results = append(resp.Results, result)
+	}
+	return resp, nil
+}
+
+func moderationCategoryName(name string) string {
+	if alias, ok := moderationCategoryAliases[name]; ok {
+		return alias
+	}
+	return name
+}
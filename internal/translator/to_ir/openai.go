@@ -2,6 +2,7 @@ package to_ir
 
 import (
 	"bytes"
+	"fmt"
 	"strings"
 
 	"github.com/tidwall/gjson"
@@ -26,11 +27,15 @@ func ParseOpenAIRequest(rawJSON []byte) (*ir.UnifiedChatRequest, error) {
 	ir.ApplyOpenAIExtendedParams(req, root)
 
 	if input := root.Get("input"); input.Exists() && !root.Get("messages").Exists() {
-		parseResponsesAPIFields(root, req)
+		if err := parseResponsesAPIFields(root, req); err != nil {
+			return nil, err
+		}
+		parsePromptCache(root.Get(ir.PromptCacheField), req, false)
 	} else {
 		for _, m := range root.Get("messages").Array() {
 			req.Messages = append(req.Messages, parseOpenAIMessage(m))
 		}
+		parsePromptCache(root.Get(ir.PromptCacheField), req, true)
 	}
 
 	for _, t := range root.Get("tools").Array() {
@@ -146,26 +151,17 @@ func ParseOpenAIRequest(rawJSON []byte) (*ir.UnifiedChatRequest, error) {
 	}
 
 	if v := root.Get("tool_choice"); v.Exists() {
-		if v.IsObject() {
-			t := v.Get("type").String()
-			if t == "function" || (t == "" && v.Get("function.name").Exists()) {
-				req.ToolChoice = "function"
-				req.ToolChoiceFunction = v.Get("function.name").String()
-				for _, a := range v.Get("allowed_tools").Array() {
-					req.AllowedTools = append(req.AllowedTools, a.String())
-				}
-			} else {
-				req.ToolChoice = t
-			}
-		} else {
-			req.ToolChoice = v.String()
-		}
+		parseOpenAIToolChoice(v, req)
+	}
+
+	if req.SafetySettings, err = ir.ParseSafetySettings(root.Get(ir.SafetySettingsField)); err != nil {
+		return nil, err
 	}
 
 	return req, nil
 }
 
-func parseResponsesAPIFields(root gjson.Result, req *ir.UnifiedChatRequest) {
+func parseResponsesAPIFields(root gjson.Result, req *ir.UnifiedChatRequest) error {
 	if v := root.Get("instructions").String(); v != "" {
 		req.Instructions = v
 		req.Messages = append(req.Messages, ir.Message{
@@ -178,8 +174,12 @@ func parseResponsesAPIFields(root gjson.Result, req *ir.UnifiedChatRequest) {
 			Role: ir.RoleUser, Content: []ir.ContentPart{{Type: ir.ContentTypeText, Text: input.String()}},
 		})
 	} else {
-		for _, item := range input.Array() {
-			if msg := parseResponsesInputItem(item); msg != nil {
+		for i, item := range input.Array() {
+			msg, err := parseResponsesInputItem(item)
+			if err != nil {
+				return &ir.InvalidRequestError{Message: fmt.Sprintf("input[%d]: %v", i, err)}
+			}
+			if msg != nil {
 				req.Messages = append(req.Messages, *msg)
 			}
 		}
@@ -195,9 +195,13 @@ func parseResponsesAPIFields(root gjson.Result, req *ir.UnifiedChatRequest) {
 	if v := root.Get("store"); v.Exists() {
 		req.Store = ir.Ptr(v.Bool())
 	}
+	return nil
 }
 
-func parseResponsesInputItem(item gjson.Result) *ir.Message {
+// parseResponsesInputItem converts one Responses API input item. Reasoning
+// items from earlier turns are dropped; item types with no IR equivalent
+// (item_reference, built-in tool calls, ...) are rejected.
+func parseResponsesInputItem(item gjson.Result) (*ir.Message, error) {
 	t := item.Get("type").String()
 	if t == "" && item.Get("role").Exists() {
 		t = "message"
@@ -215,13 +219,17 @@ func parseResponsesInputItem(item gjson.Result) *ir.Message {
 				}
 			}
 		}
-		return msg
+		return msg, nil
 	case "function_call":
-		return &ir.Message{Role: ir.RoleAssistant, ToolCalls: []ir.ToolCall{{ID: item.Get("call_id").String(), Name: item.Get("name").String(), Args: item.Get("arguments").String()}}}
+		return &ir.Message{Role: ir.RoleAssistant, ToolCalls: []ir.ToolCall{{ID: item.Get("call_id").String(), Name: item.Get("name").String(), Args: item.Get("arguments").String()}}}, nil
 	case "function_call_output":
-		return &ir.Message{Role: ir.RoleTool, Content: []ir.ContentPart{{Type: ir.ContentTypeToolResult, ToolResult: &ir.ToolResultPart{ToolCallID: item.Get("call_id").String(), Result: item.Get("output").String()}}}}
+		return &ir.Message{Role: ir.RoleTool, Content: []ir.ContentPart{{Type: ir.ContentTypeToolResult, ToolResult: parseToolResultContent(item.Get("call_id").String(), item.Get("output"), parseResponsesContentPart)}}}, nil
+	case "reasoning":
+		return nil, nil
+	case "":
+		return nil, fmt.Errorf("item has no type")
 	}
-	return nil
+	return nil, fmt.Errorf("unsupported item type %q", t)
 }
 
 func parseResponsesContentPart(p gjson.Result) *ir.ContentPart {
@@ -385,6 +393,10 @@ func ParseOpenAIChunk(rawJSON []byte) ([]ir.UnifiedEvent, error) {
 		if v := choice.Get("content_filter_results"); v.Exists() {
 			ev.ContentFilter = v.Value()
 		}
+		// Some upstreams report usage on the finish chunk itself.
+		if u := root.Get("usage"); u.IsObject() {
+			ev.Usage = ir.ParseOpenAIUsage(u)
+		}
 		evs = append(evs, ev)
 	} else if len(evs) > 0 {
 		evs[0].SystemFingerprint = root.Get("system_fingerprint").String()
@@ -431,6 +443,78 @@ func parseResponsesStreamEvent(et string, root gjson.Result) ([]ir.UnifiedEvent,
 	return nil, nil
 }
 
+// parseOpenAIToolChoice reads tool_choice in its Chat Completions and
+// Responses API shapes: a mode string, a named function
+// ({"type":"function","function":{"name":...}} or {"type":"function","name":...})
+// or an allowed_tools restriction.
+func parseOpenAIToolChoice(v gjson.Result, req *ir.UnifiedChatRequest) {
+	if !v.IsObject() {
+		req.ToolChoice = v.String()
+		return
+	}
+	switch t := v.Get("type").String(); {
+	case t == "function" || (t == "" && v.Get("function.name").Exists()):
+		req.ToolChoice = ir.ToolChoiceFunction
+		req.ToolChoiceFunction = v.Get("function.name").String()
+		if req.ToolChoiceFunction == "" {
+			req.ToolChoiceFunction = v.Get("name").String()
+		}
+		for _, a := range v.Get("allowed_tools").Array() {
+			req.AllowedTools = append(req.AllowedTools, a.String())
+		}
+	case t == "allowed_tools":
+		at := v.Get("allowed_tools")
+		if !at.IsObject() {
+			at = v
+		}
+		req.ToolChoice = at.Get("mode").String()
+		if req.ToolChoice == "" {
+			req.ToolChoice = ir.ToolChoiceAuto
+		}
+		for _, tool := range at.Get("tools").Array() {
+			name := tool.Get("function.name").String()
+			if name == "" {
+				name = tool.Get("name").String()
+			}
+			if name != "" {
+				req.AllowedTools = append(req.AllowedTools, name)
+			}
+		}
+	default:
+		req.ToolChoice = t
+	}
+}
+
+// parsePromptCache applies the prompt_cache extension. Message indices are only
+// honoured when req.Messages maps one-to-one onto the request's messages array.
+func parsePromptCache(pc gjson.Result, req *ir.UnifiedChatRequest, indexed bool) {
+	if !pc.IsObject() {
+		return
+	}
+	mark := func(m *ir.Message) {
+		if m.CacheControl == nil {
+			m.CacheControl = &ir.CacheControl{Type: ir.CacheControlEphemeral}
+		}
+	}
+	if pc.Get("system").Bool() {
+		for i := range req.Messages {
+			if req.Messages[i].Role == ir.RoleSystem {
+				mark(&req.Messages[i])
+			}
+		}
+	}
+	if indexed {
+		for _, v := range pc.Get("messages").Array() {
+			if i := int(v.Int()); i >= 0 && i < len(req.Messages) {
+				mark(&req.Messages[i])
+			}
+		}
+	}
+	if v := pc.Get("cached_content").String(); v != "" {
+		req.Metadata[ir.MetaGeminiCachedContent] = v
+	}
+}
+
 func parseOpenAIMessage(m gjson.Result) ir.Message {
 	role := m.Get("role").String()
 	msg := ir.Message{Role: ir.MapStandardRole(role)}
@@ -446,9 +530,12 @@ func parseOpenAIMessage(m gjson.Result) ir.Message {
 		}
 	}
 	c := m.Get("content")
-	if c.Type == gjson.String && role != "tool" {
+	switch {
+	case role == "tool":
+		// The content is the tool result; it is parsed below.
+	case c.Type == gjson.String:
 		msg.Content = append(msg.Content, ir.ContentPart{Type: ir.ContentTypeText, Text: c.String()})
-	} else {
+	default:
 		for _, item := range c.Array() {
 			if p := parseOpenAIContentPart(item, &msg); p != nil {
 				msg.Content = append(msg.Content, *p)
@@ -471,7 +558,7 @@ func parseOpenAIMessage(m gjson.Result) ir.Message {
 		if id == "" {
 			id = m.Get("tool_use_id").String()
 		}
-		msg.Content = append(msg.Content, ir.ContentPart{Type: ir.ContentTypeToolResult, ToolResult: &ir.ToolResultPart{ToolCallID: id, Result: ir.SanitizeText(extractContentString(c))}})
+		msg.Content = append(msg.Content, ir.ContentPart{Type: ir.ContentTypeToolResult, ToolResult: parseToolResultContent(id, c, parseChatToolResultPart)})
 	}
 	return msg
 }
@@ -534,7 +621,9 @@ func parseOpenAIContentPart(item gjson.Result, msg *ir.Message) *ir.ContentPart
 		msg.ToolCalls = append(msg.ToolCalls, ir.ToolCall{ID: item.Get("id").String(), Name: item.Get("name").String(), Args: args})
 	case "tool_result":
 		msg.Role = ir.RoleTool
-		return &ir.ContentPart{Type: ir.ContentTypeToolResult, ToolResult: &ir.ToolResultPart{ToolCallID: item.Get("tool_use_id").String(), Result: ir.SanitizeText(extractContentString(item.Get("content")))}}
+		tr := parseToolResultContent(item.Get("tool_use_id").String(), item.Get("content"), parseChatToolResultPart)
+		tr.IsError = item.Get("is_error").Bool()
+		return &ir.ContentPart{Type: ir.ContentTypeToolResult, ToolResult: tr}
 	}
 	return nil
 }
@@ -542,11 +631,14 @@ func parseOpenAIContentPart(item gjson.Result, msg *ir.Message) *ir.ContentPart
 func parseOpenAITool(t gjson.Result) *ir.ToolDefinition {
 	var n, d string
 	var pr gjson.Result
-	if t.Get("type").String() == "function" {
-		fn := t.Get("function")
+	if fn := t.Get("function"); t.Get("type").String() == "function" && fn.Exists() {
 		n, d, pr = fn.Get("name").String(), fn.Get("description").String(), fn.Get("parameters")
 	} else if t.Get("name").Exists() {
+		// Claude-style and flat Responses API definitions.
 		n, d, pr = t.Get("name").String(), t.Get("description").String(), t.Get("input_schema")
+		if !pr.Exists() {
+			pr = t.Get("parameters")
+		}
 	}
 	if n == "" {
 		return nil
@@ -617,14 +709,46 @@ func parseDataURI(url string) *ir.ImagePart {
 	return &ir.ImagePart{MimeType: m, Data: p[1]}
 }
 
-func extractContentString(c gjson.Result) string {
-	if c.Type == gjson.String {
-		return c.String()
-	}
-	for _, i := range c.Array() {
-		if i.Get("type").String() == "text" {
-			return i.Get("text").String()
-		}
+// parseToolResultContent builds the result of tool call id from content. A
+// string is kept as-is; an array of parts has its text joined and its images
+// and files carried alongside, so providers that accept media in tool results
+// receive it intact.
+func parseToolResultContent(id string, c gjson.Result, parse func(gjson.Result) *ir.ContentPart) *ir.ToolResultPart {
+	tr := &ir.ToolResultPart{ToolCallID: id}
+	if !c.IsArray() {
+		tr.Result = ir.SanitizeText(c.String())
+		return tr
+	}
+	var texts []string
+	for _, item := range c.Array() {
+		if item.Type == gjson.String {
+			texts = append(texts, item.String())
+			continue
+		}
+		p := parse(item)
+		if p == nil {
+			continue
+		}
+		switch p.Type {
+		case ir.ContentTypeText:
+			texts = append(texts, p.Text)
+		case ir.ContentTypeImage:
+			tr.Images = append(tr.Images, p.Image)
+		case ir.ContentTypeFile:
+			tr.Files = append(tr.Files, p.File)
+		}
+	}
+	tr.Result = ir.SanitizeText(strings.Join(texts, "\n"))
+	return tr
+}
+
+// parseChatToolResultPart parses one part of a tool result sent in Chat
+// Completions or Claude shape.
+func parseChatToolResultPart(item gjson.Result) *ir.ContentPart {
+	if item.Get("type").String() == "document" {
+		s := item.Get("source")
+		return &ir.ContentPart{Type: ir.ContentTypeFile, File: &ir.FilePart{Filename: item.Get("title").String(), MimeType: s.Get("media_type").String(), FileData: s.Get("data").String(), FileURL: s.Get("url").String(), FileID: s.Get("file_id").String()}}
 	}
-	return c.Raw
+	var msg ir.Message
+	return parseOpenAIContentPart(item, &msg)
 }
@@ -1,6 +1,8 @@
 package to_ir
 
 import (
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/nghyane/llm-mux/internal/translator/ir"
@@ -614,3 +616,130 @@ func TestParseOpenAIRequest_MaxCompletionTokens(t *testing.T) {
 		t.Errorf("MaxTokens = %v, want 300", req.MaxTokens)
 	}
 }
+
+func TestParseOpenAIRequest_PromptCache(t *testing.T) {
+	input := `{
+		"model": "claude-sonnet-4",
+		"messages": [
+			{"role": "system", "content": "You are a helpful assistant."},
+			{"role": "user", "content": "Long document..."},
+			{"role": "assistant", "content": "Noted."},
+			{"role": "user", "content": "Summarize it."}
+		],
+		"prompt_cache": {"system": true, "messages": [1, 9], "cached_content": "cachedContents/abc"}
+	}`
+
+	req, err := ParseOpenAIRequest([]byte(input))
+	if err != nil {
+		t.Fatalf("ParseOpenAIRequest failed: %v", err)
+	}
+	for i, want := range []bool{true, true, false, false} {
+		if got := req.Messages[i].CacheControl != nil; got != want {
+			t.Errorf("message %d cacheable = %v, want %v", i, got, want)
+		}
+	}
+	if cc := req.Messages[0].CacheControl; cc != nil && cc.Type != ir.CacheControlEphemeral {
+		t.Errorf("CacheControl.Type = %q, want %q", cc.Type, ir.CacheControlEphemeral)
+	}
+	if got := req.Metadata[ir.MetaGeminiCachedContent]; got != "cachedContents/abc" {
+		t.Errorf("cached content = %v", got)
+	}
+}
+
+// ==================== Responses API Input Tests ====================
+
+func TestParseOpenAIRequest_ResponsesInput(t *testing.T) {
+	input := `{
+		"model": "gpt-4.1",
+		"instructions": "Be brief.",
+		"input": [
+			{"role": "user", "content": [{"type": "input_text", "text": "Weather in Paris?"}]},
+			{"type": "reasoning", "id": "rs_1", "summary": []},
+			{"type": "function_call", "call_id": "call_1", "name": "get_weather", "arguments": "{\"city\":\"Paris\"}"},
+			{"type": "function_call_output", "call_id": "call_1", "output": "18C"}
+		],
+		"tools": [{"type": "function", "name": "get_weather", "parameters": {"type": "object"}}]
+	}`
+
+	req, err := ParseOpenAIRequest([]byte(input))
+	if err != nil {
+		t.Fatalf("ParseOpenAIRequest failed: %v", err)
+	}
+	roles := make([]ir.Role, len(req.Messages))
+	for i, m := range req.Messages {
+		roles[i] = m.Role
+	}
+	want := []ir.Role{ir.RoleSystem, ir.RoleUser, ir.RoleAssistant, ir.RoleTool}
+	if len(roles) != len(want) {
+		t.Fatalf("roles = %v, want %v", roles, want)
+	}
+	for i := range want {
+		if roles[i] != want[i] {
+			t.Fatalf("roles = %v, want %v", roles, want)
+		}
+	}
+	if tc := req.Messages[2].ToolCalls; len(tc) != 1 || tc[0].ID != "call_1" || tc[0].Name != "get_weather" {
+		t.Errorf("tool calls = %+v", tc)
+	}
+	if tr := req.Messages[3].Content[0].ToolResult; tr == nil || tr.ToolCallID != "call_1" || tr.Result != "18C" {
+		t.Errorf("tool result = %+v", tr)
+	}
+	if len(req.Tools) != 1 || req.Tools[0].Name != "get_weather" {
+		t.Errorf("tools = %+v", req.Tools)
+	}
+}
+
+func TestParseOpenAIRequest_ResponsesInputUnsupportedItem(t *testing.T) {
+	for _, item := range []string{
+		`{"type": "item_reference", "id": "msg_1"}`,
+		`{"type": "computer_call", "call_id": "c"}`,
+		`{"content": "no role or type"}`,
+	} {
+		_, err := ParseOpenAIRequest([]byte(`{"model": "gpt-4.1", "input": [{"role": "user", "content": "hi"}, ` + item + `]}`))
+		var invalid *ir.InvalidRequestError
+		if !errors.As(err, &invalid) {
+			t.Errorf("%s: err = %v, want an invalid request error", item, err)
+			continue
+		}
+		if invalid.StatusCode() != 400 || !strings.HasPrefix(invalid.Message, "input[1]:") {
+			t.Errorf("%s: err = %q (status %d)", item, invalid.Message, invalid.StatusCode())
+		}
+	}
+}
+
+func TestParseOpenAIRequest_StructuredToolResult(t *testing.T) {
+	input := `{
+		"model": "gpt-4o",
+		"messages": [
+			{"role": "user", "content": "Take a screenshot"},
+			{"role": "assistant", "tool_calls": [{"id": "call_1", "type": "function", "function": {"name": "screenshot", "arguments": "{}"}}]},
+			{"role": "tool", "tool_call_id": "call_1", "content": [
+				{"type": "text", "text": "Captured the window."},
+				{"type": "image_url", "image_url": {"url": "data:image/png;base64,iVBORw0KGgo="}},
+				{"type": "text", "text": "1280x720"},
+				{"type": "image_url", "image_url": {"url": "https://example.com/shot.png"}}
+			]}
+		]
+	}`
+
+	req, err := ParseOpenAIRequest([]byte(input))
+	if err != nil {
+		t.Fatalf("ParseOpenAIRequest failed: %v", err)
+	}
+	tr := req.Messages[2].Content[0].ToolResult
+	if tr == nil || tr.ToolCallID != "call_1" {
+		t.Fatalf("tool result = %+v", tr)
+	}
+	if tr.Result != "Captured the window.\n1280x720" {
+		t.Errorf("result = %q, want both text parts", tr.Result)
+	}
+	if len(tr.Images) != 2 {
+		t.Fatalf("images = %d, want 2", len(tr.Images))
+	}
+	if tr.Images[0].MimeType != "image/png" || tr.Images[0].Data != "iVBORw0KGgo=" {
+		t.Errorf("inline image = %+v", tr.Images[0])
+	}
+	if tr.Images[1].URL != "https://example.com/shot.png" {
+		t.Errorf("linked image = %+v", tr.Images[1])
+	}
+}
@@ -0,0 +1,77 @@
+package to_ir
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+)
+
+// ParseRerankRequest parses a /v1/rerank body in the Cohere/Jina shape.
+// Documents may be strings or objects with a text field.
+func ParseRerankRequest(rawJSON []byte) (*ir.RerankRequest, error) {
+	root, err := ir.ParseAndValidateJSON(rawJSON)
+	if err != nil {
+		return nil, err
+	}
+	req := &ir.RerankRequest{
+		Model:           root.Get("model").String(),
+		Query:           root.Get("query").String(),
+		TopN:            int(root.Get("top_n").Int()),
+		ReturnDocuments: root.Get("return_documents").Bool(),
+	}
+	if req.Query == "" {
+		return nil, fmt.Errorf("query must not be empty")
+	}
+	docs := root.Get("documents")
+	if !docs.IsArray() || len(docs.Array()) == 0 {
+		return nil, fmt.Errorf("documents must be a non-empty array")
+	}
+	for i, doc := range docs.Array() {
+		switch {
+		case doc.Type == gjson.String:
+			req.Documents = append(req.Documents, doc.String())
+		case doc.IsObject() && doc.Get("text").Type == gjson.String:
+			req.Documents = append(req.Documents, doc.Get("text").String())
+		default:
+			return nil, fmt.Errorf("documents[%d] must be a string or an object with a text field", i)
+		}
+	}
+	if req.TopN < 0 {
+		return nil, fmt.Errorf("top_n must not be negative")
+	}
+	return req, nil
+}
+
+// ParseRerankResponse parses a rerank response from a Cohere-style API.
+// Results are read from "results" (Cohere, Jina) or "data" (Voyage) and
+// sorted by descending score. Usage is read from usage.total_tokens when
+// present.
+func ParseRerankResponse(rawJSON []byte) (*ir.RerankResponse, error) {
+	root, err := ir.ParseAndValidateJSON(rawJSON)
+	if err != nil {
+		return nil, err
+	}
+	resp := &ir.RerankResponse{
+		ID:    root.Get("id").String(),
+		Model: root.Get("model").String(),
+	}
+	items := root.Get("results")
+	if !items.Exists() {
+		items = root.Get("data")
+	}
+	for _, item := range items.Array() {
+		resp.Results = append(resp.Results, ir.RerankResult{
+			Index: int(item.Get("index").Int()),
+			Score: item.Get("relevance_score").Float(),
+		})
+	}
+	sort.SliceStable(resp.Results, func(i, j int) bool { return resp.Results[i].Score > resp.Results[j].Score })
+
+	if tokens := root.Get("usage.total_tokens"); tokens.Exists() {
+		resp.Usage = &ir.Usage{PromptTokens: tokens.Int(), TotalTokens: tokens.Int()}
+	}
+	return resp, nil
+}
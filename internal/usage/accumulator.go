@@ -0,0 +1,357 @@
+package usage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/json"
+	"github.com/nghyane/llm-mux/internal/persist"
+)
+
+const (
+	accumulatorBucketSize   = time.Minute
+	accumulatorRetention    = 24 * time.Hour
+	accumulatorSaveInterval = time.Minute
+	accumulatorFileVersion  = 1
+
+	unknownUsageLabel = "unknown"
+)
+
+// UsageCounters holds request and token totals.
+type UsageCounters struct {
+	Requests     int64 `json:"requests"`
+	Failed       int64 `json:"failed"`
+	InputTokens  int64 `json:"input_tokens"`
+	OutputTokens int64 `json:"output_tokens"`
+	TotalTokens  int64 `json:"total_tokens"`
+}
+
+func (c *UsageCounters) add(o UsageCounters) {
+	c.Requests += o.Requests
+	c.Failed += o.Failed
+	c.InputTokens += o.InputTokens
+	c.OutputTokens += o.OutputTokens
+	c.TotalTokens += o.TotalTokens
+}
+
+// UsageWindow aggregates the counters recorded since a point in time.
+type UsageWindow struct {
+	Since       time.Time                `json:"since"`
+	Totals      UsageCounters            `json:"totals"`
+	ByProvider  map[string]UsageCounters `json:"by_provider"`
+	ByModel     map[string]UsageCounters `json:"by_model"`
+	ByClientKey map[string]UsageCounters `json:"by_client_key"`
+	ByAccount   map[string]UsageCounters `json:"by_account"`
+}
+
+// AccumulatedUsage is the rolling view served by the management API.
+type AccumulatedUsage struct {
+	LastHour UsageWindow `json:"last_hour"`
+	LastDay  UsageWindow `json:"last_day"`
+}
+
+// counterKey identifies one series inside a bucket.
+type counterKey struct {
+	Provider  string
+	Model     string
+	ClientKey string
+	Account   string
+}
+
+type usageBucket struct {
+	start    time.Time
+	counters map[counterKey]*UsageCounters
+}
+
+// Accumulator keeps per-minute usage counters for the last day. It is
+// registered as a usage plugin and can save its buckets to a JSON file so the
+// rolling totals survive restarts.
+type Accumulator struct {
+	mu      sync.Mutex
+	buckets []*usageBucket // ordered by start
+	dirty   bool
+
+	saver persist.Loop
+}
+
+var defaultAccumulator = NewAccumulator()
+
+func init() {
+	RegisterPlugin(defaultAccumulator)
+}
+
+// GetAccumulator returns the shared rolling usage accumulator.
+func GetAccumulator() *Accumulator { return defaultAccumulator }
+
+// NewAccumulator constructs an empty in-memory accumulator.
+func NewAccumulator() *Accumulator { return &Accumulator{} }
+
+// HandleUsage implements Plugin.
+func (a *Accumulator) HandleUsage(ctx context.Context, record Record) {
+	if a == nil || !statisticsEnabled.Load() {
+		return
+	}
+	timestamp := record.RequestedAt
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+	tokens := normaliseUsage(record.Usage)
+	failed := record.Failed
+	if !failed {
+		failed = !resolveSuccess(ctx)
+	}
+	delta := UsageCounters{
+		Requests:     1,
+		InputTokens:  tokens.PromptTokens,
+		OutputTokens: tokens.CompletionTokens,
+		TotalTokens:  tokens.TotalTokens,
+	}
+	if failed {
+		delta.Failed = 1
+	}
+	key := counterKey{
+		Provider:  usageLabel(record.Provider),
+		Model:     usageLabel(record.Model),
+		ClientKey: usageLabel(record.ClientKey),
+		Account:   usageLabel(record.AuthID),
+	}
+	a.add(timestamp, key, delta)
+}
+
+func usageLabel(v string) string {
+	if v = strings.TrimSpace(v); v == "" {
+		return unknownUsageLabel
+	}
+	return v
+}
+
+func (a *Accumulator) add(at time.Time, key counterKey, delta UsageCounters) {
+	start := at.Truncate(accumulatorBucketSize)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.pruneLocked(time.Now())
+	if start.Before(time.Now().Add(-accumulatorRetention)) {
+		return
+	}
+	bucket := a.bucketLocked(start)
+	counters, ok := bucket.counters[key]
+	if !ok {
+		counters = &UsageCounters{}
+		bucket.counters[key] = counters
+	}
+	counters.add(delta)
+	a.dirty = true
+}
+
+// bucketLocked returns the bucket starting at start, inserting it in order.
+func (a *Accumulator) bucketLocked(start time.Time) *usageBucket {
+	i := len(a.buckets)
+	for i > 0 && a.buckets[i-1].start.After(start) {
+		i--
+	}
+	if i > 0 && a.buckets[i-1].start.Equal(start) {
+		return a.buckets[i-1]
+	}
+	bucket := &usageBucket{start: start, counters: make(map[counterKey]*UsageCounters)}
+	a.buckets = append(a.buckets, nil)
+	copy(a.buckets[i+1:], a.buckets[i:])
+	a.buckets[i] = bucket
+	return bucket
+}
+
+func (a *Accumulator) pruneLocked(now time.Time) {
+	cutoff := now.Add(-accumulatorRetention).Truncate(accumulatorBucketSize)
+	n := 0
+	for n < len(a.buckets) && a.buckets[n].start.Before(cutoff) {
+		n++
+	}
+	if n > 0 {
+		a.buckets = append(a.buckets[:0], a.buckets[n:]...)
+		a.dirty = true
+	}
+}
+
+// Snapshot returns the last-hour and last-day windows as of now.
+func (a *Accumulator) Snapshot(now time.Time) AccumulatedUsage {
+	hourStart := now.Add(-time.Hour).Truncate(accumulatorBucketSize)
+	dayStart := now.Add(-accumulatorRetention).Truncate(accumulatorBucketSize)
+	out := AccumulatedUsage{LastHour: newUsageWindow(hourStart), LastDay: newUsageWindow(dayStart)}
+	if a == nil {
+		return out
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, bucket := range a.buckets {
+		if bucket.start.Before(dayStart) {
+			continue
+		}
+		inHour := !bucket.start.Before(hourStart)
+		for key, counters := range bucket.counters {
+			out.LastDay.add(key, *counters)
+			if inHour {
+				out.LastHour.add(key, *counters)
+			}
+		}
+	}
+	return out
+}
+
+func newUsageWindow(since time.Time) UsageWindow {
+	return UsageWindow{
+		Since:       since,
+		ByProvider:  make(map[string]UsageCounters),
+		ByModel:     make(map[string]UsageCounters),
+		ByClientKey: make(map[string]UsageCounters),
+		ByAccount:   make(map[string]UsageCounters),
+	}
+}
+
+func (w *UsageWindow) add(key counterKey, c UsageCounters) {
+	w.Totals.add(c)
+	addTo(w.ByProvider, key.Provider, c)
+	addTo(w.ByModel, key.Model, c)
+	addTo(w.ByClientKey, key.ClientKey, c)
+	addTo(w.ByAccount, key.Account, c)
+}
+
+func addTo(m map[string]UsageCounters, label string, c UsageCounters) {
+	v := m[label]
+	v.add(c)
+	m[label] = v
+}
+
+// accumulatorFile is the on-disk form of the accumulator.
+type accumulatorFile struct {
+	Version int                 `json:"version"`
+	Buckets []accumulatorRecord `json:"buckets"`
+}
+
+type accumulatorRecord struct {
+	Start   time.Time          `json:"start"`
+	Entries []accumulatorEntry `json:"entries"`
+}
+
+type accumulatorEntry struct {
+	Provider  string `json:"provider"`
+	Model     string `json:"model"`
+	ClientKey string `json:"client_key"`
+	Account   string `json:"account"`
+	UsageCounters
+}
+
+// Load replaces the in-memory buckets with those saved at path. A missing
+// file is not an error.
+func (a *Accumulator) Load(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	var file accumulatorFile
+	if err = json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("decode usage counters: %w", err)
+	}
+	if file.Version != accumulatorFileVersion {
+		return fmt.Errorf("unsupported usage counters version %d", file.Version)
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.buckets = nil
+	for _, rec := range file.Buckets {
+		bucket := a.bucketLocked(rec.Start.Truncate(accumulatorBucketSize))
+		for _, e := range rec.Entries {
+			key := counterKey{Provider: e.Provider, Model: e.Model, ClientKey: e.ClientKey, Account: e.Account}
+			counters, ok := bucket.counters[key]
+			if !ok {
+				counters = &UsageCounters{}
+				bucket.counters[key] = counters
+			}
+			counters.add(e.UsageCounters)
+		}
+	}
+	a.pruneLocked(time.Now())
+	a.dirty = false
+	return nil
+}
+
+// Save writes the buckets to path when they changed since the last save.
+func (a *Accumulator) Save(path string) error {
+	a.mu.Lock()
+	if !a.dirty {
+		a.mu.Unlock()
+		return nil
+	}
+	a.pruneLocked(time.Now())
+	file := accumulatorFile{Version: accumulatorFileVersion, Buckets: make([]accumulatorRecord, 0, len(a.buckets))}
+	for _, bucket := range a.buckets {
+		rec := accumulatorRecord{Start: bucket.start, Entries: make([]accumulatorEntry, 0, len(bucket.counters))}
+		for key, counters := range bucket.counters {
+			rec.Entries = append(rec.Entries, accumulatorEntry{
+				Provider:      key.Provider,
+				Model:         key.Model,
+				ClientKey:     key.ClientKey,
+				Account:       key.Account,
+				UsageCounters: *counters,
+			})
+		}
+		file.Buckets = append(file.Buckets, rec)
+	}
+	a.dirty = false
+	a.mu.Unlock()
+
+	data, err := json.Marshal(file)
+	if err == nil {
+		err = persist.WriteFile(path, data)
+	}
+	if err != nil {
+		a.mu.Lock()
+		a.dirty = true
+		a.mu.Unlock()
+	}
+	return err
+}
+
+// StartPersistence loads saved counters from path and saves them every minute
+// until StopPersistence is called. It does nothing while already running, so
+// live counters are never replaced by older saved ones.
+func (a *Accumulator) StartPersistence(path string) error {
+	if a == nil || path == "" || a.saver.Running() {
+		return nil
+	}
+	if err := a.Load(path); err != nil {
+		return err
+	}
+	a.saver.Start(path, accumulatorSaveInterval, "usage counters", a.Save)
+	return nil
+}
+
+// StopPersistence stops the save loop and writes the counters one last time.
+func (a *Accumulator) StopPersistence() error {
+	if a == nil {
+		return nil
+	}
+	return a.saver.Stop()
+}
+
+// InitializeCounters loads the rolling usage counters from path and keeps the
+// file updated. An empty path keeps the counters in memory only.
+func InitializeCounters(path string) error {
+	if strings.HasPrefix(path, "~") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return fmt.Errorf("failed to get home directory: %w", err)
+		}
+		path = filepath.Join(home, path[1:])
+	}
+	return defaultAccumulator.StartPersistence(path)
+}
+
+// StopCounters flushes the rolling usage counters to disk.
+func StopCounters() error { return defaultAccumulator.StopPersistence() }
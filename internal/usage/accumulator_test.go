@@ -0,0 +1,74 @@
+package usage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nghyane/llm-mux/internal/translator/ir"
+)
+
+func TestAccumulator_Windows(t *testing.T) {
+	a := NewAccumulator()
+	now := time.Now()
+	a.HandleUsage(context.Background(), Record{
+		Provider: "claude", Model: "claude-sonnet-4", ClientKey: "team-a", AuthID: "claude-a.json",
+		RequestedAt: now.Add(-2 * time.Hour),
+		Usage:       &ir.Usage{PromptTokens: 100, CompletionTokens: 10, TotalTokens: 110},
+	})
+	a.HandleUsage(context.Background(), Record{
+		Provider: "gemini", Model: "gemini-2.5-pro", ClientKey: "team-b", AuthID: "gemini-b.json",
+		RequestedAt: now.Add(-time.Minute),
+		Usage:       &ir.Usage{PromptTokens: 7, CompletionTokens: 3, TotalTokens: 10},
+	})
+	a.HandleUsage(context.Background(), Record{
+		Provider: "gemini", Model: "gemini-2.5-pro", RequestedAt: now, Failed: true,
+	})
+	a.HandleUsage(context.Background(), Record{
+		Provider: "claude", RequestedAt: now.Add(-25 * time.Hour),
+		Usage: &ir.Usage{PromptTokens: 1000, TotalTokens: 1000},
+	})
+
+	snap := a.Snapshot(now)
+	if got := snap.LastHour.Totals; got.Requests != 2 || got.Failed != 1 || got.InputTokens != 7 || got.OutputTokens != 3 {
+		t.Fatalf("last hour totals = %+v", got)
+	}
+	if got := snap.LastDay.Totals; got.Requests != 3 || got.InputTokens != 107 || got.TotalTokens != 120 {
+		t.Fatalf("last day totals = %+v", got)
+	}
+	if got := snap.LastDay.ByProvider["claude"]; got.InputTokens != 100 {
+		t.Fatalf("claude by provider = %+v", got)
+	}
+	if got := snap.LastHour.ByClientKey[unknownUsageLabel]; got.Requests != 1 || got.Failed != 1 {
+		t.Fatalf("unknown client key = %+v", got)
+	}
+	if got := snap.LastDay.ByAccount["claude-a.json"]; got.OutputTokens != 10 {
+		t.Fatalf("claude account = %+v", got)
+	}
+}
+
+func TestAccumulator_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage-counters.json")
+	a := NewAccumulator()
+	a.HandleUsage(context.Background(), Record{
+		Provider: "claude", Model: "claude-sonnet-4", ClientKey: "team-a", AuthID: "claude-a.json",
+		RequestedAt: time.Now(),
+		Usage:       &ir.Usage{PromptTokens: 40, CompletionTokens: 2, TotalTokens: 42},
+	})
+	if err := a.Save(path); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	b := NewAccumulator()
+	if err := b.Load(path); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	got := b.Snapshot(time.Now()).LastHour.ByModel["claude-sonnet-4"]
+	if got.Requests != 1 || got.InputTokens != 40 || got.OutputTokens != 2 {
+		t.Fatalf("restored counters = %+v", got)
+	}
+	if err := NewAccumulator().Load(filepath.Join(t.TempDir(), "missing.json")); err != nil {
+		t.Fatalf("missing file: %v", err)
+	}
+}
@@ -14,6 +14,7 @@ type Record struct {
 	Provider    string
 	Model       string
 	APIKey      string
+	ClientKey   string // log-safe label of the client API key
 	AuthID      string
 	AuthIndex   uint64
 	Source      string
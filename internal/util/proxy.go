@@ -17,7 +17,7 @@ func SetProxy(cfg *config.SDKConfig, httpClient *http.Client) *http.Client {
 	proxyURL, errParse := url.Parse(cfg.ProxyURL)
 	if errParse == nil {
 		switch proxyURL.Scheme {
-		case "socks5":
+		case "socks5", "socks5h":
 			var proxyAuth *proxy.Auth
 			if proxyURL.User != nil {
 				username := proxyURL.User.Username()
@@ -10,7 +10,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/nghyane/llm-mux/internal/auth/login"
 	"github.com/nghyane/llm-mux/internal/config"
+	log "github.com/nghyane/llm-mux/internal/logging"
 	"github.com/nghyane/llm-mux/internal/provider"
 	"github.com/nghyane/llm-mux/internal/runtime/geminicli"
 )
@@ -55,6 +57,54 @@ func createProviderAuth(idGen *stableIDGenerator, providerName, label, key, base
 	return a
 }
 
+// createVertexAuth builds the auth for a vertex provider. It carries the
+// project, region and optional service account file as attributes; the
+// executor falls back to Application Default Credentials without a file.
+func createVertexAuth(idGen *stableIDGenerator, prov config.Provider, cfg *config.Config, now time.Time) *provider.Auth {
+	location := prov.Location
+	if location == "" && len(prov.Locations) > 0 {
+		location = prov.Locations[0]
+	}
+	if location == "" {
+		location = "us-central1"
+	}
+	id, token := idGen.next("vertex:service-account", prov.Project, location, prov.CredentialsFile)
+	attrs := map[string]string{
+		"source":     fmt.Sprintf("config:vertex[%s]", token),
+		"project_id": prov.Project,
+		"location":   location,
+	}
+	if len(prov.Locations) > 0 {
+		attrs["locations"] = strings.Join(prov.Locations, ",")
+	}
+	if prov.UserAgent != "" {
+		attrs["user_agent"] = prov.UserAgent
+	}
+	if prov.StreamMode != "" {
+		attrs["stream_mode"] = prov.StreamMode
+	}
+	if prov.CredentialsFile != "" {
+		attrs["credentials_file"] = prov.CredentialsFile
+	}
+	if prov.BaseURL != "" {
+		attrs["base_url"] = prov.BaseURL
+	}
+	addConfigHeadersToAttrs(prov.Headers, attrs)
+	a := &provider.Auth{
+		ID:         id,
+		Provider:   "vertex",
+		Label:      "vertex-" + prov.Project,
+		Labels:     prov.AccountLabels(config.ProviderAPIKey{}),
+		Status:     provider.StatusActive,
+		ProxyURL:   prov.ProxyURL,
+		Attributes: attrs,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	applyAuthExcludedModelsMeta(a, cfg, prov.ExcludedModels, "service_account")
+	return a
+}
+
 // SnapshotCoreAuths converts current clients snapshot into core auth entries.
 func (w *Watcher) SnapshotCoreAuths() []*provider.Auth {
 	out := make([]*provider.Auth, 0, 32)
@@ -81,6 +131,18 @@ func (w *Watcher) SnapshotCoreAuths() []*provider.Auth {
 			case config.ProviderTypeVertexCompat:
 				pName = "vertex"
 				lbl = "vertex-apikey"
+			case config.ProviderTypeCohere:
+				pName = "cohere"
+				lbl = "cohere-apikey"
+			case config.ProviderTypeXAI:
+				pName = "xai"
+				lbl = "xai-apikey"
+			case config.ProviderTypeMistral:
+				pName = "mistral"
+				lbl = "mistral-apikey"
+			case config.ProviderTypeVertex:
+				out = append(out, createVertexAuth(idGen, prov, cfg, now))
+				continue
 			default:
 				continue
 			}
@@ -94,6 +156,13 @@ func (w *Watcher) SnapshotCoreAuths() []*provider.Auth {
 					proxy = strings.TrimSpace(prov.ProxyURL)
 				}
 				auth := createProviderAuth(idGen, pName, lbl, key, strings.TrimSpace(prov.BaseURL), proxy, prov.Headers, prov.Models, prov.ExcludedModels, cfg, now)
+				if prov.UserAgent != "" {
+					auth.Attributes["user_agent"] = prov.UserAgent
+				}
+				if prov.StreamMode != "" {
+					auth.Attributes["stream_mode"] = prov.StreamMode
+				}
+				auth.Labels = prov.AccountLabels(apiKey)
 				out = append(out, auth)
 			}
 		}
@@ -110,7 +179,7 @@ func (w *Watcher) SnapshotCoreAuths() []*provider.Auth {
 			continue
 		}
 		full := filepath.Join(w.authDir, name)
-		data, err := os.ReadFile(full)
+		data, err := login.ReadTokenFile(full)
 		if err != nil || len(data) == 0 {
 			continue
 		}
@@ -140,20 +209,29 @@ func (w *Watcher) SnapshotCoreAuths() []*provider.Auth {
 		if p, ok := metadata["proxy_url"].(string); ok {
 			proxyURL = p
 		}
+		attrs := map[string]string{
+			"source": full,
+			"path":   full,
+		}
+		if base, _ := metadata["base_url"].(string); strings.TrimSpace(base) != "" {
+			if errBase := config.ValidateBaseURL(base); errBase != nil {
+				log.Warnf("ignoring base_url in %s: %v", name, errBase)
+			} else {
+				attrs["base_url"] = strings.TrimSpace(base)
+			}
+		}
 
 		a := &provider.Auth{
-			ID:       id,
-			Provider: prov,
-			Label:    label,
-			Status:   provider.StatusActive,
-			Attributes: map[string]string{
-				"source": full,
-				"path":   full,
-			},
-			ProxyURL:  proxyURL,
-			Metadata:  metadata,
-			CreatedAt: now,
-			UpdatedAt: now,
+			ID:         id,
+			Provider:   prov,
+			Label:      label,
+			Labels:     provider.LabelsFromMetadata(metadata),
+			Status:     provider.StatusActive,
+			Attributes: attrs,
+			ProxyURL:   proxyURL,
+			Metadata:   metadata,
+			CreatedAt:  now,
+			UpdatedAt:  now,
 		}
 		applyAuthExcludedModelsMeta(a, cfg, nil, "oauth")
 		if prov == "gemini-cli" {
@@ -102,6 +102,10 @@ func buildConfigChangeDetails(oldCfg, newCfg *config.Config) []string {
 		changes = append(changes, entries...)
 	}
 
+	if !reflect.DeepEqual(oldCfg.ModelFamilies, newCfg.ModelFamilies) {
+		changes = append(changes, fmt.Sprintf("model-families: updated (%d -> %d entries)", len(oldCfg.ModelFamilies), len(newCfg.ModelFamilies)))
+	}
+
 	// Remote management (never print the key)
 	if oldCfg.RemoteManagement.AllowRemote != newCfg.RemoteManagement.AllowRemote {
 		changes = append(changes, fmt.Sprintf("remote-management.allow-remote: %t -> %t", oldCfg.RemoteManagement.AllowRemote, newCfg.RemoteManagement.AllowRemote))
@@ -199,7 +199,7 @@ func applyAuthExcludedModelsMeta(auth *provider.Auth, cfg *config.Config, perKey
 			}
 		}
 	}
-	if authKindKey == "apikey" {
+	if authKindKey == "apikey" || authKindKey == "service_account" {
 		add(perKey)
 	} else if cfg.OAuthExcludedModels != nil {
 		providerKey := strings.ToLower(strings.TrimSpace(auth.Provider))
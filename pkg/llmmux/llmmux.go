@@ -1,5 +1,29 @@
 // Package llmmux provides the public API for embedding llm-mux as a library.
 // It wraps the internal service implementation with a stable, minimal API surface.
+//
+// Cross-cutting behavior around upstream calls is added with executor
+// middleware rather than by modifying executors:
+//
+//	stats := llmmux.NewProviderStats()
+//	svc, err := llmmux.NewBuilder().
+//		WithConfig(cfg).
+//		WithConfigPath(path).
+//		WithExecutorMiddleware(llmmux.LoggingMiddleware(), llmmux.MetricsMiddleware(stats)).
+//		Build()
+//
+// Middleware is applied in the order given, the first being outermost: above,
+// logging sees each call before metrics does and its result after. Every
+// attempt against a credential passes through the chain, so retries and
+// fallbacks across credentials are each observed separately.
+//
+// A custom Executor registered with Manager.RegisterExecutor can implement
+// ModelLister to have its models registered for every auth of its provider,
+// and CapabilityDeclarer so those models take part in capability routing.
+// Executors implementing Embedder serve /v1/embeddings for their models, and
+// executors implementing Reranker serve /v1/rerank.
+//
+// Package translate converts requests and responses between the OpenAI,
+// Claude and Gemini formats without building a Service.
 package llmmux
 
 import (
@@ -9,6 +33,7 @@ import (
 	"github.com/nghyane/llm-mux/internal/auth/login"
 	"github.com/nghyane/llm-mux/internal/config"
 	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/registry"
 	"github.com/nghyane/llm-mux/internal/service"
 )
 
@@ -30,6 +55,41 @@ type Auth = provider.Auth
 // Manager orchestrates auth lifecycle, selection, execution, and persistence.
 type Manager = provider.Manager
 
+// Executor performs upstream calls for a single provider.
+type Executor = provider.ProviderExecutor
+
+// ExecutorMiddleware decorates an Executor; see Builder.WithExecutorMiddleware.
+type ExecutorMiddleware = provider.ExecutorMiddleware
+
+// ModelLister is implemented by executors that declare the models they serve.
+type ModelLister = provider.ModelLister
+
+// CapabilityDeclarer is implemented by executors that declare model capabilities.
+type CapabilityDeclarer = provider.CapabilityDeclarer
+
+// Embedder is implemented by executors that serve embeddings.
+type Embedder = provider.Embedder
+
+// Reranker is implemented by executors that serve rerank requests.
+type Reranker = provider.Reranker
+
+// ModelInfo describes a model served by an executor.
+type ModelInfo = registry.ModelInfo
+
+// Capability is a bit set of request features a model can serve.
+type Capability = registry.Capability
+
+// Capabilities a model can declare.
+const (
+	CapVision     = registry.CapVision
+	CapTools      = registry.CapTools
+	CapJSONSchema = registry.CapJSONSchema
+	CapThinking   = registry.CapThinking
+)
+
+// ProviderStats collects per provider and model call outcomes and latency.
+type ProviderStats = provider.ProviderStats
+
 // Authenticator manages login and optional refresh flows for a provider.
 type Authenticator = login.Authenticator
 
@@ -64,6 +124,22 @@ func NewProviderManager(store provider.Store) *Manager {
 	return provider.NewManager(store, nil, nil)
 }
 
+// LoggingMiddleware returns the built-in executor logging middleware.
+func LoggingMiddleware() ExecutorMiddleware {
+	return provider.LoggingMiddleware()
+}
+
+// MetricsMiddleware returns the built-in executor metrics middleware, which
+// records into stats.
+func MetricsMiddleware(stats *ProviderStats) ExecutorMiddleware {
+	return provider.MetricsMiddleware(stats)
+}
+
+// NewProviderStats creates an empty stats collector for MetricsMiddleware.
+func NewProviderStats() *ProviderStats {
+	return provider.NewProviderStats()
+}
+
 // Run is a convenience function to create and run a service with default settings.
 func Run(ctx context.Context, cfg *Config) error {
 	svc, err := NewBuilder().
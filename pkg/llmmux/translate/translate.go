@@ -0,0 +1,121 @@
+// Package translate converts chat requests and responses between the OpenAI
+// Chat Completions, Anthropic Messages and Gemini generateContent formats,
+// the same way the server does, without running it.
+//
+// Every conversion goes through llm-mux's intermediate representation, so
+// any supported format converts to any other:
+//
+//	Request   OpenAI, Claude, Gemini request  -> OpenAI, Claude, Gemini request
+//	Response  OpenAI, Claude, Gemini response -> OpenAI, Claude, Gemini response
+//	Stream    OpenAI, Claude, Gemini stream   -> OpenAI, Claude, Gemini stream
+//
+// A request converted to its own format is normalized rather than passed
+// through. Responses in the same format pass through unchanged. The Responses
+// API, Ollama and provider-specific envelopes are not part of this package.
+//
+// Translating a client's OpenAI request for Claude and the reply back:
+//
+//	body, err := translate.Request(translate.OpenAI, translate.Claude, "", openAIBody)
+//	...
+//	reply, err := translate.Response(translate.Claude, translate.OpenAI, model, claudeBody)
+//
+// Functions are safe for concurrent use; a Stream is not.
+package translate
+
+import (
+	"fmt"
+
+	"github.com/nghyane/llm-mux/internal/provider"
+	"github.com/nghyane/llm-mux/internal/runtime/executor"
+)
+
+// Format names a wire format.
+type Format string
+
+// Supported formats.
+const (
+	// OpenAI is the Chat Completions format (/v1/chat/completions).
+	OpenAI Format = "openai"
+	// Claude is the Anthropic Messages format (/v1/messages).
+	Claude Format = "claude"
+	// Gemini is the generateContent format (:generateContent and
+	// :streamGenerateContent).
+	Gemini Format = "gemini"
+)
+
+func (f Format) check() (provider.Format, error) {
+	switch f {
+	case OpenAI, Claude, Gemini:
+		return provider.FromString(string(f)), nil
+	}
+	return provider.FormatUnknown, fmt.Errorf("translate: unsupported format %q", string(f))
+}
+
+func checkPair(from, to Format) (provider.Format, provider.Format, error) {
+	src, err := from.check()
+	if err != nil {
+		return src, src, err
+	}
+	dst, err := to.check()
+	return src, dst, err
+}
+
+// Request converts a chat request body from one format to another. model,
+// when set, replaces the model named in the body; Gemini requests name none,
+// so it should be set when from is Gemini.
+func Request(from, to Format, model string, payload []byte) ([]byte, error) {
+	src, _, err := checkPair(from, to)
+	if err != nil {
+		return nil, err
+	}
+	switch to {
+	case Claude:
+		return executor.TranslateToClaude(nil, src, model, payload, false, nil)
+	case Gemini:
+		return executor.TranslateToGemini(nil, src, model, payload, false, nil)
+	default:
+		return executor.TranslateToOpenAI(nil, src, model, payload, false, nil)
+	}
+}
+
+// Response converts a complete, non-streaming response body from one format
+// to another. model is reported in the converted response.
+func Response(from, to Format, model string, payload []byte) ([]byte, error) {
+	src, dst, err := checkPair(from, to)
+	if err != nil {
+		return nil, err
+	}
+	return executor.TranslateResponseNonStream(nil, src, dst, payload, model)
+}
+
+// Stream converts a streamed response one line at a time. Create one per
+// response with NewStream.
+type Stream struct {
+	t *executor.ChunkTranslator
+}
+
+// NewStream returns a Stream converting a response streamed in from into the
+// chunks a streaming client of to expects. model is reported in the chunks.
+func NewStream(from, to Format, model string) (*Stream, error) {
+	src, dst, err := checkPair(from, to)
+	if err != nil {
+		return nil, err
+	}
+	t, err := executor.NewChunkTranslator(nil, src, dst, model)
+	if err != nil {
+		return nil, err
+	}
+	return &Stream{t: t}, nil
+}
+
+// Translate converts one line of the upstream stream, such as an SSE
+// "data:" line, into zero or more chunks. Each chunk is complete output in
+// the target format, SSE framing included.
+func (s *Stream) Translate(line []byte) ([][]byte, error) {
+	return s.t.Translate(line)
+}
+
+// Close returns the chunks that remain once the upstream stream has ended.
+func (s *Stream) Close() [][]byte {
+	return s.t.Flush()
+}
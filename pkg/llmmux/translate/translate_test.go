@@ -0,0 +1,59 @@
+package translate
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/nghyane/llm-mux/internal/translator/golden"
+)
+
+// TestGolden converts the translator's shared fixtures through this package;
+// the outputs are in testdata.
+func TestGolden(t *testing.T) {
+	golden.Run(t, "testdata", func(fx golden.Fixture, to string) ([]byte, error) {
+		from := Format(fx.From)
+		switch fx.Kind {
+		case "request":
+			return Request(from, Format(to), fx.Model, fx.Input)
+		case "response":
+			return Response(from, Format(to), fx.Model, fx.Input)
+		case "stream":
+			lines, err := fx.StreamLines()
+			if err != nil {
+				return nil, err
+			}
+			return translateStream(from, Format(to), fx.Model, lines)
+		}
+		return nil, fmt.Errorf("unknown fixture kind %q", fx.Kind)
+	})
+}
+
+// translateStream feeds lines to a Stream and joins the chunks.
+func translateStream(from, to Format, model string, lines []string) ([]byte, error) {
+	s, err := NewStream(from, to, model)
+	if err != nil {
+		return nil, err
+	}
+	var out []byte
+	for _, line := range lines {
+		chunks, err := s.Translate([]byte(line))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, bytes.Join(chunks, nil)...)
+	}
+	return append(out, bytes.Join(s.Close(), nil)...), nil
+}
+
+func TestUnsupportedFormat(t *testing.T) {
+	if _, err := Request(OpenAI, "ollama", "", []byte(`{}`)); err == nil {
+		t.Error("Request to ollama succeeded")
+	}
+	if _, err := Response("codex", OpenAI, "", []byte(`{}`)); err == nil {
+		t.Error("Response from codex succeeded")
+	}
+	if _, err := NewStream(Gemini, "", ""); err == nil {
+		t.Error("NewStream to an empty format succeeded")
+	}
+}